@@ -3,6 +3,9 @@ package agent
 import (
 	"context"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/nevindra/oasis/core"
 	"github.com/nevindra/oasis/internal/runtime"
@@ -210,16 +213,98 @@ func WithToolConfig(tc ToolConfig) AgentOption {
 	return func(c *Config) { tc.ApplyTo(c) }
 }
 
+// WithToolTimeout bounds every tool call to d. The tool's context is
+// cancelled when d elapses, and the call returns an error result the model
+// sees like any other tool failure. A ToolConfig policy with its own Timeout
+// overrides d for that tool; policies without one inherit it. Tools must
+// honour ctx to actually stop. Streaming tools are exempt, as with all
+// ToolPolicy settings.
+func WithToolTimeout(d time.Duration) AgentOption {
+	return func(c *Config) { c.ToolTimeout = d }
+}
+
+// WithToolRetry retries a failing tool call up to maxAttempts times in total,
+// waiting backoff before the second attempt and doubling it after each
+// failure. Only errors core.DefaultRetryOn accepts are retried: timeouts and
+// errors marked with core.RetryableError. Terminal errors and
+// ToolResult.Error outcomes go to the model at once. The backoff stops when
+// ctx is cancelled. Tools opt out by implementing core.RetrySafeTool and
+// returning false. A ToolConfig policy with its own Retries overrides this for
+// that tool. Streaming tools are exempt, as with all ToolPolicy settings.
+func WithToolRetry(maxAttempts int, backoff time.Duration) AgentOption {
+	return func(c *Config) {
+		c.ToolRetries = max(maxAttempts-1, 0)
+		c.ToolRetryDelay = backoff
+	}
+}
+
+// WithMaxParallelTools caps how many tool calls from one LLM response run
+// concurrently (default 10). 1 executes them sequentially, in order. It is
+// shorthand for WithLimits(Limits{MaxParallelDispatch: n}) and also bounds
+// execute_plan fan-out. See WithToolConcurrency for a per-tool cap.
+func WithMaxParallelTools(n int) AgentOption {
+	return func(c *Config) { c.MaxParallelDispatch = n }
+}
+
+// WithSequentialTools executes the tool calls of each LLM response one at a
+// time, in the order the model emitted them, on the agent's own goroutine:
+// each call's side effects (file writes, scheduling) complete before the
+// next call starts, which makes traces and integration tests reproducible.
+// The cost is latency — an iteration takes the sum of its tool durations
+// instead of the longest one — so parallel dispatch stays the default.
+// Unlike WithMaxParallelTools(1) it does not limit execute_plan fan-out.
+func WithSequentialTools() AgentOption {
+	return func(c *Config) { c.SequentialTools = true }
+}
+
+// WithToolCache caches successful tool results by tool name and canonicalized
+// arguments for ttl, so identical calls within a conversation (or across
+// users sharing the agent) skip execution. Pass core.NewInMemoryToolCache()
+// for a bounded in-process LRU. Tools opt out by implementing
+// core.CacheableTool and returning false — do that for any tool with side
+// effects or mutable state. A tool whose output depends on who calls it
+// (a tenant filter, the task's chat) implements core.ScopedCacheTool so
+// each scope gets its own entries. See CacheMiddleware.
+func WithToolCache(cache core.ToolCache, ttl time.Duration) AgentOption {
+	return func(c *Config) {
+		// Innermost, so approval gates, logging, and spans still see every call.
+		c.ToolMiddleware = append([]core.ToolMiddleware{CacheMiddleware(cache, ttl)}, c.ToolMiddleware...)
+	}
+}
+
+// WithToolArgValidation checks every tool call's arguments against the
+// tool's Parameters schema before it runs. Invalid calls are not executed;
+// the model gets a tool error listing each violation and can retry with
+// corrected arguments. See ArgsValidationMiddleware.
+func WithToolArgValidation() AgentOption {
+	return func(c *Config) { c.ToolMiddleware = append(c.ToolMiddleware, ArgsValidationMiddleware()) }
+}
+
+// WithToolApproval asks the human to approve every call to the named tools
+// before they run, using Approval's defaults: the prompt shows the tool name
+// and an argument preview, and a denial goes back to the LLM as a tool error.
+// Requires WithInputHandler; without one, gated calls fail. Use
+// ToolConfig.Approvals with Approval for a custom prompt or DenyHalt.
+func WithToolApproval(toolNames ...string) AgentOption {
+	return func(c *Config) {
+		for _, name := range toolNames {
+			c.ToolApprovals = append(c.ToolApprovals, Approval(name))
+		}
+	}
+}
+
+// approvalArgsPreview caps the argument preview in the default approval prompt.
+const approvalArgsPreview = 300
+
 // Approval is a convenience builder for ApprovalConfig with sane defaults
-// (default prompt: "Approve call to <name>?", OnDeny: DenyAskLLMToRevise).
-// Use this when populating ToolConfig.Approvals.
+// (default prompt: "Approve call to <name>?" followed by a preview of the
+// arguments, OnDeny: DenyAskLLMToRevise). Use this when populating
+// ToolConfig.Approvals.
 func Approval(toolName string, opts ...ApprovalOption) ApprovalConfig {
 	cfg := ApprovalConfig{
 		ToolName: toolName,
-		Prompt: func(call core.ToolCall) string {
-			return "Approve call to " + call.Name + "?"
-		},
-		OnDeny: DenyAskLLMToRevise,
+		Prompt:   defaultApprovalPrompt,
+		OnDeny:   DenyAskLLMToRevise,
 	}
 	for _, opt := range opts {
 		opt(&cfg)
@@ -227,6 +312,18 @@ func Approval(toolName string, opts ...ApprovalOption) ApprovalConfig {
 	return cfg
 }
 
+func defaultApprovalPrompt(call core.ToolCall) string {
+	q := "Approve call to " + call.Name + "?"
+	args := strings.TrimSpace(string(call.Args))
+	if args == "" || args == "{}" || args == "null" {
+		return q
+	}
+	if preview := TruncateStr(args, approvalArgsPreview); preview != args {
+		args = preview + "…"
+	}
+	return q + "\nArguments: " + args
+}
+
 // Hook constructors re-exported from runtime.
 var (
 	Continue          = runtime.Continue
@@ -289,6 +386,67 @@ func WithGeneration(g Generation) AgentOption {
 	}
 }
 
+// WithStopSequences sets the strings that end generation on every LLM call,
+// keeping the agent's other generation params. Apply it after WithGeneration,
+// which replaces the whole set.
+func WithStopSequences(seqs ...string) AgentOption {
+	seqs = slices.Clone(seqs)
+	return func(c *Config) {
+		var g Generation
+		if c.GenParams != nil {
+			g = *c.GenParams
+		}
+		g.StopSequences = seqs
+		cloned := runtime.CloneGeneration(g)
+		c.GenParams = &cloned
+	}
+}
+
+// WithAutoContinue continues a final response that stopped at the
+// output-token limit (FinishLength): the partial reply and a "continue" turn
+// are sent back to the model, up to maxContinuations times, and the pieces
+// are concatenated into one Output. Each continuation is one more LLM call,
+// counts toward MaxIter and adds to Usage. A reply still truncated after the
+// last continuation finishes with FinishLength. Ignored when a ResponseSchema
+// is set, since partial JSON cannot be continued reliably.
+func WithAutoContinue(maxContinuations int) AgentOption {
+	return func(c *Config) { c.AutoContinue = maxContinuations }
+}
+
+// WithAttachmentTypes restricts the MIME types accepted in
+// AgentTask.Attachments, e.g. WithAttachmentTypes("image/*", "application/pdf").
+// Execute fails with an *AttachmentError before any LLM call when an attachment
+// does not match. Without it, the provider's own list applies when the
+// provider implements core.AttachmentTypesProvider.
+func WithAttachmentTypes(mimeTypes ...string) AgentOption {
+	return func(c *Config) { c.AttachmentTypes = append(c.AttachmentTypes, mimeTypes...) }
+}
+
+// WithToolCallFallback lets the agent use tools with providers that cannot
+// call them natively — those implementing core.ToolSupportProvider and
+// reporting false. For such providers the tool definitions are written into
+// the system prompt, the model is asked to reply with a fenced tool_calls
+// JSON block, and that block is parsed back into ToolCalls. Tool results go
+// back as user messages. Providers with native support are unaffected.
+func WithToolCallFallback() AgentOption {
+	return func(c *Config) { c.ToolCallFallback = true }
+}
+
+// WithDryRun makes the agent plan without acting: tool calls the model makes
+// are recorded as StepTraces marked DryRun, and the model gets a synthetic
+// "dry run — not executed" result instead of the tool's output. With
+// toolNames, only calls to those tools are skipped and the rest run
+// normally, e.g. WithDryRun("shell_exec", "file_write") to review side
+// effects while reads still inform the plan. AgentResult.DryRun is set on
+// every run. Skipped calls never reach approval gates or middleware.
+// ask_user still runs; each execute_plan step is checked on its own.
+func WithDryRun(toolNames ...string) AgentOption {
+	return func(c *Config) {
+		c.DryRun = true
+		c.DryRunTools = append(c.DryRunTools, toolNames...)
+	}
+}
+
 // WithPlanExecution enables the built-in "execute_plan" tool.
 func WithPlanExecution() AgentOption {
 	return func(c *Config) { c.PlanExecution = true }
@@ -313,6 +471,13 @@ func WithSkills(p skills.SkillProvider) AgentOption {
 	return func(c *Config) { c.SkillProvider = p }
 }
 
+// WithSkillSearcher sets the searcher behind the skill_search tool, e.g.
+// skills.NewEmbeddingSearcher for semantic matching. Requires a provider
+// configured via WithSkills.
+func WithSkillSearcher(s skills.SkillSearcher) AgentOption {
+	return func(c *Config) { c.SkillSearcher = s }
+}
+
 // WithSkillCatalog injects the catalog of available skills (name, description,
 // tags) into the system prompt on every request, so the model can choose a skill
 // before its first tool call. Requires a provider configured via WithSkills.
@@ -327,6 +492,18 @@ func WithResponseSchema(s *core.ResponseSchema) AgentOption {
 	return func(c *Config) { c.ResponseSchema = s }
 }
 
+// WithResponseSchemaRetries validates the final response against the
+// ResponseSchema and, when it does not conform (invalid JSON, missing required
+// property, wrong type), re-prompts the model with the validation errors up
+// to n times. Each retry is one more LLM call and counts toward MaxIter. When
+// retries run out, Execute returns *ErrSchemaValidation carrying the last raw
+// output and its errors. n <= 0 (the default) skips validation. Requires
+// WithResponseSchema; with streaming, text deltas of rejected attempts have
+// already been emitted.
+func WithResponseSchemaRetries(n int) AgentOption {
+	return func(c *Config) { c.ResponseSchemaRetries = n }
+}
+
 // WithDynamicPrompt sets a per-request prompt resolution function.
 func WithDynamicPrompt(fn PromptFunc) AgentOption {
 	return func(c *Config) { c.DynamicPrompt = fn }
@@ -496,6 +673,58 @@ func InputHandlerFromContext(ctx context.Context) (InputHandler, bool) {
 	return h, ok
 }
 
+// NewTimeoutInputHandler wraps inner so a question left unanswered for
+// timeout resolves to onTimeout(req) instead of blocking the run — e.g. a
+// default "No" when the user walks away from an ask_user dialog. On timeout
+// the context passed to inner is cancelled, ending its wait; a handler that
+// ignores ctx is abandoned rather than waited for. A nil onTimeout returns
+// context.DeadlineExceeded instead. Cancelling the caller's ctx returns
+// ctx.Err(), never the default. timeout <= 0 returns inner unchanged.
+func NewTimeoutInputHandler(inner InputHandler, timeout time.Duration, onTimeout func(InputRequest) InputResponse) InputHandler {
+	if timeout <= 0 {
+		return inner
+	}
+	return &timeoutInputHandler{inner: inner, timeout: timeout, onTimeout: onTimeout}
+}
+
+type timeoutInputHandler struct {
+	inner     InputHandler
+	timeout   time.Duration
+	onTimeout func(InputRequest) InputResponse
+}
+
+func (h *timeoutInputHandler) RequestInput(ctx context.Context, req InputRequest) (InputResponse, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	type reply struct {
+		resp InputResponse
+		err  error
+	}
+	done := make(chan reply, 1)
+	go func() {
+		resp, err := h.inner.RequestInput(waitCtx, req)
+		done <- reply{resp, err}
+	}()
+
+	select {
+	case r := <-done:
+		// An answer that raced the deadline still wins; an error caused by
+		// the deadline falls through to the default below.
+		if r.err == nil || ctx.Err() != nil || waitCtx.Err() == nil {
+			return r.resp, r.err
+		}
+	case <-waitCtx.Done():
+	}
+	if err := ctx.Err(); err != nil {
+		return InputResponse{}, err
+	}
+	if h.onTimeout == nil {
+		return InputResponse{}, context.DeadlineExceeded
+	}
+	return h.onTimeout(req), nil
+}
+
 // ---- Task context propagation ----
 
 // taskCtxKey is the context key for AgentTask.
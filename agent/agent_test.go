@@ -355,6 +355,47 @@ func (m *mockInputHandler) RequestInput(_ context.Context, req InputRequest) (In
 	return m.response, m.err
 }
 
+// blockingInputHandler waits for ctx and records why it stopped.
+type blockingInputHandler struct{ stopped chan error }
+
+func (b blockingInputHandler) RequestInput(ctx context.Context, _ InputRequest) (InputResponse, error) {
+	<-ctx.Done()
+	b.stopped <- ctx.Err()
+	return InputResponse{}, ctx.Err()
+}
+
+func TestTimeoutInputHandler(t *testing.T) {
+	no := func(InputRequest) InputResponse { return InputResponse{Value: "No"} }
+
+	// An answer in time passes through.
+	h := NewTimeoutInputHandler(&mockInputHandler{response: InputResponse{Value: "yes"}}, time.Second, no)
+	if resp, err := h.RequestInput(context.Background(), InputRequest{}); err != nil || resp.Value != "yes" {
+		t.Fatalf("answered: resp = %+v, err = %v", resp, err)
+	}
+
+	// No answer: the default wins and the inner wait is cancelled.
+	inner := blockingInputHandler{stopped: make(chan error, 3)}
+	h = NewTimeoutInputHandler(inner, 10*time.Millisecond, no)
+	if resp, err := h.RequestInput(context.Background(), InputRequest{Question: "Deploy?"}); err != nil || resp.Value != "No" {
+		t.Fatalf("timed out: resp = %+v, err = %v; want the default", resp, err)
+	}
+	if err := <-inner.stopped; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("inner stopped with %v, want deadline exceeded", err)
+	}
+
+	// Caller cancellation is an error, not the default.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewTimeoutInputHandler(inner, time.Second, no).RequestInput(ctx, InputRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: err = %v, want context.Canceled", err)
+	}
+
+	// A nil default reports the timeout.
+	if _, err := NewTimeoutInputHandler(inner, time.Millisecond, nil).RequestInput(context.Background(), InputRequest{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("nil default: err = %v, want deadline exceeded", err)
+	}
+}
+
 func TestInputHandlerFromContextMissing(t *testing.T) {
 	ctx := context.Background()
 	handler, ok := InputHandlerFromContext(ctx)
@@ -1060,6 +1101,19 @@ func TestGenerationParamsCompose(t *testing.T) {
 	}
 }
 
+func TestWithStopSequences(t *testing.T) {
+	cfg := BuildConfig([]AgentOption{
+		WithGeneration(Generation{Temperature: ptr(0.2)}),
+		WithStopSequences("Observation:", "END"),
+	})
+	if cfg.GenParams == nil || cfg.GenParams.Temperature == nil || *cfg.GenParams.Temperature != 0.2 {
+		t.Fatalf("GenParams = %+v, want temperature kept", cfg.GenParams)
+	}
+	if got := cfg.GenParams.StopSequences; len(got) != 2 || got[0] != "Observation:" || got[1] != "END" {
+		t.Errorf("StopSequences = %v, want [Observation: END]", got)
+	}
+}
+
 func TestGenerationParamsNilWhenUnset(t *testing.T) {
 	cfg := BuildConfig(nil)
 	if cfg.GenParams != nil {
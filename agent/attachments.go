@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// AttachmentError reports a task attachment rejected before the first LLM
+// call: larger than Limits.MaxInputAttachmentBytes, or of a MIME type the
+// agent (WithAttachmentTypes) or its provider does not accept.
+type AttachmentError struct {
+	// Index is the attachment's position in AgentTask.Attachments.
+	Index    int
+	MimeType string
+	Reason   string
+}
+
+func (e *AttachmentError) Error() string {
+	mime := e.MimeType
+	if mime == "" {
+		mime = "no mime type"
+	}
+	return fmt.Sprintf("attachment %d (%s): %s", e.Index, mime, e.Reason)
+}
+
+// validateTaskAttachments checks the caller's attachments against the size
+// cap and the MIME allowlist: cfg.AttachmentTypes when set, otherwise the
+// provider's own list. URL attachments are not size-checked — the bytes never
+// pass through the agent.
+func validateTaskAttachments(cfg *LoopConfig, atts []core.Attachment) error {
+	if len(atts) == 0 {
+		return nil
+	}
+	allowed, source := cfg.AttachmentTypes, "this agent"
+	if len(allowed) == 0 {
+		if p, ok := cfg.Provider.(core.AttachmentTypesProvider); ok {
+			allowed, source = p.AttachmentTypes(), "provider "+cfg.Provider.Name()
+		}
+	}
+	for i, a := range atts {
+		if n := int64(len(a.Data)); cfg.MaxInputAttachmentBytes > 0 && n > cfg.MaxInputAttachmentBytes {
+			return &AttachmentError{Index: i, MimeType: a.MimeType,
+				Reason: fmt.Sprintf("%d bytes exceeds the %d-byte limit", n, cfg.MaxInputAttachmentBytes)}
+		}
+		if len(allowed) > 0 && !core.MIMEAllowed(allowed, a.MimeType) {
+			return &AttachmentError{Index: i, MimeType: a.MimeType,
+				Reason: fmt.Sprintf("type not accepted by %s (allowed: %s)", source, strings.Join(allowed, ", "))}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// typedProvider is a mockProvider that advertises an attachment allowlist.
+type typedProvider struct {
+	*mockProvider
+	types []string
+}
+
+func (p typedProvider) AttachmentTypes() []string { return p.types }
+
+func TestTaskAttachmentValidation(t *testing.T) {
+	png := core.NewAttachment("image/png", []byte("12345678"))
+	zip := core.NewAttachment("application/zip", []byte("PK"))
+
+	tests := []struct {
+		name    string
+		prov    core.Provider
+		opts    []AgentOption
+		atts    []core.Attachment
+		wantErr string // substring; "" = accepted
+	}{
+		{"no limits", &mockProvider{}, nil, []core.Attachment{png, zip}, ""},
+		{"within size", &mockProvider{}, []AgentOption{WithLimits(Limits{MaxInputAttachmentBytes: 8})}, []core.Attachment{png}, ""},
+		{"too large", &mockProvider{}, []AgentOption{WithLimits(Limits{MaxInputAttachmentBytes: 4})}, []core.Attachment{zip, png}, "attachment 1 (image/png): 8 bytes exceeds the 4-byte limit"},
+		{"agent allowlist", &mockProvider{}, []AgentOption{WithAttachmentTypes("image/*")}, []core.Attachment{png, zip}, "not accepted by this agent"},
+		{"provider allowlist", typedProvider{&mockProvider{name: "p"}, []string{"image/png"}}, nil, []core.Attachment{zip}, "not accepted by provider p"},
+		{"agent overrides provider", typedProvider{&mockProvider{}, []string{"image/png"}}, []AgentOption{WithAttachmentTypes("application/zip")}, []core.Attachment{zip}, ""},
+		{"url skips size", &mockProvider{}, []AgentOption{WithLimits(Limits{MaxInputAttachmentBytes: 1})}, []core.Attachment{core.NewAttachmentFromURL("image/png", "https://x/y.png")}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New("a", "d", tt.prov, tt.opts...)
+			_, err := a.Execute(context.Background(), AgentTask{Input: "look", Attachments: tt.atts})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			var ae *AttachmentError
+			if !errors.As(err, &ae) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want *AttachmentError containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaskAttachmentValidationClosesStream(t *testing.T) {
+	a := New("a", "d", &mockProvider{}, WithAttachmentTypes("image/*"))
+	ch := make(chan core.StreamEvent, 8)
+	task := AgentTask{Input: "x", Attachments: []core.Attachment{core.NewAttachment("text/plain", []byte("hi"))}}
+	if _, err := a.Execute(context.Background(), task, core.WithStream(ch)); err == nil {
+		t.Fatal("want error")
+	}
+	for range ch { // blocks forever if the stream was left open
+	}
+}
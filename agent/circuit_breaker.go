@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerWindow    = time.Minute
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// BreakerOption configures CircuitBreakerMiddleware.
+type BreakerOption func(*circuitBreaker)
+
+// BreakerThreshold sets how many consecutive failures open the circuit.
+// Default 5.
+func BreakerThreshold(n int) BreakerOption {
+	return func(b *circuitBreaker) { b.threshold = n }
+}
+
+// BreakerWindow sets the period the consecutive failures must fall within;
+// a streak older than the window starts over. Default 1 minute.
+func BreakerWindow(d time.Duration) BreakerOption {
+	return func(b *circuitBreaker) { b.window = d }
+}
+
+// BreakerCooldown sets how long an open circuit rejects calls before it
+// lets one probe through. Default 30 seconds.
+func BreakerCooldown(d time.Duration) BreakerOption {
+	return func(b *circuitBreaker) { b.cooldown = d }
+}
+
+// CircuitBreakerMiddleware stops calling a tool whose backing service is
+// down. After BreakerThreshold consecutive failures within BreakerWindow
+// the tool's circuit opens: calls return a "temporarily unavailable" error
+// result at once, without running the tool, so the turn stays fast and the
+// model can route around the dependency. After BreakerCooldown the circuit
+// half-opens and lets a single call probe the tool; success closes it, and
+// failure reopens it for another cooldown.
+//
+// A failure is a Go error, which is how tools report infrastructure
+// failures (core.InfraError). A ToolResult.Error is a business outcome,
+// such as "not found", and counts as a success: the tool answered. Any
+// success resets the streak. Calls cut short by the caller's cancellation
+// are not counted. State is kept per tool name and shared by every agent the
+// middleware is installed on.
+func CircuitBreakerMiddleware(opts ...BreakerOption) core.ToolMiddleware {
+	b := &circuitBreaker{
+		threshold: defaultBreakerThreshold,
+		window:    defaultBreakerWindow,
+		cooldown:  defaultBreakerCooldown,
+		states:    make(map[string]*breakerState),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return func(inner core.AnyTool) core.AnyTool {
+		if st, ok := inner.(core.StreamingAnyTool); ok {
+			return &breakerStreamingWrapper{breakerWrapper{inner: inner, b: b}, st}
+		}
+		return &breakerWrapper{inner: inner, b: b}
+	}
+}
+
+// WithCircuitBreaker installs CircuitBreakerMiddleware on every tool of
+// the agent. See CircuitBreakerMiddleware for the semantics.
+func WithCircuitBreaker(opts ...BreakerOption) AgentOption {
+	mw := CircuitBreakerMiddleware(opts...)
+	return func(c *Config) { c.ToolMiddleware = append(c.ToolMiddleware, mw) }
+}
+
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+
+	mu     sync.Mutex
+	states map[string]*breakerState
+}
+
+type breakerState struct {
+	failures   int       // consecutive failures in the current streak
+	streakFrom time.Time // first failure of the streak
+	openUntil  time.Time // zero = closed
+	probing    bool      // half-open: a probe call is in flight
+}
+
+// allow reports whether a call to name may run, or how long the circuit
+// stays open.
+func (b *circuitBreaker) allow(name string) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.states[name]
+	if s == nil || s.openUntil.IsZero() {
+		return true, 0
+	}
+	if now := time.Now(); now.Before(s.openUntil) {
+		return false, s.openUntil.Sub(now)
+	}
+	if s.probing {
+		return false, b.cooldown
+	}
+	s.probing = true
+	return true, 0
+}
+
+// record updates name's circuit with a call's outcome. cancelled calls
+// only release a probe slot.
+func (b *circuitBreaker) record(name string, failed, cancelled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.states[name]
+	if s == nil {
+		s = &breakerState{}
+		b.states[name] = s
+	}
+	probe := s.probing
+	s.probing = false
+	switch {
+	case cancelled:
+	case !failed:
+		*s = breakerState{}
+	case probe:
+		s.openUntil = time.Now().Add(b.cooldown)
+	default:
+		now := time.Now()
+		if s.failures == 0 || now.Sub(s.streakFrom) > b.window {
+			s.failures, s.streakFrom = 0, now
+		}
+		s.failures++
+		if s.failures >= b.threshold {
+			s.failures = 0
+			s.openUntil = now.Add(b.cooldown)
+		}
+	}
+}
+
+func (b *circuitBreaker) run(ctx context.Context, name string, call func() (core.ToolResult, error)) (core.ToolResult, error) {
+	if ok, wait := b.allow(name); !ok {
+		return core.ToolResult{Error: fmt.Sprintf(
+			"%s is temporarily unavailable after repeated failures; retry in %s or continue without it",
+			name, wait.Round(time.Second))}, nil
+	}
+	r, err := call()
+	b.record(name, err != nil, errors.Is(ctx.Err(), context.Canceled))
+	return r, err
+}
+
+type breakerWrapper struct {
+	inner core.AnyTool
+	b     *circuitBreaker
+}
+
+func (w *breakerWrapper) Name() string                    { return w.inner.Name() }
+func (w *breakerWrapper) Definition() core.ToolDefinition { return w.inner.Definition() }
+func (w *breakerWrapper) ExecuteRaw(ctx context.Context, args json.RawMessage) (core.ToolResult, error) {
+	return w.b.run(ctx, w.inner.Name(), func() (core.ToolResult, error) {
+		return w.inner.ExecuteRaw(ctx, args)
+	})
+}
+
+type breakerStreamingWrapper struct {
+	breakerWrapper
+	st core.StreamingAnyTool
+}
+
+func (w *breakerStreamingWrapper) ExecuteStream(ctx context.Context, args json.RawMessage, ch chan<- core.StreamEvent) (core.ToolResult, error) {
+	return w.b.run(ctx, w.inner.Name(), func() (core.ToolResult, error) {
+		return w.st.ExecuteStream(ctx, args, ch)
+	})
+}
+
+// compile-time checks
+var (
+	_ core.AnyTool          = (*breakerWrapper)(nil)
+	_ core.StreamingAnyTool = (*breakerStreamingWrapper)(nil)
+)
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// flakyTool fails while down is set and counts the calls that reach it.
+type flakyTool struct {
+	name  string
+	down  bool
+	calls int
+}
+
+func (f *flakyTool) Name() string                    { return f.name }
+func (f *flakyTool) Definition() core.ToolDefinition { return core.ToolDefinition{Name: f.name} }
+func (f *flakyTool) ExecuteRaw(context.Context, json.RawMessage) (core.ToolResult, error) {
+	f.calls++
+	if f.down {
+		return core.ToolResult{}, errors.New("connection refused")
+	}
+	return core.ToolResult{Content: "ok"}, nil
+}
+
+func TestCircuitBreakerMiddleware(t *testing.T) {
+	ctx := context.Background()
+	mw := CircuitBreakerMiddleware(BreakerThreshold(2), BreakerCooldown(30*time.Millisecond))
+	search := &flakyTool{name: "search", down: true}
+	other := &flakyTool{name: "other"}
+	tool, otherTool := mw(search), mw(other)
+
+	// Two consecutive failures open the circuit.
+	for range 2 {
+		if _, err := tool.ExecuteRaw(ctx, nil); err == nil {
+			t.Fatal("want the tool's error while the circuit is closed")
+		}
+	}
+	r, err := tool.ExecuteRaw(ctx, nil)
+	if err != nil || !strings.Contains(r.Error, "temporarily unavailable") || search.calls != 2 {
+		t.Fatalf("open circuit: result = %+v, err = %v, calls = %d; want a fast unavailable result", r, err, search.calls)
+	}
+
+	// State is per tool name.
+	if r, err := otherTool.ExecuteRaw(ctx, nil); err != nil || r.Content != "ok" {
+		t.Fatalf("other tool: result = %+v, err = %v", r, err)
+	}
+
+	// After the cooldown one probe runs; its failure reopens the circuit.
+	time.Sleep(40 * time.Millisecond)
+	if _, err := tool.ExecuteRaw(ctx, nil); err == nil || search.calls != 3 {
+		t.Fatalf("probe: err = %v, calls = %d; want the probe to reach the tool", err, search.calls)
+	}
+	if r, _ := tool.ExecuteRaw(ctx, nil); r.Error == "" || search.calls != 3 {
+		t.Fatalf("after failed probe: result = %+v, calls = %d; want the circuit reopened", r, search.calls)
+	}
+
+	// A successful probe closes it.
+	time.Sleep(40 * time.Millisecond)
+	search.down = false
+	for range 2 {
+		if r, err := tool.ExecuteRaw(ctx, nil); err != nil || r.Content != "ok" {
+			t.Fatalf("recovered: result = %+v, err = %v", r, err)
+		}
+	}
+	if search.calls != 5 {
+		t.Errorf("calls = %d, want 5", search.calls)
+	}
+}
+
+// notFoundTool reports a business error in its result, never a Go error.
+type notFoundTool struct{ calls int }
+
+func (*notFoundTool) Name() string                    { return "lookup" }
+func (*notFoundTool) Definition() core.ToolDefinition { return core.ToolDefinition{Name: "lookup"} }
+func (n *notFoundTool) ExecuteRaw(context.Context, json.RawMessage) (core.ToolResult, error) {
+	n.calls++
+	return core.ToolResult{Error: "order not found"}, nil
+}
+
+func TestCircuitBreakerMiddleware_IgnoresResultErrors(t *testing.T) {
+	lookup := &notFoundTool{}
+	tool := CircuitBreakerMiddleware(BreakerThreshold(2))(lookup)
+	for range 4 {
+		if r, _ := tool.ExecuteRaw(context.Background(), nil); r.Error != "order not found" {
+			t.Fatalf("result = %+v, want the tool's own error", r)
+		}
+	}
+	if lookup.calls != 4 {
+		t.Errorf("calls = %d, want 4: result errors must not open the circuit", lookup.calls)
+	}
+}
@@ -23,7 +23,7 @@ func toolResultToDispatch(result core.ToolResult, err error) DispatchResult {
 	if result.Error != "" {
 		return DispatchResult{Content: "error: " + result.Error, IsError: true}
 	}
-	return DispatchResult{Content: result.Content, Attachments: result.Attachments, UI: result.UI}
+	return DispatchResult{Content: result.Content, Attachments: result.Attachments, UI: result.UI, Sources: result.Sources}
 }
 
 // DispatchTool executes a tool via the given executor and converts the result
@@ -70,10 +70,24 @@ type StandardDispatchConfig struct {
 	// Logger is used to emit a one-time warning when a streaming tool
 	// has a policy registered. nil = no logging.
 	Logger *slog.Logger
+	// DryRun reports whether a call to the named tool is recorded instead of
+	// dispatched. nil = execute everything. ask_user and execute_plan are
+	// always dispatched; execute_plan's steps are checked individually.
+	DryRun func(name string) bool
+}
+
+// dryRunResult is the synthetic result the model sees for a call skipped in
+// dry-run mode.
+func dryRunResult(name string) DispatchResult {
+	return DispatchResult{
+		Content: fmt.Sprintf("dry run — not executed. The call to %s was recorded for review. "+
+			"Continue planning the remaining steps as if it had succeeded.", name),
+		DryRun: true,
+	}
 }
 
 // NewStandardDispatch builds the recursive DispatchFunc.
-// Order: Builtins → AgentRouter → (policy/streaming) → DispatchTool.
+// Order: DryRun → Builtins → AgentRouter → (policy/streaming) → DispatchTool.
 //
 // Stability: runtime-integration export shared with the network subpackage;
 // excluded from the v1.x compatibility promise (may change or move to internal).
@@ -84,6 +98,9 @@ func NewStandardDispatch(cfg StandardDispatchConfig) DispatchFunc {
 
 	var dispatch DispatchFunc
 	dispatch = func(ctx context.Context, tc core.ToolCall) DispatchResult {
+		if cfg.DryRun != nil && tc.Name != core.ToolAskUser && tc.Name != core.ToolExecutePlan && cfg.DryRun(tc.Name) {
+			return dryRunResult(tc.Name)
+		}
 		if cfg.Builtins != nil {
 			if r, ok := cfg.Builtins(ctx, tc, dispatch); ok {
 				return r
@@ -136,6 +153,9 @@ type toolExecResult struct {
 	duration    time.Duration
 	isError     bool
 	ui          *core.UIComponent
+	handoff     *core.Handoff
+	dryRun      bool
+	sources     []core.Source
 }
 
 // indexedResult pairs a tool execution result with its position in the
@@ -148,16 +168,49 @@ type indexedResult struct {
 // safeDispatch wraps a dispatch call with panic recovery. If the dispatched
 // tool panics, the panic is caught and converted to an error result instead
 // of crashing the process. Matches the recovery pattern used for subagent
-// dispatch in Network.makeDispatch.
+// dispatch in Network.makeDispatch. It also puts the call's idempotency key
+// on ctx (see core.IdempotencyKeyFromContext).
 func safeDispatch(ctx context.Context, tc core.ToolCall, dispatch DispatchFunc) (dr DispatchResult) {
 	defer func() {
 		if p := recover(); p != nil {
 			dr = DispatchResult{Content: fmt.Sprintf("error: tool %q panic: %v", tc.Name, p), IsError: true}
 		}
 	}()
+	if tc.ID != "" {
+		var scope string
+		if task, ok := TaskFromContext(ctx); ok {
+			scope = task.ThreadID
+			if scope == "" && task.UserID != "" {
+				scope = "user:" + task.UserID
+			}
+		}
+		ctx = core.WithIdempotencyKey(ctx, core.ToolCallIdempotencyKey(scope, tc.ID))
+	}
 	return dispatch(ctx, tc)
 }
 
+// execTool runs tc through safeDispatch and returns its result, timed.
+func execTool(ctx context.Context, tc core.ToolCall, dispatch DispatchFunc) toolExecResult {
+	start := time.Now()
+	dr := safeDispatch(ctx, tc, dispatch)
+	return toolExecResult{content: dr.Content, usage: dr.Usage, attachments: dr.Attachments, duration: time.Since(start), isError: dr.IsError, ui: dr.UI, handoff: dr.Handoff, dryRun: dr.DryRun, sources: dr.Sources}
+}
+
+// dispatchSequential runs tool calls one at a time, in order, on the calling
+// goroutine. Once ctx is cancelled the remaining calls are not started and
+// get context-error results.
+func dispatchSequential(ctx context.Context, calls []core.ToolCall, dispatch DispatchFunc) []toolExecResult {
+	results := make([]toolExecResult, len(calls))
+	for i, tc := range calls {
+		if ctx.Err() != nil {
+			results[i] = toolExecResult{content: "error: " + ctx.Err().Error(), isError: true}
+			continue
+		}
+		results[i] = execTool(ctx, tc, dispatch)
+	}
+	return results
+}
+
 // dispatchParallel runs all tool calls concurrently via the dispatch function
 // and returns results in the same order as the input calls.
 // Single calls run inline (no goroutine). Multiple calls use a fixed worker
@@ -170,9 +223,7 @@ func safeDispatch(ctx context.Context, tc core.ToolCall, dispatch DispatchFunc)
 func dispatchParallel(ctx context.Context, calls []core.ToolCall, dispatch DispatchFunc, maxWorkers int) []toolExecResult {
 	// Fast path: single call, no goroutine needed.
 	if len(calls) == 1 {
-		start := time.Now()
-		dr := safeDispatch(ctx, calls[0], dispatch)
-		return []toolExecResult{{content: dr.Content, usage: dr.Usage, attachments: dr.Attachments, duration: time.Since(start), isError: dr.IsError, ui: dr.UI}}
+		return []toolExecResult{execTool(ctx, calls[0], dispatch)}
 	}
 
 	resultCh := make(chan indexedResult, len(calls))
@@ -200,9 +251,7 @@ func dispatchParallel(ctx context.Context, calls []core.ToolCall, dispatch Dispa
 					resultCh <- indexedResult{w.idx, toolExecResult{content: "error: " + ctx.Err().Error(), isError: true}}
 					continue
 				}
-				start := time.Now()
-				dr := safeDispatch(ctx, w.tc, dispatch)
-				resultCh <- indexedResult{w.idx, toolExecResult{content: dr.Content, usage: dr.Usage, attachments: dr.Attachments, duration: time.Since(start), isError: dr.IsError, ui: dr.UI}}
+				resultCh <- indexedResult{w.idx, execTool(ctx, w.tc, dispatch)}
 			}
 		}()
 	}
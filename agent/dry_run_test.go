@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+func TestDryRun(t *testing.T) {
+	var lastReq core.ChatRequest
+	newProvider := func() *mockProvider {
+		return &mockProvider{
+			name: "test",
+			responses: []core.ChatResponse{
+				{ToolCalls: []core.ToolCall{
+					{ID: "1", Name: "read", Args: json.RawMessage(`{"path":"a.txt"}`)},
+					{ID: "2", Name: "rec", Args: json.RawMessage(`{"path":"a.txt"}`)},
+				}},
+				{Content: "I would overwrite a.txt."},
+			},
+			onChat: func(req *core.ChatRequest) { lastReq = *req },
+		}
+	}
+
+	// Only the named tool is skipped; the read still runs.
+	called := false
+	a := New("planner", "plans", newProvider(),
+		WithTools(readTool{}, &recordingTool{called: &called}),
+		WithDryRun("rec"))
+	result, err := a.Execute(context.Background(), AgentTask{Input: "update a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Error("dry-run tool was executed")
+	}
+	if !result.DryRun {
+		t.Error("result.DryRun = false, want true")
+	}
+	if len(result.Steps) != 2 || result.Steps[0].DryRun || !result.Steps[1].DryRun {
+		t.Fatalf("steps = %+v, want read executed and rec recorded as dry run", result.Steps)
+	}
+	if s := result.Steps[1]; s.Name != "rec" || string(s.RawArgs) != `{"path":"a.txt"}` {
+		t.Errorf("dry-run step = %+v, want the planned call and its args", s)
+	}
+	var toolResults []string
+	for _, m := range lastReq.Messages {
+		if m.Role == core.RoleTool {
+			toolResults = append(toolResults, m.Content)
+		}
+	}
+	if len(toolResults) != 2 || toolResults[0] != "did read" || !strings.HasPrefix(toolResults[1], "dry run — not executed") {
+		t.Errorf("tool results sent to the model = %q", toolResults)
+	}
+
+	// Without tool names every call is skipped.
+	a = New("planner", "plans", newProvider(), WithTools(readTool{}, &recordingTool{called: &called}), WithDryRun())
+	if result, err = a.Execute(context.Background(), AgentTask{Input: "update a.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range result.Steps {
+		if !s.DryRun {
+			t.Errorf("step %s executed, want every call recorded as dry run", s.Name)
+		}
+	}
+
+	// Off by default.
+	a = New("planner", "plans", newProvider(), WithTools(readTool{}, &recordingTool{called: &called}))
+	if result, err = a.Execute(context.Background(), AgentTask{Input: "update a.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if result.DryRun || !called {
+		t.Errorf("DryRun = %v, tool called = %v; want a normal run", result.DryRun, called)
+	}
+}
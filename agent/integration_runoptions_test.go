@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -272,3 +273,41 @@ func TestExecuteWith_PopulatesSources(t *testing.T) {
 		t.Errorf("Sources[0].URL: want https://example.com, got %q", result.Sources[0].URL)
 	}
 }
+
+// resultSourcedTool attaches sources to each ToolResult rather than
+// implementing core.Sourced on the tool.
+type resultSourcedTool struct{}
+
+func (resultSourcedTool) Name() string { return "lookup" }
+func (resultSourcedTool) Definition() core.ToolDefinition {
+	return core.ToolDefinition{Name: "lookup", Description: "lookup", Parameters: []byte(`{"type":"object"}`)}
+}
+func (resultSourcedTool) ExecuteRaw(_ context.Context, args json.RawMessage) (core.ToolResult, error) {
+	return core.ToolResult{Content: "found", Sources: []core.Source{{URL: "doc://" + string(args), Origin: "rag"}}}, nil
+}
+
+func TestExecute_PropagatesToolResultSources(t *testing.T) {
+	provider := &scriptedProvider{
+		responses: []core.ChatResponse{
+			{ToolCalls: []core.ToolCall{
+				{ID: "1", Name: "lookup", Args: []byte(`"a"`)},
+				{ID: "2", Name: "lookup", Args: []byte(`"b"`)},
+			}},
+			{ToolCalls: []core.ToolCall{{ID: "3", Name: core.ToolExecutePlan,
+				Args: []byte(`{"steps":[{"tool":"lookup","args":"c"}]}`)}}},
+			{Content: "done"},
+		},
+	}
+	a := New("a", "d", provider, WithTools(resultSourcedTool{}), WithPlanExecution())
+	result, err := a.Execute(context.Background(), AgentTask{Input: "q"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, s := range result.Sources {
+		urls = append(urls, s.URL)
+	}
+	if want := []string{`doc://"a"`, `doc://"b"`, `doc://"c"`}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("Sources URLs = %v, want %v", urls, want)
+	}
+}
@@ -42,6 +42,17 @@ type loopState struct {
 	iterations []core.IterationTrace
 
 	sources []core.Source
+
+	// schemaRetries counts re-prompts spent on ResponseSchema violations.
+	schemaRetries int
+
+	// continuations counts AutoContinue turns; continuedOutput holds the
+	// length-truncated text they extend.
+	continuations   int
+	continuedOutput string
+
+	// dryRun mirrors Config.DryRun onto the result.
+	dryRun bool
 }
 
 var loopStatePool = sync.Pool{New: func() any { return new(loopState) }}
@@ -62,7 +73,6 @@ func releaseLoopState(s *loopState) {
 	s.totalUsage = core.Usage{}
 	s.lastAgentOutput = ""
 	s.lastThinking = ""
-	s.accumulatedAttachments = s.accumulatedAttachments[:0]
 	s.accumulatedAttachmentBytes = 0
 	s.attachByteBudget = 0
 	s.hasAgentTools = false
@@ -71,9 +81,15 @@ func releaseLoopState(s *loopState) {
 	s.closeCh = nil
 	s.lastProviderMeta = nil
 	s.messageRuneCount = 0
+	s.schemaRetries = 0
+	s.continuations = 0
+	s.continuedOutput = ""
+	s.dryRun = false
 
 	// Why: steps, lastWarnings, files, iterations and sources are assigned
-	// directly into the returned AgentResult by patchTerminal (no copy). If we
+	// directly into the returned AgentResult by patchTerminal (no copy), and
+	// accumulatedAttachments by mergeAttachments when the final response has
+	// none of its own. If we
 	// truncated to [:0] and kept the backing array in the pool, the next Execute
 	// in this process would append into those same arrays and silently corrupt
 	// the previously returned result. Nil-ing transfers ownership of the escaped
@@ -88,6 +104,7 @@ func releaseLoopState(s *loopState) {
 	// AgentResult owns (+1 alloc, ~176 B on SingleTurn). That allocation is
 	// load-bearing — AgentResult.Iterations must point at memory the pool can
 	// never reuse — and is the intended price of the fix.
+	s.accumulatedAttachments = nil
 	s.steps = nil
 	s.lastWarnings = nil
 	s.files = nil
@@ -116,6 +133,7 @@ func (s *loopState) patchTerminal(r *AgentResult, reason core.FinishReason) {
 	r.Files = s.files
 	r.Iterations = s.iterations
 	r.Sources = s.sources
+	r.DryRun = s.dryRun
 }
 
 // applyPromptCacheMarkers stamps cache-breakpoint flags on the message slice
@@ -266,6 +284,9 @@ func runIteration(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<
 			req.Tools = defs
 		}
 	}
+	if cfg.ToolCallFallback && len(req.Tools) > 0 && !supportsTools(iterProvider) {
+		iterProvider = toolCallFallback{inner: iterProvider}
+	}
 
 	// Why: placed after RunPreLLM and PrepareStep so any message-list mutations
 	// (e.g. processors appending guardrail messages, hooks rewriting tool defs)
@@ -348,10 +369,51 @@ func runIteration(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<
 		if cfg.Logger.Enabled(ctx, slog.LevelDebug) {
 			cfg.Logger.Debug("final response (no tool calls)", "agent", cfg.Name, "iteration", i)
 		}
+		if cfg.AutoContinue > 0 && cfg.ResponseSchema == nil && resp.FinishReason == core.FinishLength &&
+			resp.Content != "" && state.continuations < cfg.AutoContinue {
+			state.continuations++
+			cfg.Logger.Info("final response hit the length limit, continuing", "agent", cfg.Name, "continuation", state.continuations)
+			if ch != nil && !streamedThisIter {
+				select {
+				case ch <- core.StreamEvent{Type: core.EventTextDelta, Content: resp.Content}:
+				case <-ctx.Done():
+				}
+			}
+			state.continuedOutput += resp.Content
+			state.messages = append(state.messages, core.AssistantMessage(resp.Content), core.UserMessage(continuePrompt))
+			if state.compressThreshold > 0 {
+				state.messageRuneCount += utf8.RuneCountInString(resp.Content) + utf8.RuneCountInString(continuePrompt)
+			}
+			endIteration(ep, core.FinishLength)
+			return iterationResult{outcome: iterContinue}
+		}
 		content := resp.Content
+		if state.continuedOutput != "" {
+			content = state.continuedOutput + content
+		}
 		if content == "" {
 			content = state.lastAgentOutput
 		}
+		if cfg.ResponseSchema != nil && cfg.ResponseSchemaRetries > 0 {
+			if errs := validateResponseSchema(cfg.ResponseSchema, content); len(errs) > 0 {
+				if state.schemaRetries >= cfg.ResponseSchemaRetries {
+					cfg.Logger.Warn("response schema validation failed, retries exhausted", "agent", cfg.Name, "retries", state.schemaRetries, "errors", errs)
+					endIteration(ep, core.FinishError)
+					return terminateIteration(ctx, cfg, task, ch, state, core.FinishError,
+						AgentResult{Output: content, Thinking: state.lastThinking},
+						&ErrSchemaValidation{Output: content, Errors: errs})
+				}
+				state.schemaRetries++
+				cfg.Logger.Info("response schema validation failed, retrying", "agent", cfg.Name, "attempt", state.schemaRetries, "errors", errs)
+				retry := schemaRetryMessage(errs)
+				state.messages = append(state.messages, core.AssistantMessage(content), retry)
+				if state.compressThreshold > 0 {
+					state.messageRuneCount += utf8.RuneCountInString(content) + utf8.RuneCountInString(retry.Content)
+				}
+				endIteration(ep, core.FinishStop)
+				return iterationResult{outcome: iterContinue}
+			}
+		}
 		if ch != nil && !streamedThisIter {
 			select {
 			case ch <- core.StreamEvent{Type: core.EventTextDelta, Content: content}:
@@ -406,16 +468,20 @@ func runIteration(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<
 			// Continue: fall through to natural iterDone.
 		}
 
-		endIteration(ep, core.FinishStop)
+		reason := finalFinishReason(resp.FinishReason)
+		if reason == core.FinishLength || reason == core.FinishContentFilter {
+			cfg.Logger.Warn("final response incomplete", "agent", cfg.Name, "iteration", i, "finish_reason", reason)
+		}
+		endIteration(ep, reason)
 		cfg.Mem.PersistTurn(iterCtx, cfg.Name, task, task.Input, content, state.steps)
 		result := AgentResult{
 			Output:      content,
 			Thinking:    state.lastThinking,
 			Attachments: mergeAttachments(state.accumulatedAttachments, resp.Attachments),
 		}
-		state.patchTerminal(&result, core.FinishStop)
+		state.patchTerminal(&result, reason)
 		emitObjectFinish(ctx, ch, cfg.ResponseSchema, content, &result)
-		finalizeRun(ctx, ch, state, cfg.Name, core.FinishStop, result)
+		finalizeRun(ctx, ch, state, cfg.Name, reason, result)
 		return iterationResult{
 			outcome: iterDone,
 			final:   result,
@@ -479,10 +545,11 @@ func runIteration(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<
 			}
 		}
 
-		var agents, directTools []string
+		var agents, tasks, directTools []string
 		for _, tc := range resp.ToolCalls {
 			if after, ok := strings.CutPrefix(tc.Name, core.ToolPrefixAgent); ok {
 				agents = append(agents, after)
+				tasks = append(tasks, delegatedTask(tc.Args))
 			} else {
 				directTools = append(directTools, tc.Name)
 			}
@@ -492,7 +559,7 @@ func runIteration(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<
 			case ch <- core.StreamEvent{
 				Type:    core.EventRoutingDecision,
 				Name:    cfg.Name,
-				Content: buildRoutingSummary(agents, directTools),
+				Content: buildRoutingSummary(agents, directTools, tasks, strings.TrimSpace(resp.Content)),
 			}:
 			case <-ctx.Done():
 			}
@@ -506,7 +573,12 @@ func runIteration(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<
 	fileSinkCh, waitFileSink := newFileCapturingSink(ctx, ch, state)
 	iterCtx = contextWithStreamSink(iterCtx, fileSinkCh)
 	dispatchStart := time.Now()
-	results := dispatchParallel(iterCtx, resp.ToolCalls, cfg.Dispatch, cfg.MaxParallelDispatch)
+	var results []toolExecResult
+	if cfg.SequentialTools {
+		results = dispatchSequential(iterCtx, resp.ToolCalls, cfg.Dispatch)
+	} else {
+		results = dispatchParallel(iterCtx, resp.ToolCalls, cfg.Dispatch, cfg.MaxParallelDispatch)
+	}
 	if cfg.Logger.Enabled(ctx, slog.LevelDebug) {
 		cfg.Logger.Debug("tool dispatch completed", "agent", cfg.Name, "iteration", i, "duration", time.Since(dispatchStart))
 	}
@@ -718,7 +790,10 @@ func runIteration(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<
 			state.lastAgentOutput = content
 		}
 
-		// Collect citations.
+		// Collect citations: those on the result, then the tool's own.
+		if !results[j].isError {
+			state.sources = append(state.sources, results[j].sources...)
+		}
 		if !results[j].isError && cfg.LookupTool != nil {
 			if t, ok := cfg.LookupTool(tc.Name); ok {
 				if sourced, ok := t.(core.Sourced); ok {
@@ -727,8 +802,9 @@ func runIteration(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<
 			}
 		}
 	}
-	// Compress context if over budget.
-	if state.compressThreshold > 0 && state.messageRuneCount > state.compressThreshold {
+	// Compress context if over budget. Skipped once the token budget is spent:
+	// the next iteration stops the run without another model call.
+	if state.compressThreshold > 0 && state.messageRuneCount > state.compressThreshold && !overTokenBudget(cfg, state) {
 		if cfg.Logger.Enabled(ctx, slog.LevelInfo) {
 			cfg.Logger.Info("context compression triggered", "agent", cfg.Name, "iteration", i, "runes", state.messageRuneCount, "threshold", state.compressThreshold)
 		}
@@ -1025,6 +1101,23 @@ type iterEndParams struct {
 	llmCalled bool
 }
 
+// continuePrompt is the user turn AutoContinue sends after a response cut
+// off by the output-token limit.
+const continuePrompt = "Your previous response was cut off by the output length limit. Continue exactly where it stopped, without repeating any of it."
+
+// finalFinishReason maps the model's finish reason on the final response to
+// the run's. A reply cut off by the output-token limit or a content filter,
+// or ended by a stop sequence, keeps the provider's reason so callers can
+// tell it from a plain stop; anything else (including none) is a natural
+// stop.
+func finalFinishReason(model core.FinishReason) core.FinishReason {
+	switch model {
+	case core.FinishLength, core.FinishContentFilter, core.FinishStopSequence:
+		return model
+	}
+	return core.FinishStop
+}
+
 // endIteration finalizes one agent loop iteration: records the IterationTrace
 // when an LLM was called, ends the tracing span, and emits EventIterationFinish
 // on the stream channel.
@@ -147,8 +147,17 @@ func (a *LLMAgent) buildLoopConfig(ctx context.Context, task AgentTask, ch chan<
 		toolDefs = append(append(defs, toolDefs...), BuildTaskToolDef(cfg.TaskRoster, cfg.SelfCloneMax > 0, cfg.SelfCloneMax))
 	}
 
+	// Why: the cached defs only know the construction-time InputHandler. A
+	// per-call handler (e.g. one backed by a WebSocket) on an agent built
+	// without one must still advertise ask_user for this run.
+	perCallInput := opts != nil && opts.InputHandler != nil
+	if perCallInput && a.InputHandler == nil {
+		defs := make([]core.ToolDefinition, 0, len(toolDefs)+1)
+		toolDefs = append(append(defs, toolDefs...), askDef)
+	}
+
 	var dispatch DispatchFunc
-	if ch == nil && !a.HasDynamicTools() && len(cfg.ToolPolicies) == 0 && len(cfg.ToolPolicyMatchers) == 0 {
+	if ch == nil && !perCallInput && !a.HasDynamicTools() && len(cfg.ToolPolicies) == 0 && len(cfg.ToolPolicyMatchers) == 0 && cfg.ToolTimeout == 0 && cfg.ToolRetries == 0 {
 		a.cachedNonStreamDispatchOnce.Do(func() {
 			a.cachedNonStreamDispatch = a.makeDispatch(executeTool, executeToolStream, nil, toolDefs, isStreamingTool, cfg)
 		})
@@ -179,6 +188,15 @@ func (a *LLMAgent) makeDispatch(executeTool ToolExecFunc, executeToolStream Tool
 				return res, true
 			}
 		}
+		// cfg carries any per-call InputHandler override; it falls back to
+		// the agent-level handler when no override is set.
+		if tc.Name == core.ToolAskUser && cfg.InputHandler != nil {
+			content, err := executeAskUser(ctx, cfg.InputHandler, a.Name(), tc)
+			if err != nil {
+				return DispatchResult{Content: "error: " + err.Error(), IsError: true}, true
+			}
+			return DispatchResult{Content: content}, true
+		}
 		return a.DispatchBuiltins(ctx, tc, dispatch, executeAskUser, executePlan)
 	}
 	return NewStandardDispatch(StandardDispatchConfig{
@@ -190,6 +208,7 @@ func (a *LLMAgent) makeDispatch(executeTool ToolExecFunc, executeToolStream Tool
 		ResolvePolicy:     cfg.ResolveToolPolicy,
 		IsStreamingTool:   isStreamingTool,
 		Logger:            cfg.Logger,
+		DryRun:            cfg.IsDryRun,
 	})
 }
 
@@ -302,6 +321,7 @@ func executePlan(ctx context.Context, args json.RawMessage, dispatch DispatchFun
 	// Aggregate results.
 	var totalUsage core.Usage
 	var allAttachments []core.Attachment
+	var allSources []core.Source
 	stepResults := make([]planStepResult, len(params.Steps))
 	for i, step := range params.Steps {
 		totalUsage.InputTokens += results[i].usage.InputTokens
@@ -310,6 +330,9 @@ func executePlan(ctx context.Context, args json.RawMessage, dispatch DispatchFun
 		if len(results[i].attachments) > 0 {
 			allAttachments = append(allAttachments, results[i].attachments...)
 		}
+		if !results[i].isError {
+			allSources = append(allSources, results[i].sources...)
+		}
 
 		sr := planStepResult{Step: i, Tool: step.Tool, Status: "ok", Result: results[i].content}
 		if results[i].isError {
@@ -321,7 +344,7 @@ func executePlan(ctx context.Context, args json.RawMessage, dispatch DispatchFun
 	}
 
 	out, _ := json.Marshal(stepResults)
-	return DispatchResult{Content: string(out), Usage: totalUsage, Attachments: allAttachments}
+	return DispatchResult{Content: string(out), Usage: totalUsage, Attachments: allAttachments, Sources: allSources}
 }
 
 // --- ask_user tool ---
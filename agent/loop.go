@@ -69,6 +69,14 @@ func runLoop(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<- cor
 	if len(cfg.ResumeMessages) > 0 {
 		messages = cfg.ResumeMessages
 	} else {
+		// Reject oversized or unsupported task attachments here, with a clear
+		// error, rather than letting the provider fail with a bare 400.
+		if err := validateTaskAttachments(cfg, task.Attachments); err != nil {
+			if ch != nil {
+				close(ch)
+			}
+			return AgentResult{}, err
+		}
 		initial := cfg.Mem.BuildMessages(ctx, cfg.Name, cfg.SystemPrompt, task)
 		// Why a small constant headroom instead of sizing for MaxIter: a
 		// typical run makes 0–3 tool calls, while MaxIter-proportional
@@ -111,14 +119,23 @@ func runLoop(ctx context.Context, cfg *LoopConfig, task AgentTask, ch chan<- cor
 
 	state := acquireLoopState(messages, messageRuneCount, attachByteBudget, hasAgentTools, cfg.CompressThreshold, ch)
 	defer releaseLoopState(state)
+	state.dryRun = cfg.DryRun
 
 	for i := 0; i < cfg.MaxIter; i++ {
+		if err := checkTokenBudget(cfg, state); err != nil {
+			r := terminateIteration(ctx, cfg, task, ch, state, core.FinishError, AgentResult{}, err)
+			return r.final, r.err
+		}
 		result := runIteration(ctx, cfg, task, ch, state, i)
 		if result.outcome == iterDone {
 			return result.final, result.err
 		}
 	}
 
+	if err := checkTokenBudget(cfg, state); err != nil {
+		r := terminateIteration(ctx, cfg, task, ch, state, core.FinishError, AgentResult{}, err)
+		return r.final, r.err
+	}
 	return forceSynthesis(ctx, cfg, task, ch, state)
 }
 
@@ -94,7 +94,7 @@ func BenchmarkBuildRoutingSummary(b *testing.B) {
 	agents := []string{"researcher", "writer", "reviewer"}
 	tools := []string{"web_search", "file_read"}
 	for range b.N {
-		buildRoutingSummary(agents, tools)
+		buildRoutingSummary(agents, tools, nil, "")
 	}
 }
 
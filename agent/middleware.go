@@ -1,6 +1,15 @@
 package agent
 
-import "github.com/nevindra/oasis/core"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
 
 // Middleware wraps an Agent with extra behavior (logging, retry, tracing).
 // Same shape as provider.Middleware and core.ToolMiddleware — compose with Chain.
@@ -32,3 +41,170 @@ func Chain(mws ...Middleware) Middleware {
 		return a
 	}
 }
+
+// agentFunc adapts an Execute function to core.Agent, keeping the wrapped
+// agent's Name and Description. Built-in middlewares return it.
+type agentFunc struct {
+	core.Agent
+	exec func(ctx context.Context, task AgentTask, opts ...core.RunOption) (AgentResult, error)
+}
+
+func (a agentFunc) Execute(ctx context.Context, task AgentTask, opts ...core.RunOption) (AgentResult, error) {
+	return a.exec(ctx, task, opts...)
+}
+
+// AgentLoggingMiddleware logs agent.start and agent.finish events with the
+// agent name, user and thread IDs, duration, token usage, and error (if any)
+// at slog.LevelInfo. Use logger==nil to install a no-op logger. Streaming is
+// unaffected: the stream channel travels in opts and is passed through.
+func AgentLoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = nopLogger
+	}
+	return func(inner core.Agent) core.Agent {
+		return agentFunc{Agent: inner, exec: func(ctx context.Context, task AgentTask, opts ...core.RunOption) (AgentResult, error) {
+			start := time.Now()
+			logger.Info("agent.start", "name", inner.Name(),
+				"user_id", task.UserID, "thread_id", task.ThreadID, "input_bytes", len(task.Input))
+			res, err := inner.Execute(ctx, task, opts...)
+			attrs := []any{"name", inner.Name(),
+				"duration", time.Since(start),
+				"input_tokens", res.Usage.InputTokens,
+				"output_tokens", res.Usage.OutputTokens,
+				"finish_reason", res.FinishReason}
+			if err != nil {
+				attrs = append(attrs, "error", err)
+			}
+			logger.Info("agent.finish", attrs...)
+			return res, err
+		}}
+	}
+}
+
+// ErrUserRateLimited is returned by UserRateLimitMiddleware and
+// AgentRateLimitMiddleware when a user or chat has no request budget left.
+// Check with errors.Is and answer with a "slow down" message rather than an
+// error.
+var ErrUserRateLimited = errors.New("user rate limit exceeded")
+
+// Limiter decides whether a request for key may proceed now. Allow consumes
+// budget when it returns true. Implementations must be safe for concurrent
+// use. TokenBucketLimiter is the default; plug in a shared store (Redis, etc.)
+// to limit across processes.
+type Limiter interface {
+	Allow(key string) bool
+}
+
+// AgentRateLimitMiddleware consults l before every Execute, keyed by
+// key(task). A nil key uses AgentTask.UserID; pass
+// func(t AgentTask) string { return t.ChatID } to limit per chat. A rejected
+// request fails at once with an error wrapping ErrUserRateLimited: the agent
+// is not called and, when streaming, the stream channel is closed.
+func AgentRateLimitMiddleware(l Limiter, key func(AgentTask) string) Middleware {
+	if key == nil {
+		key = func(t AgentTask) string { return t.UserID }
+	}
+	return func(inner core.Agent) core.Agent {
+		return agentFunc{Agent: inner, exec: func(ctx context.Context, task AgentTask, opts ...core.RunOption) (AgentResult, error) {
+			if k := key(task); !l.Allow(k) {
+				if ch := core.ApplyRunOptions(opts...).Stream; ch != nil {
+					close(ch)
+				}
+				return AgentResult{}, fmt.Errorf("agent %s: %q: %w", inner.Name(), k, ErrUserRateLimited)
+			}
+			return inner.Execute(ctx, task, opts...)
+		}}
+	}
+}
+
+// UserRateLimitMiddleware limits each AgentTask.UserID to perSecond requests
+// per second with bursts of up to burst. Tasks without a UserID share a
+// single bucket. Shorthand for AgentRateLimitMiddleware with a
+// TokenBucketLimiter.
+func UserRateLimitMiddleware(perSecond float64, burst int) Middleware {
+	return AgentRateLimitMiddleware(NewTokenBucketLimiter(perSecond, burst), nil)
+}
+
+// TokenBucketOption configures a TokenBucketLimiter.
+type TokenBucketOption func(*TokenBucketLimiter)
+
+// LimiterClock replaces time.Now as the limiter's clock, so tests can drive
+// refills without sleeping.
+func LimiterClock(now func() time.Time) TokenBucketOption {
+	return func(l *TokenBucketLimiter) { l.now = now }
+}
+
+// TokenBucketLimiter is the default Limiter: one token bucket per key,
+// refilled at a fixed rate. Idle buckets are dropped once refilled, so memory
+// tracks active keys.
+type TokenBucketLimiter struct {
+	rate, burst float64
+	now         func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// NewTokenBucketLimiter allows perSecond requests per second per key, with
+// bursts of up to burst (minimum 1).
+func NewTokenBucketLimiter(perSecond float64, burst int, opts ...TokenBucketOption) *TokenBucketLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &TokenBucketLimiter{
+		rate:    perSecond,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// limiterPruneAt is the bucket count above which full buckets are dropped.
+const limiterPruneAt = 1024
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Allow takes a token from key's bucket, reporting false when it is empty.
+func (l *TokenBucketLimiter) Allow(key string) bool {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= limiterPruneAt {
+			l.prune(now)
+		}
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.refill(now, l.rate, l.burst)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// prune drops buckets that have refilled to capacity; recreating one later
+// starts it full, which is the same state.
+func (l *TokenBucketLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if b.refill(now, l.rate, l.burst); b.tokens >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time, rate, burst float64) {
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+}
+
+var _ Limiter = (*TokenBucketLimiter)(nil)
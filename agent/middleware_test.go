@@ -1,9 +1,14 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"log/slog"
+	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/nevindra/oasis/core"
 )
@@ -166,3 +171,96 @@ func TestWithMiddleware_CalledOnEachExecute(t *testing.T) {
 		t.Errorf("middleware called %d times across 2 Execute calls, want 2", calls.Load())
 	}
 }
+
+func TestAgentLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	base := &stubAgent{name: "base", desc: "d", fn: func(AgentTask) (AgentResult, error) {
+		return AgentResult{Output: "ok", Usage: core.Usage{InputTokens: 3, OutputTokens: 4}}, nil
+	}}
+	a := AgentLoggingMiddleware(logger)(base)
+	if a.Name() != "base" || a.Description() != "d" {
+		t.Errorf("identity not preserved: %q %q", a.Name(), a.Description())
+	}
+	if res, err := a.Execute(context.Background(), AgentTask{Input: "hi", UserID: "u1"}); err != nil || res.Output != "ok" {
+		t.Fatalf("Execute = %+v, %v", res, err)
+	}
+	out := buf.String()
+	for _, want := range []string{"msg=agent.start", "user_id=u1", "msg=agent.finish", "input_tokens=3", "output_tokens=4"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestUserRateLimitMiddleware(t *testing.T) {
+	var calls int
+	base := &stubAgent{name: "base", fn: func(AgentTask) (AgentResult, error) { calls++; return AgentResult{}, nil }}
+	a := UserRateLimitMiddleware(0.001, 2)(base)
+	ctx := context.Background()
+
+	for i := range 2 {
+		if _, err := a.Execute(ctx, AgentTask{UserID: "alice"}); err != nil {
+			t.Fatalf("call %d within burst: %v", i, err)
+		}
+	}
+	ch := make(chan core.StreamEvent, 1)
+	_, err := a.Execute(ctx, AgentTask{UserID: "alice"}, core.WithStream(ch))
+	if !errors.Is(err, ErrUserRateLimited) {
+		t.Fatalf("err = %v, want ErrUserRateLimited", err)
+	}
+	if _, open := <-ch; open {
+		t.Error("stream left open on a rejected call")
+	}
+	// Buckets are per user.
+	if _, err := a.Execute(ctx, AgentTask{UserID: "bob"}); err != nil {
+		t.Errorf("other user limited: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("agent called %d times, want 3", calls)
+	}
+}
+
+func TestTokenBucketLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewTokenBucketLimiter(2, 1, LimiterClock(func() time.Time { return now }))
+	if !l.Allow("u") || l.Allow("u") {
+		t.Fatal("burst of 1 not enforced")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if !l.Allow("u") {
+		t.Error("bucket did not refill at 2/s")
+	}
+	l.prune(now.Add(time.Hour))
+	if len(l.buckets) != 0 {
+		t.Errorf("full buckets not pruned: %d left", len(l.buckets))
+	}
+}
+
+// denyLimiter rejects every key except allowed and records what it saw.
+type denyLimiter struct {
+	allowed string
+	seen    []string
+}
+
+func (d *denyLimiter) Allow(key string) bool {
+	d.seen = append(d.seen, key)
+	return key == d.allowed
+}
+
+func TestAgentRateLimitMiddleware_CustomLimiterAndKey(t *testing.T) {
+	base := &stubAgent{name: "base", fn: func(AgentTask) (AgentResult, error) { return AgentResult{Output: "ok"}, nil }}
+	l := &denyLimiter{allowed: "chat-1"}
+	a := AgentRateLimitMiddleware(l, func(t AgentTask) string { return t.ChatID })(base)
+	ctx := context.Background()
+
+	if r, err := a.Execute(ctx, AgentTask{UserID: "alice", ChatID: "chat-1"}); err != nil || r.Output != "ok" {
+		t.Fatalf("allowed chat: result = %+v, err = %v", r, err)
+	}
+	if _, err := a.Execute(ctx, AgentTask{UserID: "alice", ChatID: "chat-2"}); !errors.Is(err, ErrUserRateLimited) {
+		t.Errorf("err = %v, want ErrUserRateLimited", err)
+	}
+	if len(l.seen) != 2 || l.seen[1] != "chat-2" {
+		t.Errorf("limiter keys = %v, want chat IDs", l.seen)
+	}
+}
@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// WithResponseCache caches the agent's final answers in cache for ttl. key
+// derives the cache key from a task; nil uses ResponseCacheKey, and an empty
+// key skips the cache for that task. A hit returns the stored answer without
+// calling the LLM, tools, or memory; when streaming, it is replayed as text
+// deltas.
+//
+// Only turns that finish with FinishStop are stored, and never a turn that
+// ran a nondeterministic tool: a tool whose Cacheable() is false, or one that
+// called core.MarkResponseUncacheable. The default key includes UserID but
+// not conversation history, so an agent configured WithMemory is not cached
+// unless key is set: its answers depend on the thread, and a hit would never
+// reach thread history. A custom key for such an agent should include
+// ThreadID, and accepts that hits are not persisted.
+func WithResponseCache(cache core.ToolCache, ttl time.Duration, key func(AgentTask) string) AgentOption {
+	return func(c *Config) {
+		// c is read on each call, after every option has been applied.
+		memoryOn := func() bool { return c.MemoryInitialized }
+		c.AgentMiddleware = append(c.AgentMiddleware, responseCacheMiddleware(cache, ttl, key, memoryOn))
+		// Innermost, so the tool's own Cacheable method is still visible.
+		c.ToolMiddleware = append([]core.ToolMiddleware{markUncacheableMiddleware}, c.ToolMiddleware...)
+	}
+}
+
+// ResponseCacheMiddleware is the agent middleware behind WithResponseCache.
+// Used on its own, only tools that call core.MarkResponseUncacheable poison
+// a turn; WithResponseCache also marks tools whose Cacheable() is false. It
+// cannot see whether the agent has memory, so pass a key that includes
+// ThreadID for agents that do.
+func ResponseCacheMiddleware(cache core.ToolCache, ttl time.Duration, key func(AgentTask) string) Middleware {
+	return responseCacheMiddleware(cache, ttl, key, nil)
+}
+
+// responseCacheMiddleware is ResponseCacheMiddleware; when memoryOn reports
+// true and key is nil, the cache is skipped.
+func responseCacheMiddleware(cache core.ToolCache, ttl time.Duration, key func(AgentTask) string, memoryOn func() bool) Middleware {
+	return func(inner core.Agent) core.Agent {
+		if cache == nil {
+			return inner
+		}
+		keyFn := key
+		if keyFn == nil {
+			keyFn = func(task AgentTask) string {
+				if memoryOn != nil && memoryOn() {
+					return ""
+				}
+				return ResponseCacheKey(inner.Name(), task)
+			}
+		}
+		return agentFunc{Agent: inner, exec: func(ctx context.Context, task AgentTask, opts ...core.RunOption) (AgentResult, error) {
+			k := keyFn(task)
+			if k == "" {
+				return inner.Execute(ctx, task, opts...)
+			}
+			if r, ok := cache.Get(ctx, k); ok {
+				res := AgentResult{Output: r.Content, Attachments: r.Attachments, Sources: r.Sources, FinishReason: core.FinishStop}
+				if ch := core.ApplyRunOptions(opts...).Stream; ch != nil {
+					replayText(ctx, res.Output, ch)
+				}
+				return res, nil
+			}
+			tctx, cacheable := core.TrackResponseCacheability(ctx)
+			res, err := inner.Execute(tctx, task, opts...)
+			if err == nil && res.FinishReason == core.FinishStop && res.Output != "" && cacheable() {
+				cache.Set(ctx, k, core.ToolResult{Content: res.Output, Attachments: res.Attachments, Sources: res.Sources}, ttl)
+			}
+			return res, err
+		}}
+	}
+}
+
+// ResponseCacheKey is WithResponseCache's default key: a hash of the agent
+// name, UserID, the input and Extra. Tasks with attachments get "" and are
+// not cached.
+func ResponseCacheKey(agentName string, task AgentTask) string {
+	if len(task.Attachments) > 0 {
+		return ""
+	}
+	extra, err := json.Marshal(task.Extra) // map keys marshal sorted
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	for _, s := range []string{agentName, task.UserID, task.Input, string(extra)} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return "response:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// replayText emits text on ch as word-sized text deltas and closes ch.
+func replayText(ctx context.Context, text string, ch chan<- core.StreamEvent) {
+	defer close(ch)
+	for text != "" {
+		n := strings.IndexByte(text[1:], ' ') + 1
+		if n == 0 {
+			n = len(text)
+		}
+		select {
+		case ch <- core.StreamEvent{Type: core.EventTextDelta, Content: text[:n]}:
+		case <-ctx.Done():
+			return
+		}
+		text = text[n:]
+	}
+}
+
+// markUncacheableMiddleware poisons the current turn's response cache entry
+// before running a tool whose Cacheable() is false.
+func markUncacheableMiddleware(inner core.AnyTool) core.AnyTool {
+	if c, ok := inner.(core.CacheableTool); !ok || c.Cacheable() {
+		return inner
+	}
+	if st, ok := inner.(core.StreamingAnyTool); ok {
+		return &uncacheableStreamingWrapper{uncacheableWrapper{inner}, st}
+	}
+	return &uncacheableWrapper{inner}
+}
+
+type uncacheableWrapper struct {
+	inner core.AnyTool
+}
+
+func (w *uncacheableWrapper) Name() string                    { return w.inner.Name() }
+func (w *uncacheableWrapper) Definition() core.ToolDefinition { return w.inner.Definition() }
+func (w *uncacheableWrapper) Cacheable() bool                 { return false }
+func (w *uncacheableWrapper) ExecuteRaw(ctx context.Context, args json.RawMessage) (core.ToolResult, error) {
+	core.MarkResponseUncacheable(ctx)
+	return w.inner.ExecuteRaw(ctx, args)
+}
+
+type uncacheableStreamingWrapper struct {
+	uncacheableWrapper
+	stream core.StreamingAnyTool
+}
+
+func (w *uncacheableStreamingWrapper) ExecuteStream(ctx context.Context, args json.RawMessage, ch chan<- core.StreamEvent) (core.ToolResult, error) {
+	core.MarkResponseUncacheable(ctx)
+	return w.stream.ExecuteStream(ctx, args, ch)
+}
+
+var (
+	_ core.CacheableTool    = (*uncacheableWrapper)(nil)
+	_ core.StreamingAnyTool = (*uncacheableStreamingWrapper)(nil)
+)
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/memory"
+)
+
+// liveTool is a nondeterministic tool, like a web search.
+type liveTool struct{}
+
+func (liveTool) Name() string                    { return "live" }
+func (liveTool) Definition() core.ToolDefinition { return core.ToolDefinition{Name: "live"} }
+func (liveTool) Cacheable() bool                 { return false }
+func (liveTool) ExecuteRaw(context.Context, json.RawMessage) (core.ToolResult, error) {
+	return core.ToolResult{Content: "now"}, nil
+}
+
+func TestWithResponseCache_HitSkipsLLM(t *testing.T) {
+	cache := core.NewInMemoryToolCache()
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{{Content: "Paris is the capital"}, {Content: "Madrid"}}}
+	a := New("geo", "", provider, WithResponseCache(cache, time.Minute, nil))
+	ctx := context.Background()
+
+	first, err := a.Execute(ctx, AgentTask{Input: "capital of France?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := a.Execute(ctx, AgentTask{Input: "capital of France?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.idx != 1 {
+		t.Errorf("LLM calls = %d, want 1", provider.idx)
+	}
+	if second.Output != first.Output || second.FinishReason != core.FinishStop {
+		t.Errorf("cached result = %+v, want output %q", second, first.Output)
+	}
+
+	ch := make(chan core.StreamEvent, 16)
+	if _, err := a.Execute(ctx, AgentTask{Input: "capital of France?"}, core.WithStream(ch)); err != nil {
+		t.Fatal(err)
+	}
+	var text strings.Builder
+	deltas := 0
+	for ev := range ch {
+		if ev.Type == core.EventTextDelta {
+			text.WriteString(ev.Content)
+			deltas++
+		}
+	}
+	if text.String() != first.Output || deltas < 2 {
+		t.Errorf("replayed %d deltas %q, want word deltas of %q", deltas, text.String(), first.Output)
+	}
+
+	if _, err := a.Execute(ctx, AgentTask{Input: "capital of Spain?"}); err != nil {
+		t.Fatal(err)
+	}
+	if provider.idx != 2 {
+		t.Errorf("LLM calls after new input = %d, want 2", provider.idx)
+	}
+}
+
+func TestWithResponseCache_NonCacheableToolPoisons(t *testing.T) {
+	cache := core.NewInMemoryToolCache()
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{
+		{ToolCalls: []core.ToolCall{{ID: "1", Name: "live", Args: json.RawMessage(`{}`)}}},
+		{Content: "it is now"},
+		{Content: "it is later"},
+	}}
+	a := New("clock", "", provider, WithTools(liveTool{}), WithResponseCache(cache, time.Minute, nil))
+	ctx := context.Background()
+
+	if _, err := a.Execute(ctx, AgentTask{Input: "time?"}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := a.Execute(ctx, AgentTask{Input: "time?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Output != "it is later" {
+		t.Errorf("output = %q, want a fresh answer", res.Output)
+	}
+}
+
+func TestResponseCacheMiddleware_MarkAndEmptyKey(t *testing.T) {
+	cache := core.NewInMemoryToolCache()
+	calls := 0
+	base := &stubAgent{name: "base", fn: func(task AgentTask) (AgentResult, error) {
+		calls++
+		return AgentResult{Output: "ok", FinishReason: core.FinishStop}, nil
+	}}
+	key := func(t AgentTask) string { return t.ThreadID }
+	a := ResponseCacheMiddleware(cache, time.Minute, key)(base)
+	ctx := context.Background()
+
+	for range 2 {
+		a.Execute(ctx, AgentTask{Input: "hi"}) // empty key: never cached
+	}
+	if calls != 2 {
+		t.Errorf("calls with empty key = %d, want 2", calls)
+	}
+
+	calls = 0
+	for range 2 {
+		a.Execute(ctx, AgentTask{Input: "hi", ThreadID: "t1"})
+	}
+	if calls != 1 {
+		t.Errorf("calls with key = %d, want 1", calls)
+	}
+
+	if ResponseCacheKey("a", AgentTask{Input: "x", Attachments: []core.Attachment{{MimeType: "image/png"}}}) != "" {
+		t.Error("tasks with attachments should not get a default key")
+	}
+
+	ctx, cacheable := core.TrackResponseCacheability(ctx)
+	inner, innerCacheable := core.TrackResponseCacheability(ctx)
+	core.MarkResponseUncacheable(inner)
+	if innerCacheable() || cacheable() {
+		t.Error("a mark should poison the tracker and its enclosing trackers")
+	}
+}
+
+func TestWithResponseCache_PerUserAndMemory(t *testing.T) {
+	if ResponseCacheKey("a", AgentTask{Input: "my name?", UserID: "u1"}) == ResponseCacheKey("a", AgentTask{Input: "my name?", UserID: "u2"}) {
+		t.Error("default key must differ per user")
+	}
+
+	// With memory, answers depend on the thread and a hit would skip
+	// persistence, so the default key does not cache.
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{{Content: "Ann"}, {Content: "Bob"}}}
+	a := New("mem", "", provider,
+		WithMemory(memory.WithHistory(memory.HistoryConfig{MaxMessages: 10})),
+		WithResponseCache(core.NewInMemoryToolCache(), time.Minute, nil))
+	ctx := context.Background()
+	for range 2 {
+		if _, err := a.Execute(ctx, AgentTask{Input: "my name?"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if provider.idx != 2 {
+		t.Errorf("LLM calls = %d, want 2 (memory agent not cached by default)", provider.idx)
+	}
+}
+
+// typedLiveTool is a typed tool that opts out of caching; Erase forwards it.
+type typedLiveTool struct{}
+
+func (typedLiveTool) Definition() core.ToolMeta { return core.ToolMeta{Name: "live"} }
+func (typedLiveTool) Cacheable() bool           { return false }
+func (typedLiveTool) Execute(context.Context, struct{}) (string, error) {
+	return "now", nil
+}
+
+func TestWithResponseCache_TypedNonCacheableToolPoisons(t *testing.T) {
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{
+		{ToolCalls: []core.ToolCall{{ID: "1", Name: "live", Args: json.RawMessage(`{}`)}}},
+		{Content: "it is now"},
+		{Content: "it is later"},
+	}}
+	a := New("clock", "", provider,
+		WithTools(core.Erase[struct{}, string](typedLiveTool{})),
+		WithResponseCache(core.NewInMemoryToolCache(), time.Minute, nil))
+	ctx := context.Background()
+	a.Execute(ctx, AgentTask{Input: "time?"})
+	if res, _ := a.Execute(ctx, AgentTask{Input: "time?"}); res.Output != "it is later" {
+		t.Errorf("output = %q, want a fresh answer", res.Output)
+	}
+}
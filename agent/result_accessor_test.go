@@ -28,6 +28,82 @@ func TestAgentResultFinishReasonNaturalStop(t *testing.T) {
 	}
 }
 
+func TestAgentResultFinishReasonTruncated(t *testing.T) {
+	for _, model := range []core.FinishReason{core.FinishLength, core.FinishContentFilter} {
+		provider := newFnProvider(func(ctx context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+			if ch != nil {
+				close(ch)
+			}
+			return core.ChatResponse{Content: "The answer is", FinishReason: model}, nil
+		})
+		a := New("t", "test", provider)
+		result, err := a.Execute(context.Background(), AgentTask{Input: "x"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.FinishReason != model {
+			t.Errorf("FinishReason = %q, want %q", result.FinishReason, model)
+		}
+		if n := len(result.Iterations); n != 1 || result.Iterations[0].FinishReason != model {
+			t.Errorf("iterations = %+v, want one ending with %q", result.Iterations, model)
+		}
+	}
+}
+
+func TestAgentResultFinishReasonStopSequence(t *testing.T) {
+	provider := newFnProvider(func(ctx context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+		if ch != nil {
+			close(ch)
+		}
+		return core.ChatResponse{Content: "Step 1", FinishReason: core.FinishStopSequence}, nil
+	})
+	a := New("t", "test", provider)
+	result, err := a.Execute(context.Background(), AgentTask{Input: "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.FinishReason != core.FinishStopSequence {
+		t.Errorf("FinishReason = %q, want %q", result.FinishReason, core.FinishStopSequence)
+	}
+}
+
+func TestAutoContinue(t *testing.T) {
+	truncated := func(s string) core.ChatResponse {
+		return core.ChatResponse{Content: s, FinishReason: core.FinishLength, Usage: core.Usage{OutputTokens: 10}}
+	}
+	var lastReq core.ChatRequest
+	provider := &mockProvider{
+		name:      "test",
+		responses: []core.ChatResponse{truncated("one "), truncated("two "), {Content: "three", FinishReason: core.FinishStop, Usage: core.Usage{OutputTokens: 5}}},
+		onChat:    func(req *core.ChatRequest) { lastReq = *req },
+	}
+	a := New("t", "test", provider, WithAutoContinue(3))
+	result, err := a.Execute(context.Background(), AgentTask{Input: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "one two three" || result.FinishReason != core.FinishStop {
+		t.Errorf("result = %q (%s), want stitched output with FinishStop", result.Output, result.FinishReason)
+	}
+	if result.Usage.OutputTokens != 25 {
+		t.Errorf("OutputTokens = %d, want 25 across all calls", result.Usage.OutputTokens)
+	}
+	if last := lastReq.Messages[len(lastReq.Messages)-1]; last.Content != continuePrompt {
+		t.Errorf("last message = %q, want the continue prompt", last.Content)
+	}
+
+	// The cap stops continuation; the still-truncated output keeps FinishLength.
+	provider = &mockProvider{name: "test", responses: []core.ChatResponse{truncated("one "), truncated("two "), truncated("three")}}
+	a = New("t", "test", provider, WithAutoContinue(1))
+	result, err = a.Execute(context.Background(), AgentTask{Input: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "one two " || result.FinishReason != core.FinishLength || provider.idx != 2 {
+		t.Errorf("result = %q (%s) after %d calls, want two calls ending in FinishLength", result.Output, result.FinishReason, provider.idx)
+	}
+}
+
 // Task 3.2 — SuspendPayload is a skip placeholder per plan instructions.
 func TestAgentResultSuspendPayload(t *testing.T) {
 	// Build a provider whose first reply triggers a suspend-via-tool.
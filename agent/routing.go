@@ -1,11 +1,16 @@
 package agent
 
-import "strings"
+import (
+	"encoding/json"
+	"strings"
+)
 
 // buildRoutingSummary builds a JSON summary of agent and tool routing
 // without json.Marshal or map allocation. Tool/agent names are always
 // safe identifiers (alphanumeric + underscore), so no escaping needed.
-func buildRoutingSummary(agents, tools []string) string {
+// tasks, parallel to agents, and reasoning are free text and are escaped;
+// both are omitted when empty.
+func buildRoutingSummary(agents, tools, tasks []string, reasoning string) string {
 	var sb strings.Builder
 	sb.WriteString(`{"agents":[`)
 	for i, a := range agents {
@@ -25,6 +30,37 @@ func buildRoutingSummary(agents, tools []string) string {
 		sb.WriteString(t)
 		sb.WriteByte('"')
 	}
-	sb.WriteString(`]}`)
+	sb.WriteByte(']')
+	if len(tasks) > 0 {
+		sb.WriteString(`,"tasks":[`)
+		for i, t := range tasks {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			writeJSONString(&sb, t)
+		}
+		sb.WriteByte(']')
+	}
+	if reasoning != "" {
+		sb.WriteString(`,"reasoning":`)
+		writeJSONString(&sb, reasoning)
+	}
+	sb.WriteByte('}')
 	return sb.String()
 }
+
+// writeJSONString writes s to sb as a JSON string literal.
+func writeJSONString(sb *strings.Builder, s string) {
+	b, _ := json.Marshal(s) // marshaling a string cannot fail
+	sb.Write(b)
+}
+
+// delegatedTask returns the "task" argument of an agent_* tool call, or ""
+// when args carry none.
+func delegatedTask(args json.RawMessage) string {
+	var p struct {
+		Task string `json:"task"`
+	}
+	_ = json.Unmarshal(args, &p)
+	return p.Task
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// ErrSchemaValidation is returned when the final response still fails to
+// validate against the ResponseSchema after WithResponseSchemaRetries
+// re-prompts. Output is the model's last raw response; Errors lists what was
+// wrong with it, one entry per violation (e.g. `$.title: required property
+// missing`). The AgentResult returned alongside carries the same Output.
+type ErrSchemaValidation struct {
+	Output string
+	Errors []string
+}
+
+func (e *ErrSchemaValidation) Error() string {
+	return "response does not match schema: " + strings.Join(e.Errors, "; ")
+}
+
+// validateResponseSchema checks content against schema and returns the
+// violations found, or nil when content conforms. A schema the validator
+// cannot parse is treated as accepting everything.
+func validateResponseSchema(schema *core.ResponseSchema, content string) []string {
+	errs := core.SchemaViolations(schema.Schema, []byte(content))
+	for i, e := range errs {
+		if strings.HasPrefix(e, "not valid JSON") {
+			errs[i] = "response is " + e
+		}
+	}
+	return errs
+}
+
+// schemaRetryMessage is the user turn appended after an invalid response,
+// telling the model what to fix.
+func schemaRetryMessage(errs []string) core.ChatMessage {
+	var b strings.Builder
+	b.WriteString("Your previous response did not match the required JSON schema:\n")
+	for _, e := range errs {
+		b.WriteString("- ")
+		b.WriteString(e)
+		b.WriteByte('\n')
+	}
+	b.WriteString("Respond again with only JSON that satisfies the schema.")
+	return core.UserMessage(b.String())
+}
@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+var reportSchema = core.NewResponseSchema("report", &core.SchemaObject{
+	Type: "object",
+	Properties: map[string]*core.SchemaObject{
+		"title":    {Type: "string"},
+		"priority": {Type: "string", Enum: []string{"low", "high"}},
+		"tags":     {Type: "array", Items: &core.SchemaObject{Type: "string"}},
+	},
+	Required: []string{"title"},
+})
+
+func TestValidateResponseSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"valid", `{"title":"Q3","priority":"high","tags":["a"]}`, nil},
+		{"not json", `here is your report`, []string{"not valid JSON"}},
+		{"trailing data", `{"title":"a"} {"title":"b"}`, []string{"unexpected data"}},
+		{"missing required", `{"tags":[]}`, []string{"$.title: required property missing"}},
+		{"wrong type", `{"title":3}`, []string{"$.title: expected string, got number"}},
+		{"bad item", `{"title":"x","tags":["a",true]}`, []string{"$.tags[1]: expected string, got boolean"}},
+		{"bad enum", `{"title":"x","priority":"urgent"}`, []string{"$.priority: value is not one of"}},
+		{"root type", `[1,2]`, []string{"$: expected object, got array"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateResponseSchema(reportSchema, tt.content)
+			if len(got) != len(tt.want) {
+				t.Fatalf("errors = %q, want %d matching %q", got, len(tt.want), tt.want)
+			}
+			for i := range tt.want {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("errors[%d] = %q, want substring %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateResponseSchemaRawKeywords(t *testing.T) {
+	schema := &core.ResponseSchema{Name: "n", Schema: []byte(`{
+		"type": "object",
+		"properties": {"n": {"type": ["integer", "null"]}},
+		"additionalProperties": false
+	}`)}
+	if errs := validateResponseSchema(schema, `{"n":null}`); errs != nil {
+		t.Errorf("null should match type array: %q", errs)
+	}
+	if errs := validateResponseSchema(schema, `{"n":1.5}`); len(errs) != 1 {
+		t.Errorf("1.5 is not an integer: %q", errs)
+	}
+	if errs := validateResponseSchema(schema, `{"n":1,"extra":true}`); len(errs) != 1 || !strings.Contains(errs[0], "$.extra: property not allowed") {
+		t.Errorf("extra property: %q", errs)
+	}
+}
+
+func TestResponseSchemaRetrySucceeds(t *testing.T) {
+	var lastReq core.ChatRequest
+	provider := &mockProvider{
+		name: "mock",
+		responses: []core.ChatResponse{
+			{Content: `{"tags":["a"]}`},
+			{Content: `{"title":"Q3","tags":["a"]}`},
+		},
+		onChat: func(req *core.ChatRequest) { lastReq = *req },
+	}
+	a := New("t", "test", provider, WithResponseSchema(reportSchema), WithResponseSchemaRetries(2))
+
+	result, err := a.Execute(context.Background(), AgentTask{Input: "report"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Output != `{"title":"Q3","tags":["a"]}` {
+		t.Errorf("Output = %q", result.Output)
+	}
+	if provider.idx != 2 {
+		t.Errorf("provider calls = %d, want 2", provider.idx)
+	}
+	msgs := lastReq.Messages
+	if len(msgs) < 2 {
+		t.Fatalf("retry request has %d messages", len(msgs))
+	}
+	if got := msgs[len(msgs)-2]; got.Role != core.RoleAssistant || got.Content != `{"tags":["a"]}` {
+		t.Errorf("retry should replay the invalid answer, got %+v", got)
+	}
+	if got := msgs[len(msgs)-1]; got.Role != core.RoleUser || !strings.Contains(got.Content, "$.title: required property missing") {
+		t.Errorf("retry prompt should carry the validation error, got %q", got.Content)
+	}
+}
+
+func TestResponseSchemaRetriesExhausted(t *testing.T) {
+	provider := &mockProvider{
+		name: "mock",
+		responses: []core.ChatResponse{
+			{Content: `not json`},
+			{Content: `{"title":7}`},
+		},
+	}
+	a := New("t", "test", provider, WithResponseSchema(reportSchema), WithResponseSchemaRetries(1))
+
+	result, err := a.Execute(context.Background(), AgentTask{Input: "report"})
+	var sv *ErrSchemaValidation
+	if !errors.As(err, &sv) {
+		t.Fatalf("err = %v, want *ErrSchemaValidation", err)
+	}
+	if sv.Output != `{"title":7}` || result.Output != sv.Output {
+		t.Errorf("Output = %q / %q, want last raw response", sv.Output, result.Output)
+	}
+	if len(sv.Errors) != 1 || !strings.Contains(sv.Errors[0], "$.title") {
+		t.Errorf("Errors = %q", sv.Errors)
+	}
+	if provider.idx != 2 {
+		t.Errorf("provider calls = %d, want 2", provider.idx)
+	}
+}
+
+func TestResponseSchemaWithoutRetriesSkipsValidation(t *testing.T) {
+	provider := &mockProvider{name: "mock", responses: []core.ChatResponse{{Content: `not json`}}}
+	a := New("t", "test", provider, WithResponseSchema(reportSchema))
+
+	result, err := a.Execute(context.Background(), AgentTask{Input: "report"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Output != "not json" {
+		t.Errorf("Output = %q", result.Output)
+	}
+}
@@ -243,7 +243,7 @@ func ExecuteSelfClone(ctx context.Context, parentName, description string, provi
 		"duration", elapsed,
 		"input_tokens", result.Usage.InputTokens,
 		"output_tokens", result.Usage.OutputTokens)
-	return DispatchResult{Content: result.Output, Usage: result.Usage, Attachments: result.Attachments}
+	return DispatchResult{Content: result.Output, Usage: result.Usage, Attachments: result.Attachments, Sources: result.Sources}
 }
 
 // newCloneAgent builds the ephemeral copy: the parent's Config minus memory
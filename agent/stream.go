@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/nevindra/oasis/core"
 	"github.com/nevindra/oasis/internal/runtime"
@@ -60,16 +61,117 @@ const (
 // Client disconnection propagates via ctx cancellation to the agent.
 // Callers typically pass r.Context() as ctx.
 func ServeSSE(ctx context.Context, w http.ResponseWriter, agent core.Agent, task AgentTask) (AgentResult, error) {
+	return ServeSSEWithKeepAlive(ctx, w, agent, task, 0)
+}
+
+// ServeSSEWithKeepAlive is [ServeSSE] with an idle keep-alive. Whenever no
+// event has been written for interval, it writes the SSE comment line
+// ": keepalive" so proxies that close idle connections (typically after 30s)
+// keep the stream open during slow tool calls. Clients ignore comment lines.
+//
+// Keep-alives are written from the same goroutine as events, so they never
+// interleave with a partial event, and the ticker stops when the agent
+// finishes. interval <= 0 disables keep-alives (identical to ServeSSE).
+func ServeSSEWithKeepAlive(ctx context.Context, w http.ResponseWriter, agent core.Agent, task AgentTask, interval time.Duration) (AgentResult, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return AgentResult{}, fmt.Errorf("ResponseWriter does not implement http.Flusher")
+	}
+
+	setSSEHeaders(w)
+
+	res, err := streamAgentKeepAlive(ctx, agent, task, interval, func(ev core.StreamEvent) {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+		flusher.Flush()
+	}, func() {
+		fmt.Fprint(w, ": keepalive\n\n")
+		flusher.Flush()
+	})
+
+	if err != nil {
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", errorData(err))
+		flusher.Flush()
+		return res, err
+	}
+
+	doneData, _ := json.Marshal(res)
+	fmt.Fprintf(w, "event: done\ndata: %s\n\n", doneData)
+	flusher.Flush()
+
+	return res, nil
+}
+
+// ServeNDJSON streams an agent's response over HTTP as newline-delimited JSON
+// (Content-Type application/x-ndjson) for clients that don't speak SSE.
+//
+// Each [StreamEvent] is written as one JSON object per line and flushed. The
+// final line mirrors the SSE terminal event: {"type":"done","result":<AgentResult>}
+// on success, or {"type":"error","error":"<message>"} on failure.
+//
+// Like [ServeSSE], w must implement [http.Flusher], and client disconnection
+// propagates via ctx cancellation to the agent.
+func ServeNDJSON(ctx context.Context, w http.ResponseWriter, agent core.Agent, task AgentTask) (AgentResult, error) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "streaming not supported", http.StatusInternalServerError)
 		return AgentResult{}, fmt.Errorf("ResponseWriter does not implement http.Flusher")
 	}
 
-	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Content-Type", "application/x-ndjson")
 	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
 
+	// json.Encoder terminates each value with '\n', the NDJSON record
+	// separator, and escapes newlines inside strings, so one event is
+	// always one line.
+	enc := json.NewEncoder(w)
+	res, err := streamAgent(ctx, agent, task, func(ev core.StreamEvent) {
+		if enc.Encode(ev) == nil {
+			flusher.Flush()
+		}
+	})
+
+	if err != nil {
+		_ = enc.Encode(terminalFrame{Type: "error", Error: err.Error()})
+		flusher.Flush()
+		return res, err
+	}
+
+	_ = enc.Encode(terminalFrame{Type: "done", Result: &res})
+	flusher.Flush()
+
+	return res, nil
+}
+
+// terminalFrame is the final record of the NDJSON and WebSocket transports:
+// the JSON counterpart of the SSE "done" / "error" events.
+type terminalFrame struct {
+	Type   string       `json:"type"`
+	Result *AgentResult `json:"result,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// streamAgent is the plumbing shared by the streaming transports. It runs
+// agent in a background goroutine with a buffered event channel, calls emit
+// for every event on the caller's goroutine, and returns the agent's result
+// once the channel closes. A panic in the agent is recovered and returned as
+// an error. extra run options are appended after the stream option.
+//
+// emit must not block indefinitely; the channel is always drained so the
+// agent never stalls on a slow or dead client.
+func streamAgent(ctx context.Context, agent core.Agent, task AgentTask, emit func(core.StreamEvent), extra ...core.RunOption) (AgentResult, error) {
+	return streamAgentKeepAlive(ctx, agent, task, 0, emit, nil, extra...)
+}
+
+// streamAgentKeepAlive is streamAgent plus an idle callback: onIdle runs on
+// the emitting goroutine whenever interval passes without an event. The
+// timer is reset after every event and stopped when the agent finishes.
+// interval <= 0 disables the callback.
+func streamAgentKeepAlive(ctx context.Context, agent core.Agent, task AgentTask, interval time.Duration, emit func(core.StreamEvent), onIdle func(), extra ...core.RunOption) (AgentResult, error) {
 	ch := make(chan core.StreamEvent, 64)
 	safeClose := onceClose(ch)
 
@@ -79,45 +181,49 @@ func ServeSSE(ctx context.Context, w http.ResponseWriter, agent core.Agent, task
 	}
 	resultCh := make(chan execResult, 1)
 
+	opts := append([]core.RunOption{core.WithStream(ch)}, extra...)
 	go func() {
 		defer func() {
 			if p := recover(); p != nil {
 				// Ensure ch is closed so the for-range loop below
 				// doesn't block forever, then signal the error.
-				// Use sync.Once because ExecuteStream may have already
+				// Use sync.Once because Execute may have already
 				// closed ch before the panic site.
 				safeClose()
 				resultCh <- execResult{AgentResult{}, fmt.Errorf("agent panic: %v", p)}
 				return
 			}
 		}()
-		r, err := agent.Execute(ctx, task, core.WithStream(ch))
+		r, err := agent.Execute(ctx, task, opts...)
 		resultCh <- execResult{r, err}
 	}()
 
-	for ev := range ch {
-		data, err := json.Marshal(ev)
-		if err != nil {
-			continue
+	if interval <= 0 || onIdle == nil {
+		for ev := range ch {
+			emit(ev)
+		}
+	} else {
+		idle := time.NewTimer(interval)
+		defer idle.Stop()
+	loop:
+		for {
+			select {
+			case ev, ok := <-ch:
+				if !ok {
+					break loop
+				}
+				emit(ev)
+			case <-idle.C:
+				onIdle()
+			}
+			// Why: since Go 1.23 Reset discards any pending fire, so the
+			// next keep-alive is always a full interval after the last write.
+			idle.Reset(interval)
 		}
-		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
-		flusher.Flush()
 	}
 
 	res := <-resultCh
-
-	if res.err != nil {
-		errData, _ := json.Marshal(map[string]string{"error": res.err.Error()})
-		fmt.Fprintf(w, "event: error\ndata: %s\n\n", errData)
-		flusher.Flush()
-		return res.result, res.err
-	}
-
-	doneData, _ := json.Marshal(res.result)
-	fmt.Fprintf(w, "event: done\ndata: %s\n\n", doneData)
-	flusher.Flush()
-
-	return res.result, nil
+	return res.result, res.err
 }
 
 // WriteSSEEvent writes a single Server-Sent Event to w and flushes.
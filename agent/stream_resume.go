@@ -0,0 +1,359 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// defaultStreamGrace is how long a StreamBuffer keeps a run going with no
+// client attached, and keeps a finished stream's frames, when none is given.
+const defaultStreamGrace = 2 * time.Minute
+
+// ErrStreamNotFound is returned by StreamBuffer.ResumeStream when the stream
+// ID is unknown, or its frames have already been released.
+var ErrStreamNotFound = errors.New("stream not found")
+
+// ErrStreamBufferClosed is returned by StreamBuffer.ServeSSE, without writing
+// to w, once Close has been called.
+var ErrStreamBufferClosed = errors.New("stream buffer closed")
+
+// StreamBuffer serves agent runs as resumable Server-Sent Events. Its
+// ServeSSE records every frame under a caller-chosen stream ID and keeps the
+// agent running when the client disconnects; ResumeStream replays the
+// frames the client missed and follows the run to its end.
+//
+// A run with no client attached is cancelled after the grace period. A
+// finished stream stays resumable for the same period, then its frames are
+// released. With a core.StreamLogStore, frames are also persisted, so a
+// client reconnecting after a restart, or to another replica, is sent the
+// partial output followed by an "error" event. Following a live run needs
+// the resume request to reach the process running it. Persisted streams
+// whose last frame is older than the grace period, such as those of a run
+// cut short by a crash, are pruned at most once per grace period when a
+// stream is served or resumed.
+//
+// Create with NewStreamBuffer; call Close on shutdown.
+type StreamBuffer struct {
+	store  core.StreamLogStore
+	grace  time.Duration
+	logger *slog.Logger
+	runs   sync.WaitGroup
+
+	mu        sync.Mutex
+	closed    bool
+	live      map[string]*bufferedStream
+	releases  map[string]*time.Timer // pending releases of finished streams
+	lastPrune time.Time
+}
+
+// NewStreamBuffer returns a StreamBuffer that persists frames to store (nil
+// keeps them in memory only). grace <= 0 uses two minutes.
+func NewStreamBuffer(store core.StreamLogStore, grace time.Duration) *StreamBuffer {
+	if grace <= 0 {
+		grace = defaultStreamGrace
+	}
+	return &StreamBuffer{
+		store:    store,
+		grace:    grace,
+		logger:   slog.Default(),
+		live:     make(map[string]*bufferedStream),
+		releases: make(map[string]*time.Timer),
+	}
+}
+
+// bufferedStream is the in-memory record of one run. frames only grows;
+// notify is closed and replaced on every append to wake followers.
+type bufferedStream struct {
+	mu      sync.Mutex
+	frames  []core.StreamFrame
+	notify  chan struct{}
+	done    bool
+	res     AgentResult
+	err     error
+	clients int
+	idle    *time.Timer
+	cancel  context.CancelFunc
+}
+
+// ServeSSE streams agent's run of task to w like the package-level ServeSSE,
+// and additionally writes an "id: <seq>" line with every event so the client
+// can resume after the last one it received. The run is detached from ctx:
+// when ctx is cancelled, ServeSSE returns ctx.Err() and the agent keeps
+// running for the grace period. streamID must be unique among live streams.
+func (b *StreamBuffer) ServeSSE(ctx context.Context, w http.ResponseWriter, agent core.Agent, task AgentTask, streamID string) (AgentResult, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return AgentResult{}, fmt.Errorf("ResponseWriter does not implement http.Flusher")
+	}
+
+	// Why WithoutCancel: the point of buffering is that a dropped
+	// connection does not end the run. The grace timer cancels it instead.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s := &bufferedStream{notify: make(chan struct{}), cancel: cancel, clients: 1}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		cancel()
+		return AgentResult{}, ErrStreamBufferClosed
+	}
+	if _, dup := b.live[streamID]; dup {
+		b.mu.Unlock()
+		cancel()
+		return AgentResult{}, fmt.Errorf("stream %q already exists", streamID)
+	}
+	b.live[streamID] = s
+	b.runs.Add(1)
+	b.mu.Unlock()
+
+	go b.run(runCtx, s, agent, task, streamID)
+
+	setSSEHeaders(w)
+	err := b.follow(ctx, w, flusher, s, 0)
+	if err != nil {
+		return AgentResult{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.res, s.err
+}
+
+// ResumeStream writes the frames of streamID after sequence number after
+// (0 = from the start) to w as Server-Sent Events and, if the run is still
+// going in this process, follows it until it finishes or ctx is cancelled.
+// Browsers send the last id they saw in the Last-Event-ID header.
+//
+// It returns ErrStreamNotFound, without writing to w, when the stream is
+// unknown. A stream found only in the store whose run did not finish ends
+// with an "error" event.
+func (b *StreamBuffer) ResumeStream(ctx context.Context, w http.ResponseWriter, streamID string, after int64) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return fmt.Errorf("ResponseWriter does not implement http.Flusher")
+	}
+
+	b.mu.Lock()
+	s := b.live[streamID]
+	b.mu.Unlock()
+	if s != nil {
+		s.attach()
+		setSSEHeaders(w)
+		return b.follow(ctx, w, flusher, s, after)
+	}
+
+	if b.store == nil {
+		return ErrStreamNotFound
+	}
+	b.prune(ctx)
+	frames, err := b.store.StreamFrames(ctx, streamID)
+	if err != nil {
+		return err
+	}
+	if len(frames) == 0 {
+		return ErrStreamNotFound
+	}
+	setSSEHeaders(w)
+	for _, f := range frames {
+		if f.Seq > after {
+			writeFrame(w, f)
+		}
+	}
+	if last := frames[len(frames)-1]; last.Type != "done" && last.Type != "error" {
+		writeFrame(w, core.StreamFrame{Type: "error", Data: errorData(errors.New("stream interrupted: the run is no longer active"))})
+	}
+	flusher.Flush()
+	return nil
+}
+
+// Close cancels every live run and waits for them to return. Later calls to
+// ServeSSE fail. Pending releases are stopped; frames they would have
+// deleted from the store are pruned once they age past the grace period.
+// Safe to call more than once.
+func (b *StreamBuffer) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	for _, s := range b.live {
+		s.cancel()
+	}
+	for id, t := range b.releases {
+		t.Stop()
+		delete(b.releases, id)
+	}
+	b.mu.Unlock()
+	b.runs.Wait()
+	return nil
+}
+
+// run executes the agent, records each event as a frame, then schedules the
+// finished stream's release.
+func (b *StreamBuffer) run(ctx context.Context, s *bufferedStream, agent core.Agent, task AgentTask, streamID string) {
+	defer b.runs.Done()
+	// Store writes outlive cancellation so the terminal frame is recorded.
+	storeCtx := context.WithoutCancel(ctx)
+	b.prune(storeCtx)
+	record := func(typ string, data []byte) {
+		f := s.append(typ, data)
+		if b.store != nil {
+			// A failed write only costs resumability after a restart;
+			// the in-memory copy still serves this process.
+			if err := b.store.AppendStreamFrame(storeCtx, streamID, f); err != nil {
+				b.logger.Warn("stream buffer: persist frame failed", "stream_id", streamID, "seq", f.Seq, "error", err)
+			}
+		}
+	}
+
+	res, err := streamAgent(ctx, agent, task, func(ev core.StreamEvent) {
+		if data, err := json.Marshal(ev); err == nil {
+			record(string(ev.Type), data)
+		}
+	})
+	if err != nil {
+		record("error", errorData(err))
+	} else {
+		data, _ := json.Marshal(res)
+		record("done", data)
+	}
+	s.finish(res, err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.releases[streamID] = time.AfterFunc(b.grace, func() {
+		b.mu.Lock()
+		delete(b.live, streamID)
+		delete(b.releases, streamID)
+		b.mu.Unlock()
+		if b.store != nil {
+			if err := b.store.DeleteStreamFrames(storeCtx, streamID); err != nil {
+				b.logger.Warn("stream buffer: delete frames failed", "stream_id", streamID, "error", err)
+			}
+		}
+	})
+}
+
+// prune deletes persisted streams that have seen no frame for the grace
+// period, at most once per grace period. In-process streams are released by
+// run; this catches those left behind by a crash or restart.
+func (b *StreamBuffer) prune(ctx context.Context) {
+	if b.store == nil {
+		return
+	}
+	now := time.Now()
+	b.mu.Lock()
+	if now.Sub(b.lastPrune) < b.grace {
+		b.mu.Unlock()
+		return
+	}
+	b.lastPrune = now
+	b.mu.Unlock()
+	if _, err := b.store.PruneStreamFrames(ctx, now.Add(-b.grace)); err != nil {
+		b.logger.Warn("stream buffer: prune frames failed", "error", err)
+	}
+}
+
+// follow writes s's frames after seq after to w as they arrive, until the
+// run finishes (nil) or ctx is cancelled (ctx.Err()). The caller must have
+// attached to s; follow detaches on return.
+func (b *StreamBuffer) follow(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, s *bufferedStream, after int64) error {
+	defer s.detach(b.grace)
+	for {
+		s.mu.Lock()
+		var frames []core.StreamFrame
+		if after < int64(len(s.frames)) {
+			frames = s.frames[max(after, 0):]
+		}
+		done, notify := s.done, s.notify
+		s.mu.Unlock()
+
+		for _, f := range frames {
+			writeFrame(w, f)
+		}
+		if len(frames) > 0 {
+			flusher.Flush()
+			after = frames[len(frames)-1].Seq
+		}
+		// done is read with the frames, so the terminal frame was written.
+		if done {
+			return nil
+		}
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *bufferedStream) append(typ string, data []byte) core.StreamFrame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := core.StreamFrame{Seq: int64(len(s.frames)) + 1, Type: typ, Data: data}
+	s.frames = append(s.frames, f)
+	close(s.notify)
+	s.notify = make(chan struct{})
+	return f
+}
+
+func (s *bufferedStream) finish(res AgentResult, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.res, s.err, s.done = res, err, true
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// attach registers a client and stops the idle timer.
+func (s *bufferedStream) attach() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients++
+	if s.idle != nil {
+		s.idle.Stop()
+		s.idle = nil
+	}
+}
+
+// detach unregisters a client. When the last one leaves a running stream,
+// the run is cancelled unless a client attaches within grace.
+func (s *bufferedStream) detach(grace time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients--
+	if s.clients == 0 && !s.done {
+		s.idle = time.AfterFunc(grace, s.cancel)
+	}
+}
+
+func setSSEHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+}
+
+// writeFrame writes f as one SSE event. Frames with Seq 0 carry no id.
+func writeFrame(w http.ResponseWriter, f core.StreamFrame) {
+	if f.Seq > 0 {
+		fmt.Fprintf(w, "id: %d\n", f.Seq)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", f.Type, f.Data)
+}
+
+// errorData is the data of an SSE "error" event, as written by ServeSSE.
+func errorData(err error) []byte {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return data
+}
@@ -0,0 +1,202 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// gatedAgent streams "Hello", closes sent, then waits for release (or ctx)
+// before streaming " world".
+type gatedAgent struct {
+	sent, release chan struct{}
+}
+
+func (g *gatedAgent) Name() string        { return "gated" }
+func (g *gatedAgent) Description() string { return "" }
+func (g *gatedAgent) Execute(ctx context.Context, _ AgentTask, opts ...RunOption) (AgentResult, error) {
+	ch := core.ApplyRunOptions(opts...).Stream
+	defer close(ch)
+	ch <- core.StreamEvent{Type: core.EventTextDelta, Content: "Hello"}
+	close(g.sent)
+	select {
+	case <-g.release:
+	case <-ctx.Done():
+		return AgentResult{}, ctx.Err()
+	}
+	ch <- core.StreamEvent{Type: core.EventTextDelta, Content: " world"}
+	return AgentResult{Output: "Hello world"}, nil
+}
+
+// memStreamLog is an in-memory core.StreamLogStore.
+type memStreamLog struct {
+	mu     sync.Mutex
+	frames map[string][]core.StreamFrame
+	last   map[string]time.Time // when each stream's newest frame was appended
+}
+
+func (m *memStreamLog) AppendStreamFrame(_ context.Context, id string, f core.StreamFrame) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.frames[id] = append(m.frames[id], f)
+	if m.last == nil {
+		m.last = make(map[string]time.Time)
+	}
+	m.last[id] = time.Now()
+	return nil
+}
+
+func (m *memStreamLog) StreamFrames(_ context.Context, id string) ([]core.StreamFrame, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]core.StreamFrame(nil), m.frames[id]...), nil
+}
+
+func (m *memStreamLog) DeleteStreamFrames(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.frames, id)
+	return nil
+}
+
+func (m *memStreamLog) PruneStreamFrames(_ context.Context, before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, at := range m.last {
+		if at.Before(before) {
+			n += len(m.frames[id])
+			delete(m.frames, id)
+			delete(m.last, id)
+		}
+	}
+	return n, nil
+}
+
+func TestStreamBuffer_ResumeAfterDisconnect(t *testing.T) {
+	store := &memStreamLog{frames: map[string][]core.StreamFrame{}}
+	buf := NewStreamBuffer(store, time.Minute)
+	defer buf.Close()
+	ag := &gatedAgent{sent: make(chan struct{}), release: make(chan struct{})}
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	go func() { <-ag.sent; disconnect() }()
+	if _, err := buf.ServeSSE(ctx, httptest.NewRecorder(), ag, AgentTask{}, "s1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ServeSSE after disconnect = %v, want context.Canceled", err)
+	}
+
+	// The run outlived the request; a resumed client sees all of it.
+	close(ag.release)
+	rec := httptest.NewRecorder()
+	if err := buf.ResumeStream(context.Background(), rec, "s1", 0); err != nil {
+		t.Fatal(err)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"id: 1\nevent: text-delta", `"Hello"`, `" world"`, "event: done"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("resumed body missing %q:\n%s", want, body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	if err := buf.ResumeStream(context.Background(), rec, "s1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if body := rec.Body.String(); strings.Contains(body, `"Hello"`) || !strings.Contains(body, `" world"`) {
+		t.Errorf("resume after id 1 should skip the first delta:\n%s", body)
+	}
+
+	if err := buf.ResumeStream(context.Background(), httptest.NewRecorder(), "nope", 0); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("unknown stream = %v, want ErrStreamNotFound", err)
+	}
+
+	// Another process sharing the store replays the persisted frames.
+	rec = httptest.NewRecorder()
+	if err := NewStreamBuffer(store, time.Minute).ResumeStream(context.Background(), rec, "s1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "event: done") || strings.Contains(body, "interrupted") {
+		t.Errorf("store replay = %q", body)
+	}
+}
+
+func TestStreamBuffer_GraceCancelsAbandonedRun(t *testing.T) {
+	store := &memStreamLog{frames: map[string][]core.StreamFrame{}}
+	buf := NewStreamBuffer(store, 20*time.Millisecond)
+	defer buf.Close()
+	ag := &gatedAgent{sent: make(chan struct{}), release: make(chan struct{})}
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	go func() { <-ag.sent; disconnect() }()
+	buf.ServeSSE(ctx, httptest.NewRecorder(), ag, AgentTask{}, "s1")
+
+	// Wait for the grace timer to cancel the run, then resume before the
+	// finished stream is released.
+	buf.mu.Lock()
+	s := buf.live["s1"]
+	buf.mu.Unlock()
+	for done := false; !done; time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		done = s.done
+		s.mu.Unlock()
+	}
+	rec := httptest.NewRecorder()
+	if err := buf.ResumeStream(context.Background(), rec, "s1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "event: error") || strings.Contains(body, `" world"`) {
+		t.Errorf("abandoned run should end in an error event:\n%s", body)
+	}
+}
+
+func TestStreamBuffer_PrunesStaleFrames(t *testing.T) {
+	store := &memStreamLog{frames: map[string][]core.StreamFrame{}}
+	ctx := context.Background()
+	// Frames left behind by a process that crashed mid-run.
+	store.AppendStreamFrame(ctx, "crashed", core.StreamFrame{Seq: 1, Type: "text-delta", Data: []byte(`{}`)})
+	store.AppendStreamFrame(ctx, "recent", core.StreamFrame{Seq: 1, Type: "text-delta", Data: []byte(`{}`)})
+	store.last["crashed"] = time.Now().Add(-time.Hour)
+
+	buf := NewStreamBuffer(store, time.Minute)
+	defer buf.Close()
+	if err := buf.ResumeStream(ctx, httptest.NewRecorder(), "crashed", 0); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("stale stream = %v, want ErrStreamNotFound", err)
+	}
+	if err := buf.ResumeStream(ctx, httptest.NewRecorder(), "recent", 0); err != nil {
+		t.Errorf("recent stream: %v", err)
+	}
+}
+
+func TestStreamBuffer_CloseStopsReleaseTimers(t *testing.T) {
+	store := &memStreamLog{frames: map[string][]core.StreamFrame{}}
+	buf := NewStreamBuffer(store, 20*time.Millisecond)
+	ag := &gatedAgent{sent: make(chan struct{}), release: make(chan struct{})}
+	close(ag.release)
+	if _, err := buf.ServeSSE(context.Background(), httptest.NewRecorder(), ag, AgentTask{}, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	buf.Close()
+
+	time.Sleep(60 * time.Millisecond)
+	if frames, _ := store.StreamFrames(context.Background(), "s1"); len(frames) == 0 {
+		t.Error("release timer ran after Close")
+	}
+}
+
+func TestStreamBuffer_ServeAfterClose(t *testing.T) {
+	buf := NewStreamBuffer(nil, time.Minute)
+	buf.Close()
+	rec := httptest.NewRecorder()
+	if _, err := buf.ServeSSE(context.Background(), rec, &gatedAgent{}, AgentTask{}, "s1"); !errors.Is(err, ErrStreamBufferClosed) {
+		t.Errorf("ServeSSE after Close = %v, want ErrStreamBufferClosed", err)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("ServeSSE after Close wrote %q", rec.Body.String())
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nevindra/oasis/core"
 )
@@ -348,6 +349,148 @@ func TestServeSSE_NoFlusher(t *testing.T) {
 	}
 }
 
+// slowStreamingAgent emits one event, stays silent for pause, then emits another.
+type slowStreamingAgent struct{ pause time.Duration }
+
+func (slowStreamingAgent) Name() string        { return "slow" }
+func (slowStreamingAgent) Description() string { return "slow tool call" }
+func (s slowStreamingAgent) Execute(ctx context.Context, _ AgentTask, opts ...RunOption) (AgentResult, error) {
+	ch := core.ApplyRunOptions(opts...).Stream
+	defer close(ch)
+	ch <- core.StreamEvent{Type: core.EventToolCallStart, Name: "http_fetch"}
+	select {
+	case <-time.After(s.pause):
+	case <-ctx.Done():
+		return AgentResult{}, ctx.Err()
+	}
+	ch <- core.StreamEvent{Type: core.EventToolCallResult, Name: "http_fetch"}
+	return AgentResult{Output: "fetched"}, nil
+}
+
+func TestServeSSEWithKeepAlive(t *testing.T) {
+	rec := httptest.NewRecorder()
+	result, err := ServeSSEWithKeepAlive(context.Background(), rec, slowStreamingAgent{pause: 100 * time.Millisecond}, AgentTask{Input: "fetch"}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "fetched" {
+		t.Errorf("Output = %q, want %q", result.Output, "fetched")
+	}
+
+	body := rec.Body.String()
+	if !strings.Contains(body, ": keepalive\n\n") {
+		t.Fatalf("expected keepalive comment during the silent gap, got:\n%s", body)
+	}
+	// Keep-alives fall between the two tool events, never inside one.
+	start := strings.Index(body, "event: tool-call-start")
+	ka := strings.Index(body, ": keepalive")
+	end := strings.Index(body, "event: tool-call-result")
+	if !(start < ka && ka < end) {
+		t.Errorf("keepalive not between events (start=%d keepalive=%d result=%d)", start, ka, end)
+	}
+	if !strings.HasSuffix(body, "\n\n") || strings.Count(body, "event: done") != 1 {
+		t.Errorf("stream did not end with a single done event:\n%s", body)
+	}
+
+	// Ticker stops on completion: nothing more is written after return.
+	n := rec.Body.Len()
+	time.Sleep(30 * time.Millisecond)
+	if rec.Body.Len() != n {
+		t.Error("keepalive written after ServeSSEWithKeepAlive returned")
+	}
+}
+
+func TestServeSSEWithKeepAlive_Disabled(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if _, err := ServeSSEWithKeepAlive(context.Background(), rec, slowStreamingAgent{pause: 30 * time.Millisecond}, AgentTask{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(rec.Body.String(), "keepalive") {
+		t.Errorf("interval 0 must not emit keepalives:\n%s", rec.Body.String())
+	}
+}
+
+// --- NDJSON tests ---
+
+func TestServeNDJSON(t *testing.T) {
+	agent := &stubStreamingAgent{
+		name: "test",
+		desc: "test agent",
+		events: []core.StreamEvent{
+			{Type: core.EventTextDelta, Content: "Hello\nworld"},
+			{Type: core.EventToolCallStart, Name: "search", Args: json.RawMessage(`{"q":"test"}`)},
+		},
+		result: AgentResult{Output: "Hello world"},
+	}
+
+	rec := httptest.NewRecorder()
+	result, err := ServeNDJSON(context.Background(), rec, agent, AgentTask{Input: "say hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "Hello world" {
+		t.Errorf("result.Output = %q, want %q", result.Output, "Hello world")
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want %q", ct, "application/x-ndjson")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	if len(lines) != 3 { // 2 events + 1 done
+		t.Fatalf("expected 3 lines, got %d:\n%s", len(lines), rec.Body.String())
+	}
+	var ev core.StreamEvent
+	if err := json.Unmarshal([]byte(lines[0]), &ev); err != nil {
+		t.Fatalf("line 0 is not a StreamEvent: %v", err)
+	}
+	if ev.Type != core.EventTextDelta || ev.Content != "Hello\nworld" {
+		t.Errorf("line 0 = %+v, want text-delta with embedded newline", ev)
+	}
+
+	var done struct {
+		Type   string      `json:"type"`
+		Result AgentResult `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &done); err != nil {
+		t.Fatalf("failed to parse final line: %v", err)
+	}
+	if done.Type != "done" || done.Result.Output != "Hello world" {
+		t.Errorf("final line = %+v, want done with result", done)
+	}
+}
+
+func TestServeNDJSON_AgentError(t *testing.T) {
+	agent := &stubStreamingAgent{
+		name:   "fail",
+		desc:   "fails",
+		events: []core.StreamEvent{{Type: core.EventTextDelta, Content: "partial"}},
+		err:    errors.New("provider timeout"),
+	}
+
+	rec := httptest.NewRecorder()
+	_, err := ServeNDJSON(context.Background(), rec, agent, AgentTask{Input: "fail"})
+	if err == nil || err.Error() != "provider timeout" {
+		t.Fatalf("err = %v, want provider timeout", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	var last map[string]string
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if last["type"] != "error" || last["error"] != "provider timeout" {
+		t.Errorf("final line = %v, want error/provider timeout", last)
+	}
+}
+
+func TestServeNDJSON_NoFlusher(t *testing.T) {
+	w := &nonFlusher{header: http.Header{}}
+	_, err := ServeNDJSON(context.Background(), w, &stubStreamingAgent{}, AgentTask{})
+	if err == nil || !strings.Contains(err.Error(), "Flusher") {
+		t.Fatalf("err = %v, want mention of Flusher", err)
+	}
+}
+
 // TestStreamDispatch_LogsPanic verifies that a panic inside a subscriber
 // callback is recovered and logged (not silently swallowed) per ENGINEERING.md
 // "Errors must be observable."
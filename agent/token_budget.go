@@ -0,0 +1,49 @@
+package agent
+
+import "fmt"
+
+// ErrBudgetExceeded is returned when a run's accumulated input+output tokens
+// reach the WithTokenBudget ceiling. The check runs before each of the loop's
+// LLM calls, so the call that crossed the line completes and Used may exceed
+// Budget. The
+// AgentResult returned alongside carries the usage and steps spent so far.
+type ErrBudgetExceeded struct {
+	Budget int
+	Used   int
+}
+
+func (e *ErrBudgetExceeded) Error() string {
+	return fmt.Sprintf("token budget exceeded: used %d of %d tokens", e.Used, e.Budget)
+}
+
+// WithTokenBudget caps the tokens (input + output, summed across every LLM
+// call) a single Execute may spend. Once the total reaches maxTokens the loop
+// makes no further LLM calls — no auto-continue or schema retry, no context
+// compression, no forced synthesis at MaxIter — and returns
+// *ErrBudgetExceeded with the partial result. Calls made by tools (subagents,
+// plan_execute steps) count once they return. It guards against runaway tool
+// loops that MaxIter alone bounds too loosely. 0 disables it.
+func WithTokenBudget(maxTokens int) AgentOption {
+	return func(c *Config) { c.TokenBudget = maxTokens }
+}
+
+// checkTokenBudget reports *ErrBudgetExceeded once the run's usage reaches
+// cfg.TokenBudget.
+func checkTokenBudget(cfg *LoopConfig, state *loopState) error {
+	if !overTokenBudget(cfg, state) {
+		return nil
+	}
+	used := state.totalUsage.InputTokens + state.totalUsage.OutputTokens
+	cfg.Logger.Warn("token budget exceeded, stopping run", "agent", cfg.Name,
+		"budget", cfg.TokenBudget, "used", used)
+	return &ErrBudgetExceeded{Budget: cfg.TokenBudget, Used: used}
+}
+
+// overTokenBudget reports whether the run's usage has reached
+// cfg.TokenBudget. Used to skip LLM calls made between iterations, such as
+// context compression, which the next iteration's checkTokenBudget would
+// make pointless.
+func overTokenBudget(cfg *LoopConfig, state *loopState) bool {
+	return cfg.TokenBudget > 0 &&
+		state.totalUsage.InputTokens+state.totalUsage.OutputTokens >= cfg.TokenBudget
+}
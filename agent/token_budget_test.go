@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/memory"
+)
+
+func TestWithTokenBudget(t *testing.T) {
+	call := core.ChatResponse{
+		ToolCalls: []core.ToolCall{{ID: "1", Name: "greet", Args: json.RawMessage(`{}`)}},
+		Usage:     core.Usage{InputTokens: 100, OutputTokens: 50},
+	}
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{call, call, call, {Content: "done"}}}
+	a := New("looper", "", provider, WithTools(mockTool{}), WithTokenBudget(250))
+
+	result, err := a.Execute(context.Background(), AgentTask{Input: "go"})
+	var be *ErrBudgetExceeded
+	if !errors.As(err, &be) {
+		t.Fatalf("err = %v, want *ErrBudgetExceeded", err)
+	}
+	if be.Budget != 250 || be.Used != 300 {
+		t.Errorf("budget error = %+v, want Budget 250, Used 300", be)
+	}
+	if provider.idx != 2 {
+		t.Errorf("LLM calls = %d, want 2", provider.idx)
+	}
+	if result.Usage.InputTokens != 200 || result.Usage.OutputTokens != 100 {
+		t.Errorf("usage = %+v, want the two calls' tokens", result.Usage)
+	}
+	if len(result.Steps) == 0 {
+		t.Error("partial result has no steps")
+	}
+}
+
+func TestWithTokenBudget_UnderBudget(t *testing.T) {
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{
+		{Content: "done", Usage: core.Usage{InputTokens: 100, OutputTokens: 50}},
+	}}
+	a := New("cheap", "", provider, WithTokenBudget(1000))
+	result, err := a.Execute(context.Background(), AgentTask{Input: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "done" {
+		t.Errorf("Output = %q, want done", result.Output)
+	}
+}
+
+func TestWithTokenBudget_SkipsCompression(t *testing.T) {
+	call := core.ChatResponse{
+		ToolCalls: []core.ToolCall{{ID: "1", Name: "greet", Args: json.RawMessage(`{}`)}},
+		Usage:     core.Usage{InputTokens: 100, OutputTokens: 50},
+	}
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{call, call, call, call, {Content: "done"}}}
+	summarizer := &mockProvider{name: "summarizer", responses: []core.ChatResponse{{Content: "s"}, {Content: "s"}, {Content: "s"}}}
+	compressModel := func(context.Context, core.AgentTask) core.Provider { return summarizer }
+	a := New("looper", "", provider, WithTools(mockTool{}), WithTokenBudget(400),
+		WithMemory(memory.WithCompress(compressModel, 1)))
+
+	if _, err := a.Execute(context.Background(), AgentTask{Input: "go"}); err == nil {
+		t.Fatal("want *ErrBudgetExceeded")
+	}
+	// The third iteration spends the budget, so the compression it would
+	// trigger is skipped; only the first iteration's runs.
+	if summarizer.idx != 1 {
+		t.Errorf("compression calls = %d, want 1", summarizer.idx)
+	}
+}
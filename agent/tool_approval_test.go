@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/nevindra/oasis/core"
@@ -149,3 +150,48 @@ func TestToolApproval_EmitsPendingEvent(t *testing.T) {
 		t.Errorf("expected EventToolApprovalPending in stream, got: %v", got)
 	}
 }
+
+func TestWithToolApproval_GatesNamedTools(t *testing.T) {
+	handler := &fakeInputHandler{approve: false}
+	called := false
+	other := &countingTool{name: "other"}
+
+	ag := New("test", "", &callbackProvider{},
+		WithTools(&recordingTool{called: &called}, other),
+		WithInputHandler(handler),
+		WithToolApproval("rec"),
+	)
+
+	result, err := ag.Tools().Execute(context.Background(), "rec", json.RawMessage(`{"path":"/etc/hosts"}`))
+	if err != nil {
+		t.Fatalf("Execute err = %v", err)
+	}
+	if called {
+		t.Error("tool should NOT have run on deny")
+	}
+	if !strings.Contains(result.Error, "denied") {
+		t.Errorf("result.Error = %q, want denial fed back to the LLM", result.Error)
+	}
+	want := "Approve call to rec?\nArguments: {\"path\":\"/etc/hosts\"}"
+	if handler.lastReq.Question != want {
+		t.Errorf("Question = %q, want %q", handler.lastReq.Question, want)
+	}
+
+	if _, err := ag.Tools().Execute(context.Background(), "other", json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if handler.calls != 1 || other.calls != 1 {
+		t.Errorf("ungated tool: handler calls = %d, tool calls = %d; want 1, 1", handler.calls, other.calls)
+	}
+}
+
+func TestApprovalDefaultPrompt_TruncatesArgs(t *testing.T) {
+	long := `{"cmd":"` + strings.Repeat("x", 1000) + `"}`
+	q := Approval("shell_exec").Prompt(core.ToolCall{Name: "shell_exec", Args: json.RawMessage(long)})
+	if !strings.HasSuffix(q, "…") || len(q) > approvalArgsPreview+100 {
+		t.Errorf("prompt not truncated: %d bytes", len(q))
+	}
+	if q := Approval("x").Prompt(core.ToolCall{Name: "x", Args: json.RawMessage(`{}`)}); q != "Approve call to x?" {
+		t.Errorf("empty args prompt = %q", q)
+	}
+}
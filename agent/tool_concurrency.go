@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// ConcurrencyLimitMiddleware caps how many calls to each named tool run at
+// once: limits maps a tool name to its maximum in-flight calls. A call over
+// the cap waits for a slot; if ctx ends first it returns ctx.Err() without
+// running the tool. Tools not in limits, or with a limit below 1, are left
+// unwrapped. Slots are shared by every agent the middleware is installed on,
+// so one instance can protect a rate-limited API across agents.
+func ConcurrencyLimitMiddleware(limits map[string]int) core.ToolMiddleware {
+	sems := make(map[string]chan struct{}, len(limits))
+	for name, n := range limits {
+		if n > 0 {
+			sems[name] = make(chan struct{}, n)
+		}
+	}
+	return func(inner core.AnyTool) core.AnyTool {
+		sem, ok := sems[inner.Name()]
+		if !ok {
+			return inner
+		}
+		if st, ok := inner.(core.StreamingAnyTool); ok {
+			return &concurrencyStreamingWrapper{concurrencyWrapper{inner: inner, sem: sem}, st}
+		}
+		return &concurrencyWrapper{inner: inner, sem: sem}
+	}
+}
+
+// WithToolConcurrency installs ConcurrencyLimitMiddleware on the agent's
+// tools, e.g. map[string]int{"http_fetch": 2}. It complements
+// WithMaxParallelTools, which bounds all tool calls of one LLM response.
+func WithToolConcurrency(limits map[string]int) AgentOption {
+	mw := ConcurrencyLimitMiddleware(limits)
+	return func(c *Config) { c.ToolMiddleware = append(c.ToolMiddleware, mw) }
+}
+
+type concurrencyWrapper struct {
+	inner core.AnyTool
+	sem   chan struct{}
+}
+
+func (w *concurrencyWrapper) run(ctx context.Context, call func() (core.ToolResult, error)) (core.ToolResult, error) {
+	select {
+	case w.sem <- struct{}{}:
+	case <-ctx.Done():
+		return core.ToolResult{}, ctx.Err()
+	}
+	defer func() { <-w.sem }()
+	return call()
+}
+
+func (w *concurrencyWrapper) Name() string                    { return w.inner.Name() }
+func (w *concurrencyWrapper) Definition() core.ToolDefinition { return w.inner.Definition() }
+func (w *concurrencyWrapper) ExecuteRaw(ctx context.Context, args json.RawMessage) (core.ToolResult, error) {
+	return w.run(ctx, func() (core.ToolResult, error) {
+		return w.inner.ExecuteRaw(ctx, args)
+	})
+}
+
+type concurrencyStreamingWrapper struct {
+	concurrencyWrapper
+	st core.StreamingAnyTool
+}
+
+func (w *concurrencyStreamingWrapper) ExecuteStream(ctx context.Context, args json.RawMessage, ch chan<- core.StreamEvent) (core.ToolResult, error) {
+	return w.run(ctx, func() (core.ToolResult, error) {
+		return w.st.ExecuteStream(ctx, args, ch)
+	})
+}
+
+// compile-time checks
+var (
+	_ core.AnyTool          = (*concurrencyWrapper)(nil)
+	_ core.StreamingAnyTool = (*concurrencyStreamingWrapper)(nil)
+)
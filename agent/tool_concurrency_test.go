@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// gaugeTool records the peak number of its calls running at once.
+type gaugeTool struct {
+	name       string
+	inFlight   atomic.Int32
+	peak       atomic.Int32
+	hold       time.Duration
+	blockUntil chan struct{}
+}
+
+func (g *gaugeTool) Name() string                    { return g.name }
+func (g *gaugeTool) Definition() core.ToolDefinition { return core.ToolDefinition{Name: g.name} }
+func (g *gaugeTool) ExecuteRaw(ctx context.Context, _ json.RawMessage) (core.ToolResult, error) {
+	n := g.inFlight.Add(1)
+	defer g.inFlight.Add(-1)
+	for {
+		p := g.peak.Load()
+		if n <= p || g.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	if g.blockUntil != nil {
+		<-g.blockUntil
+	}
+	time.Sleep(g.hold)
+	return core.ToolResult{Content: "ok"}, nil
+}
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	fetch := &gaugeTool{name: "http_fetch", hold: 10 * time.Millisecond}
+	mw := ConcurrencyLimitMiddleware(map[string]int{"http_fetch": 2})
+	tool := mw(fetch)
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tool.ExecuteRaw(context.Background(), nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if p := fetch.peak.Load(); p != 2 {
+		t.Errorf("peak concurrency = %d, want 2", p)
+	}
+
+	other := &gaugeTool{name: "other"}
+	if mw(other) != core.AnyTool(other) {
+		t.Error("tool without a limit should be returned unwrapped")
+	}
+}
+
+func TestConcurrencyLimitMiddleware_ContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+	slow := &gaugeTool{name: "slow", blockUntil: release}
+	tool := ConcurrencyLimitMiddleware(map[string]int{"slow": 1})(slow)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tool.ExecuteRaw(context.Background(), nil)
+	}()
+	for slow.inFlight.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := tool.ExecuteRaw(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded while the slot is held", err)
+	}
+	close(release)
+	<-done
+}
+
+func TestWithMaxParallelTools_Sequential(t *testing.T) {
+	tool := &gaugeTool{name: "work", hold: 5 * time.Millisecond}
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{
+		{ToolCalls: []core.ToolCall{
+			{ID: "1", Name: "work", Args: json.RawMessage(`{}`)},
+			{ID: "2", Name: "work", Args: json.RawMessage(`{}`)},
+			{ID: "3", Name: "work", Args: json.RawMessage(`{}`)},
+		}},
+		{Content: "done"},
+	}}
+	a := New("seq", "", provider, WithTools(tool), WithMaxParallelTools(1))
+	if _, err := a.Execute(context.Background(), AgentTask{Input: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	if p := tool.peak.Load(); p != 1 {
+		t.Errorf("peak concurrency = %d, want 1", p)
+	}
+}
+
+// orderTool records the IDs it is called with, in call order.
+type orderTool struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (o *orderTool) Name() string                    { return "step" }
+func (o *orderTool) Definition() core.ToolDefinition { return core.ToolDefinition{Name: "step"} }
+func (o *orderTool) ExecuteRaw(_ context.Context, args json.RawMessage) (core.ToolResult, error) {
+	var in struct{ ID string }
+	json.Unmarshal(args, &in)
+	// Earlier calls run longer, so parallel dispatch would finish them last.
+	time.Sleep(time.Duration(5-len(in.ID)) * time.Millisecond)
+	o.mu.Lock()
+	o.order = append(o.order, in.ID)
+	o.mu.Unlock()
+	return core.ToolResult{Content: in.ID}, nil
+}
+
+func TestWithSequentialTools_EmittedOrder(t *testing.T) {
+	tool := &orderTool{}
+	var calls []core.ToolCall
+	for _, id := range []string{"a", "bb", "ccc", "dddd"} {
+		calls = append(calls, core.ToolCall{ID: id, Name: "step", Args: json.RawMessage(`{"ID":"` + id + `"}`)})
+	}
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{{ToolCalls: calls}, {Content: "done"}}}
+	a := New("seq", "", provider, WithTools(tool), WithSequentialTools())
+	if _, err := a.Execute(context.Background(), AgentTask{Input: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(tool.order); got != "[a bb ccc dddd]" {
+		t.Errorf("execution order = %s, want emitted order", got)
+	}
+}
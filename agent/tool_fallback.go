@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// toolCallFence opens the block the model writes to call tools in fallback
+// mode. The closing fence is a plain "```".
+const toolCallFence = "```tool_calls"
+
+// supportsTools reports whether p calls tools natively. Providers that do not
+// implement core.ToolSupportProvider are assumed to.
+func supportsTools(p core.Provider) bool {
+	ts, ok := p.(core.ToolSupportProvider)
+	return !ok || ts.SupportsTools()
+}
+
+// toolCallFallback adapts a provider without native function calling to the
+// loop's tool protocol. Each request's tools move into the system prompt;
+// earlier tool calls and results are rewritten as plain text; and a
+// tool_calls block in the reply is parsed back into ToolCalls. The loop
+// itself sees an ordinary tool-calling provider.
+type toolCallFallback struct {
+	inner core.Provider
+}
+
+func (f toolCallFallback) Name() string { return f.inner.Name() }
+
+// ChatStream calls the inner provider without streaming — a half-written
+// tool_calls block must not reach the caller as text — and emits the final
+// text, if any, as a single delta.
+func (f toolCallFallback) ChatStream(ctx context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+	if ch != nil {
+		defer close(ch)
+	}
+	tools := req.Tools
+	req.Tools = nil
+	msgs, err := fallbackMessages(req.Messages, tools)
+	if err != nil {
+		return core.ChatResponse{}, err
+	}
+	req.Messages = msgs
+
+	resp, err := core.Chat(ctx, f.inner, req)
+	if err != nil {
+		return resp, err
+	}
+	resp.Content, resp.ToolCalls = parseFallbackToolCalls(resp.Content, tools)
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = core.FinishToolCalls
+	} else if ch != nil && resp.Content != "" {
+		select {
+		case ch <- core.StreamEvent{Type: core.EventTextDelta, Content: resp.Content}:
+		case <-ctx.Done():
+		}
+	}
+	return resp, nil
+}
+
+// fallbackMessages returns a copy of msgs the inner provider can accept: the
+// tool catalog is appended to the system prompt, assistant tool calls are
+// rendered as tool_calls blocks, and runs of tool results become one user
+// message. It fails when a tool call's arguments are not valid JSON.
+func fallbackMessages(msgs []core.ChatMessage, tools []core.ToolDefinition) ([]core.ChatMessage, error) {
+	out := make([]core.ChatMessage, 0, len(msgs)+1)
+	prompt := toolCallPrompt(tools)
+	if len(msgs) > 0 && msgs[0].Role == core.RoleSystem {
+		sys := msgs[0]
+		sys.Content += "\n\n" + prompt
+		out = append(out, sys)
+		msgs = msgs[1:]
+	} else {
+		out = append(out, core.SystemMessage(prompt))
+	}
+
+	names := make(map[string]string) // tool call ID → tool name
+	results := -1                    // index in out of the open tool-results message
+	for _, m := range msgs {
+		switch {
+		case m.Role == core.RoleAssistant && len(m.ToolCalls) > 0:
+			calls := make([]fallbackCall, len(m.ToolCalls))
+			for i, tc := range m.ToolCalls {
+				names[tc.ID] = tc.Name
+				calls[i] = fallbackCall{Name: tc.Name, Arguments: tc.Args}
+			}
+			block, err := json.Marshal(calls)
+			if err != nil {
+				return nil, fmt.Errorf("render tool calls: %w", err)
+			}
+			m.Content = strings.TrimSpace(m.Content + "\n" + toolCallFence + "\n" + string(block) + "\n```")
+			m.ToolCalls = nil
+			m.Metadata = nil
+			out = append(out, m)
+		case m.Role == core.RoleTool:
+			text := fmt.Sprintf("Result of %s:\n%s", names[m.ToolCallID], m.Content)
+			if results >= 0 {
+				out[results].Content += "\n\n" + text
+				out[results].Attachments = append(out[results].Attachments, m.Attachments...)
+				continue
+			}
+			results = len(out)
+			out = append(out, core.ChatMessage{Role: core.RoleUser, Content: text, Attachments: m.Attachments})
+			continue
+		default:
+			out = append(out, m)
+		}
+		results = -1
+	}
+	return out, nil
+}
+
+// fallbackCall is one entry of a tool_calls block.
+type fallbackCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// toolCallPrompt describes tools and the reply format for calling them.
+func toolCallPrompt(tools []core.ToolDefinition) string {
+	var b strings.Builder
+	b.WriteString("# Tools\n\n")
+	b.WriteString("You can call the tools below. To call tools, reply with a block in exactly this form, ")
+	b.WriteString("listing one or more calls, and nothing after it:\n\n")
+	b.WriteString(toolCallFence + "\n")
+	b.WriteString(`[{"name": "tool_name", "arguments": {"param": "value"}}]` + "\n```\n\n")
+	b.WriteString("The results come back in the next user message. ")
+	b.WriteString("When no tool is needed, answer normally without the block.\n\n")
+	b.WriteString("Available tools:\n")
+	for _, t := range tools {
+		fmt.Fprintf(&b, "\n## %s\n%s\n", t.Name, t.Description)
+		if len(t.Parameters) > 0 {
+			fmt.Fprintf(&b, "Parameters (JSON Schema): %s\n", t.Parameters)
+		}
+	}
+	return b.String()
+}
+
+// parseFallbackToolCalls extracts the tool_calls block from content. It
+// returns the text before the block and the calls naming known tools. When
+// there is no block, or it does not parse, content is returned unchanged so
+// the reply is treated as a final answer.
+func parseFallbackToolCalls(content string, tools []core.ToolDefinition) (string, []core.ToolCall) {
+	start := strings.Index(content, toolCallFence)
+	if start < 0 {
+		return content, nil
+	}
+	body := content[start+len(toolCallFence):]
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	body = strings.TrimSpace(body)
+
+	var calls []fallbackCall
+	if err := json.Unmarshal([]byte(body), &calls); err != nil {
+		var one fallbackCall
+		if err := json.Unmarshal([]byte(body), &one); err != nil {
+			return content, nil
+		}
+		calls = []fallbackCall{one}
+	}
+
+	known := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		known[t.Name] = true
+	}
+	var out []core.ToolCall
+	for _, c := range calls {
+		if !known[c.Name] {
+			continue
+		}
+		args := c.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage(`{}`)
+		}
+		out = append(out, core.ToolCall{ID: "call_" + core.NewID(), Name: c.Name, Args: args})
+	}
+	if len(out) == 0 {
+		return content, nil
+	}
+	return strings.TrimSpace(content[:start]), out
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// toollessProvider is a mockProvider that reports no native tool support.
+type toollessProvider struct{ *mockProvider }
+
+func (toollessProvider) SupportsTools() bool { return false }
+
+func TestToolCallFallback(t *testing.T) {
+	var reqs []core.ChatRequest
+	mp := &mockProvider{
+		name: "plain",
+		responses: []core.ChatResponse{
+			{Content: "Let me look.\n```tool_calls\n[{\"name\": \"read\", \"arguments\": {\"path\": \"a.txt\"}}, {\"name\": \"nope\"}]\n```"},
+			{Content: "The file says hi."},
+		},
+		onChat: func(req *core.ChatRequest) { reqs = append(reqs, *req) },
+	}
+	a := New("a", "d", toollessProvider{mp}, WithPrompt("Be brief."), WithTools(readTool{}), WithToolCallFallback())
+	result, err := a.Execute(context.Background(), AgentTask{Input: "what is in a.txt?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "The file says hi." {
+		t.Errorf("Output = %q", result.Output)
+	}
+	var calls []StepTrace
+	for _, s := range result.Steps {
+		if s.Type == "tool" {
+			calls = append(calls, s)
+		}
+	}
+	if len(calls) != 1 || calls[0].Name != "read" || calls[0].Output != "did read" {
+		t.Fatalf("tool steps = %+v, want one read call (unknown tools dropped)", calls)
+	}
+	if len(reqs) != 2 {
+		t.Fatalf("provider called %d times, want 2", len(reqs))
+	}
+	first := reqs[0]
+	if len(first.Tools) != 0 {
+		t.Error("tools sent natively to a provider without tool support")
+	}
+	if sys := first.Messages[0]; sys.Role != core.RoleSystem || !strings.HasPrefix(sys.Content, "Be brief.") || !strings.Contains(sys.Content, "## read\nRead file") {
+		t.Errorf("system prompt = %q, want the agent prompt plus the tool catalog", sys.Content)
+	}
+	for _, m := range reqs[1].Messages {
+		if m.Role == core.RoleTool || len(m.ToolCalls) > 0 {
+			t.Errorf("native tool message reached the provider: %+v", m)
+		}
+	}
+	last := reqs[1].Messages[len(reqs[1].Messages)-1]
+	if last.Role != core.RoleUser || last.Content != "Result of read:\ndid read" {
+		t.Errorf("last message = %+v, want the tool result as a user message", last)
+	}
+	prev := reqs[1].Messages[len(reqs[1].Messages)-2]
+	if prev.Role != core.RoleAssistant || !strings.Contains(prev.Content, "```tool_calls\n[{\"name\":\"read\",\"arguments\":{\"path\":\"a.txt\"}}]") {
+		t.Errorf("assistant message = %q, want the call rendered as a tool_calls block", prev.Content)
+	}
+}
+
+func TestToolCallFallbackOnlyWhenUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		prov func(*mockProvider) core.Provider
+		opts []AgentOption
+	}{
+		{"native provider", func(m *mockProvider) core.Provider { return m }, []AgentOption{WithToolCallFallback()}},
+		{"fallback not enabled", func(m *mockProvider) core.Provider { return toollessProvider{m} }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []core.ToolDefinition
+			mp := &mockProvider{responses: []core.ChatResponse{{Content: "ok"}}, onChat: func(req *core.ChatRequest) { got = req.Tools }}
+			a := New("a", "d", tt.prov(mp), append(tt.opts, WithTools(readTool{}))...)
+			if _, err := a.Execute(context.Background(), AgentTask{Input: "hi"}); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 {
+				t.Errorf("tools sent = %d, want the native tool list", len(got))
+			}
+		})
+	}
+}
+
+func TestFallbackMessagesRejectsInvalidArgs(t *testing.T) {
+	msgs := []core.ChatMessage{{
+		Role:      core.RoleAssistant,
+		ToolCalls: []core.ToolCall{{ID: "1", Name: "read", Args: json.RawMessage(`{"path":`)}},
+	}}
+	if _, err := fallbackMessages(msgs, nil); err == nil {
+		t.Error("fallbackMessages accepted a tool call with invalid JSON arguments")
+	}
+}
+
+func TestParseFallbackToolCalls(t *testing.T) {
+	tools := []core.ToolDefinition{{Name: "read"}}
+	for _, content := range []string{
+		"no block here",
+		"```tool_calls\nnot json\n```",
+		"```tool_calls\n[{\"name\": \"unknown\"}]\n```",
+	} {
+		if text, calls := parseFallbackToolCalls(content, tools); text != content || calls != nil {
+			t.Errorf("parse(%q) = %q, %v; want content unchanged and no calls", content, text, calls)
+		}
+	}
+	text, calls := parseFallbackToolCalls("ok\n```tool_calls\n{\"name\": \"read\"}\n```", tools)
+	if text != "ok" || len(calls) != 1 || calls[0].Name != "read" || string(calls[0].Args) != "{}" || calls[0].ID == "" {
+		t.Errorf("single object: text=%q calls=%+v", text, calls)
+	}
+}
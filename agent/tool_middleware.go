@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"time"
@@ -80,3 +81,141 @@ func (t *timingWrapper) ExecuteRaw(ctx context.Context, args json.RawMessage) (c
 func OTelSpanMiddleware(tracer core.Tracer) core.ToolMiddleware {
 	return runtime.OTelSpanMiddleware(tracer)
 }
+
+// CacheMiddleware serves repeated calls with identical (tool name, args) from
+// cache for ttl instead of executing the tool again. Only successful results
+// are stored: a Go error or a non-empty ToolResult.Error always passes
+// through uncached. Tools that implement core.CacheableTool and return false,
+// and streaming tools (whose events can't be replayed), are left unwrapped.
+// Tools implementing core.ScopedCacheTool get their scope in the key, so
+// results are shared only within a scope.
+//
+// Install it innermost so other middleware (logging, approval) still sees
+// every call; WithToolCache does this for you.
+func CacheMiddleware(cache core.ToolCache, ttl time.Duration) core.ToolMiddleware {
+	return func(inner core.AnyTool) core.AnyTool {
+		if cache == nil {
+			return inner
+		}
+		if c, ok := inner.(core.CacheableTool); ok && !c.Cacheable() {
+			return inner
+		}
+		if _, ok := inner.(core.StreamingAnyTool); ok {
+			return inner
+		}
+		return &cacheWrapper{inner: inner, cache: cache, ttl: ttl}
+	}
+}
+
+type cacheWrapper struct {
+	inner core.AnyTool
+	cache core.ToolCache
+	ttl   time.Duration
+}
+
+func (c *cacheWrapper) Name() string                    { return c.inner.Name() }
+func (c *cacheWrapper) Definition() core.ToolDefinition { return c.inner.Definition() }
+func (c *cacheWrapper) ExecuteRaw(ctx context.Context, args json.RawMessage) (core.ToolResult, error) {
+	var scope string
+	if s, ok := c.inner.(core.ScopedCacheTool); ok {
+		scope = s.CacheScope(ctx)
+	}
+	key := core.ScopedToolCacheKey(c.inner.Name(), scope, args)
+	if r, ok := c.cache.Get(ctx, key); ok {
+		return r, nil
+	}
+	r, err := c.inner.ExecuteRaw(ctx, args)
+	if err == nil && r.Error == "" {
+		c.cache.Set(ctx, key, r, c.ttl)
+	}
+	return r, err
+}
+
+// ArgsValidationMiddleware checks each call's arguments against the tool's
+// Parameters schema (core.ValidateArgs) before the tool runs. Invalid
+// arguments never reach the tool: the call returns a ToolResult.Error listing
+// each violation, which the model can correct from. Tools without a schema
+// (e.g. deferred MCP tools not yet loaded) are not checked.
+func ArgsValidationMiddleware() core.ToolMiddleware {
+	return func(inner core.AnyTool) core.AnyTool {
+		w := &argsValidationWrapper{inner: inner}
+		if st, ok := inner.(core.StreamingAnyTool); ok {
+			return &argsValidationStreamingWrapper{w, st}
+		}
+		return w
+	}
+}
+
+type argsValidationWrapper struct{ inner core.AnyTool }
+
+// check returns the error result for invalid args, or false when they pass.
+func (w *argsValidationWrapper) check(args json.RawMessage) (core.ToolResult, bool) {
+	schema := w.inner.Definition().Parameters
+	if len(schema) == 0 {
+		return core.ToolResult{}, false
+	}
+	err := core.ValidateArgs(schema, args)
+	if err == nil {
+		return core.ToolResult{}, false
+	}
+	var ae *core.ArgsError
+	if errors.As(err, &ae) {
+		ae.Tool = w.inner.Name()
+	}
+	return core.ToolResult{Error: err.Error() + ". Fix the arguments and call the tool again."}, true
+}
+
+func (w *argsValidationWrapper) Name() string                    { return w.inner.Name() }
+func (w *argsValidationWrapper) Definition() core.ToolDefinition { return w.inner.Definition() }
+func (w *argsValidationWrapper) ExecuteRaw(ctx context.Context, args json.RawMessage) (core.ToolResult, error) {
+	if r, bad := w.check(args); bad {
+		return r, nil
+	}
+	return w.inner.ExecuteRaw(ctx, args)
+}
+
+// Cacheable forwards CacheableTool from the inner tool, so middleware
+// installed outside this one still sees its opt-out. Tools that don't
+// implement it are cacheable.
+func (w *argsValidationWrapper) Cacheable() bool {
+	if c, ok := w.inner.(core.CacheableTool); ok {
+		return c.Cacheable()
+	}
+	return true
+}
+
+// CacheScope forwards ScopedCacheTool from the inner tool.
+func (w *argsValidationWrapper) CacheScope(ctx context.Context) string {
+	if s, ok := w.inner.(core.ScopedCacheTool); ok {
+		return s.CacheScope(ctx)
+	}
+	return ""
+}
+
+// RetrySafe forwards RetrySafeTool from the inner tool. Tools that don't
+// implement it are retry-safe.
+func (w *argsValidationWrapper) RetrySafe() bool {
+	if r, ok := w.inner.(core.RetrySafeTool); ok {
+		return r.RetrySafe()
+	}
+	return true
+}
+
+type argsValidationStreamingWrapper struct {
+	*argsValidationWrapper
+	stream core.StreamingAnyTool
+}
+
+func (w *argsValidationStreamingWrapper) ExecuteStream(ctx context.Context, args json.RawMessage, ch chan<- core.StreamEvent) (core.ToolResult, error) {
+	if r, bad := w.check(args); bad {
+		return r, nil
+	}
+	return w.stream.ExecuteStream(ctx, args, ch)
+}
+
+var (
+	_ core.StreamingAnyTool = (*argsValidationStreamingWrapper)(nil)
+	_ core.CacheableTool    = (*argsValidationWrapper)(nil)
+	_ core.RetrySafeTool    = (*argsValidationWrapper)(nil)
+	_ core.ScopedCacheTool  = (*argsValidationWrapper)(nil)
+)
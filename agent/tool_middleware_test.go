@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/nevindra/oasis/core"
 )
@@ -229,3 +231,176 @@ func (b *testLogBuffer) String() string {
 	defer b.mu.Unlock()
 	return b.buf.String()
 }
+
+type countingTool struct {
+	name      string
+	calls     int
+	fail      bool
+	cacheable *bool
+}
+
+func (c *countingTool) Name() string { return c.name }
+func (c *countingTool) Definition() core.ToolDefinition {
+	return core.ToolDefinition{Name: c.name, Description: "counts calls"}
+}
+func (c *countingTool) ExecuteRaw(_ context.Context, _ json.RawMessage) (core.ToolResult, error) {
+	c.calls++
+	if c.fail {
+		return core.ToolResult{Error: "boom"}, nil
+	}
+	return core.ToolResult{Content: "result"}, nil
+}
+
+type optOutTool struct{ countingTool }
+
+func (*optOutTool) Cacheable() bool { return false }
+
+func TestWithToolCache(t *testing.T) {
+	ctx := context.Background()
+	search := &countingTool{name: "search"}
+	failing := &countingTool{name: "flaky", fail: true}
+	write := &optOutTool{countingTool{name: "write"}}
+
+	ag := New("test", "", &callbackProvider{},
+		WithTools(search, failing, write),
+		WithToolCache(core.NewInMemoryToolCache(), time.Minute),
+	)
+	for _, args := range []string{`{"q":"go","n":1}`, `{"n":1,"q":"go"}`} {
+		r, err := ag.Tools().Execute(ctx, "search", json.RawMessage(args))
+		if err != nil || r.Content != "result" {
+			t.Fatalf("search = %+v, %v", r, err)
+		}
+	}
+	if search.calls != 1 {
+		t.Errorf("search calls = %d, want 1 (second served from cache)", search.calls)
+	}
+	if _, err := ag.Tools().Execute(ctx, "search", json.RawMessage(`{"q":"rust","n":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if search.calls != 2 {
+		t.Errorf("search calls = %d, want 2 after new args", search.calls)
+	}
+
+	for i := 0; i < 2; i++ {
+		ag.Tools().Execute(ctx, "flaky", json.RawMessage(`{}`))
+		ag.Tools().Execute(ctx, "write", json.RawMessage(`{}`))
+	}
+	if failing.calls != 2 {
+		t.Errorf("error results must not be cached: flaky calls = %d", failing.calls)
+	}
+	if write.calls != 2 {
+		t.Errorf("opted-out tool must not be cached: write calls = %d", write.calls)
+	}
+}
+
+// scopedTool is a countingTool whose cache scope is the task's user.
+type scopedTool struct{ countingTool }
+
+func (*scopedTool) CacheScope(ctx context.Context) string {
+	task, _ := TaskFromContext(ctx)
+	return task.UserID
+}
+
+func TestWithToolCache_Scoped(t *testing.T) {
+	tool := &scopedTool{countingTool{name: "history"}}
+	cache := core.NewInMemoryToolCache()
+	ag := New("test", "", &callbackProvider{}, WithTools(tool), WithToolCache(cache, time.Minute))
+
+	for _, user := range []string{"alice", "bob", "alice"} {
+		ctx := WithTaskContext(context.Background(), AgentTask{UserID: user})
+		if _, err := ag.Tools().Execute(ctx, "history", json.RawMessage(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tool.calls != 2 {
+		t.Errorf("calls = %d, want 2 (one per user, alice's repeat cached)", tool.calls)
+	}
+}
+
+// keyTool records the idempotency key of each call and fails its first
+// attempt with a retryable error.
+type keyTool struct{ keys []string }
+
+func (k *keyTool) Name() string                    { return "send" }
+func (k *keyTool) Definition() core.ToolDefinition { return core.ToolDefinition{Name: "send"} }
+func (k *keyTool) ExecuteRaw(ctx context.Context, _ json.RawMessage) (core.ToolResult, error) {
+	key, _ := core.IdempotencyKeyFromContext(ctx)
+	k.keys = append(k.keys, key)
+	if len(k.keys) == 1 {
+		return core.ToolResult{}, core.RetryableError(errors.New("timeout"))
+	}
+	return core.ToolResult{Content: "sent"}, nil
+}
+
+func TestIdempotencyKey_StableAcrossRetries(t *testing.T) {
+	tool := &keyTool{}
+	provider := &mockProvider{name: "test", responses: []core.ChatResponse{
+		{ToolCalls: []core.ToolCall{{ID: "call_1", Name: "send", Args: json.RawMessage(`{}`)}}},
+		{Content: "done"},
+	}}
+	a := New("mailer", "", provider, WithTools(tool), WithToolRetry(2, 0))
+	if _, err := a.Execute(context.Background(), AgentTask{Input: "mail", ThreadID: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	want := core.ToolCallIdempotencyKey("t1", "call_1")
+	if len(tool.keys) != 2 || tool.keys[0] != want || tool.keys[1] != want {
+		t.Errorf("keys = %v, want %q on both attempts", tool.keys, want)
+	}
+}
+
+// schemaTool is a countingTool that declares a Parameters schema.
+type schemaTool struct{ countingTool }
+
+func (s *schemaTool) Definition() core.ToolDefinition {
+	return core.ToolDefinition{Name: s.name, Parameters: json.RawMessage(
+		`{"type":"object","properties":{"limit":{"type":"integer"}},"required":["limit"]}`)}
+}
+
+func TestWithToolArgValidation(t *testing.T) {
+	ctx := context.Background()
+	search := &schemaTool{countingTool{name: "search"}}
+	plain := &countingTool{name: "plain"}
+	ag := New("test", "", &callbackProvider{}, WithTools(search, plain), WithToolArgValidation())
+
+	r, err := ag.Tools().Execute(ctx, "search", json.RawMessage(`{"limit":"ten"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(r.Error, "tool search: invalid arguments: $.limit: expected integer, got string") {
+		t.Errorf("Error = %q", r.Error)
+	}
+	if search.calls != 0 {
+		t.Errorf("search ran %d times with invalid args", search.calls)
+	}
+
+	if r, _ := ag.Tools().Execute(ctx, "search", json.RawMessage(`{"limit":10}`)); r.Error != "" || search.calls != 1 {
+		t.Errorf("valid call: result %+v, calls %d", r, search.calls)
+	}
+	if r, _ := ag.Tools().Execute(ctx, "plain", json.RawMessage(`{"anything":1}`)); r.Error != "" || plain.calls != 1 {
+		t.Errorf("tool without schema: result %+v, calls %d", r, plain.calls)
+	}
+}
+
+// optOutSchemaTool declares a schema and opts out of caching and retries.
+type optOutSchemaTool struct{ schemaTool }
+
+func (*optOutSchemaTool) Cacheable() bool { return false }
+func (*optOutSchemaTool) RetrySafe() bool { return false }
+
+func TestArgsValidationMiddleware_ForwardsOptOuts(t *testing.T) {
+	wrapped := ArgsValidationMiddleware()(&optOutSchemaTool{schemaTool{countingTool{name: "send"}}})
+	if c, ok := wrapped.(core.CacheableTool); !ok || c.Cacheable() {
+		t.Error("wrapper hides the tool's Cacheable opt-out")
+	}
+	if r, ok := wrapped.(core.RetrySafeTool); !ok || r.RetrySafe() {
+		t.Error("wrapper hides the tool's RetrySafe opt-out")
+	}
+
+	plain := ArgsValidationMiddleware()(&countingTool{name: "plain"})
+	if c := plain.(core.CacheableTool); !c.Cacheable() {
+		t.Error("tool without CacheableTool should stay cacheable")
+	}
+	if r := plain.(core.RetrySafeTool); !r.RetrySafe() {
+		t.Error("tool without RetrySafeTool should stay retry-safe")
+	}
+}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/nevindra/oasis/core"
@@ -23,6 +24,11 @@ func runWithPolicy(parent context.Context, policy core.ToolPolicy, fn func(conte
 		retryOn = core.DefaultRetryOn
 	}
 
+	if policy.Retries > 0 {
+		// Lets typed tools surface RetryableError as a Go error.
+		parent = core.WithRetryAttempt(parent)
+	}
+
 	var (
 		result  core.ToolResult
 		lastErr error
@@ -39,6 +45,16 @@ func runWithPolicy(parent context.Context, policy core.ToolPolicy, fn func(conte
 		}
 		result, lastErr = fn(attemptCtx)
 		if cancel != nil {
+			// Name the timeout so the model sees why the call failed rather
+			// than a bare "context deadline exceeded". Only our own deadline
+			// counts; a cancelled parent passes through untouched.
+			if attemptCtx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+				if lastErr != nil {
+					lastErr = fmt.Errorf("tool timed out after %s: %w", policy.Timeout, lastErr)
+				} else if result.Error != "" {
+					result.Error = fmt.Sprintf("tool timed out after %s: %s", policy.Timeout, result.Error)
+				}
+			}
 			cancel()
 		}
 
@@ -285,3 +285,212 @@ func TestNewStandardDispatch_NoPolicyPassthrough(t *testing.T) {
 		t.Errorf("calls = %d, want 1", p.calls)
 	}
 }
+
+// --- WithToolTimeout tests ---
+
+func TestWithToolTimeout_DefaultAndOverride(t *testing.T) {
+	cfg := BuildConfig([]AgentOption{
+		WithToolTimeout(3 * time.Second),
+		WithToolConfig(ToolConfig{Policies: map[string]core.ToolPolicy{
+			"slow":  {Timeout: 30 * time.Second},
+			"retry": {Retries: 2},
+		}}),
+	})
+	if p, ok := cfg.ResolveToolPolicy("any"); !ok || p.Timeout != 3*time.Second {
+		t.Errorf("ResolveToolPolicy(any) = (%v, %v), want default 3s", p, ok)
+	}
+	if p, _ := cfg.ResolveToolPolicy("slow"); p.Timeout != 30*time.Second {
+		t.Errorf("Timeout = %v, want 30s (per-tool override)", p.Timeout)
+	}
+	if p, _ := cfg.ResolveToolPolicy("retry"); p.Timeout != 3*time.Second || p.Retries != 2 {
+		t.Errorf("policy = %+v, want default timeout merged with retries", p)
+	}
+}
+
+func TestWithToolTimeout_ParallelCalls(t *testing.T) {
+	hang := core.Func("hang", "blocks until cancelled", func(ctx context.Context, _ struct{}) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	fast := core.Func("fast", "returns immediately", func(_ context.Context, _ struct{}) (string, error) {
+		return "fast done", nil
+	})
+
+	var toolMsgs []core.ChatMessage
+	provider := &mockProvider{
+		name: "test",
+		responses: []core.ChatResponse{
+			{ToolCalls: []core.ToolCall{
+				{ID: "1", Name: "hang", Args: json.RawMessage(`{}`)},
+				{ID: "2", Name: "fast", Args: json.RawMessage(`{}`)},
+			}},
+			{Content: "done"},
+		},
+		onChat: func(req *core.ChatRequest) {
+			toolMsgs = toolMsgs[:0]
+			for _, m := range req.Messages {
+				if m.Role == core.RoleTool {
+					toolMsgs = append(toolMsgs, m)
+				}
+			}
+		},
+	}
+	a := New("t", "", provider, WithTools(hang, fast), WithToolTimeout(30*time.Millisecond))
+
+	start := time.Now()
+	result, err := a.Execute(context.Background(), AgentTask{Input: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "done" {
+		t.Errorf("Output = %q", result.Output)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("run took %v; the hanging tool was not cut off", elapsed)
+	}
+	if len(toolMsgs) != 2 {
+		t.Fatalf("tool messages = %d, want 2", len(toolMsgs))
+	}
+	if !strings.Contains(toolMsgs[0].Content, "timed out after 30ms") {
+		t.Errorf("hang result = %q, want timeout error", toolMsgs[0].Content)
+	}
+	if !strings.Contains(toolMsgs[1].Content, "fast done") {
+		t.Errorf("fast result = %q", toolMsgs[1].Content)
+	}
+}
+
+// --- WithToolRetry tests ---
+
+// transientTool fails with err for the first fails calls, then succeeds.
+type transientTool struct {
+	name  string
+	fails int32
+	err   error
+	calls atomic.Int32
+}
+
+func (f *transientTool) Name() string { return f.name }
+func (f *transientTool) Definition() core.ToolDefinition {
+	return core.ToolDefinition{Name: f.name, Description: "flaky"}
+}
+func (f *transientTool) ExecuteRaw(context.Context, json.RawMessage) (core.ToolResult, error) {
+	if f.calls.Add(1) <= f.fails {
+		return core.ToolResult{}, f.err
+	}
+	return core.TextResult(f.name + " ok"), nil
+}
+
+// noRetryTool is a transientTool that opts out of default retries.
+type noRetryTool struct{ *transientTool }
+
+func (noRetryTool) RetrySafe() bool { return false }
+
+func TestWithToolRetry(t *testing.T) {
+	transient := core.RetryableError(errors.New("HTTP 503"))
+	tests := []struct {
+		name      string
+		tool      core.AnyTool
+		wantCalls int32
+		wantOK    bool
+	}{
+		{"retries transient errors", &transientTool{name: "search", fails: 2, err: transient}, 3, true},
+		{"gives up after max attempts", &transientTool{name: "search", fails: 5, err: transient}, 3, false},
+		{"terminal error not retried", &transientTool{name: "search", fails: 1, err: errors.New("bad request")}, 1, false},
+		{"opted-out tool not retried", noRetryTool{&transientTool{name: "send", fails: 1, err: transient}}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tt.tool.Name()
+			provider := &mockProvider{responses: []core.ChatResponse{
+				{ToolCalls: []core.ToolCall{{ID: "1", Name: name, Args: json.RawMessage(`{}`)}}},
+				{Content: "done"},
+			}}
+			a := New("t", "", provider, WithTools(tt.tool), WithToolRetry(3, time.Millisecond))
+			result, err := a.Execute(context.Background(), AgentTask{Input: "go"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var calls int32
+			switch tool := tt.tool.(type) {
+			case *transientTool:
+				calls = tool.calls.Load()
+			case noRetryTool:
+				calls = tool.calls.Load()
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if ok := result.Steps[0].Output == name+" ok"; ok != tt.wantOK {
+				t.Errorf("step output = %q, want success=%v", result.Steps[0].Output, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWithToolRetry_ExplicitPolicyWins(t *testing.T) {
+	cfg := BuildConfig([]AgentOption{
+		WithToolRetry(4, time.Second),
+		WithToolConfig(ToolConfig{Policies: map[string]core.ToolPolicy{"pay": {Retries: 1}}}),
+	})
+	if p, ok := cfg.ResolveToolPolicy("any"); !ok || p.Retries != 3 || p.RetryDelay != time.Second {
+		t.Errorf("ResolveToolPolicy(any) = (%+v, %v), want 3 retries at 1s", p, ok)
+	}
+	if p, _ := cfg.ResolveToolPolicy("pay"); p.Retries != 1 {
+		t.Errorf("Retries = %d, want the explicit policy's 1", p.Retries)
+	}
+}
+
+// typedFlaky is a typed tool failing with a retryable error for the first
+// fails calls. unsafe opts it out of default retries.
+type typedFlaky struct {
+	fails  int32
+	unsafe bool
+	calls  atomic.Int32
+}
+
+func (f *typedFlaky) Definition() core.ToolMeta { return core.ToolMeta{Name: "send"} }
+func (f *typedFlaky) RetrySafe() bool           { return !f.unsafe }
+func (f *typedFlaky) Execute(context.Context, struct{}) (string, error) {
+	if f.calls.Add(1) <= f.fails {
+		return "", core.RetryableError(errors.New("HTTP 503"))
+	}
+	return "sent", nil
+}
+
+func TestWithToolRetry_TypedTools(t *testing.T) {
+	run := func(tool *typedFlaky, dynamic bool) {
+		t.Helper()
+		provider := &mockProvider{responses: []core.ChatResponse{
+			{ToolCalls: []core.ToolCall{{ID: "1", Name: "send", Args: json.RawMessage(`{}`)}}},
+			{Content: "done"},
+		}}
+		erased := core.Erase[struct{}, string](tool)
+		opt := WithTools(erased)
+		if dynamic {
+			opt = WithDynamicTools(func(context.Context, AgentTask) []core.AnyTool { return []core.AnyTool{erased} })
+		}
+		a := New("t", "", provider, opt, WithToolRetry(3, time.Millisecond))
+		if _, err := a.Execute(context.Background(), AgentTask{Input: "go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	safe := &typedFlaky{fails: 2}
+	run(safe, false)
+	if n := safe.calls.Load(); n != 3 {
+		t.Errorf("retry-safe typed tool calls = %d, want 3", n)
+	}
+	for _, dynamic := range []bool{false, true} {
+		unsafe := &typedFlaky{fails: 1, unsafe: true}
+		run(unsafe, dynamic)
+		if n := unsafe.calls.Load(); n != 1 {
+			t.Errorf("opted-out typed tool (dynamic=%v) calls = %d, want 1", dynamic, n)
+		}
+	}
+
+	// Without a retry policy a RetryableError stays a business error.
+	res, err := core.Erase[struct{}, string](&typedFlaky{fails: 1}).ExecuteRaw(context.Background(), json.RawMessage(`{}`))
+	if err != nil || res.Error == "" {
+		t.Errorf("ExecuteRaw outside retry = (%+v, %v), want ToolResult.Error only", res, err)
+	}
+}
@@ -64,5 +64,7 @@ func buildStepTrace(tc core.ToolCall, res toolExecResult) StepTrace {
 		RawOutput: res.content,
 		Usage:     res.usage,
 		Duration:  res.duration,
+		Handoff:   res.handoff,
+		DryRun:    res.dryRun,
 	}
 }
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// WithTraceSink hands a core.RunRecord — the task, the full Result.Steps and
+// usage — to sink after every Execute, successful or not. Use it to persist
+// what agents actually do for offline analysis; NewJSONLTraceSink writes
+// one JSON line per run. It is separate from WithTracer, which emits spans.
+func WithTraceSink(sink core.TraceSink) AgentOption {
+	return func(c *Config) { c.TraceSink = sink }
+}
+
+// traceLine is the JSON-lines shape written by NewJSONLTraceSink. It keeps
+// the analysis fields and drops bulky payloads such as attachment bytes.
+type traceLine struct {
+	Time         time.Time         `json:"time"`
+	Agent        string            `json:"agent"`
+	ThreadID     string            `json:"thread_id,omitempty"`
+	UserID       string            `json:"user_id,omitempty"`
+	Input        string            `json:"input"`
+	Output       string            `json:"output"`
+	FinishReason core.FinishReason `json:"finish_reason,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	DurationMS   int64             `json:"duration_ms"`
+	Usage        core.Usage        `json:"usage"`
+	Steps        []core.StepTrace  `json:"steps"`
+}
+
+// NewJSONLTraceSink returns a TraceSink that writes each run to w as one
+// JSON object per line: agent, thread and user IDs, input, output, finish
+// reason, error, duration, usage and steps. Writes are serialized, so w may
+// be a plain file. Write errors are reported to logger (nil = dropped) and
+// never fail the run.
+func NewJSONLTraceSink(w io.Writer, logger *slog.Logger) core.TraceSink {
+	if logger == nil {
+		logger = nopLogger
+	}
+	var mu sync.Mutex
+	return func(ctx context.Context, rec core.RunRecord) {
+		line := traceLine{
+			Time:         rec.Start,
+			Agent:        rec.Agent,
+			ThreadID:     rec.Task.ThreadID,
+			UserID:       rec.Task.UserID,
+			Input:        rec.Task.Input,
+			Output:       rec.Result.Output,
+			FinishReason: rec.Result.FinishReason,
+			DurationMS:   rec.Duration.Milliseconds(),
+			Usage:        rec.Result.Usage,
+			Steps:        rec.Result.Steps,
+		}
+		if rec.Err != nil {
+			line.Error = rec.Err.Error()
+		}
+		b, err := json.Marshal(line)
+		if err != nil {
+			logger.Warn("trace sink: marshal run record", "agent", rec.Agent, "error", err)
+			return
+		}
+		b = append(b, '\n')
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := w.Write(b); err != nil {
+			logger.Warn("trace sink: write run record", "agent", rec.Agent, "error", err)
+		}
+	}
+}
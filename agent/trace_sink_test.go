@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+func TestWithTraceSink(t *testing.T) {
+	var recs []core.RunRecord
+	sink := func(_ context.Context, rec core.RunRecord) { recs = append(recs, rec) }
+	provider := &mockProvider{responses: []core.ChatResponse{
+		{ToolCalls: []core.ToolCall{{ID: "1", Name: "read", Args: json.RawMessage(`{}`)}}},
+		{Content: "done", Usage: core.Usage{InputTokens: 5, OutputTokens: 2}},
+	}}
+	a := New("reader", "", provider, WithTools(readTool{}), WithTraceSink(sink))
+	if _, err := a.Execute(context.Background(), AgentTask{Input: "read it", UserID: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("sink called %d times, want 1", len(recs))
+	}
+	rec := recs[0]
+	if rec.Agent != "reader" || rec.Task.UserID != "u1" || rec.Err != nil || rec.Start.IsZero() {
+		t.Errorf("record = %+v", rec)
+	}
+	if len(rec.Result.Steps) == 0 || rec.Result.Steps[0].Name != "read" || rec.Result.Usage.InputTokens != 5 {
+		t.Errorf("record result = %+v, want the read step and usage", rec.Result)
+	}
+}
+
+func TestJSONLTraceSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLTraceSink(&buf, nil)
+	sink(context.Background(), core.RunRecord{
+		Agent:  "a",
+		Task:   AgentTask{Input: "q", ThreadID: "t1", Attachments: []core.Attachment{core.NewAttachment("image/png", []byte("big"))}},
+		Result: AgentResult{Output: "ans", Steps: []core.StepTrace{{Name: "search", Type: "tool"}}},
+	})
+	sink(context.Background(), core.RunRecord{Agent: "a", Err: errors.New("boom")})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	var first struct {
+		Agent    string           `json:"agent"`
+		ThreadID string           `json:"thread_id"`
+		Input    string           `json:"input"`
+		Output   string           `json:"output"`
+		Steps    []core.StepTrace `json:"steps"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Agent != "a" || first.ThreadID != "t1" || first.Input != "q" || first.Output != "ans" || len(first.Steps) != 1 {
+		t.Errorf("line 1 = %s", lines[0])
+	}
+	if strings.Contains(lines[0], "attachments") {
+		t.Errorf("attachment payload written: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"error":"boom"`) {
+		t.Errorf("line 2 = %s, want the error", lines[1])
+	}
+}
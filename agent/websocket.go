@@ -0,0 +1,308 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// WebSocketTextMessage is the frame type ServeWebSocket writes. It matches
+// the TextMessage constant of gorilla/websocket and the text opcode of RFC 6455.
+const WebSocketTextMessage = 1
+
+// WebSocketConn is the minimal connection surface ServeWebSocket needs.
+// *websocket.Conn from gorilla/websocket satisfies it directly; other
+// libraries (e.g. nhooyr.io/websocket) need a thin adapter.
+//
+// ServeWebSocket serializes calls to WriteMessage, and calls ReadMessage from
+// a single goroutine, matching the one-reader/one-writer contract those
+// libraries impose.
+type WebSocketConn interface {
+	ReadMessage() (messageType int, data []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+}
+
+// WebSocket frame types exchanged by ServeWebSocket. Outbound StreamEvent
+// frames use the event's own "type" field; the values below cover the
+// frames that have no StreamEvent counterpart.
+const (
+	wsFrameDone          = "done"
+	wsFrameError         = "error"
+	wsFrameInputRequest  = "input-request"
+	wsFrameInputResponse = "input-response"
+	wsFrameInterrupt     = "interrupt"
+)
+
+// wsInbound is a client → server frame.
+type wsInbound struct {
+	Type   string   `json:"type"`
+	ID     string   `json:"id,omitempty"`
+	Value  string   `json:"value,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// wsInputRequest is the server → client frame asking the user a question.
+type wsInputRequest struct {
+	Type        string            `json:"type"`
+	ID          string            `json:"id"`
+	Question    string            `json:"question"`
+	Options     []string          `json:"options,omitempty"`
+	MultiSelect bool              `json:"multi_select,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// ServeWebSocket streams an agent's response over a WebSocket connection.
+//
+// Each [StreamEvent] is written as a JSON text frame (the event's "type"
+// field identifies it). When the agent finishes, a terminal frame mirrors
+// [ServeSSE]: {"type":"done","result":<AgentResult>} on success, or
+// {"type":"error","error":"<message>"} on failure.
+//
+// Inbound frames drive the run:
+//
+//	{"type":"interrupt"}                                  cancels the run
+//	{"type":"input-response","id":"...","value":"..."}    answers a question
+//
+// The run gets an [InputHandler] backed by the socket (via a per-call
+// [RunOptions] override), so ask_user and processors that request input send
+// {"type":"input-request","id":"...","question":"...","options":[...]} and
+// block until the matching input-response arrives or the run ends.
+//
+// A read error (client disconnect) or a failed write cancels the agent via
+// ctx, the same as a dropped SSE request. A terminal frame that cannot be
+// written is reported in the returned error.
+//
+// ServeWebSocket is one-shot: it takes ownership of conn and closes it
+// before returning, once its internal reader has exited. A conn that does
+// not implement io.Closer must be closed by the caller, which is what stops
+// that reader. To serve several turns on one connection use a
+// [WebSocketSession].
+func ServeWebSocket(ctx context.Context, conn WebSocketConn, agent core.Agent, task AgentTask) (res AgentResult, err error) {
+	s := NewWebSocketSession(conn)
+	defer func() {
+		if cerr := s.Close(); cerr != nil {
+			err = errors.Join(err, fmt.Errorf("websocket: close: %w", cerr))
+		}
+	}()
+	return s.Serve(ctx, agent, task)
+}
+
+// WebSocketSession serves successive agent turns over one WebSocket
+// connection, as ServeWebSocket does for a single turn. One goroutine reads
+// the connection for the session's lifetime and routes each inbound frame to
+// the turn in progress; frames that arrive between turns are dropped.
+//
+// Create with NewWebSocketSession; call Close when done.
+type WebSocketSession struct {
+	conn WebSocketConn
+	ctx  context.Context // cancelled by Close
+	stop context.CancelFunc
+
+	writeMu sync.Mutex
+
+	mu   sync.Mutex
+	turn *wsTurn // nil between turns
+
+	closed chan struct{} // closed when readLoop exits
+}
+
+// wsTurn is the state of one Serve call, reset when it returns.
+type wsTurn struct {
+	s       *WebSocketSession
+	cancel  context.CancelFunc
+	pending map[string]chan InputResponse // guarded by s.mu
+}
+
+// NewWebSocketSession starts reading conn. The session owns conn from here
+// on: Close closes it if it implements io.Closer (gorilla's *websocket.Conn
+// does).
+func NewWebSocketSession(conn WebSocketConn) *WebSocketSession {
+	ctx, stop := context.WithCancel(context.Background())
+	s := &WebSocketSession{conn: conn, ctx: ctx, stop: stop, closed: make(chan struct{})}
+	go s.readLoop()
+	return s
+}
+
+// Serve runs one turn of agent on task and streams it to the client, with
+// the frames and semantics of ServeWebSocket. Turns run one at a time:
+// Serve fails if another turn is in progress, or once the connection has
+// failed or the session is closed.
+func (s *WebSocketSession) Serve(ctx context.Context, agent core.Agent, task AgentTask) (AgentResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(s.ctx, cancel)()
+
+	turn := &wsTurn{s: s, cancel: cancel, pending: make(map[string]chan InputResponse)}
+	s.mu.Lock()
+	switch {
+	case s.done():
+		s.mu.Unlock()
+		return AgentResult{}, errWebSocketClosed
+	case s.turn != nil:
+		s.mu.Unlock()
+		return AgentResult{}, errors.New("websocket: a turn is already in progress")
+	}
+	s.turn = turn
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.turn = nil
+		s.mu.Unlock()
+	}()
+
+	// Write failures cancel the run rather than stalling it; streamAgent
+	// keeps draining events until the agent observes the cancellation.
+	res, err := streamAgent(ctx, agent, task, func(ev core.StreamEvent) {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		if err := s.write(data); err != nil {
+			cancel()
+		}
+	}, WithOverrides(&RunOptions{InputHandler: turn}))
+
+	if err != nil {
+		errData, _ := json.Marshal(terminalFrame{Type: wsFrameError, Error: err.Error()})
+		if werr := s.write(errData); werr != nil {
+			return res, errors.Join(err, fmt.Errorf("websocket: send error frame: %w", werr))
+		}
+		return res, err
+	}
+
+	doneData, err := json.Marshal(terminalFrame{Type: wsFrameDone, Result: &res})
+	if err != nil {
+		return res, fmt.Errorf("websocket: marshal done frame: %w", err)
+	}
+	if err := s.write(doneData); err != nil {
+		return res, fmt.Errorf("websocket: send done frame: %w", err)
+	}
+	return res, nil
+}
+
+// Close cancels the turn in progress, stops the reader and closes conn if
+// it implements io.Closer, then waits for the reader to exit. Without
+// io.Closer the reader exits once its pending read returns. Safe to call
+// more than once.
+func (s *WebSocketSession) Close() error {
+	s.stop()
+	c, ok := s.conn.(io.Closer)
+	if !ok {
+		return nil
+	}
+	err := c.Close()
+	<-s.closed
+	return err
+}
+
+// done reports whether the reader has exited.
+func (s *WebSocketSession) done() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// write sends one text frame. Serialized because WebSocket libraries allow
+// only one concurrent writer.
+func (s *WebSocketSession) write(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteMessage(WebSocketTextMessage, data)
+}
+
+// readLoop consumes inbound frames until the connection fails or the
+// session is stopped. ReadMessage cannot observe ctx, so a stopped session's
+// reader exits when its pending read returns.
+func (s *WebSocketSession) readLoop() {
+	defer close(s.closed)
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.mu.Lock()
+			if s.turn != nil {
+				s.turn.cancel()
+			}
+			s.mu.Unlock()
+			return
+		}
+		var msg wsInbound
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		s.mu.Lock()
+		turn := s.turn
+		if turn == nil {
+			s.mu.Unlock()
+			continue
+		}
+		switch msg.Type {
+		case wsFrameInterrupt:
+			turn.cancel()
+		case wsFrameInputResponse:
+			if reply, ok := turn.pending[msg.ID]; ok {
+				delete(turn.pending, msg.ID)
+				// Buffered(1) and removed from pending, so this never
+				// blocks the reader.
+				reply <- InputResponse{Value: msg.Value, Values: msg.Values}
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// RequestInput implements InputHandler by sending an input-request frame and
+// waiting for the matching input-response.
+func (t *wsTurn) RequestInput(ctx context.Context, req InputRequest) (InputResponse, error) {
+	s := t.s
+	id := core.NewID()
+	reply := make(chan InputResponse, 1)
+
+	s.mu.Lock()
+	t.pending[id] = reply
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(t.pending, id)
+		s.mu.Unlock()
+	}()
+
+	data, err := json.Marshal(wsInputRequest{
+		Type:        wsFrameInputRequest,
+		ID:          id,
+		Question:    req.Question,
+		Options:     req.Options,
+		MultiSelect: req.MultiSelect,
+		Metadata:    req.Metadata,
+	})
+	if err != nil {
+		return InputResponse{}, fmt.Errorf("marshal input request: %w", err)
+	}
+	if err := s.write(data); err != nil {
+		return InputResponse{}, fmt.Errorf("send input request: %w", err)
+	}
+
+	select {
+	case resp := <-reply:
+		return resp, nil
+	case <-ctx.Done():
+		return InputResponse{}, ctx.Err()
+	case <-s.closed:
+		return InputResponse{}, errWebSocketClosed
+	}
+}
+
+var errWebSocketClosed = errors.New("websocket connection closed")
+
+// compile-time check
+var _ InputHandler = (*wsTurn)(nil)
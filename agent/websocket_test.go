@@ -0,0 +1,363 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// fakeWSConn is an in-memory WebSocketConn. Frames written by the server are
+// recorded and published on out; inbound frames are fed through in.
+type fakeWSConn struct {
+	in     chan []byte
+	out    chan []byte
+	closed chan struct{}
+	once   sync.Once
+
+	mu     sync.Mutex
+	frames [][]byte
+}
+
+func newFakeWSConn() *fakeWSConn {
+	return &fakeWSConn{
+		in:     make(chan []byte, 8),
+		out:    make(chan []byte, 256),
+		closed: make(chan struct{}),
+	}
+}
+
+func (c *fakeWSConn) ReadMessage() (int, []byte, error) {
+	select {
+	case data := <-c.in:
+		return WebSocketTextMessage, data, nil
+	case <-c.closed:
+		return 0, nil, io.EOF
+	}
+}
+
+func (c *fakeWSConn) WriteMessage(_ int, data []byte) error {
+	c.mu.Lock()
+	c.frames = append(c.frames, data)
+	c.mu.Unlock()
+	select {
+	case c.out <- data:
+	default:
+	}
+	return nil
+}
+
+func (c *fakeWSConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeWSConn) send(t *testing.T, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.in <- data
+}
+
+// frameTypes decodes the "type" field of every recorded frame.
+func (c *fakeWSConn) frameTypes(t *testing.T) []string {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	types := make([]string, 0, len(c.frames))
+	for _, f := range c.frames {
+		var probe struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(f, &probe); err != nil {
+			t.Fatalf("frame is not JSON: %s", f)
+		}
+		types = append(types, probe.Type)
+	}
+	return types
+}
+
+func (c *fakeWSConn) lastFrame() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.frames[len(c.frames)-1]
+}
+
+func TestServeWebSocket(t *testing.T) {
+	agent := &stubStreamingAgent{
+		name: "ws",
+		events: []core.StreamEvent{
+			{Type: core.EventTextDelta, Content: "Hello"},
+			{Type: core.EventTextDelta, Content: " world"},
+		},
+		result: AgentResult{Output: "Hello world"},
+	}
+	conn := newFakeWSConn()
+	defer conn.Close()
+
+	result, err := ServeWebSocket(context.Background(), conn, agent, AgentTask{Input: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "Hello world" {
+		t.Errorf("Output = %q, want %q", result.Output, "Hello world")
+	}
+
+	types := conn.frameTypes(t)
+	want := []string{"text-delta", "text-delta", "done"}
+	if len(types) != len(want) {
+		t.Fatalf("frame types = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("frame[%d] type = %q, want %q", i, types[i], want[i])
+		}
+	}
+
+	var done struct {
+		Result AgentResult `json:"result"`
+	}
+	if err := json.Unmarshal(conn.lastFrame(), &done); err != nil {
+		t.Fatal(err)
+	}
+	if done.Result.Output != "Hello world" {
+		t.Errorf("done result output = %q, want %q", done.Result.Output, "Hello world")
+	}
+}
+
+func TestServeWebSocketClosesConn(t *testing.T) {
+	conn := newFakeWSConn()
+	agent := &stubStreamingAgent{name: "ws", result: AgentResult{Output: "ok"}}
+	if _, err := ServeWebSocket(context.Background(), conn, agent, AgentTask{Input: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-conn.closed:
+	default:
+		t.Fatal("conn still open after ServeWebSocket returned")
+	}
+}
+
+func TestServeWebSocketReportsTerminalWriteError(t *testing.T) {
+	conn := &failingWSConn{fakeWSConn: newFakeWSConn()}
+	agent := &stubStreamingAgent{name: "ws", result: AgentResult{Output: "ok"}}
+	_, err := ServeWebSocket(context.Background(), conn, agent, AgentTask{Input: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "send done frame") {
+		t.Fatalf("err = %v, want a send done frame error", err)
+	}
+}
+
+// failingWSConn fails every write.
+type failingWSConn struct{ *fakeWSConn }
+
+func (c *failingWSConn) WriteMessage(int, []byte) error { return errors.New("broken pipe") }
+
+func TestServeWebSocketErrorFrame(t *testing.T) {
+	agent := &stubStreamingAgent{name: "ws", err: errors.New("boom")}
+	conn := newFakeWSConn()
+	defer conn.Close()
+
+	_, err := ServeWebSocket(context.Background(), conn, agent, AgentTask{Input: "hi"})
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("err = %v, want boom", err)
+	}
+
+	var frame map[string]string
+	if err := json.Unmarshal(conn.lastFrame(), &frame); err != nil {
+		t.Fatal(err)
+	}
+	if frame["type"] != "error" || frame["error"] != "boom" {
+		t.Errorf("terminal frame = %v, want error/boom", frame)
+	}
+}
+
+func TestServeWebSocketPanicRecovery(t *testing.T) {
+	conn := newFakeWSConn()
+	defer conn.Close()
+
+	_, err := ServeWebSocket(context.Background(), conn, &panicStreamingAgent{}, AgentTask{Input: "hi"})
+	if err == nil {
+		t.Fatal("expected error from panicking agent")
+	}
+	types := conn.frameTypes(t)
+	if types[len(types)-1] != "error" {
+		t.Errorf("last frame type = %q, want error", types[len(types)-1])
+	}
+}
+
+// blockingAgent emits one event and blocks until ctx is cancelled.
+type blockingAgent struct{}
+
+func (blockingAgent) Name() string        { return "blocker" }
+func (blockingAgent) Description() string { return "blocks until cancelled" }
+func (blockingAgent) Execute(ctx context.Context, _ AgentTask, opts ...RunOption) (AgentResult, error) {
+	ch := core.ApplyRunOptions(opts...).Stream
+	defer close(ch)
+	ch <- core.StreamEvent{Type: core.EventRunStart}
+	<-ctx.Done()
+	return AgentResult{}, ctx.Err()
+}
+
+func TestServeWebSocketInterrupt(t *testing.T) {
+	conn := newFakeWSConn()
+	defer conn.Close()
+
+	go func() {
+		<-conn.out // run-start reached the client
+		conn.send(t, map[string]string{"type": "interrupt"})
+	}()
+
+	_, err := ServeWebSocket(context.Background(), conn, blockingAgent{}, AgentTask{Input: "hi"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestServeWebSocketDisconnectCancels(t *testing.T) {
+	conn := newFakeWSConn()
+
+	go func() {
+		<-conn.out
+		conn.Close()
+	}()
+
+	_, err := ServeWebSocket(context.Background(), conn, blockingAgent{}, AgentTask{Input: "hi"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestServeWebSocketAskUser(t *testing.T) {
+	var sawAskUser bool
+	provider := &mockProvider{
+		name: "test",
+		responses: []core.ChatResponse{
+			{ToolCalls: []core.ToolCall{{ID: "1", Name: core.ToolAskUser, Args: json.RawMessage(`{"question":"Which color?","options":["red","blue"]}`)}}},
+			{Content: "you picked blue"},
+		},
+		onChat: func(req *core.ChatRequest) {
+			for _, td := range req.Tools {
+				if td.Name == core.ToolAskUser {
+					sawAskUser = true
+				}
+			}
+		},
+	}
+	// Built without WithInputHandler: the socket supplies the handler per call.
+	a := New("asker", "asks", provider)
+
+	conn := newFakeWSConn()
+	defer conn.Close()
+
+	answered := make(chan struct{})
+	go func() {
+		defer close(answered)
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case data := <-conn.out:
+				var req struct {
+					Type     string   `json:"type"`
+					ID       string   `json:"id"`
+					Question string   `json:"question"`
+					Options  []string `json:"options"`
+				}
+				if json.Unmarshal(data, &req) != nil || req.Type != "input-request" {
+					continue
+				}
+				if req.Question != "Which color?" || len(req.Options) != 2 {
+					t.Errorf("input-request = %+v", req)
+				}
+				conn.send(t, map[string]string{"type": "input-response", "id": req.ID, "value": "blue"})
+				return
+			case <-timeout:
+				t.Error("no input-request frame received")
+				return
+			}
+		}
+	}()
+
+	result, err := ServeWebSocket(context.Background(), conn, a, AgentTask{Input: "pick"})
+	<-answered
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sawAskUser {
+		t.Error("ask_user tool was not advertised for the socket-backed InputHandler")
+	}
+	if result.Output != "you picked blue" {
+		t.Errorf("Output = %q, want %q", result.Output, "you picked blue")
+	}
+	var answer string
+	for _, step := range result.Steps {
+		if step.Name == core.ToolAskUser {
+			answer = step.Output
+		}
+	}
+	if !strings.Contains(answer, "blue") {
+		t.Errorf("ask_user step output = %q, want the socket answer", answer)
+	}
+}
+
+func TestWebSocketSessionMultipleTurns(t *testing.T) {
+	conn := newFakeWSConn()
+	s := NewWebSocketSession(conn)
+	defer s.Close()
+	defer conn.Close()
+
+	a := &stubStreamingAgent{name: "ws", result: AgentResult{Output: "ok"}}
+	for i := range 2 {
+		res, err := s.Serve(context.Background(), a, AgentTask{Input: "hi"})
+		if err != nil || res.Output != "ok" {
+			t.Fatalf("turn %d: res = %+v, err = %v", i, res, err)
+		}
+	}
+
+	// An interrupt sent between turns must not cancel the next one, and the
+	// reader must still route frames to it.
+	conn.send(t, map[string]string{"type": "interrupt"})
+	time.Sleep(10 * time.Millisecond)
+	go func() {
+		for data := range conn.out {
+			if strings.Contains(string(data), `"run-start"`) {
+				conn.send(t, map[string]string{"type": "interrupt"})
+				return
+			}
+		}
+	}()
+	if _, err := s.Serve(context.Background(), blockingAgent{}, AgentTask{Input: "hi"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("third turn err = %v, want context.Canceled from its own interrupt", err)
+	}
+}
+
+func TestWebSocketSessionClose(t *testing.T) {
+	conn := newFakeWSConn()
+	defer conn.Close()
+	s := NewWebSocketSession(conn)
+
+	go func() {
+		<-conn.out
+		s.Close()
+	}()
+	if _, err := s.Serve(context.Background(), blockingAgent{}, AgentTask{Input: "hi"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled after Close", err)
+	}
+
+	conn.send(t, map[string]string{"type": "noop"}) // lets the stopped reader's pending read return
+	select {
+	case <-s.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reader did not exit after Close")
+	}
+	if _, err := s.Serve(context.Background(), blockingAgent{}, AgentTask{Input: "hi"}); err == nil {
+		t.Error("Serve after Close should fail")
+	}
+}
@@ -2,7 +2,11 @@ package oasis
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/nevindra/oasis/core"
 )
 
 // --- Batch execution ---
@@ -70,3 +74,108 @@ type BatchEmbeddingProvider interface {
 	// Returns one vector per input text group.
 	BatchEmbedResults(ctx context.Context, jobID string) ([][]float32, error)
 }
+
+// defaultBatchPollInterval is used by RunBatch when pollInterval <= 0.
+const defaultBatchPollInterval = 30 * time.Second
+
+// maxBatchPollFailures is how many transient BatchStatus errors in a row
+// RunBatch rides out before giving up on the job.
+const maxBatchPollFailures = 5
+
+// BatchJobError is returned by RunBatch for any failure after the job was
+// submitted. JobID lets the caller resume polling, fetch results later, or
+// cancel the job; State is the job's last known state.
+type BatchJobError struct {
+	JobID string
+	State BatchState
+	Err   error
+}
+
+func (e *BatchJobError) Error() string { return fmt.Sprintf("oasis: batch %s: %v", e.JobID, e.Err) }
+func (e *BatchJobError) Unwrap() error { return e.Err }
+
+// RunBatch executes tasks as a single batch job on provider: it submits one
+// chat request per task (the task input and attachments as a user message),
+// polls BatchStatus every pollInterval (30s when <= 0) until the job ends,
+// and returns one AgentResult per task in task order.
+//
+// Batch requests are single LLM calls: no tools, memory, or processors run.
+// Use an Agent for tasks that need them. Transient BatchStatus errors (per
+// core.DefaultRetryOn) are retried on the next poll, up to 5 in a row.
+// Every failure after submission is a *BatchJobError carrying the job ID,
+// including a job that ends Failed, Cancelled, or Expired. When ctx is
+// cancelled while the job is pending or running, RunBatch cancels the job
+// and returns an error wrapping ctx.Err(), joined with the cancel error if
+// that failed too.
+func RunBatch(ctx context.Context, provider BatchProvider, tasks []AgentTask, pollInterval time.Duration) ([]AgentResult, error) {
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	if pollInterval <= 0 {
+		pollInterval = defaultBatchPollInterval
+	}
+
+	reqs := make([]ChatRequest, len(tasks))
+	for i, t := range tasks {
+		msg := core.UserMessage(t.Input)
+		msg.Attachments = t.Attachments
+		reqs[i] = ChatRequest{Messages: []ChatMessage{msg}}
+	}
+	job, err := provider.BatchChat(ctx, reqs)
+	if err != nil {
+		return nil, fmt.Errorf("oasis: submit batch: %w", err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	failures := 0
+	for job.State != BatchSucceeded {
+		switch job.State {
+		case BatchFailed, BatchCancelled, BatchExpired:
+			return nil, &BatchJobError{JobID: job.ID, State: job.State, Err: fmt.Errorf("ended %s", job.State)}
+		}
+		select {
+		case <-ctx.Done():
+			// A cancelled caller should not leave a billed job behind.
+			err := ctx.Err()
+			if cerr := provider.BatchCancel(context.WithoutCancel(ctx), job.ID); cerr != nil {
+				err = errors.Join(err, fmt.Errorf("cancel: %w", cerr))
+			}
+			return nil, &BatchJobError{JobID: job.ID, State: job.State, Err: err}
+		case <-ticker.C:
+		}
+		polled, err := provider.BatchStatus(ctx, job.ID)
+		if err != nil {
+			if failures++; failures < maxBatchPollFailures && ctx.Err() == nil && core.DefaultRetryOn(err) {
+				continue
+			}
+			return nil, &BatchJobError{JobID: job.ID, State: job.State, Err: fmt.Errorf("poll: %w", err)}
+		}
+		job, failures = polled, 0
+	}
+
+	resps, err := provider.BatchChatResults(ctx, job.ID)
+	if err != nil {
+		return nil, &BatchJobError{JobID: job.ID, State: job.State, Err: fmt.Errorf("results: %w", err)}
+	}
+	if len(resps) != len(tasks) {
+		return nil, &BatchJobError{JobID: job.ID, State: job.State, Err: fmt.Errorf("returned %d responses for %d tasks", len(resps), len(tasks))}
+	}
+	results := make([]AgentResult, len(resps))
+	for i, r := range resps {
+		finish := r.FinishReason
+		if finish == "" {
+			finish = core.FinishStop
+		}
+		results[i] = AgentResult{
+			Output:       r.Content,
+			Thinking:     r.Thinking,
+			Attachments:  r.Attachments,
+			Usage:        r.Usage,
+			FinishReason: finish,
+			Warnings:     r.Warnings,
+			ProviderMeta: r.ProviderMeta,
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,123 @@
+package oasis_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis"
+	"github.com/nevindra/oasis/core"
+)
+
+// fakeBatch is a BatchProvider whose job walks through states, one per
+// BatchStatus call, and echoes each request's input.
+type fakeBatch struct {
+	states    []oasis.BatchState
+	reqs      []oasis.ChatRequest
+	polls     int
+	cancelled string
+	statusErr []error // returned by the first BatchStatus calls, in order
+	cancelErr error
+}
+
+func (f *fakeBatch) BatchChat(_ context.Context, reqs []oasis.ChatRequest) (oasis.BatchJob, error) {
+	f.reqs = reqs
+	return oasis.BatchJob{ID: "job-1", State: oasis.BatchPending}, nil
+}
+
+func (f *fakeBatch) BatchStatus(_ context.Context, id string) (oasis.BatchJob, error) {
+	if len(f.statusErr) > 0 {
+		err := f.statusErr[0]
+		f.statusErr = f.statusErr[1:]
+		return oasis.BatchJob{}, err
+	}
+	state := f.states[min(f.polls, len(f.states)-1)]
+	f.polls++
+	return oasis.BatchJob{ID: id, State: state}, nil
+}
+
+func (f *fakeBatch) BatchChatResults(_ context.Context, _ string) ([]oasis.ChatResponse, error) {
+	out := make([]oasis.ChatResponse, len(f.reqs))
+	for i, r := range f.reqs {
+		out[i] = oasis.ChatResponse{Content: "re: " + r.Messages[0].Content}
+	}
+	return out, nil
+}
+
+func (f *fakeBatch) BatchCancel(_ context.Context, id string) error {
+	f.cancelled = id
+	return f.cancelErr
+}
+
+func TestRunBatch(t *testing.T) {
+	p := &fakeBatch{states: []oasis.BatchState{oasis.BatchRunning, oasis.BatchSucceeded}}
+	tasks := []oasis.AgentTask{{Input: "a"}, {Input: "b"}, {Input: "c"}}
+	results, err := oasis.RunBatch(context.Background(), p, tasks, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.polls != 2 || len(results) != 3 {
+		t.Fatalf("polls = %d, results = %d; want 2 polls and 3 results", p.polls, len(results))
+	}
+	for i, r := range results {
+		if want := "re: " + tasks[i].Input; r.Output != want || r.FinishReason != oasis.FinishStop {
+			t.Errorf("results[%d] = %q (%s), want %q (stop)", i, r.Output, r.FinishReason, want)
+		}
+	}
+}
+
+func TestRunBatch_TerminalFailure(t *testing.T) {
+	for _, state := range []oasis.BatchState{oasis.BatchFailed, oasis.BatchCancelled, oasis.BatchExpired} {
+		p := &fakeBatch{states: []oasis.BatchState{state}}
+		_, err := oasis.RunBatch(context.Background(), p, []oasis.AgentTask{{Input: "a"}}, time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), string(state)) {
+			t.Errorf("state %s: err = %v, want an error naming the state", state, err)
+		}
+	}
+}
+
+func TestRunBatch_CancelsJobOnContextDone(t *testing.T) {
+	p := &fakeBatch{states: []oasis.BatchState{oasis.BatchRunning}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := oasis.RunBatch(ctx, p, []oasis.AgentTask{{Input: "a"}}, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || p.cancelled != "job-1" {
+		t.Fatalf("err = %v, cancelled = %q; want deadline exceeded and job-1 cancelled", err, p.cancelled)
+	}
+}
+
+func TestRunBatch_RetriesTransientPollErrors(t *testing.T) {
+	transient := core.RetryableError(errors.New("HTTP 503"))
+	p := &fakeBatch{
+		states:    []oasis.BatchState{oasis.BatchSucceeded},
+		statusErr: []error{transient, transient},
+	}
+	results, err := oasis.RunBatch(context.Background(), p, []oasis.AgentTask{{Input: "a"}}, time.Millisecond)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("results = %d, err = %v; want 1 result after retries", len(results), err)
+	}
+}
+
+func TestRunBatch_ErrorCarriesJobID(t *testing.T) {
+	p := &fakeBatch{
+		states:    []oasis.BatchState{oasis.BatchRunning},
+		statusErr: []error{errors.New("HTTP 403")},
+	}
+	_, err := oasis.RunBatch(context.Background(), p, []oasis.AgentTask{{Input: "a"}}, time.Millisecond)
+	var be *oasis.BatchJobError
+	if !errors.As(err, &be) || be.JobID != "job-1" || be.State != oasis.BatchPending {
+		t.Fatalf("err = %v, want a *BatchJobError for pending job-1", err)
+	}
+}
+
+func TestRunBatch_ReportsCancelFailure(t *testing.T) {
+	p := &fakeBatch{states: []oasis.BatchState{oasis.BatchRunning}, cancelErr: errors.New("cancel refused")}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := oasis.RunBatch(ctx, p, []oasis.AgentTask{{Input: "a"}}, time.Millisecond)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, p.cancelErr) {
+		t.Fatalf("err = %v, want context.Canceled joined with the cancel error", err)
+	}
+}
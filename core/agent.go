@@ -37,6 +37,10 @@ const (
 	// ToolSpawnAgent is the Network built-in that lets the router add new
 	// children at runtime. Wired by network.WithDynamicSpawning.
 	ToolSpawnAgent = "spawn_agent"
+
+	// ToolTransferToAgent is the handoff tool a Network child calls to pass
+	// the conversation to a sibling. Built by network.HandoffTool.
+	ToolTransferToAgent = "transfer_to_agent"
 )
 
 // Agent is a unit of work that takes a task and returns a result.
@@ -137,6 +141,10 @@ type AgentResult struct {
 	// are NOT here — they post-date the return and live in the ScoreStore /
 	// ScoreSink only. Nil when no inline scorer ran.
 	Scores []Score `json:"scores,omitempty"`
+	// DryRun reports that the agent ran in dry-run mode: the tool calls in
+	// Steps marked DryRun were planned but never executed, and Output was
+	// written without their real results.
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 // ModelFunc resolves the LLM provider per-request.
@@ -209,6 +217,24 @@ type StepTrace struct {
 	Usage Usage `json:"usage"`
 	// Duration is the wall-clock time for this step.
 	Duration time.Duration `json:"duration"`
+	// Handoff is set when the delegation in this step ended with one agent
+	// transferring the conversation to another. Nil otherwise.
+	Handoff *Handoff `json:"handoff,omitempty"`
+	// DryRun marks a tool call the agent would have executed but only
+	// recorded, because it ran in dry-run mode. Output holds the synthetic
+	// result the model saw, not tool output.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Handoff records an agent-to-agent transfer of control inside a Network.
+type Handoff struct {
+	// From is the agent that handed off.
+	From string `json:"from"`
+	// To is the agent that took over. Empty when control went back to the
+	// network's router.
+	To string `json:"to,omitempty"`
+	// Reason is the handing-off agent's explanation, for traces and audit.
+	Reason string `json:"reason,omitempty"`
 }
 
 // IterationTrace records one iteration of the agent's tool-calling loop.
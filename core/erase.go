@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
 )
 
 // Erase converts a Tool[In, Out] into an AnyTool. The JSON Schema for In is
@@ -32,6 +33,33 @@ type erasedTool[In, Out any] struct {
 func (e *erasedTool[In, Out]) Name() string               { return e.def.Name }
 func (e *erasedTool[In, Out]) Definition() ToolDefinition { return e.def }
 
+// Cacheable forwards CacheableTool from the typed tool, so typed tools can
+// opt out of caching. Tools that don't implement it are cacheable.
+func (e *erasedTool[In, Out]) Cacheable() bool {
+	if c, ok := any(e.tool).(CacheableTool); ok {
+		return c.Cacheable()
+	}
+	return true
+}
+
+// CacheScope forwards ScopedCacheTool from the typed tool. Tools that don't
+// implement it share one scope.
+func (e *erasedTool[In, Out]) CacheScope(ctx context.Context) string {
+	if s, ok := any(e.tool).(ScopedCacheTool); ok {
+		return s.CacheScope(ctx)
+	}
+	return ""
+}
+
+// RetrySafe forwards RetrySafeTool from the typed tool. Tools that don't
+// implement it are retry-safe.
+func (e *erasedTool[In, Out]) RetrySafe() bool {
+	if r, ok := any(e.tool).(RetrySafeTool); ok {
+		return r.RetrySafe()
+	}
+	return true
+}
+
 func (e *erasedTool[In, Out]) ExecuteRaw(ctx context.Context, args json.RawMessage) (ToolResult, error) {
 	var in In
 	args = coerceArgs(args)
@@ -39,7 +67,7 @@ func (e *erasedTool[In, Out]) ExecuteRaw(ctx context.Context, args json.RawMessa
 		return ToolResult{Error: "invalid args: " + err.Error()}, nil
 	}
 	out, err := e.tool.Execute(ctx, in)
-	return toolResultFromOut(out, err)
+	return toolResultFromOut(out, err, isRetryAttempt(ctx))
 }
 
 // toolResultFromOut builds the ToolResult for a typed tool invocation's
@@ -55,17 +83,22 @@ func (e *erasedTool[In, Out]) ExecuteRaw(ctx context.Context, args json.RawMessa
 // Error semantics (must match the ToolResult/error contract):
 //   - err is an infra error → ToolResult.Error is set AND the Go error
 //     propagates so the caller can react to infrastructure failures.
-//   - err is a non-infra (business) error → ToolResult.Error is set, Go error
+//   - err is marked with RetryableError and retrying is true (the call runs
+//     under a retry policy, see WithRetryAttempt) → same as an infra error,
+//     since the policy only sees Go errors.
+//   - err is any other (business) error → ToolResult.Error is set, Go error
 //     is nil.
 //   - marshal of out fails → ToolResult.Error carries a "marshal result: "
 //     prefix, Go error is nil (a marshal failure is a tool-output bug, not an
 //     infrastructure failure).
 //   - success → Content is the marshaled JSON; if out implements UIRenderable,
-//     UI is populated and UI.Props aliases the same body bytes as Content.
-func toolResultFromOut[Out any](out Out, err error) (ToolResult, error) {
+//     UI is populated and UI.Props aliases the same body bytes as Content; if
+//     out implements Sourced, Sources is populated.
+func toolResultFromOut[Out any](out Out, err error, retrying bool) (ToolResult, error) {
 	if err != nil {
 		result := ToolResult{Error: err.Error()}
-		if IsInfraError(err) {
+		var r Retryable
+		if IsInfraError(err) || (retrying && errors.As(err, &r) && r.Retryable()) {
 			return result, err
 		}
 		return result, nil
@@ -78,6 +111,9 @@ func toolResultFromOut[Out any](out Out, err error) (ToolResult, error) {
 	if r, ok := any(out).(UIRenderable); ok {
 		res.UI = &UIComponent{Name: r.UIComponent(), Props: body}
 	}
+	if s, ok := any(out).(Sourced); ok {
+		res.Sources = s.Sources()
+	}
 	return res, nil
 }
 
@@ -116,7 +152,7 @@ func (e *erasedStreamingTool[In, Out]) ExecuteStream(ctx context.Context, args j
 		return ToolResult{Error: "invalid args: " + err.Error()}, nil
 	}
 	out, err := e.streamTool.ExecuteStream(ctx, in, ch)
-	return toolResultFromOut(out, err)
+	return toolResultFromOut(out, err, isRetryAttempt(ctx))
 }
 
 // deriveOutSchema returns the OutputSchema to publish for an erased tool.
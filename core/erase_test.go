@@ -367,3 +367,17 @@ func TestErase_UnmarshalErrorReturnsNilGoError(t *testing.T) {
 		t.Errorf("expected invalid args prefix, got %q", res.Error)
 	}
 }
+
+// sideEffectTool is a typed tool that opts out of caching.
+type sideEffectTool struct{ echoTool }
+
+func (sideEffectTool) Cacheable() bool { return false }
+
+func TestErase_ForwardsCacheable(t *testing.T) {
+	if c, ok := Erase[echoInput, echoOutput](&echoTool{}).(CacheableTool); !ok || !c.Cacheable() {
+		t.Error("typed tool without Cacheable should erase to a cacheable tool")
+	}
+	if c, ok := Erase[echoInput, echoOutput](&sideEffectTool{}).(CacheableTool); !ok || c.Cacheable() {
+		t.Error("Cacheable() false on the typed tool was not forwarded")
+	}
+}
@@ -46,5 +46,5 @@ func (t *funcTool[In, Out]) ExecuteRaw(ctx context.Context, args json.RawMessage
 		return ToolResult{Error: "invalid args: " + err.Error()}, nil
 	}
 	out, err := t.fn(ctx, in)
-	return toolResultFromOut(out, err)
+	return toolResultFromOut(out, err, isRetryAttempt(ctx))
 }
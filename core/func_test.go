@@ -200,3 +200,22 @@ func TestFunc_PanicOnUnsupportedType(t *testing.T) {
 
 // Compile-time check: funcTool satisfies AnyTool.
 var _ AnyTool = Func("x", "x", func(context.Context, struct{}) (string, error) { return "", nil })
+
+func TestFunc_RetryableErrorPropagation(t *testing.T) {
+	tool := Func("flaky", "Fails transiently",
+		func(_ context.Context, _ struct{}) (string, error) {
+			return "", RetryableError(errors.New("HTTP 503"))
+		})
+	// Outside a retry policy the error is a business error, as before.
+	res, err := tool.ExecuteRaw(context.Background(), json.RawMessage(`{}`))
+	if err != nil || res.Error != "HTTP 503" {
+		t.Fatalf("without retry: (%q, %v), want the error in ToolResult only", res.Error, err)
+	}
+	res, err = tool.ExecuteRaw(WithRetryAttempt(context.Background()), json.RawMessage(`{}`))
+	if err == nil || !DefaultRetryOn(err) {
+		t.Fatalf("err = %v, want the retryable error propagated", err)
+	}
+	if res.Error != "HTTP 503" {
+		t.Errorf("ToolResult.Error = %q", res.Error)
+	}
+}
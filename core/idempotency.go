@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// idempotencyKeyCtx is the context key for a tool call's idempotency key.
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey returns a child context carrying key. The agent sets it
+// for every tool call; tests and custom dispatchers may set their own.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key of the tool call
+// running on ctx. The agent derives it from the thread (the user when there
+// is no thread) and the tool call ID, so it is the same when the call is retried (WithToolRetry, a tool
+// policy) or re-dispatched after a resume, and differs for a new call the
+// model makes. Pass it to Once to make a side effect happen at most once.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key, ok && key != ""
+}
+
+// ToolCallIdempotencyKey derives the idempotency key the agent sets for tool
+// call callID on threadID (or another scope, such as the user, when the
+// task has no thread).
+func ToolCallIdempotencyKey(threadID, callID string) string {
+	h := sha256.Sum256([]byte(threadID + "\x00" + callID))
+	return hex.EncodeToString(h[:16])
+}
+
+// ConfigStore is the subset of Store that Once records results in. Any
+// Store satisfies it.
+type ConfigStore interface {
+	GetConfig(ctx context.Context, key string) (string, error)
+	SetConfig(ctx context.Context, key, value string) error
+}
+
+// idempotencyKeyPrefix namespaces Once records in the config table.
+const idempotencyKeyPrefix = "idempotency:"
+
+// onceRecord is the stored form of a completed Once call. At is when it was
+// recorded, in Unix seconds; PruneOnce uses it.
+type onceRecord struct {
+	Result string `json:"result"`
+	At     int64  `json:"at,omitempty"`
+}
+
+// onceLocks serializes Once calls per key within the process. An entry lives
+// only while a call holds or waits for it, so the map stays as small as the
+// number of keys in flight.
+var onceLocks = struct {
+	sync.Mutex
+	m map[string]*onceKeyLock
+}{m: make(map[string]*onceKeyLock)}
+
+type onceKeyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockOnce locks key and returns its unlock func.
+func lockOnce(key string) func() {
+	onceLocks.Lock()
+	l := onceLocks.m[key]
+	if l == nil {
+		l = &onceKeyLock{}
+		onceLocks.m[key] = l
+	}
+	l.refs++
+	onceLocks.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		onceLocks.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(onceLocks.m, key)
+		}
+		onceLocks.Unlock()
+	}
+}
+
+// Once runs fn at most once per idempotency key and records its result in
+// store. A later call with the same key returns the recorded result without
+// running fn. Errors are not recorded, so a failed attempt can be retried.
+// An empty key runs fn unguarded. If fn succeeds but the record cannot be
+// saved, Once returns fn's result with the save error.
+//
+//	key, _ := core.IdempotencyKeyFromContext(ctx)
+//	id, err := core.Once(ctx, store, key, func(ctx context.Context) (string, error) {
+//	    return tickets.Create(ctx, in.Title)
+//	})
+//
+// Calls in one process are serialized per key; calls with different keys
+// never wait on each other. Two processes racing on the same first call may
+// both run fn; the store has no atomic claim for it. Records are kept until
+// PruneOnce removes them.
+func Once(ctx context.Context, store ConfigStore, key string, fn func(context.Context) (string, error)) (string, error) {
+	if key == "" {
+		return fn(ctx)
+	}
+	defer lockOnce(key)()
+
+	raw, err := store.GetConfig(ctx, idempotencyKeyPrefix+key)
+	if err != nil {
+		return "", fmt.Errorf("idempotency: load %s: %w", key, err)
+	}
+	if raw != "" {
+		var rec onceRecord
+		if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+			return "", fmt.Errorf("idempotency: decode %s: %w", key, err)
+		}
+		return rec.Result, nil
+	}
+
+	result, err := fn(ctx)
+	if err != nil {
+		return result, err
+	}
+	data, _ := json.Marshal(onceRecord{Result: result, At: time.Now().Unix()})
+	// Why WithoutCancel: the side effect already happened; a cancelled ctx
+	// must not lose the record and let a retry repeat it.
+	if err := store.SetConfig(context.WithoutCancel(ctx), idempotencyKeyPrefix+key, string(data)); err != nil {
+		return result, fmt.Errorf("idempotency: record %s: %w", key, err)
+	}
+	return result, nil
+}
+
+// PruneOnce deletes the Once records in store older than maxAge and returns
+// how many it removed. Run it on a schedule longer than any retry or resume
+// window; a call re-dispatched after its record is gone runs fn again.
+// Records written before they carried a timestamp count as expired. store
+// must implement ConfigLister.
+func PruneOnce(ctx context.Context, store ConfigStore, maxAge time.Duration) (int, error) {
+	lister, ok := store.(ConfigLister)
+	if !ok {
+		return 0, fmt.Errorf("idempotency: prune: store does not implement ConfigLister")
+	}
+	entries, err := lister.ListConfig(ctx, idempotencyKeyPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("idempotency: prune: %w", err)
+	}
+	cutoff := time.Now().Add(-maxAge).Unix()
+	n := 0
+	for key, raw := range entries {
+		if !strings.HasPrefix(key, idempotencyKeyPrefix) {
+			continue
+		}
+		var rec onceRecord
+		if json.Unmarshal([]byte(raw), &rec) == nil && rec.At >= cutoff {
+			continue
+		}
+		if err := lister.DeleteConfig(ctx, key); err != nil {
+			return n, fmt.Errorf("idempotency: prune %s: %w", key, err)
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mapConfigStore map[string]string
+
+func (m mapConfigStore) GetConfig(_ context.Context, key string) (string, error) {
+	return m[key], nil
+}
+
+func (m mapConfigStore) SetConfig(_ context.Context, key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func (m mapConfigStore) ListConfig(_ context.Context, prefix string) (map[string]string, error) {
+	out := make(map[string]string)
+	for k, v := range m {
+		if strings.HasPrefix(k, prefix) {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+func (m mapConfigStore) DeleteConfig(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func TestOnce(t *testing.T) {
+	store := mapConfigStore{}
+	ctx := context.Background()
+	runs := 0
+	send := func(context.Context) (string, error) {
+		runs++
+		return "", nil // an empty result still counts as done
+	}
+
+	for range 2 {
+		if _, err := Once(ctx, store, "k1", send); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("runs = %d, want 1", runs)
+	}
+
+	fail := errors.New("smtp down")
+	calls := 0
+	flaky := func(context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", fail
+		}
+		return "ticket-7", nil
+	}
+	if _, err := Once(ctx, store, "k2", flaky); !errors.Is(err, fail) {
+		t.Fatalf("err = %v, want %v", err, fail)
+	}
+	if got, err := Once(ctx, store, "k2", flaky); err != nil || got != "ticket-7" {
+		t.Fatalf("retry after failure = %q, %v", got, err)
+	}
+	if got, _ := Once(ctx, store, "k2", flaky); got != "ticket-7" || calls != 2 {
+		t.Errorf("duplicate = %q after %d calls, want recorded result after 2", got, calls)
+	}
+}
+
+func TestIdempotencyKeyFromContext(t *testing.T) {
+	if _, ok := IdempotencyKeyFromContext(context.Background()); ok {
+		t.Error("empty context should carry no key")
+	}
+	a := ToolCallIdempotencyKey("thread-1", "call_1")
+	if a == ToolCallIdempotencyKey("thread-2", "call_1") || a != ToolCallIdempotencyKey("thread-1", "call_1") {
+		t.Error("key should be stable per (thread, call) and differ across threads")
+	}
+	if got, ok := IdempotencyKeyFromContext(WithIdempotencyKey(context.Background(), a)); !ok || got != a {
+		t.Errorf("round trip = %q, %v", got, ok)
+	}
+}
+
+// blockingConfigStore is a mapConfigStore safe for concurrent use.
+type blockingConfigStore struct {
+	mu sync.Mutex
+	m  mapConfigStore
+}
+
+func (s *blockingConfigStore) GetConfig(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.GetConfig(ctx, key)
+}
+
+func (s *blockingConfigStore) SetConfig(ctx context.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.SetConfig(ctx, key, value)
+}
+
+func TestOnce_KeysDoNotBlockEachOther(t *testing.T) {
+	store := &blockingConfigStore{m: mapConfigStore{}}
+	ctx := context.Background()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go Once(ctx, store, "slow", func(context.Context) (string, error) {
+		close(started)
+		<-release
+		return "", nil
+	})
+	<-started
+	defer close(release)
+
+	done := make(chan struct{})
+	go func() {
+		Once(ctx, store, "fast", func(context.Context) (string, error) { return "", nil })
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Once on one key waited for a slow call on another")
+	}
+}
+
+func TestPruneOnce(t *testing.T) {
+	ctx := context.Background()
+	store := mapConfigStore{"unrelated": "keep"}
+	if _, err := Once(ctx, store, "fresh", func(context.Context) (string, error) { return "a", nil }); err != nil {
+		t.Fatal(err)
+	}
+	old, _ := json.Marshal(onceRecord{Result: "b", At: time.Now().Add(-48 * time.Hour).Unix()})
+	store[idempotencyKeyPrefix+"old"] = string(old)
+	store[idempotencyKeyPrefix+"legacy"] = `{"result":"c"}`
+
+	n, err := PruneOnce(ctx, store, 24*time.Hour)
+	if err != nil || n != 2 {
+		t.Fatalf("PruneOnce = %d, %v; want 2, nil", n, err)
+	}
+	if _, ok := store[idempotencyKeyPrefix+"fresh"]; !ok {
+		t.Error("fresh record was pruned")
+	}
+	if store["unrelated"] != "keep" {
+		t.Error("non-idempotency config entry was touched")
+	}
+
+	type plain struct{ ConfigStore }
+	if _, err := PruneOnce(ctx, plain{store}, time.Hour); err == nil {
+		t.Error("expected error for a store without ConfigLister")
+	}
+}
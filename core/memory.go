@@ -43,6 +43,15 @@ type MemoryItemStore interface {
 	SearchSemantic(ctx context.Context, embedding []float32, filter MemoryFilter, topK int) ([]ScoredMemoryItem, error)
 }
 
+// MemoryExpirer is an optional MemoryItemStore capability for purging
+// expired items. DeleteExpired removes the items of the given kinds (any kind
+// when none is given) whose ExpiresAt has passed, and returns the count
+// deleted. Expired items are already hidden from List and SearchSemantic;
+// stores without this capability just keep the rows.
+type MemoryExpirer interface {
+	DeleteExpired(ctx context.Context, kinds ...MemoryKind) (int, error)
+}
+
 // MemoryKind discriminates the role of a MemoryItem. The framework defines
 // six canonical kinds; Kind is an open string type so users may define their
 // own.
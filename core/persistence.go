@@ -42,12 +42,19 @@ type ChunkMeta struct {
 	SectionHeading string  `json:"section_heading,omitempty"`
 	SourceURL      string  `json:"source_url,omitempty"`
 	Images         []Image `json:"images,omitempty"`
-	// ContentType discriminates chunk modality: "text" (default/empty) or "image".
+	// ContentType discriminates chunk modality: "text" (default/empty), "image",
+	// or "table" (a Markdown table extracted from a PDF).
 	// Used by filters to scope retrieval to a specific modality.
 	ContentType string `json:"content_type,omitempty"`
 	// BlobRef is an opaque reference to a BlobStore object (e.g. "s3://bucket/key").
 	// Populated when images are stored externally instead of inline in Images.
 	BlobRef string `json:"blob_ref,omitempty"`
+	// OCR reports that the chunk's text was recognized from a scanned page
+	// image, so it may contain recognition errors.
+	OCR bool `json:"ocr,omitempty"`
+	// Language is the ISO 639-1 code of the chunk's language (e.g. "en",
+	// "id"), set when the ingestor runs language detection.
+	Language string `json:"lang,omitempty"`
 }
 
 // Image represents an extracted image from a document.
@@ -160,6 +167,12 @@ func ByMeta(key, value string) ChunkFilter {
 	return ChunkFilter{Field: "meta." + key, Op: OpEq, Value: StringValue(value)}
 }
 
+// ByLanguage returns a filter matching chunks tagged with the given ISO 639-1
+// language code. Untagged chunks never match.
+func ByLanguage(code string) ChunkFilter {
+	return ByMeta("lang", code)
+}
+
 // ByExcludeDocument returns a filter that excludes chunks belonging to the given document.
 func ByExcludeDocument(docID string) ChunkFilter {
 	return ChunkFilter{Field: "document_id", Op: OpNeq, Value: StringValue(docID)}
@@ -213,4 +226,22 @@ type ScheduledAction struct {
 	Enabled         bool   `json:"enabled"`
 	SkillID         string `json:"skill_id,omitempty"`
 	CreatedAt       int64  `json:"created_at"`
+	// MissedPolicy tells the runner what to do when NextRun passed while
+	// no runner was polling, e.g. the process was down.
+	MissedPolicy MissedPolicy `json:"missed_policy,omitempty"`
+	// LastRun is when the action last fired (unix seconds), set by
+	// ScheduledActionClaimer.ClaimScheduledAction. 0 = never.
+	LastRun int64 `json:"last_run,omitempty"`
 }
+
+// MissedPolicy is a ScheduledAction's catch-up rule for fire times missed
+// while offline.
+type MissedPolicy string
+
+const (
+	// MissedFireOnce fires a missed action once, as soon as a runner sees it,
+	// however many fire times were missed. The default.
+	MissedFireOnce MissedPolicy = ""
+	// MissedSkip drops missed fire times: the runner only advances NextRun.
+	MissedSkip MissedPolicy = "skip"
+)
@@ -28,6 +28,30 @@ type ToolPolicy struct {
 	RetryOn func(error) bool
 }
 
+// RetrySafeTool lets a tool opt out of the agent-wide default retries set by
+// agent.WithToolRetry. Tools that do not implement it are retried on
+// retryable errors; return false from RetrySafe for tools whose calls must
+// not repeat, such as sending a message or charging a card. A ToolPolicy
+// registered for the tool by name still applies.
+type RetrySafeTool interface {
+	RetrySafe() bool
+}
+
+type retryAttemptKey struct{}
+
+// WithRetryAttempt marks ctx as a tool call made under a retry policy. Only
+// on such a ctx do typed tools (Func, Erase) return an error marked with
+// RetryableError as the Go error from ExecuteRaw, so the policy can retry
+// it. Everywhere else it stays in ToolResult.Error like any business error.
+func WithRetryAttempt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryAttemptKey{}, true)
+}
+
+func isRetryAttempt(ctx context.Context) bool {
+	v, _ := ctx.Value(retryAttemptKey{}).(bool)
+	return v
+}
+
 // Retryable is the opt-in convention tool authors use to mark an error as
 // retryable. DefaultRetryOn honors this mark via errors.As, and any
 // user-supplied RetryOn predicate may do the same.
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// RunRecord is the business-level record of one agent run: what was asked,
+// which tools and agents ran (Result.Steps), and what it cost
+// (Result.Usage). Unlike Tracer spans it is one flat value per Execute,
+// meant for offline analysis of agent behavior.
+type RunRecord struct {
+	// Agent is the name of the agent or network that ran.
+	Agent  string
+	Task   AgentTask
+	Result AgentResult
+	// Err is the error Execute returned, nil on success. Result still holds
+	// the steps taken before the failure.
+	Err      error
+	Start    time.Time
+	Duration time.Duration
+}
+
+// TraceSink receives a RunRecord after every Execute. It is called
+// synchronously on the Execute goroutine, possibly from many goroutines at
+// once, so implementations must be safe for concurrent use and should hand
+// slow writes off rather than block the caller. Attach with
+// agent.WithTraceSink.
+type TraceSink func(ctx context.Context, rec RunRecord)
@@ -32,10 +32,7 @@ func AddRunUsage(ctx context.Context, model string, u Usage) {
 	}
 	ru.mu.Lock()
 	cur := ru.byMod[model]
-	cur.InputTokens += u.InputTokens
-	cur.OutputTokens += u.OutputTokens
-	cur.CachedTokens += u.CachedTokens
-	cur.CacheCreationTokens += u.CacheCreationTokens
+	cur.Add(u)
 	ru.byMod[model] = cur
 	ru.mu.Unlock()
 }
@@ -0,0 +1,217 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ArgsError reports tool arguments that do not match the tool's Parameters
+// schema. Errors lists one violation per entry, e.g.
+// `$.limit: expected integer, got string`.
+type ArgsError struct {
+	Tool   string
+	Errors []string
+}
+
+func (e *ArgsError) Error() string {
+	msg := "invalid arguments: " + strings.Join(e.Errors, "; ")
+	if e.Tool != "" {
+		msg = "tool " + e.Tool + ": " + msg
+	}
+	return msg
+}
+
+// ValidateArgs checks tool call arguments against a tool's Parameters
+// schema and returns an *ArgsError listing every violation (up to ten), or
+// nil when args conform. Empty or null args are checked as {}. A schema the
+// validator cannot parse accepts everything. It understands the keywords
+// DeriveSchema emits — type, properties, required, items, enum and
+// additionalProperties: false — and ignores the rest.
+func ValidateArgs(schema, args json.RawMessage) error {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage(`{}`)
+	}
+	if errs := SchemaViolations(schema, args); len(errs) > 0 {
+		return &ArgsError{Errors: errs}
+	}
+	return nil
+}
+
+// SchemaViolations checks the JSON document doc against schema and returns
+// the violations found, or nil when doc conforms. A schema the validator
+// cannot parse is treated as accepting everything.
+func SchemaViolations(schema json.RawMessage, doc []byte) []string {
+	var root schemaNode
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil
+	}
+	var v any
+	dec := json.NewDecoder(strings.NewReader(string(doc)))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return []string{"not valid JSON: " + err.Error()}
+	}
+	if dec.More() {
+		return []string{"not valid JSON: unexpected data after top-level value"}
+	}
+	var errs []string
+	validateNode(&root, v, "$", &errs)
+	return errs
+}
+
+// maxSchemaErrors caps the violations reported per document so a wildly
+// wrong answer doesn't turn the model's feedback into a wall of text.
+const maxSchemaErrors = 10
+
+// schemaNode is the subset of JSON Schema the validator understands: the
+// keywords SchemaObject emits plus additionalProperties:false and type
+// arrays. Unknown keywords are ignored, so a richer schema validates at
+// least as leniently as the provider enforces it.
+type schemaNode struct {
+	Type                 json.RawMessage        `json:"type"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	Items                *schemaNode            `json:"items"`
+	Enum                 []json.RawMessage      `json:"enum"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+}
+
+// types returns the allowed type names; empty means any.
+func (n *schemaNode) types() []string {
+	if len(n.Type) == 0 {
+		return nil
+	}
+	var one string
+	if json.Unmarshal(n.Type, &one) == nil {
+		return []string{one}
+	}
+	var many []string
+	_ = json.Unmarshal(n.Type, &many)
+	return many
+}
+
+func validateNode(n *schemaNode, v any, path string, errs *[]string) {
+	if len(*errs) >= maxSchemaErrors {
+		return
+	}
+	if ts := n.types(); len(ts) > 0 && !matchesAnyType(ts, v) {
+		*errs = append(*errs, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(ts, " or "), jsonTypeName(v)))
+		return
+	}
+	if len(n.Enum) > 0 && !inEnum(n.Enum, v) {
+		*errs = append(*errs, fmt.Sprintf("%s: value is not one of the allowed enum values", path))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		for _, name := range n.Required {
+			if _, ok := val[name]; !ok {
+				*errs = append(*errs, fmt.Sprintf("%s.%s: required property missing", path, name))
+			}
+		}
+		closed := string(n.AdditionalProperties) == "false"
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys) // deterministic error order
+		for _, k := range keys {
+			if len(*errs) >= maxSchemaErrors {
+				return
+			}
+			if prop, ok := n.Properties[k]; ok && prop != nil {
+				validateNode(prop, val[k], path+"."+k, errs)
+			} else if closed {
+				*errs = append(*errs, fmt.Sprintf("%s.%s: property not allowed", path, k))
+			}
+		}
+	case []any:
+		if n.Items == nil {
+			return
+		}
+		for i, item := range val {
+			validateNode(n.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+func matchesAnyType(types []string, v any) bool {
+	for _, t := range types {
+		switch t {
+		case "object":
+			if _, ok := v.(map[string]any); ok {
+				return true
+			}
+		case "array":
+			if _, ok := v.([]any); ok {
+				return true
+			}
+		case "string":
+			if _, ok := v.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := v.(json.Number); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := v.(json.Number); ok {
+				if _, err := n.Int64(); err == nil {
+					return true
+				}
+			}
+		case "boolean":
+			if _, ok := v.(bool); ok {
+				return true
+			}
+		case "null":
+			if v == nil {
+				return true
+			}
+		default:
+			// Unknown type keyword: don't fail on what we can't check.
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func inEnum(enum []json.RawMessage, v any) bool {
+	got, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	for _, e := range enum {
+		var ev any
+		dec := json.NewDecoder(strings.NewReader(string(e)))
+		dec.UseNumber()
+		if dec.Decode(&ev) != nil {
+			continue
+		}
+		want, err := json.Marshal(ev)
+		if err == nil && string(want) == string(got) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateArgs(t *testing.T) {
+	type searchArgs struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit,omitempty"`
+	}
+	schema := DeriveSchema[searchArgs]()
+	tests := []struct {
+		name string
+		args string
+		want []string
+	}{
+		{"valid", `{"query":"go","limit":3}`, nil},
+		{"optional omitted", `{"query":"go"}`, nil},
+		{"missing required", `{"limit":3}`, []string{"$.query: required property missing"}},
+		{"wrong type", `{"query":"go","limit":"3"}`, []string{"$.limit: expected integer, got string"}},
+		{"null args", `null`, []string{"$.query: required property missing"}},
+		{"not json", `{"query":`, []string{"not valid JSON"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArgs(schema, json.RawMessage(tt.args))
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateArgs = %v, want nil", err)
+				}
+				return
+			}
+			var ae *ArgsError
+			if !errors.As(err, &ae) {
+				t.Fatalf("ValidateArgs = %v, want *ArgsError", err)
+			}
+			if len(ae.Errors) != len(tt.want) {
+				t.Fatalf("errors = %q, want %q", ae.Errors, tt.want)
+			}
+			for i, w := range tt.want {
+				if !strings.Contains(ae.Errors[i], w) {
+					t.Errorf("errors[%d] = %q, want it to contain %q", i, ae.Errors[i], w)
+				}
+			}
+		})
+	}
+}
@@ -23,7 +23,9 @@ type Source struct {
 // Sourced is the opt-in capability for tools, retrievers, and providers
 // that produce citations. The agent loop checks for this interface on
 // every tool result and every provider response; implementations that
-// don't satisfy it contribute nothing to AgentResult.Sources.
+// don't satisfy it contribute nothing to AgentResult.Sources. A typed
+// tool's Out type may implement it too: its sources land on
+// ToolResult.Sources for that call only.
 type Sourced interface {
 	Sources() []Source
 }
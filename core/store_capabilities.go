@@ -13,6 +13,47 @@ type KeywordSearcher interface {
 	SearchChunksKeyword(ctx context.Context, query string, topK int, filters ...ChunkFilter) ([]ScoredChunk, error)
 }
 
+// MessageTextSearcher is an optional Store capability for full-text search
+// over stored conversation messages. It complements Store.SearchMessages:
+// exact words and phrases that vector search misses. When chatID is
+// non-empty, only messages in that chat's threads are searched. Results are
+// ordered by relevance, best first.
+type MessageTextSearcher interface {
+	SearchMessagesText(ctx context.Context, query string, limit int, chatID string) ([]Message, error)
+}
+
+// StoreMaintainer is an optional Store capability for periodic maintenance.
+// Run Vacuum on a schedule (e.g. nightly) to reclaim space and drop rows
+// orphaned by deletes; use Stats to decide when it is worth running.
+// Implementations must be safe to call while the store serves reads.
+type StoreMaintainer interface {
+	Vacuum(ctx context.Context) (VacuumResult, error)
+	Stats(ctx context.Context) (StoreStats, error)
+}
+
+// VacuumResult reports what a Vacuum removed and the on-disk size before and
+// after it.
+type VacuumResult struct {
+	OrphanChunks int   `json:"orphan_chunks"` // chunks whose document no longer exists
+	OrphanEdges  int   `json:"orphan_edges"`  // chunk edges with a missing endpoint
+	SizeBefore   int64 `json:"size_before"`   // bytes
+	SizeAfter    int64 `json:"size_after"`    // bytes
+}
+
+// StoreStats reports a Store's row counts and on-disk size.
+type StoreStats struct {
+	Threads   int64 `json:"threads"`
+	Messages  int64 `json:"messages"`
+	Documents int64 `json:"documents"`
+	Chunks    int64 `json:"chunks"`
+	Edges     int64 `json:"edges"`
+	// SizeBytes is the database size on disk. FreeBytes is the part of it
+	// held by unused pages that Vacuum would reclaim; 0 when the backend
+	// does not report it.
+	SizeBytes int64 `json:"size_bytes"`
+	FreeBytes int64 `json:"free_bytes"`
+}
+
 // GraphStore is an optional Store capability for chunk relationship graphs.
 // Store implementations that maintain a knowledge graph can implement this
 // interface; callers discover it via type assertion.
@@ -61,6 +102,63 @@ type ScheduledActionStore interface {
 	ListScheduledActionsByDescription(ctx context.Context, pattern string) ([]ScheduledAction, error)
 }
 
+// ScheduledActionClaimer is an optional ScheduledActionStore capability that
+// lets several runners (replicas) poll one store without double-firing.
+// ClaimScheduledAction atomically moves action id from NextRun due to next and
+// sets LastRun to now — only if NextRun still equals due. It reports false
+// when another runner claimed that fire time first; fire the action only on
+// true.
+type ScheduledActionClaimer interface {
+	ClaimScheduledAction(ctx context.Context, id string, due, next, now int64) (bool, error)
+}
+
+// LeaseStore is an optional Store capability for named, expiring leases —
+// the minimum coordination for leader election, e.g. so only one replica
+// runs the scheduler. The holder is the Store value itself: give each
+// replica its own Store.
+//
+// AcquireLease takes name for ttl if it is free or expired, or extends it
+// if this Store already holds it, and reports whether this Store now holds
+// it. RenewLease extends a lease this Store holds; false means the lease
+// expired or was taken and the caller must step down. ReleaseLease frees a
+// lease this Store holds and is a no-op otherwise.
+type LeaseStore interface {
+	AcquireLease(ctx context.Context, name string, ttl time.Duration) (bool, error)
+	RenewLease(ctx context.Context, name string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name string) error
+}
+
+// StreamFrame is one event of a resumable stream as it was sent to the
+// client: the SSE event name and its JSON data. Seq starts at 1 and
+// increases by one per frame.
+type StreamFrame struct {
+	Seq  int64           `json:"seq"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// StreamLogStore is an optional Store capability for persisting the frames
+// of a resumable stream (agent.StreamBuffer), so a client that reconnects
+// can be sent what it missed even after the process that ran the agent has
+// restarted. StreamFrames returns a stream's frames ordered by Seq, or none
+// for an unknown stream. PruneStreamFrames deletes the frames of every stream
+// whose newest frame was appended before before, and returns how many frames
+// it removed.
+type StreamLogStore interface {
+	AppendStreamFrame(ctx context.Context, streamID string, f StreamFrame) error
+	StreamFrames(ctx context.Context, streamID string) ([]StreamFrame, error)
+	DeleteStreamFrames(ctx context.Context, streamID string) error
+	PruneStreamFrames(ctx context.Context, before time.Time) (int, error)
+}
+
+// ConfigLister is an optional Store capability for enumerating and deleting
+// config entries. ListConfig returns every entry whose key starts with
+// prefix. PruneOnce uses it to expire idempotency records.
+type ConfigLister interface {
+	ListConfig(ctx context.Context, prefix string) (map[string]string, error)
+	DeleteConfig(ctx context.Context, key string) error
+}
+
 // ScoreStore is an optional Store capability for persisting scorer results.
 // Store implementations that support it can implement this interface; callers
 // discover it via type assertion. Stores that don't implement it simply skip
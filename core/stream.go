@@ -37,15 +37,19 @@ const (
 	EventStepStart StreamEventType = "step-start"
 	// EventStepFinish signals a workflow step has completed.
 	// Name carries the step name; Content carries the output (success) or
-	// error message (failure); Duration carries the step wall-clock time.
+	// error message (failure, with IsError set); Duration carries the step
+	// wall-clock time.
 	EventStepFinish StreamEventType = "step-finish"
 	// EventStepProgress carries intermediate progress from a ForEach workflow step.
 	// Name carries the step name; Content carries progress JSON
 	// (e.g. {"completed":3,"total":10}).
 	EventStepProgress StreamEventType = "step-progress"
 	// EventRoutingDecision signals the Network router has decided which agents/tools
-	// to invoke. Name carries the network name; Content carries a JSON summary
-	// (e.g. {"agents":["researcher"],"tools":["search"]}).
+	// to invoke. It precedes the agent-start events. Name carries the network
+	// name; Content carries a JSON summary with the task given to each agent
+	// and, when the router wrote any text alongside the calls, its reasoning
+	// (e.g. {"agents":["researcher"],"tools":["search"],
+	// "tasks":["find Q3 revenue"],"reasoning":"Needs fresh data."}).
 	EventRoutingDecision StreamEventType = "routing-decision"
 	// EventFileAttachment signals that a file has been delivered from a sandbox
 	// and is available for download. Content carries JSON metadata:
@@ -58,7 +62,7 @@ const (
 	EventMaxIterReached StreamEventType = "max-iter-reached"
 	// EventReasoningStart marks the beginning of a reasoning block from a
 	// provider that emits reasoning incrementally (Claude extended thinking,
-	// OpenAI o1). No payload.
+	// OpenAI o1, Gemini thought parts). No payload.
 	EventReasoningStart StreamEventType = "reasoning-start"
 	// EventReasoningDelta carries an incremental reasoning text chunk.
 	EventReasoningDelta StreamEventType = "reasoning-delta"
@@ -183,6 +187,9 @@ type FinishReason string
 const (
 	// FinishStop — model produced a natural stop (no further tool calls).
 	FinishStop FinishReason = "stop"
+	// FinishStopSequence — model emitted one of GenerationParams.StopSequences.
+	// Only providers that report the match set it; others report FinishStop.
+	FinishStopSequence FinishReason = "stop-sequence"
 	// FinishToolCalls — model stopped to request tool calls. Intermediate
 	// state on per-iteration finish; not emitted on EventRunFinish.
 	FinishToolCalls FinishReason = "tool-calls"
@@ -225,8 +232,9 @@ type StreamEvent struct {
 	Duration time.Duration `json:"duration,omitempty"`
 	// IsError reports that the step this event describes failed. Set on
 	// agent-finish events when the delegated subagent returned an error
-	// (Content then carries the "error: ..." text the router sees). False on
-	// success and on all other event types.
+	// (Content then carries the "error: ..." text the router sees) and on
+	// step-finish events when a workflow step failed. False on success and
+	// on all other event types.
 	IsError bool `json:"is_error,omitempty"`
 	// Agent is the name of the delegated subagent whose run produced this
 	// event, stamped on every event forwarded from a child into the parent's
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ToolCache stores successful tool results keyed by tool name and arguments,
+// so identical calls within a TTL skip execution. Keys are opaque fixed-length
+// strings (see ToolCacheKey); values are the full ToolResult.
+//
+// Implementations must be safe for concurrent use. NewInMemoryToolCache is a
+// bounded LRU; a Store- or Redis-backed cache can share results across
+// processes by implementing the same two methods.
+type ToolCache interface {
+	// Get returns the cached result for key, or ok=false when absent or
+	// expired.
+	Get(ctx context.Context, key string) (result ToolResult, ok bool)
+	// Set stores result under key for ttl. ttl <= 0 means no expiry.
+	Set(ctx context.Context, key string, result ToolResult, ttl time.Duration)
+}
+
+// CacheableTool lets a tool opt out of result caching. Tools that do not
+// implement it are cached when a ToolCache is configured; return false from
+// Cacheable for tools with side effects or time-sensitive output.
+type CacheableTool interface {
+	Cacheable() bool
+}
+
+// ScopedCacheTool lets a cacheable tool partition its cached results.
+// CacheScope returns whose data the call on ctx reads, such as a tenant or
+// the task's chat ID, and the scope is added to the cache key, so calls in
+// different scopes never share a result. A tool whose output depends on
+// more than its arguments must implement it or opt out with CacheableTool.
+type ScopedCacheTool interface {
+	CacheScope(ctx context.Context) string
+}
+
+// responseCacheabilityKey is the context key for the active turn's
+// *responseCacheability.
+type responseCacheabilityKey struct{}
+
+type responseCacheability struct {
+	uncacheable atomic.Bool
+	parent      *responseCacheability
+}
+
+// MarkResponseUncacheable records that the agent turn running on ctx used
+// nondeterministic or time-sensitive data (a web search, the current time),
+// so a response cache such as agent.WithResponseCache must not store the
+// turn's answer. Call it from a tool's Execute. Tools implementing
+// CacheableTool with Cacheable() false are marked automatically. A no-op
+// when no response cache is tracking ctx.
+func MarkResponseUncacheable(ctx context.Context) {
+	for c, _ := ctx.Value(responseCacheabilityKey{}).(*responseCacheability); c != nil; c = c.parent {
+		c.uncacheable.Store(true)
+	}
+}
+
+// TrackResponseCacheability returns a child of ctx that records
+// MarkResponseUncacheable calls, and a func reporting whether the turn is
+// still cacheable. Marks made under a nested tracker (a sub-agent with its
+// own response cache) also reach the enclosing ones. For response cache
+// implementations.
+func TrackResponseCacheability(ctx context.Context) (context.Context, func() bool) {
+	parent, _ := ctx.Value(responseCacheabilityKey{}).(*responseCacheability)
+	c := &responseCacheability{parent: parent}
+	return context.WithValue(ctx, responseCacheabilityKey{}, c), func() bool { return !c.uncacheable.Load() }
+}
+
+// ToolCacheKey returns the cache key for a call to name with args. Arguments
+// are canonicalized (object keys sorted, whitespace dropped) so semantically
+// identical calls share a key; args that are not valid JSON are hashed as-is.
+func ToolCacheKey(name string, args json.RawMessage) string {
+	return ScopedToolCacheKey(name, "", args)
+}
+
+// ScopedToolCacheKey is ToolCacheKey for a call made in scope (see
+// ScopedCacheTool). An empty scope gives the same key as ToolCacheKey.
+func ScopedToolCacheKey(name, scope string, args json.RawMessage) string {
+	canon := []byte(args)
+	var v any
+	if len(bytes.TrimSpace(args)) > 0 && json.Unmarshal(args, &v) == nil {
+		if b, err := json.Marshal(v); err == nil {
+			canon = b
+		}
+	}
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	if scope != "" {
+		h.Write([]byte(scope))
+		h.Write([]byte{0})
+	}
+	h.Write(canon)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// InMemoryToolCacheOption configures the default in-memory ToolCache.
+type InMemoryToolCacheOption func(*inMemoryToolCache)
+
+// WithToolCacheMaxEntries sets the maximum number of cached results. When
+// exceeded, the least recently used entry is evicted. Default is 1000.
+func WithToolCacheMaxEntries(n int) InMemoryToolCacheOption {
+	return func(c *inMemoryToolCache) { c.maxEntries = n }
+}
+
+// NewInMemoryToolCache returns a bounded in-process LRU ToolCache. Expired
+// entries are dropped lazily on Get and evicted first by recency.
+func NewInMemoryToolCache(opts ...InMemoryToolCacheOption) ToolCache {
+	c := &inMemoryToolCache{
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		maxEntries: 1000,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type toolCacheEntry struct {
+	key       string
+	result    ToolResult
+	expiresAt time.Time // zero = never
+}
+
+type inMemoryToolCache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List // front = most recently used
+	maxEntries int
+}
+
+func (c *inMemoryToolCache) Get(_ context.Context, key string) (ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return ToolResult{}, false
+	}
+	e := el.Value.(*toolCacheEntry)
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return ToolResult{}, false
+	}
+	c.lru.MoveToFront(el)
+	return e.result, true
+}
+
+func (c *inMemoryToolCache) Set(_ context.Context, key string, result ToolResult, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*toolCacheEntry)
+		e.result, e.expiresAt = result, expiresAt
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&toolCacheEntry{key: key, result: result, expiresAt: expiresAt})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*toolCacheEntry).key)
+	}
+}
+
+// Compile-time interface satisfaction check.
+var _ ToolCache = (*inMemoryToolCache)(nil)
@@ -0,0 +1,64 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+func TestToolCacheKeyCanonicalizesArgs(t *testing.T) {
+	a := core.ToolCacheKey("web_search", json.RawMessage(`{"q":"go","n":3}`))
+	b := core.ToolCacheKey("web_search", json.RawMessage(`{ "n": 3, "q": "go" }`))
+	if a != b {
+		t.Error("equivalent args should share a key")
+	}
+	if a == core.ToolCacheKey("http_fetch", json.RawMessage(`{"q":"go","n":3}`)) {
+		t.Error("different tools must not share a key")
+	}
+	if a == core.ToolCacheKey("web_search", json.RawMessage(`{"q":"go","n":4}`)) {
+		t.Error("different args must not share a key")
+	}
+	if a != core.ScopedToolCacheKey("web_search", "", json.RawMessage(`{"q":"go","n":3}`)) {
+		t.Error("an empty scope should match ToolCacheKey")
+	}
+	if a == core.ScopedToolCacheKey("web_search", "tenant-a", json.RawMessage(`{"q":"go","n":3}`)) {
+		t.Error("different scopes must not share a key")
+	}
+}
+
+func TestInMemoryToolCacheLRU(t *testing.T) {
+	ctx := context.Background()
+	c := core.NewInMemoryToolCache(core.WithToolCacheMaxEntries(2))
+	c.Set(ctx, "a", core.ToolResult{Content: "A"}, 0)
+	c.Set(ctx, "b", core.ToolResult{Content: "B"}, 0)
+	if _, ok := c.Get(ctx, "a"); !ok { // a is now most recent
+		t.Fatal("a missing")
+	}
+	c.Set(ctx, "c", core.ToolResult{Content: "C"}, 0)
+
+	if _, ok := c.Get(ctx, "b"); ok {
+		t.Error("b should have been evicted as least recently used")
+	}
+	if r, ok := c.Get(ctx, "a"); !ok || r.Content != "A" {
+		t.Errorf("a = %+v, %v", r, ok)
+	}
+	if r, ok := c.Get(ctx, "c"); !ok || r.Content != "C" {
+		t.Errorf("c = %+v, %v", r, ok)
+	}
+}
+
+func TestInMemoryToolCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	c := core.NewInMemoryToolCache()
+	c.Set(ctx, "k", core.ToolResult{Content: "v"}, 20*time.Millisecond)
+	if _, ok := c.Get(ctx, "k"); !ok {
+		t.Fatal("fresh entry missing")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := c.Get(ctx, "k"); ok {
+		t.Error("expired entry returned")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -69,6 +70,47 @@ type MultimodalEmbeddingProvider interface {
 	EmbedMultimodal(ctx context.Context, inputs []MultimodalInput) ([][]float32, error)
 }
 
+// AttachmentTypesProvider is an optional Provider capability listing the
+// attachment MIME types the backend accepts. Entries are exact types
+// ("application/pdf") or type wildcards ("image/*"). Agents check the task's
+// attachments against it before the first LLM call, so an unsupported file
+// fails with a clear error instead of a provider 400. A nil or empty list
+// means no restriction.
+type AttachmentTypesProvider interface {
+	AttachmentTypes() []string
+}
+
+// ToolSupportProvider is an optional Provider capability reporting whether
+// the backend does native function calling. Providers that do not implement
+// it are assumed to support tools. Agents built with WithToolCallFallback
+// describe tools in the prompt instead when SupportsTools returns false.
+type ToolSupportProvider interface {
+	SupportsTools() bool
+}
+
+// MIMEAllowed reports whether mime matches one of the allowlist entries.
+// Parameters (";charset=utf-8") and case are ignored; "type/*" matches any
+// subtype and "*/*" matches everything.
+func MIMEAllowed(allowed []string, mime string) bool {
+	mime = strings.ToLower(strings.TrimSpace(mime))
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = strings.TrimSpace(mime[:i])
+	}
+	if mime == "" {
+		return false
+	}
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		switch {
+		case a == "*/*" || a == mime:
+			return true
+		case strings.HasSuffix(a, "/*") && strings.HasPrefix(mime, a[:len(a)-1]):
+			return true
+		}
+	}
+	return false
+}
+
 // BlobStore abstracts binary object storage for large assets (images, audio,
 // video) that are too large to store inline in metadata JSON.
 //
@@ -109,6 +151,10 @@ type ToolResult struct {
 	// frontend component instead of (or alongside) Content. Set via UIResult
 	// or by an Out type implementing UIRenderable.
 	UI *UIComponent `json:"ui,omitempty"`
+	// Sources are citations for this result (e.g. the documents a search
+	// returned). The agent loop appends them to AgentResult.Sources. Set
+	// directly or by an Out type implementing Sourced.
+	Sources []Source `json:"sources,omitempty"`
 }
 
 // ToolRegistry holds all registered atomic tools and dispatches execution.
@@ -397,13 +443,20 @@ func NewResponseSchema(name string, s *SchemaObject) *ResponseSchema {
 }
 
 // GenerationParams controls LLM generation behavior.
-// All fields are pointers — nil means "use provider default".
+// Every field is a pointer or slice — nil means "use provider default".
 // A Temperature of 0.0 is a valid setting, so nil (not zero) signals "unset".
 type GenerationParams struct {
 	Temperature *float64 `json:"temperature,omitempty"`
 	TopP        *float64 `json:"top_p,omitempty"`
 	TopK        *int     `json:"top_k,omitempty"`
 	MaxTokens   *int     `json:"max_tokens,omitempty"`
+	// Seed requests deterministic sampling where the provider supports it.
+	// Same seed, same request and same model give best-effort repeatable
+	// output; providers do not guarantee it.
+	Seed *int `json:"seed,omitempty"`
+	// StopSequences ends generation when the model emits any of the
+	// strings; the matched sequence is not included in the output.
+	StopSequences []string `json:"stop_sequences,omitempty"`
 }
 
 // VideoOptions carries Wan-style video-synthesis parameters. Nil pointers /
@@ -469,6 +522,14 @@ type Usage struct {
 	CacheCreationTokens int `json:"cache_creation_tokens,omitempty"` // tokens WRITTEN to cache (cache-warming cost); Anthropic only
 }
 
+// Add adds every counter of o to u.
+func (u *Usage) Add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CachedTokens += o.CachedTokens
+	u.CacheCreationTokens += o.CacheCreationTokens
+}
+
 type ToolDefinition struct {
 	Name        string          `json:"name"`
 	Description string          `json:"description"`
@@ -30,3 +30,23 @@ func TestChatResponseNewFieldsRoundTrip(t *testing.T) {
 		t.Errorf("ProviderMeta lost")
 	}
 }
+
+func TestMIMEAllowed(t *testing.T) {
+	allowed := []string{"image/*", "application/PDF"}
+	for mime, want := range map[string]bool{
+		"image/png":               true,
+		"IMAGE/JPEG":              true,
+		"application/pdf":         true,
+		"application/pdf; name=a": true,
+		"application/zip":         false,
+		"imagex/png":              false,
+		"":                        false,
+	} {
+		if got := MIMEAllowed(allowed, mime); got != want {
+			t.Errorf("MIMEAllowed(%q) = %v, want %v", mime, got, want)
+		}
+	}
+	if !MIMEAllowed([]string{"*/*"}, "video/mp4") {
+		t.Error(`"*/*" should match everything`)
+	}
+}
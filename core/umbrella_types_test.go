@@ -97,6 +97,7 @@ func TestChunkFilterConstructors(t *testing.T) {
 		{"ByDocument multi", ByDocument("doc1", "doc2"), "document_id", OpIn},
 		{"BySource", BySource("/tmp/file.pdf"), "source", OpEq},
 		{"ByMeta", ByMeta("section_heading", "Introduction"), "meta.section_heading", OpEq},
+		{"ByLanguage", ByLanguage("id"), "meta.lang", OpEq},
 		{"CreatedAfter", CreatedAfter(1000), "created_at", OpGt},
 		{"CreatedBefore", CreatedBefore(2000), "created_at", OpLt},
 	}
@@ -11,6 +11,9 @@
 //     custom regex).
 //   - ContentGuard:      input/output length limits (rune count).
 //   - KeywordGuard:      keyword and regex blocklist for user messages.
+//   - ModerationGuard:   pluggable Classifier (moderation API, LLM judge,
+//     or the built-in InjectionClassifier) with a score
+//     threshold.
 //   - MaxToolCallsGuard: silently trims excess tool calls per LLM turn
 //     (graceful degradation, no halt).
 //
@@ -0,0 +1,133 @@
+package guardrail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// --- ModerationGuard ---
+
+// Classification is a Classifier's verdict on one message.
+type Classification struct {
+	// Score is the violation likelihood in [0, 1]. The guard halts when it
+	// reaches the configured threshold.
+	Score float64
+	// Category names what was detected (e.g. "injection", "self_harm").
+	// Logged on a block; empty when nothing was detected.
+	Category string
+}
+
+// Classifier scores a user message for disallowed content. Implementations
+// may call a moderation API or an LLM; they must be safe for concurrent use.
+type Classifier interface {
+	Classify(ctx context.Context, text string) (Classification, error)
+}
+
+// ClassifierFunc adapts a function to the Classifier interface.
+type ClassifierFunc func(ctx context.Context, text string) (Classification, error)
+
+// Classify calls f.
+func (f ClassifierFunc) Classify(ctx context.Context, text string) (Classification, error) {
+	return f(ctx, text)
+}
+
+// ModerationGuard is a PreProcessor that runs the last user message through
+// a pluggable Classifier and halts the run with a safe refusal when the
+// score reaches the threshold. The refusal is returned as *core.ErrHalt, so
+// the caller gets a normal AgentResult rather than an error.
+//
+// Use InjectionClassifier for a built-in, zero-cost heuristic, or supply
+// your own (moderation API, LLM judge). Safe for concurrent use when the
+// classifier is.
+type ModerationGuard struct {
+	classifier Classifier
+	threshold  float64
+	response   string
+	failOpen   bool
+	logger     *slog.Logger
+}
+
+// ModerationOption configures a ModerationGuard.
+type ModerationOption func(*ModerationGuard)
+
+// NewModerationGuard creates a guard backed by c.
+func NewModerationGuard(c Classifier, opts ...ModerationOption) *ModerationGuard {
+	g := &ModerationGuard{
+		classifier: c,
+		threshold:  0.5,
+		response:   "I can't help with that request.",
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.logger == nil {
+		g.logger = nopLogger
+	}
+	return g
+}
+
+// ModerationThreshold sets the score at or above which the guard halts.
+// Default: 0.5.
+func ModerationThreshold(t float64) ModerationOption {
+	return func(g *ModerationGuard) { g.threshold = t }
+}
+
+// ModerationResponse sets the refusal returned when the guard halts.
+// Default: "I can't help with that request."
+func ModerationResponse(msg string) ModerationOption {
+	return func(g *ModerationGuard) { g.response = msg }
+}
+
+// ModerationFailOpen lets messages through when the classifier errors. By
+// default a classifier error fails the run, so an unavailable moderation
+// backend never silently disables the check.
+func ModerationFailOpen() ModerationOption {
+	return func(g *ModerationGuard) { g.failOpen = true }
+}
+
+// ModerationLogger sets the structured logger for the guard. Blocked
+// messages are logged at WARN level with the category and score.
+func ModerationLogger(l *slog.Logger) ModerationOption {
+	return func(g *ModerationGuard) { g.logger = l }
+}
+
+// PreLLM classifies the last user message.
+func (g *ModerationGuard) PreLLM(ctx context.Context, req *core.ChatRequest) error {
+	content := lastUserContent(req.Messages)
+	if content == "" {
+		return nil
+	}
+	c, err := g.classifier.Classify(ctx, content)
+	if err != nil {
+		if g.failOpen {
+			g.logger.Warn("moderation classifier failed, allowing message", "error", err)
+			return nil
+		}
+		return fmt.Errorf("moderation classifier: %w", err)
+	}
+	if c.Score >= g.threshold {
+		g.logger.Warn("moderation blocked message", "category", c.Category, "score", c.Score)
+		return &core.ErrHalt{Response: g.response}
+	}
+	return nil
+}
+
+// InjectionClassifier returns a Classifier backed by InjectionGuard's
+// multi-layer heuristics: score 1 with category "injection" on a match,
+// 0 otherwise. opts tune the layers the same way they tune InjectionGuard
+// (extra patterns, regex, SkipLayers).
+func InjectionClassifier(opts ...InjectionOption) Classifier {
+	ig := NewInjectionGuard(opts...)
+	return ClassifierFunc(func(_ context.Context, text string) (Classification, error) {
+		if _, err := ig.checkContent(text); err != nil {
+			return Classification{Score: 1, Category: "injection"}, nil
+		}
+		return Classification{}, nil
+	})
+}
+
+// compile-time check
+var _ core.PreProcessor = (*ModerationGuard)(nil)
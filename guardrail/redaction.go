@@ -50,8 +50,15 @@ var presetRules = map[string][]redactRule{
 }
 
 // RedactionGuard performs deterministic, zero-cost regex redaction on request
-// and/or response text. It implements core.PreProcessor and core.PostProcessor.
-// Stateless; safe for concurrent use.
+// and/or response text. It implements core.PreProcessor, core.PostProcessor,
+// and core.PostToolProcessor. Stateless; safe for concurrent use.
+//
+// Output-side hooks run before the turn is persisted, so assistant replies
+// and the tool messages the model sees are stored redacted. Persisted step
+// traces record the tool result before PostTool runs; attach
+// TranscriptTransform via agent.ToolConfig.TransformMatchers to redact those
+// too. The task input itself is persisted as given — scrub it before
+// Execute if raw user text must not be stored.
 type RedactionGuard struct {
 	rules       []redactRule
 	strategy    Strategy
@@ -153,6 +160,53 @@ func (g *RedactionGuard) PostLLM(_ context.Context, resp *core.ChatResponse) err
 	return nil
 }
 
+// PostTool applies output-phase redaction to a tool result's content and
+// error before the model sees it.
+func (g *RedactionGuard) PostTool(_ context.Context, call core.ToolCall, result *core.ToolResult) error {
+	if g.phases == PhaseInput {
+		return nil
+	}
+	for _, field := range []*string{&result.Content, &result.Error} {
+		out, matched := g.apply(*field)
+		if matched && g.strategy == StrategyBlock {
+			g.logger.Warn("redaction guard blocked tool result", "tool", call.Name)
+			return &core.ErrHalt{Response: g.response}
+		}
+		*field = out
+	}
+	return nil
+}
+
+// TranscriptTransform returns a tool transform that redacts tool results in
+// the UI stream and the persisted transcript (step traces, result store),
+// using the guard's rules and placeholder. Sinks cannot halt a run, so it
+// redacts regardless of strategy. Attach it to every tool with:
+//
+//	agent.WithToolConfig(agent.ToolConfig{TransformMatchers: []agent.TransformMatcher{
+//	    {Match: func(string) bool { return true }, Transform: guard.TranscriptTransform()},
+//	}})
+func (g *RedactionGuard) TranscriptTransform() core.ToolTransform {
+	sink := &core.SinkTransform{
+		Result: func(_ string, r core.ToolResult) core.ToolResult {
+			r.Content = g.redact(r.Content)
+			r.Error = g.redact(r.Error)
+			return r
+		},
+	}
+	return core.ToolTransform{Display: sink, Transcript: sink}
+}
+
+// redact replaces every rule match in text with the placeholder.
+func (g *RedactionGuard) redact(text string) string {
+	if text == "" {
+		return text
+	}
+	for _, r := range g.rules {
+		text = r.re.ReplaceAllString(text, g.placeholder(r.kind))
+	}
+	return text
+}
+
 // apply runs every rule over text. Returns the (possibly redacted) text and
 // whether any rule matched. For StrategyWarn it logs and returns text
 // unchanged; for StrategyRedact it replaces matches.
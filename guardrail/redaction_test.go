@@ -2,6 +2,7 @@ package guardrail
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -90,3 +91,49 @@ func TestRedactionPostChunkBlockHalts(t *testing.T) {
 		t.Errorf("expected *core.ErrHalt, got %v", err)
 	}
 }
+
+func TestRedactionPostToolRedactsResult(t *testing.T) {
+	g := NewRedactionGuard(RedactPresets("pii"))
+	result := core.ToolResult{
+		Content: "customer: jane.doe@example.com",
+		Error:   "card 4111 1111 1111 1111 declined",
+	}
+	if err := g.PostTool(context.Background(), core.ToolCall{Name: "lookup"}, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content != "customer: [REDACTED:email]" {
+		t.Errorf("content = %q", result.Content)
+	}
+	if strings.Contains(result.Error, "4111") {
+		t.Errorf("card not redacted in error: %q", result.Error)
+	}
+
+	block := NewRedactionGuard(RedactPresets("pii"), RedactStrategy(StrategyBlock))
+	result = core.ToolResult{Content: "jane.doe@example.com"}
+	var halt *core.ErrHalt
+	if err := block.PostTool(context.Background(), core.ToolCall{Name: "lookup"}, &result); !errors.As(err, &halt) {
+		t.Errorf("expected *core.ErrHalt, got %v", err)
+	}
+
+	inputOnly := NewRedactionGuard(RedactPresets("pii"), RedactPhases(PhaseInput))
+	result = core.ToolResult{Content: "jane.doe@example.com"}
+	_ = inputOnly.PostTool(context.Background(), core.ToolCall{Name: "lookup"}, &result)
+	if result.Content != "jane.doe@example.com" {
+		t.Errorf("input-only guard touched tool result: %q", result.Content)
+	}
+}
+
+func TestRedactionTranscriptTransform(t *testing.T) {
+	g := NewRedactionGuard(RedactPresets("pii"), RedactStrategy(StrategyBlock))
+	tt := g.TranscriptTransform()
+	if tt.Model != nil {
+		t.Error("transform must not touch the model sink")
+	}
+	got := tt.Transcript.Result("lookup", core.ToolResult{Content: "mail jane.doe@example.com"})
+	if got.Content != "mail [REDACTED:email]" {
+		t.Errorf("transcript content = %q", got.Content)
+	}
+	if tt.Display.Result == nil {
+		t.Error("display sink not set")
+	}
+}
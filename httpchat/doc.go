@@ -0,0 +1,32 @@
+// Package httpchat exposes any core.Agent as a plain JSON + SSE chat
+// endpoint, for applications that embed an agent behind their own web UI
+// instead of a chat platform.
+//
+// NewHandler returns an http.Handler serving three routes:
+//
+//	POST /chat          JSON request → JSON AgentResult (200)
+//	POST /chat/stream   JSON request → Server-Sent Events via agent.ServeSSE
+//	POST /chat/resume   resume token + answer → same responses as /chat
+//
+// WithStreamResume adds GET /chat/stream/{id}, which resumes a stream after
+// the client's Last-Event-ID; see agent.StreamBuffer.
+//
+// The request body maps onto AgentTask: input, thread_id, user_id, chat_id,
+// and base64 attachments. The IDs are client-controlled; set them from your
+// authentication with WithTaskIdentity.
+//
+// Human input. On /chat the run gets a per-call InputHandler, so the LLM's
+// ask_user tool works without further wiring. When the agent asks a
+// question, /chat answers 202 with a resume token, the question, and its
+// options; the run stays parked until the client POSTs the answer to
+// /chat/resume (or the token expires). A run that suspends through the
+// processor/workflow machinery (agent.ErrSuspended) also answers 202 with a
+// token; /chat/resume then calls ErrSuspended.Resume with the posted data.
+//
+// /chat/stream runs without the ask_user handler: a question cannot be
+// answered mid-stream over a one-way SSE response.
+//
+// Authentication, CORS, and the listener belong to the application — wrap
+// the handler with your own middleware. Call Close on shutdown to cancel
+// parked runs and open streams and release suspended snapshots.
+package httpchat
@@ -0,0 +1,564 @@
+package httpchat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nevindra/oasis/agent"
+	"github.com/nevindra/oasis/core"
+)
+
+const (
+	defaultPendingTTL      = 30 * time.Minute
+	defaultMaxPending      = 1024
+	defaultMaxRequestBytes = 32 << 20 // 32 MB — room for base64 attachments
+)
+
+// Status values reported in InputRequired.Status.
+const (
+	// StatusInputRequired means the agent called ask_user and its run is
+	// parked until the answer is POSTed to /chat/resume.
+	StatusInputRequired = "input_required"
+	// StatusSuspended means the run returned agent.ErrSuspended; the posted
+	// resume data is passed to ErrSuspended.Resume.
+	StatusSuspended = "suspended"
+)
+
+// ChatRequest is the JSON body of POST /chat and POST /chat/stream.
+type ChatRequest struct {
+	Input       string       `json:"input"`
+	ThreadID    string       `json:"thread_id,omitempty"`
+	UserID      string       `json:"user_id,omitempty"`
+	ChatID      string       `json:"chat_id,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a base64-encoded file sent with a ChatRequest.
+type Attachment struct {
+	MimeType string `json:"mime_type"`
+	Data     string `json:"data"`
+}
+
+// ResumeRequest is the JSON body of POST /chat/resume. Value/Values answer
+// an ask_user question (Values for multi-select). For a StatusSuspended
+// token, Data is passed to ErrSuspended.Resume verbatim; when Data is empty,
+// Value is sent as a JSON string instead.
+type ResumeRequest struct {
+	ResumeToken string          `json:"resume_token"`
+	Value       string          `json:"value,omitempty"`
+	Values      []string        `json:"values,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// InputRequired is the 202 response body returned when a run needs human
+// input before it can finish.
+type InputRequired struct {
+	Status      string          `json:"status"`
+	ResumeToken string          `json:"resume_token"`
+	Question    string          `json:"question,omitempty"`
+	Options     []string        `json:"options,omitempty"`
+	MultiSelect bool            `json:"multi_select,omitempty"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+}
+
+type handlerOptions struct {
+	ttl             time.Duration
+	maxPending      int
+	maxRequestBytes int64
+	streamResume    bool
+	streamStore     core.StreamLogStore
+	streamGrace     time.Duration
+	identity        func(*http.Request, *agent.AgentTask) error
+}
+
+// HandlerOption configures NewHandler.
+type HandlerOption func(*handlerOptions)
+
+// WithPendingTTL sets how long a resume token stays valid (default 30m).
+// When it expires, the parked run is cancelled or the suspended snapshot
+// released, and /chat/resume answers 404.
+func WithPendingTTL(d time.Duration) HandlerOption {
+	return func(o *handlerOptions) { o.ttl = d }
+}
+
+// WithMaxPending bounds the number of runs waiting on human input (default
+// 1024). Beyond it, a run that needs input is cancelled and the request
+// answers 503.
+func WithMaxPending(n int) HandlerOption {
+	return func(o *handlerOptions) { o.maxPending = n }
+}
+
+// WithMaxRequestBytes bounds request bodies (default 32 MB).
+func WithMaxRequestBytes(n int64) HandlerOption {
+	return func(o *handlerOptions) { o.maxRequestBytes = n }
+}
+
+// WithStreamResume makes /chat/stream resumable: the run survives a client
+// disconnect for grace (<= 0 uses two minutes), the response carries an
+// X-Stream-ID header, and GET /chat/stream/{id} replays the events after the
+// Last-Event-ID header and follows the run to its end. store persists the
+// events (nil keeps them in memory). See agent.StreamBuffer.
+func WithStreamResume(store core.StreamLogStore, grace time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.streamResume = true
+		o.streamStore = store
+		o.streamGrace = grace
+	}
+}
+
+// WithTaskIdentity sets the task's identity from the application's own
+// authentication. fn runs on every /chat and /chat/stream request after the
+// body is decoded and should overwrite UserID (and ChatID or ThreadID where
+// they are tied to the user) with values taken from r, such as a verified
+// session. A non-nil error rejects the request with 403 and its message.
+//
+// Without it, user_id, chat_id and thread_id come from the request body and
+// are untrusted: any client can name another user's ID and read or write
+// that user's memory.
+func WithTaskIdentity(fn func(r *http.Request, task *agent.AgentTask) error) HandlerOption {
+	return func(o *handlerOptions) { o.identity = fn }
+}
+
+// Handler serves an agent over JSON + SSE. It implements http.Handler.
+// Create with NewHandler; call Close on shutdown.
+type Handler struct {
+	agent   core.Agent
+	mux     *http.ServeMux
+	opts    handlerOptions
+	baseCtx context.Context
+	stop    context.CancelFunc
+	runs    sync.WaitGroup
+	streams *agent.StreamBuffer // nil unless WithStreamResume
+
+	mu      sync.Mutex
+	closed  bool
+	pending map[string]*pendingEntry
+}
+
+// NewHandler wraps a as a chat endpoint. See the package documentation for
+// the routes and wire format.
+func NewHandler(a core.Agent, opts ...HandlerOption) *Handler {
+	o := handlerOptions{
+		ttl:             defaultPendingTTL,
+		maxPending:      defaultMaxPending,
+		maxRequestBytes: defaultMaxRequestBytes,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// Why a handler-owned base context instead of r.Context(): a run parked
+	// on ask_user outlives the request that started it. Close cancels it.
+	baseCtx, stop := context.WithCancel(context.Background())
+	h := &Handler{
+		agent:   a,
+		mux:     http.NewServeMux(),
+		opts:    o,
+		baseCtx: baseCtx,
+		stop:    stop,
+		pending: make(map[string]*pendingEntry),
+	}
+	h.mux.HandleFunc("POST /chat", h.serveChat)
+	h.mux.HandleFunc("POST /chat/stream", h.serveStream)
+	h.mux.HandleFunc("POST /chat/resume", h.serveResume)
+	if o.streamResume {
+		h.streams = agent.NewStreamBuffer(o.streamStore, o.streamGrace)
+		h.mux.HandleFunc("GET /chat/stream/{id}", h.serveStreamResume)
+	}
+	return h
+}
+
+// ServeHTTP routes /chat, /chat/stream, /chat/resume and, with
+// WithStreamResume, GET /chat/stream/{id}. Mount under a
+// prefix with http.StripPrefix.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Close cancels every parked run, releases suspended snapshots, and waits
+// for in-flight runs to return. Requests arriving afterwards get 503.
+// Safe to call more than once.
+func (h *Handler) Close() error {
+	h.mu.Lock()
+	h.closed = true
+	pending := h.pending
+	h.pending = make(map[string]*pendingEntry)
+	h.mu.Unlock()
+
+	h.stop()
+	for _, e := range pending {
+		e.timer.Stop()
+		e.discard()
+	}
+	if h.streams != nil {
+		h.streams.Close()
+	}
+	h.runs.Wait()
+	return nil
+}
+
+// --- routes ---
+
+func (h *Handler) serveChat(w http.ResponseWriter, r *http.Request) {
+	task, ok := h.decodeTask(w, r)
+	if !ok {
+		return
+	}
+	run := newParkedRun()
+	if !h.start(run, func(ctx context.Context) (agent.AgentResult, error) {
+		return h.agent.Execute(ctx, task, agent.WithOverrides(&agent.RunOptions{InputHandler: run}))
+	}) {
+		writeError(w, http.StatusServiceUnavailable, "handler closed")
+		return
+	}
+	h.await(w, r, run)
+}
+
+func (h *Handler) serveStream(w http.ResponseWriter, r *http.Request) {
+	task, ok := h.decodeTask(w, r)
+	if !ok {
+		return
+	}
+	if !h.track() {
+		writeError(w, http.StatusServiceUnavailable, "handler closed")
+		return
+	}
+	defer h.runs.Done()
+	if h.streams == nil {
+		// The run ends with the request or on Close, whichever comes first.
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		defer context.AfterFunc(h.baseCtx, cancel)()
+		// ServeSSE reports agent errors in-band as an "error" event.
+		_, _ = agent.ServeSSE(ctx, w, h.agent, task)
+		return
+	}
+	id, err := newToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "generate stream id: "+err.Error())
+		return
+	}
+	w.Header().Set("X-Stream-ID", id)
+	_, err = h.streams.ServeSSE(r.Context(), w, h.agent, task, id)
+	if errors.Is(err, agent.ErrStreamBufferClosed) {
+		// Close ran between track and ServeSSE; nothing was written yet.
+		writeError(w, http.StatusServiceUnavailable, "handler closed")
+	}
+}
+
+func (h *Handler) serveStreamResume(w http.ResponseWriter, r *http.Request) {
+	// A missing or malformed Last-Event-ID replays from the start.
+	after, _ := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+	err := h.streams.ResumeStream(r.Context(), w, r.PathValue("id"), after)
+	if errors.Is(err, agent.ErrStreamNotFound) {
+		writeError(w, http.StatusNotFound, "unknown or expired stream")
+	}
+}
+
+func (h *Handler) serveResume(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.opts.maxRequestBytes)
+	var req ResumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.ResumeToken == "" {
+		writeError(w, http.StatusBadRequest, "resume_token is required")
+		return
+	}
+	e := h.take(req.ResumeToken)
+	if e == nil {
+		writeError(w, http.StatusNotFound, "unknown or expired resume token")
+		return
+	}
+
+	if e.suspended == nil {
+		// The run goroutine is blocked in RequestInput; answers is
+		// buffered(1) and each token is taken once, so this never blocks.
+		e.run.answers <- agent.InputResponse{Value: req.Value, Values: req.Values}
+		h.await(w, r, e.run)
+		return
+	}
+
+	data := req.Data
+	if len(data) == 0 {
+		data, _ = json.Marshal(req.Value)
+	}
+	susp := e.suspended
+	if !h.start(e.run, func(ctx context.Context) (agent.AgentResult, error) {
+		return susp.Resume(ctx, data)
+	}) {
+		susp.Release()
+		writeError(w, http.StatusServiceUnavailable, "handler closed")
+		return
+	}
+	h.await(w, r, e.run)
+}
+
+// --- run lifecycle ---
+
+// parkedRun is the link between one agent run and the requests that drive
+// it. It is also the run's InputHandler: a question is reported on events
+// and the run blocks until an answer arrives on answers.
+type parkedRun struct {
+	cancel  context.CancelFunc
+	answers chan agent.InputResponse
+	// events carries questions and the final outcome to the waiting request.
+	// Why cap 2: at most one unread question plus the final outcome can be
+	// queued when the request that should read them went away.
+	events chan runEvent
+}
+
+type runEvent struct {
+	question *agent.InputRequest
+	result   agent.AgentResult
+	err      error
+}
+
+func newParkedRun() *parkedRun {
+	return &parkedRun{
+		cancel:  func() {},
+		answers: make(chan agent.InputResponse, 1),
+		events:  make(chan runEvent, 2),
+	}
+}
+
+// RequestInput implements agent.InputHandler.
+func (p *parkedRun) RequestInput(ctx context.Context, req agent.InputRequest) (agent.InputResponse, error) {
+	select {
+	case p.events <- runEvent{question: &req}:
+	case <-ctx.Done():
+		return agent.InputResponse{}, ctx.Err()
+	}
+	select {
+	case resp := <-p.answers:
+		return resp, nil
+	case <-ctx.Done():
+		return agent.InputResponse{}, ctx.Err()
+	}
+}
+
+// track registers a run served within its request, so Close waits for it.
+// Returns false once Close has begun; otherwise the caller must call
+// h.runs.Done when the run returns.
+func (h *Handler) track() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	// Why Add under mu: see start.
+	h.runs.Add(1)
+	return true
+}
+
+// start runs fn in a goroutine bound to the handler's base context and
+// reports its outcome on run.events. Returns false once Close has begun.
+func (h *Handler) start(run *parkedRun, fn func(ctx context.Context) (agent.AgentResult, error)) bool {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return false
+	}
+	// Why Add under mu: Close sets closed under the same lock before Wait,
+	// so no Add can race with Wait.
+	h.runs.Add(1)
+	h.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(h.baseCtx)
+	run.cancel = cancel
+	go func() {
+		defer h.runs.Done()
+		defer cancel()
+		res, err := fn(ctx)
+		run.events <- runEvent{result: res, err: err}
+	}()
+	return true
+}
+
+// await writes the run's next outcome: a 202 when it needs input, otherwise
+// the final result. A client that disconnects first cancels the run.
+func (h *Handler) await(w http.ResponseWriter, r *http.Request, run *parkedRun) {
+	var ev runEvent
+	select {
+	case ev = <-run.events:
+	case <-r.Context().Done():
+		run.cancel()
+		return
+	}
+
+	if ev.question != nil {
+		token, err := h.park(&pendingEntry{run: run})
+		if err != nil {
+			run.cancel()
+			writeParkError(w, err)
+			return
+		}
+		writeJSONStatus(w, http.StatusAccepted, InputRequired{
+			Status:      StatusInputRequired,
+			ResumeToken: token,
+			Question:    ev.question.Question,
+			Options:     ev.question.Options,
+			MultiSelect: ev.question.MultiSelect,
+		})
+		return
+	}
+
+	var susp *agent.ErrSuspended
+	if errors.As(ev.err, &susp) {
+		// Align the snapshot's own expiry with the token's.
+		susp.WithSuspendTTL(h.opts.ttl)
+		token, err := h.park(&pendingEntry{run: run, suspended: susp})
+		if err != nil {
+			susp.Release()
+			writeParkError(w, err)
+			return
+		}
+		writeJSONStatus(w, http.StatusAccepted, InputRequired{
+			Status:      StatusSuspended,
+			ResumeToken: token,
+			Payload:     susp.Payload,
+		})
+		return
+	}
+	if ev.err != nil {
+		writeError(w, http.StatusInternalServerError, ev.err.Error())
+		return
+	}
+	writeJSONStatus(w, http.StatusOK, ev.result)
+}
+
+// --- pending tokens ---
+
+// pendingEntry is a run waiting on /chat/resume. suspended is set when the
+// run ended with ErrSuspended; otherwise the run is blocked in RequestInput.
+type pendingEntry struct {
+	run       *parkedRun
+	suspended *agent.ErrSuspended
+	timer     *time.Timer
+}
+
+// discard abandons the entry: cancels a parked run, releases a snapshot.
+func (e *pendingEntry) discard() {
+	if e.suspended != nil {
+		e.suspended.Release()
+	}
+	e.run.cancel()
+}
+
+// Reasons park refuses an entry; both are answered with 503.
+var (
+	errHandlerClosed = errors.New("handler closed")
+	errTooManyParked = errors.New("too many conversations awaiting input")
+)
+
+// park registers e under a fresh resume token that expires after the TTL.
+func (h *Handler) park(e *pendingEntry) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return "", errHandlerClosed
+	}
+	if len(h.pending) >= h.opts.maxPending {
+		return "", errTooManyParked
+	}
+	h.pending[token] = e
+	e.timer = time.AfterFunc(h.opts.ttl, func() {
+		if expired := h.take(token); expired != nil {
+			expired.discard()
+		}
+	})
+	return token, nil
+}
+
+// writeParkError answers a failed park: 503 when the handler is closed or
+// full, 500 when no token could be generated.
+func writeParkError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, errHandlerClosed) || errors.Is(err, errTooManyParked) {
+		status = http.StatusServiceUnavailable
+	}
+	writeError(w, status, err.Error())
+}
+
+// take removes and returns the entry for token, or nil. Exactly one caller
+// (resume, expiry, or Close) gets each entry.
+func (h *Handler) take(token string) *pendingEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e, ok := h.pending[token]
+	if !ok {
+		return nil
+	}
+	delete(h.pending, token)
+	e.timer.Stop()
+	return e
+}
+
+// newToken returns an unguessable resume token. Why not core.NewID: UUIDv7
+// is time-ordered and partly predictable, and the token is a bearer
+// credential for someone else's conversation.
+func newToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate resume token: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// --- request / response helpers ---
+
+func (h *Handler) decodeTask(w http.ResponseWriter, r *http.Request) (agent.AgentTask, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.opts.maxRequestBytes)
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return agent.AgentTask{}, false
+	}
+	if req.Input == "" && len(req.Attachments) == 0 {
+		writeError(w, http.StatusBadRequest, "input or attachments required")
+		return agent.AgentTask{}, false
+	}
+	task := agent.AgentTask{
+		Input:    req.Input,
+		ThreadID: req.ThreadID,
+		UserID:   req.UserID,
+		ChatID:   req.ChatID,
+	}
+	for i, a := range req.Attachments {
+		att, err := core.NewAttachmentFromBase64(a.MimeType, a.Data)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("attachments[%d]: %v", i, err))
+			return agent.AgentTask{}, false
+		}
+		task.Attachments = append(task.Attachments, att)
+	}
+	if h.opts.identity != nil {
+		if err := h.opts.identity(r, &task); err != nil {
+			writeError(w, http.StatusForbidden, err.Error())
+			return agent.AgentTask{}, false
+		}
+	}
+	return task, true
+}
+
+func writeJSONStatus(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSONStatus(w, status, map[string]string{"error": msg})
+}
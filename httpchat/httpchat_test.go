@@ -0,0 +1,385 @@
+package httpchat
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/agent"
+	"github.com/nevindra/oasis/core"
+)
+
+// scriptedProvider returns responses in order and records each request.
+type scriptedProvider struct {
+	mu        sync.Mutex
+	responses []core.ChatResponse
+	reqs      []core.ChatRequest
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+func (p *scriptedProvider) ChatStream(_ context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+	if ch != nil {
+		defer close(ch)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reqs = append(p.reqs, req)
+	if len(p.responses) == 0 {
+		return core.ChatResponse{Content: "exhausted"}, nil
+	}
+	resp := p.responses[0]
+	p.responses = p.responses[1:]
+	return resp, nil
+}
+
+// lastToolResult returns the content of the last tool message the provider saw.
+func (p *scriptedProvider) lastToolResult() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	msgs := p.reqs[len(p.reqs)-1].Messages
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == "tool" {
+			return msgs[i].Content
+		}
+	}
+	return ""
+}
+
+// taskRecorder is a core.Agent that records the task it was given.
+type taskRecorder struct {
+	mu   sync.Mutex
+	task agent.AgentTask
+}
+
+func (a *taskRecorder) Name() string        { return "recorder" }
+func (a *taskRecorder) Description() string { return "records the task" }
+func (a *taskRecorder) Execute(_ context.Context, task agent.AgentTask, opts ...core.RunOption) (agent.AgentResult, error) {
+	a.mu.Lock()
+	a.task = task
+	a.mu.Unlock()
+	if ch := core.ApplyRunOptions(opts...).Stream; ch != nil {
+		ch <- core.StreamEvent{Type: core.EventTextDelta, Content: "hi"}
+		close(ch)
+	}
+	return agent.AgentResult{Output: "echo: " + task.Input}, nil
+}
+
+func post(t *testing.T, h http.Handler, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data)))
+	return rec
+}
+
+func TestChatMapsRequestOntoTask(t *testing.T) {
+	rec := &taskRecorder{}
+	h := NewHandler(rec)
+	defer h.Close()
+
+	resp := post(t, h, "/chat", ChatRequest{
+		Input:       "hello",
+		ThreadID:    "t1",
+		UserID:      "u1",
+		ChatID:      "c1",
+		Attachments: []Attachment{{MimeType: "text/plain", Data: base64.StdEncoding.EncodeToString([]byte("file"))}},
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body)
+	}
+	var result agent.AgentResult
+	if err := json.Unmarshal(resp.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "echo: hello" {
+		t.Errorf("Output = %q", result.Output)
+	}
+	task := rec.task
+	if task.ThreadID != "t1" || task.UserID != "u1" || task.ChatID != "c1" {
+		t.Errorf("task ids = %q/%q/%q", task.ThreadID, task.UserID, task.ChatID)
+	}
+	if len(task.Attachments) != 1 || string(task.Attachments[0].Data) != "file" {
+		t.Errorf("attachments = %+v", task.Attachments)
+	}
+}
+
+func TestChatTaskIdentity(t *testing.T) {
+	rec := &taskRecorder{}
+	h := NewHandler(rec, WithTaskIdentity(func(r *http.Request, task *agent.AgentTask) error {
+		user := r.Header.Get("X-User")
+		if user == "" {
+			return errors.New("not signed in")
+		}
+		task.UserID = user
+		return nil
+	}))
+	defer h.Close()
+
+	body, _ := json.Marshal(ChatRequest{Input: "hi", UserID: "victim"})
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewReader(body))
+	req.Header.Set("X-User", "u1")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body)
+	}
+	if rec.task.UserID != "u1" {
+		t.Errorf("UserID = %q, want the authenticated u1 over the body's victim", rec.task.UserID)
+	}
+
+	if resp := post(t, h, "/chat", ChatRequest{Input: "hi", UserID: "victim"}); resp.Code != http.StatusForbidden {
+		t.Errorf("unauthenticated status = %d, want 403", resp.Code)
+	}
+}
+
+func TestChatRejectsBadRequests(t *testing.T) {
+	h := NewHandler(&taskRecorder{})
+	defer h.Close()
+
+	if resp := post(t, h, "/chat", ChatRequest{}); resp.Code != http.StatusBadRequest {
+		t.Errorf("empty input status = %d, want 400", resp.Code)
+	}
+	bad := ChatRequest{Input: "x", Attachments: []Attachment{{MimeType: "image/png", Data: "%%%"}}}
+	if resp := post(t, h, "/chat", bad); resp.Code != http.StatusBadRequest {
+		t.Errorf("bad base64 status = %d, want 400", resp.Code)
+	}
+	if resp := post(t, h, "/chat/resume", ResumeRequest{ResumeToken: "nope"}); resp.Code != http.StatusNotFound {
+		t.Errorf("unknown token status = %d, want 404", resp.Code)
+	}
+}
+
+func askUserProvider() *scriptedProvider {
+	return &scriptedProvider{responses: []core.ChatResponse{
+		{ToolCalls: []core.ToolCall{{ID: "1", Name: core.ToolAskUser, Args: json.RawMessage(`{"question":"Which plan?","options":["basic","pro"]}`)}}},
+		{Content: "done"},
+	}}
+}
+
+func TestChatAskUserRoundTrip(t *testing.T) {
+	p := askUserProvider()
+	h := NewHandler(agent.New("asker", "asks", p))
+	defer h.Close()
+
+	resp := post(t, h, "/chat", ChatRequest{Input: "sign me up"})
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body)
+	}
+	var ir InputRequired
+	if err := json.Unmarshal(resp.Body.Bytes(), &ir); err != nil {
+		t.Fatal(err)
+	}
+	if ir.Status != StatusInputRequired || ir.ResumeToken == "" || ir.Question != "Which plan?" || len(ir.Options) != 2 {
+		t.Fatalf("input required = %+v", ir)
+	}
+
+	resp = post(t, h, "/chat/resume", ResumeRequest{ResumeToken: ir.ResumeToken, Value: "pro"})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("resume status = %d, body = %s", resp.Code, resp.Body)
+	}
+	if got := p.lastToolResult(); !strings.Contains(got, "pro") {
+		t.Errorf("ask_user result seen by LLM = %q, want the posted answer", got)
+	}
+
+	// Tokens are single-use.
+	if resp := post(t, h, "/chat/resume", ResumeRequest{ResumeToken: ir.ResumeToken, Value: "pro"}); resp.Code != http.StatusNotFound {
+		t.Errorf("reused token status = %d, want 404", resp.Code)
+	}
+}
+
+func TestChatPendingTokenExpires(t *testing.T) {
+	h := NewHandler(agent.New("asker", "asks", askUserProvider()), WithPendingTTL(20*time.Millisecond))
+	defer h.Close()
+
+	resp := post(t, h, "/chat", ChatRequest{Input: "sign me up"})
+	var ir InputRequired
+	_ = json.Unmarshal(resp.Body.Bytes(), &ir)
+
+	time.Sleep(60 * time.Millisecond)
+	if resp := post(t, h, "/chat/resume", ResumeRequest{ResumeToken: ir.ResumeToken, Value: "pro"}); resp.Code != http.StatusNotFound {
+		t.Errorf("expired token status = %d, want 404", resp.Code)
+	}
+}
+
+func TestChatMaxPending(t *testing.T) {
+	h := NewHandler(agent.New("asker", "asks", askUserProvider()), WithMaxPending(0))
+	defer h.Close()
+
+	if resp := post(t, h, "/chat", ChatRequest{Input: "x"}); resp.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", resp.Code)
+	}
+}
+
+// approval is a typed protocol used by approveOnce to suspend the run.
+var approval = agent.NewSuspendProtocol[string, string]("httpchat_test.approve")
+
+// approveOnce suspends the first LLM call and lets later ones through.
+type approveOnce struct {
+	mu   sync.Mutex
+	done bool
+}
+
+func (p *approveOnce) PreLLM(_ context.Context, _ *core.ChatRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return nil
+	}
+	p.done = true
+	return approval.Suspend("approve the run?")
+}
+
+func TestChatSuspendedResume(t *testing.T) {
+	p := &scriptedProvider{responses: []core.ChatResponse{{Content: "approved and done"}}}
+	a := agent.New("gated", "gated", p, agent.WithProcessors(agent.Processors{Pre: []core.PreProcessor{&approveOnce{}}}))
+	h := NewHandler(a)
+	defer h.Close()
+
+	resp := post(t, h, "/chat", ChatRequest{Input: "go"})
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body)
+	}
+	var ir InputRequired
+	if err := json.Unmarshal(resp.Body.Bytes(), &ir); err != nil {
+		t.Fatal(err)
+	}
+	if ir.Status != StatusSuspended || string(ir.Payload) != `"approve the run?"` {
+		t.Fatalf("input required = %+v", ir)
+	}
+
+	resp = post(t, h, "/chat/resume", ResumeRequest{ResumeToken: ir.ResumeToken, Value: "yes"})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("resume status = %d, body = %s", resp.Code, resp.Body)
+	}
+	var result agent.AgentResult
+	if err := json.Unmarshal(resp.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "approved and done" {
+		t.Errorf("Output = %q", result.Output)
+	}
+}
+
+func TestChatStream(t *testing.T) {
+	h := NewHandler(&taskRecorder{})
+	defer h.Close()
+
+	resp := post(t, h, "/chat/stream", ChatRequest{Input: "hello"})
+	if ct := resp.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := resp.Body.String()
+	if !strings.Contains(body, "event: text-delta") || !strings.Contains(body, "event: done") {
+		t.Errorf("stream body:\n%s", body)
+	}
+}
+
+// blockingAgent streams until its context is cancelled.
+type blockingAgent struct{ started chan struct{} }
+
+func (a *blockingAgent) Name() string        { return "blocker" }
+func (a *blockingAgent) Description() string { return "blocks" }
+func (a *blockingAgent) Execute(ctx context.Context, _ agent.AgentTask, opts ...core.RunOption) (agent.AgentResult, error) {
+	if ch := core.ApplyRunOptions(opts...).Stream; ch != nil {
+		defer close(ch)
+	}
+	close(a.started)
+	<-ctx.Done()
+	return agent.AgentResult{}, ctx.Err()
+}
+
+func TestChatStreamClose(t *testing.T) {
+	a := &blockingAgent{started: make(chan struct{})}
+	h := NewHandler(a)
+
+	served := make(chan struct{})
+	go func() {
+		post(t, h, "/chat/stream", ChatRequest{Input: "hello"})
+		close(served)
+	}()
+	<-a.started
+
+	closed := make(chan struct{})
+	go func() {
+		h.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not cancel the in-flight stream")
+	}
+	select {
+	case <-served:
+	default:
+		t.Error("Close returned before the stream request finished")
+	}
+
+	if resp := post(t, h, "/chat/stream", ChatRequest{Input: "x"}); resp.Code != http.StatusServiceUnavailable {
+		t.Errorf("stream after Close status = %d, want 503", resp.Code)
+	}
+}
+
+func TestChatStreamResume(t *testing.T) {
+	h := NewHandler(&taskRecorder{}, WithStreamResume(nil, time.Minute))
+	defer h.Close()
+
+	resp := post(t, h, "/chat/stream", ChatRequest{Input: "hello"})
+	id := resp.Header().Get("X-Stream-ID")
+	if id == "" || !strings.Contains(resp.Body.String(), "id: 1\n") {
+		t.Fatalf("stream id %q, body:\n%s", id, resp.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/chat/stream/"+id, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if body := rec.Body.String(); strings.Contains(body, "event: text-delta") || !strings.Contains(body, "event: done") {
+		t.Errorf("resume after id 1:\n%s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/chat/stream/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown stream status = %d, want 404", rec.Code)
+	}
+}
+
+func TestCloseCancelsParkedRuns(t *testing.T) {
+	h := NewHandler(agent.New("asker", "asks", askUserProvider()))
+
+	resp := post(t, h, "/chat", ChatRequest{Input: "sign me up"})
+	var ir InputRequired
+	_ = json.Unmarshal(resp.Body.Bytes(), &ir)
+
+	closed := make(chan struct{})
+	go func() {
+		h.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return; parked run was not cancelled")
+	}
+
+	if resp := post(t, h, "/chat/resume", ResumeRequest{ResumeToken: ir.ResumeToken, Value: "pro"}); resp.Code != http.StatusNotFound {
+		t.Errorf("resume after Close status = %d, want 404", resp.Code)
+	}
+	if resp := post(t, h, "/chat", ChatRequest{Input: "x"}); resp.Code != http.StatusServiceUnavailable {
+		t.Errorf("chat after Close status = %d, want 503", resp.Code)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+}
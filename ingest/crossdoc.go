@@ -8,6 +8,7 @@ import (
 	"sync/atomic"
 
 	oasis "github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/ratelimit"
 )
 
 // DocumentChunkLister is an optional Store capability for listing chunks
@@ -82,34 +83,93 @@ func (ing *Ingestor) ExtractCrossDocumentEdges(ctx context.Context, opts ...Cros
 	// Load or create the crossdoc checkpoint.
 	var cpID string
 	processedDocs := make(map[string]bool)
-
 	if cfg.resume {
-		cs := ing.checkpointStoreOf()
-		if cs != nil {
-			cps, err := cs.ListCheckpoints(ctx)
-			if err == nil {
-				for _, cp := range cps {
-					if cp.Type == "crossdoc" {
-						cpID = cp.ID
-						var state crossDocState
-						if cp.BatchData != "" {
-							if jerr := json.Unmarshal([]byte(cp.BatchData), &state); jerr == nil {
-								for _, id := range state.ProcessedDocIDs {
-									processedDocs[id] = true
-								}
-							}
-						}
-						break
-					}
-				}
-			}
-		}
+		cpID, processedDocs = ing.loadCrossDocCheckpoint(ctx)
 	}
 
 	totalEdges, err := ing.runCrossDoc(ctx, cfg, gs, dcl, cpID, processedDocs)
 	return totalEdges, err
 }
 
+// ExtractCrossDocumentEdgesFor extracts cross-document edges for newly
+// ingested documents only: each of docIDs is paired against the whole
+// corpus, so every discovered pair has at least one new side, and chunk
+// pairs already connected by a stored edge are not sent to the LLM again.
+// Use it after ingesting a few documents instead of a full
+// ExtractCrossDocumentEdges run.
+//
+// If an interrupted resumable run left a checkpoint, the documents are
+// recorded in it, so resuming that run skips them; the checkpoint itself is
+// kept for the resume. opts apply as for ExtractCrossDocumentEdges, except
+// that CrossDocWithDocumentIDs is replaced by docIDs.
+//
+// Returns the number of edges created.
+func (ing *Ingestor) ExtractCrossDocumentEdgesFor(ctx context.Context, docIDs []string, opts ...CrossDocOption) (int, error) {
+	if len(docIDs) == 0 {
+		return 0, nil
+	}
+	if ing.graphProvider == nil {
+		return 0, fmt.Errorf("cross-document extraction requires WithGraphExtraction")
+	}
+	gs, ok := ing.store.(oasis.GraphStore)
+	if !ok {
+		if ing.logger != nil {
+			ing.logger.Warn("cross-doc: store does not implement GraphStore, skipping")
+		}
+		return 0, nil
+	}
+	dcl, ok := ing.store.(DocumentChunkLister)
+	if !ok {
+		return 0, fmt.Errorf("cross-document extraction requires store to implement DocumentChunkLister")
+	}
+
+	cfg := crossDocConfig{
+		similarityThreshold: 0.5,
+		maxPairsPerChunk:    3,
+		batchSize:           5,
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	cfg.documentIDs = docIDs
+
+	cpID, processedDocs := ing.loadCrossDocCheckpoint(ctx)
+	// Only an existing checkpoint is updated: an incremental run never
+	// creates one, and never deletes the one a resume is waiting on.
+	cfg.resume = cpID != ""
+	cfg.keepCheckpoint = true
+	return ing.runCrossDoc(ctx, cfg, gs, dcl, cpID, processedDocs)
+}
+
+// loadCrossDocCheckpoint returns the ID and processed documents of the
+// pending crossdoc checkpoint, or "" and an empty set when there is none.
+func (ing *Ingestor) loadCrossDocCheckpoint(ctx context.Context) (string, map[string]bool) {
+	processed := make(map[string]bool)
+	cs := ing.checkpointStoreOf()
+	if cs == nil {
+		return "", processed
+	}
+	cps, err := cs.ListCheckpoints(ctx)
+	if err != nil {
+		return "", processed
+	}
+	for _, cp := range cps {
+		if cp.Type != "crossdoc" {
+			continue
+		}
+		var state crossDocState
+		if cp.BatchData != "" {
+			if jerr := json.Unmarshal([]byte(cp.BatchData), &state); jerr == nil {
+				for _, id := range state.ProcessedDocIDs {
+					processed[id] = true
+				}
+			}
+		}
+		return cp.ID, processed
+	}
+	return "", processed
+}
+
 // ResumeCrossDocExtraction resumes a previously interrupted cross-document
 // extraction using a checkpoint ID from ListCheckpoints.
 func (ing *Ingestor) ResumeCrossDocExtraction(ctx context.Context, checkpointID string, opts ...CrossDocOption) (int, error) {
@@ -258,6 +318,12 @@ func (ing *Ingestor) runCrossDoc(
 	// Discover optional batch search capability.
 	batchSearcher, hasBatch := ing.store.(BatchSearcher)
 
+	// One limiter for the whole run, shared by every worker.
+	graphProvider := ing.graphProvider
+	if cfg.rpm > 0 {
+		graphProvider = ratelimit.RateLimitMiddleware(ratelimit.RPM(cfg.rpm))(graphProvider)
+	}
+
 	// 2. Process each document.
 	type chunkPair struct {
 		local  oasis.Chunk
@@ -288,6 +354,27 @@ func (ing *Ingestor) runCrossDoc(
 			}
 		}
 
+		// Pairs already joined by a stored edge (from an earlier run) need
+		// no LLM call; neither does a pair another document of this run has
+		// already claimed.
+		stored, err := storedEdgePairs(ctx, gs, embChunks)
+		if err != nil && ing.logger != nil {
+			ing.logger.Warn("cross-doc: stored edge lookup failed, re-extracting pairs", "doc", doc.Source, "err", err)
+		}
+		seenPair := func(a, b string) bool {
+			if stored[a+":"+b] {
+				return true
+			}
+			key1, key2 := a+":"+b, b+":"+a
+			mu.Lock()
+			defer mu.Unlock()
+			if globalSeen[key1] || globalSeen[key2] {
+				return true
+			}
+			globalSeen[key1] = true
+			return false
+		}
+
 		// Search for cross-document candidates — batch or per-chunk.
 		var pairs []chunkPair
 		if hasBatch && len(embeddings) > 0 {
@@ -305,15 +392,7 @@ func (ing *Ingestor) runCrossDoc(
 					if cand.Score < cfg.similarityThreshold {
 						continue
 					}
-					key1 := c.ID + ":" + cand.ID
-					key2 := cand.ID + ":" + c.ID
-					mu.Lock()
-					seen := globalSeen[key1] || globalSeen[key2]
-					if !seen {
-						globalSeen[key1] = true
-					}
-					mu.Unlock()
-					if seen {
+					if seenPair(c.ID, cand.ID) {
 						continue
 					}
 					pairs = append(pairs, chunkPair{local: c, remote: cand.Chunk})
@@ -332,15 +411,7 @@ func (ing *Ingestor) runCrossDoc(
 				if cand.Score < cfg.similarityThreshold {
 					continue
 				}
-				key1 := c.ID + ":" + cand.ID
-				key2 := cand.ID + ":" + c.ID
-				mu.Lock()
-				seen := globalSeen[key1] || globalSeen[key2]
-				if !seen {
-					globalSeen[key1] = true
-				}
-				mu.Unlock()
-				if seen {
+				if seenPair(c.ID, cand.ID) {
 					continue
 				}
 				pairs = append(pairs, chunkPair{local: c, remote: cand.Chunk})
@@ -374,7 +445,7 @@ func (ing *Ingestor) runCrossDoc(
 			}
 		}
 
-		edges, err := extractGraphEdges(ctx, ing.graphProvider, batchChunks, cfg.batchSize, 0, ing.graphWorkers, "", ing.llmTimeout, ing.logger)
+		edges, err := extractGraphEdges(ctx, graphProvider, batchChunks, cfg.batchSize, 0, ing.graphWorkers, "", ing.llmTimeout, ing.logger)
 		if err != nil {
 			if ing.logger != nil {
 				ing.logger.Error("cross-doc: edge extraction failed", "doc", doc.Source, "err", err)
@@ -452,8 +523,9 @@ func (ing *Ingestor) runCrossDoc(
 		wg.Wait()
 	}
 
-	// Delete checkpoint on successful completion.
-	if cpID != "" {
+	// Delete the checkpoint only once every document is recorded: after a
+	// cancellation or a failed document it is what the next resume reads.
+	if cpID != "" && !cfg.keepCheckpoint && ctx.Err() == nil && int(processedCount.Load()) == len(docs) {
 		ing.deleteCheckpoint(ctx, cpID)
 	}
 
@@ -466,3 +538,40 @@ func (ing *Ingestor) runCrossDoc(
 
 	return total, nil
 }
+
+// storedEdgePairs returns "a:b" keys, in both directions, for every stored
+// edge touching chunks. On a failed lookup it returns an empty set with the
+// error; callers can carry on with it, since re-extracted pairs are
+// deduplicated when stored.
+func storedEdgePairs(ctx context.Context, gs oasis.GraphStore, chunks []oasis.Chunk) (map[string]bool, error) {
+	pairs := make(map[string]bool)
+	if len(chunks) == 0 {
+		return pairs, nil
+	}
+	ids := make([]string, len(chunks))
+	for i, c := range chunks {
+		ids[i] = c.ID
+	}
+	var edges []oasis.ChunkEdge
+	if bgs, ok := gs.(oasis.BidirectionalGraphStore); ok {
+		var err error
+		if edges, err = bgs.GetBothEdges(ctx, ids); err != nil {
+			return pairs, err
+		}
+	} else {
+		out, err := gs.GetEdges(ctx, ids)
+		if err != nil {
+			return pairs, err
+		}
+		in, err := gs.GetIncomingEdges(ctx, ids)
+		if err != nil {
+			return pairs, err
+		}
+		edges = append(out, in...)
+	}
+	for _, e := range edges {
+		pairs[e.SourceID+":"+e.TargetID] = true
+		pairs[e.TargetID+":"+e.SourceID] = true
+	}
+	return pairs, nil
+}
@@ -2,7 +2,12 @@ package ingest
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	oasis "github.com/nevindra/oasis/core"
@@ -50,10 +55,216 @@ func TestExtractCrossDocumentEdges_NoProvider(t *testing.T) {
 	}
 }
 
+func TestExtractCrossDocumentEdges_ConcurrentResume(t *testing.T) {
+	base := &mockCrossDocStore{chunksByDoc: map[string][]oasis.Chunk{}}
+	for i := 1; i <= 6; i++ {
+		id := fmt.Sprintf("d%d", i)
+		base.documents = append(base.documents, oasis.Document{ID: id})
+		base.chunksByDoc[id] = []oasis.Chunk{{ID: "c" + id, DocumentID: id, Content: "text " + id, Embedding: []float32{1, 0}}}
+	}
+	store := &checkpointCrossDocStore{mockCrossDocStore: base, checkpoints: map[string]oasis.IngestCheckpoint{}, failDoc: "d3"}
+	provider := &mockGraphProvider{response: `{"edges":[]}`}
+	ing := NewIngestor(store, &mockEmbeddingProvider{embedding: []float32{1, 0}}, WithGraphExtraction(provider))
+
+	opts := []CrossDocOption{CrossDocWithResume(true), CrossDocWithWorkers(3), CrossDocWithRPM(1000)}
+	if _, err := ing.ExtractCrossDocumentEdges(context.Background(), opts...); err != nil {
+		t.Fatal(err)
+	}
+	// d3 failed, so the checkpoint must survive and list the other five.
+	cps, _ := store.ListCheckpoints(context.Background())
+	if len(cps) != 1 {
+		t.Fatalf("checkpoints = %d, want 1 kept after a failed document", len(cps))
+	}
+	if got := store.processed(t, cps[0].ID); len(got) != 5 || got["d3"] {
+		t.Fatalf("processed = %v, want every document but d3", got)
+	}
+
+	store.failDoc = ""
+	if _, err := ing.ResumeCrossDocExtraction(context.Background(), cps[0].ID, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.chunkLookups["d1"]; got != 1 {
+		t.Errorf("d1 read %d times, want 1 (resume skips processed documents)", got)
+	}
+	if cps, _ := store.ListCheckpoints(context.Background()); len(cps) != 0 {
+		t.Errorf("checkpoints = %d, want none after a complete run", len(cps))
+	}
+}
+
+func TestExtractCrossDocumentEdgesFor(t *testing.T) {
+	base := &mockCrossDocStore{
+		documents: []oasis.Document{{ID: "d1"}, {ID: "d2"}, {ID: "d3"}},
+		chunksByDoc: map[string][]oasis.Chunk{
+			"d1": {{ID: "c1", DocumentID: "d1", Content: "text d1", Embedding: []float32{1, 0}}},
+			"d2": {{ID: "c2", DocumentID: "d2", Content: "text d2", Embedding: []float32{1, 0}}},
+			"d3": {{ID: "c3", DocumentID: "d3", Content: "text d3", Embedding: []float32{1, 0}}},
+		},
+		// c3-c1 was extracted by an earlier run.
+		storedEdges: []oasis.ChunkEdge{{ID: "e0", SourceID: "c1", TargetID: "c3", Relation: oasis.RelReferences, Weight: 0.9}},
+	}
+	store := &corpusCrossDocStore{base}
+	var prompt string
+	provider := &mockGraphProvider{
+		response:      `{"edges":[{"source":"c3","target":"c2","relation":"references","weight":0.8}]}`,
+		capturePrompt: &prompt,
+	}
+	ing := NewIngestor(store, &mockEmbeddingProvider{embedding: []float32{1, 0}}, WithGraphExtraction(provider))
+
+	n, err := ing.ExtractCrossDocumentEdgesFor(context.Background(), []string{"d3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("edges = %d, want 1", n)
+	}
+	if !strings.Contains(prompt, "text d2") || strings.Contains(prompt, "text d1") {
+		t.Errorf("prompt should pair c3 with c2 only (c1 is already linked):\n%s", prompt)
+	}
+}
+
+func TestExtractCrossDocumentEdgesFor_RecordsInPendingCheckpoint(t *testing.T) {
+	base := &mockCrossDocStore{
+		documents: []oasis.Document{{ID: "d1"}, {ID: "d2"}},
+		chunksByDoc: map[string][]oasis.Chunk{
+			"d1": {{ID: "c1", DocumentID: "d1", Content: "a", Embedding: []float32{1, 0}}},
+			"d2": {{ID: "c2", DocumentID: "d2", Content: "b", Embedding: []float32{1, 0}}},
+		},
+	}
+	store := &checkpointCrossDocStore{mockCrossDocStore: base, checkpoints: map[string]oasis.IngestCheckpoint{
+		"cp": {ID: "cp", Type: "crossdoc", BatchData: `{"processed_doc_ids":["d1"]}`},
+	}}
+	ing := NewIngestor(store, &mockEmbeddingProvider{embedding: []float32{1, 0}}, WithGraphExtraction(&mockGraphProvider{response: `{"edges":[]}`}))
+
+	if _, err := ing.ExtractCrossDocumentEdgesFor(context.Background(), []string{"d2"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.processed(t, "cp"); !got["d1"] || !got["d2"] {
+		t.Errorf("processed = %v, want d1 kept and d2 added; checkpoint must survive", got)
+	}
+}
+
 // --- Mock helpers ---
 
+// corpusCrossDocStore searches every other document's chunks and serves
+// stored edges.
+type corpusCrossDocStore struct{ *mockCrossDocStore }
+
+func (s *corpusCrossDocStore) SearchChunks(_ context.Context, _ []float32, _ int, filters ...oasis.ChunkFilter) ([]oasis.ScoredChunk, error) {
+	var exclude string
+	for _, f := range filters {
+		if f.Op == oasis.OpNeq && f.Field == "document_id" {
+			v, _ := f.Value.(oasis.StringValue)
+			exclude = string(v)
+		}
+	}
+	var out []oasis.ScoredChunk
+	for _, d := range s.documents {
+		if d.ID == exclude {
+			continue
+		}
+		for _, c := range s.chunksByDoc[d.ID] {
+			out = append(out, oasis.ScoredChunk{Chunk: c, Score: 0.9})
+		}
+	}
+	return out, nil
+}
+
+func (s *corpusCrossDocStore) edgesWhere(ids []string, end func(oasis.ChunkEdge) string) []oasis.ChunkEdge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []oasis.ChunkEdge
+	for _, e := range s.storedEdges {
+		if slices.Contains(ids, end(e)) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (s *corpusCrossDocStore) GetEdges(_ context.Context, ids []string) ([]oasis.ChunkEdge, error) {
+	return s.edgesWhere(ids, func(e oasis.ChunkEdge) string { return e.SourceID }), nil
+}
+
+func (s *corpusCrossDocStore) GetIncomingEdges(_ context.Context, ids []string) ([]oasis.ChunkEdge, error) {
+	return s.edgesWhere(ids, func(e oasis.ChunkEdge) string { return e.TargetID }), nil
+}
+
+// checkpointCrossDocStore adds oasis.CheckpointStore and fails chunk listing
+// for failDoc.
+type checkpointCrossDocStore struct {
+	*mockCrossDocStore
+	checkpoints  map[string]oasis.IngestCheckpoint
+	chunkLookups map[string]int
+	failDoc      string
+}
+
+func (s *checkpointCrossDocStore) GetChunksByDocument(ctx context.Context, docID string) ([]oasis.Chunk, error) {
+	s.mu.Lock()
+	if s.chunkLookups == nil {
+		s.chunkLookups = map[string]int{}
+	}
+	s.chunkLookups[docID]++
+	s.mu.Unlock()
+	if docID == s.failDoc {
+		return nil, errors.New("unavailable")
+	}
+	return s.mockCrossDocStore.GetChunksByDocument(ctx, docID)
+}
+
+func (s *checkpointCrossDocStore) SaveCheckpoint(_ context.Context, cp oasis.IngestCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[cp.ID] = cp
+	return nil
+}
+
+func (s *checkpointCrossDocStore) LoadCheckpoint(_ context.Context, id string) (oasis.IngestCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[id]
+	if !ok {
+		return cp, oasis.ErrNotFound
+	}
+	return cp, nil
+}
+
+func (s *checkpointCrossDocStore) DeleteCheckpoint(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, id)
+	return nil
+}
+
+func (s *checkpointCrossDocStore) ListCheckpoints(_ context.Context) ([]oasis.IngestCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []oasis.IngestCheckpoint
+	for _, cp := range s.checkpoints {
+		out = append(out, cp)
+	}
+	return out, nil
+}
+
+func (s *checkpointCrossDocStore) processed(t *testing.T, cpID string) map[string]bool {
+	t.Helper()
+	cp, err := s.LoadCheckpoint(context.Background(), cpID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state crossDocState
+	if err := json.Unmarshal([]byte(cp.BatchData), &state); err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[string]bool)
+	for _, id := range state.ProcessedDocIDs {
+		out[id] = true
+	}
+	return out
+}
+
 type mockCrossDocStore struct {
 	oasis.Store
+	mu          sync.Mutex
 	documents   []oasis.Document
 	chunksByDoc map[string][]oasis.Chunk
 	storedEdges []oasis.ChunkEdge
@@ -89,6 +300,8 @@ func (s *mockCrossDocStore) SearchChunks(_ context.Context, _ []float32, topK in
 }
 
 func (s *mockCrossDocStore) StoreEdges(_ context.Context, edges []oasis.ChunkEdge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.storedEdges = append(s.storedEdges, edges...)
 	return nil
 }
@@ -135,3 +348,20 @@ var _ oasis.GraphStore = (*mockCrossDocStore)(nil)
 
 // noopLogger suppresses log output in tests.
 var _ = fmt.Sprintf // suppress unused import
+
+// failingEdgeStore is a mockCrossDocStore whose both-ways edge lookup fails.
+type failingEdgeStore struct{ mockCrossDocStore }
+
+func (s *failingEdgeStore) GetBothEdges(_ context.Context, _ []string) ([]oasis.ChunkEdge, error) {
+	return nil, errors.New("graph offline")
+}
+
+func TestStoredEdgePairsReportsLookupErrors(t *testing.T) {
+	pairs, err := storedEdgePairs(context.Background(), &failingEdgeStore{}, []oasis.Chunk{{ID: "a"}})
+	if err == nil {
+		t.Fatal("want the store error")
+	}
+	if len(pairs) != 0 {
+		t.Errorf("pairs = %v, want none", pairs)
+	}
+}
@@ -19,6 +19,9 @@ type Extractor interface {
 type ExtractResult struct {
 	Text string
 	Meta []PageMeta
+	// Warnings lists problems that cost part of the content without failing
+	// the extraction, such as a page whose OCR failed.
+	Warnings []string
 }
 
 // PageMeta holds metadata for a single page or section of extracted content.
@@ -28,8 +31,14 @@ type PageMeta struct {
 	PageNumber int
 	Heading    string
 	Images     []oasis.Image
-	StartByte  int
-	EndByte    int
+	// OCR marks text recognized from a page image rather than read from
+	// the document's text layer.
+	OCR bool
+	// Tables holds the page's tables as Markdown, kept out of the text
+	// range. Set by PDFExtractor with WithTableExtraction.
+	Tables    []string
+	StartByte int
+	EndByte   int
 }
 
 // MetadataExtractor is an optional capability for extractors that produce
@@ -5,19 +5,59 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ledongthuc/pdf"
 )
 
+// defaultOCRMinChars is the text-layer length below which a page is treated
+// as scanned when OCR fallback is enabled.
+const defaultOCRMinChars = 20
+
 // Compile-time interface checks.
 var _ Extractor = (*PDFExtractor)(nil)
 var _ MetadataExtractor = (*PDFExtractor)(nil)
 
 // PDFExtractor implements Extractor and MetadataExtractor for PDF documents.
-type PDFExtractor struct{}
+type PDFExtractor struct {
+	ocr         OCR
+	ocrMinChars int
+	tables      bool
+}
+
+// PDFOption configures a PDFExtractor.
+type PDFOption func(*PDFExtractor)
+
+// WithOCRFallback recognizes pages whose text layer is missing or nearly
+// empty — scanned documents — with ocr. Recognized pages are marked OCR in
+// their PageMeta, and chunks cut from them carry ChunkMeta.OCR.
+func WithOCRFallback(ocr OCR) PDFOption {
+	return func(e *PDFExtractor) { e.ocr = ocr }
+}
+
+// WithOCRMinChars sets how many characters of extractable text a page needs
+// to skip OCR. Ignored without WithOCRFallback. Default: 20.
+func WithOCRMinChars(n int) PDFOption {
+	return func(e *PDFExtractor) { e.ocrMinChars = n }
+}
+
+// WithTableExtraction detects tables from glyph layout and emits each as a
+// Markdown table in PageMeta.Tables instead of linearizing it into the page
+// text. The ingestor stores every table as its own chunk with ContentType
+// "table". Detection is heuristic — rows of short cells separated by wide
+// gaps — and costs an extra layout pass per page. Off by default.
+func WithTableExtraction() PDFOption {
+	return func(e *PDFExtractor) { e.tables = true }
+}
 
 // NewPDFExtractor creates a PDF extractor.
-func NewPDFExtractor() *PDFExtractor { return &PDFExtractor{} }
+func NewPDFExtractor(opts ...PDFOption) *PDFExtractor {
+	e := &PDFExtractor{ocrMinChars: defaultOCRMinChars}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
 
 // Extract extracts plain text from a PDF document.
 func (e *PDFExtractor) Extract(ctx context.Context, content []byte) (string, error) {
@@ -29,7 +69,12 @@ func (e *PDFExtractor) Extract(ctx context.Context, content []byte) (string, err
 }
 
 // ExtractWithMeta extracts text page-by-page with page number metadata.
-func (e *PDFExtractor) ExtractWithMeta(_ context.Context, content []byte) (ExtractResult, error) {
+// With WithOCRFallback, pages with too little text are recognized by the
+// OCR engine. A page whose OCR fails keeps its text layer, if any, and is
+// listed in ExtractResult.Warnings; only cancellation of ctx fails the
+// extraction. With WithTableExtraction,
+// detected tables move from the page text to PageMeta.Tables.
+func (e *PDFExtractor) ExtractWithMeta(ctx context.Context, content []byte) (ExtractResult, error) {
 	if len(content) == 0 {
 		return ExtractResult{}, fmt.Errorf("empty PDF content")
 	}
@@ -39,6 +84,7 @@ func (e *PDFExtractor) ExtractWithMeta(_ context.Context, content []byte) (Extra
 	}
 	var text strings.Builder
 	var meta []PageMeta
+	var warnings []string
 	for i := 1; i <= r.NumPage(); i++ {
 		page := r.Page(i)
 		if page.V.IsNull() {
@@ -46,13 +92,33 @@ func (e *PDFExtractor) ExtractWithMeta(_ context.Context, content []byte) (Extra
 		}
 		startByte := text.Len()
 		pageText, err := pdfExtractPageText(page)
-		if err != nil {
+		if err != nil && e.ocr == nil {
 			continue
 		}
-		if pageText == "" {
+		ocr := false
+		if e.ocr != nil && utf8.RuneCountInString(pageText) < e.ocrMinChars {
+			recognized, err := e.ocr.RecognizePage(ctx, content, i)
+			switch {
+			case ctx.Err() != nil:
+				return ExtractResult{}, ctx.Err()
+			case err != nil:
+				warnings = append(warnings, fmt.Sprintf("page %d: ocr failed: %v", i, err))
+			default:
+				if recognized = strings.TrimSpace(recognized); len(recognized) > len(pageText) {
+					pageText, ocr = recognized, true
+				}
+			}
+		}
+		var tables []string
+		if e.tables && !ocr {
+			if rest, found, ok := pdfPageTables(page); ok {
+				pageText, tables = rest, found
+			}
+		}
+		if pageText == "" && len(tables) == 0 {
 			continue
 		}
-		if text.Len() > 0 {
+		if text.Len() > 0 && pageText != "" {
 			text.WriteString("\n\n")
 			startByte = text.Len()
 		}
@@ -60,13 +126,16 @@ func (e *PDFExtractor) ExtractWithMeta(_ context.Context, content []byte) (Extra
 		endByte := text.Len()
 		meta = append(meta, PageMeta{
 			PageNumber: i,
+			OCR:        ocr,
+			Tables:     tables,
 			StartByte:  startByte,
 			EndByte:    endByte,
 		})
 	}
 	return ExtractResult{
-		Text: strings.TrimSpace(text.String()),
-		Meta: meta,
+		Text:     strings.TrimSpace(text.String()),
+		Meta:     meta,
+		Warnings: warnings,
 	}, nil
 }
 
@@ -0,0 +1,150 @@
+package ingest
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// minTableRows is the smallest run of aligned rows (header included)
+	// treated as a table.
+	minTableRows = 3
+	// maxTableCellRunes caps cell length: table cells are short, prose lines
+	// laid out in columns are not.
+	maxTableCellRunes = 40
+)
+
+// pdfRow is one line of a page: its cells in reading order.
+type pdfRow struct {
+	y     float64
+	cells []pdfCell
+}
+
+// pdfCell is a run of glyphs on one line; end is the right edge of its last
+// glyph.
+type pdfCell struct {
+	x, end float64
+	text   string
+}
+
+// pdfPageTables splits a page into tables and the remaining text. Tables are
+// detected from glyph positions: at least minTableRows consecutive lines
+// with the same number of short, widely separated cells (two or more).
+// Each table is rendered as a Markdown table whose first row is the header.
+// ok is false when the page has no table; text is then empty and the
+// caller keeps its plain-text extraction.
+func pdfPageTables(page pdf.Page) (text string, tables []string, ok bool) {
+	rows := pdfRows(page.Content().Text)
+	var lines []string
+	for i := 0; i < len(rows); {
+		j := i + 1
+		if isTableRow(rows[i]) {
+			for j < len(rows) && isTableRow(rows[j]) && len(rows[j].cells) == len(rows[i].cells) {
+				j++
+			}
+			if j-i >= minTableRows {
+				tables = append(tables, markdownTable(rows[i:j]))
+				i = j
+				continue
+			}
+			j = i + 1
+		}
+		for _, r := range rows[i:j] {
+			cells := make([]string, len(r.cells))
+			for k, c := range r.cells {
+				cells[k] = c.text
+			}
+			lines = append(lines, strings.Join(cells, " "))
+		}
+		i = j
+	}
+	if len(tables) == 0 {
+		return "", nil, false
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), tables, true
+}
+
+// pdfRows groups glyphs into lines, top to bottom, and each line into cells
+// separated by gaps wider than about a character. Glyph widths may be zero
+// for fonts without width tables; glyphs then share the X of their text run,
+// which still separates runs drawn at different positions.
+func pdfRows(glyphs []pdf.Text) []pdfRow {
+	var rows []pdfRow
+	for _, g := range glyphs {
+		if g.S == "" {
+			continue
+		}
+		tol := math.Max(g.FontSize*0.3, 1)
+		idx := -1
+		for k := range rows {
+			if math.Abs(rows[k].y-g.Y) <= tol {
+				idx = k
+				break
+			}
+		}
+		if idx < 0 {
+			rows = append(rows, pdfRow{y: g.Y})
+			idx = len(rows) - 1
+		}
+		r := &rows[idx]
+		gap := math.Max(g.FontSize, 1)
+		if n := len(r.cells); n > 0 {
+			last := &r.cells[n-1]
+			if g.X >= last.x && g.X-last.end <= gap {
+				last.text += g.S
+				last.end = math.Max(last.end, g.X+g.W)
+				continue
+			}
+		}
+		r.cells = append(r.cells, pdfCell{x: g.X, end: g.X + g.W, text: g.S})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].y > rows[j].y })
+	for k := range rows {
+		cells := rows[k].cells[:0]
+		sort.SliceStable(rows[k].cells, func(i, j int) bool { return rows[k].cells[i].x < rows[k].cells[j].x })
+		for _, c := range rows[k].cells {
+			if c.text = strings.Join(strings.Fields(c.text), " "); c.text != "" {
+				cells = append(cells, c)
+			}
+		}
+		rows[k].cells = cells
+	}
+	return rows
+}
+
+func isTableRow(r pdfRow) bool {
+	if len(r.cells) < 2 {
+		return false
+	}
+	for _, c := range r.cells {
+		if utf8.RuneCountInString(c.text) > maxTableCellRunes {
+			return false
+		}
+	}
+	return true
+}
+
+// markdownTable renders rows as a Markdown table with the first row as the
+// header.
+func markdownTable(rows []pdfRow) string {
+	var b strings.Builder
+	for i, r := range rows {
+		b.WriteString("|")
+		for _, c := range r.cells {
+			b.WriteString(" ")
+			b.WriteString(strings.ReplaceAll(c.text, "|", `\|`))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+		if i == 0 {
+			b.WriteString("|")
+			b.WriteString(strings.Repeat(" --- |", len(r.cells)))
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
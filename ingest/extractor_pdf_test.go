@@ -1,8 +1,14 @@
 package ingest
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+
+	oasis "github.com/nevindra/oasis/core"
 )
 
 func TestPDFExtractEmptyContent(t *testing.T) {
@@ -20,3 +26,184 @@ func TestPDFExtractWithMetaEmptyContent(t *testing.T) {
 		t.Error("expected error for empty content")
 	}
 }
+
+// buildPDF writes a minimal PDF with one page per entry of pages. An empty
+// entry is a page with no text layer, like a scanned page.
+func buildPDF(pages []string) []byte {
+	streams := make([]string, len(pages))
+	for i, text := range pages {
+		if text != "" {
+			streams[i] = pdfText(72, 720, text)
+		}
+	}
+	return buildPDFStreams(streams)
+}
+
+// pdfText draws text at (x, y) in 12pt Helvetica.
+func pdfText(x, y int, text string) string {
+	return fmt.Sprintf("BT /F1 12 Tf %d %d Td (%s) Tj ET\n", x, y, text)
+}
+
+// buildPDFStreams writes a minimal PDF with one page per content stream.
+func buildPDFStreams(pages []string) []byte {
+	var b bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	b.WriteString("%PDF-1.4\n")
+	n := len(pages)
+	kids := make([]string, n)
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), n))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	for i, stream := range pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes()
+}
+
+// fakeOCR recognizes every page as "scanned page N".
+type fakeOCR struct {
+	pages []int
+	err   error
+}
+
+func (f *fakeOCR) RecognizePage(_ context.Context, _ []byte, page int) (string, error) {
+	f.pages = append(f.pages, page)
+	return fmt.Sprintf("scanned page %d", page), f.err
+}
+
+func TestPDFExtractOCRFallback(t *testing.T) {
+	doc := buildPDF([]string{"This page has a real text layer.", ""})
+
+	plain, err := NewPDFExtractor().ExtractWithMeta(context.Background(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plain.Meta) != 1 || plain.Meta[0].PageNumber != 1 {
+		t.Fatalf("meta = %+v, want only the text page without OCR", plain.Meta)
+	}
+
+	ocr := &fakeOCR{}
+	res, err := NewPDFExtractor(WithOCRFallback(ocr)).ExtractWithMeta(context.Background(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ocr.pages) != 1 || ocr.pages[0] != 2 {
+		t.Errorf("OCR pages = %v, want only the page without text", ocr.pages)
+	}
+	if !strings.Contains(res.Text, "real text layer") || !strings.HasSuffix(res.Text, "scanned page 2") {
+		t.Errorf("text = %q, want both pages", res.Text)
+	}
+	if len(res.Meta) != 2 || res.Meta[0].OCR || !res.Meta[1].OCR {
+		t.Errorf("meta = %+v, want page 2 marked OCR", res.Meta)
+	}
+	if m := assignMeta(res.Meta[1].StartByte, res.Meta[1].EndByte, "", res.Meta); m == nil || !m.OCR || m.PageNumber != 2 {
+		t.Errorf("chunk meta = %+v, want OCR on page 2", m)
+	}
+
+	// A failed page is reported and skipped; the other pages survive.
+	res, err = NewPDFExtractor(WithOCRFallback(&fakeOCR{err: errors.New("tesseract missing")})).ExtractWithMeta(context.Background(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(res.Text, "real text layer") || strings.Contains(res.Text, "scanned") {
+		t.Errorf("text = %q, want only the text-layer page", res.Text)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "page 2") || !strings.Contains(res.Warnings[0], "tesseract missing") {
+		t.Errorf("warnings = %q, want the page 2 OCR failure", res.Warnings)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewPDFExtractor(WithOCRFallback(&fakeOCR{err: context.Canceled})).ExtractWithMeta(ctx, doc); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled extraction err = %v, want context.Canceled", err)
+	}
+}
+
+// tablePage draws a heading, a 3-column table and a closing sentence.
+func tablePage() string {
+	page := pdfText(72, 720, "Quarterly results for the fiscal year.")
+	rows := [][]string{
+		{"Quarter", "Revenue", "Growth"},
+		{"Q1", "1.2M", "4%"},
+		{"Q2", "1.4M", "17%"},
+		{"Q3", "1.9M", "36%"},
+	}
+	for i, row := range rows {
+		for j, cell := range row {
+			page += pdfText(72+150*j, 680-20*i, cell)
+		}
+	}
+	return page + pdfText(72, 580, "Revenue grew every quarter.")
+}
+
+const wantTable = `| Quarter | Revenue | Growth |
+| --- | --- | --- |
+| Q1 | 1.2M | 4% |
+| Q2 | 1.4M | 17% |
+| Q3 | 1.9M | 36% |`
+
+func TestPDFExtractTables(t *testing.T) {
+	doc := buildPDFStreams([]string{tablePage()})
+
+	res, err := NewPDFExtractor(WithTableExtraction()).ExtractWithMeta(context.Background(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Meta) != 1 || len(res.Meta[0].Tables) != 1 {
+		t.Fatalf("meta = %+v, want one page with one table", res.Meta)
+	}
+	if got := res.Meta[0].Tables[0]; got != wantTable {
+		t.Errorf("table =\n%s\nwant\n%s", got, wantTable)
+	}
+	if res.Text != "Quarterly results for the fiscal year.\nRevenue grew every quarter." {
+		t.Errorf("text = %q, want the prose without the table", res.Text)
+	}
+
+	plain, err := NewPDFExtractor().ExtractWithMeta(context.Background(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plain.Meta[0].Tables) != 0 || !strings.Contains(plain.Text, "1.9M") {
+		t.Errorf("without the option: meta = %+v, text = %q; want the table left in the text", plain.Meta, plain.Text)
+	}
+}
+
+func TestIngestPDFTableChunks(t *testing.T) {
+	store := &mockStore{}
+	ing := NewIngestor(store, &mockEmbedding{},
+		WithExtractor(TypePDF, NewPDFExtractor(WithTableExtraction())))
+
+	if _, err := ing.IngestFile(context.Background(), buildPDFStreams([]string{pdfText(72, 720, "Cover"), tablePage()}), "report.pdf"); err != nil {
+		t.Fatal(err)
+	}
+	var tables []oasis.Chunk
+	for _, c := range store.chunks {
+		if c.Metadata != nil && c.Metadata.ContentType == "table" {
+			tables = append(tables, c)
+		}
+	}
+	if len(tables) != 1 {
+		t.Fatalf("table chunks = %d, want 1 (chunks: %+v)", len(tables), store.chunks)
+	}
+	c := tables[0]
+	if c.Content != wantTable || c.Metadata.PageNumber != 2 || c.Metadata.SourceURL != "report.pdf" || len(c.Embedding) == 0 {
+		t.Errorf("table chunk = %+v, want the embedded table from page 2", c)
+	}
+	if c.ChunkIndex != len(store.chunks)-1 {
+		t.Errorf("table ChunkIndex = %d, want it after the %d text chunks", c.ChunkIndex, len(store.chunks)-1)
+	}
+}
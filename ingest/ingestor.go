@@ -18,6 +18,9 @@ type IngestResult struct {
 	DocumentID string
 	Document   oasis.Document
 	ChunkCount int
+	// Warnings carries the extractor's ExtractResult.Warnings: content that
+	// was skipped without failing the ingest.
+	Warnings []string
 }
 
 // defaultMaxContentSize is the default maximum content size for extraction (50 MB).
@@ -70,6 +73,9 @@ type Ingestor struct {
 	batchConcurrency   int
 	batchCrossDocEdges bool
 
+	// language tagging
+	langDetector LanguageDetector
+
 	// image embedding config
 	imageEmbedding oasis.MultimodalEmbeddingProvider
 	blobStore      oasis.BlobStore
@@ -307,6 +313,7 @@ func (ing *Ingestor) ingestFile(ctx context.Context, content []byte, filename st
 
 	var text string
 	var pageMeta []PageMeta
+	var warnings []string
 
 	// Use MetadataExtractor if available.
 	if me, ok := extractor.(MetadataExtractor); ok {
@@ -326,7 +333,11 @@ func (ing *Ingestor) ingestFile(ctx context.Context, content []byte, filename st
 		}
 		text = result.Text
 		pageMeta = result.Meta
+		warnings = result.Warnings
 		if ing.logger != nil {
+			for _, w := range warnings {
+				ing.logger.Warn("extraction warning", "doc_id", docID, "source", filename, "warning", w)
+			}
 			ing.logger.Debug("extraction completed",
 				"doc_id", docID, "text_bytes", len(text),
 				"page_meta_count", len(pageMeta))
@@ -436,6 +447,7 @@ func (ing *Ingestor) ingestFile(ctx context.Context, content []byte, filename st
 		DocumentID: docID,
 		Document:   doc,
 		ChunkCount: len(chunks),
+		Warnings:   warnings,
 	}
 	if ing.logger != nil {
 		ing.logger.Info("ingest completed",
@@ -638,11 +650,57 @@ func chunkWith(ctx context.Context, chunker Chunker, text string) ([]string, err
 }
 
 // chunkAndEmbed handles chunking (flat or parent-child) and batched embedding.
+// Tables found by the extractor are appended as chunks of their own.
 func (ing *Ingestor) chunkAndEmbed(ctx context.Context, text, docID string, ct ContentType, source string, pageMeta []PageMeta) ([]oasis.Chunk, error) {
+	var chunks []oasis.Chunk
+	var err error
 	if ing.strategy == StrategyParentChild {
-		return ing.chunkParentChild(ctx, text, docID, ct, source, pageMeta)
+		chunks, err = ing.chunkParentChild(ctx, text, docID, ct, source, pageMeta)
+	} else {
+		chunks, err = ing.chunkFlat(ctx, text, docID, ct, source, pageMeta)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tables, err := ing.embedTableChunks(ctx, docID, source, pageMeta, len(chunks))
+	if err != nil {
+		return nil, err
+	}
+	return append(chunks, tables...), nil
+}
+
+// embedTableChunks turns each table in pageMeta into one embedded chunk with
+// ContentType "table", so a table is retrieved whole rather than split
+// across text chunks. ChunkIndex continues from firstIndex.
+func (ing *Ingestor) embedTableChunks(ctx context.Context, docID, source string, pageMeta []PageMeta, firstIndex int) ([]oasis.Chunk, error) {
+	var chunks []oasis.Chunk
+	for _, pm := range pageMeta {
+		for _, table := range pm.Tables {
+			chunks = append(chunks, oasis.Chunk{
+				ID:         oasis.NewID(),
+				DocumentID: docID,
+				Content:    table,
+				ChunkIndex: firstIndex + len(chunks),
+				Metadata: &oasis.ChunkMeta{
+					PageNumber:  pm.PageNumber,
+					SourceURL:   source,
+					ContentType: "table",
+				},
+			})
+		}
+	}
+	if len(chunks) == 0 {
+		return nil, nil
 	}
-	return ing.chunkFlat(ctx, text, docID, ct, source, pageMeta)
+	if ing.logger != nil {
+		ing.logger.Info("table chunks created",
+			"doc_id", docID, "table_count", len(chunks))
+	}
+	ing.detectLanguages(chunks)
+	if err := ing.batchEmbed(ctx, chunks, nil); err != nil {
+		return nil, err
+	}
+	return chunks, nil
 }
 
 // chunkFlat performs single-level chunking with batched embedding.
@@ -692,6 +750,7 @@ func (ing *Ingestor) chunkFlat(ctx context.Context, text, docID string, ct Conte
 			Metadata:   assignMeta(startByte, endByte, source, pageMeta),
 		}
 	}
+	ing.detectLanguages(chunks)
 
 	if ing.contextProvider != nil {
 		if ing.logger != nil {
@@ -812,6 +871,8 @@ func (ing *Ingestor) chunkParentChild(ctx context.Context, text, docID string, c
 			"doc_id", docID, "parent_count", len(parentTexts),
 			"child_count", len(childChunks))
 	}
+	ing.detectLanguages(allChunks)
+	ing.detectLanguages(childChunks)
 
 	if ing.contextProvider != nil {
 		if ing.logger != nil {
@@ -875,6 +936,7 @@ func assignMeta(startByte, endByte int, source string, pageMeta []PageMeta) *oas
 		if len(best.Images) > 0 {
 			meta.Images = best.Images
 		}
+		meta.OCR = best.OCR
 	}
 
 	return meta
@@ -0,0 +1,179 @@
+package ingest
+
+import (
+	"strings"
+	"unicode"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+// LanguageDetector identifies the language of a chunk's text.
+// Implementations must be safe for concurrent use.
+type LanguageDetector interface {
+	// DetectLanguage returns the ISO 639-1 code of text's language, or ""
+	// when it cannot tell.
+	DetectLanguage(text string) string
+}
+
+// LanguageDetectorFunc adapts a function to LanguageDetector, e.g. to wrap
+// a detection library or service.
+type LanguageDetectorFunc func(text string) string
+
+// DetectLanguage implements LanguageDetector.
+func (f LanguageDetectorFunc) DetectLanguage(text string) string { return f(text) }
+
+// Compile-time interface check.
+var _ LanguageDetector = (*WordListDetector)(nil)
+
+// minLanguageHits is how many function words of the winning language a text
+// needs before WordListDetector commits to it.
+const minLanguageHits = 2
+
+// languageWords holds frequent function words per Latin-script language.
+// Words shared across languages count for each of them; the unshared ones
+// decide.
+var languageWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "was", "with", "as", "on", "are", "this", "be", "by", "have", "from", "not", "or", "which", "you", "they", "we"},
+	"id": {"yang", "dan", "di", "ini", "itu", "dengan", "untuk", "tidak", "dari", "dalam", "akan", "pada", "ke", "juga", "ada", "adalah", "saya", "kami", "kita", "mereka", "bisa", "sudah", "atau", "karena", "oleh"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "las", "del", "se", "por", "un", "una", "con", "no", "es", "para", "al", "lo", "como", "más", "pero", "sus", "su", "está"},
+	"fr": {"le", "la", "les", "de", "des", "et", "est", "que", "une", "un", "du", "en", "dans", "pour", "qui", "pas", "sur", "au", "avec", "ce", "il", "elle", "sont", "nous", "vous"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "den", "von", "zu", "ein", "eine", "auf", "für", "sich", "dem", "des", "auch", "es", "im", "wir", "ich", "sie", "wird", "werden"},
+	"pt": {"o", "a", "os", "as", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "por", "mais", "dos", "das", "se", "na", "no", "ao", "são", "foi"},
+	"it": {"il", "di", "che", "e", "la", "per", "un", "una", "non", "sono", "della", "del", "con", "si", "le", "da", "gli", "in", "questo", "anche", "ma", "è", "come", "nel", "alla"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "in", "zijn", "met", "voor", "die", "er", "ook", "aan", "maar", "als", "bij", "wordt", "dit", "hij", "we"},
+}
+
+// scriptLanguages maps non-Latin scripts to the language reported for them.
+// Han without kana is reported as Chinese and Cyrillic as Russian.
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+}
+
+// WordListDetector is the built-in LanguageDetector. It reads the script
+// first: Japanese, Chinese, Korean, Russian, Arabic, Thai, Hindi, Greek and
+// Hebrew are told apart by their letters. Latin-script text is scored
+// against lists of frequent function words for English, Indonesian,
+// Spanish, French, German, Portuguese, Italian and Dutch. Text too short or
+// too mixed to call returns "". It needs no model or network access.
+type WordListDetector struct {
+	words map[string][]string // word -> languages
+}
+
+// NewWordListDetector creates the built-in detector. When langs is given,
+// Latin-script text is only matched against those languages, which avoids
+// confusing close relatives in a corpus known to hold just a few (for
+// example "en" and "id"). Unknown codes are ignored.
+func NewWordListDetector(langs ...string) *WordListDetector {
+	keep := make(map[string]bool, len(langs))
+	for _, l := range langs {
+		keep[strings.ToLower(l)] = true
+	}
+	d := &WordListDetector{words: make(map[string][]string)}
+	for lang, words := range languageWords {
+		if len(keep) > 0 && !keep[lang] {
+			continue
+		}
+		for _, w := range words {
+			d.words[w] = append(d.words[w], lang)
+		}
+	}
+	return d
+}
+
+// DetectLanguage implements LanguageDetector.
+func (d *WordListDetector) DetectLanguage(text string) string {
+	var latin, han, kana, letters int
+	other := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		default:
+			for _, s := range scriptLanguages {
+				if unicode.Is(s.table, r) {
+					other[s.lang]++
+					break
+				}
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	// A script that makes up most of the letters decides on its own.
+	if kana > 0 && (kana+han)*2 > letters {
+		return "ja"
+	}
+	if han*2 > letters {
+		return "zh"
+	}
+	for lang, n := range other {
+		if n*2 > letters {
+			return lang
+		}
+	}
+	if latin*2 <= letters {
+		return ""
+	}
+	return d.scoreWords(text)
+}
+
+// scoreWords counts function-word hits per language and returns the clear
+// winner, or "" on a tie or too few hits.
+func (d *WordListDetector) scoreWords(text string) string {
+	hits := make(map[string]int)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, lang := range d.words[w] {
+			hits[lang]++
+		}
+	}
+	best, first, second := "", 0, 0
+	for lang, n := range hits {
+		switch {
+		case n > first:
+			best, first, second = lang, n, first
+		case n > second:
+			second = n
+		}
+	}
+	if first < minLanguageHits || first == second {
+		return ""
+	}
+	return best
+}
+
+// detectLanguages sets ChunkMeta.Language on each chunk in place. Chunks
+// whose language cannot be told are left untagged; none are dropped or
+// reordered.
+func (ing *Ingestor) detectLanguages(chunks []oasis.Chunk) {
+	if ing.langDetector == nil {
+		return
+	}
+	for i := range chunks {
+		lang := ing.langDetector.DetectLanguage(chunks[i].Content)
+		if lang == "" {
+			continue
+		}
+		if chunks[i].Metadata == nil {
+			chunks[i].Metadata = &oasis.ChunkMeta{}
+		}
+		chunks[i].Metadata.Language = lang
+	}
+}
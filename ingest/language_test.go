@@ -0,0 +1,64 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWordListDetector(t *testing.T) {
+	d := NewWordListDetector()
+	tests := []struct {
+		text, want string
+	}{
+		{"The quarterly report shows that revenue grew in all of the regions.", "en"},
+		{"Laporan ini menunjukkan bahwa pendapatan yang diterima tidak sesuai dengan target untuk tahun ini.", "id"},
+		{"Der Bericht zeigt, dass die Einnahmen nicht mit dem Ziel übereinstimmen.", "de"},
+		{"El informe muestra que los ingresos de la empresa crecieron por tercer año.", "es"},
+		{"東京は日本の首都です。", "ja"},
+		{"北京是中国的首都。", "zh"},
+		{"서울은 한국의 수도입니다.", "ko"},
+		{"Москва — столица России.", "ru"},
+		{"Q3 1.9M 36%", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := d.DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	// Restricting the candidates keeps Latin text within them.
+	if got := NewWordListDetector("en", "id").DetectLanguage("El informe muestra que los ingresos de la empresa crecieron."); got != "" {
+		t.Errorf("restricted detector = %q, want no match outside en/id", got)
+	}
+}
+
+func TestIngestLanguageTags(t *testing.T) {
+	store := &mockStore{}
+	ing := NewIngestor(store, &mockEmbedding{},
+		WithChunker(NewRecursiveChunker(WithMaxTokens(20), WithOverlapTokens(0))),
+		WithLanguageDetection(NewWordListDetector("en", "id")))
+
+	text := "The report shows that revenue grew in all of the regions this year.\n\n" +
+		"Laporan ini menunjukkan bahwa pendapatan yang diterima tidak sesuai dengan target.\n\n" +
+		"12 34 56"
+	if _, err := ing.IngestText(context.Background(), text, "", "mixed"); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.chunks) != 3 {
+		t.Fatalf("chunks = %d, want 3", len(store.chunks))
+	}
+	for i, want := range []string{"en", "id", ""} {
+		c := store.chunks[i]
+		if c.ChunkIndex != i {
+			t.Errorf("chunk %d has ChunkIndex %d, want input order kept", i, c.ChunkIndex)
+		}
+		got := ""
+		if c.Metadata != nil {
+			got = c.Metadata.Language
+		}
+		if got != want {
+			t.Errorf("chunk %d (%q) language = %q, want %q", i, c.Content, got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OCR recognizes the text of one page of a PDF, for pages that have no
+// usable text layer. page is 1-based. Implementations typically render the
+// page to an image and run it through an OCR engine or a vision model.
+type OCR interface {
+	RecognizePage(ctx context.Context, pdf []byte, page int) (string, error)
+}
+
+// Compile-time interface check.
+var _ OCR = (*TesseractOCR)(nil)
+
+// TesseractOCR renders pages with pdftoppm (poppler-utils) and recognizes
+// them with the tesseract CLI. Both binaries must be on PATH at runtime; it
+// adds no Go dependencies.
+type TesseractOCR struct {
+	lang string
+	dpi  int
+}
+
+// TesseractOption configures a TesseractOCR.
+type TesseractOption func(*TesseractOCR)
+
+// TesseractLanguage sets the tesseract language code, e.g. "deu" or
+// "eng+ind". The language data must be installed. Default: "eng".
+func TesseractLanguage(lang string) TesseractOption {
+	return func(t *TesseractOCR) { t.lang = lang }
+}
+
+// TesseractDPI sets the resolution pages are rendered at. Higher values
+// recognize small print better and run slower. Default: 300.
+func TesseractDPI(dpi int) TesseractOption {
+	return func(t *TesseractOCR) { t.dpi = dpi }
+}
+
+// NewTesseractOCR creates an OCR engine backed by pdftoppm and tesseract.
+func NewTesseractOCR(opts ...TesseractOption) *TesseractOCR {
+	t := &TesseractOCR{lang: "eng", dpi: 300}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// RecognizePage implements OCR.
+func (t *TesseractOCR) RecognizePage(ctx context.Context, pdf []byte, page int) (string, error) {
+	dir, err := os.MkdirTemp("", "oasis-ocr-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "doc.pdf")
+	if err := os.WriteFile(in, pdf, 0o600); err != nil {
+		return "", err
+	}
+	n := strconv.Itoa(page)
+	root := filepath.Join(dir, "page")
+	if _, err := runTool(ctx, "pdftoppm", "-f", n, "-l", n, "-r", strconv.Itoa(t.dpi), "-png", "-singlefile", in, root); err != nil {
+		return "", err
+	}
+	out, err := runTool(ctx, "tesseract", root+".png", "stdout", "-l", t.lang)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// runTool runs an external binary and returns its stdout, folding stderr
+// into the error.
+func runTool(ctx context.Context, name string, args ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%s not found on PATH: %w", name, err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+	}
+	return stdout.String(), nil
+}
@@ -130,6 +130,15 @@ func WithContextMaxDocBytes(n int) Option {
 	return func(ing *Ingestor) { ing.contextMaxDocBytes = n }
 }
 
+// WithLanguageDetection tags every text and table chunk with the language
+// d detects in it, in ChunkMeta.Language (JSON key "lang"), so searches can
+// be scoped with oasis.ByLanguage. Detection runs on the chunk text before
+// contextual enrichment; chunks d cannot call stay untagged. Use
+// NewWordListDetector for the built-in detector.
+func WithLanguageDetection(d LanguageDetector) Option {
+	return func(ing *Ingestor) { ing.langDetector = d }
+}
+
 // WithIngestorTracer sets the Tracer for an Ingestor.
 func WithIngestorTracer(t oasis.Tracer) Option {
 	return func(ing *Ingestor) { ing.tracer = t }
@@ -231,7 +240,9 @@ type crossDocConfig struct {
 	maxPairsPerChunk    int
 	batchSize           int
 	workers             int
+	rpm                 int
 	resume              bool
+	keepCheckpoint      bool // set by ExtractCrossDocumentEdgesFor
 	progressFunc        func(processed, total int)
 }
 
@@ -269,6 +280,15 @@ func CrossDocWithWorkers(n int) CrossDocOption {
 	return func(c *crossDocConfig) { c.workers = n }
 }
 
+// CrossDocWithRPM caps the graph provider's requests per minute across all
+// workers of the run (default: unlimited), so a large corpus does not trip
+// the provider's rate limit. Requests wait for budget rather than fail. For
+// a limit shared with other callers, wrap the provider passed to
+// WithGraphExtraction in ratelimit.RateLimitMiddleware instead.
+func CrossDocWithRPM(n int) CrossDocOption {
+	return func(c *crossDocConfig) { c.rpm = n }
+}
+
 // CrossDocWithProgressFunc sets a callback invoked after each document is
 // processed. The callback receives the number of documents processed so far
 // and the total number of documents to process.
@@ -0,0 +1,56 @@
+// Package netguard holds the address checks the web tools use to keep the
+// model from reaching internal services (SSRF): tools/http blocks such
+// connections in its dialer, tools/browser on every request its tab makes.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ErrPrivateAddress is returned for non-public addresses.
+var ErrPrivateAddress = errors.New("blocked: address is private, loopback or link-local")
+
+// cgnat is the carrier-grade NAT range (RFC 6598), which net.IP.IsPrivate
+// does not cover.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPrivateIP reports whether ip is loopback, private, link-local (including
+// the 169.254.169.254 cloud metadata endpoint), CGNAT, unspecified or
+// multicast.
+func IsPrivateIP(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+		if v4[0] == 0 || cgnat.Contains(v4) {
+			return true
+		}
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// CheckHost resolves host with lookup (net.DefaultResolver.LookupIP when
+// nil) and returns an error wrapping ErrPrivateAddress if any of its
+// addresses is private. An IP literal is checked as is. A host that does not
+// resolve is an error too.
+func CheckHost(ctx context.Context, host string, lookup func(ctx context.Context, network, host string) ([]net.IP, error)) error {
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if lookup == nil {
+			lookup = net.DefaultResolver.LookupIP
+		}
+		var err error
+		if ips, err = lookup(ctx, "ip", host); err != nil {
+			return fmt.Errorf("resolve %s: %w", host, err)
+		}
+	}
+	for _, ip := range ips {
+		if IsPrivateIP(ip) {
+			return fmt.Errorf("%w (%s)", ErrPrivateAddress, ip)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestIsPrivateIP(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"172.16.0.1":      true,
+		"192.168.1.1":     true,
+		"169.254.169.254": true,
+		"100.64.0.1":      true,
+		"0.0.0.0":         true,
+		"::1":             true,
+		"fd00:ec2::254":   true,
+		"fe80::1":         true,
+		"::ffff:10.0.0.1": true,
+		"8.8.8.8":         false,
+		"93.184.216.34":   false,
+		"2606:4700::1111": false,
+	}
+	for addr, want := range cases {
+		if got := IsPrivateIP(net.ParseIP(addr)); got != want {
+			t.Errorf("IsPrivateIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	lookup := func(_ context.Context, _, host string) ([]net.IP, error) {
+		switch host {
+		case "public.test":
+			return []net.IP{net.ParseIP("93.184.216.34")}, nil
+		case "mixed.test":
+			return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("10.0.0.5")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	ctx := context.Background()
+	if err := CheckHost(ctx, "public.test", lookup); err != nil {
+		t.Errorf("public host: %v", err)
+	}
+	if err := CheckHost(ctx, "mixed.test", lookup); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("host with a private address: err = %v, want ErrPrivateAddress", err)
+	}
+	if err := CheckHost(ctx, "missing.test", lookup); err == nil {
+		t.Error("unresolvable host: want an error")
+	}
+	if err := CheckHost(ctx, "127.0.0.1", nil); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("loopback literal: err = %v, want ErrPrivateAddress", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/nevindra/oasis/core"
@@ -117,11 +119,49 @@ type Config struct {
 	GenParams           *core.GenerationParams
 	ActiveSkills        []skills.Skill
 	SkillProvider       skills.SkillProvider
+	// SkillSearcher, when set, backs skill_search instead of the provider's
+	// own searcher or BM25. Set via agent.WithSkillSearcher.
+	SkillSearcher skills.SkillSearcher
 	// SkillCatalog, when true, injects the provider's Discover() summaries into
 	// the system prompt each request so the model sees available skills before
 	// its first tool call. Set via agent.WithSkillCatalog.
 	SkillCatalog bool
 
+	// ResponseSchemaRetries is how many times a final response that fails
+	// ResponseSchema validation is sent back to the model with the errors.
+	// 0 disables validation. Set via agent.WithResponseSchemaRetries.
+	ResponseSchemaRetries int
+
+	// AutoContinue is how many times a final response truncated by the
+	// output-token limit is continued with a follow-up turn. 0 disables it.
+	// Set via agent.WithAutoContinue.
+	AutoContinue int
+
+	// DryRun records tool calls instead of executing them; DryRunTools
+	// limits it to the named tools (empty = every tool). Set via
+	// agent.WithDryRun.
+	DryRun      bool
+	DryRunTools []string
+
+	// ToolCallFallback enables prompt-based tool calling for providers that
+	// report no native tool support. Set via agent.WithToolCallFallback.
+	ToolCallFallback bool
+
+	// MaxInputAttachmentBytes caps each attachment on the task itself
+	// (AgentTask.Attachments), checked before the first LLM call. 0 = no
+	// limit. Set via Limits.
+	MaxInputAttachmentBytes int64
+	// AttachmentTypes is the MIME allowlist for task attachments ("image/*"
+	// matches any image). Empty defers to the provider's own list when it
+	// implements core.AttachmentTypesProvider. Set via agent.WithAttachmentTypes.
+	AttachmentTypes []string
+
+	// TokenBudget caps the input+output tokens a single run may spend. Once
+	// the running total reaches it, no further LLM call is made and the run
+	// ends with agent.ErrBudgetExceeded. 0 = no limit. Set via
+	// agent.WithTokenBudget or Limits.
+	TokenBudget int
+
 	// DisablePromptCaching opts the agent out of automatic cache-breakpoint
 	// placement on its LLM calls. By default (DisablePromptCaching=false), the
 	// agent loop marks messages[0] (system + tools prefix) and the current tail
@@ -175,6 +215,19 @@ type Config struct {
 	ToolPolicies map[string]core.ToolPolicy
 	// Ordered matchers; first match wins (after exact).
 	ToolPolicyMatchers []toolPolicyMatcher
+	// ToolTimeout is the default per-call deadline for tools whose resolved
+	// policy has no Timeout. 0 = none. Set via agent.WithToolTimeout.
+	ToolTimeout time.Duration
+	// ToolRetries and ToolRetryDelay are the default retry budget and base
+	// backoff for tools whose resolved policy has no Retries. Set via
+	// agent.WithToolRetry.
+	ToolRetries    int
+	ToolRetryDelay time.Duration
+	// NoRetryTools holds the names (as keys) of tools that opted out of
+	// default retries via core.RetrySafeTool: static, sandbox and skill tools
+	// from Init, and dynamic tools as they resolve. A pointer so per-run
+	// Config copies share it.
+	NoRetryTools *sync.Map
 
 	// Per-tool payload transforms (exact name entries).
 	ToolTransforms map[string]core.ToolTransform
@@ -186,6 +239,10 @@ type Config struct {
 	MaxPlanSteps        int
 	MaxToolResultLen    int
 
+	// SequentialTools runs one response's tool calls one at a time, in the
+	// order the LLM emitted them. Set via agent.WithSequentialTools.
+	SequentialTools bool
+
 	// Tool result paging store.
 	ToolResultStore    core.ToolResultStore
 	ToolResultStoreSet bool
@@ -205,14 +262,61 @@ type Config struct {
 	ScoreStore core.ScoreStore
 	// ScoreSink forwards persisted scorer results to an external platform. Optional.
 	ScoreSink core.ScoreSink
+
+	// TraceSink receives a RunRecord after every Execute. Set via
+	// agent.WithTraceSink.
+	TraceSink core.TraceSink
+}
+
+// IsDryRun reports whether a call to the named tool is recorded instead of
+// executed.
+func (c *Config) IsDryRun(name string) bool {
+	if c == nil || !c.DryRun {
+		return false
+	}
+	return len(c.DryRunTools) == 0 || slices.Contains(c.DryRunTools, name)
 }
 
 // ResolveToolPolicy implements ServeMux-style policy lookup: exact-name first,
 // then matchers in registration order.
+// A policy without a Timeout (or no policy at all) picks up ToolTimeout, and
+// one without Retries picks up ToolRetries unless the tool opted out.
 func (c *Config) ResolveToolPolicy(name string) (core.ToolPolicy, bool) {
 	if c == nil {
 		return core.ToolPolicy{}, false
 	}
+	p, ok := c.lookupToolPolicy(name)
+	if c.ToolTimeout > 0 && p.Timeout == 0 {
+		p.Timeout = c.ToolTimeout
+		ok = true
+	}
+	if c.ToolRetries > 0 && p.Retries == 0 && !c.retryOptedOut(name) {
+		p.Retries = c.ToolRetries
+		if p.RetryDelay == 0 {
+			p.RetryDelay = c.ToolRetryDelay
+		}
+		ok = true
+	}
+	return p, ok
+}
+
+func (c *Config) retryOptedOut(name string) bool {
+	if c.NoRetryTools == nil {
+		return false
+	}
+	_, ok := c.NoRetryTools.Load(name)
+	return ok
+}
+
+// noteRetryOptOut records t's core.RetrySafeTool opt-out. Call it with the
+// unwrapped tool: middleware wrapping hides the method.
+func (c *Config) noteRetryOptOut(t core.AnyTool) {
+	if rs, ok := t.(core.RetrySafeTool); ok && !rs.RetrySafe() && c.NoRetryTools != nil {
+		c.NoRetryTools.Store(t.Name(), struct{}{})
+	}
+}
+
+func (c *Config) lookupToolPolicy(name string) (core.ToolPolicy, bool) {
 	if p, ok := c.ToolPolicies[name]; ok {
 		return p, true
 	}
@@ -266,6 +370,13 @@ type Limits struct {
 	MaxToolResultLen    int
 	MaxSuspendSnapshots int
 	MaxSuspendBytes     int64
+	// MaxInputAttachmentBytes caps each attachment the caller sends in
+	// AgentTask.Attachments; MaxAttachmentBytes only budgets attachments
+	// collected from tool results. 0 = no limit.
+	MaxInputAttachmentBytes int64
+	// TokenBudget caps the input+output tokens of one run, summed across
+	// every LLM call. 0 = no limit.
+	TokenBudget int
 }
 
 // ApplyTo overlays non-zero fields from l onto c.
@@ -298,6 +409,12 @@ func (l Limits) ApplyTo(c *Config) {
 	if l.MaxSuspendBytes != 0 {
 		c.MaxSuspendBytes = l.MaxSuspendBytes
 	}
+	if l.MaxInputAttachmentBytes != 0 {
+		c.MaxInputAttachmentBytes = l.MaxInputAttachmentBytes
+	}
+	if l.TokenBudget != 0 {
+		c.TokenBudget = l.TokenBudget
+	}
 }
 
 // ---- Processors & Hooks ----
@@ -375,6 +492,9 @@ func LimitsFromConfig(c *Config) Limits {
 		MaxToolResultLen:    c.MaxToolResultLen,
 		MaxSuspendSnapshots: c.MaxSuspendSnapshots,
 		MaxSuspendBytes:     c.MaxSuspendBytes,
+
+		MaxInputAttachmentBytes: c.MaxInputAttachmentBytes,
+		TokenBudget:             c.TokenBudget,
 	}
 }
 
@@ -388,8 +508,8 @@ func LimitsFromConfig(c *Config) Limits {
 // without a second edit.
 type Generation = core.GenerationParams
 
-// CloneGeneration returns a deep copy of g — each non-nil pointer field is
-// freshly allocated so the caller and source no longer share underlying
+// CloneGeneration returns a deep copy of g — each non-nil pointer or slice field
+// is freshly allocated so the caller and source no longer share underlying
 // values. Used by Runtime.Generation, mergeGenerationParams, and
 // agent.WithGeneration to keep their copy semantics consistent.
 func CloneGeneration(g Generation) Generation {
@@ -398,7 +518,7 @@ func CloneGeneration(g Generation) Generation {
 	return out
 }
 
-// overlayNonNilGeneration copies each non-nil pointer field of src into dst,
+// overlayNonNilGeneration copies each non-nil pointer or slice field of src into dst,
 // deep-copying so dst no longer aliases src. nil fields on src leave dst's
 // existing value in place. The single owner of the GenerationParams field
 // list — both CloneGeneration and mergeGenerationParams delegate here so a
@@ -420,6 +540,13 @@ func overlayNonNilGeneration(dst *core.GenerationParams, src *Generation) {
 		v := *src.MaxTokens
 		dst.MaxTokens = &v
 	}
+	if src.Seed != nil {
+		v := *src.Seed
+		dst.Seed = &v
+	}
+	if src.StopSequences != nil {
+		dst.StopSequences = slices.Clone(src.StopSequences)
+	}
 }
 
 // ---- RunOptions ----
@@ -492,6 +619,12 @@ func (o *RunOptions) Validate() error {
 		if lim.MaxSuspendBytes < 0 {
 			return &RunOptionsError{Field: "Limits.MaxSuspendBytes", Message: "must be >= 0"}
 		}
+		if lim.MaxInputAttachmentBytes < 0 {
+			return &RunOptionsError{Field: "Limits.MaxInputAttachmentBytes", Message: "must be >= 0"}
+		}
+		if lim.TokenBudget < 0 {
+			return &RunOptionsError{Field: "Limits.TokenBudget", Message: "must be >= 0"}
+		}
 	}
 	return nil
 }
@@ -17,6 +17,15 @@ type DispatchResult struct {
 	// UI, when non-nil, carries a renderable component descriptor produced by
 	// the tool. Copied from ToolResult.UI on the success path.
 	UI *core.UIComponent
+	// Handoff, when non-nil, records an agent-to-agent transfer that
+	// happened during this dispatch. Copied onto the StepTrace.
+	Handoff *core.Handoff
+	// DryRun marks a call that was recorded but not executed because the
+	// agent runs with WithDryRun. Copied onto the StepTrace.
+	DryRun bool
+	// Sources are the citations the tool attached to its result. Copied from
+	// ToolResult.Sources on the success path.
+	Sources []core.Source
 }
 
 // DispatchFunc executes a single tool call and returns the result.
@@ -94,6 +94,7 @@ func Init(c *Runtime, name, description string, provider core.Provider, cfg *Con
 	c.description = description
 	c.provider = provider
 	c.tools = core.NewToolRegistry()
+	c.NoRetryTools = new(sync.Map)
 	c.processors = processor.NewChain()
 
 	// Default maxIter when not explicitly set.
@@ -130,20 +131,24 @@ func Init(c *Runtime, name, description string, provider core.Provider, cfg *Con
 	effectiveMiddleware := c.effectiveToolMiddleware()
 
 	for _, t := range cfg.Tools {
-		c.tools.Add(core.ApplyToolMiddleware(t, effectiveMiddleware))
+		c.addTool(t, effectiveMiddleware)
 	}
 
 	// Register sandbox tools when a sandbox is configured.
 	if cfg.Sandbox != nil {
 		for _, t := range cfg.SandboxTools {
-			c.tools.Add(core.ApplyToolMiddleware(t, effectiveMiddleware))
+			c.addTool(t, effectiveMiddleware)
 		}
 	}
 
 	// Register skill tools when a skill provider is configured.
 	if cfg.SkillProvider != nil {
-		for _, t := range skills.NewSkillTools(cfg.SkillProvider) {
-			c.tools.Add(core.ApplyToolMiddleware(t, effectiveMiddleware))
+		var opts []skills.ToolOption
+		if cfg.SkillSearcher != nil {
+			opts = append(opts, skills.WithSearcher(cfg.SkillSearcher))
+		}
+		for _, t := range skills.NewSkillTools(cfg.SkillProvider, opts...) {
+			c.addTool(t, effectiveMiddleware)
 		}
 	}
 
@@ -173,6 +178,13 @@ func Init(c *Runtime, name, description string, provider core.Provider, cfg *Con
 	c.cachedLookupTool = c.tools.Lookup
 }
 
+// addTool registers t wrapped in mws. The RetrySafeTool opt-out is read
+// first, because the wrapping hides it.
+func (c *Runtime) addTool(t core.AnyTool, mws []core.ToolMiddleware) {
+	c.noteRetryOptOut(t)
+	c.tools.Add(core.ApplyToolMiddleware(t, mws))
+}
+
 // Name returns the agent's name.
 func (c *Runtime) Name() string { return c.name }
 
@@ -350,6 +362,7 @@ func (c *Runtime) ResolveDynamicTools(ctx context.Context, task core.AgentTask)
 	var toolDefs []core.ToolDefinition
 	index := make(map[string]core.AnyTool, len(dynTools))
 	for _, t := range dynTools {
+		c.noteRetryOptOut(t)
 		wrapped := core.ApplyToolMiddleware(t, mws)
 		toolDefs = append(toolDefs, wrapped.Definition())
 		index[wrapped.Name()] = wrapped
@@ -508,6 +521,16 @@ func (c *Runtime) ExecuteWithSpan(
 				"steps", len(result.Steps))
 		}
 	}
+	if c.Config.TraceSink != nil {
+		c.Config.TraceSink(ctx, core.RunRecord{
+			Agent:    c.name,
+			Task:     task,
+			Result:   result,
+			Err:      err,
+			Start:    start,
+			Duration: time.Since(start),
+		})
+	}
 	return result, err
 }
 
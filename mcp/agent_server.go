@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+// agentToolMeta is the _meta payload attached to an agent tool result.
+type agentToolMeta struct {
+	Usage        oasis.Usage        `json:"usage"`
+	Steps        []oasis.StepTrace  `json:"steps,omitempty"`
+	FinishReason oasis.FinishReason `json:"finish_reason,omitempty"`
+}
+
+// AgentTool returns a ToolHandler that runs a as a single MCP tool. The
+// tool is named after the agent, takes {"input": string}, and returns
+// AgentResult.Output as text. Token usage, step traces and the finish reason
+// are attached as structured metadata under the result's _meta field. Agent
+// errors come back as error results rather than JSON-RPC errors, so the
+// calling model sees them.
+func AgentTool(a oasis.Agent) ToolHandler {
+	return ToolHandler{
+		Definition: ToolDefinition{
+			Name:        toolName(a.Name()),
+			Description: a.Description(),
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"input": map[string]any{
+						"type":        "string",
+						"description": "The task or message for the agent",
+					},
+				},
+				"required": []string{"input"},
+			},
+		},
+		Execute: func(ctx context.Context, args json.RawMessage) ToolCallResult {
+			var params struct {
+				Input string `json:"input"`
+			}
+			if err := json.Unmarshal(args, &params); err != nil {
+				return ErrorResult("invalid args: " + err.Error())
+			}
+			if params.Input == "" {
+				return ErrorResult("input is required")
+			}
+			res, err := a.Execute(ctx, oasis.AgentTask{Input: params.Input})
+			if err != nil {
+				return ErrorResult(err.Error())
+			}
+			result := TextResult(res.Output)
+			meta, err := json.Marshal(agentToolMeta{
+				Usage:        res.Usage,
+				Steps:        res.Steps,
+				FinishReason: res.FinishReason,
+			})
+			if err == nil {
+				result.Meta = meta
+			}
+			return result
+		},
+	}
+}
+
+// ServeAgent exposes a as an MCP stdio server with a single tool (see
+// AgentTool) and blocks until stdin closes or ctx is cancelled. It is a
+// shortcut for New + AddTool(AgentTool(a)) + Serve, so an agent binary can
+// be registered directly in any MCP-aware client.
+func ServeAgent(ctx context.Context, a oasis.Agent, name, version string, opts ...ServerOption) error {
+	s := New(name, version, opts...)
+	s.AddTool(AgentTool(a))
+	return s.Serve(ctx)
+}
+
+// toolName maps an agent name onto the MCP tool name charset
+// ([A-Za-z0-9_-]); anything else becomes an underscore.
+func toolName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		return "agent"
+	}
+	return name
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+// stubAgent returns a fixed result or error.
+type stubAgent struct {
+	name string
+	res  oasis.AgentResult
+	err  error
+	got  string
+}
+
+func (a *stubAgent) Name() string        { return a.name }
+func (a *stubAgent) Description() string { return "a stub agent" }
+func (a *stubAgent) Execute(_ context.Context, task oasis.AgentTask, _ ...oasis.RunOption) (oasis.AgentResult, error) {
+	a.got = task.Input
+	return a.res, a.err
+}
+
+func TestAgentToolCall(t *testing.T) {
+	a := &stubAgent{name: "research agent", res: oasis.AgentResult{
+		Output: "the answer",
+		Usage:  oasis.Usage{InputTokens: 10, OutputTokens: 5},
+		Steps:  []oasis.StepTrace{{Name: "search", Type: oasis.StepTypeTool}},
+	}}
+	srv, out := testServer()
+	srv.AddTool(AgentTool(a))
+
+	resp := sendAndReceive(t, srv, out,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"research_agent","arguments":{"input":"question"}}}`)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+
+	raw, _ := json.Marshal(resp.Result)
+	var result ToolCallResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError || len(result.Content) != 1 || result.Content[0].Text != "the answer" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if a.got != "question" {
+		t.Errorf("agent input = %q, want %q", a.got, "question")
+	}
+
+	var meta agentToolMeta
+	if err := json.Unmarshal(result.Meta, &meta); err != nil {
+		t.Fatalf("unmarshal _meta: %v (raw: %s)", err, result.Meta)
+	}
+	if meta.Usage.InputTokens != 10 || meta.Usage.OutputTokens != 5 {
+		t.Errorf("usage = %+v", meta.Usage)
+	}
+	if len(meta.Steps) != 1 || meta.Steps[0].Name != "search" {
+		t.Errorf("steps = %+v", meta.Steps)
+	}
+}
+
+func TestAgentToolError(t *testing.T) {
+	h := AgentTool(&stubAgent{name: "a", err: errors.New("boom")})
+
+	result := h.Execute(context.Background(), json.RawMessage(`{"input":"x"}`))
+	if !result.IsError || result.Content[0].Text != "boom" {
+		t.Errorf("agent error result = %+v", result)
+	}
+	if result := h.Execute(context.Background(), json.RawMessage(`{}`)); !result.IsError {
+		t.Error("missing input: expected isError=true")
+	}
+}
+
+func TestAgentToolDefinition(t *testing.T) {
+	h := AgentTool(&stubAgent{name: "my agent/v2"})
+	if h.Definition.Name != "my_agent_v2" {
+		t.Errorf("Name = %q", h.Definition.Name)
+	}
+	if h.Definition.Description != "a stub agent" {
+		t.Errorf("Description = %q", h.Definition.Description)
+	}
+	schema, _ := json.Marshal(h.Definition.InputSchema)
+	if string(schema) != `{"properties":{"input":{"description":"The task or message for the agent","type":"string"}},"required":["input"],"type":"object"}` {
+		t.Errorf("InputSchema = %s", schema)
+	}
+}
@@ -111,6 +111,10 @@ type toolCallParams struct {
 type ToolCallResult struct {
 	Content []textContent `json:"content"`
 	IsError bool          `json:"isError,omitempty"`
+	// Meta is optional structured metadata returned alongside the content
+	// (the MCP "_meta" field). Clients pass it through without showing it
+	// to the model.
+	Meta json.RawMessage `json:"_meta,omitempty"`
 }
 
 // textContent is a text content block in MCP responses.
@@ -0,0 +1,140 @@
+// memory/episode_test.go
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+func addMessages(t *testing.T, store historyStore, threadID string, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		must(t, store.StoreMessage(context.Background(), core.Message{
+			ID: fmt.Sprintf("m%02d", i), ThreadID: threadID, Role: core.RoleUser, Content: fmt.Sprintf("message %d", i),
+		}))
+	}
+}
+
+func TestEpisodeSummarizer_FoldsIncrementally(t *testing.T) {
+	ctx := context.Background()
+	store := historyStore{newConformanceStore(t)}
+	provider := &fakeProvider{response: "S"}
+	in := &IngestContext{
+		Task:      core.AgentTask{ThreadID: "t1"},
+		Store:     store,
+		ItemStore: store,
+		Logger:    discardLogger(),
+	}
+	proc := EpisodeSummarizer{Provider: provider, Every: 10, Keep: 10}
+
+	// 30 messages, 10 kept recent: two blocks fold on the first run.
+	addMessages(t, store, "t1", 0, 30)
+	must(t, proc.Process(ctx, in))
+	ep, err := store.Get(ctx, EpisodeID("t1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 2 || ep.Content != "S" || ep.Tags[0] != "through:m19" || ep.Scope != Scoped(ScopeThread, "t1") {
+		t.Fatalf("calls = %d, episode = %+v; want 2 calls through m19", provider.calls, ep)
+	}
+
+	// Less than a block beyond the window: nothing to do.
+	addMessages(t, store, "t1", 30, 35)
+	must(t, proc.Process(ctx, in))
+	if provider.calls != 2 {
+		t.Fatalf("calls = %d, want no fold below a full block", provider.calls)
+	}
+
+	// The next block is folded on top of the previous summary.
+	addMessages(t, store, "t1", 35, 40)
+	provider.response = "S2"
+	must(t, proc.Process(ctx, in))
+	prompt := provider.req.Messages[1].Content
+	if provider.calls != 3 || !strings.Contains(prompt, "Current summary:\nS\n") ||
+		!strings.Contains(prompt, "message 20\n") || strings.Contains(prompt, "message 19\n") || strings.Contains(prompt, "message 30\n") {
+		t.Fatalf("calls = %d, prompt:\n%s\nwant previous summary plus messages 20-29", provider.calls, prompt)
+	}
+	if ep, _ = store.Get(ctx, EpisodeID("t1")); ep.Content != "S2" || ep.Tags[0] != "through:m29" {
+		t.Errorf("episode = %+v, want S2 through m29", ep)
+	}
+}
+
+func TestBuildMessages_InjectsEpisodeAheadOfHistory(t *testing.T) {
+	ctx := context.Background()
+	store := historyStore{newConformanceStore(t)}
+	addMessages(t, store, "t1", 0, 3)
+	must(t, store.Upsert(ctx, core.MemoryItem{
+		ID: EpisodeID("t1"), Kind: KindSummary, Content: "The user planned a trip.", Scope: Scoped(ScopeThread, "t1"),
+	}))
+
+	m := &AgentMemory{}
+	m.Init(BuildConfig(WithStore(store), WithEpisodicSummary(nil, 10), WithLogger(discardLogger())))
+	out := m.BuildMessages(ctx, "a", "sys", core.AgentTask{ThreadID: "t1", Input: "hi"})
+	if len(out) != 5 {
+		t.Fatalf("messages = %d, want system + 3 history + input", len(out))
+	}
+	if out[0].Role != core.RoleSystem || !strings.Contains(out[0].Content, "sys") || !strings.Contains(out[0].Content, "The user planned a trip.") {
+		t.Errorf("system message = %q, want the prompt and the episode", out[0].Content)
+	}
+	if out[1].Content != "message 0" {
+		t.Errorf("first history message = %q", out[1].Content)
+	}
+}
+
+// recordingCompactor returns a fixed summary and records each request.
+type recordingCompactor struct {
+	summary string
+	reqs    []core.CompactRequest
+}
+
+func (c *recordingCompactor) Compact(_ context.Context, req core.CompactRequest) (core.CompactResult, error) {
+	c.reqs = append(c.reqs, req)
+	return core.CompactResult{SummaryText: c.summary}, nil
+}
+
+func TestPersistCompression_FoldsWithCompactor(t *testing.T) {
+	ctx := context.Background()
+	store := historyStore{newConformanceStore(t)}
+	compactor := &recordingCompactor{summary: "C1"}
+	m := &AgentMemory{}
+	m.Init(BuildConfig(WithStore(store), WithHistory(HistoryConfig{MaxMessages: 10}),
+		WithCompaction(compactor, 0.8), PersistCompression(), WithLogger(discardLogger())))
+
+	var es EpisodeSummarizer
+	for _, p := range m.asyncIngestChain() {
+		if s, ok := p.(EpisodeSummarizer); ok {
+			es = s
+		}
+	}
+	if es.Compactor != compactor {
+		t.Fatalf("async chain has no EpisodeSummarizer using the compactor: %+v", es)
+	}
+	in := &IngestContext{Task: core.AgentTask{ThreadID: "t1"}, Store: store, ItemStore: store, Logger: discardLogger()}
+
+	addMessages(t, store, "t1", 0, 30)
+	must(t, es.Process(ctx, in))
+	if len(compactor.reqs) != 1 || compactor.reqs[0].IsRecompact || len(compactor.reqs[0].Messages) != 20 {
+		t.Fatalf("first fold requests = %+v, want one fresh 20-message compaction", compactor.reqs)
+	}
+
+	// New messages extend the stored summary instead of starting over.
+	addMessages(t, store, "t1", 30, 50)
+	compactor.summary = "C2"
+	must(t, es.Process(ctx, in))
+	req := compactor.reqs[1]
+	if !req.IsRecompact || !strings.Contains(req.Messages[0].Content, "C1") || req.Messages[1].Content != "message 20" {
+		t.Fatalf("second fold = %+v, want previous summary then messages from 20", req)
+	}
+
+	// A fresh AgentMemory (a restart) loads the stored summary.
+	m2 := &AgentMemory{}
+	m2.Init(BuildConfig(WithStore(store), WithCompaction(compactor, 0.8), PersistCompression(), WithLogger(discardLogger())))
+	out := m2.BuildMessages(ctx, "a", "sys", core.AgentTask{ThreadID: "t1", Input: "hi"})
+	if !strings.Contains(out[0].Content, "C2") {
+		t.Errorf("system message = %q, want the persisted summary", out[0].Content)
+	}
+}
@@ -0,0 +1,212 @@
+// memory/export.go
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// maxExportRows is the per-query limit for export and purge. The store
+// interfaces page only by limit, so these queries ask for everything.
+const maxExportRows = 1<<31 - 1
+
+// UserDataOption configures ExportUser and PurgeUser.
+type UserDataOption func(*userDataCfg)
+
+type userDataCfg struct {
+	chats      []string
+	embeddings bool
+}
+
+// UserChats adds chats that belong to the user alone — typically their
+// direct-message chats. Their threads, messages and chat-scoped items are
+// exported and purged along with the user's own partition. Do not pass
+// shared group chats: everything in them would be attributed to the user.
+func UserChats(chatIDs ...string) UserDataOption {
+	return func(c *userDataCfg) { c.chats = append(c.chats, chatIDs...) }
+}
+
+// ExportEmbeddings keeps MemoryItem.Embedding in the export. Embeddings are
+// omitted by default because they dwarf the text they were computed from.
+func ExportEmbeddings() UserDataOption {
+	return func(c *userDataCfg) { c.embeddings = true }
+}
+
+// UserExport is a machine-readable archive of what memory holds about one
+// user, suitable for a data-portability request.
+type UserExport struct {
+	UserID     string            `json:"user_id"`
+	ExportedAt int64             `json:"exported_at"`
+	Threads    []ExportedThread  `json:"threads"`
+	Items      []core.MemoryItem `json:"items"`
+}
+
+// ExportedThread is a thread with its full message history, oldest first.
+type ExportedThread struct {
+	core.Thread
+	Messages []core.Message `json:"messages"`
+}
+
+// UserPurge reports what PurgeUser deleted.
+type UserPurge struct {
+	Threads int // threads deleted, each with its messages
+	Items   int // memory items deleted
+}
+
+// ExportUser collects everything memory holds about userID: the items in
+// the user's partition (UserScope), including expired ones, and — for each
+// chat passed via UserChats — the chat's threads, their messages and the
+// items scoped to the chat or to one of its threads.
+func (m *AgentMemory) ExportUser(ctx context.Context, userID string, opts ...UserDataOption) (UserExport, error) {
+	cfg, err := m.userDataConfig(userID, opts)
+	if err != nil {
+		return UserExport{}, err
+	}
+	out := UserExport{UserID: userID, ExportedAt: core.NowUnix(), Threads: []ExportedThread{}, Items: []core.MemoryItem{}}
+
+	for _, chatID := range cfg.chats {
+		threads, err := m.store.ListThreads(ctx, chatID, maxExportRows)
+		if err != nil {
+			return UserExport{}, fmt.Errorf("memory: export chat %s: %w", chatID, err)
+		}
+		for _, t := range threads {
+			msgs, err := m.store.GetMessages(ctx, t.ID, maxExportRows)
+			if err != nil {
+				return UserExport{}, fmt.Errorf("memory: export thread %s: %w", t.ID, err)
+			}
+			if msgs == nil {
+				msgs = []core.Message{}
+			}
+			out.Threads = append(out.Threads, ExportedThread{Thread: t, Messages: msgs})
+		}
+	}
+
+	scopes, err := m.userScopes(ctx, userID, cfg)
+	if err != nil {
+		return UserExport{}, err
+	}
+	for _, sc := range scopes {
+		items, err := m.itemStore.List(ctx, core.MemoryFilter{Scope: &sc, IncludeExp: true, Limit: maxExportRows})
+		if err != nil {
+			return UserExport{}, fmt.Errorf("memory: export items in %s:%s: %w", sc.Kind, sc.Ref, err)
+		}
+		for _, it := range items {
+			if !cfg.embeddings {
+				it.Embedding = nil
+			}
+			out.Items = append(out.Items, it)
+		}
+	}
+	return out, nil
+}
+
+// PurgeUser deletes everything ExportUser would return: the user's memory
+// items and, for each chat passed via UserChats, its threads (with their
+// messages) and chat-scoped items. Deletion stops at the first store error;
+// the returned counts cover what was deleted before it, and calling
+// PurgeUser again resumes the purge.
+func (m *AgentMemory) PurgeUser(ctx context.Context, userID string, opts ...UserDataOption) (UserPurge, error) {
+	cfg, err := m.userDataConfig(userID, opts)
+	if err != nil {
+		return UserPurge{}, err
+	}
+	var res UserPurge
+	// Scopes are collected before any thread is deleted: thread-scoped items
+	// are found through the chats' thread lists.
+	scopes, err := m.userScopes(ctx, userID, cfg)
+	if err != nil {
+		return res, err
+	}
+	for _, sc := range scopes {
+		n, err := m.itemStore.DeleteWhere(ctx, core.MemoryFilter{Scope: &sc, IncludeExp: true})
+		res.Items += n
+		if err != nil {
+			return res, fmt.Errorf("memory: purge items in %s:%s: %w", sc.Kind, sc.Ref, err)
+		}
+	}
+	for _, chatID := range cfg.chats {
+		threads, err := m.store.ListThreads(ctx, chatID, maxExportRows)
+		if err != nil {
+			return res, fmt.Errorf("memory: purge chat %s: %w", chatID, err)
+		}
+		for _, t := range threads {
+			if err := m.store.DeleteThread(ctx, t.ID); err != nil {
+				return res, fmt.Errorf("memory: purge thread %s: %w", t.ID, err)
+			}
+			res.Threads++
+		}
+	}
+	m.logger.Info("purged user memory", "user_id", userID, "threads", res.Threads, "items", res.Items)
+	return res, nil
+}
+
+// MoveChatItems re-scopes the items in chatID's partition to userID's
+// (UserScope), for deployments that start setting AgentTask.UserID and
+// want the user to keep recalling what was learned in their chat. It
+// returns the number of items moved. Only use it for chats that belong to
+// the user alone: items from a shared chat cannot be attributed to one user.
+func (m *AgentMemory) MoveChatItems(ctx context.Context, chatID, userID string) (int, error) {
+	if _, err := m.userDataConfig(userID, nil); err != nil {
+		return 0, err
+	}
+	if chatID == "" {
+		return 0, errors.New("memory: chat ID is required")
+	}
+	sc := Scoped(ScopeResource, chatID)
+	items, err := m.itemStore.List(ctx, core.MemoryFilter{Scope: &sc, IncludeExp: true, Limit: maxExportRows})
+	if err != nil {
+		return 0, fmt.Errorf("memory: list items in chat %s: %w", chatID, err)
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+	for i := range items {
+		items[i].Scope = UserScope(userID)
+	}
+	// Same IDs, so the upsert rewrites each item in place.
+	if err := m.itemStore.UpsertBatch(ctx, items); err != nil {
+		return 0, fmt.Errorf("memory: move items of chat %s: %w", chatID, err)
+	}
+	m.logger.Info("moved chat memory to user", "chat_id", chatID, "user_id", userID, "items", len(items))
+	return len(items), nil
+}
+
+// userDataConfig validates the shared preconditions of ExportUser and
+// PurgeUser and applies opts.
+func (m *AgentMemory) userDataConfig(userID string, opts []UserDataOption) (userDataCfg, error) {
+	var cfg userDataCfg
+	if m.store == nil {
+		return cfg, errors.New("memory: no store configured")
+	}
+	if m.itemStore == nil {
+		return cfg, errors.New("memory: this operation requires a store implementing core.MemoryItemStore")
+	}
+	if userID == "" {
+		return cfg, errors.New("memory: user ID is required")
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return cfg, nil
+}
+
+// userScopes lists the item partitions attributed to the user: their own,
+// and for each of their chats the chat's and its threads' (episode
+// summaries, thread-scoped working memory).
+func (m *AgentMemory) userScopes(ctx context.Context, userID string, cfg userDataCfg) ([]core.MemoryScope, error) {
+	scopes := []core.MemoryScope{UserScope(userID)}
+	for _, chatID := range cfg.chats {
+		scopes = append(scopes, Scoped(ScopeResource, chatID))
+		threads, err := m.store.ListThreads(ctx, chatID, maxExportRows)
+		if err != nil {
+			return nil, fmt.Errorf("memory: list threads of chat %s: %w", chatID, err)
+		}
+		for _, t := range threads {
+			scopes = append(scopes, Scoped(ScopeThread, t.ID))
+		}
+	}
+	return scopes, nil
+}
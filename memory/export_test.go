@@ -0,0 +1,141 @@
+// memory/export_test.go
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// historyStore is a testStore whose thread and message reads and deletes
+// are backed by its maps.
+type historyStore struct{ *testStore }
+
+func (s historyStore) ListThreads(_ context.Context, chatID string, _ int) ([]core.Thread, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []core.Thread
+	for _, t := range s.threads {
+		if t.ChatID == chatID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// GetMessages returns the newest limit messages, oldest first.
+func (s historyStore) GetMessages(_ context.Context, threadID string, limit int) ([]core.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msgs := s.messages[threadID]
+	return msgs[max(len(msgs)-limit, 0):], nil
+}
+
+func (s historyStore) DeleteThread(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.threads, id)
+	delete(s.messages, id)
+	return nil
+}
+
+func seedUserData(t *testing.T) (historyStore, *AgentMemory) {
+	t.Helper()
+	ctx := context.Background()
+	store := historyStore{newConformanceStore(t)}
+	must(t, store.CreateThread(ctx, core.Thread{ID: "t-alice", ChatID: "dm-alice"}))
+	must(t, store.StoreMessage(ctx, core.Message{ID: "m1", ThreadID: "t-alice", Role: core.RoleUser, Content: "I live in Berlin"}))
+	must(t, store.CreateThread(ctx, core.Thread{ID: "t-bob", ChatID: "dm-bob"}))
+	must(t, store.StoreMessage(ctx, core.Message{ID: "m2", ThreadID: "t-bob", Role: core.RoleUser, Content: "hi"}))
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "f-alice", Kind: KindFact, Content: "Lives in Berlin", Scope: UserScope("alice"), Embedding: []float32{1, 0}}))
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "n-alice", Kind: KindNote, Content: "draft", Scope: Scoped(ScopeResource, "dm-alice")}))
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "f-bob", Kind: KindFact, Content: "Likes tea", Scope: UserScope("bob")}))
+
+	m := &AgentMemory{}
+	m.Init(AgentMemoryConfig{Store: store, Logger: discardLogger()})
+	return store, m
+}
+
+func TestAgentMemory_ExportUser(t *testing.T) {
+	_, m := seedUserData(t)
+	exp, err := m.ExportUser(context.Background(), "alice", UserChats("dm-alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp.Threads) != 1 || exp.Threads[0].ID != "t-alice" || len(exp.Threads[0].Messages) != 1 {
+		t.Fatalf("threads = %+v, want t-alice with one message", exp.Threads)
+	}
+	ids := map[string]bool{}
+	for _, it := range exp.Items {
+		ids[it.ID] = true
+		if it.Embedding != nil {
+			t.Errorf("item %s kept its embedding without ExportEmbeddings", it.ID)
+		}
+	}
+	if len(ids) != 2 || !ids["f-alice"] || !ids["n-alice"] {
+		t.Fatalf("items = %v, want f-alice and n-alice", ids)
+	}
+	if _, err := json.Marshal(exp); err != nil {
+		t.Fatalf("export is not JSON-serializable: %v", err)
+	}
+
+	withEmb, err := m.ExportUser(context.Background(), "alice", ExportEmbeddings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(withEmb.Items) != 1 || withEmb.Items[0].Embedding == nil {
+		t.Fatalf("items = %+v, want f-alice with its embedding", withEmb.Items)
+	}
+}
+
+func TestAgentMemory_PurgeUser(t *testing.T) {
+	store, m := seedUserData(t)
+	ctx := context.Background()
+	res, err := m.PurgeUser(ctx, "alice", UserChats("dm-alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Threads != 1 || res.Items != 2 {
+		t.Fatalf("purge = %+v, want 1 thread and 2 items", res)
+	}
+	if _, err := store.GetThread(ctx, "t-alice"); err == nil {
+		t.Error("alice's thread survived the purge")
+	}
+	left, _ := store.List(ctx, core.MemoryFilter{IncludeExp: true})
+	if len(left) != 1 || left[0].ID != "f-bob" {
+		t.Errorf("remaining items = %+v, want only bob's fact", left)
+	}
+	if _, err := store.GetThread(ctx, "t-bob"); err != nil {
+		t.Error("bob's thread was purged")
+	}
+
+	if _, err := m.PurgeUser(ctx, ""); err == nil {
+		t.Error("PurgeUser with an empty user ID: want error")
+	}
+}
+
+func TestAgentMemory_MoveChatItems(t *testing.T) {
+	store, m := seedUserData(t)
+	ctx := context.Background()
+	n, err := m.MoveChatItems(ctx, "dm-alice", "alice")
+	if err != nil || n != 1 {
+		t.Fatalf("moved %d items, err = %v; want 1", n, err)
+	}
+	it, err := store.Get(ctx, "n-alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Scope != UserScope("alice") || it.Content != "draft" {
+		t.Errorf("moved item = %+v, want alice's partition with content kept", it)
+	}
+	chat := Scoped(ScopeResource, "dm-alice")
+	if left, _ := store.List(ctx, core.MemoryFilter{Scope: &chat}); len(left) != 0 {
+		t.Errorf("chat partition still holds %d items", len(left))
+	}
+
+	if _, err := m.MoveChatItems(ctx, "", "alice"); err == nil {
+		t.Error("MoveChatItems with an empty chat ID: want error")
+	}
+}
@@ -4,6 +4,7 @@ package memory
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/nevindra/oasis/core"
@@ -113,10 +114,14 @@ func TestUpserter_WritesAllCandidates(t *testing.T) {
 type fakeProvider struct {
 	response string
 	called   bool
+	calls    int
+	req      core.ChatRequest // last request
 }
 
-func (f *fakeProvider) ChatStream(_ context.Context, _ core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+func (f *fakeProvider) ChatStream(_ context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
 	f.called = true
+	f.calls++
+	f.req = req
 	if ch != nil {
 		close(ch)
 	}
@@ -157,6 +162,150 @@ func TestFactExtractor_EmitsCandidatesWithProvenance(t *testing.T) {
 	}
 }
 
+func TestFactExtractor_ScopesByUserID(t *testing.T) {
+	provider := &fakeProvider{
+		response: `[{"fact": "User's name is Nev", "category": "personal"}]`,
+	}
+	in := &IngestContext{
+		Task:     core.AgentTask{ThreadID: "t1", ChatID: "group", UserID: "u42"},
+		UserText: "Hi, I'm Nev.",
+		Provider: provider,
+		Logger:   discardLogger(),
+	}
+	if err := (FactExtractor{}).Process(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+	if len(in.Candidates) != 1 {
+		t.Fatalf("candidates = %d", len(in.Candidates))
+	}
+	if got := in.Candidates[0].Scope; got != UserScope("u42") {
+		t.Errorf("scope = %+v, want the user's partition", got)
+	}
+}
+
+func TestFactExtractor_Categories(t *testing.T) {
+	const response = `[{"fact": "User is allergic to penicillin", "category": "medical"},
+		{"fact": "User works at Acme", "category": "work"}]`
+	run := func(f FactExtractor) (*fakeProvider, []core.MemoryItem) {
+		provider := &fakeProvider{response: response}
+		in := &IngestContext{
+			Task:     core.AgentTask{ThreadID: "t1"},
+			UserText: "I'm allergic to penicillin and I work at Acme.",
+			Provider: provider,
+			Logger:   discardLogger(),
+		}
+		if err := f.Process(context.Background(), in); err != nil {
+			t.Fatal(err)
+		}
+		return provider, in.Candidates
+	}
+
+	// Default set: unchanged prompt, unknown categories dropped.
+	provider, got := run(FactExtractor{})
+	if len(got) != 1 || got[0].Tags[0] != "category:work" {
+		t.Fatalf("default candidates = %+v, want only the work fact", got)
+	}
+	if !strings.Contains(provider.req.Messages[0].Content, "Categorize each fact as: personal, preference, work, habit, or relationship\n") {
+		t.Error("default prompt does not list the default categories")
+	}
+
+	provider, got = run(FactExtractor{Categories: []string{"medical", "project"}})
+	if len(got) != 1 || got[0].Tags[0] != "category:medical" {
+		t.Fatalf("custom candidates = %+v, want only the medical fact", got)
+	}
+	if !strings.Contains(provider.req.Messages[0].Content, "Categorize each fact as: medical or project\n") {
+		t.Errorf("prompt does not list the configured categories:\n%s", provider.req.Messages[0].Content)
+	}
+}
+
+func TestFactExtractor_RecordsConfidence(t *testing.T) {
+	provider := &fakeProvider{
+		response: `[{"fact": "User lives in Berlin", "category": "personal"},
+			{"fact": "User might move to Lisbon", "category": "personal", "confidence": 0.4},
+			{"fact": "User is a pilot", "category": "work", "confidence": 1.7}]`,
+	}
+	in := &IngestContext{
+		Task:     core.AgentTask{ThreadID: "t1"},
+		UserText: "I live in Berlin, but I think I might move to Lisbon.",
+		Provider: provider,
+		Logger:   discardLogger(),
+	}
+	if err := (FactExtractor{}).Process(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+	if len(in.Candidates) != 2 {
+		t.Fatalf("candidates = %d, want 2 (out-of-range confidence dropped)", len(in.Candidates))
+	}
+	if got := Confidence(in.Candidates[0]); got != 1 {
+		t.Errorf("confidence without a score = %v, want 1", got)
+	}
+	if got := Confidence(in.Candidates[1]); got != 0.4 {
+		t.Errorf("hedged confidence = %v, want 0.4", got)
+	}
+}
+
+func TestFactExtractor_TimeBoundFactsExpire(t *testing.T) {
+	provider := &fakeProvider{
+		response: `[{"fact": "User is training for a marathon", "category": "habit", "expires_in_days": 90},
+			{"fact": "User is on vacation", "category": "personal", "expires_in_days": 0}]`,
+	}
+	in := &IngestContext{
+		Task:     core.AgentTask{ThreadID: "t1"},
+		UserText: "I'm currently training for a marathon.",
+		Provider: provider,
+		Logger:   discardLogger(),
+	}
+	if err := (FactExtractor{}).Process(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+	if len(in.Candidates) != 1 {
+		t.Fatalf("candidates = %d, want 1 (non-positive expiry dropped)", len(in.Candidates))
+	}
+	c := in.Candidates[0]
+	if want := c.CreatedAt + 90*24*3600; c.ExpiresAt != want {
+		t.Errorf("ExpiresAt = %d, want %d", c.ExpiresAt, want)
+	}
+}
+
+func TestDecayProbabilistic_DeletesExpiredFacts(t *testing.T) {
+	store := newConformanceStore(t)
+	ctx := context.Background()
+	now := core.NowUnix()
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "expired", Kind: KindFact, ExpiresAt: now - 1}))
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "fresh", Kind: KindFact, ExpiresAt: now + 3600}))
+
+	in := &IngestContext{ItemStore: store, Logger: discardLogger()}
+	if err := (DecayProbabilistic{Probability: 1}).Process(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := store.List(ctx, core.MemoryFilter{IncludeExp: true})
+	if len(got) != 1 || got[0].ID != "fresh" {
+		t.Fatalf("remaining = %+v, want only fresh", got)
+	}
+}
+
+// filterOnlyStore hides the store's MemoryExpirer capability, like a custom
+// store written without it.
+type filterOnlyStore struct{ core.MemoryItemStore }
+
+func TestDecayProbabilistic_SkipsExpiryWithoutCapability(t *testing.T) {
+	inner := newConformanceStore(t)
+	store := filterOnlyStore{inner}
+	ctx := context.Background()
+	now := core.NowUnix()
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "expired", Kind: KindFact, ExpiresAt: now - 1}))
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "fresh", Kind: KindFact, CreatedAt: now}))
+
+	in := &IngestContext{ItemStore: store, Logger: discardLogger()}
+	if err := (DecayProbabilistic{Probability: 1}).Process(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := inner.List(ctx, core.MemoryFilter{IncludeExp: true})
+	if len(got) != 2 {
+		t.Fatalf("remaining = %+v, want both items kept", got)
+	}
+}
+
 func TestFactExtractor_SkipsTrivial(t *testing.T) {
 	provider := &fakeProvider{}
 	in := &IngestContext{UserText: "ok", Provider: provider, Logger: discardLogger()}
@@ -198,6 +347,37 @@ func TestDeduper_SkipsWhenNoSupersedes(t *testing.T) {
 	// Test passes if panicEmbedder.Embed was never called.
 }
 
+func TestFactDedup_MergesNearDuplicates(t *testing.T) {
+	ctx := context.Background()
+	store := newConformanceStore(t)
+	defer store.Close()
+	sc := Scoped(ScopeResource, "c1")
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "old", Kind: KindFact, Content: "Likes hiking", Scope: sc, Pinned: true, Embedding: []float32{1, 0, 0}}))
+	must(t, store.Upsert(ctx, core.MemoryItem{ID: "other", Kind: KindFact, Content: "Likes hiking", Scope: Scoped(ScopeResource, "c2"), Embedding: []float32{1, 0, 0}}))
+
+	in := &IngestContext{
+		Candidates: []core.MemoryItem{
+			{ID: "n1", Kind: KindFact, Content: "Enjoys hiking", Scope: sc, Embedding: []float32{0.99, 0.1, 0}},
+			{ID: "n2", Kind: KindFact, Content: "Really enjoys hiking", Scope: sc, Embedding: []float32{0.98, 0.12, 0}},
+			{ID: "n3", Kind: KindFact, Content: "Works at Acme", Scope: sc, Embedding: []float32{0, 0, 1}},
+		},
+		ItemStore: store,
+		Logger:    discardLogger(),
+	}
+	if err := (FactDedup{}).Process(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if len(in.Candidates) != 2 {
+		t.Fatalf("candidates = %+v, want the in-turn duplicate dropped", in.Candidates)
+	}
+	if c := in.Candidates[0]; c.ID != "old" || !c.Pinned || c.Content != "Enjoys hiking" {
+		t.Errorf("merged candidate = %+v, want newer wording under the old ID, still pinned", c)
+	}
+	if c := in.Candidates[1]; c.ID != "n3" {
+		t.Errorf("unrelated candidate ID = %q, want n3", c.ID)
+	}
+}
+
 // --- TitleGenerator tests ---
 
 func TestTitleGenerator_SkipsWhenThreadNotCreated(t *testing.T) {
@@ -1,7 +1,14 @@
 // memory/item.go
 package memory
 
-import "github.com/nevindra/oasis/core"
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nevindra/oasis/core"
+)
 
 // Memory kind constants. These are convenience aliases for the canonical
 // core.MemoryKind values; use core.MemoryKind as the type in new code.
@@ -27,3 +34,62 @@ const (
 func Scoped(k core.MemoryScopeKind, ref string) core.MemoryScope {
 	return core.MemoryScope{Kind: k, Ref: ref}
 }
+
+// userScopePrefix keeps user partitions apart from chat-ID partitions that
+// happen to share the same string.
+const userScopePrefix = "user:"
+
+// UserScope returns the ScopeResource partition holding one end user's
+// items — the scope the ingest and retrieve pipelines use when
+// AgentTask.UserID is set. Pass it to Remember, Recall or List to read and
+// write the same partition outside a turn.
+func UserScope(userID string) core.MemoryScope {
+	return Scoped(ScopeResource, userScopePrefix+userID)
+}
+
+// confidenceTagPrefix marks the tag carrying an item's confidence. Like the
+// "category:" and "supersedes:" tags written by FactExtractor, it keeps the
+// score in Tags so every MemoryItemStore persists it without a schema change.
+const confidenceTagPrefix = "confidence:"
+
+// ConfidenceTag returns the tag recording confidence c, clamped to [0, 1].
+// Add it to MemoryItem.Tags when saving an item the agent is unsure of.
+func ConfidenceTag(c float32) string {
+	c = min(max(c, 0), 1)
+	return confidenceTagPrefix + strconv.FormatFloat(float64(c), 'f', 2, 32)
+}
+
+// Confidence returns the confidence recorded in item's tags, or 1 when the
+// item has none (or an unparsable one) — items saved without a confidence
+// are treated as certain.
+func Confidence(item core.MemoryItem) float32 {
+	for _, t := range item.Tags {
+		rest, ok := strings.CutPrefix(t, confidenceTagPrefix)
+		if !ok {
+			continue
+		}
+		c, err := strconv.ParseFloat(rest, 32)
+		if err != nil || c < 0 || c > 1 {
+			return 1
+		}
+		return float32(c)
+	}
+	return 1
+}
+
+// weightByConfidence scales each result's Score by the item's Confidence,
+// re-sorts by the weighted score (ties keep store order) and keeps at most
+// topK. Callers over-fetch so a confident, slightly less similar item can
+// displace a hesitant one.
+func weightByConfidence(results []core.ScoredMemoryItem, topK int) []core.ScoredMemoryItem {
+	for i := range results {
+		results[i].Score *= Confidence(results[i].Item)
+	}
+	slices.SortStableFunc(results, func(a, b core.ScoredMemoryItem) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
@@ -44,22 +44,31 @@ type AgentMemory struct {
 	protectedTools       []string
 
 	// Recall knobs
-	semanticRecall   bool
-	semanticMinScore float32
-	recallKinds      []core.MemoryKind
-	recallTopK       int
+	semanticRecall    bool
+	semanticMinScore  float32
+	semanticDedup     float32
+	semanticMaxTokens int
+	recallKinds       []core.MemoryKind
+	recallTopK        int
 
 	// Working memory
 	workingMemory      bool
 	workingMemoryScope core.MemoryScopeKind
 
 	// Lifecycle
-	autoTitle bool
+	autoTitle      bool
+	factCategories []string
+	factDedup      float32
+
+	// Episodic summary
+	episodeProvider core.Provider
+	episodeEvery    int
 
 	// Compaction (history-shrink). Trigger lives in the agent loop; these
 	// fields are mirrored here so processors / callers can introspect them.
-	compactor        core.Compactor
-	compactThreshold float64
+	compactor          core.Compactor
+	compactThreshold   float64
+	persistCompression bool
 
 	// Per-turn compression (in-memory summarization).
 	compressModel     core.ModelFunc
@@ -116,20 +125,42 @@ type AgentMemoryConfig struct {
 
 	SemanticRecall   bool
 	SemanticMinScore float32
-	RecallKinds      []core.MemoryKind
-	RecallTopK       int
+	// SemanticDedup / SemanticMaxTokens tune cross-thread recall; see
+	// WithSemanticRecallDedup and WithSemanticRecallBudget.
+	SemanticDedup     float32
+	SemanticMaxTokens int
+	RecallKinds       []core.MemoryKind
+	RecallTopK        int
 
 	WorkingMemory      bool
 	WorkingMemoryScope core.MemoryScopeKind
 
 	AutoTitle bool
 
+	// FactCategories overrides the categories FactExtractor may assign.
+	// Empty keeps the default set.
+	FactCategories []string
+
+	// FactDedupThreshold enables FactDedup at this similarity; 0 = off.
+	FactDedupThreshold float32
+
+	// EpisodeProvider / EpisodeEvery enable episodic summaries: every
+	// EpisodeEvery messages that age out of the history window are folded
+	// into a per-thread summary. See WithEpisodicSummary.
+	EpisodeProvider core.Provider
+	EpisodeEvery    int
+
 	// Compaction: when stored history exceeds CompactThreshold × window,
 	// the trigger (in the agent loop) calls Compactor.Compact. The trigger
 	// stays framework-level; policy lives in the Compactor implementation.
 	Compactor        core.Compactor
 	CompactThreshold float64
 
+	// PersistCompression folds history that ages out of the window into a
+	// durable per-thread summary with Compactor, so later turns (and
+	// restarts) start from it. See PersistCompression.
+	PersistCompression bool
+
 	// Per-turn compression: when in-memory message slice exceeds
 	// CompressThreshold runes, summarize via CompressModel. Works without a
 	// Store — operates on the in-memory slice during a single Execute.
@@ -169,13 +200,20 @@ func (m *AgentMemory) Init(cfg AgentMemoryConfig) {
 	m.protectedTools = cfg.ProtectedTools
 	m.semanticRecall = cfg.SemanticRecall
 	m.semanticMinScore = cfg.SemanticMinScore
+	m.semanticDedup = cfg.SemanticDedup
+	m.semanticMaxTokens = cfg.SemanticMaxTokens
 	m.recallKinds = cfg.RecallKinds
 	m.recallTopK = cfg.RecallTopK
 	m.workingMemory = cfg.WorkingMemory
 	m.workingMemoryScope = cfg.WorkingMemoryScope
 	m.autoTitle = cfg.AutoTitle
+	m.factCategories = cfg.FactCategories
+	m.factDedup = cfg.FactDedupThreshold
+	m.episodeProvider = cfg.EpisodeProvider
+	m.episodeEvery = cfg.EpisodeEvery
 	m.compactor = cfg.Compactor
 	m.compactThreshold = cfg.CompactThreshold
+	m.persistCompression = cfg.PersistCompression && cfg.Compactor != nil
 	m.compressModel = cfg.CompressModel
 	m.compressThreshold = cfg.CompressThreshold
 	m.tools = cfg.Tools
@@ -254,10 +292,13 @@ func (m *AgentMemory) syncIngestChain() []IngestProcessor {
 func (m *AgentMemory) asyncIngestChain() []IngestProcessor {
 	var chain []IngestProcessor
 	if m.provider != nil {
-		chain = append(chain, FactExtractor{})
+		chain = append(chain, FactExtractor{Categories: m.factCategories})
 	}
 	if m.embedding != nil {
 		chain = append(chain, Deduper{}, Embedder{})
+		if m.factDedup > 0 {
+			chain = append(chain, FactDedup{MinScore: m.factDedup})
+		}
 	}
 	// Upserter and DecayProbabilistic act only on the item store; without
 	// one they are no-ops, and skipping them lets PersistTurn avoid spawning
@@ -265,6 +306,17 @@ func (m *AgentMemory) asyncIngestChain() []IngestProcessor {
 	if m.itemStore != nil {
 		chain = append(chain, Upserter{})
 	}
+	if m.summarizesEpisodes() && m.store != nil {
+		es := EpisodeSummarizer{
+			Provider: m.episodeProvider,
+			Every:    m.episodeEvery,
+			Keep:     m.maxHistory,
+		}
+		if m.persistCompression {
+			es.Compactor = m.compactor
+		}
+		chain = append(chain, es)
+	}
 	if m.autoTitle && m.provider != nil {
 		chain = append(chain, TitleGenerator{})
 	}
@@ -275,6 +327,13 @@ func (m *AgentMemory) asyncIngestChain() []IngestProcessor {
 	return chain
 }
 
+// summarizesEpisodes reports whether threads keep a durable summary of the
+// history that has left the window (WithEpisodicSummary or
+// PersistCompression).
+func (m *AgentMemory) summarizesEpisodes() bool {
+	return (m.episodeEvery > 0 || m.persistCompression) && m.itemStore != nil
+}
+
 // PersistTurn persists a completed agent turn. The thread and message rows
 // are written synchronously before it returns, so a caller that observes
 // PersistTurn (and therefore Agent.Execute) returning is guaranteed that a
@@ -397,7 +456,8 @@ func RecallKind(k core.MemoryKind) RecallOption {
 func RecallScope(s core.MemoryScope) RecallOption { return func(c *recallCfg) { c.scope = &s } }
 func RecallLimit(n int) RecallOption              { return func(c *recallCfg) { c.limit = n } }
 
-// Recall returns items semantically similar to query.
+// Recall returns items semantically similar to query. Score is the cosine
+// similarity scaled by the item's Confidence, and results are ranked by it.
 func (m *AgentMemory) Recall(ctx context.Context, query string, opts ...RecallOption) ([]core.ScoredMemoryItem, error) {
 	if m.store == nil {
 		return nil, errors.New("memory: no store configured")
@@ -416,9 +476,13 @@ func (m *AgentMemory) Recall(ctx context.Context, query string, opts ...RecallOp
 	if err != nil || len(embs) == 0 {
 		return nil, err
 	}
-	return m.itemStore.SearchSemantic(ctx, embs[0], core.MemoryFilter{
+	results, err := m.itemStore.SearchSemantic(ctx, embs[0], core.MemoryFilter{
 		Kinds: cfg.kinds, Scope: cfg.scope,
-	}, cfg.limit)
+	}, cfg.limit*recallOverfetch)
+	if err != nil {
+		return nil, err
+	}
+	return weightByConfidence(results, cfg.limit), nil
 }
 
 // ForgetSpec describes what to delete.
@@ -6,6 +6,7 @@ package memtest
 import (
 	"context"
 	"errors"
+	"slices"
 	"sort"
 	"sync"
 	"testing"
@@ -77,6 +78,20 @@ func (s *ItemStore) DeleteWhere(_ context.Context, f core.MemoryFilter) (int, er
 	return n, nil
 }
 
+// DeleteExpired implements core.MemoryExpirer.
+func (s *ItemStore) DeleteExpired(_ context.Context, kinds ...core.MemoryKind) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	for id, it := range s.items {
+		if expired(it) && (len(kinds) == 0 || slices.Contains(kinds, it.Kind)) {
+			delete(s.items, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
 func (s *ItemStore) Get(_ context.Context, id string) (core.MemoryItem, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -137,6 +152,7 @@ func ConformanceTest(t *testing.T, newStore func(t *testing.T) core.MemoryItemSt
 	t.Run("DeleteWhereRejectsEmpty", func(t *testing.T) { testDeleteWhereEmpty(t, newStore(t)) })
 	t.Run("SearchSemantic", func(t *testing.T) { testSearchSemantic(t, newStore(t)) })
 	t.Run("GetMissingReturnsNotFound", func(t *testing.T) { testGetMissing(t, newStore(t)) })
+	t.Run("DeleteExpired", func(t *testing.T) { testDeleteExpired(t, newStore(t)) })
 }
 
 func testUpsertGet(t *testing.T, s core.MemoryItemStore) {
@@ -313,8 +329,52 @@ func matches(it core.MemoryItem, f core.MemoryFilter) bool {
 	if f.Until > 0 && it.CreatedAt > f.Until {
 		return false
 	}
-	if !f.IncludeExp && it.ExpiresAt > 0 && it.ExpiresAt <= time.Now().Unix() {
+	if !f.IncludeExp && expired(it) {
 		return false
 	}
 	return true
 }
+
+// expired reports whether it has an ExpiresAt in the past.
+func expired(it core.MemoryItem) bool {
+	return it.ExpiresAt > 0 && it.ExpiresAt <= time.Now().Unix()
+}
+
+func testDeleteExpired(t *testing.T, s core.MemoryItemStore) {
+	t.Helper()
+	ex, ok := s.(core.MemoryExpirer)
+	if !ok {
+		t.Skip("store does not implement core.MemoryExpirer")
+	}
+	ctx := context.Background()
+	now := time.Now().Unix()
+	for _, it := range []core.MemoryItem{
+		{ID: "expired", Kind: memory.KindFact, ExpiresAt: now - 60},
+		{ID: "live", Kind: memory.KindFact, ExpiresAt: now + 3600},
+		{ID: "permanent", Kind: memory.KindFact},
+		{ID: "expired-note", Kind: memory.KindNote, ExpiresAt: now - 60},
+	} {
+		if err := s.Upsert(ctx, it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := ex.DeleteExpired(ctx, memory.KindFact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("deleted %d, want 1", n)
+	}
+	got, err := s.List(ctx, core.MemoryFilter{IncludeExp: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("remaining = %+v, want live, permanent and expired-note", got)
+	}
+	for _, it := range got {
+		if it.ID == "expired" {
+			t.Fatal("expired item survived DeleteExpired")
+		}
+	}
+}
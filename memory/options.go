@@ -92,6 +92,18 @@ func WithCompaction(c core.Compactor, threshold float64) Option {
 	}
 }
 
+// PersistCompression makes WithCompaction durable across turns. History that
+// ages out of the window is folded, block by block, into the thread's stored
+// summary (the same row WithEpisodicSummary writes, ID EpisodeID) using the
+// WithCompaction compactor. Each fold extends the previous summary with only
+// the new messages, and later turns — including after a restart — load the
+// summary instead of re-summarizing raw history. Block size follows
+// WithEpisodicSummary's everyN (default 20). Requires WithCompaction and a
+// Store implementing core.MemoryItemStore; without a compactor it is a no-op.
+func PersistCompression() Option {
+	return func(c *AgentMemoryConfig) { c.PersistCompression = true }
+}
+
 // WithCompress enables per-turn LLM-driven summarization when the in-memory
 // message slice exceeds threshold runes. fn returns the model used for
 // summarization (falls back to the agent's main provider if fn returns nil).
@@ -112,6 +124,21 @@ func WithSemanticRecallMinScore(s float32) Option {
 	return func(c *AgentMemoryConfig) { c.SemanticMinScore = s }
 }
 
+// WithSemanticRecallDedup collapses near-duplicate cross-thread recalls: a
+// recalled message whose cosine similarity to a higher-scoring one is at
+// least threshold (e.g. 0.92) is dropped. Uses the Store's message
+// embeddings when returned, otherwise embeds the candidates with
+// WithEmbedding's provider. threshold <= 0 disables.
+func WithSemanticRecallDedup(threshold float32) Option {
+	return func(c *AgentMemoryConfig) { c.SemanticDedup = threshold }
+}
+
+// WithSemanticRecallBudget caps the estimated tokens of cross-thread recall
+// injected per turn, keeping the highest-scoring messages. 0 = no cap.
+func WithSemanticRecallBudget(maxTokens int) Option {
+	return func(c *AgentMemoryConfig) { c.SemanticMaxTokens = maxTokens }
+}
+
 // WithRecallKinds configures which MemoryItem kinds are included in BatchedRecall.
 // Defaults to [KindFact] when not set.
 func WithRecallKinds(kinds ...core.MemoryKind) Option {
@@ -139,6 +166,46 @@ func WithWorkingMemoryScope(s core.MemoryScopeKind) Option {
 // WithAutoTitle enables LLM-driven thread title generation on the first turn.
 func WithAutoTitle() Option { return func(c *AgentMemoryConfig) { c.AutoTitle = true } }
 
+// WithEpisodicSummary keeps early context of long threads. Once a thread
+// holds everyN messages beyond the history window (WithHistory's
+// MaxMessages), a background job folds the oldest block into a per-thread
+// episode summary with provider, extending the previous summary rather than
+// re-reading the thread. The summary is injected after the system prompt,
+// ahead of recent history. everyN <= 0 uses 20; a nil provider falls back to
+// WithProvider. Requires a Store implementing core.MemoryItemStore.
+func WithEpisodicSummary(provider core.Provider, everyN int) Option {
+	return func(c *AgentMemoryConfig) {
+		c.EpisodeProvider = provider
+		c.EpisodeEvery = everyN
+		if c.EpisodeEvery <= 0 {
+			c.EpisodeEvery = defaultEpisodeEvery
+		}
+	}
+}
+
+// WithFactCategories replaces the categories the fact extractor may assign
+// (default: personal, preference, work, habit, relationship). The extraction
+// prompt lists exactly these, and facts in any other category are dropped.
+// Include the defaults you still want:
+//
+//	memory.WithFactCategories("personal", "preference", "medical", "project")
+func WithFactCategories(categories ...string) Option {
+	return func(c *AgentMemoryConfig) { c.FactCategories = categories }
+}
+
+// WithFactDedup merges newly extracted facts into near-duplicates already
+// stored — an existing fact in the same scope whose cosine similarity to the
+// new one is at least threshold is overwritten with the newer wording rather
+// than kept alongside it. threshold <= 0 uses 0.85. Requires WithEmbedding.
+func WithFactDedup(threshold float32) Option {
+	return func(c *AgentMemoryConfig) {
+		if threshold <= 0 {
+			threshold = dedupMinScore
+		}
+		c.FactDedupThreshold = threshold
+	}
+}
+
 // WithTools registers agent-callable memory tools. Default OFF; pass
 // the tools you want — typically constructed from an AgentMemory like:
 //
@@ -14,7 +14,11 @@ const (
 	defaultKeepRecent             = 3
 	defaultSemanticRecallMinScore = float32(0.60)
 	maxRecallContentLen           = 500
+	crossThreadTopK               = 5
 	defaultRecallTopK             = 8
+	// recallOverfetch multiplies the search limit so confidence weighting
+	// has candidates to promote beyond the raw similarity top-K.
+	recallOverfetch = 2
 )
 
 // RetrieveProcessor transforms a RetrieveContext on the hot path.
@@ -31,6 +35,7 @@ type RetrieveContext struct {
 	Embedding []float32
 
 	History     []core.Message
+	Episode     string                                // thread's episode summary, set by LoadEpisode
 	Selected    map[core.MemoryKind][]core.MemoryItem // by Kind, set by BatchedRecall
 	Pinned      []core.MemoryItem
 	CrossThread []core.ScoredMessage
@@ -90,7 +95,8 @@ func (m *AgentMemory) BuildMessages(ctx context.Context, agentName, systemPrompt
 	// Assemble final []core.ChatMessage.
 	//
 	// Message order:
-	//   [0]   system  — stable: systemPrompt only (no RAG content)
+	//   [0]   system  — stable: systemPrompt, plus the episode summary
+	//                   when WithEpisodicSummary is on (no RAG content)
 	//   [1..N] history — stable: loaded from store
 	//   [N+1] user    — RAG context block (only if PromptParts non-empty)
 	//                   varies per turn; kept out of system to preserve cache hits
@@ -105,6 +111,11 @@ func (m *AgentMemory) BuildMessages(ctx context.Context, agentName, systemPrompt
 	if strings.TrimSpace(systemPrompt) != "" {
 		out = append(out, core.SystemMessage(systemPrompt))
 	}
+	if in.Episode != "" {
+		// Changes only when a block is folded, so it sits with the stable
+		// prefix rather than in the per-turn context block.
+		out = append(out, core.SystemMessage(episodePrefix+in.Episode))
+	}
 	if m.replayToolCalls {
 		// Expand persisted step traces back into tool_call/tool_result pairs
 		// (see replay.go). Expansion happens AFTER trimming, per whole stored
@@ -132,6 +143,9 @@ func (m *AgentMemory) defaultRetrieveChain() []RetrieveProcessor {
 		EmbedInput{},
 		LoadHistory{Limit: m.maxHistory},
 	}
+	if m.summarizesEpisodes() {
+		chain = append(chain, LoadEpisode{})
+	}
 	if m.itemStore != nil {
 		chain = append(chain, LoadPinned{})
 		chain = append(chain, BatchedRecall{
@@ -140,7 +154,11 @@ func (m *AgentMemory) defaultRetrieveChain() []RetrieveProcessor {
 		})
 	}
 	if m.semanticRecall {
-		chain = append(chain, RecallCrossThread{MinScore: m.semanticMinScore})
+		chain = append(chain, RecallCrossThread{
+			MinScore:  m.semanticMinScore,
+			Dedup:     m.semanticDedup,
+			MaxTokens: m.semanticMaxTokens,
+		})
 	}
 	if m.maxTokens > 0 {
 		trimProc := TrimToBudget{
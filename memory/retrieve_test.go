@@ -56,6 +56,61 @@ func TestBuildMessages_BatchedRecallIncludesFacts(t *testing.T) {
 	}
 }
 
+func TestBuildMessages_RecallIsolatedPerUser(t *testing.T) {
+	store := newConformanceStore(t)
+	must(t, store.Upsert(context.Background(), core.MemoryItem{
+		ID: "f1", Kind: KindFact, Content: "User likes dark mode",
+		Scope: UserScope("alice"), Embedding: []float32{1, 0, 0},
+	}))
+	var m AgentMemory
+	m.Init(AgentMemoryConfig{
+		Store: store, Embedding: &fakeEmbedder{out: [][]float32{{1, 0, 0}}},
+		RecallKinds: []core.MemoryKind{KindFact}, RecallTopK: 5,
+		Logger: discardLogger(),
+	})
+	recalled := func(userID string) bool {
+		// Both users talk in the same group chat.
+		task := core.AgentTask{ThreadID: "t1", ChatID: "group", UserID: userID, Input: "what color"}
+		for _, msg := range m.BuildMessages(context.Background(), "agent", "", task) {
+			if strings.Contains(msg.Content, "dark mode") {
+				return true
+			}
+		}
+		return false
+	}
+	if !recalled("alice") {
+		t.Error("alice's fact not recalled for alice")
+	}
+	if recalled("bob") {
+		t.Error("alice's fact leaked into bob's context")
+	}
+}
+
+func TestAgentMemory_RecallWeightsByConfidence(t *testing.T) {
+	store := newConformanceStore(t)
+	ctx := context.Background()
+	// The hesitant fact is the closer match, but its low confidence ranks it
+	// below the certain one.
+	must(t, store.Upsert(ctx, core.MemoryItem{
+		ID: "hesitant", Kind: KindFact, Content: "User might move to Berlin",
+		Tags: []string{ConfidenceTag(0.3)}, Embedding: []float32{1, 0, 0},
+	}))
+	must(t, store.Upsert(ctx, core.MemoryItem{
+		ID: "certain", Kind: KindFact, Content: "User lives in Munich",
+		Embedding: []float32{0.9, 0.1, 0},
+	}))
+	var m AgentMemory
+	m.Init(AgentMemoryConfig{Store: store, Embedding: &fakeEmbedder{out: [][]float32{{1, 0, 0}}}, Logger: discardLogger()})
+
+	got, err := m.Recall(ctx, "where does the user live", RecallLimit(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Item.ID != "certain" {
+		t.Fatalf("Recall = %+v, want the certain fact first", got)
+	}
+}
+
 // TestBuildMessages_RAGInContextBlock asserts that when processors return
 // PromptParts, they land in a separate user message wrapped in <context> tags,
 // positioned after history and before the current user input.
@@ -213,3 +268,47 @@ func TestBuildMessages_SystemStableAcrossCalls(t *testing.T) {
 		t.Errorf("system message differs between calls (cache miss):\ncall1: %q\ncall2: %q", sys1.Content, sys2.Content)
 	}
 }
+
+// crossThreadStore returns fixed cross-thread search results.
+type crossThreadStore struct {
+	*testStore
+	related []core.ScoredMessage
+}
+
+func (s crossThreadStore) SearchMessages(_ context.Context, _ []float32, _ int, _ string) ([]core.ScoredMessage, error) {
+	return s.related, nil
+}
+
+func TestRecallCrossThread_DedupAndBudget(t *testing.T) {
+	msg := func(id, thread, content string, score float32, emb ...float32) core.ScoredMessage {
+		return core.ScoredMessage{Message: core.Message{ID: id, ThreadID: thread, Role: core.RoleUser,
+			Content: content, Embedding: emb}, Score: score}
+	}
+	store := crossThreadStore{related: []core.ScoredMessage{
+		msg("a", "t2", "my cat is named Miso", 0.9, 1, 0),
+		msg("b", "t3", "my cat's name is Miso", 0.8, 0.99, 0.05),
+		msg("c", "t1", "same thread", 0.95, 0, 1),
+		msg("d", "t4", "I live in Lisbon", 0.7, 0, 1),
+		msg("e", "t5", strings.Repeat("long ", 100), 0.65, 0.5, 0.5),
+	}}
+	in := &RetrieveContext{
+		Task:         core.AgentTask{ThreadID: "t1", ChatID: "c1"},
+		Embedding:    []float32{1, 0},
+		HistoryStore: store,
+		Logger:       discardLogger(),
+	}
+	r := RecallCrossThread{Dedup: 0.95, MaxTokens: 40}
+	if err := r.Process(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, m := range in.CrossThread {
+		ids = append(ids, m.ID)
+	}
+	if got := strings.Join(ids, ","); got != "a,d" {
+		t.Errorf("kept = %s, want a,d (b is a duplicate of a, e exceeds the token cap)", got)
+	}
+	if len(in.PromptParts) != 1 || strings.Contains(in.PromptParts[0], "cat's name") {
+		t.Errorf("prompt parts = %q", in.PromptParts)
+	}
+}
@@ -4,6 +4,7 @@ package memory
 import (
 	"context"
 	"errors"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -71,6 +72,23 @@ func (s *inMemTestStore) DeleteWhere(_ context.Context, f core.MemoryFilter) (in
 	return n, nil
 }
 
+func (s *inMemTestStore) DeleteExpired(_ context.Context, kinds ...core.MemoryKind) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	for id, it := range s.items {
+		if itemExpired(it) && (len(kinds) == 0 || slices.Contains(kinds, it.Kind)) {
+			delete(s.items, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func itemExpired(it core.MemoryItem) bool {
+	return it.ExpiresAt > 0 && it.ExpiresAt <= time.Now().Unix()
+}
+
 func (s *inMemTestStore) Get(_ context.Context, id string) (core.MemoryItem, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -155,7 +173,7 @@ func filterMatches(it core.MemoryItem, f core.MemoryFilter) bool {
 	if f.Until > 0 && it.CreatedAt > f.Until {
 		return false
 	}
-	if !f.IncludeExp && it.ExpiresAt > 0 && it.ExpiresAt <= time.Now().Unix() {
+	if !f.IncludeExp && itemExpired(it) {
 		return false
 	}
 	return true
@@ -5,6 +5,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/nevindra/oasis/core"
 )
@@ -22,6 +23,7 @@ func ConformanceTest(t *testing.T, newStore func(t *testing.T) core.MemoryItemSt
 	t.Run("DeleteWhereRejectsEmpty", func(t *testing.T) { testDeleteWhereEmpty(t, newStore(t)) })
 	t.Run("SearchSemantic", func(t *testing.T) { testSearchSemantic(t, newStore(t)) })
 	t.Run("GetMissingReturnsNotFound", func(t *testing.T) { testGetMissing(t, newStore(t)) })
+	t.Run("DeleteExpired", func(t *testing.T) { testDeleteExpired(t, newStore(t)) })
 }
 
 func testUpsertGet(t *testing.T, s core.MemoryItemStore) {
@@ -171,3 +173,42 @@ func newInMemory() core.MemoryItemStore {
 	// Implementation: see memory/store_conformance_inmem_test.go
 	return newInMemTestStore()
 }
+
+func testDeleteExpired(t *testing.T, s core.MemoryItemStore) {
+	t.Helper()
+	ex, ok := s.(core.MemoryExpirer)
+	if !ok {
+		t.Skip("store does not implement core.MemoryExpirer")
+	}
+	ctx := context.Background()
+	now := time.Now().Unix()
+	for _, it := range []core.MemoryItem{
+		{ID: "expired", Kind: KindFact, ExpiresAt: now - 60},
+		{ID: "live", Kind: KindFact, ExpiresAt: now + 3600},
+		{ID: "permanent", Kind: KindFact},
+		{ID: "expired-note", Kind: KindNote, ExpiresAt: now - 60},
+	} {
+		if err := s.Upsert(ctx, it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := ex.DeleteExpired(ctx, KindFact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("deleted %d, want 1", n)
+	}
+	got, err := s.List(ctx, core.MemoryFilter{IncludeExp: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("remaining = %+v, want live, permanent and expired-note", got)
+	}
+	for _, it := range got {
+		if it.ID == "expired" {
+			t.Fatal("expired item survived DeleteExpired")
+		}
+	}
+}
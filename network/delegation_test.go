@@ -367,6 +367,56 @@ func TestForwardedChildEventsCarryAgentStamp(t *testing.T) {
 	}
 }
 
+// TestRoutingDecisionCarriesTasksAndReasoning: the routing-decision event
+// names each chosen agent with the task it receives and the router's text,
+// and arrives before the agent-start event.
+func TestRoutingDecisionCarriesTasksAndReasoning(t *testing.T) {
+	router := &routerCallbackProvider{
+		name: "router",
+		onChat: func(req core.ChatRequest) core.ChatResponse {
+			if countAssistantToolTurns(req) == 0 {
+				return core.ChatResponse{Content: "Needs fresh numbers.",
+					ToolCalls: []core.ToolCall{delegationCall("1", "analyst", "find Q3 revenue")}}
+			}
+			return core.ChatResponse{Content: "final"}
+		},
+	}
+	sub := &stubAgent{name: "analyst", desc: "Numbers", fn: func(agent.AgentTask) (agent.AgentResult, error) {
+		return agent.AgentResult{Output: "42"}, nil
+	}}
+	net := New("net", "test", router, WithChildren(sub))
+
+	ch := make(chan core.StreamEvent, 128)
+	if _, err := net.Execute(context.Background(), agent.AgentTask{Input: "go"}, core.WithStream(ch)); err != nil {
+		t.Fatal(err)
+	}
+	var order []core.StreamEventType
+	var decision struct {
+		Agents    []string `json:"agents"`
+		Tasks     []string `json:"tasks"`
+		Reasoning string   `json:"reasoning"`
+	}
+	for ev := range ch {
+		switch ev.Type {
+		case core.EventRoutingDecision:
+			if err := json.Unmarshal([]byte(ev.Content), &decision); err != nil {
+				t.Fatalf("routing decision %q: %v", ev.Content, err)
+			}
+			order = append(order, ev.Type)
+		case core.EventAgentStart:
+			order = append(order, ev.Type)
+		}
+	}
+	if len(order) != 2 || order[0] != core.EventRoutingDecision {
+		t.Errorf("event order = %v, want routing decision then agent start", order)
+	}
+	if len(decision.Agents) != 1 || decision.Agents[0] != "analyst" ||
+		len(decision.Tasks) != 1 || decision.Tasks[0] != "find Q3 revenue" ||
+		decision.Reasoning != "Needs fresh numbers." {
+		t.Errorf("decision = %+v", decision)
+	}
+}
+
 // TestNetworkRouterSelfClone: a router with WithSelfClone fans out copies of
 // ITSELF (not children) — two spawn_subagent calls in one message run two
 // router clones concurrently, named <network>-N.
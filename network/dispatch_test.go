@@ -3,6 +3,7 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -104,3 +105,86 @@ func TestParallelDispatch_DisabledIsSequential(t *testing.T) {
 		t.Fatalf("sequential dispatch (MaxParallelDispatch=1) should be sequential; gap was %v (want ≥40ms)", gap)
 	}
 }
+
+func TestParallelRouting_GuidanceAndAttribution(t *testing.T) {
+	legal := &slowAgent{name: "legal", desc: "legal review"}
+	finance := &slowAgent{name: "finance", desc: "financial review"}
+
+	var mu sync.Mutex
+	var requests []core.ChatRequest
+	router := &routerCallbackProvider{name: "router", onChat: func(req core.ChatRequest) core.ChatResponse {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, req)
+		if len(requests) > 1 {
+			return core.ChatResponse{Content: "synthesis"}
+		}
+		call := func(id, sub string) core.ToolCall {
+			args, _ := json.Marshal(map[string]string{"subagent": sub, "task": "review the contract"})
+			return core.ToolCall{ID: id, Name: core.ToolTask, Args: args}
+		}
+		return core.ChatResponse{ToolCalls: []core.ToolCall{call("1", "legal"), call("2", "finance")}}
+	}}
+
+	net := New("review", "review", router, WithChildren(legal, finance), WithParallelRouting())
+	if _, err := net.Execute(context.Background(), core.AgentTask{Input: "review this"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("router calls = %d, want 2", len(requests))
+	}
+	var taskDesc string
+	for _, td := range requests[0].Tools {
+		if td.Name == core.ToolTask {
+			taskDesc = td.Description
+		}
+	}
+	if !strings.Contains(taskDesc, "Fan-out routing") {
+		t.Errorf("task tool description lacks fan-out guidance: %q", taskDesc)
+	}
+
+	results := map[string]string{}
+	for _, m := range requests[1].Messages {
+		if m.Role == core.RoleTool {
+			results[m.ToolCallID] = m.Content
+		}
+	}
+	if want := "Report from subagent \"legal\":\n\nlegal"; results["1"] != want {
+		t.Errorf("legal result = %q, want %q", results["1"], want)
+	}
+	if want := "Report from subagent \"finance\":\n\nfinance"; results["2"] != want {
+		t.Errorf("finance result = %q, want %q", results["2"], want)
+	}
+}
+
+func TestParallelRouting_OffLeavesResultsUnlabelled(t *testing.T) {
+	a := &slowAgent{name: "a", desc: "agent a"}
+	var second core.ChatRequest
+	calls := 0
+	router := &routerCallbackProvider{name: "router", onChat: func(req core.ChatRequest) core.ChatResponse {
+		calls++
+		if calls > 1 {
+			second = req
+			return core.ChatResponse{Content: "done"}
+		}
+		for _, td := range req.Tools {
+			if td.Name == core.ToolTask && strings.Contains(td.Description, "Fan-out routing") {
+				t.Error("fan-out guidance present without WithParallelRouting")
+			}
+		}
+		args, _ := json.Marshal(map[string]string{"subagent": "a", "task": "x"})
+		return core.ChatResponse{ToolCalls: []core.ToolCall{{ID: "1", Name: core.ToolTask, Args: args}}}
+	}}
+	net := New("team", "team", router, WithChildren(a))
+	if _, err := net.Execute(context.Background(), core.AgentTask{Input: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range second.Messages {
+		if m.Role == core.RoleTool && m.Content != "a" {
+			t.Errorf("tool result = %q, want raw output", m.Content)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package network
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nevindra/oasis/agent"
+	"github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/internal/runtime"
+)
+
+// HandoffRouter is the Handoff target that gives the conversation back to
+// the network's router.
+const HandoffRouter = "router"
+
+// maxThreadOwners bounds how many threads a Network remembers a handoff
+// owner for. The least recently used thread is forgotten first; its next
+// turn goes to the router.
+const maxThreadOwners = 10000
+
+// maxHandoffs bounds chained transfers within one delegation so two agents
+// cannot pass a task back and forth forever.
+const maxHandoffs = 4
+
+// handoffKey carries the *handoffSlot of the delegation in progress.
+type handoffKey struct{}
+
+// handoffHopsKey carries the number of transfers that led to the current
+// delegation. Kept separate from handoffKey so a network nested inside
+// another does not inherit the outer network's count.
+type handoffHopsKey struct{}
+
+// handoffSlot is placed in a child's context by dispatchAgent. Handoff
+// records a transfer request in it; dispatchAgent acts on the request once
+// the child's run returns.
+type handoffSlot struct {
+	network *Network
+	from    string
+	hops    int
+
+	mu  sync.Mutex
+	req *handoffRequest
+}
+
+type handoffRequest struct {
+	to     string
+	reason string
+	task   string
+}
+
+func (s *handoffSlot) take() *handoffRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req := s.req
+	s.req = nil
+	return req
+}
+
+func handoffHops(ctx context.Context) int {
+	hops, _ := ctx.Value(handoffHopsKey{}).(int)
+	return hops
+}
+
+// Handoff transfers the conversation from the agent currently running as a
+// Network child to the sibling named target. Call it from a tool; the
+// transfer happens when the calling agent's run ends, so the tool should
+// tell the model to wrap up (HandoffTool does this).
+//
+// The Network then runs target with task — or, when task is empty, the same
+// task the calling agent was given — under the same thread ID, so memory
+// carries over. Later turns on that thread go straight to target, skipping
+// the router, until target hands off again. Pass HandoffRouter as target to
+// give control back. reason is recorded in StepTrace.Handoff.
+//
+// Handoff returns an error, for the tool to report to the model, when ctx
+// does not belong to a Network delegation, target is unknown or the caller
+// itself, or the transfer chain is too long.
+func Handoff(ctx context.Context, target, reason, task string) error {
+	slot, _ := ctx.Value(handoffKey{}).(*handoffSlot)
+	if slot == nil {
+		return errors.New("handoff is only available to agents running inside a network")
+	}
+	if target != HandoffRouter {
+		if target == slot.from {
+			return fmt.Errorf("cannot hand off to yourself (%q)", target)
+		}
+		n := slot.network
+		n.mu.RLock()
+		_, ok := n.agents[target]
+		names := make([]string, 0, len(n.sortedAgentNames))
+		for _, name := range n.sortedAgentNames {
+			if name != slot.from {
+				names = append(names, name)
+			}
+		}
+		n.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown agent %q — valid: %s", target, strings.Join(append(names, HandoffRouter), ", "))
+		}
+		if slot.hops >= maxHandoffs {
+			return fmt.Errorf("too many handoffs in a row (%d); answer the request yourself or hand back to %q", maxHandoffs, HandoffRouter)
+		}
+	}
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	if slot.req != nil {
+		return fmt.Errorf("already handed off to %q", slot.req.to)
+	}
+	slot.req = &handoffRequest{to: target, reason: reason, task: task}
+	return nil
+}
+
+// ActiveAgent returns the child that currently owns the conversation on
+// threadID through a handoff, or "" when the router handles it.
+func (n *Network) ActiveAgent(threadID string) string {
+	if threadID == "" {
+		return ""
+	}
+	return n.owners.get(threadID)
+}
+
+// setActiveAgent records owner for threadID; "" clears it. Without a thread
+// ID there is no later turn to route, so nothing is recorded.
+func (n *Network) setActiveAgent(threadID, owner string) {
+	if threadID == "" {
+		return
+	}
+	n.owners.set(threadID, owner)
+}
+
+// threadOwners is an LRU map from thread ID to the child that owns the
+// thread, holding at most max entries. The zero value is not usable; create
+// with newThreadOwners.
+type threadOwners struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front = most recently used
+	max     int
+}
+
+type threadOwner struct {
+	thread, owner string
+}
+
+func newThreadOwners(max int) *threadOwners {
+	return &threadOwners{entries: make(map[string]*list.Element), lru: list.New(), max: max}
+}
+
+func (t *threadOwners) get(thread string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	el, ok := t.entries[thread]
+	if !ok {
+		return ""
+	}
+	t.lru.MoveToFront(el)
+	return el.Value.(*threadOwner).owner
+}
+
+// set records owner for thread; "" removes the entry.
+func (t *threadOwners) set(thread, owner string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if el, ok := t.entries[thread]; ok {
+		if owner == "" {
+			t.lru.Remove(el)
+			delete(t.entries, thread)
+			return
+		}
+		el.Value.(*threadOwner).owner = owner
+		t.lru.MoveToFront(el)
+		return
+	}
+	if owner == "" {
+		return
+	}
+	t.entries[thread] = t.lru.PushFront(&threadOwner{thread: thread, owner: owner})
+	for t.lru.Len() > t.max {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.entries, oldest.Value.(*threadOwner).thread)
+	}
+}
+
+// removeOwner drops every thread owned by owner.
+func (t *threadOwners) removeOwner(owner string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for el := t.lru.Front(); el != nil; {
+		next := el.Next()
+		if e := el.Value.(*threadOwner); e.owner == owner {
+			t.lru.Remove(el)
+			delete(t.entries, e.thread)
+		}
+		el = next
+	}
+}
+
+// followHandoff acts on a transfer requested by from, whose run produced
+// result. A hand-back returns from's output with a note for the router;
+// a transfer to a sibling runs the sibling on the carried task and returns
+// its output in from's place.
+func (n *Network) followHandoff(ctx context.Context, from, taskText string, result agent.AgentResult, req *handoffRequest, parentTask agent.AgentTask, ch chan<- core.StreamEvent, ledger *delegationLedger, forRouter bool) agent.DispatchResult {
+	h := &core.Handoff{From: from, To: req.to, Reason: req.reason}
+	n.Logger().Info("subagent handed off", "network", n.Name(), "from", from, "to", req.to, "reason", agent.TruncateStr(req.reason, 80))
+
+	if req.to == HandoffRouter {
+		h.To = ""
+		n.setActiveAgent(parentTask.ThreadID, "")
+		output := result.Output
+		if forRouter {
+			output = fmt.Sprintf("%s\n\n[%q handed the conversation back to you. Reason: %s]", output, from, req.reason)
+		}
+		return agent.DispatchResult{Content: output, Usage: result.Usage, Attachments: result.Attachments, Sources: result.Sources, Handoff: h}
+	}
+
+	n.setActiveAgent(parentTask.ThreadID, req.to)
+	task := req.task
+	if task == "" {
+		task = taskText
+	}
+	ctx = context.WithValue(ctx, handoffHopsKey{}, handoffHops(ctx)+1)
+	dr := n.delegate(ctx, req.to, task, parentTask, ch, ledger, forRouter)
+	if dr.IsError {
+		// The sibling could not take over; leave the thread with the router.
+		n.setActiveAgent(parentTask.ThreadID, "")
+	} else if forRouter {
+		dr.Content = fmt.Sprintf("%q transferred this task to %q, which now owns the conversation. Reason: %s\n\n%s",
+			from, req.to, req.reason, dr.Content)
+	}
+	dr.Usage.Add(result.Usage)
+	dr.Handoff = h
+	return dr
+}
+
+// runOwnedTurn answers a turn on a thread that owner took over, without the
+// router. When owner hands back (or fails) during the turn, the router runs
+// the turn as usual.
+func (n *Network) runOwnedTurn(ctx context.Context, owner string, task agent.AgentTask, ch chan<- core.StreamEvent, ro *agent.RunOptions) (agent.AgentResult, error) {
+	start := time.Now()
+	dr := n.delegate(ctx, owner, task.Input, task, ch, nil, false)
+	step := core.StepTrace{
+		Name:      owner,
+		Type:      core.StepTypeAgent,
+		Input:     agent.TruncateStr(task.Input, 200),
+		Output:    agent.TruncateStr(dr.Content, 500),
+		RawOutput: dr.Content,
+		Usage:     dr.Usage,
+		Duration:  time.Since(start),
+		Handoff:   dr.Handoff,
+	}
+
+	if dr.IsError || n.ActiveAgent(task.ThreadID) == "" {
+		if dr.IsError {
+			n.setActiveAgent(task.ThreadID, "")
+			n.Logger().Warn("handed-off agent failed, routing turn to router", "network", n.Name(), "agent", owner, "error", dr.Content)
+		}
+		lc := n.buildLoopConfig(ctx, task, ch, ro)
+		result, err := agent.RunLoop(ctx, lc, task, ch)
+		if !result.Suspended() {
+			runtime.ReleaseLoopConfig(lc)
+		}
+		result.Steps = append([]core.StepTrace{step}, result.Steps...)
+		result.Usage.Add(dr.Usage)
+		return result, err
+	}
+
+	result := agent.AgentResult{
+		Output:       dr.Content,
+		Usage:        dr.Usage,
+		Steps:        []core.StepTrace{step},
+		FinishReason: core.FinishStop,
+		Files:        dr.Attachments,
+	}
+	if ch != nil {
+		select {
+		case ch <- core.StreamEvent{Type: core.EventRunFinish, Name: n.Name(), Content: result.Output, Usage: result.Usage, FinishReason: result.FinishReason}:
+		case <-ctx.Done():
+		}
+		close(ch)
+	}
+	return result, nil
+}
+
+// handoffParams is the transfer_to_agent argument shape.
+type handoffParams struct {
+	Agent  string `json:"agent"`
+	Reason string `json:"reason"`
+	Task   string `json:"task,omitempty"`
+}
+
+// handoffTool is the transfer_to_agent tool built by HandoffTool.
+type handoffTool struct {
+	targets []string
+}
+
+// HandoffTool returns the transfer_to_agent tool, which lets an agent
+// running as a Network child hand the conversation to a sibling (see
+// Handoff). Add it to each specialist that may transfer:
+//
+//	billing := agent.New("billing", "Billing questions", p,
+//	    agent.WithTools(network.HandoffTool("support", "sales")),
+//	)
+//
+// targets, when given, are listed to the model and are the only agents it
+// may pick, besides HandoffRouter; with none, any sibling is accepted.
+func HandoffTool(targets ...string) core.AnyTool {
+	sorted := append([]string(nil), targets...)
+	sort.Strings(sorted)
+	return &handoffTool{targets: sorted}
+}
+
+func (t *handoffTool) Name() string { return core.ToolTransferToAgent }
+
+func (t *handoffTool) Definition() core.ToolDefinition {
+	desc := "Transfer this conversation to another agent on your team when the request is outside your expertise " +
+		"or another agent is better suited. That agent takes over this request and the user's follow-up messages " +
+		"until it transfers back. Use agent \"" + HandoffRouter + "\" to give the conversation back to the coordinator. " +
+		"After a successful transfer, end your turn with at most one short sentence; do not keep working on the request."
+	agentSchema := map[string]any{"type": "string", "description": "Name of the agent to transfer to."}
+	if len(t.targets) > 0 {
+		desc += " Agents you can transfer to: " + strings.Join(t.targets, ", ") + "."
+		agentSchema["enum"] = append(append([]string(nil), t.targets...), HandoffRouter)
+	}
+	schema, _ := json.Marshal(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"agent":  agentSchema,
+			"reason": map[string]any{"type": "string", "description": "Why the other agent should take over. Recorded for audit."},
+			"task":   map[string]any{"type": "string", "description": "Optional self-contained task for the other agent. Defaults to the task you were given."},
+		},
+		"required": []string{"agent", "reason"},
+	})
+	return core.ToolDefinition{Name: core.ToolTransferToAgent, Description: desc, Parameters: schema}
+}
+
+func (t *handoffTool) ExecuteRaw(ctx context.Context, args json.RawMessage) (core.ToolResult, error) {
+	var p handoffParams
+	if err := json.Unmarshal(args, &p); err != nil {
+		return core.ToolResult{Error: "invalid arguments: " + err.Error()}, nil
+	}
+	if p.Agent == "" {
+		return core.ToolResult{Error: "agent is required"}, nil
+	}
+	if len(t.targets) > 0 && p.Agent != HandoffRouter {
+		i := sort.SearchStrings(t.targets, p.Agent)
+		if i == len(t.targets) || t.targets[i] != p.Agent {
+			return core.ToolResult{Error: fmt.Sprintf("cannot transfer to %q — allowed: %s, %s", p.Agent, strings.Join(t.targets, ", "), HandoffRouter)}, nil
+		}
+	}
+	if err := Handoff(ctx, p.Agent, p.Reason, p.Task); err != nil {
+		return core.ToolResult{Error: err.Error()}, nil
+	}
+	if p.Agent == HandoffRouter {
+		return core.ToolResult{Content: "Transfer accepted: the coordinator takes the conversation back when you finish. End your turn now."}, nil
+	}
+	return core.ToolResult{Content: fmt.Sprintf("Transfer accepted: %q takes over when you finish. End your turn now with at most one short sentence.", p.Agent)}, nil
+}
+
+// compile-time check
+var _ core.AnyTool = (*handoffTool)(nil)
@@ -0,0 +1,195 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nevindra/oasis/agent"
+	"github.com/nevindra/oasis/core"
+)
+
+// ctxAgent is a child whose behaviour depends on its context, so tests can
+// call transfer_to_agent the way a real agent's tool call would.
+type ctxAgent struct {
+	name  string
+	fn    func(ctx context.Context, task agent.AgentTask) string
+	mu    sync.Mutex
+	tasks []agent.AgentTask
+}
+
+func (a *ctxAgent) Name() string        { return a.name }
+func (a *ctxAgent) Description() string { return a.name + " specialist" }
+func (a *ctxAgent) Execute(ctx context.Context, task agent.AgentTask, opts ...core.RunOption) (agent.AgentResult, error) {
+	if rcfg := core.ApplyRunOptions(opts...); rcfg.Stream != nil {
+		close(rcfg.Stream)
+	}
+	a.mu.Lock()
+	a.tasks = append(a.tasks, task)
+	a.mu.Unlock()
+	return agent.AgentResult{Output: a.fn(ctx, task)}, nil
+}
+
+func transfer(t *testing.T, ctx context.Context, tool core.AnyTool, to, reason string) core.ToolResult {
+	t.Helper()
+	args, _ := json.Marshal(handoffParams{Agent: to, Reason: reason})
+	res, err := tool.ExecuteRaw(ctx, args)
+	if err != nil {
+		t.Fatalf("transfer_to_agent: %v", err)
+	}
+	return res
+}
+
+func TestHandoffTransfersConversation(t *testing.T) {
+	tool := HandoffTool()
+	billing := &ctxAgent{name: "billing", fn: func(ctx context.Context, _ agent.AgentTask) string {
+		if res := transfer(t, ctx, tool, "support", "account locked, not a billing issue"); res.Error != "" {
+			t.Errorf("transfer rejected: %s", res.Error)
+		}
+		return "Passing you to support."
+	}}
+	support := &ctxAgent{name: "support", fn: func(_ context.Context, task agent.AgentTask) string {
+		return "support: " + task.Input
+	}}
+
+	var routerCalls int
+	router := &routerCallbackProvider{name: "router", onChat: func(core.ChatRequest) core.ChatResponse {
+		routerCalls++
+		if routerCalls == 1 {
+			args, _ := json.Marshal(agent.TaskToolArgs{Subagent: "billing", Task: "refund my order"})
+			return core.ChatResponse{ToolCalls: []core.ToolCall{{ID: "1", Name: core.ToolTask, Args: args}}}
+		}
+		return core.ChatResponse{Content: "done"}
+	}}
+	net := New("desk", "help desk", router, WithChildren(billing, support))
+
+	res, err := net.Execute(context.Background(), core.AgentTask{Input: "I want a refund", ThreadID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(support.tasks) != 1 || support.tasks[0].Input != "refund my order" || support.tasks[0].ThreadID != "t1" {
+		t.Fatalf("support tasks = %+v, want the carried task on thread t1", support.tasks)
+	}
+	var h *core.Handoff
+	for _, s := range res.Steps {
+		if s.Handoff != nil {
+			h = s.Handoff
+			if !strings.Contains(s.RawOutput, "support: refund my order") {
+				t.Errorf("handoff step output = %q, want the target's report", s.RawOutput)
+			}
+		}
+	}
+	if h == nil || *h != (core.Handoff{From: "billing", To: "support", Reason: "account locked, not a billing issue"}) {
+		t.Fatalf("step handoff = %+v", h)
+	}
+	if got := net.ActiveAgent("t1"); got != "support" {
+		t.Fatalf("ActiveAgent = %q, want support", got)
+	}
+
+	// The next turn on the thread skips the router.
+	calls := routerCalls
+	res, err = net.Execute(context.Background(), core.AgentTask{Input: "still locked", ThreadID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routerCalls != calls {
+		t.Errorf("router was called on an owned turn")
+	}
+	if res.Output != "support: still locked" || len(res.Steps) != 1 || res.Steps[0].Name != "support" {
+		t.Errorf("owned turn result = %+v", res)
+	}
+
+	// Other threads still go through the router.
+	if got := net.ActiveAgent("t2"); got != "" {
+		t.Errorf("ActiveAgent(t2) = %q, want empty", got)
+	}
+}
+
+func TestHandoffBackToRouter(t *testing.T) {
+	tool := HandoffTool("billing")
+	support := &ctxAgent{name: "support", fn: func(ctx context.Context, task agent.AgentTask) string {
+		if task.Input == "thanks, bye" {
+			transfer(t, ctx, tool, HandoffRouter, "issue resolved")
+			return "Glad I could help."
+		}
+		return "support: " + task.Input
+	}}
+	billing := &ctxAgent{name: "billing", fn: func(context.Context, agent.AgentTask) string { return "billing" }}
+
+	var routerCalls int
+	router := &routerCallbackProvider{name: "router", onChat: func(core.ChatRequest) core.ChatResponse {
+		routerCalls++
+		return core.ChatResponse{Content: "router here"}
+	}}
+	net := New("desk", "help desk", router, WithChildren(billing, support))
+	net.setActiveAgent("t1", "support")
+
+	res, err := net.Execute(context.Background(), core.AgentTask{Input: "thanks, bye", ThreadID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routerCalls != 1 || res.Output != "router here" {
+		t.Errorf("router should answer the handed-back turn: calls=%d output=%q", routerCalls, res.Output)
+	}
+	if len(res.Steps) == 0 || res.Steps[0].Handoff == nil || res.Steps[0].Handoff.To != "" || res.Steps[0].Handoff.Reason != "issue resolved" {
+		t.Errorf("first step = %+v, want the hand-back", res.Steps)
+	}
+	if got := net.ActiveAgent("t1"); got != "" {
+		t.Errorf("ActiveAgent = %q after hand-back, want empty", got)
+	}
+}
+
+func TestHandoffRejections(t *testing.T) {
+	if err := Handoff(context.Background(), "support", "", ""); err == nil {
+		t.Error("Handoff outside a network: want error")
+	}
+
+	var results []core.ToolResult
+	tool := HandoffTool("support")
+	billing := &ctxAgent{name: "billing", fn: func(ctx context.Context, _ agent.AgentTask) string {
+		results = append(results,
+			transfer(t, ctx, tool, "sales", "x"),
+			transfer(t, ctx, HandoffTool(), "nobody", "x"),
+			transfer(t, ctx, HandoffTool(), "billing", "x"),
+		)
+		return "handled it myself"
+	}}
+	sales := &ctxAgent{name: "sales", fn: func(context.Context, agent.AgentTask) string { return "sales" }}
+	support := &ctxAgent{name: "support", fn: func(context.Context, agent.AgentTask) string { return "support" }}
+	net := New("desk", "help desk", &routerCallbackProvider{name: "router"}, WithChildren(billing, sales, support))
+
+	dr := net.dispatchAgent(context.Background(), "billing", "refund", core.AgentTask{ThreadID: "t1"}, nil, nil)
+	if dr.Content != "handled it myself" || dr.Handoff != nil {
+		t.Errorf("dispatch = %+v, want the caller's own output", dr)
+	}
+	for i, want := range []string{"cannot transfer to \"sales\"", "unknown agent \"nobody\"", "cannot hand off to yourself"} {
+		if !strings.Contains(results[i].Error, want) {
+			t.Errorf("result %d error = %q, want %q", i, results[i].Error, want)
+		}
+	}
+	if net.ActiveAgent("t1") != "" {
+		t.Error("rejected handoffs must not change the thread owner")
+	}
+}
+
+func TestThreadOwnersEvictsLeastRecentlyUsed(t *testing.T) {
+	o := newThreadOwners(2)
+	o.set("t1", "billing")
+	o.set("t2", "sales")
+	o.get("t1") // t2 is now the least recently used
+	o.set("t3", "support")
+
+	if got := o.get("t2"); got != "" {
+		t.Errorf("t2 owner = %q, want evicted", got)
+	}
+	if o.get("t1") != "billing" || o.get("t3") != "support" {
+		t.Error("recently used threads must keep their owner")
+	}
+
+	o.removeOwner("billing")
+	if o.get("t1") != "" {
+		t.Error("removeOwner must drop the owner's threads")
+	}
+}
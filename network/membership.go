@@ -37,7 +37,8 @@ func (n *Network) AddAgent(child core.Agent) error {
 
 // RemoveAgent removes the child with the given name. Thread-safe. Returns an
 // error if no such child exists. In-flight calls to the removed child are not
-// interrupted.
+// interrupted; conversations it had taken over via Handoff return to the
+// router.
 func (n *Network) RemoveAgent(name string) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -58,5 +59,7 @@ func (n *Network) RemoveAgent(name string) error {
 	}
 	// Mark the dynamic-path cache stale so the next Execute rebuilds it.
 	n.toolDefsDirty = true
+	// Threads the removed child had taken over go back to the router.
+	n.owners.removeOwner(name)
 	return nil
 }
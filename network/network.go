@@ -127,6 +127,16 @@ type Network struct {
 	// childTimeout, when > 0, bounds each delegation to a child agent.
 	// Set via WithChildTimeout.
 	childTimeout time.Duration
+
+	// parallelRouting enables fan-out guidance and per-agent attribution of
+	// delegation results. Set via WithParallelRouting.
+	parallelRouting bool
+
+	// owners maps a thread ID to the child that took the conversation over
+	// via Handoff; Execute routes that thread's turns straight to it.
+	// Entries are removed when the child hands back, and the least recently
+	// used are evicted beyond maxThreadOwners.
+	owners *threadOwners
 }
 
 // New constructs a Network — a router LLM coordinating zero or more child
@@ -142,6 +152,7 @@ type Network struct {
 func New(name, description string, router core.Provider, opts ...Option) *Network {
 	n := &Network{
 		agents: make(map[string]agent.Agent),
+		owners: newThreadOwners(maxThreadOwners),
 	}
 
 	// Apply options first — they may mutate Network fields BEFORE runtime init.
@@ -240,6 +251,16 @@ func (n *Network) Execute(ctx context.Context, task agent.AgentTask, opts ...cor
 		// Per-run spawn budget for the router's spawn_subagent built-in.
 		ctx = agent.WithCloneScope(ctx)
 	}
+	if owner := n.ActiveAgent(task.ThreadID); owner != "" {
+		return n.ExecuteWithSpan(ctx, task, rcfg.Stream, "Network", "network",
+			func(context.Context, agent.AgentTask, chan<- core.StreamEvent) *agent.LoopConfig {
+				return runtime.AcquireLoopConfig()
+			},
+			func(ctx context.Context, _ *agent.LoopConfig, task agent.AgentTask, ch chan<- core.StreamEvent) (agent.AgentResult, error) {
+				return n.runOwnedTurn(ctx, owner, task, ch, ro)
+			},
+		)
+	}
 	return n.ExecuteWithSpan(ctx, task, rcfg.Stream, "Network", "network",
 		func(ctx context.Context, task agent.AgentTask, ch chan<- core.StreamEvent) *agent.LoopConfig {
 			return n.buildLoopConfig(ctx, task, ch, ro)
@@ -311,6 +332,7 @@ func (n *Network) makeDispatch(parentTask agent.AgentTask, ch chan<- core.Stream
 		ResolvePolicy:     cfg.ResolveToolPolicy,
 		IsStreamingTool:   isStreamingTool,
 		Logger:            cfg.Logger,
+		DryRun:            cfg.IsDryRun,
 	})
 }
 
@@ -368,6 +390,13 @@ func (n *Network) taskRoster() []agent.TaskTarget {
 // the "error: ..." text when the child failed. The ledger rejects duplicate
 // in-flight delegations and replays completed ones instead of re-executing.
 func (n *Network) dispatchAgent(ctx context.Context, agentName, taskText string, parentTask agent.AgentTask, ch chan<- core.StreamEvent, ledger *delegationLedger) agent.DispatchResult {
+	return n.delegate(ctx, agentName, taskText, parentTask, ch, ledger, true)
+}
+
+// delegate is the body of dispatchAgent. forRouter is false when the child's
+// output goes straight to the user (a handed-off turn), which skips the
+// router-facing labels: parallel-routing attribution and handoff notes.
+func (n *Network) delegate(ctx context.Context, agentName, taskText string, parentTask agent.AgentTask, ch chan<- core.StreamEvent, ledger *delegationLedger, forRouter bool) agent.DispatchResult {
 	n.mu.RLock()
 	sub, ok := n.agents[agentName]
 	names := make([]string, len(n.sortedAgentNames))
@@ -446,6 +475,10 @@ func (n *Network) dispatchAgent(ctx context.Context, agentName, taskText string,
 		defer cancel()
 	}
 
+	// The child may transfer the conversation to a sibling via Handoff.
+	slot := &handoffSlot{network: n, from: agentName, hops: handoffHops(ctx)}
+	execCtx = context.WithValue(execCtx, handoffKey{}, slot)
+
 	start := time.Now()
 	result, err := agent.ExecuteAgent(execCtx, sub, agentName, subTask, ch, n.Logger())
 	elapsed := time.Since(start)
@@ -481,7 +514,14 @@ func (n *Network) dispatchAgent(ctx context.Context, agentName, taskText string,
 		"duration", elapsed,
 		"input_tokens", result.Usage.InputTokens,
 		"output_tokens", result.Usage.OutputTokens)
-	return agent.DispatchResult{Content: result.Output, Usage: result.Usage, Attachments: result.Attachments}
+	if req := slot.take(); req != nil {
+		return n.followHandoff(ctx, agentName, taskText, result, req, parentTask, ch, ledger, forRouter)
+	}
+	output := result.Output
+	if forRouter && n.parallelRouting {
+		output = attributeReport(agentName, output)
+	}
+	return agent.DispatchResult{Content: output, Usage: result.Usage, Attachments: result.Attachments, Sources: result.Sources}
 }
 
 // buildToolDefs builds tool definitions from subagents and the given tool definitions.
@@ -534,7 +574,11 @@ func (n *Network) buildToolDefsLocked(toolDefs []core.ToolDefinition) []core.Too
 		for _, name := range n.sortedAgentNames {
 			targets = append(targets, agent.TaskTarget{Name: name, Description: n.agents[name].Description()})
 		}
-		defs = append(defs, agent.BuildTaskToolDef(targets, n.SelfCloneMax > 0, n.SelfCloneMax))
+		def := agent.BuildTaskToolDef(targets, n.SelfCloneMax > 0, n.SelfCloneMax)
+		if n.parallelRouting {
+			def.Description += parallelRoutingGuidance
+		}
+		defs = append(defs, def)
 	}
 	if n.spawnPolicy != nil {
 		defs = append(defs, core.ToolDefinition{
@@ -0,0 +1,34 @@
+package network
+
+import "fmt"
+
+// parallelRoutingGuidance is appended to the task tool description under
+// WithParallelRouting. It turns the generic "parallel calls are possible"
+// note into an instruction to fan out and then synthesize.
+const parallelRoutingGuidance = "\n\nFan-out routing: when a request has independent angles or workstreams " +
+	"(for example legal, financial, and technical review of the same material), delegate to EVERY relevant " +
+	"subagent in the same response instead of one at a time, giving each the full context it needs. " +
+	"Each result is labelled with the subagent that produced it. When all reports are back, synthesize " +
+	"them into one answer that attributes findings to their subagent and calls out where they disagree."
+
+// WithParallelRouting configures the router for fan-out: the task tool
+// description tells the router to delegate independent angles of a request
+// to all relevant subagents in a single turn, and each subagent's report is
+// returned labelled with its name so the router's synthesis step can
+// attribute findings.
+//
+// Delegations issued in the same turn already run concurrently; this option
+// changes what the router is asked to do, not how calls are dispatched.
+//
+//	net := network.New("review", "Multi-angle document review", routerP,
+//	    network.WithChildren(legal, finance, tech),
+//	    network.WithParallelRouting(),
+//	)
+func WithParallelRouting() Option {
+	return func(n *Network) { n.parallelRouting = true }
+}
+
+// attributeReport labels a subagent's successful report for the router.
+func attributeReport(agentName, output string) string {
+	return fmt.Sprintf("Report from subagent %q:\n\n%s", agentName, output)
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/nevindra/oasis/core"
 	"github.com/nevindra/oasis/network"
 	"github.com/nevindra/oasis/processor"
+	"github.com/nevindra/oasis/provider"
 	"github.com/nevindra/oasis/ratelimit"
 	"github.com/nevindra/oasis/skills"
 	"github.com/nevindra/oasis/workflow"
@@ -44,6 +45,7 @@ type AnyTool = core.AnyTool
 type Tool[In, Out any] = core.Tool[In, Out]
 type ToolMeta = core.ToolMeta
 type ToolResult = core.ToolResult
+type ToolCache = core.ToolCache
 type UIComponent = core.UIComponent
 type UIRenderable = core.UIRenderable
 type RunOption = core.RunOption
@@ -61,6 +63,13 @@ type Hooks = agent.Hooks
 type Stream = agent.Stream
 type SuspendProtocol[Req, Resp any] = agent.SuspendProtocol[Req, Resp]
 type ErrSuspended = agent.ErrSuspended
+type ErrSchemaValidation = agent.ErrSchemaValidation
+type AttachmentError = agent.AttachmentError
+type ErrBudgetExceeded = agent.ErrBudgetExceeded
+type AgentMiddleware = agent.Middleware
+type Limiter = agent.Limiter
+type RunRecord = core.RunRecord
+type TraceSink = core.TraceSink
 
 // --- Protocol types ---
 
@@ -71,6 +80,8 @@ type StreamEvent = core.StreamEvent
 type StreamEventType = core.StreamEventType
 type FinishReason = core.FinishReason
 type InputHandler = agent.InputHandler
+type InputRequest = agent.InputRequest
+type InputResponse = agent.InputResponse
 
 // --- Constructors ---
 
@@ -91,18 +102,44 @@ func NewToolRegistry() *core.ToolRegistry { return core.NewToolRegistry() }
 // Why: generic funcs can't be aliased as vars.
 func NewProcessorChain() *processor.Chain { return processor.NewChain() }
 
+// Chain wraps a with middleware; the first middleware runs outermost, as with
+// http.Handler chains. See [agent.Chain]. Streaming needs no special support:
+// the stream channel rides in the RunOptions each middleware passes on.
+func Chain(a Agent, mws ...AgentMiddleware) Agent { return agent.Chain(mws...)(a) }
+
 // NewSuspendProtocol declares a typed HITL contract. See [agent.NewSuspendProtocol].
 // Why: generic funcs can't be aliased as vars.
 func NewSuspendProtocol[Req, Resp any](name string) SuspendProtocol[Req, Resp] {
 	return agent.NewSuspendProtocol[Req, Resp](name)
 }
 
+// NewTimeoutInputHandler resolves unanswered questions to a default after a
+// timeout. See [agent.NewTimeoutInputHandler].
+var NewTimeoutInputHandler = agent.NewTimeoutInputHandler
+
 // NewInMemoryToolResultStore returns the default in-process ToolResultStore.
 var NewInMemoryToolResultStore = core.NewInMemoryToolResultStore
 
+// NewInMemoryToolCache returns the default in-process LRU ToolCache.
+var NewInMemoryToolCache = core.NewInMemoryToolCache
+
 // NewID generates a globally unique, time-sortable UUIDv7 (RFC 9562).
 var NewID = core.NewID
 
+// IdempotencyKeyFromContext returns the running tool call's idempotency key.
+// See [core.IdempotencyKeyFromContext].
+var IdempotencyKeyFromContext = core.IdempotencyKeyFromContext
+
+// Once runs a side effect at most once per idempotency key. See [core.Once].
+var Once = core.Once
+
+// PruneOnce deletes Once records older than a given age. See [core.PruneOnce].
+var PruneOnce = core.PruneOnce
+
+// ValidateArgs checks tool call arguments against a Parameters schema and
+// returns a *core.ArgsError on violations. See [core.ValidateArgs].
+var ValidateArgs = core.ValidateArgs
+
 // Spawn runs an Agent in the background and returns an [agent.AgentHandle].
 var Spawn = agent.Spawn
 
@@ -117,7 +154,10 @@ var WithPrompt = agent.WithPrompt
 var WithMemory = agent.WithMemory
 var WithLimits = agent.WithLimits
 var WithGeneration = agent.WithGeneration
+var WithStopSequences = agent.WithStopSequences
+var WithAutoContinue = agent.WithAutoContinue
 var WithResponseSchema = agent.WithResponseSchema
+var WithResponseSchemaRetries = agent.WithResponseSchemaRetries
 var WithDynamicPrompt = agent.WithDynamicPrompt
 var WithDynamicModel = agent.WithDynamicModel
 var WithDynamicTools = agent.WithDynamicTools
@@ -127,14 +167,38 @@ var WithMetadata = agent.WithMetadata
 var WithProcessors = agent.WithProcessors
 var WithHooks = agent.WithHooks
 var WithToolConfig = agent.WithToolConfig
+var WithToolCache = agent.WithToolCache
+var WithToolTimeout = agent.WithToolTimeout
+var WithCircuitBreaker = agent.WithCircuitBreaker
 var Approval = agent.Approval
+var WithToolApproval = agent.WithToolApproval
 var WithInputHandler = agent.WithInputHandler
 var WithMiddleware = agent.WithMiddleware
 var WithSkills = agent.WithSkills
 var WithActiveSkills = agent.WithActiveSkills
 var WithSkillCatalog = agent.WithSkillCatalog
+var WithSkillSearcher = agent.WithSkillSearcher
+var WithDryRun = agent.WithDryRun
+var WithAttachmentTypes = agent.WithAttachmentTypes
+var WithToolCallFallback = agent.WithToolCallFallback
+var WithToolRetry = agent.WithToolRetry
+var WithTraceSink = agent.WithTraceSink
+var NewJSONLTraceSink = agent.NewJSONLTraceSink
+var WithTokenBudget = agent.WithTokenBudget
+var WithMaxParallelTools = agent.WithMaxParallelTools
+var WithSequentialTools = agent.WithSequentialTools
+var WithResponseCache = agent.WithResponseCache
+var WithToolConcurrency = agent.WithToolConcurrency
+var WithToolArgValidation = agent.WithToolArgValidation
 var WithEmbedding = agent.WithEmbedding
 var RetryMiddleware = agent.RetryMiddleware
+var AgentLoggingMiddleware = agent.AgentLoggingMiddleware
+var UserRateLimitMiddleware = agent.UserRateLimitMiddleware
+var AgentRateLimitMiddleware = agent.AgentRateLimitMiddleware
+var ResponseCacheMiddleware = agent.ResponseCacheMiddleware
+var NewTokenBucketLimiter = agent.NewTokenBucketLimiter
+var LimiterClock = agent.LimiterClock
+var ErrUserRateLimited = agent.ErrUserRateLimited
 var WithOverrides = agent.WithOverrides
 
 // --- Run options (per-call) ---
@@ -211,6 +275,26 @@ var RPM = ratelimit.RPM
 // TPM caps tokens per minute (input + output) for [RateLimitMiddleware]. See [ratelimit.TPM].
 var TPM = ratelimit.TPM
 
+// EmbeddingCache stores embedding vectors for [WithEmbeddingCache]. See
+// [provider.EmbeddingCache].
+type EmbeddingCache = provider.EmbeddingCache
+
+// WithEmbeddingCache wraps an EmbeddingProvider so identical texts are
+// embedded once. See [provider.WithEmbeddingCache].
+var WithEmbeddingCache = provider.WithEmbeddingCache
+
+// NewInMemoryEmbeddingCache returns an LRU [EmbeddingCache]. See
+// [provider.NewInMemoryEmbeddingCache].
+var NewInMemoryEmbeddingCache = provider.NewInMemoryEmbeddingCache
+
+// NewStoreEmbeddingCache returns an [EmbeddingCache] persisted in a Store's
+// config table. See [provider.NewStoreEmbeddingCache].
+var NewStoreEmbeddingCache = provider.NewStoreEmbeddingCache
+
+// TruncateEmbedding shortens an EmbeddingProvider's vectors to their
+// leading dimensions. See [provider.TruncateEmbedding].
+var TruncateEmbedding = provider.TruncateEmbedding
+
 // --- Tool helpers ---
 
 // Func creates an [AnyTool] from a plain function. Schema is derived from In
@@ -8,6 +8,7 @@ import (
 	"github.com/nevindra/oasis/agent"
 	"github.com/nevindra/oasis/core"
 	"github.com/nevindra/oasis/network"
+	"github.com/nevindra/oasis/provider"
 	"github.com/nevindra/oasis/ratelimit"
 	"github.com/nevindra/oasis/workflow"
 )
@@ -33,6 +34,27 @@ func TestReexportsNonNil(t *testing.T) {
 		{"WithPrompt", oasis.WithPrompt},
 		{"WithMemory", oasis.WithMemory},
 		{"WithGeneration", oasis.WithGeneration},
+		{"WithStopSequences", oasis.WithStopSequences},
+		{"WithAutoContinue", oasis.WithAutoContinue},
+		{"WithSkillSearcher", oasis.WithSkillSearcher},
+		{"WithDryRun", oasis.WithDryRun},
+		{"WithAttachmentTypes", oasis.WithAttachmentTypes},
+		{"WithToolCallFallback", oasis.WithToolCallFallback},
+		{"WithToolRetry", oasis.WithToolRetry},
+		{"WithTraceSink", oasis.WithTraceSink},
+		{"NewJSONLTraceSink", oasis.NewJSONLTraceSink},
+		{"WithTokenBudget", oasis.WithTokenBudget},
+		{"WithMaxParallelTools", oasis.WithMaxParallelTools},
+		{"WithSequentialTools", oasis.WithSequentialTools},
+		{"WithResponseCache", oasis.WithResponseCache},
+		{"WithToolConcurrency", oasis.WithToolConcurrency},
+		{"WithToolArgValidation", oasis.WithToolArgValidation},
+		{"AgentLoggingMiddleware", oasis.AgentLoggingMiddleware},
+		{"UserRateLimitMiddleware", oasis.UserRateLimitMiddleware},
+		{"AgentRateLimitMiddleware", oasis.AgentRateLimitMiddleware},
+		{"ResponseCacheMiddleware", oasis.ResponseCacheMiddleware},
+		{"NewTokenBucketLimiter", oasis.NewTokenBucketLimiter},
+		{"LimiterClock", oasis.LimiterClock},
 		{"WithStream", oasis.WithStream},
 		{"RateLimitMiddleware", oasis.RateLimitMiddleware},
 		{"RPM", oasis.RPM},
@@ -74,6 +96,13 @@ func TestReexportsIdentity(t *testing.T) {
 		{"ErrorResult", oasis.ErrorResult, core.ErrorResult},
 		{"RawTool", oasis.RawTool, core.RawTool},
 		{"NewID", oasis.NewID, core.NewID},
+		{"IdempotencyKeyFromContext", oasis.IdempotencyKeyFromContext, core.IdempotencyKeyFromContext},
+		{"Once", oasis.Once, core.Once},
+		{"PruneOnce", oasis.PruneOnce, core.PruneOnce},
+		{"ValidateArgs", oasis.ValidateArgs, core.ValidateArgs},
+		{"NewTimeoutInputHandler", oasis.NewTimeoutInputHandler, agent.NewTimeoutInputHandler},
+		{"WithEmbeddingCache", oasis.WithEmbeddingCache, provider.WithEmbeddingCache},
+		{"TruncateEmbedding", oasis.TruncateEmbedding, provider.TruncateEmbedding},
 	}
 	for _, c := range cases {
 		got := reflect.ValueOf(c.reexport).Pointer()
@@ -0,0 +1,250 @@
+package observer
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nevindra/oasis/agent"
+	oasis "github.com/nevindra/oasis/core"
+)
+
+// DefaultLedgerKey is the config key UsageLedger.Save and Load use when no
+// key is given.
+const DefaultLedgerKey = "observer.usage_ledger"
+
+// defaultLedgerEntries is how many users, and separately how many threads,
+// a UsageLedger keeps totals for when WithLedgerMaxEntries is not given.
+const defaultLedgerEntries = 10000
+
+// UsageSummary is the accumulated usage for one user or thread.
+type UsageSummary struct {
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CachedTokens int     `json:"cached_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+func (s *UsageSummary) add(u oasis.Usage, cost float64) {
+	s.Requests++
+	s.InputTokens += u.InputTokens
+	s.OutputTokens += u.OutputTokens
+	s.CachedTokens += u.CachedTokens
+	s.CostUSD += cost
+}
+
+// UsageRecord is one LLM call as seen by the ledger. UserID and ThreadID
+// are empty when the call ran outside an agent task.
+type UsageRecord struct {
+	UserID   string
+	ThreadID string
+	Model    string
+	Usage    oasis.Usage
+	CostUSD  float64
+	Time     time.Time
+}
+
+// ConfigStore is the subset of core.Store the ledger persists to. Any
+// core.Store satisfies it.
+type ConfigStore interface {
+	GetConfig(ctx context.Context, key string) (string, error)
+	SetConfig(ctx context.Context, key, value string) error
+}
+
+// LedgerOption configures a UsageLedger.
+type LedgerOption func(*UsageLedger)
+
+// WithLedgerHook registers fn to be called with every recorded call, after
+// the in-memory totals are updated. Use it to append records to your own
+// billing table. fn runs on the provider's goroutine; keep it fast.
+func WithLedgerHook(fn func(ctx context.Context, rec UsageRecord)) LedgerOption {
+	return func(l *UsageLedger) { l.hook = fn }
+}
+
+// WithLedgerMaxEntries caps how many users, and separately how many threads,
+// the ledger keeps totals for (default 10000). Past the cap, the entry
+// updated least recently is dropped. Use WithLedgerHook to keep a complete
+// record.
+func WithLedgerMaxEntries(n int) LedgerOption {
+	return func(l *UsageLedger) { l.max = n }
+}
+
+// UsageLedger rolls token usage and cost up per user and per thread for
+// chargeback. Attach it to Instruments.Ledger; ObservedProvider then records
+// every call, reading the user and thread from the AgentTask on the call's
+// context (agent.TaskFromContext). Calls without a task, or with empty IDs,
+// count toward no user or thread.
+//
+// Totals live in memory, for at most WithLedgerMaxEntries users and threads
+// each. Save and Load persist them through the store's config table so they
+// survive restarts. Safe for concurrent use.
+type UsageLedger struct {
+	mu       sync.Mutex
+	max      int
+	byUser   *usageTotals
+	byThread *usageTotals
+	hook     func(ctx context.Context, rec UsageRecord)
+}
+
+// NewUsageLedger creates an empty ledger.
+func NewUsageLedger(opts ...LedgerOption) *UsageLedger {
+	l := &UsageLedger{max: defaultLedgerEntries}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.max <= 0 {
+		l.max = defaultLedgerEntries
+	}
+	l.byUser = newUsageTotals(l.max)
+	l.byThread = newUsageTotals(l.max)
+	return l
+}
+
+// Record adds one call's usage and cost, attributed to the task on ctx.
+func (l *UsageLedger) Record(ctx context.Context, model string, usage oasis.Usage, cost float64) {
+	rec := UsageRecord{Model: model, Usage: usage, CostUSD: cost, Time: time.Now()}
+	if task, ok := agent.TaskFromContext(ctx); ok {
+		rec.UserID = task.UserID
+		rec.ThreadID = task.ThreadID
+	}
+
+	l.mu.Lock()
+	if rec.UserID != "" {
+		l.byUser.add(rec.UserID, usage, cost)
+	}
+	if rec.ThreadID != "" {
+		l.byThread.add(rec.ThreadID, usage, cost)
+	}
+	l.mu.Unlock()
+
+	if l.hook != nil {
+		l.hook(ctx, rec)
+	}
+}
+
+// ByUser returns the accumulated usage for userID.
+func (l *UsageLedger) ByUser(userID string) UsageSummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.byUser.get(userID)
+}
+
+// ByThread returns the accumulated usage for threadID.
+func (l *UsageLedger) ByThread(threadID string) UsageSummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.byThread.get(threadID)
+}
+
+// ledgerSnapshot is the persisted form of a UsageLedger.
+type ledgerSnapshot struct {
+	Users   map[string]UsageSummary `json:"users"`
+	Threads map[string]UsageSummary `json:"threads"`
+}
+
+// Save writes the current totals to store under key (DefaultLedgerKey when
+// empty) as JSON. Call it periodically or on shutdown.
+func (l *UsageLedger) Save(ctx context.Context, store ConfigStore, key string) error {
+	if key == "" {
+		key = DefaultLedgerKey
+	}
+	l.mu.Lock()
+	data, err := json.Marshal(ledgerSnapshot{Users: l.byUser.snapshot(), Threads: l.byThread.snapshot()})
+	l.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshal usage ledger: %w", err)
+	}
+	if err := store.SetConfig(ctx, key, string(data)); err != nil {
+		return fmt.Errorf("save usage ledger: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the in-memory totals with the snapshot stored under key
+// (DefaultLedgerKey when empty). A missing key leaves the ledger empty.
+// Call it once at startup, before traffic.
+func (l *UsageLedger) Load(ctx context.Context, store ConfigStore, key string) error {
+	if key == "" {
+		key = DefaultLedgerKey
+	}
+	raw, err := store.GetConfig(ctx, key)
+	if err != nil {
+		return fmt.Errorf("load usage ledger: %w", err)
+	}
+	snap := ledgerSnapshot{}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &snap); err != nil {
+			return fmt.Errorf("decode usage ledger: %w", err)
+		}
+	}
+	l.mu.Lock()
+	l.byUser = newUsageTotals(l.max)
+	l.byThread = newUsageTotals(l.max)
+	for id, s := range snap.Users {
+		l.byUser.put(id, s)
+	}
+	for id, s := range snap.Threads {
+		l.byThread.put(id, s)
+	}
+	l.mu.Unlock()
+	return nil
+}
+
+// usageTotals is an LRU map from user or thread ID to its UsageSummary,
+// holding at most max entries. The owning UsageLedger's mutex guards it.
+type usageTotals struct {
+	entries map[string]*list.Element
+	lru     *list.List // front = most recently updated
+	max     int
+}
+
+type usageEntry struct {
+	id  string
+	sum UsageSummary
+}
+
+func newUsageTotals(max int) *usageTotals {
+	return &usageTotals{entries: make(map[string]*list.Element), lru: list.New(), max: max}
+}
+
+func (t *usageTotals) get(id string) UsageSummary {
+	if el, ok := t.entries[id]; ok {
+		return el.Value.(*usageEntry).sum
+	}
+	return UsageSummary{}
+}
+
+// add accumulates one call into id's totals and marks id most recent.
+func (t *usageTotals) add(id string, u oasis.Usage, cost float64) {
+	s := t.get(id)
+	s.add(u, cost)
+	t.put(id, s)
+}
+
+// put sets id's totals, evicting the least recently updated entry when the
+// map is full.
+func (t *usageTotals) put(id string, s UsageSummary) {
+	if el, ok := t.entries[id]; ok {
+		el.Value.(*usageEntry).sum = s
+		t.lru.MoveToFront(el)
+		return
+	}
+	t.entries[id] = t.lru.PushFront(&usageEntry{id: id, sum: s})
+	for t.lru.Len() > t.max {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.entries, oldest.Value.(*usageEntry).id)
+	}
+}
+
+func (t *usageTotals) snapshot() map[string]UsageSummary {
+	out := make(map[string]UsageSummary, len(t.entries))
+	for id, el := range t.entries {
+		out[id] = el.Value.(*usageEntry).sum
+	}
+	return out
+}
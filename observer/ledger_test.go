@@ -0,0 +1,118 @@
+package observer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nevindra/oasis/agent"
+	oasis "github.com/nevindra/oasis/core"
+)
+
+// mapConfigStore is an in-memory ConfigStore.
+type mapConfigStore map[string]string
+
+func (m mapConfigStore) GetConfig(_ context.Context, key string) (string, error) {
+	return m[key], nil
+}
+
+func (m mapConfigStore) SetConfig(_ context.Context, key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func TestUsageLedgerFromAgentTask(t *testing.T) {
+	inst := testInstruments(t)
+	inst.Cost = NewCostCalculator(map[string]oasis.ModelPricing{
+		"m": {InputPerMillion: 1, OutputPerMillion: 2},
+	})
+	var hooked []UsageRecord
+	inst.Ledger = NewUsageLedger(WithLedgerHook(func(_ context.Context, rec UsageRecord) {
+		hooked = append(hooked, rec)
+	}))
+
+	p := WrapProvider(&mockProvider{name: "p", chatResp: oasis.ChatResponse{
+		Content: "hi",
+		Usage:   oasis.Usage{InputTokens: 1000, OutputTokens: 500},
+	}}, "m", inst)
+	a := agent.New("a", "test", p)
+
+	for _, task := range []agent.AgentTask{
+		{Input: "one", UserID: "alice", ThreadID: "t1"},
+		{Input: "two", UserID: "alice", ThreadID: "t2"},
+		{Input: "three", UserID: "bob", ThreadID: "t2"},
+	} {
+		if _, err := a.Execute(context.Background(), task); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	}
+
+	alice := inst.Ledger.ByUser("alice")
+	if alice.Requests != 2 || alice.InputTokens != 2000 || alice.OutputTokens != 1000 {
+		t.Errorf("alice = %+v", alice)
+	}
+	if want := 0.004; alice.CostUSD < want-1e-9 || alice.CostUSD > want+1e-9 {
+		t.Errorf("alice cost = %v, want %v", alice.CostUSD, want)
+	}
+	if t2 := inst.Ledger.ByThread("t2"); t2.Requests != 2 {
+		t.Errorf("t2 = %+v", t2)
+	}
+	if got := inst.Ledger.ByUser("nobody"); got != (UsageSummary{}) {
+		t.Errorf("unknown user = %+v", got)
+	}
+	if len(hooked) != 3 || hooked[2].UserID != "bob" || hooked[2].Model != "m" {
+		t.Errorf("hook records = %+v", hooked)
+	}
+}
+
+func TestUsageLedgerSaveLoad(t *testing.T) {
+	ctx := context.Background()
+	store := mapConfigStore{}
+
+	l := NewUsageLedger()
+	l.Record(agent.WithTaskContext(ctx, agent.AgentTask{UserID: "u", ThreadID: "t"}), "m",
+		oasis.Usage{InputTokens: 10, OutputTokens: 5}, 0.5)
+	if err := l.Save(ctx, store, ""); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, ok := store[DefaultLedgerKey]; !ok {
+		t.Fatalf("snapshot not written under %q", DefaultLedgerKey)
+	}
+
+	restored := NewUsageLedger()
+	if err := restored.Load(ctx, store, ""); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := restored.ByUser("u"); got.Requests != 1 || got.InputTokens != 10 || got.CostUSD != 0.5 {
+		t.Errorf("restored user = %+v", got)
+	}
+	if got := restored.ByThread("t"); got.OutputTokens != 5 {
+		t.Errorf("restored thread = %+v", got)
+	}
+
+	// A missing key is an empty ledger, not an error.
+	if err := NewUsageLedger().Load(ctx, mapConfigStore{}, ""); err != nil {
+		t.Errorf("Load missing key: %v", err)
+	}
+}
+
+func TestUsageLedgerEvictsLeastRecentlyUpdated(t *testing.T) {
+	ctx := context.Background()
+	l := NewUsageLedger(WithLedgerMaxEntries(2))
+	record := func(user string) {
+		l.Record(agent.WithTaskContext(ctx, agent.AgentTask{UserID: user}), "m", oasis.Usage{InputTokens: 1}, 0)
+	}
+	record("a")
+	record("b")
+	record("a") // b is now least recently updated
+	record("c")
+
+	if got := l.ByUser("b"); got != (UsageSummary{}) {
+		t.Errorf("b = %+v, want evicted", got)
+	}
+	if got := l.ByUser("a"); got.Requests != 2 {
+		t.Errorf("a = %+v, want 2 requests", got)
+	}
+	if got := l.ByUser("c"); got.Requests != 1 {
+		t.Errorf("c = %+v, want 1 request", got)
+	}
+}
@@ -47,6 +47,10 @@ type Instruments struct {
 	EmbedDuration metric.Float64Histogram
 
 	Cost *CostCalculator
+
+	// Ledger, when set, receives every LLM call's usage and cost from
+	// ObservedProvider for per-user and per-thread rollups. Nil disables it.
+	Ledger *UsageLedger
 }
 
 // Init sets up OTEL trace, metric, and log providers with OTLP HTTP exporters.
@@ -123,10 +127,17 @@ func Init(ctx context.Context, pricing map[string]oasis.ModelPricing) (*Instrume
 // wires trace+metric+log exporters to one endpoint. Signals whose global
 // provider was never configured degrade to no-ops.
 func NewInstruments(pricing map[string]oasis.ModelPricing) (*Instruments, error) {
-	tracer := otel.Tracer(scopeName)
-	meter := otel.Meter(scopeName)
-	logger := global.GetLoggerProvider().Logger(scopeName)
+	return newInstruments(
+		otel.Tracer(scopeName),
+		otel.Meter(scopeName),
+		global.GetLoggerProvider().Logger(scopeName),
+		pricing,
+	)
+}
 
+// newInstruments creates every counter and histogram on meter. Shared by the
+// OTLP and Prometheus constructors so both backends expose the same signals.
+func newInstruments(tracer trace.Tracer, meter metric.Meter, logger oasislog.Logger, pricing map[string]oasis.ModelPricing) (*Instruments, error) {
 	tokenUsage, err := meter.Int64Counter("llm.token.usage",
 		metric.WithDescription("Total tokens consumed"),
 		metric.WithUnit("{token}"))
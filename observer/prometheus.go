@@ -0,0 +1,217 @@
+package observer
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	oasis "github.com/nevindra/oasis/core"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log/global"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// prometheusContentType is the Prometheus text exposition format, version 0.0.4.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// NewPrometheusInstruments builds Instruments whose metrics are served in the
+// Prometheus text format instead of pushed over OTLP. The returned handler
+// renders the current values on every scrape; mount it at /metrics.
+//
+// The instruments are the same type Init returns, so WrapProvider, WrapTool
+// and WrapEmbedding work unchanged: token usage, cost (from pricing), request
+// and execution counts with their status label, and duration histograms, all
+// labelled by model, provider and tool name. Metric and label names have dots
+// replaced by underscores, and counters gain a _total suffix
+// (llm.token.usage → llm_token_usage_total).
+//
+// Metrics use a private MeterProvider, so nothing global is replaced. Spans
+// and logs still go to the global OTEL providers (no-ops unless the host
+// configured them).
+func NewPrometheusInstruments(pricing map[string]oasis.ModelPricing) (*Instruments, http.Handler, error) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	inst, err := newInstruments(
+		otel.Tracer(scopeName),
+		mp.Meter(scopeName),
+		global.GetLoggerProvider().Logger(scopeName),
+		pricing,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inst, &prometheusHandler{reader: reader}, nil
+}
+
+// prometheusHandler collects from a ManualReader and writes the text format.
+type prometheusHandler struct {
+	reader *sdkmetric.ManualReader
+}
+
+func (h *prometheusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var rm metricdata.ResourceMetrics
+	if err := h.reader.Collect(r.Context(), &rm); err != nil {
+		http.Error(w, "collect metrics: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", prometheusContentType)
+	bw := bufio.NewWriter(w)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			writePrometheusMetric(bw, m)
+		}
+	}
+	_ = bw.Flush()
+}
+
+// writePrometheusMetric writes one metric family. Aggregations with no
+// Prometheus equivalent (exponential histograms, summaries) are skipped;
+// the observer never creates them.
+func writePrometheusMetric(w *bufio.Writer, m metricdata.Metrics) {
+	name := prometheusName(m.Name)
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		writeSum(w, name, m.Description, data.IsMonotonic, data.DataPoints)
+	case metricdata.Sum[float64]:
+		writeSum(w, name, m.Description, data.IsMonotonic, data.DataPoints)
+	case metricdata.Gauge[int64]:
+		writeHeader(w, name, m.Description, "gauge")
+		writePoints(w, name, data.DataPoints)
+	case metricdata.Gauge[float64]:
+		writeHeader(w, name, m.Description, "gauge")
+		writePoints(w, name, data.DataPoints)
+	case metricdata.Histogram[int64]:
+		writeHistogram(w, name, m.Description, data.DataPoints)
+	case metricdata.Histogram[float64]:
+		writeHistogram(w, name, m.Description, data.DataPoints)
+	}
+}
+
+func writeSum[N int64 | float64](w *bufio.Writer, name, help string, monotonic bool, points []metricdata.DataPoint[N]) {
+	typ := "gauge"
+	if monotonic {
+		typ = "counter"
+		if !strings.HasSuffix(name, "_total") {
+			name += "_total"
+		}
+	}
+	writeHeader(w, name, help, typ)
+	writePoints(w, name, points)
+}
+
+func writePoints[N int64 | float64](w *bufio.Writer, name string, points []metricdata.DataPoint[N]) {
+	sorted := make([]metricdata.DataPoint[N], len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Attributes.Encoded(attribute.DefaultEncoder()) < sorted[j].Attributes.Encoded(attribute.DefaultEncoder())
+	})
+	for _, dp := range sorted {
+		fmt.Fprintf(w, "%s%s %s\n", name, prometheusLabels(dp.Attributes, ""), formatFloat(float64(dp.Value)))
+	}
+}
+
+func writeHistogram[N int64 | float64](w *bufio.Writer, name, help string, points []metricdata.HistogramDataPoint[N]) {
+	writeHeader(w, name, help, "histogram")
+	sorted := make([]metricdata.HistogramDataPoint[N], len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Attributes.Encoded(attribute.DefaultEncoder()) < sorted[j].Attributes.Encoded(attribute.DefaultEncoder())
+	})
+	for _, dp := range sorted {
+		// OTEL bucket counts are per-bucket; Prometheus buckets are cumulative.
+		var cumulative uint64
+		for i, bound := range dp.Bounds {
+			if i < len(dp.BucketCounts) {
+				cumulative += dp.BucketCounts[i]
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, prometheusLabels(dp.Attributes, formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, prometheusLabels(dp.Attributes, "+Inf"), dp.Count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, prometheusLabels(dp.Attributes, ""), formatFloat(float64(dp.Sum)))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, prometheusLabels(dp.Attributes, ""), dp.Count)
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help, typ string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// prometheusLabels renders attrs as {k="v",...}. A non-empty le adds the
+// histogram bucket label.
+func prometheusLabels(attrs attribute.Set, le string) string {
+	if attrs.Len() == 0 && le == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	iter := attrs.Iter()
+	for iter.Next() {
+		kv := iter.Attribute()
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		b.WriteString(prometheusName(string(kv.Key)))
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(kv.Value.Emit()))
+		b.WriteByte('"')
+	}
+	if le != "" {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`le="`)
+		b.WriteString(le)
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// prometheusName maps an OTEL name onto [a-zA-Z_:][a-zA-Z0-9_:]*.
+func prometheusName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(s string) string { return labelValueEscaper.Replace(s) }
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
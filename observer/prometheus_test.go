@@ -0,0 +1,68 @@
+package observer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+func TestPrometheusInstruments(t *testing.T) {
+	inst, handler, err := NewPrometheusInstruments(map[string]oasis.ModelPricing{
+		"m": {InputPerMillion: 1, OutputPerMillion: 2},
+	})
+	if err != nil {
+		t.Fatalf("NewPrometheusInstruments: %v", err)
+	}
+
+	op := WrapProvider(&mockProvider{name: "p", chatResp: oasis.ChatResponse{
+		Usage: oasis.Usage{InputTokens: 1000, OutputTokens: 500},
+	}}, "m", inst)
+	if _, err := oasis.Chat(context.Background(), op, oasis.ChatRequest{}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	tool := WrapTool(&mockTool{
+		def:    oasis.ToolDefinition{Name: "search"},
+		result: oasis.ToolResult{Error: "not found"},
+	}, inst)
+	if _, err := tool.ExecuteRaw(context.Background(), nil); err != nil {
+		t.Fatalf("ExecuteRaw: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE llm_token_usage_total counter",
+		`llm_token_usage_total{direction="input",llm_model="m",llm_provider="p"} 1000`,
+		`llm_token_usage_total{direction="output",llm_model="m",llm_provider="p"} 500`,
+		`llm_cost_total{llm_method="chat_stream",llm_model="m",llm_provider="p"} 0.002`,
+		`llm_requests_total{llm_method="chat_stream",llm_model="m",llm_provider="p",status="ok"} 1`,
+		"# TYPE llm_duration histogram",
+		`llm_duration_bucket{llm_method="chat_stream",llm_model="m",llm_provider="p",le="+Inf"} 1`,
+		`tool_executions_total{status="tool_error",tool_name="search"} 1`,
+		`tool_duration_count{tool_name="search"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in scrape:\n%s", want, body)
+		}
+	}
+}
+
+func TestPrometheusLabelEscaping(t *testing.T) {
+	if got := prometheusName("llm.token.usage"); got != "llm_token_usage" {
+		t.Errorf("prometheusName = %q", got)
+	}
+	if got := escapeLabelValue("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabelValue = %q", got)
+	}
+}
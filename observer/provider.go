@@ -160,6 +160,9 @@ func (o *ObservedProvider) record(ctx context.Context, span trace.Span, method,
 		attribute.String("status", status),
 	))
 	o.inst.LLMDuration.Record(ctx, durationMs, attrs)
+	if o.inst.Ledger != nil {
+		o.inst.Ledger.Record(ctx, o.model, usage, cost)
+	}
 
 	// Structured log
 	var rec oasislog.Record
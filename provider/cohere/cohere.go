@@ -0,0 +1,155 @@
+// Package cohere provides a rag.Reranker backed by Cohere's rerank endpoint.
+//
+// It only reranks; pair it with any EmbeddingProvider (for example
+// provider/voyage) for the first-stage vector search:
+//
+//	retriever := rag.NewHybridRetriever(store, embedding,
+//	    rag.WithReranker(cohere.New(os.Getenv("COHERE_API_KEY"))))
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	oasis "github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/rag"
+)
+
+const (
+	defaultBaseURL = "https://api.cohere.com/v2"
+	defaultModel   = "rerank-v3.5"
+)
+
+// Reranker implements rag.Reranker with Cohere's /rerank API. Results are
+// re-scored with Cohere's relevance score in [0, 1], which replaces
+// RetrievalResult.Score. Safe for concurrent use.
+type Reranker struct {
+	apiKey          string
+	model           string
+	baseURL         string
+	client          *http.Client
+	name            string
+	maxTokensPerDoc int
+}
+
+var _ rag.Reranker = (*Reranker)(nil)
+
+// New creates a Cohere reranker.
+//
+//	cohere.New(key,
+//	    cohere.WithModel("rerank-multilingual-v3.0"),
+//	    cohere.WithHTTPClient(myClient),
+//	)
+func New(apiKey string, opts ...Option) *Reranker {
+	r := &Reranker{
+		apiKey:  apiKey,
+		model:   defaultModel,
+		baseURL: defaultBaseURL,
+		client:  &http.Client{},
+		name:    "cohere",
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.baseURL = strings.TrimRight(r.baseURL, "/")
+	return r
+}
+
+// Name returns the reranker name (default "cohere", overridable via WithName).
+func (r *Reranker) Name() string { return r.name }
+
+// Model returns the rerank model name.
+func (r *Reranker) Model() string { return r.model }
+
+type rerankRequest struct {
+	Model           string   `json:"model"`
+	Query           string   `json:"query"`
+	Documents       []string `json:"documents"`
+	TopN            int      `json:"top_n,omitempty"`
+	MaxTokensPerDoc int      `json:"max_tokens_per_doc,omitempty"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float32 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// Rerank scores each result's Content against query and returns the topK
+// most relevant, sorted by score descending. topK <= 0 returns all results.
+func (r *Reranker) Rerank(ctx context.Context, query string, results []rag.RetrievalResult, topK int) ([]rag.RetrievalResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+	docs := make([]string, len(results))
+	for i, res := range results {
+		docs[i] = res.Content
+	}
+	req := rerankRequest{
+		Model:           r.model,
+		Query:           query,
+		Documents:       docs,
+		MaxTokensPerDoc: r.maxTokensPerDoc,
+	}
+	if topK > 0 && topK < len(results) {
+		req.TopN = topK
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, r.wrapErr("marshal rerank request: " + err.Error())
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"/rerank", bytes.NewReader(payload))
+	if err != nil {
+		return nil, r.wrapErr("create rerank request: " + err.Error())
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, r.wrapErr("rerank request failed: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, r.wrapErr("read rerank response: " + err.Error())
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &oasis.ErrHTTP{
+			Status:     resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: oasis.ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	var parsed rerankResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, r.wrapErr("decode rerank response: " + err.Error())
+	}
+	out := make([]rag.RetrievalResult, 0, len(parsed.Results))
+	for _, pr := range parsed.Results {
+		if pr.Index < 0 || pr.Index >= len(results) {
+			return nil, r.wrapErr("rerank response references unknown document index")
+		}
+		res := results[pr.Index]
+		res.Score = pr.RelevanceScore
+		out = append(out, res)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if topK > 0 && len(out) > topK {
+		out = out[:topK]
+	}
+	return out, nil
+}
+
+func (r *Reranker) wrapErr(msg string) error {
+	return &oasis.ErrLLM{Provider: r.name, Message: msg}
+}
@@ -0,0 +1,70 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	oasis "github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/rag"
+)
+
+func TestNew_Defaults(t *testing.T) {
+	r := New("key")
+	if r.Name() != "cohere" || r.Model() != defaultModel || r.baseURL != defaultBaseURL {
+		t.Errorf("defaults = %q %q %q", r.Name(), r.Model(), r.baseURL)
+	}
+	r = New("key", WithName("cohere-eu"), WithModel("rerank-english-v3.0"), WithBaseURL("http://x/v2/"))
+	if r.Name() != "cohere-eu" || r.Model() != "rerank-english-v3.0" || r.baseURL != "http://x/v2" {
+		t.Errorf("options = %q %q %q", r.Name(), r.Model(), r.baseURL)
+	}
+}
+
+func TestRerank(t *testing.T) {
+	var got rerankRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer key" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"id":"x","results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.4}]}`))
+	}))
+	defer srv.Close()
+
+	r := New("key", WithBaseURL(srv.URL), WithMaxTokensPerDoc(512))
+	in := []rag.RetrievalResult{
+		{ChunkID: "a", Content: "alpha", Score: 0.8},
+		{ChunkID: "b", Content: "beta", Score: 0.7},
+		{ChunkID: "c", Content: "gamma", Score: 0.6},
+	}
+	out, err := r.Rerank(context.Background(), "which?", in, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Query != "which?" || len(got.Documents) != 3 || got.TopN != 2 || got.MaxTokensPerDoc != 512 || got.Model != defaultModel {
+		t.Errorf("request = %+v", got)
+	}
+	if len(out) != 2 || out[0].ChunkID != "c" || out[0].Score != 0.9 || out[1].ChunkID != "a" {
+		t.Errorf("out = %+v", out)
+	}
+}
+
+func TestRerank_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := New("key", WithBaseURL(srv.URL)).Rerank(context.Background(), "q", []rag.RetrievalResult{{Content: "x"}}, 1)
+	var httpErr *oasis.ErrHTTP
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusTooManyRequests || httpErr.RetryAfter == 0 {
+		t.Fatalf("err = %v", err)
+	}
+}
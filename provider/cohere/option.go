@@ -0,0 +1,33 @@
+package cohere
+
+import "net/http"
+
+// Option configures a Cohere Reranker.
+type Option func(*Reranker)
+
+// WithModel sets the rerank model (default "rerank-v3.5").
+func WithModel(model string) Option {
+	return func(r *Reranker) { r.model = model }
+}
+
+// WithBaseURL overrides the API base (default "https://api.cohere.com/v2"),
+// e.g. for a proxy or a private deployment. The /rerank path is appended.
+func WithBaseURL(url string) Option {
+	return func(r *Reranker) { r.baseURL = url }
+}
+
+// WithHTTPClient sets a custom HTTP client (e.g. for timeouts, proxies, or testing).
+func WithHTTPClient(c *http.Client) Option {
+	return func(r *Reranker) { r.client = c }
+}
+
+// WithName overrides the name returned by Name() (default "cohere").
+func WithName(name string) Option {
+	return func(r *Reranker) { r.name = name }
+}
+
+// WithMaxTokensPerDoc truncates each document to n tokens on the server
+// before scoring. 0 uses the API default (4096).
+func WithMaxTokensPerDoc(n int) Option {
+	return func(r *Reranker) { r.maxTokensPerDoc = n }
+}
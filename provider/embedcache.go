@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// EmbeddingCache stores embedding vectors by key. Keys are opaque
+// fixed-length strings derived from the text, the model, and the
+// dimensions; see WithEmbeddingCache.
+//
+// Implementations must be safe for concurrent use. A cache is best effort:
+// Get reports a miss and Set drops the vector when the backend fails, so a
+// cache outage costs API calls, never an Embed error.
+type EmbeddingCache interface {
+	Get(ctx context.Context, key string) ([]float32, bool)
+	Set(ctx context.Context, key string, vec []float32)
+}
+
+// EmbeddingCacheOption configures WithEmbeddingCache.
+type EmbeddingCacheOption func(*cachedEmbedding)
+
+// EmbeddingCacheModel sets the model name in the cache key. Needed only for
+// providers without a Model() string method; the built-in gemini and
+// openaicompat embeddings have one.
+func EmbeddingCacheModel(model string) EmbeddingCacheOption {
+	return func(c *cachedEmbedding) { c.model = model }
+}
+
+// WithEmbeddingCache wraps inner so identical texts are embedded once: each
+// text's vector is looked up in cache and only the misses are sent to
+// inner, in one call. The returned vectors keep the input order.
+//
+// The cache key hashes the text together with inner's Name, model, and
+// Dimensions, so switching models or dimensions never returns stale
+// vectors. The model comes from EmbeddingCacheModel or, failing that, a
+// Model() string method on inner.
+//
+//	emb := provider.WithEmbeddingCache(gemini.NewEmbedding(key, model, 768),
+//	    provider.NewInMemoryEmbeddingCache(10000))
+//
+// If inner is also a core.MultimodalEmbeddingProvider, so is the result;
+// EmbedMultimodal calls pass through uncached.
+func WithEmbeddingCache(inner core.EmbeddingProvider, cache EmbeddingCache, opts ...EmbeddingCacheOption) core.EmbeddingProvider {
+	c := &cachedEmbedding{inner: inner, cache: cache}
+	if m, ok := inner.(interface{ Model() string }); ok {
+		c.model = m.Model()
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if mm, ok := inner.(core.MultimodalEmbeddingProvider); ok {
+		return &cachedMultimodalEmbedding{cachedEmbedding: c, multimodal: mm}
+	}
+	return c
+}
+
+type cachedEmbedding struct {
+	inner core.EmbeddingProvider
+	cache EmbeddingCache
+	model string
+}
+
+func (c *cachedEmbedding) Name() string    { return c.inner.Name() }
+func (c *cachedEmbedding) Dimensions() int { return c.inner.Dimensions() }
+
+// Model reports the model in the cache key, so stacked caches key alike.
+func (c *cachedEmbedding) Model() string { return c.model }
+
+func (c *cachedEmbedding) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+	// Texts to embed, each once, and the positions that need each.
+	var misses []string
+	missAt := make(map[string][]int)
+	for i, text := range texts {
+		keys[i] = c.key(text)
+		if vec, ok := c.cache.Get(ctx, keys[i]); ok {
+			out[i] = vec
+			continue
+		}
+		if _, seen := missAt[text]; !seen {
+			misses = append(misses, text)
+		}
+		missAt[text] = append(missAt[text], i)
+	}
+	if len(misses) == 0 {
+		return out, nil
+	}
+
+	vecs, err := c.inner.Embed(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(misses) {
+		return nil, fmt.Errorf("embedding cache: %s returned %d vectors for %d texts", c.inner.Name(), len(vecs), len(misses))
+	}
+	for j, text := range misses {
+		at := missAt[text]
+		c.cache.Set(ctx, keys[at[0]], vecs[j])
+		for _, i := range at {
+			out[i] = vecs[j]
+		}
+	}
+	return out, nil
+}
+
+// cachedMultimodalEmbedding is a cachedEmbedding over an inner provider
+// that also embeds multimodal inputs. Those bypass the cache: their
+// attachments would have to be hashed into the key.
+type cachedMultimodalEmbedding struct {
+	*cachedEmbedding
+	multimodal core.MultimodalEmbeddingProvider
+}
+
+func (c *cachedMultimodalEmbedding) EmbedMultimodal(ctx context.Context, inputs []core.MultimodalInput) ([][]float32, error) {
+	return c.multimodal.EmbedMultimodal(ctx, inputs)
+}
+
+func (c *cachedEmbedding) key(text string) string {
+	h := sha256.New()
+	h.Write([]byte(c.inner.Name()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.model))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(c.inner.Dimensions())))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewInMemoryEmbeddingCache returns an in-process LRU EmbeddingCache holding
+// up to maxEntries vectors (default 10000 when maxEntries <= 0).
+func NewInMemoryEmbeddingCache(maxEntries int) EmbeddingCache {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &lruEmbeddingCache{
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+type lruEmbeddingEntry struct {
+	key string
+	vec []float32
+}
+
+type lruEmbeddingCache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List // front = most recently used
+	maxEntries int
+}
+
+func (c *lruEmbeddingCache) Get(_ context.Context, key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*lruEmbeddingEntry).vec, true
+}
+
+func (c *lruEmbeddingCache) Set(_ context.Context, key string, vec []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruEmbeddingEntry).vec = vec
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&lruEmbeddingEntry{key: key, vec: vec})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEmbeddingEntry).key)
+	}
+}
+
+// ConfigStore is the subset of core.Store that NewStoreEmbeddingCache
+// persists to. Any core.Store satisfies it.
+type ConfigStore interface {
+	GetConfig(ctx context.Context, key string) (string, error)
+	SetConfig(ctx context.Context, key, value string) error
+}
+
+// embeddingCacheKeyPrefix namespaces cached vectors in the config table.
+const embeddingCacheKeyPrefix = "embedding_cache:"
+
+// NewStoreEmbeddingCache returns an EmbeddingCache that persists vectors in
+// the store's key-value config table, so they survive restarts and are
+// shared by every process on the same database. Entries are never evicted.
+// Store errors are logged with slog.Default and treated as misses.
+// To keep hot texts in memory too, stack two layers:
+//
+//	emb = provider.WithEmbeddingCache(
+//	    provider.WithEmbeddingCache(emb, provider.NewStoreEmbeddingCache(store)),
+//	    provider.NewInMemoryEmbeddingCache(0))
+func NewStoreEmbeddingCache(store ConfigStore) EmbeddingCache {
+	return storeEmbeddingCache{store: store}
+}
+
+type storeEmbeddingCache struct{ store ConfigStore }
+
+func (c storeEmbeddingCache) Get(ctx context.Context, key string) ([]float32, bool) {
+	raw, err := c.store.GetConfig(ctx, embeddingCacheKeyPrefix+key)
+	if err != nil {
+		slog.Default().Warn("embedding cache: read failed", "key", key, "error", err)
+		return nil, false
+	}
+	if raw == "" {
+		return nil, false
+	}
+	b, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil || len(b)%4 != 0 {
+		return nil, false
+	}
+	vec := make([]float32, len(b)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return vec, true
+}
+
+func (c storeEmbeddingCache) Set(ctx context.Context, key string, vec []float32) {
+	b := make([]byte, len(vec)*4)
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(v))
+	}
+	if err := c.store.SetConfig(ctx, embeddingCacheKeyPrefix+key, base64.StdEncoding.EncodeToString(b)); err != nil {
+		slog.Default().Warn("embedding cache: write failed", "key", key, "error", err)
+	}
+}
+
+// Compile-time interface satisfaction checks.
+var (
+	_ core.EmbeddingProvider           = (*cachedEmbedding)(nil)
+	_ core.MultimodalEmbeddingProvider = (*cachedMultimodalEmbedding)(nil)
+	_ EmbeddingCache                   = (*lruEmbeddingCache)(nil)
+	_ EmbeddingCache                   = storeEmbeddingCache{}
+)
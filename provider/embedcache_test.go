@@ -0,0 +1,134 @@
+package provider_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/provider"
+)
+
+// countingEmbedding embeds each text as {len(text), dims} and records the
+// texts of every call.
+type countingEmbedding struct {
+	model string
+	dims  int
+	calls [][]string
+}
+
+func (e *countingEmbedding) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls = append(e.calls, texts)
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i] = []float32{float32(len(t)), float32(e.dims)}
+	}
+	return out, nil
+}
+func (e *countingEmbedding) Dimensions() int { return e.dims }
+func (e *countingEmbedding) Name() string    { return "counting" }
+func (e *countingEmbedding) Model() string   { return e.model }
+
+// mapConfigStore is an in-memory provider.ConfigStore.
+type mapConfigStore struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func (s *mapConfigStore) GetConfig(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[key], nil
+}
+
+func (s *mapConfigStore) SetConfig(_ context.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	return nil
+}
+
+func TestWithEmbeddingCache_OnlyEmbedsMisses(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingEmbedding{model: "m1", dims: 2}
+	emb := provider.WithEmbeddingCache(inner, provider.NewInMemoryEmbeddingCache(0))
+
+	if _, err := emb.Embed(ctx, []string{"a", "bb"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := emb.Embed(ctx, []string{"ccc", "a", "ccc", "bb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float32{{3, 2}, {1, 2}, {3, 2}, {2, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("vectors = %v, want %v", got, want)
+	}
+	if len(inner.calls) != 2 || !reflect.DeepEqual(inner.calls[1], []string{"ccc"}) {
+		t.Errorf("inner calls = %v, want the second call to embed only ccc", inner.calls)
+	}
+
+	// Cached vectors never leak across models or dimensions.
+	cache := provider.NewInMemoryEmbeddingCache(0)
+	for _, e := range []core.EmbeddingProvider{
+		&countingEmbedding{model: "m1", dims: 2},
+		&countingEmbedding{model: "m2", dims: 2},
+		&countingEmbedding{model: "m1", dims: 3},
+	} {
+		if _, err := provider.WithEmbeddingCache(e, cache).Embed(ctx, []string{"a"}); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(e.(*countingEmbedding).calls); n != 1 {
+			t.Errorf("%+v: inner calls = %d, want a miss", e, n)
+		}
+	}
+}
+
+func TestStoreEmbeddingCache_Persists(t *testing.T) {
+	ctx := context.Background()
+	store := &mapConfigStore{m: map[string]string{}}
+	first := &countingEmbedding{model: "m1", dims: 2}
+	if _, err := provider.WithEmbeddingCache(first, provider.NewStoreEmbeddingCache(store)).Embed(ctx, []string{"hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh provider and cache over the same store: no API call.
+	second := &countingEmbedding{model: "m1", dims: 2}
+	got, err := provider.WithEmbeddingCache(second, provider.NewStoreEmbeddingCache(store)).Embed(ctx, []string{"hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.calls) != 0 || !reflect.DeepEqual(got, [][]float32{{5, 2}}) {
+		t.Errorf("calls = %v, vectors = %v; want {5, 2} from the store", second.calls, got)
+	}
+}
+
+// multimodalEmbedding is a countingEmbedding that also embeds multimodal
+// inputs, as {-1}.
+type multimodalEmbedding struct{ countingEmbedding }
+
+func (e *multimodalEmbedding) EmbedMultimodal(_ context.Context, inputs []core.MultimodalInput) ([][]float32, error) {
+	out := make([][]float32, len(inputs))
+	for i := range out {
+		out[i] = []float32{-1}
+	}
+	return out, nil
+}
+
+func TestWithEmbeddingCache_ForwardsMultimodal(t *testing.T) {
+	plain := provider.WithEmbeddingCache(&countingEmbedding{model: "m1", dims: 2}, provider.NewInMemoryEmbeddingCache(0))
+	if _, ok := plain.(core.MultimodalEmbeddingProvider); ok {
+		t.Error("text-only provider should not gain EmbedMultimodal")
+	}
+
+	emb := provider.WithEmbeddingCache(&multimodalEmbedding{countingEmbedding{model: "m1", dims: 2}}, provider.NewInMemoryEmbeddingCache(0))
+	mm, ok := emb.(core.MultimodalEmbeddingProvider)
+	if !ok {
+		t.Fatal("wrapped multimodal provider lost EmbedMultimodal")
+	}
+	got, err := mm.EmbedMultimodal(context.Background(), []core.MultimodalInput{{Text: "photo"}})
+	if err != nil || !reflect.DeepEqual(got, [][]float32{{-1}}) {
+		t.Errorf("EmbedMultimodal = %v, %v", got, err)
+	}
+}
@@ -260,7 +260,7 @@ func parseGeminiResponse(parsed geminiResponse) oasis.ChatResponse {
 			}
 			if part.FunctionCall != nil {
 				tc := core.ToolCall{
-					ID:   part.FunctionCall.Name,
+					ID:   part.FunctionCall.callID(),
 					Name: part.FunctionCall.Name,
 					Args: part.FunctionCall.Args,
 				}
@@ -144,11 +144,7 @@ func (e *GeminiEmbedding) BatchEmbedResults(ctx context.Context, jobID string) (
 			results = append(results, nil)
 			continue
 		}
-		vec := make([]float32, len(inlined.Embedding.Values))
-		for j, v := range inlined.Embedding.Values {
-			vec[j] = float32(v)
-		}
-		results = append(results, vec)
+		results = append(results, unitVector(inlined.Embedding.Values))
 	}
 
 	return results, nil
@@ -4,11 +4,14 @@ package gemini
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -57,7 +60,16 @@ func New(apiKey, model string, opts ...Option) *Gemini {
 // Name returns "gemini".
 func (g *Gemini) Name() string { return "gemini" }
 
+// attachmentTypes are the MIME types Gemini accepts as inline or file parts.
+var attachmentTypes = []string{"image/*", "audio/*", "video/*", "text/*", "application/pdf"}
+
+// AttachmentTypes implements oasis.AttachmentTypesProvider so agents reject
+// unsupported attachments before sending the request.
+func (g *Gemini) AttachmentTypes() []string { return attachmentTypes }
+
 // ChatStream streams text-delta events into ch, then returns the final accumulated response.
+// Thought parts from thinking models stream as reasoning events and land in
+// ChatResponse.Thinking, never in Content.
 // The channel is closed when streaming completes.
 // When req.Tools is non-empty, tool call arguments stream as EventToolCallDelta events.
 func (g *Gemini) ChatStream(ctx context.Context, req oasis.ChatRequest, ch chan<- oasis.StreamEvent) (oasis.ChatResponse, error) {
@@ -94,11 +106,7 @@ func (g *Gemini) ChatStream(ctx context.Context, req oasis.ChatRequest, ch chan<
 		return oasis.ChatResponse{}, httpErr(resp, string(b))
 	}
 
-	var fullContent strings.Builder
-	var usage oasis.Usage
-	var attachments []oasis.Attachment
-	var finishReason string
-	var safetyRatings []geminiSafetyRating
+	var acc streamAcc
 
 	scanner := bufio.NewScanner(resp.Body)
 	// Large buffer for SSE payloads: image generation returns base64-encoded
@@ -116,7 +124,7 @@ func (g *Gemini) ChatStream(ctx context.Context, req oasis.ChatRequest, ch chan<
 			if jsonBuf.Len() > 0 {
 				jsonBuf.WriteString(line)
 				if json.Valid([]byte(jsonBuf.String())) {
-					if err := g.processStreamChunk(ctx, jsonBuf.String(), &fullContent, &usage, &attachments, &finishReason, &safetyRatings, ch); err != nil {
+					if err := g.processStreamChunk(ctx, jsonBuf.String(), &acc, ch); err != nil {
 						return oasis.ChatResponse{}, err
 					}
 					jsonBuf.Reset()
@@ -132,7 +140,7 @@ func (g *Gemini) ChatStream(ctx context.Context, req oasis.ChatRequest, ch chan<
 
 		// Check if JSON is complete using json.Valid; accumulate across lines if not.
 		if json.Valid([]byte(data)) {
-			if err := g.processStreamChunk(ctx, data, &fullContent, &usage, &attachments, &finishReason, &safetyRatings, ch); err != nil {
+			if err := g.processStreamChunk(ctx, data, &acc, ch); err != nil {
 				return oasis.ChatResponse{}, err
 			}
 		} else {
@@ -144,21 +152,27 @@ func (g *Gemini) ChatStream(ctx context.Context, req oasis.ChatRequest, ch chan<
 	// Process any remaining buffered JSON.
 	if jsonBuf.Len() > 0 {
 		if b := []byte(jsonBuf.String()); json.Valid(b) {
-			if err := g.processStreamChunk(ctx, jsonBuf.String(), &fullContent, &usage, &attachments, &finishReason, &safetyRatings, ch); err != nil {
+			if err := g.processStreamChunk(ctx, jsonBuf.String(), &acc, ch); err != nil {
 				return oasis.ChatResponse{}, err
 			}
 		}
 	}
 
+	// Close a reasoning block that ran to stream end without an answer.
+	if err := acc.endReasoning(ctx, ch); err != nil {
+		return oasis.ChatResponse{}, err
+	}
+
 	out := oasis.ChatResponse{
-		Content:      fullContent.String(),
-		Attachments:  attachments,
-		Usage:        usage,
-		FinishReason: mapGeminiFinishReason(finishReason),
+		Content:      acc.content.String(),
+		Thinking:     acc.thinking.String(),
+		Attachments:  acc.attachments,
+		Usage:        acc.usage,
+		FinishReason: mapGeminiFinishReason(acc.finishReason),
 	}
-	if len(safetyRatings) > 0 {
+	if len(acc.safetyRatings) > 0 {
 		meta, err := json.Marshal(map[string]any{
-			"safety_ratings": safetyRatings,
+			"safety_ratings": acc.safetyRatings,
 		})
 		if err == nil {
 			out.ProviderMeta = meta
@@ -167,40 +181,89 @@ func (g *Gemini) ChatStream(ctx context.Context, req oasis.ChatRequest, ch chan<
 	return out, nil
 }
 
+// streamAcc accumulates a streamed response across SSE chunks.
+type streamAcc struct {
+	content       strings.Builder
+	thinking      strings.Builder
+	reasoning     bool // a reasoning block is open
+	usage         oasis.Usage
+	attachments   []oasis.Attachment
+	finishReason  string
+	safetyRatings []geminiSafetyRating
+}
+
+// send delivers ev unless ch is nil. Returns ctx.Err() if the consumer has
+// cancelled before the send completes.
+func send(ctx context.Context, ch chan<- oasis.StreamEvent, ev oasis.StreamEvent) error {
+	if ch == nil {
+		return nil
+	}
+	select {
+	case ch <- ev:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// endReasoning closes an open reasoning block, emitting EventReasoningEnd
+// with the full thinking text.
+func (a *streamAcc) endReasoning(ctx context.Context, ch chan<- oasis.StreamEvent) error {
+	if !a.reasoning {
+		return nil
+	}
+	a.reasoning = false
+	return send(ctx, ch, oasis.StreamEvent{Type: oasis.EventReasoningEnd, Content: a.thinking.String()})
+}
+
 // processStreamChunk parses a single JSON chunk from the SSE stream,
-// extracts text deltas, usage, finish reason, and safety ratings, and sends
-// text events to the channel. The last non-empty finishReason and any safety
-// ratings from candidates[0] overwrite the caller's accumulators.
+// extracts text and thought deltas, usage, finish reason, and safety
+// ratings, and sends events to the channel. Thought parts stream as a
+// reasoning block (EventReasoningStart/Delta/End) that closes when answer
+// text arrives; only answer text goes to EventTextDelta and Content. The
+// last non-empty finishReason and any safety ratings from candidates[0]
+// overwrite the accumulator's.
 // Returns ctx.Err() if the consumer has cancelled before the send completes.
-func (g *Gemini) processStreamChunk(ctx context.Context, jsonStr string, fullContent *strings.Builder, usage *oasis.Usage, attachments *[]oasis.Attachment, finishReason *string, safetyRatings *[]geminiSafetyRating, ch chan<- oasis.StreamEvent) error {
+func (g *Gemini) processStreamChunk(ctx context.Context, jsonStr string, acc *streamAcc, ch chan<- oasis.StreamEvent) error {
 	var parsed map[string]json.RawMessage
 	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
 		return nil
 	}
 
-	// Extract text from candidates[0].content.parts[].text
-	text := extractTextFromParsed(parsed)
-	if text != "" {
-		fullContent.WriteString(text)
-		if ch != nil {
-			select {
-			case ch <- oasis.StreamEvent{Type: oasis.EventTextDelta, Content: text}:
-			case <-ctx.Done():
-				return ctx.Err()
+	// Extract text and thoughts from candidates[0].content.parts[].text
+	text, thought := extractTextFromParsed(parsed)
+	if thought != "" {
+		if !acc.reasoning {
+			acc.reasoning = true
+			if err := send(ctx, ch, oasis.StreamEvent{Type: oasis.EventReasoningStart}); err != nil {
+				return err
 			}
 		}
+		acc.thinking.WriteString(thought)
+		if err := send(ctx, ch, oasis.StreamEvent{Type: oasis.EventReasoningDelta, Content: thought}); err != nil {
+			return err
+		}
+	}
+	if text != "" {
+		if err := acc.endReasoning(ctx, ch); err != nil {
+			return err
+		}
+		acc.content.WriteString(text)
+		if err := send(ctx, ch, oasis.StreamEvent{Type: oasis.EventTextDelta, Content: text}); err != nil {
+			return err
+		}
 	}
 
 	// Extract attachments from inlineData parts.
 	if atts := extractAttachmentsFromParsed(parsed); len(atts) > 0 {
-		*attachments = append(*attachments, atts...)
+		acc.attachments = append(acc.attachments, atts...)
 	}
 
 	// Extract usage metadata (overwrite each time; last chunk wins).
-	extractUsageFromParsed(parsed, usage)
+	extractUsageFromParsed(parsed, &acc.usage)
 
 	// Extract finish reason and safety ratings from candidates[0].
-	extractFinishMetaFromParsed(parsed, finishReason, safetyRatings)
+	extractFinishMetaFromParsed(parsed, &acc.finishReason, &acc.safetyRatings)
 	return nil
 }
 
@@ -258,7 +321,7 @@ func (g *Gemini) doGenerate(ctx context.Context, body map[string]any) (oasis.Cha
 			}
 			if part.FunctionCall != nil {
 				tc := oasis.ToolCall{
-					ID:   part.FunctionCall.Name,
+					ID:   part.FunctionCall.callID(),
 					Name: part.FunctionCall.Name,
 					Args: part.FunctionCall.Args,
 				}
@@ -369,7 +432,12 @@ type GeminiEmbedding struct {
 	httpClient *http.Client
 }
 
-// NewEmbedding creates a new Gemini embedding provider.
+// NewEmbedding creates a new Gemini embedding provider. dims is sent as
+// outputDimensionality, so the API returns vectors of that size. Gemini
+// embedding models are trained with Matryoshka representation learning, so
+// a dims below the model's native size (e.g. 768 of 3072) keeps most of the
+// retrieval quality at a fraction of the storage. Truncated vectors are
+// L2-normalized before they are returned.
 func NewEmbedding(apiKey, model string, dims int) *GeminiEmbedding {
 	return &GeminiEmbedding{
 		apiKey:     apiKey,
@@ -385,6 +453,9 @@ func (e *GeminiEmbedding) Name() string { return "gemini" }
 // Dimensions returns the configured embedding dimensionality.
 func (e *GeminiEmbedding) Dimensions() int { return e.dims }
 
+// Model returns the embedding model name.
+func (e *GeminiEmbedding) Model() string { return e.model }
+
 // Embed embeds each text sequentially and returns the embedding vectors.
 func (e *GeminiEmbedding) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	url := fmt.Sprintf("%s/models/%s:embedContent?key=%s", baseURL, e.model, e.apiKey)
@@ -436,16 +507,31 @@ func (e *GeminiEmbedding) Embed(ctx context.Context, texts []string) ([][]float3
 			return nil, &oasis.ErrLLM{Provider: "gemini", Message: "missing embedding.values in response"}
 		}
 
-		vec := make([]float32, len(parsed.Embedding.Values))
-		for i, v := range parsed.Embedding.Values {
-			vec[i] = float32(v)
-		}
-		embeddings = append(embeddings, vec)
+		embeddings = append(embeddings, unitVector(parsed.Embedding.Values))
 	}
 
 	return embeddings, nil
 }
 
+// unitVector converts values to float32 and scales them to unit length.
+// The API only normalizes full-size embeddings; truncated ones would
+// otherwise skew cosine scores. An all-zero vector is returned unchanged.
+func unitVector(values []float64) []float32 {
+	var sum float64
+	for _, v := range values {
+		sum += v * v
+	}
+	norm := math.Sqrt(sum)
+	vec := make([]float32, len(values))
+	for i, v := range values {
+		if norm > 0 {
+			v /= norm
+		}
+		vec[i] = float32(v)
+	}
+	return vec
+}
+
 // ---- Body builder ----
 
 // buildBody constructs the Gemini API request body from chat messages and optional tool definitions.
@@ -462,6 +548,10 @@ func appendUnique(s []string, v string) []string {
 func (g *Gemini) buildBody(messages []oasis.ChatMessage, tools []oasis.ToolDefinition, schema *oasis.ResponseSchema, genParams *oasis.GenerationParams, modalities []string) (map[string]any, error) {
 	var systemParts []string
 	var contents []map[string]any
+	// callNames maps tool call IDs to function names: Gemini matches a
+	// functionResponse to its call by name. Histories recorded before call
+	// IDs were unique used the name as the ID, so unknown IDs pass through.
+	callNames := make(map[string]string)
 
 	for _, m := range messages {
 		switch {
@@ -472,6 +562,7 @@ func (g *Gemini) buildBody(messages []oasis.ChatMessage, tools []oasis.ToolDefin
 			// Assistant message with tool calls -> model role with functionCall parts.
 			parts := make([]map[string]any, 0, len(m.ToolCalls))
 			for _, tc := range m.ToolCalls {
+				callNames[tc.ID] = tc.Name
 				// Parse args from json.RawMessage into a generic map so Gemini gets an object.
 				var args any
 				if len(tc.Args) > 0 {
@@ -508,12 +599,16 @@ func (g *Gemini) buildBody(messages []oasis.ChatMessage, tools []oasis.ToolDefin
 
 		case m.Role == "tool":
 			// Tool result message -> user role with functionResponse part.
+			name := m.ToolCallID
+			if n, ok := callNames[m.ToolCallID]; ok && n != "" {
+				name = n
+			}
 			contents = append(contents, map[string]any{
 				"role": "user",
 				"parts": []map[string]any{
 					{
 						"functionResponse": map[string]any{
-							"name": m.ToolCallID,
+							"name": name,
 							"response": map[string]any{
 								"result": m.Content,
 							},
@@ -648,6 +743,12 @@ func (g *Gemini) buildBody(messages []oasis.ChatMessage, tools []oasis.ToolDefin
 		if genParams.MaxTokens != nil {
 			genConfig["maxOutputTokens"] = *genParams.MaxTokens
 		}
+		if genParams.Seed != nil {
+			genConfig["seed"] = *genParams.Seed
+		}
+		if len(genParams.StopSequences) > 0 {
+			genConfig["stopSequences"] = genParams.StopSequences
+		}
 	}
 
 	if g.mediaResolution != "" {
@@ -761,10 +862,23 @@ type geminiInlineData struct {
 }
 
 type geminiFuncCall struct {
+	ID   string          `json:"id,omitempty"`
 	Name string          `json:"name"`
 	Args json.RawMessage `json:"args"`
 }
 
+// callID returns the call's ID, or a fresh unique one when Gemini sent none.
+// The function name alone is not unique: every call to the same tool in a
+// thread would share it, and so would share its idempotency key.
+func (fc *geminiFuncCall) callID() string {
+	if fc.ID != "" {
+		return fc.ID
+	}
+	var b [8]byte
+	rand.Read(b[:])
+	return fc.Name + "_" + hex.EncodeToString(b[:])
+}
+
 type geminiUsage struct {
 	PromptTokenCount        int `json:"promptTokenCount"`
 	CandidatesTokenCount    int `json:"candidatesTokenCount"`
@@ -782,16 +896,16 @@ type embedValues struct {
 // ---- Stream helpers ----
 
 // extractTextFromParsed extracts concatenated text from candidates[0].content.parts[].text
-// in a raw parsed JSON map.
-func extractTextFromParsed(parsed map[string]json.RawMessage) string {
+// in a raw parsed JSON map, returning answer text and thought text separately.
+func extractTextFromParsed(parsed map[string]json.RawMessage) (text, thought string) {
 	candidatesRaw, ok := parsed["candidates"]
 	if !ok {
-		return ""
+		return "", ""
 	}
 
 	var candidates []json.RawMessage
 	if err := json.Unmarshal(candidatesRaw, &candidates); err != nil || len(candidates) == 0 {
-		return ""
+		return "", ""
 	}
 
 	var candidate struct {
@@ -803,19 +917,21 @@ func extractTextFromParsed(parsed map[string]json.RawMessage) string {
 		} `json:"content"`
 	}
 	if err := json.Unmarshal(candidates[0], &candidate); err != nil {
-		return ""
+		return "", ""
 	}
 
-	var sb strings.Builder
+	var sb, tb strings.Builder
 	for _, p := range candidate.Content.Parts {
-		if p.Thought {
+		if p.Text == nil {
 			continue
 		}
-		if p.Text != nil {
+		if p.Thought {
+			tb.WriteString(*p.Text)
+		} else {
 			sb.WriteString(*p.Text)
 		}
 	}
-	return sb.String()
+	return sb.String(), tb.String()
 }
 
 // extractAttachmentsFromParsed extracts inlineData parts from candidates[0].content.parts[]
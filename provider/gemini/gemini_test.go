@@ -3,6 +3,7 @@ package gemini
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -156,6 +157,48 @@ func TestBuildBody_ToolResults(t *testing.T) {
 	}
 }
 
+func TestToolCallIDsUniqueAcrossTurns(t *testing.T) {
+	turn := func() oasis.ToolCall {
+		resp := parseGeminiResponse(geminiResponse{Candidates: []geminiCandidate{{
+			Content: geminiContent{Parts: []geminiPart{{
+				FunctionCall: &geminiFuncCall{Name: "create_ticket", Args: json.RawMessage(`{}`)},
+			}}},
+		}}})
+		if len(resp.ToolCalls) != 1 {
+			t.Fatalf("expected 1 tool call, got %d", len(resp.ToolCalls))
+		}
+		return resp.ToolCalls[0]
+	}
+	first, second := turn(), turn()
+	if first.ID == second.ID {
+		t.Fatalf("two calls to the same tool share ID %q", first.ID)
+	}
+	if first.Name != "create_ticket" {
+		t.Errorf("Name = %q, want create_ticket", first.Name)
+	}
+
+	// The functionResponse is still matched to its call by function name.
+	body, err := testGemini().buildBody([]oasis.ChatMessage{
+		{Role: "user", Content: "open a ticket"},
+		{Role: "assistant", ToolCalls: []oasis.ToolCall{first}},
+		{Role: "tool", Content: "T-1", ToolCallID: first.ID},
+	}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("buildBody returned error: %v", err)
+	}
+	contents := body["contents"].([]map[string]any)
+	fr := contents[2]["parts"].([]map[string]any)[0]["functionResponse"].(map[string]any)
+	if fr["name"] != "create_ticket" {
+		t.Errorf("functionResponse name = %q, want create_ticket", fr["name"])
+	}
+
+	// An ID sent by Gemini is kept.
+	fc := geminiFuncCall{ID: "abc", Name: "create_ticket"}
+	if got := fc.callID(); got != "abc" {
+		t.Errorf("callID() = %q, want abc", got)
+	}
+}
+
 func TestBuildBody_ToolDeclarations(t *testing.T) {
 	g := testGemini()
 	messages := []oasis.ChatMessage{
@@ -412,6 +455,41 @@ func TestBuildBody_GenerationConfigWithOptions(t *testing.T) {
 	}
 }
 
+func TestBuildBody_GenerationParamsOverride(t *testing.T) {
+	g := testGemini()
+	temp, seed := 0.0, 42
+	messages := []oasis.ChatMessage{{Role: "user", Content: "Hello"}}
+	params := &oasis.GenerationParams{Temperature: &temp, Seed: &seed, StopSequences: []string{"END"}}
+
+	body, err := g.buildBody(messages, nil, nil, params, nil)
+	if err != nil {
+		t.Fatalf("buildBody returned error: %v", err)
+	}
+	gc := body["generationConfig"].(map[string]any)
+	if gc["temperature"] != 0.0 {
+		t.Errorf("expected temperature 0, got %v", gc["temperature"])
+	}
+	if gc["seed"] != 42 {
+		t.Errorf("expected seed 42, got %v", gc["seed"])
+	}
+	if stops, ok := gc["stopSequences"].([]string); !ok || len(stops) != 1 || stops[0] != "END" {
+		t.Errorf("expected stopSequences [END], got %v", gc["stopSequences"])
+	}
+	if gc["topP"] != 0.9 {
+		t.Errorf("expected provider default topP 0.9, got %v", gc["topP"])
+	}
+
+	// Unset params leave the body unchanged.
+	body, _ = g.buildBody(messages, nil, nil, &oasis.GenerationParams{}, nil)
+	gc = body["generationConfig"].(map[string]any)
+	if _, ok := gc["seed"]; ok {
+		t.Error("expected no seed when GenerationParams.Seed is nil")
+	}
+	if _, ok := gc["stopSequences"]; ok {
+		t.Error("expected no stopSequences when unset")
+	}
+}
+
 func TestBuildBody_ImageGeneration(t *testing.T) {
 	g := New("key", "gemini-2.0-flash-exp-image-generation",
 		WithResponseModalities("TEXT", "IMAGE"),
@@ -653,6 +731,52 @@ func TestChatStream_SplitPayloadSSE(t *testing.T) {
 	}
 }
 
+// TestChatStream_ThoughtParts verifies that thought parts stream as a
+// reasoning block ahead of the answer and stay out of Content.
+func TestChatStream_ThoughtParts(t *testing.T) {
+	sseBody := `data: {"candidates":[{"content":{"parts":[{"text":"Let me ","thought":true}]}}]}` + "\n\n" +
+		`data: {"candidates":[{"content":{"parts":[{"text":"think.","thought":true}]}}]}` + "\n\n" +
+		`data: {"candidates":[{"content":{"parts":[{"text":"42"}]}}]}` + "\n\n"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(sseBody))
+	}))
+	defer srv.Close()
+
+	orig := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = orig }()
+
+	g := New("test-key", "gemini-flash")
+	ch := make(chan oasis.StreamEvent, 16)
+	result, err := g.ChatStream(context.Background(), oasis.ChatRequest{
+		Messages: []oasis.ChatMessage{{Role: "user", Content: "hi"}},
+	}, ch)
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if result.Content != "42" || result.Thinking != "Let me think." {
+		t.Errorf("content = %q, thinking = %q; want %q and %q", result.Content, result.Thinking, "42", "Let me think.")
+	}
+
+	var got []string
+	for e := range ch {
+		got = append(got, string(e.Type)+":"+e.Content)
+	}
+	want := []string{
+		"reasoning-start:",
+		"reasoning-delta:Let me ",
+		"reasoning-delta:think.",
+		"reasoning-end:Let me think.",
+		"text-delta:42",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
 // TestChatStream_CancelledConsumer verifies that cancelling the context while
 // the stream is in progress causes ChatStream to return promptly with
 // context.Canceled rather than blocking forever (goroutine leak guard).
@@ -820,6 +944,37 @@ func TestNewConstructors(t *testing.T) {
 	}
 }
 
+func TestEmbedNormalizesTruncatedVectors(t *testing.T) {
+	var gotDims float64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		gotDims, _ = body["outputDimensionality"].(float64)
+		// A truncated Matryoshka embedding is not unit length.
+		w.Write([]byte(`{"embedding":{"values":[3,4]}}`))
+	}))
+	defer srv.Close()
+
+	origBaseURL := baseURL
+	defer func() { baseURL = origBaseURL }()
+	baseURL = srv.URL
+
+	e := NewEmbedding("key", "gemini-embedding-001", 2)
+	vecs, err := e.Embed(context.Background(), []string{"hello"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if gotDims != 2 {
+		t.Errorf("outputDimensionality = %v, want 2", gotDims)
+	}
+	if len(vecs) != 1 || len(vecs[0]) != 2 {
+		t.Fatalf("vecs = %v, want one 2-dimensional vector", vecs)
+	}
+	if math.Abs(float64(vecs[0][0])-0.6) > 1e-6 || math.Abs(float64(vecs[0][1])-0.8) > 1e-6 {
+		t.Errorf("vec = %v, want [0.6 0.8]", vecs[0])
+	}
+}
+
 func TestNewWithOptions(t *testing.T) {
 	g := New("key", "model",
 		WithTemperature(0.5),
@@ -109,6 +109,9 @@ func (e *Embedding) Name() string { return e.name }
 // Dimensions returns the configured embedding dimensionality.
 func (e *Embedding) Dimensions() int { return e.dims }
 
+// Model returns the embedding model name.
+func (e *Embedding) Model() string { return e.model }
+
 // Embed returns embedding vectors for the given texts.
 func (e *Embedding) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	req := embedRequest{
@@ -26,6 +26,16 @@ func mapOpenAIFinishReason(reason string) oasis.FinishReason {
 	}
 }
 
+// stopFinishReason refines a "stop" finish reason to FinishStopSequence
+// when the server reported the matched stop string in stop_reason. OpenAI
+// itself does not, so its stop-sequence stops stay FinishStop.
+func stopFinishReason(reason oasis.FinishReason, stopReason json.RawMessage) oasis.FinishReason {
+	if reason == oasis.FinishStop && len(stopReason) > 0 && stopReason[0] == '"' {
+		return oasis.FinishStopSequence
+	}
+	return reason
+}
+
 // ParseResponse converts an OpenAI-format ChatResponse to an oasis ChatResponse.
 // It extracts content, tool calls, usage, finish reason, and provider metadata
 // from choices[0] and the top-level response fields.
@@ -44,7 +54,7 @@ func ParseResponse(resp ChatResponse) (oasis.ChatResponse, error) {
 		out.Attachments = imagesToAttachments(choice.Message.Images)
 	}
 
-	out.FinishReason = mapOpenAIFinishReason(choice.FinishReason)
+	out.FinishReason = stopFinishReason(mapOpenAIFinishReason(choice.FinishReason), choice.StopReason)
 
 	if resp.Usage != nil {
 		out.Usage = oasis.Usage{
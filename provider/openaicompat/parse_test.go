@@ -222,6 +222,35 @@ func TestMapOpenAIFinishReason(t *testing.T) {
 	}
 }
 
+func TestParseResponse_StopSequenceFinishReason(t *testing.T) {
+	cases := []struct {
+		stopReason string
+		want       string
+	}{
+		{`"Observation:"`, "stop-sequence"},
+		{`128001`, "stop"}, // stop token ID, not a sequence
+		{`null`, "stop"},
+		{``, "stop"},
+	}
+	for _, c := range cases {
+		var raw json.RawMessage
+		if c.stopReason != "" {
+			raw = json.RawMessage(c.stopReason)
+		}
+		result, err := ParseResponse(ChatResponse{Choices: []Choice{{
+			Message:      &ChoiceMessage{Content: "Thought: search"},
+			FinishReason: "stop",
+			StopReason:   raw,
+		}}})
+		if err != nil {
+			t.Fatalf("ParseResponse returned error: %v", err)
+		}
+		if string(result.FinishReason) != c.want {
+			t.Errorf("stop_reason %s: FinishReason = %q, want %q", c.stopReason, result.FinishReason, c.want)
+		}
+	}
+}
+
 func TestParseResponse_FinishReasonAndMeta(t *testing.T) {
 	resp := ChatResponse{
 		ID: "chatcmpl-fp",
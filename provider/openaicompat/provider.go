@@ -27,6 +27,9 @@ type Provider struct {
 	name    string
 	opts    []Option
 	logger  *slog.Logger
+
+	attachmentTypes []string
+	noTools         bool
 }
 
 // NewProvider creates an OpenAI-compatible chat provider.
@@ -55,6 +58,15 @@ func NewProvider(apiKey, model, baseURL string, opts ...ProviderOption) *Provide
 // Name returns the provider name (default "openai", configurable via WithName).
 func (p *Provider) Name() string { return p.name }
 
+// AttachmentTypes implements oasis.AttachmentTypesProvider. Nil (the default)
+// places no restriction, since compatible backends differ; set the list with
+// WithAttachmentTypes.
+func (p *Provider) AttachmentTypes() []string { return p.attachmentTypes }
+
+// SupportsTools implements oasis.ToolSupportProvider. True unless disabled
+// with WithToolSupport(false).
+func (p *Provider) SupportsTools() bool { return !p.noTools }
+
 // mergeGenParams returns the provider's base options with any per-request
 // GenerationParams appended. Per-request params override provider defaults
 // because options are applied in order (last wins).
@@ -62,7 +74,7 @@ func (p *Provider) mergeGenParams(params *oasis.GenerationParams) []Option {
 	if params == nil {
 		return p.opts
 	}
-	opts := make([]Option, len(p.opts), len(p.opts)+4)
+	opts := make([]Option, len(p.opts), len(p.opts)+6)
 	copy(opts, p.opts)
 	if params.Temperature != nil {
 		opts = append(opts, WithTemperature(*params.Temperature))
@@ -73,6 +85,12 @@ func (p *Provider) mergeGenParams(params *oasis.GenerationParams) []Option {
 	if params.MaxTokens != nil {
 		opts = append(opts, WithMaxTokens(*params.MaxTokens))
 	}
+	if params.Seed != nil {
+		opts = append(opts, WithSeed(*params.Seed))
+	}
+	if len(params.StopSequences) > 0 {
+		opts = append(opts, WithStop(params.StopSequences...))
+	}
 	if params.TopK != nil && p.logger != nil {
 		p.logger.Warn("GenerationParams.TopK not supported by OpenAI-compatible provider, ignored")
 	}
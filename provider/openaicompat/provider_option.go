@@ -25,6 +25,25 @@ func WithOptions(opts ...Option) ProviderOption {
 	return func(p *Provider) { p.opts = append(p.opts, opts...) }
 }
 
+// WithAttachmentTypes sets the MIME types this backend accepts as message
+// attachments ("image/*" matches any image). Agents using the provider reject
+// other attachments before the request is sent. See OpenAIAttachmentTypes for
+// the OpenAI API's list.
+func WithAttachmentTypes(mimeTypes ...string) ProviderOption {
+	return func(p *Provider) { p.attachmentTypes = mimeTypes }
+}
+
+// OpenAIAttachmentTypes lists the attachment types the OpenAI chat completions
+// API accepts: images and PDF files.
+var OpenAIAttachmentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf"}
+
+// WithToolSupport declares whether the backend does native function calling
+// (default true). Set false for models served without tool support; agents
+// built with WithToolCallFallback then describe tools in the prompt instead.
+func WithToolSupport(enabled bool) ProviderOption {
+	return func(p *Provider) { p.noTools = !enabled }
+}
+
 // WithLogger sets a structured logger for the provider.
 // When set, the provider emits warnings for unsupported GenerationParams fields
 // (e.g. TopK). If not set, no warnings are emitted.
@@ -342,3 +342,25 @@ func TestProvider_WithOptions(t *testing.T) {
 		t.Fatalf("Chat returned error: %v", err)
 	}
 }
+
+func TestProvider_MergeGenParams(t *testing.T) {
+	p := NewProvider("key", "gpt-4o", "http://unused", WithOptions(WithTemperature(0.7)))
+	temp, seed := 0.0, 7
+	req := BuildBody(nil, nil, "gpt-4o", nil, p.mergeGenParams(&oasis.GenerationParams{
+		Temperature: &temp, Seed: &seed, StopSequences: []string{"END"},
+	})...)
+	if req.Temperature == nil || *req.Temperature != 0 {
+		t.Errorf("expected per-request temperature 0, got %v", req.Temperature)
+	}
+	if req.Seed == nil || *req.Seed != 7 {
+		t.Errorf("expected seed 7, got %v", req.Seed)
+	}
+	if len(req.Stop) != 1 || req.Stop[0] != "END" {
+		t.Errorf("expected stop [END], got %v", req.Stop)
+	}
+
+	req = BuildBody(nil, nil, "gpt-4o", nil, p.mergeGenParams(&oasis.GenerationParams{})...)
+	if req.Temperature == nil || *req.Temperature != 0.7 || req.Seed != nil || req.Stop != nil {
+		t.Errorf("expected provider defaults with unset params, got temperature %v seed %v stop %v", req.Temperature, req.Seed, req.Stop)
+	}
+}
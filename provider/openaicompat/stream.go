@@ -34,6 +34,7 @@ func StreamSSE(ctx context.Context, body io.Reader, ch chan<- oasis.StreamEvent)
 	var fullReasoning strings.Builder
 	var usage oasis.Usage
 	var finishReason string
+	var stopReason json.RawMessage
 	var systemFingerprint string
 	var attachments []oasis.Attachment
 	reasoning := false // true while inside a reasoning block (Start emitted, End not yet)
@@ -97,6 +98,9 @@ func StreamSSE(ctx context.Context, body io.Reader, ch chan<- oasis.StreamEvent)
 		if choice.FinishReason != "" {
 			finishReason = choice.FinishReason
 		}
+		if len(choice.StopReason) > 0 {
+			stopReason = choice.StopReason
+		}
 
 		delta := choice.Delta
 		if delta == nil {
@@ -171,6 +175,21 @@ func StreamSSE(ctx context.Context, body io.Reader, ch chan<- oasis.StreamEvent)
 			if tc.Function.Arguments != "" {
 				toolCalls[idx].Args.WriteString(tc.Function.Arguments)
 			}
+
+			// Surface the fragment so a UI can render the call as it is
+			// written. The first fragment usually carries only the name.
+			if ch != nil && (tc.Function.Name != "" || tc.Function.Arguments != "") {
+				select {
+				case ch <- oasis.StreamEvent{
+					Type:    oasis.EventToolCallDelta,
+					ID:      toolCalls[idx].ID,
+					Name:    toolCalls[idx].Name,
+					Content: tc.Function.Arguments,
+				}:
+				case <-ctx.Done():
+					return oasis.ChatResponse{}, ctx.Err()
+				}
+			}
 		}
 
 		// Extract usage from chunks that include it.
@@ -222,7 +241,7 @@ func StreamSSE(ctx context.Context, body io.Reader, ch chan<- oasis.StreamEvent)
 		ToolCalls:    oasisToolCalls,
 		Attachments:  attachments,
 		Usage:        usage,
-		FinishReason: mapOpenAIFinishReason(finishReason),
+		FinishReason: stopFinishReason(mapOpenAIFinishReason(finishReason), stopReason),
 	}
 
 	if systemFingerprint != "" {
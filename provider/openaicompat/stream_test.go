@@ -87,13 +87,25 @@ func TestStreamSSE_ToolCallChunks(t *testing.T) {
 		t.Fatalf("StreamSSE returned error: %v", err)
 	}
 
-	// Drain channel (should be empty since tool calls don't produce text deltas).
-	var deltas []oasis.StreamEvent
+	// Tool calls produce no text deltas, only one tool-call delta per fragment.
+	var streamed strings.Builder
+	var toolDeltas int
 	for d := range ch {
-		deltas = append(deltas, d)
+		if d.Type != oasis.EventToolCallDelta {
+			t.Errorf("unexpected %s event in tool call stream", d.Type)
+			continue
+		}
+		toolDeltas++
+		if d.ID != "call_abc" || d.Name != "get_weather" {
+			t.Errorf("tool-call delta ID/Name = %q/%q, want call_abc/get_weather", d.ID, d.Name)
+		}
+		streamed.WriteString(d.Content)
+	}
+	if toolDeltas != 4 {
+		t.Errorf("expected 4 tool-call deltas (name + 3 fragments), got %d", toolDeltas)
 	}
-	if len(deltas) != 0 {
-		t.Errorf("expected no text deltas for tool call stream, got %d", len(deltas))
+	if streamed.String() != `{"city":"London"}` {
+		t.Errorf("concatenated delta args = %q", streamed.String())
 	}
 
 	if resp.Content != "" {
@@ -328,6 +328,9 @@ type Choice struct {
 	Message      *ChoiceMessage `json:"message,omitempty"`
 	Delta        *ChoiceMessage `json:"delta,omitempty"`
 	FinishReason string         `json:"finish_reason,omitempty"`
+	// StopReason is a vLLM/SGLang extension: the stop sequence that ended
+	// generation (a JSON string), a stop token ID, or null.
+	StopReason json.RawMessage `json:"stop_reason,omitempty"`
 }
 
 // ChoiceMessage is the message content within a choice (used for both message and delta).
@@ -93,6 +93,9 @@ func openaiCompatProvider(cfg Config) oasis.Provider {
 	}
 	var provOpts []openaicompat.ProviderOption
 	provOpts = append(provOpts, openaicompat.WithName(cfg.Provider))
+	if cfg.Provider == "openai" {
+		provOpts = append(provOpts, openaicompat.WithAttachmentTypes(openaicompat.OpenAIAttachmentTypes...))
+	}
 
 	var reqOpts []openaicompat.Option
 	if cfg.Temperature != nil {
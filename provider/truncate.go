@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// TruncateEmbedding wraps inner so its vectors keep only their first dims
+// dimensions, L2-renormalized to unit length — Matryoshka truncation for
+// providers that cannot shorten vectors natively. Dimensions reports dims,
+// so stores size their vector columns to match.
+//
+// Truncation trades retrieval quality for storage and search speed. It is
+// only sound for models trained for it (Matryoshka representation learning,
+// e.g. OpenAI text-embedding-3 and Gemini embeddings); other models lose
+// far more signal. Prefer native truncation where the provider offers it
+// (the dims of gemini.NewEmbedding and openaicompat.NewEmbedding), and re-embed the
+// corpus whenever dims changes.
+func TruncateEmbedding(inner core.EmbeddingProvider, dims int) core.EmbeddingProvider {
+	return &truncatedEmbedding{inner: inner, dims: dims}
+}
+
+type truncatedEmbedding struct {
+	inner core.EmbeddingProvider
+	dims  int
+}
+
+func (t *truncatedEmbedding) Name() string    { return t.inner.Name() }
+func (t *truncatedEmbedding) Dimensions() int { return t.dims }
+
+// Model forwards inner's model name, when it has one, for WithEmbeddingCache.
+func (t *truncatedEmbedding) Model() string {
+	if m, ok := t.inner.(interface{ Model() string }); ok {
+		return m.Model()
+	}
+	return ""
+}
+
+func (t *truncatedEmbedding) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vecs, err := t.inner.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]float32, len(vecs))
+	for i, v := range vecs {
+		if len(v) < t.dims {
+			return nil, fmt.Errorf("truncate embedding: %s returned %d dimensions, want at least %d", t.inner.Name(), len(v), t.dims)
+		}
+		out[i] = normalize(v[:t.dims])
+	}
+	return out, nil
+}
+
+// normalize returns a unit-length copy of v, or a copy of v unchanged when
+// it is all zeros.
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	norm := math.Sqrt(sum)
+	for i, x := range v {
+		if norm == 0 {
+			out[i] = x
+		} else {
+			out[i] = float32(float64(x) / norm)
+		}
+	}
+	return out
+}
+
+// compile-time check
+var _ core.EmbeddingProvider = (*truncatedEmbedding)(nil)
@@ -0,0 +1,30 @@
+package provider_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/nevindra/oasis/provider"
+)
+
+func TestTruncateEmbedding(t *testing.T) {
+	inner := &countingEmbedding{dims: 4}
+	emb := provider.TruncateEmbedding(inner, 1)
+	if emb.Dimensions() != 1 {
+		t.Errorf("Dimensions() = %d, want 1", emb.Dimensions())
+	}
+
+	// countingEmbedding yields {len, dims}: {3, 4} truncates to {3}, then {1}.
+	vecs, err := emb.Embed(context.Background(), []string{"abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs[0]) != 1 || math.Abs(float64(vecs[0][0])-1) > 1e-6 {
+		t.Errorf("vector = %v, want unit-length [1]", vecs[0])
+	}
+
+	if _, err := provider.TruncateEmbedding(inner, 3).Embed(context.Background(), []string{"abc"}); err == nil {
+		t.Error("truncating a 2-d vector to 3: want error")
+	}
+}
@@ -0,0 +1,218 @@
+package voyage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nevindra/oasis"
+)
+
+// Voyage's Batch API mirrors OpenAI's: the requests are uploaded as a JSONL
+// file, a batch job is created against /v1/embeddings, and the results are
+// downloaded as an output JSONL file once the job completes.
+
+// batchLine is one request line of the uploaded input file.
+type batchLine struct {
+	CustomID string `json:"custom_id"`
+	Body     struct {
+		Input []string `json:"input"`
+	} `json:"body"`
+}
+
+// batchResultLine is one line of a completed job's output file.
+type batchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Response struct {
+		StatusCode int           `json:"status_code"`
+		Body       embedResponse `json:"body"`
+	} `json:"response"`
+}
+
+type batchResponse struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	OutputFileID  string `json:"output_file_id"`
+	CreatedAt     string `json:"created_at"`
+	CompletedAt   string `json:"completed_at"`
+	RequestCounts struct {
+		Total     int `json:"total"`
+		Completed int `json:"completed"`
+		Failed    int `json:"failed"`
+	} `json:"request_counts"`
+}
+
+// BatchEmbed submits texts as one asynchronous batch job. Each group yields
+// one vector: its texts are joined with blank lines and embedded together.
+// Batch jobs are billed at a discount and complete within 12 hours.
+func (e *Embedding) BatchEmbed(ctx context.Context, texts [][]string) (oasis.BatchJob, error) {
+	var input bytes.Buffer
+	enc := json.NewEncoder(&input)
+	for i, group := range texts {
+		var line batchLine
+		line.CustomID = "req-" + strconv.Itoa(i)
+		line.Body.Input = []string{strings.Join(group, "\n\n")}
+		if err := enc.Encode(line); err != nil {
+			return oasis.BatchJob{}, e.wrapErr("marshal batch input: " + err.Error())
+		}
+	}
+
+	fileID, err := e.uploadBatchFile(ctx, input.Bytes())
+	if err != nil {
+		return oasis.BatchJob{}, err
+	}
+	payload, err := json.Marshal(map[string]any{
+		"input_file_id":     fileID,
+		"endpoint":          "/v1/embeddings",
+		"completion_window": "12h",
+		"request_params":    e.params(),
+	})
+	if err != nil {
+		return oasis.BatchJob{}, e.wrapErr("marshal batch request: " + err.Error())
+	}
+	body, err := e.do(ctx, http.MethodPost, "/batches", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return oasis.BatchJob{}, err
+	}
+	return e.parseBatch(body)
+}
+
+// uploadBatchFile uploads a JSONL input file and returns its file ID.
+func (e *Embedding) uploadBatchFile(ctx context.Context, data []byte) (string, error) {
+	var form bytes.Buffer
+	w := multipart.NewWriter(&form)
+	if err := w.WriteField("purpose", "batch"); err != nil {
+		return "", e.wrapErr("build upload: " + err.Error())
+	}
+	fw, err := w.CreateFormFile("file", "batch.jsonl")
+	if err != nil {
+		return "", e.wrapErr("build upload: " + err.Error())
+	}
+	if _, err := fw.Write(data); err != nil {
+		return "", e.wrapErr("build upload: " + err.Error())
+	}
+	if err := w.Close(); err != nil {
+		return "", e.wrapErr("build upload: " + err.Error())
+	}
+
+	body, err := e.do(ctx, http.MethodPost, "/files", w.FormDataContentType(), &form)
+	if err != nil {
+		return "", err
+	}
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &file); err != nil || file.ID == "" {
+		return "", e.wrapErr("parse upload response: " + string(body))
+	}
+	return file.ID, nil
+}
+
+// BatchEmbedStatus returns the current state of a batch embedding job.
+func (e *Embedding) BatchEmbedStatus(ctx context.Context, jobID string) (oasis.BatchJob, error) {
+	body, err := e.do(ctx, http.MethodGet, "/batches/"+jobID, "", nil)
+	if err != nil {
+		return oasis.BatchJob{}, err
+	}
+	return e.parseBatch(body)
+}
+
+// BatchEmbedResults retrieves one vector per input group for a completed
+// job. A group whose request failed yields a nil vector.
+func (e *Embedding) BatchEmbedResults(ctx context.Context, jobID string) ([][]float32, error) {
+	body, err := e.do(ctx, http.MethodGet, "/batches/"+jobID, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var br batchResponse
+	if err := json.Unmarshal(body, &br); err != nil {
+		return nil, e.wrapErr("parse status response: " + err.Error())
+	}
+	if state := batchState(br.Status); state != oasis.BatchSucceeded {
+		return nil, e.wrapErr(fmt.Sprintf("batch job not completed: state=%s", state))
+	}
+	if br.OutputFileID == "" {
+		return nil, e.wrapErr("no output file in batch response")
+	}
+
+	out, err := e.do(ctx, http.MethodGet, "/files/"+br.OutputFileID+"/content", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	byIndex := make(map[int][]float32)
+	n := 0
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var line batchResultLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return nil, e.wrapErr("parse batch output: " + err.Error())
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(line.CustomID, "req-"))
+		if err != nil {
+			continue
+		}
+		n = max(n, i+1)
+		if line.Response.StatusCode == http.StatusOK && len(line.Response.Body.Data) > 0 {
+			byIndex[i] = line.Response.Body.Data[0].Embedding
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, e.wrapErr("read batch output: " + err.Error())
+	}
+	n = max(n, br.RequestCounts.Total)
+	results := make([][]float32, n)
+	for i, v := range byIndex {
+		results[i] = v
+	}
+	return results, nil
+}
+
+func (e *Embedding) parseBatch(body []byte) (oasis.BatchJob, error) {
+	var br batchResponse
+	if err := json.Unmarshal(body, &br); err != nil {
+		return oasis.BatchJob{}, e.wrapErr("parse batch response: " + err.Error())
+	}
+	job := oasis.BatchJob{
+		ID:    br.ID,
+		State: batchState(br.Status),
+		Stats: oasis.BatchStats{
+			TotalCount:     br.RequestCounts.Total,
+			SucceededCount: br.RequestCounts.Completed,
+			FailedCount:    br.RequestCounts.Failed,
+		},
+	}
+	job.CreateTime, _ = time.Parse(time.RFC3339, br.CreatedAt)
+	job.UpdateTime, _ = time.Parse(time.RFC3339, br.CompletedAt)
+	return job, nil
+}
+
+// batchState maps a Voyage batch status to a BatchState.
+func batchState(status string) oasis.BatchState {
+	switch status {
+	case "completed":
+		return oasis.BatchSucceeded
+	case "failed":
+		return oasis.BatchFailed
+	case "cancelling", "cancelled":
+		return oasis.BatchCancelled
+	case "expired":
+		return oasis.BatchExpired
+	case "in_progress", "finalizing":
+		return oasis.BatchRunning
+	default: // "validating"
+		return oasis.BatchPending
+	}
+}
+
+var _ oasis.BatchEmbeddingProvider = (*Embedding)(nil)
@@ -0,0 +1,40 @@
+package voyage
+
+import "net/http"
+
+// Option configures a Voyage Embedding.
+type Option func(*Embedding)
+
+// WithModel sets the embedding model (default "voyage-3.5"). Dimensions
+// follow the model's default size unless WithDimensions is also given.
+func WithModel(model string) Option {
+	return func(e *Embedding) { e.model = model }
+}
+
+// WithDimensions sets the output dimension, sent as output_dimension. Only
+// models with flexible dimensions (voyage-3.5, voyage-3-large, voyage-code-3,
+// ...) accept it; valid sizes are 256, 512, 1024 and 2048.
+func WithDimensions(dims int) Option {
+	return func(e *Embedding) { e.dims = dims }
+}
+
+// WithInputType sets Voyage's input_type: "document" for stored content or
+// "query" for search queries. Empty (the default) embeds text as-is.
+func WithInputType(inputType string) Option {
+	return func(e *Embedding) { e.inputType = inputType }
+}
+
+// WithBaseURL overrides the API base (default "https://api.voyageai.com/v1").
+func WithBaseURL(url string) Option {
+	return func(e *Embedding) { e.baseURL = url }
+}
+
+// WithHTTPClient sets a custom HTTP client (e.g. for timeouts, proxies, or testing).
+func WithHTTPClient(c *http.Client) Option {
+	return func(e *Embedding) { e.client = c }
+}
+
+// WithName overrides the provider name returned by Name() (default "voyage").
+func WithName(name string) Option {
+	return func(e *Embedding) { e.name = name }
+}
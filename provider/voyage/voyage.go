@@ -0,0 +1,195 @@
+// Package voyage provides an oasis EmbeddingProvider for Voyage AI's
+// embedding API, including its asynchronous Batch API.
+//
+// It only embeds; pair it with any rag.Reranker (for example provider/cohere)
+// for second-stage reranking.
+package voyage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+const (
+	defaultBaseURL = "https://api.voyageai.com/v1"
+	defaultModel   = "voyage-3.5"
+
+	// maxInputsPerRequest is Voyage's cap on texts per /embeddings call.
+	maxInputsPerRequest = 1000
+)
+
+// modelDims lists default output sizes that differ from 1024.
+var modelDims = map[string]int{
+	"voyage-3-lite":  512,
+	"voyage-large-2": 1536,
+	"voyage-code-2":  1536,
+}
+
+// Embedding implements oasis.EmbeddingProvider and oasis.BatchEmbeddingProvider
+// for Voyage AI. Safe for concurrent use.
+type Embedding struct {
+	apiKey    string
+	model     string
+	dims      int
+	inputType string
+	baseURL   string
+	client    *http.Client
+	name      string
+
+	// sendDims reports whether dims was set explicitly and must be sent
+	// as output_dimension.
+	sendDims bool
+}
+
+// New creates a Voyage embedding provider.
+//
+//	voyage.New(key,
+//	    voyage.WithModel("voyage-3-large"),
+//	    voyage.WithDimensions(2048),
+//	    voyage.WithInputType("document"),
+//	)
+func New(apiKey string, opts ...Option) *Embedding {
+	e := &Embedding{
+		apiKey:  apiKey,
+		model:   defaultModel,
+		baseURL: defaultBaseURL,
+		client:  &http.Client{},
+		name:    "voyage",
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.baseURL = strings.TrimRight(e.baseURL, "/")
+	if e.dims > 0 {
+		e.sendDims = true
+	} else if d, ok := modelDims[e.model]; ok {
+		e.dims = d
+	} else {
+		e.dims = 1024
+	}
+	return e
+}
+
+// Name returns the provider name (default "voyage", overridable via WithName).
+func (e *Embedding) Name() string { return e.name }
+
+// Dimensions returns the embedding vector size: the WithDimensions value, or
+// the model's default.
+func (e *Embedding) Dimensions() int { return e.dims }
+
+// Model returns the embedding model name.
+func (e *Embedding) Model() string { return e.model }
+
+// embedParams are the request fields shared by /embeddings and batch jobs.
+type embedParams struct {
+	Model           string `json:"model,omitempty"`
+	InputType       string `json:"input_type,omitempty"`
+	OutputDimension int    `json:"output_dimension,omitempty"`
+}
+
+type embedRequest struct {
+	Input []string `json:"input"`
+	embedParams
+}
+
+type embedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *Embedding) params() embedParams {
+	p := embedParams{Model: e.model, InputType: e.inputType}
+	if e.sendDims {
+		p.OutputDimension = e.dims
+	}
+	return p
+}
+
+// Embed returns embedding vectors for texts, in order. Inputs beyond
+// Voyage's per-request limit are split across several calls.
+func (e *Embedding) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxInputsPerRequest {
+		end := min(start+maxInputsPerRequest, len(texts))
+		vecs, err := e.embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vecs...)
+	}
+	return out, nil
+}
+
+func (e *Embedding) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	payload, err := json.Marshal(embedRequest{Input: texts, embedParams: e.params()})
+	if err != nil {
+		return nil, e.wrapErr("marshal embed request: " + err.Error())
+	}
+	body, err := e.do(ctx, http.MethodPost, "/embeddings", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	var parsed embedResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, e.wrapErr("decode embed response: " + err.Error())
+	}
+	vecs := make([][]float32, len(texts))
+	for _, d := range parsed.Data {
+		if d.Index < 0 || d.Index >= len(vecs) {
+			return nil, e.wrapErr("embed response index out of range")
+		}
+		vecs[d.Index] = d.Embedding
+	}
+	for _, v := range vecs {
+		if v == nil {
+			return nil, e.wrapErr("embed response is missing vectors")
+		}
+	}
+	return vecs, nil
+}
+
+// do sends a request to path and returns the response body, mapping non-2xx
+// statuses to *oasis.ErrHTTP.
+func (e *Embedding) do(ctx context.Context, method, path, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+path, body)
+	if err != nil {
+		return nil, e.wrapErr("create request: " + err.Error())
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, e.wrapErr("request failed: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, e.wrapErr("read response: " + err.Error())
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &oasis.ErrHTTP{
+			Status:     resp.StatusCode,
+			Body:       string(respBody),
+			RetryAfter: oasis.ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return respBody, nil
+}
+
+func (e *Embedding) wrapErr(msg string) error {
+	return &oasis.ErrLLM{Provider: e.name, Message: msg}
+}
+
+var _ oasis.EmbeddingProvider = (*Embedding)(nil)
@@ -0,0 +1,115 @@
+package voyage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nevindra/oasis"
+)
+
+func TestNew_Dimensions(t *testing.T) {
+	tests := []struct {
+		opts []Option
+		want int
+		send bool
+	}{
+		{nil, 1024, false},
+		{[]Option{WithModel("voyage-3-lite")}, 512, false},
+		{[]Option{WithModel("voyage-3-large"), WithDimensions(2048)}, 2048, true},
+	}
+	for _, tt := range tests {
+		e := New("key", tt.opts...)
+		if e.Dimensions() != tt.want || e.sendDims != tt.send {
+			t.Errorf("%s: Dimensions() = %d (send %v), want %d (send %v)", e.Model(), e.Dimensions(), e.sendDims, tt.want, tt.send)
+		}
+	}
+	if e := New("key", WithName("voyage-eu")); e.Name() != "voyage-eu" {
+		t.Errorf("Name() = %q", e.Name())
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	var got embedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("request = %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		// Out of order on purpose: vectors are placed by index.
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer srv.Close()
+
+	e := New("key", WithBaseURL(srv.URL), WithInputType("query"), WithDimensions(256))
+	vecs, err := e.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != defaultModel || got.InputType != "query" || got.OutputDimension != 256 || len(got.Input) != 2 {
+		t.Errorf("request = %+v", got)
+	}
+	if len(vecs) != 2 || vecs[0][0] != 1 || vecs[1][1] != 1 {
+		t.Errorf("vecs = %v", vecs)
+	}
+}
+
+func TestBatchEmbed(t *testing.T) {
+	var uploaded string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			f, _, err := r.FormFile("file")
+			if err != nil || r.FormValue("purpose") != "batch" {
+				t.Errorf("upload: %v purpose=%q", err, r.FormValue("purpose"))
+				return
+			}
+			b, _ := io.ReadAll(f)
+			uploaded = string(b)
+			w.Write([]byte(`{"id":"file-in"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/batches":
+			var req map[string]any
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["input_file_id"] != "file-in" || req["endpoint"] != "/v1/embeddings" {
+				t.Errorf("create batch = %v", req)
+			}
+			w.Write([]byte(`{"id":"batch-1","status":"validating","request_counts":{"total":2}}`))
+		case r.URL.Path == "/batches/batch-1":
+			w.Write([]byte(`{"id":"batch-1","status":"completed","output_file_id":"file-out","request_counts":{"total":2,"completed":2}}`))
+		case r.URL.Path == "/files/file-out/content":
+			w.Write([]byte(`{"custom_id":"req-1","response":{"status_code":200,"body":{"data":[{"index":0,"embedding":[2]}]}}}` + "\n" +
+				`{"custom_id":"req-0","response":{"status_code":200,"body":{"data":[{"index":0,"embedding":[1]}]}}}` + "\n"))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	e := New("key", WithBaseURL(srv.URL))
+	ctx := context.Background()
+	job, err := e.BatchEmbed(ctx, [][]string{{"a", "b"}, {"c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID != "batch-1" || job.State != oasis.BatchPending {
+		t.Errorf("job = %+v", job)
+	}
+	if !strings.Contains(uploaded, `"custom_id":"req-0","body":{"input":["a\n\nb"]}`) {
+		t.Errorf("uploaded = %s", uploaded)
+	}
+	status, err := e.BatchEmbedStatus(ctx, job.ID)
+	if err != nil || status.State != oasis.BatchSucceeded || status.Stats.SucceededCount != 2 {
+		t.Fatalf("status = %+v, %v", status, err)
+	}
+	vecs, err := e.BatchEmbedResults(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != 2 || vecs[0][0] != 1 || vecs[1][0] != 2 {
+		t.Errorf("vecs = %v", vecs)
+	}
+}
@@ -0,0 +1,103 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// ErrNoGraphStore is returned by Neighbors when the store does not implement
+// core.GraphStore.
+var ErrNoGraphStore = errors.New("rag: store does not implement core.GraphStore")
+
+// Neighbors returns the k-hop neighborhood of a chunk: every edge traversed
+// within depth hops, followed in both directions, and the chunks those edges
+// reach (not including chunkID itself), both in breadth-first order. depth
+// <= 0 means 1. Edges to chunks that no longer exist are still returned;
+// their chunks are not.
+//
+//	edges, chunks, err := rag.Neighbors(ctx, store, hit.ChunkID, 2)
+//
+// GraphRetriever does the same walk with scoring for retrieval; use
+// Neighbors to inspect or render the graph around a chunk directly.
+func Neighbors(ctx context.Context, store core.Store, chunkID string, depth int) ([]core.ChunkEdge, []core.Chunk, error) {
+	gs, ok := store.(core.GraphStore)
+	if !ok {
+		return nil, nil, ErrNoGraphStore
+	}
+	if depth <= 0 {
+		depth = 1
+	}
+
+	visited := map[string]bool{chunkID: true}
+	seenEdge := make(map[string]bool)
+	var (
+		edges   []core.ChunkEdge
+		reached []string
+	)
+	frontier := []string{chunkID}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		hopEdges, err := bothEdges(ctx, gs, frontier)
+		if err != nil {
+			return nil, nil, fmt.Errorf("hop %d: %w", hop+1, err)
+		}
+		var next []string
+		for _, e := range hopEdges {
+			key := e.ID
+			if key == "" {
+				key = e.SourceID + "\x00" + e.TargetID + "\x00" + string(e.Relation)
+			}
+			if seenEdge[key] {
+				continue
+			}
+			seenEdge[key] = true
+			edges = append(edges, e)
+			for _, id := range []string{e.SourceID, e.TargetID} {
+				if !visited[id] {
+					visited[id] = true
+					reached = append(reached, id)
+					next = append(next, id)
+				}
+			}
+		}
+		frontier = next
+	}
+	if len(reached) == 0 {
+		return edges, nil, nil
+	}
+
+	fetched, err := store.GetChunksByIDs(ctx, reached)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get chunks: %w", err)
+	}
+	byID := make(map[string]core.Chunk, len(fetched))
+	for _, c := range fetched {
+		byID[c.ID] = c
+	}
+	chunks := make([]core.Chunk, 0, len(fetched))
+	for _, id := range reached {
+		if c, ok := byID[id]; ok {
+			chunks = append(chunks, c)
+		}
+	}
+	return edges, chunks, nil
+}
+
+// bothEdges fetches outgoing and incoming edges of ids, in one query when
+// the store implements core.BidirectionalGraphStore.
+func bothEdges(ctx context.Context, gs core.GraphStore, ids []string) ([]core.ChunkEdge, error) {
+	if bgs, ok := gs.(core.BidirectionalGraphStore); ok {
+		return bgs.GetBothEdges(ctx, ids)
+	}
+	out, err := gs.GetEdges(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	in, err := gs.GetIncomingEdges(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, in...), nil
+}
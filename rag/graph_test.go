@@ -0,0 +1,70 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+func TestNeighbors(t *testing.T) {
+	ab := core.ChunkEdge{ID: "ab", SourceID: "a", TargetID: "b", Relation: core.RelReferences, Weight: 0.9}
+	ca := core.ChunkEdge{ID: "ca", SourceID: "c", TargetID: "a", Relation: core.RelElaborates, Weight: 0.8}
+	bd := core.ChunkEdge{ID: "bd", SourceID: "b", TargetID: "d", Relation: core.RelDependsOn, Weight: 0.7}
+	store := &graphTestStoreWithEdges{
+		graphTestStore: graphTestStore{allChunks: map[string]core.Chunk{
+			"a": {ID: "a"}, "b": {ID: "b"}, "c": {ID: "c"}, "d": {ID: "d"},
+		}},
+		edges:         map[string][]core.ChunkEdge{"a": {ab}, "c": {ca}, "b": {bd}},
+		incomingEdges: map[string][]core.ChunkEdge{"b": {ab}, "a": {ca}, "d": {bd}},
+	}
+
+	edges, chunks, err := Neighbors(context.Background(), store, "a", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := edgeIDs(edges); got != "ab,ca" {
+		t.Errorf("depth 1 edges = %s, want ab,ca", got)
+	}
+	if got := chunkIDs(chunks); got != "b,c" {
+		t.Errorf("depth 1 chunks = %s, want b,c", got)
+	}
+
+	edges, chunks, err = Neighbors(context.Background(), store, "a", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := edgeIDs(edges); got != "ab,ca,bd" {
+		t.Errorf("depth 2 edges = %s, want ab,ca,bd (each once)", got)
+	}
+	if got := chunkIDs(chunks); got != "b,c,d" {
+		t.Errorf("depth 2 chunks = %s, want b,c,d", got)
+	}
+
+	if _, _, err := Neighbors(context.Background(), nopStore{}, "a", 1); !errors.Is(err, ErrNoGraphStore) {
+		t.Errorf("non-graph store: err = %v, want ErrNoGraphStore", err)
+	}
+}
+
+func edgeIDs(edges []core.ChunkEdge) string {
+	var s string
+	for i, e := range edges {
+		if i > 0 {
+			s += ","
+		}
+		s += e.ID
+	}
+	return s
+}
+
+func chunkIDs(chunks []core.Chunk) string {
+	var s string
+	for i, c := range chunks {
+		if i > 0 {
+			s += ","
+		}
+		s += c.ID
+	}
+	return s
+}
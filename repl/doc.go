@@ -0,0 +1,14 @@
+// Package repl runs an agent as an interactive terminal session for local
+// development: type a line, watch the streamed answer and tool calls, answer
+// ask_user questions inline. It replaces spinning up a chat-platform bot just
+// to exercise one tool.
+//
+// Wire it into a small main with your own provider and tools:
+//
+//	once := flag.String("once", "", "run a single prompt and exit")
+//	flag.Parse()
+//	err := repl.Run(ctx, myAgent, repl.WithOnce(*once))
+//
+// Every line of a session shares one thread ID, so conversation memory
+// (agent.WithMemory) carries across lines.
+package repl
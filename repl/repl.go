@@ -0,0 +1,278 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nevindra/oasis/agent"
+	"github.com/nevindra/oasis/core"
+)
+
+// maxToolResultPreview caps how much of a tool result is echoed per call.
+const maxToolResultPreview = 200
+
+// ANSI escape sequences used for prefixes.
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+	ansiBold  = "\x1b[1m"
+)
+
+type options struct {
+	in       io.Reader
+	out      io.Writer
+	threadID string
+	userID   string
+	once     string
+	color    bool
+	prompt   string
+}
+
+// Option configures Run.
+type Option func(*options)
+
+// WithInput sets the line source (default os.Stdin).
+func WithInput(r io.Reader) Option { return func(o *options) { o.in = r } }
+
+// WithOutput sets where events are printed (default os.Stdout).
+func WithOutput(w io.Writer) Option { return func(o *options) { o.out = w } }
+
+// WithThreadID pins the session thread ID (default: a fresh ID per Run).
+// Reuse one across processes to continue a stored conversation.
+func WithThreadID(id string) Option { return func(o *options) { o.threadID = id } }
+
+// WithUserID sets AgentTask.UserID for every line.
+func WithUserID(id string) Option { return func(o *options) { o.userID = id } }
+
+// WithOnce runs the single prompt p, prints the result, and returns. An
+// empty p keeps the interactive loop, so a --once flag can pass through
+// unconditionally.
+func WithOnce(p string) Option { return func(o *options) { o.once = p } }
+
+// WithColor forces ANSI colors on or off. By default colors are on unless
+// the NO_COLOR environment variable is set.
+func WithColor(on bool) Option { return func(o *options) { o.color = on } }
+
+// Run drives a as a terminal session until the input ends, the user types
+// /exit, or ctx is cancelled. Each line becomes an AgentTask whose events
+// stream to the output: text as it arrives, tool calls and results on
+// prefixed lines. The run's InputHandler prompts on the terminal, so
+// ask_user works without extra wiring.
+//
+// Agent errors are printed and the session continues; Run returns only
+// input errors and ctx cancellation. With WithOnce it returns the agent's
+// error instead, for scripting.
+func Run(ctx context.Context, a core.Agent, opts ...Option) error {
+	o := options{
+		in:     os.Stdin,
+		out:    os.Stdout,
+		color:  os.Getenv("NO_COLOR") == "",
+		prompt: "> ",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.threadID == "" {
+		o.threadID = core.NewID()
+	}
+	s := &session{
+		agent: a,
+		opts:  o,
+		lines: bufio.NewScanner(o.in),
+	}
+
+	if o.once != "" {
+		return s.turn(ctx, o.once)
+	}
+
+	for {
+		s.printf("%s", s.paint(ansiBold, o.prompt))
+		line, err := s.readLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				s.printf("\n")
+				return nil
+			}
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch line {
+		case "":
+			continue
+		case "/exit", "/quit":
+			return nil
+		}
+		if err := s.turn(ctx, line); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.printf("%s\n", s.paint(ansiRed, "error: "+err.Error()))
+		}
+	}
+}
+
+// session is the state of one Run: output is shared between the event
+// printer and the ask_user prompt, so writes go through mu.
+type session struct {
+	agent core.Agent
+	opts  options
+	lines *bufio.Scanner
+
+	mu      sync.Mutex
+	midLine bool // last write left the cursor mid-line (streamed text)
+}
+
+// turn runs one input line through the agent and prints its events.
+func (s *session) turn(ctx context.Context, input string) error {
+	task := agent.AgentTask{Input: input, ThreadID: s.opts.threadID, UserID: s.opts.userID}
+
+	ch := make(chan core.StreamEvent, 64)
+	type outcome struct {
+		res agent.AgentResult
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		res, err := s.agent.Execute(ctx, task, core.WithStream(ch),
+			agent.WithOverrides(&agent.RunOptions{InputHandler: s}))
+		done <- outcome{res, err}
+	}()
+
+	streamed := false
+	for ev := range ch {
+		if ev.Type == core.EventTextDelta && ev.Content != "" {
+			streamed = true
+		}
+		s.render(ev)
+	}
+	out := <-done
+	if out.err == nil && !streamed && out.res.Output != "" {
+		// Agents that don't stream text deltas still show their answer.
+		s.printf("%s", out.res.Output)
+		s.mu.Lock()
+		s.midLine = true
+		s.mu.Unlock()
+	}
+	s.endLine()
+	return out.err
+}
+
+// render prints one event. Text deltas are written raw so the answer reads
+// naturally; tool activity goes on its own prefixed lines.
+func (s *session) render(ev core.StreamEvent) {
+	switch ev.Type {
+	case core.EventTextDelta:
+		if ev.Content == "" {
+			return
+		}
+		s.mu.Lock()
+		fmt.Fprint(s.opts.out, ev.Content)
+		s.midLine = !strings.HasSuffix(ev.Content, "\n")
+		s.mu.Unlock()
+	case core.EventToolCallStart:
+		s.endLine()
+		s.printf("%s\n", s.paint(ansiCyan, fmt.Sprintf("→ %s %s", ev.Name, string(ev.Args))))
+	case core.EventToolCallResult:
+		s.endLine()
+		color, label := ansiGreen, "←"
+		if ev.IsError {
+			color, label = ansiRed, "✗"
+		}
+		s.printf("%s\n", s.paint(color, fmt.Sprintf("%s %s: %s", label, ev.Name, preview(ev.Content))))
+	case core.EventAgentStart:
+		s.endLine()
+		s.printf("%s\n", s.paint(ansiDim, "» "+ev.Name))
+	}
+}
+
+// RequestInput implements agent.InputHandler by printing the question and
+// reading the answer from the session's input. The agent goroutine calls it
+// while the main loop is blocked draining events, so the input is never
+// read from two goroutines at once.
+func (s *session) RequestInput(ctx context.Context, req agent.InputRequest) (agent.InputResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return agent.InputResponse{}, err
+	}
+	s.endLine()
+	s.printf("%s\n", s.paint(ansiBold, "? "+req.Question))
+	for i, opt := range req.Options {
+		s.printf("  %d) %s\n", i+1, opt)
+	}
+	s.printf("%s", s.paint(ansiBold, "answer> "))
+	line, err := s.readLine()
+	if err != nil {
+		return agent.InputResponse{}, fmt.Errorf("read answer: %w", err)
+	}
+	line = strings.TrimSpace(line)
+	if !req.MultiSelect {
+		return agent.InputResponse{Value: pickOption(line, req.Options)}, nil
+	}
+	var values []string
+	for _, part := range strings.Split(line, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, pickOption(part, req.Options))
+		}
+	}
+	return agent.InputResponse{Values: values}, nil
+}
+
+// pickOption maps a 1-based option number to its text; anything else is
+// returned as typed.
+func pickOption(answer string, options []string) string {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1]
+	}
+	return answer
+}
+
+func (s *session) readLine() (string, error) {
+	if s.lines.Scan() {
+		return s.lines.Text(), nil
+	}
+	if err := s.lines.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+func (s *session) printf(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.opts.out, format, args...)
+	s.midLine = false
+}
+
+// endLine terminates a partially written text line before a prefixed line.
+func (s *session) endLine() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.midLine {
+		fmt.Fprintln(s.opts.out)
+		s.midLine = false
+	}
+}
+
+func (s *session) paint(color, text string) string {
+	if !s.opts.color {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+func preview(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if r := []rune(s); len(r) > maxToolResultPreview {
+		return string(r[:maxToolResultPreview]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nevindra/oasis/agent"
+	"github.com/nevindra/oasis/core"
+)
+
+// scriptedProvider returns responses in order and records each request.
+type scriptedProvider struct {
+	mu        sync.Mutex
+	responses []core.ChatResponse
+	reqs      []core.ChatRequest
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+func (p *scriptedProvider) ChatStream(_ context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+	if ch != nil {
+		defer close(ch)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reqs = append(p.reqs, req)
+	resp := core.ChatResponse{Content: "exhausted"}
+	if len(p.responses) > 0 {
+		resp = p.responses[0]
+		p.responses = p.responses[1:]
+	}
+	if ch != nil && resp.Content != "" {
+		ch <- core.StreamEvent{Type: core.EventTextDelta, Content: resp.Content}
+	}
+	return resp, nil
+}
+
+// threadRecorder records the ThreadID of every task it runs.
+type threadRecorder struct {
+	threads []string
+	err     error
+}
+
+func (a *threadRecorder) Name() string        { return "recorder" }
+func (a *threadRecorder) Description() string { return "records thread ids" }
+func (a *threadRecorder) Execute(_ context.Context, task agent.AgentTask, opts ...core.RunOption) (agent.AgentResult, error) {
+	a.threads = append(a.threads, task.ThreadID)
+	if ch := core.ApplyRunOptions(opts...).Stream; ch != nil {
+		close(ch)
+	}
+	return agent.AgentResult{Output: "re: " + task.Input}, a.err
+}
+
+func TestRunSharesThreadAcrossLines(t *testing.T) {
+	a := &threadRecorder{}
+	var out bytes.Buffer
+	err := Run(context.Background(), a,
+		WithInput(strings.NewReader("first\n\nsecond\n/exit\nnever\n")),
+		WithOutput(&out), WithColor(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.threads) != 2 {
+		t.Fatalf("runs = %d, want 2 (blank skipped, stop at /exit)", len(a.threads))
+	}
+	if a.threads[0] == "" || a.threads[0] != a.threads[1] {
+		t.Errorf("thread ids = %v, want one shared non-empty id", a.threads)
+	}
+	// Non-streaming agents still print their output.
+	if !strings.Contains(out.String(), "re: first") || !strings.Contains(out.String(), "re: second") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	a := &threadRecorder{err: errors.New("boom")}
+	var out bytes.Buffer
+	err := Run(context.Background(), a, WithOnce("just this"), WithThreadID("t1"),
+		WithInput(strings.NewReader("ignored\n")), WithOutput(&out), WithColor(false))
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("err = %v, want the agent error", err)
+	}
+	if len(a.threads) != 1 || a.threads[0] != "t1" {
+		t.Errorf("threads = %v, want [t1]", a.threads)
+	}
+}
+
+func TestRunRendersToolsAndAsksUser(t *testing.T) {
+	p := &scriptedProvider{responses: []core.ChatResponse{
+		{ToolCalls: []core.ToolCall{{ID: "1", Name: core.ToolAskUser, Args: json.RawMessage(`{"question":"Which size?","options":["small","large"]}`)}}},
+		{Content: "large it is"},
+	}}
+	var out bytes.Buffer
+	err := Run(context.Background(), agent.New("asker", "asks", p),
+		WithOnce("order"), WithInput(strings.NewReader("2\n")), WithOutput(&out), WithColor(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{"→ ask_user", "? Which size?", "2) large", "← ask_user: large", "large it is"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPickOption(t *testing.T) {
+	opts := []string{"a", "b"}
+	tests := map[string]string{"1": "a", "2": "b", "3": "3", "b": "b", "0": "0"}
+	for in, want := range tests {
+		if got := pickOption(in, opts); got != want {
+			t.Errorf("pickOption(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package sandbox
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// WithAllowedCommands restricts the shell tool to commands whose program
+// name (argv[0], compared by base name so /usr/bin/git matches "git") is in
+// the list. Every command in a pipeline, list (&&, ||, ;) or command
+// substitution is checked, and a disallowed one rejects the whole call
+// before anything runs. The rejection is returned to the model as a tool
+// error naming the allowed commands.
+//
+// The check is lexical. It does not stop an allowed program from running
+// others (find -exec, xargs, git -c core.pager=...), so allow only programs
+// you would let the model drive freely, and keep execute_code in mind: it
+// is not covered.
+func WithAllowedCommands(commands ...string) ToolsOption {
+	return func(c *toolsConfig) {
+		if c.allowedCommands == nil {
+			c.allowedCommands = make(map[string]bool, len(commands))
+		}
+		for _, cmd := range commands {
+			c.allowedCommands[cmd] = true
+		}
+	}
+}
+
+// WithDeniedCommands rejects shell calls that run any of the named
+// programs, checked the same way as WithAllowedCommands. A denylist is
+// easy to route around (sh -c, env, interpreters); prefer an allowlist
+// when the command set is known.
+func WithDeniedCommands(commands ...string) ToolsOption {
+	return func(c *toolsConfig) {
+		if c.deniedCommands == nil {
+			c.deniedCommands = make(map[string]bool, len(commands))
+		}
+		for _, cmd := range commands {
+			c.deniedCommands[cmd] = true
+		}
+	}
+}
+
+// WithShellPath runs every shell command with PATH set to dirs, so only
+// binaries in those directories resolve by bare name.
+func WithShellPath(dirs ...string) ToolsOption {
+	return func(c *toolsConfig) { c.shellPath = strings.Join(dirs, ":") }
+}
+
+// checkShellCommand applies the configured allow/deny lists to command.
+// It returns a message for the model, or "" when the command may run.
+func (c *toolsConfig) checkShellCommand(command string) string {
+	if c.allowedCommands == nil && c.deniedCommands == nil {
+		return ""
+	}
+	for _, name := range commandNames(command) {
+		if c.deniedCommands[name] {
+			return fmt.Sprintf("command %q is not permitted in this sandbox; use a different approach", name)
+		}
+		if c.allowedCommands != nil && !c.allowedCommands[name] {
+			return fmt.Sprintf("command %q is not permitted in this sandbox; allowed commands: %s",
+				name, strings.Join(c.allowedCommandList(), ", "))
+		}
+	}
+	return ""
+}
+
+func (c *toolsConfig) allowedCommandList() []string {
+	names := make([]string, 0, len(c.allowedCommands))
+	for n := range c.allowedCommands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// wrapShellCommand applies WithShellPath to command.
+func (c *toolsConfig) wrapShellCommand(command string) string {
+	if c.shellPath == "" {
+		return command
+	}
+	return "export PATH=" + shellQuote(c.shellPath) + "\n" + command
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// commandNames returns the program name of every simple command in a shell
+// command line. Operators (| & ; newline), parentheses, braces and command
+// substitution ($(...) and backticks) start a new command; quotes, escapes,
+// redirections and heredoc bodies are understood; leading VAR=value
+// assignments and shell keywords are skipped. Names are base names, so
+// "/bin/rm" yields "rm".
+func commandNames(command string) []string {
+	var names []string
+	var word strings.Builder
+	inWord := false   // a word has started, even if it is empty ("")
+	atStart := true   // the next word is in command position
+	skipNext := false // the next word is a redirection target
+	heredoc := ""     // delimiter of a pending heredoc body
+	heredocNext := false
+
+	flush := func() {
+		if !inWord {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		inWord = false
+		switch {
+		case heredocNext:
+			heredoc, heredocNext = w, false
+			return
+		case skipNext:
+			skipNext = false
+			return
+		case isRedirect(w):
+			op := strings.TrimLeft(w, "0123456789&")
+			if strings.HasPrefix(op, "<<") && !strings.HasPrefix(op, "<<<") {
+				if d := strings.TrimPrefix(strings.TrimPrefix(op, "<<"), "-"); d != "" {
+					heredoc = d
+				} else {
+					heredocNext = true
+				}
+				return
+			}
+			skipNext = strings.Trim(op, "<>&|") == ""
+			return
+		case !atStart:
+			return
+		case w == "for" || w == "case" || w == "select":
+			atStart = false // the header up to ";" or newline is not a command
+			return
+		case shellKeywords[w] || isAssignment(w):
+			return // still in command position
+		}
+		names = append(names, path.Base(w))
+		atStart = false
+	}
+	boundary := func() {
+		flush()
+		atStart = true
+	}
+
+	for i := 0; i < len(command); i++ {
+		ch := command[i]
+		switch {
+		case ch == '\\' && i+1 < len(command):
+			i++
+			word.WriteByte(command[i])
+			inWord = true
+		case ch == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				end = len(command) - i - 1
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case ch == '"':
+			// Substitutions inside double quotes still run; check them too.
+			j := i + 1
+			for j < len(command) && command[j] != '"' {
+				if command[j] == '\\' && j+1 < len(command) {
+					word.WriteByte(command[j+1])
+					j += 2
+					continue
+				}
+				if n := expansionLen(command[j:]); n > 0 {
+					word.WriteString(command[j : j+n])
+					j += n
+					continue
+				}
+				if command[j] == '`' || (command[j] == '$' && j+1 < len(command) && command[j+1] == '(') {
+					names = append(names, commandNames(substitution(command, &j))...)
+					continue
+				}
+				word.WriteByte(command[j])
+				j++
+			}
+			inWord = true
+			i = j
+		case expansionLen(command[i:]) > 0:
+			n := expansionLen(command[i:])
+			word.WriteString(command[i : i+n])
+			inWord = true
+			i += n - 1
+		case ch == '$' && i+1 < len(command) && command[i+1] == '(':
+			names = append(names, commandNames(substitution(command, &i))...)
+			i--
+			inWord = true
+		case ch == '`':
+			names = append(names, commandNames(substitution(command, &i))...)
+			i--
+			inWord = true
+		case ch == '&' && ((i > 0 && (command[i-1] == '>' || command[i-1] == '<')) || (i+1 < len(command) && command[i+1] == '>')):
+			// Part of a redirection: 2>&1, >&2, &>file.
+			word.WriteByte(ch)
+			inWord = true
+		case ch == '<' || ch == '>':
+			// A redirection operator ends the previous word unless that word
+			// is a file descriptor number (2>file) or operator so far (>>).
+			if inWord && strings.Trim(word.String(), "0123456789&<>") != "" {
+				flush()
+			}
+			word.WriteByte(ch)
+			inWord = true
+		case ch == '\n':
+			boundary()
+			if heredoc != "" {
+				i = skipHeredoc(command, i+1, heredoc) - 1
+				heredoc = ""
+			}
+		case ch == '|' || ch == '&' || ch == ';' || ch == '(' || ch == ')' || ch == '{' || ch == '}':
+			boundary()
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			flush()
+		case ch == '#' && !inWord:
+			for i+1 < len(command) && command[i+1] != '\n' {
+				i++
+			}
+		default:
+			word.WriteByte(ch)
+			inWord = true
+		}
+	}
+	flush()
+	return names
+}
+
+// expansionLen returns the length of a ${...} parameter expansion or
+// $((...)) arithmetic expansion at the start of s, or 0. Neither runs a
+// command, so both stay part of the current word.
+func expansionLen(s string) int {
+	var open, close string
+	switch {
+	case strings.HasPrefix(s, "$(("):
+		open, close = "((", "))"
+	case strings.HasPrefix(s, "${"):
+		open, close = "{", "}"
+	default:
+		return 0
+	}
+	end := strings.Index(s[1+len(open):], close)
+	if end < 0 {
+		return len(s)
+	}
+	return 1 + len(open) + end + len(close)
+}
+
+// skipHeredoc returns the index just past the line equal to delim (leading
+// tabs ignored, as with <<-), starting the search at from.
+func skipHeredoc(command string, from int, delim string) int {
+	for i := from; i < len(command); {
+		end := strings.IndexByte(command[i:], '\n')
+		line := command[i:]
+		next := len(command)
+		if end >= 0 {
+			line = command[i : i+end]
+			next = i + end + 1
+		}
+		if strings.TrimLeft(line, "\t") == delim {
+			return next
+		}
+		i = next
+	}
+	return len(command)
+}
+
+// isRedirect reports whether w is a redirection word such as >, 2>>, <file,
+// &>out or 2>&1.
+func isRedirect(w string) bool {
+	op := strings.TrimLeft(w, "0123456789&")
+	return op != "" && (op[0] == '<' || op[0] == '>')
+}
+
+// substitution returns the body of the $(...) or `...` starting at
+// command[*i] and advances *i past it.
+func substitution(command string, i *int) string {
+	if command[*i] == '`' {
+		end := strings.IndexByte(command[*i+1:], '`')
+		if end < 0 {
+			body := command[*i+1:]
+			*i = len(command)
+			return body
+		}
+		body := command[*i+1 : *i+1+end]
+		*i += end + 2
+		return body
+	}
+	start := *i + 2
+	depth := 1
+	j := start
+	for ; j < len(command) && depth > 0; j++ {
+		switch command[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	end := j
+	if depth == 0 {
+		end = j - 1
+	}
+	*i = j
+	return command[start:end]
+}
+
+func isAssignment(w string) bool {
+	eq := strings.IndexByte(w, '=')
+	if eq <= 0 {
+		return false
+	}
+	for i := 0; i < eq; i++ {
+		c := w[i]
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// shellKeywords precede a command without being one.
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true, "!": true,
+	"time": true, "esac": true, "in": true,
+}
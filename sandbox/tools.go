@@ -38,6 +38,10 @@ func (t toolImpl) ExecuteRaw(ctx context.Context, args json.RawMessage) (oasis.T
 	return t.execute(ctx, args)
 }
 
+// Cacheable implements oasis.CacheableTool. Sandbox tools read and change
+// the sandbox's files and processes, so a repeated call must run again.
+func (t toolImpl) Cacheable() bool { return false }
+
 // ToolsOption configures optional sandbox tool capabilities.
 type ToolsOption func(*toolsConfig)
 
@@ -46,6 +50,11 @@ type toolsConfig struct {
 	mounts    []MountSpec
 	manifest  *Manifest
 	noBrowser bool
+
+	// Shell restrictions; see shell_policy.go.
+	allowedCommands map[string]bool // nil = any command
+	deniedCommands  map[string]bool
+	shellPath       string
 }
 
 // WithFileDelivery enables the deliver_file tool with a single legacy
@@ -229,7 +238,7 @@ func Tools(sb Sandbox, opts ...ToolsOption) []oasis.AnyTool {
 	}
 
 	tools := []oasis.AnyTool{
-		shellTool(sb),
+		shellTool(sb, cfg),
 		executeCodeTool(sb),
 		fileReadTool(sb),
 		fileWriteTool(sb, cfg),
@@ -278,16 +287,23 @@ func hasWriteableMount(mounts []MountSpec) bool {
 	return false
 }
 
-func shellTool(sb Sandbox) toolImpl {
+func shellTool(sb Sandbox, cfg *toolsConfig) toolImpl {
+	desc := "Execute a shell command in the sandbox. Use for system tasks, running builds, git operations, installing packages, and commands that don't have a dedicated tool. Do NOT use shell for: reading files (use file_read), searching file contents (use file_grep), finding files (use file_glob), writing files (use file_write), editing files (use file_edit), listing directory trees (use file_tree), or fetching URLs (use http_fetch)."
+	if cfg.allowedCommands != nil {
+		desc += " Only these commands are permitted: " + strings.Join(cfg.allowedCommandList(), ", ") + "."
+	}
 	return newTool("shell",
-		"Execute a shell command in the sandbox. Use for system tasks, running builds, git operations, installing packages, and commands that don't have a dedicated tool. Do NOT use shell for: reading files (use file_read), searching file contents (use file_grep), finding files (use file_glob), writing files (use file_write), editing files (use file_edit), listing directory trees (use file_tree), or fetching URLs (use http_fetch).",
+		desc,
 		string(core.DeriveSchema[shellArgs]()),
 		func(ctx context.Context, args json.RawMessage) (oasis.ToolResult, error) {
 			var p shellArgs
 			if err := json.Unmarshal(args, &p); err != nil {
 				return oasis.ToolResult{Error: "invalid args: " + err.Error()}, nil
 			}
-			res, err := sb.Shell(ctx, ShellRequest{Command: p.Command, Cwd: p.Cwd})
+			if msg := cfg.checkShellCommand(p.Command); msg != "" {
+				return oasis.ToolResult{Error: msg}, nil
+			}
+			res, err := sb.Shell(ctx, ShellRequest{Command: cfg.wrapShellCommand(p.Command), Cwd: p.Cwd})
 			if err != nil {
 				return oasis.ToolResult{Error: err.Error()}, nil
 			}
@@ -822,6 +838,9 @@ func (t *deliverFile) ExecuteRaw(ctx context.Context, args json.RawMessage) (oas
 	return t.executeDelivery(ctx, args, nil)
 }
 
+// Cacheable implements oasis.CacheableTool. Every call delivers the file.
+func (t *deliverFile) Cacheable() bool { return false }
+
 func (t *deliverFile) ExecuteStream(ctx context.Context, args json.RawMessage, ch chan<- oasis.StreamEvent) (oasis.ToolResult, error) {
 	return t.executeDelivery(ctx, args, ch)
 }
@@ -8,7 +8,9 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/nevindra/oasis/agent"
 	oasis "github.com/nevindra/oasis/core"
 )
 
@@ -1407,3 +1409,56 @@ func TestFindMountForPathPrefersDeepest(t *testing.T) {
 		t.Errorf("key = %q, want %q", key, "report.md")
 	}
 }
+
+// nopProvider satisfies oasis.Provider for tests that only run tools.
+type nopProvider struct{}
+
+func (nopProvider) Name() string { return "nop" }
+func (nopProvider) ChatStream(_ context.Context, _ oasis.ChatRequest, ch chan<- oasis.StreamEvent) (oasis.ChatResponse, error) {
+	if ch != nil {
+		close(ch)
+	}
+	return oasis.ChatResponse{}, nil
+}
+
+func TestTools_NotServedFromToolCache(t *testing.T) {
+	ctx := context.Background()
+	files := map[string]string{}
+	runs := 0
+	sb := &mockSandbox{
+		writeFileFn: func(_ context.Context, req WriteFileRequest) error {
+			files[req.Path] = req.Content
+			return nil
+		},
+		readFileFn: func(_ context.Context, req ReadFileRequest) (FileContent, error) {
+			return FileContent{Content: files[req.Path], Path: req.Path}, nil
+		},
+		shellFn: func(context.Context, ShellRequest) (ShellResult, error) {
+			runs++
+			return ShellResult{Output: fmt.Sprint(runs)}, nil
+		},
+	}
+	ag := agent.New("a", "", nopProvider{}, agent.WithTools(Tools(sb)...),
+		agent.WithToolCache(oasis.NewInMemoryToolCache(), time.Hour))
+
+	read := json.RawMessage(`{"path":"/a.txt"}`)
+	for _, content := range []string{"one", "two"} {
+		args, _ := json.Marshal(map[string]string{"path": "/a.txt", "content": content})
+		if r, err := ag.Tools().Execute(ctx, "file_write", args); err != nil || r.Error != "" {
+			t.Fatalf("file_write: %v %s", err, r.Error)
+		}
+		r, err := ag.Tools().Execute(ctx, "file_read", read)
+		if err != nil || r.Content != content {
+			t.Errorf("file_read after writing %q = %q, %v", content, r.Content, err)
+		}
+	}
+
+	for range 2 {
+		if _, err := ag.Tools().Execute(ctx, "shell", json.RawMessage(`{"command":"date"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs != 2 {
+		t.Errorf("shell ran %d times, want 2", runs)
+	}
+}
@@ -0,0 +1,135 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// embeddingSearcher ranks skills by cosine similarity between the query and
+// each skill's description. Skill vectors are cached by name and re-embedded
+// only when the indexed text changes, so a query costs one embedding call
+// plus one for each new or edited skill.
+type embeddingSearcher struct {
+	provider  SkillProvider
+	embedding core.EmbeddingProvider
+
+	mu      sync.Mutex
+	indexed map[string]indexedSkill
+}
+
+type indexedSkill struct {
+	text string
+	vec  []float32
+}
+
+// NewEmbeddingSearcher returns a SkillSearcher that matches queries to skills
+// semantically, using emb to embed each skill's name, description and tags.
+// Skills are discovered from p on every query, so new skills are searchable
+// at once; unchanged skills are not re-embedded.
+func NewEmbeddingSearcher(p SkillProvider, emb core.EmbeddingProvider) SkillSearcher {
+	return &embeddingSearcher{provider: p, embedding: emb, indexed: make(map[string]indexedSkill)}
+}
+
+// Compile-time check.
+var _ SkillSearcher = (*embeddingSearcher)(nil)
+
+func (s *embeddingSearcher) SearchSkills(ctx context.Context, query string, limit int) ([]SkillSearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	summaries, err := s.provider.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(summaries) == 0 {
+		return nil, nil
+	}
+	vecs, err := s.index(ctx, summaries)
+	if err != nil {
+		return nil, err
+	}
+	q, err := s.embedding.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(q) == 0 {
+		return nil, fmt.Errorf("embed query: provider returned no vectors")
+	}
+
+	results := make([]SkillSearchResult, 0, len(summaries))
+	for i, sm := range summaries {
+		if score := float64(core.CosineSimilarity(q[0], vecs[i])); score > 0 {
+			results = append(results, SkillSearchResult{SkillSummary: sm, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Name < results[j].Name
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// index returns a vector per summary, embedding only skills that are new or
+// whose text changed, and forgets skills no longer discovered.
+func (s *embeddingSearcher) index(ctx context.Context, summaries []SkillSummary) ([][]float32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	texts := make([]string, len(summaries))
+	var stale []int
+	for i, sm := range summaries {
+		texts[i] = skillIndexText(sm)
+		if e, ok := s.indexed[sm.Name]; !ok || e.text != texts[i] {
+			stale = append(stale, i)
+		}
+	}
+	if len(stale) > 0 {
+		batch := make([]string, len(stale))
+		for j, i := range stale {
+			batch[j] = texts[i]
+		}
+		vecs, err := s.embedding.Embed(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("embed skills: %w", err)
+		}
+		if len(vecs) != len(batch) {
+			return nil, fmt.Errorf("embed skills: got %d vectors for %d skills", len(vecs), len(batch))
+		}
+		for j, i := range stale {
+			s.indexed[summaries[i].Name] = indexedSkill{text: texts[i], vec: vecs[j]}
+		}
+	}
+
+	seen := make(map[string]bool, len(summaries))
+	out := make([][]float32, len(summaries))
+	for i, sm := range summaries {
+		seen[sm.Name] = true
+		out[i] = s.indexed[sm.Name].vec
+	}
+	for name := range s.indexed {
+		if !seen[name] {
+			delete(s.indexed, name)
+		}
+	}
+	return out, nil
+}
+
+// skillIndexText is the text embedded for a skill: what it is for, not its
+// full instructions, which would dilute the match.
+func skillIndexText(sm SkillSummary) string {
+	text := sm.Name + ": " + sm.Description
+	if len(sm.Tags) > 0 {
+		text += "\nTags: " + strings.Join(sm.Tags, ", ")
+	}
+	return text
+}
@@ -0,0 +1,102 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// wordEmbedding embeds text as counts over a tiny vocabulary and records
+// every text it embeds.
+type wordEmbedding struct {
+	mu       sync.Mutex
+	embedded []string
+}
+
+var vocabulary = []string{"deploy", "release", "invoice", "billing", "review", "code"}
+
+func (e *wordEmbedding) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.embedded = append(e.embedded, texts...)
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec := make([]float32, len(vocabulary))
+		for _, w := range tokenize(text) {
+			for d, v := range vocabulary {
+				if strings.HasPrefix(w, v) {
+					vec[d]++
+				}
+			}
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+func (e *wordEmbedding) Dimensions() int { return len(vocabulary) }
+func (e *wordEmbedding) Name() string    { return "words" }
+
+// listProvider serves a mutable list of skills.
+type listProvider struct{ skills []SkillSummary }
+
+func (p *listProvider) Discover(context.Context) ([]SkillSummary, error) { return p.skills, nil }
+func (p *listProvider) Activate(_ context.Context, name string) (Skill, error) {
+	return Skill{Name: name}, nil
+}
+
+func TestEmbeddingSearcher(t *testing.T) {
+	p := &listProvider{skills: []SkillSummary{
+		{Name: "ship", Description: "Deploy a release to production"},
+		{Name: "invoicing", Description: "Send the monthly billing invoice"},
+		{Name: "reviewer", Description: "Review code changes"},
+	}}
+	emb := &wordEmbedding{}
+	s := NewEmbeddingSearcher(p, emb)
+
+	results, err := s.SearchSkills(context.Background(), "how do I release?", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "ship" {
+		t.Fatalf("results = %+v, want only the deploy skill", results)
+	}
+	if len(emb.embedded) != 4 {
+		t.Errorf("embedded %d texts, want 3 skills and the query", len(emb.embedded))
+	}
+
+	// Unchanged skills are not re-embedded; edited and new ones are.
+	emb.embedded = nil
+	p.skills[1].Description = "Chase unpaid invoice reminders"
+	p.skills = append(p.skills, SkillSummary{Name: "rollback", Description: "Undo a bad deploy"})
+	results, err = s.SearchSkills(context.Background(), "deploy", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emb.embedded) != 3 {
+		t.Errorf("embedded %v, want the edited skill, the new skill and the query", emb.embedded)
+	}
+	if len(results) != 1 {
+		t.Errorf("results = %+v, want limit 1", results)
+	}
+}
+
+func TestSkillToolsWithSearcher(t *testing.T) {
+	called := false
+	custom := searchProvider{called: &called}
+	// The configured searcher wins over the provider's own SkillSearcher.
+	p := searchProvider{called: new(bool)}
+	for _, tl := range NewSkillTools(p, WithSearcher(custom)) {
+		if tl.Definition().Name != "skill_search" {
+			continue
+		}
+		res, err := tl.ExecuteRaw(context.Background(), json.RawMessage(`{"query":"anything"}`))
+		if err != nil || res.Error != "" {
+			t.Fatalf("skill_search = %+v, %v", res, err)
+		}
+	}
+	if !called || *p.called {
+		t.Errorf("custom searcher called = %v, provider searcher called = %v; want only the custom one", called, *p.called)
+	}
+}
@@ -12,23 +12,45 @@ import (
 // maxResourceBytes caps skill_read output to protect the model context window.
 const maxResourceBytes = 64 * 1024
 
+// ToolOption configures NewSkillTools.
+type ToolOption func(*toolConfig)
+
+type toolConfig struct {
+	searcher SkillSearcher
+}
+
+// WithSearcher sets the SkillSearcher behind skill_search, taking precedence
+// over the provider's own — for example NewEmbeddingSearcher for semantic
+// matching over a FromDir provider.
+func WithSearcher(s SkillSearcher) ToolOption {
+	return func(c *toolConfig) { c.searcher = s }
+}
+
 // NewSkillTools returns the set of skill-management tools backed by the given
 // SkillProvider. skill_discover, skill_activate, and skill_search are always
 // returned. skill_create and skill_update are included only when the provider
 // implements SkillWriter; skill_read and skill_list_resources only when it
-// implements SkillResources. skill_search uses the provider's own SkillSearcher
-// when present, else a built-in BM25 searcher.
-func NewSkillTools(provider SkillProvider) []core.AnyTool {
+// implements SkillResources. skill_search uses the WithSearcher searcher, else
+// the provider's own SkillSearcher when present, else a built-in BM25 searcher.
+func NewSkillTools(provider SkillProvider, opts ...ToolOption) []core.AnyTool {
+	var cfg toolConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	tools := []core.AnyTool{
 		core.Erase[skillDiscoverIn, string](&skillDiscoverTool{provider: provider}),
 		core.Erase[skillActivateIn, string](&skillActivateTool{provider: provider}),
 	}
 
-	// Search is always available: prefer the provider's own SkillSearcher,
-	// else fall back to the built-in BM25 searcher.
-	searcher, ok := provider.(SkillSearcher)
-	if !ok {
-		searcher = NewBM25Searcher(provider)
+	// Search is always available: prefer a configured searcher, then the
+	// provider's own SkillSearcher, else fall back to the built-in BM25 searcher.
+	searcher := cfg.searcher
+	if searcher == nil {
+		if s, ok := provider.(SkillSearcher); ok {
+			searcher = s
+		} else {
+			searcher = NewBM25Searcher(provider)
+		}
 	}
 	tools = append(tools, core.Erase[skillSearchIn, string](&skillSearchTool{searcher: searcher}))
 
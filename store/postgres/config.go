@@ -41,3 +41,42 @@ func (s *Store) SetConfig(ctx context.Context, key, value string) error {
 	s.logger.Debug("postgres: set config ok", "key", key, "duration", time.Since(start))
 	return nil
 }
+
+// ListConfig returns every config entry whose key starts with prefix.
+func (s *Store) ListConfig(ctx context.Context, prefix string) (map[string]string, error) {
+	start := time.Now()
+	s.logger.Debug("postgres: list config", "prefix", prefix)
+	rows, err := s.pool.Query(ctx,
+		`SELECT key, value FROM config WHERE left(key, length($1)) = $1`, prefix)
+	if err != nil {
+		s.logger.Error("postgres: list config failed", "prefix", prefix, "error", err, "duration", time.Since(start))
+		return nil, fmt.Errorf("postgres: list config: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("postgres: list config: %w", err)
+		}
+		entries[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list config: %w", err)
+	}
+	s.logger.Debug("postgres: list config ok", "prefix", prefix, "count", len(entries), "duration", time.Since(start))
+	return entries, nil
+}
+
+// DeleteConfig removes the config entry for key. A missing key is not an error.
+func (s *Store) DeleteConfig(ctx context.Context, key string) error {
+	start := time.Now()
+	s.logger.Debug("postgres: delete config", "key", key)
+	if _, err := s.pool.Exec(ctx, `DELETE FROM config WHERE key = $1`, key); err != nil {
+		s.logger.Error("postgres: delete config failed", "key", key, "error", err, "duration", time.Since(start))
+		return fmt.Errorf("postgres: delete config: %w", err)
+	}
+	s.logger.Debug("postgres: delete config ok", "key", key, "duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+var _ oasis.LeaseStore = (*Store)(nil)
+
+// --- Leases ---
+
+// leaseClock reads the database server's clock in Unix milliseconds, so
+// replicas with skewed wall clocks agree on when a lease expires.
+const leaseClock = `(SELECT (extract(epoch FROM clock_timestamp()) * 1000)::bigint AS ms)`
+
+// AcquireLease implements oasis.LeaseStore. The upsert only overwrites a
+// row that has expired or that this Store already holds.
+func (s *Store) AcquireLease(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	start := time.Now()
+	s.logger.Debug("postgres: acquire lease", "name", name, "ttl", ttl)
+
+	// EXCLUDED.expires_at - ttl recovers the same server time the new
+	// expiry was computed from.
+	tag, err := s.pool.Exec(ctx,
+		`INSERT INTO leases (name, holder, expires_at) SELECT $1, $2, clock.ms + $3 FROM `+leaseClock+` AS clock
+		 ON CONFLICT (name) DO UPDATE SET holder=EXCLUDED.holder, expires_at=EXCLUDED.expires_at
+		 WHERE leases.expires_at <= EXCLUDED.expires_at - $3 OR leases.holder = EXCLUDED.holder`,
+		name, s.leaseHolder, ttl.Milliseconds())
+	if err != nil {
+		s.logger.Error("postgres: acquire lease failed", "name", name, "error", err, "duration", time.Since(start))
+		return false, err
+	}
+	n := tag.RowsAffected()
+	s.logger.Debug("postgres: acquire lease ok", "name", name, "acquired", n == 1, "duration", time.Since(start))
+	return n == 1, nil
+}
+
+// RenewLease implements oasis.LeaseStore.
+func (s *Store) RenewLease(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	start := time.Now()
+	s.logger.Debug("postgres: renew lease", "name", name, "ttl", ttl)
+
+	tag, err := s.pool.Exec(ctx,
+		`UPDATE leases SET expires_at=clock.ms + $1 FROM `+leaseClock+` AS clock
+		 WHERE name=$2 AND holder=$3 AND expires_at > clock.ms`,
+		ttl.Milliseconds(), name, s.leaseHolder)
+	if err != nil {
+		s.logger.Error("postgres: renew lease failed", "name", name, "error", err, "duration", time.Since(start))
+		return false, err
+	}
+	n := tag.RowsAffected()
+	s.logger.Debug("postgres: renew lease ok", "name", name, "renewed", n == 1, "duration", time.Since(start))
+	return n == 1, nil
+}
+
+// ReleaseLease implements oasis.LeaseStore.
+func (s *Store) ReleaseLease(ctx context.Context, name string) error {
+	start := time.Now()
+	s.logger.Debug("postgres: release lease", "name", name)
+
+	_, err := s.pool.Exec(ctx, `DELETE FROM leases WHERE name=$1 AND holder=$2`, name, s.leaseHolder)
+	if err != nil {
+		s.logger.Error("postgres: release lease failed", "name", name, "error", err, "duration", time.Since(start))
+		return err
+	}
+	s.logger.Debug("postgres: release lease ok", "name", name, "duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+var _ oasis.StoreMaintainer = (*Store)(nil)
+
+// maintainedTables are vacuumed and analyzed by Vacuum.
+var maintainedTables = []string{"threads", "messages", "documents", "chunks", "chunk_edges"}
+
+// vectorIndexes are rebuilt by Vacuum when present (HNSW is optional).
+var vectorIndexes = []string{"chunks_embedding_idx", "messages_embedding_idx"}
+
+// Vacuum prunes chunks of missing documents and edges with a missing
+// endpoint, runs VACUUM (ANALYZE) on the core tables, and rebuilds the HNSW
+// vector indexes with REINDEX CONCURRENTLY so deleted vectors stop costing
+// search time. None of these steps block reads; REINDEX CONCURRENTLY does
+// not block writes either. It needs PostgreSQL 12 or later.
+func (s *Store) Vacuum(ctx context.Context) (oasis.VacuumResult, error) {
+	start := time.Now()
+	s.logger.Debug("postgres: vacuum started")
+
+	var res oasis.VacuumResult
+	var err error
+	if res.SizeBefore, err = s.dbSize(ctx); err != nil {
+		return res, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return res, fmt.Errorf("postgres: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+	tag, err := tx.Exec(ctx, `DELETE FROM chunks WHERE document_id NOT IN (SELECT id FROM documents)`)
+	if err != nil {
+		return res, fmt.Errorf("postgres: prune orphan chunks: %w", err)
+	}
+	res.OrphanChunks = int(tag.RowsAffected())
+	tag, err = tx.Exec(ctx,
+		`DELETE FROM chunk_edges WHERE source_id NOT IN (SELECT id FROM chunks) OR target_id NOT IN (SELECT id FROM chunks)`)
+	if err != nil {
+		return res, fmt.Errorf("postgres: prune orphan edges: %w", err)
+	}
+	res.OrphanEdges = int(tag.RowsAffected())
+	if err := tx.Commit(ctx); err != nil {
+		return res, fmt.Errorf("postgres: commit prune: %w", err)
+	}
+
+	// VACUUM and REINDEX CONCURRENTLY cannot run inside a transaction.
+	for _, table := range maintainedTables {
+		if _, err := s.pool.Exec(ctx, `VACUUM (ANALYZE) `+table); err != nil {
+			s.logger.Error("postgres: vacuum failed", "table", table, "error", err, "duration", time.Since(start))
+			return res, fmt.Errorf("postgres: vacuum %s: %w", table, err)
+		}
+	}
+	for _, idx := range vectorIndexes {
+		var exists bool
+		if err := s.pool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, idx).Scan(&exists); err != nil || !exists {
+			continue
+		}
+		if _, err := s.pool.Exec(ctx, `REINDEX INDEX CONCURRENTLY `+idx); err != nil {
+			s.logger.Error("postgres: reindex failed", "index", idx, "error", err, "duration", time.Since(start))
+			return res, fmt.Errorf("postgres: reindex %s: %w", idx, err)
+		}
+	}
+
+	if res.SizeAfter, err = s.dbSize(ctx); err != nil {
+		return res, err
+	}
+	s.logger.Info("postgres: vacuum completed",
+		"orphan_chunks", res.OrphanChunks, "orphan_edges", res.OrphanEdges,
+		"size_before", res.SizeBefore, "size_after", res.SizeAfter, "duration", time.Since(start))
+	return res, nil
+}
+
+// Stats reports row counts and the database size. FreeBytes is not
+// reported: plain VACUUM reuses dead space in place rather than freeing it.
+func (s *Store) Stats(ctx context.Context) (oasis.StoreStats, error) {
+	var st oasis.StoreStats
+	err := s.pool.QueryRow(ctx, `SELECT
+		(SELECT count(*) FROM threads),
+		(SELECT count(*) FROM messages),
+		(SELECT count(*) FROM documents),
+		(SELECT count(*) FROM chunks),
+		(SELECT count(*) FROM chunk_edges)`,
+	).Scan(&st.Threads, &st.Messages, &st.Documents, &st.Chunks, &st.Edges)
+	if err != nil {
+		return st, fmt.Errorf("postgres: stats: %w", err)
+	}
+	st.SizeBytes, err = s.dbSize(ctx)
+	return st, err
+}
+
+func (s *Store) dbSize(ctx context.Context) (int64, error) {
+	var size int64
+	if err := s.pool.QueryRow(ctx, `SELECT pg_database_size(current_database())`).Scan(&size); err != nil {
+		return 0, fmt.Errorf("postgres: database size: %w", err)
+	}
+	return size, nil
+}
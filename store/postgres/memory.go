@@ -173,6 +173,25 @@ func (s *ItemStore) DeleteWhere(ctx context.Context, f core.MemoryFilter) (int,
 	return int(tag.RowsAffected()), nil
 }
 
+// DeleteExpired implements core.MemoryExpirer.
+func (s *ItemStore) DeleteExpired(ctx context.Context, kinds ...core.MemoryKind) (int, error) {
+	q := `DELETE FROM memory_items WHERE expires_at > 0 AND expires_at <= $1`
+	args := []any{time.Now().Unix()}
+	if len(kinds) > 0 {
+		placeholders := make([]string, len(kinds))
+		for i, k := range kinds {
+			placeholders[i] = fmt.Sprintf("$%d", i+2)
+			args = append(args, string(k))
+		}
+		q += " AND kind IN (" + strings.Join(placeholders, ",") + ")"
+	}
+	tag, err := s.pool.Exec(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
 func (s *ItemStore) Get(ctx context.Context, id string) (core.MemoryItem, error) {
 	row := s.pool.QueryRow(ctx, baseSelectPg()+" WHERE id = $1", id)
 	it, err := scanItemPg(row)
@@ -158,3 +158,51 @@ func (s *Store) SearchMessages(ctx context.Context, embedding []float32, topK in
 	s.logger.Debug("postgres: search messages ok", "count", len(results), "duration", time.Since(start))
 	return results, rows.Err()
 }
+
+// SearchMessagesText performs full-text search over message content using
+// PostgreSQL tsvector/tsquery with a GIN index, best match first. When
+// chatID is non-empty, only messages in that chat's threads are searched.
+func (s *Store) SearchMessagesText(ctx context.Context, query string, limit int, chatID string) ([]oasis.Message, error) {
+	start := time.Now()
+	s.logger.Debug("postgres: search messages text", "query", query, "limit", limit, "chat_id", chatID)
+	var rows pgx.Rows
+	var err error
+	if chatID != "" {
+		rows, err = s.pool.Query(ctx,
+			`SELECT m.id, m.thread_id, m.role, m.content, m.metadata, m.created_at
+			 FROM messages m
+			 INNER JOIN threads t ON m.thread_id = t.id
+			 WHERE to_tsvector('english', m.content) @@ plainto_tsquery('english', $1) AND t.chat_id = $2
+			 ORDER BY ts_rank(to_tsvector('english', m.content), plainto_tsquery('english', $1)) DESC
+			 LIMIT $3`,
+			query, chatID, limit)
+	} else {
+		rows, err = s.pool.Query(ctx,
+			`SELECT id, thread_id, role, content, metadata, created_at
+			 FROM messages
+			 WHERE to_tsvector('english', content) @@ plainto_tsquery('english', $1)
+			 ORDER BY ts_rank(to_tsvector('english', content), plainto_tsquery('english', $1)) DESC
+			 LIMIT $2`,
+			query, limit)
+	}
+	if err != nil {
+		s.logger.Error("postgres: search messages text failed", "error", err, "duration", time.Since(start))
+		return nil, fmt.Errorf("postgres: search messages text: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []oasis.Message
+	for rows.Next() {
+		var m oasis.Message
+		var metaJSON []byte
+		if err := rows.Scan(&m.ID, &m.ThreadID, &m.Role, &m.Content, &metaJSON, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("postgres: scan message: %w", err)
+		}
+		if metaJSON != nil {
+			_ = json.Unmarshal(metaJSON, &m.Metadata)
+		}
+		messages = append(messages, m)
+	}
+	s.logger.Debug("postgres: search messages text ok", "count", len(messages), "duration", time.Since(start))
+	return messages, rows.Err()
+}
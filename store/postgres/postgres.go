@@ -28,6 +28,9 @@ type Store struct {
 	cfg       pgConfig
 	logger    *slog.Logger
 
+	// leaseHolder identifies this Store as a LeaseStore holder.
+	leaseHolder string
+
 	memoryOnce sync.Once
 	itemStore  *ItemStore
 }
@@ -83,11 +86,13 @@ func WithEFSearch(ef int) Option {
 
 var _ oasis.Store = (*Store)(nil)
 var _ oasis.KeywordSearcher = (*Store)(nil)
+var _ oasis.MessageTextSearcher = (*Store)(nil)
 var _ oasis.GraphStore = (*Store)(nil)
 var _ oasis.BidirectionalGraphStore = (*Store)(nil)
 var _ oasis.CheckpointStore = (*Store)(nil)
 var _ oasis.DocumentMetaLister = (*Store)(nil)
 var _ oasis.ScheduledActionStore = (*Store)(nil)
+var _ oasis.ScheduledActionClaimer = (*Store)(nil)
 
 // nopLogger is a logger that discards all output.
 var nopLogger = slog.New(pgDiscardHandler{})
@@ -137,7 +142,7 @@ func New(pool *pgxpool.Pool, opts ...Option) *Store {
 	if logger == nil {
 		logger = nopLogger
 	}
-	return &Store{pool: pool, cfg: cfg, logger: logger}
+	return &Store{pool: pool, cfg: cfg, logger: logger, leaseHolder: oasis.NewID()}
 }
 
 // vectorType returns "vector" or "vector(N)" depending on config.
@@ -209,6 +214,7 @@ func (s *Store) Init(ctx context.Context) error {
 			created_at BIGINT NOT NULL
 		)`, vtype),
 		`CREATE INDEX IF NOT EXISTS messages_thread_idx ON messages(thread_id)`,
+		`CREATE INDEX IF NOT EXISTS messages_fts_idx ON messages USING gin(to_tsvector('english', content))`,
 	}
 	if useHNSW {
 		stmts = append(stmts, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS messages_embedding_idx ON messages USING hnsw (embedding vector_cosine_ops)%s`, hnswWith))
@@ -254,8 +260,12 @@ func (s *Store) Init(ctx context.Context) error {
 			next_run BIGINT NOT NULL DEFAULT 0,
 			enabled BOOLEAN NOT NULL DEFAULT TRUE,
 			skill_id TEXT NOT NULL DEFAULT '',
-			created_at BIGINT NOT NULL DEFAULT 0
+			created_at BIGINT NOT NULL DEFAULT 0,
+			missed_policy TEXT NOT NULL DEFAULT '',
+			last_run BIGINT NOT NULL DEFAULT 0
 		)`,
+		`ALTER TABLE scheduled_actions ADD COLUMN IF NOT EXISTS missed_policy TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE scheduled_actions ADD COLUMN IF NOT EXISTS last_run BIGINT NOT NULL DEFAULT 0`,
 
 		`CREATE TABLE IF NOT EXISTS chunk_edges (
 			id TEXT PRIMARY KEY,
@@ -279,6 +289,21 @@ func (s *Store) Init(ctx context.Context) error {
 			updated_at BIGINT NOT NULL
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS leases (
+			name       TEXT PRIMARY KEY,
+			holder     TEXT NOT NULL,
+			expires_at BIGINT NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS stream_frames (
+			stream_id  TEXT NOT NULL,
+			seq        BIGINT NOT NULL,
+			type       TEXT NOT NULL,
+			data       TEXT NOT NULL,
+			created_at BIGINT NOT NULL,
+			PRIMARY KEY (stream_id, seq)
+		)`,
+
 		`CREATE TABLE IF NOT EXISTS scores (
 			id TEXT PRIMARY KEY,
 			scorer_id TEXT NOT NULL DEFAULT '',
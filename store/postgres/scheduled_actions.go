@@ -14,10 +14,11 @@ func (s *Store) CreateScheduledAction(ctx context.Context, action oasis.Schedule
 	start := time.Now()
 	s.logger.Debug("postgres: create scheduled action", "id", action.ID, "description", action.Description)
 	_, err := s.pool.Exec(ctx,
-		`INSERT INTO scheduled_actions (id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		`INSERT INTO scheduled_actions (id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at, missed_policy, last_run)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
 		action.ID, action.Description, action.Schedule, action.ToolCalls,
-		action.SynthesisPrompt, action.NextRun, action.Enabled, action.SkillID, action.CreatedAt)
+		action.SynthesisPrompt, action.NextRun, action.Enabled, action.SkillID, action.CreatedAt,
+		string(action.MissedPolicy), action.LastRun)
 	if err != nil {
 		s.logger.Error("postgres: create scheduled action failed", "id", action.ID, "error", err, "duration", time.Since(start))
 		return err
@@ -30,7 +31,7 @@ func (s *Store) ListScheduledActions(ctx context.Context) ([]oasis.ScheduledActi
 	start := time.Now()
 	s.logger.Debug("postgres: list scheduled actions")
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at
+		`SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at, missed_policy, last_run
 		 FROM scheduled_actions ORDER BY next_run`)
 	if err != nil {
 		s.logger.Error("postgres: list scheduled actions failed", "error", err, "duration", time.Since(start))
@@ -46,7 +47,7 @@ func (s *Store) GetDueScheduledActions(ctx context.Context, now int64) ([]oasis.
 	start := time.Now()
 	s.logger.Debug("postgres: get due scheduled actions", "now", now)
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at
+		`SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at, missed_policy, last_run
 		 FROM scheduled_actions WHERE enabled = TRUE AND next_run <= $1`, now)
 	if err != nil {
 		s.logger.Error("postgres: get due scheduled actions failed", "error", err, "duration", time.Since(start))
@@ -62,8 +63,9 @@ func (s *Store) UpdateScheduledAction(ctx context.Context, action oasis.Schedule
 	start := time.Now()
 	s.logger.Debug("postgres: update scheduled action", "id", action.ID)
 	_, err := s.pool.Exec(ctx,
-		`UPDATE scheduled_actions SET description=$1, schedule=$2, tool_calls=$3, synthesis_prompt=$4, next_run=$5, enabled=$6, skill_id=$7 WHERE id=$8`,
-		action.Description, action.Schedule, action.ToolCalls, action.SynthesisPrompt, action.NextRun, action.Enabled, action.SkillID, action.ID)
+		`UPDATE scheduled_actions SET description=$1, schedule=$2, tool_calls=$3, synthesis_prompt=$4, next_run=$5, enabled=$6, skill_id=$7, missed_policy=$8, last_run=$9 WHERE id=$10`,
+		action.Description, action.Schedule, action.ToolCalls, action.SynthesisPrompt, action.NextRun, action.Enabled, action.SkillID,
+		string(action.MissedPolicy), action.LastRun, action.ID)
 	if err != nil {
 		s.logger.Error("postgres: update scheduled action failed", "id", action.ID, "error", err, "duration", time.Since(start))
 		return err
@@ -72,6 +74,23 @@ func (s *Store) UpdateScheduledAction(ctx context.Context, action oasis.Schedule
 	return nil
 }
 
+// ClaimScheduledAction implements oasis.ScheduledActionClaimer with a
+// compare-and-set on next_run, so only one replica fires each due time.
+func (s *Store) ClaimScheduledAction(ctx context.Context, id string, due, next, now int64) (bool, error) {
+	start := time.Now()
+	s.logger.Debug("postgres: claim scheduled action", "id", id, "due", due, "next_run", next)
+	tag, err := s.pool.Exec(ctx,
+		`UPDATE scheduled_actions SET next_run=$1, last_run=$2 WHERE id=$3 AND next_run=$4`,
+		next, now, id, due)
+	if err != nil {
+		s.logger.Error("postgres: claim scheduled action failed", "id", id, "error", err, "duration", time.Since(start))
+		return false, err
+	}
+	claimed := tag.RowsAffected() == 1
+	s.logger.Debug("postgres: claim scheduled action ok", "id", id, "claimed", claimed, "duration", time.Since(start))
+	return claimed, nil
+}
+
 func (s *Store) UpdateScheduledActionEnabled(ctx context.Context, id string, enabled bool) error {
 	start := time.Now()
 	s.logger.Debug("postgres: update scheduled action enabled", "id", id, "enabled", enabled)
@@ -113,7 +132,7 @@ func (s *Store) ListScheduledActionsByDescription(ctx context.Context, pattern s
 	start := time.Now()
 	s.logger.Debug("postgres: list scheduled actions by description", "pattern", pattern)
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at
+		`SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at, missed_policy, last_run
 		 FROM scheduled_actions WHERE description LIKE $1`,
 		"%"+pattern+"%")
 	if err != nil {
@@ -130,9 +149,11 @@ func scanScheduledActions(rows pgx.Rows) ([]oasis.ScheduledAction, error) {
 	var actions []oasis.ScheduledAction
 	for rows.Next() {
 		var a oasis.ScheduledAction
-		if err := rows.Scan(&a.ID, &a.Description, &a.Schedule, &a.ToolCalls, &a.SynthesisPrompt, &a.NextRun, &a.Enabled, &a.SkillID, &a.CreatedAt); err != nil {
+		var policy string
+		if err := rows.Scan(&a.ID, &a.Description, &a.Schedule, &a.ToolCalls, &a.SynthesisPrompt, &a.NextRun, &a.Enabled, &a.SkillID, &a.CreatedAt, &policy, &a.LastRun); err != nil {
 			return nil, err
 		}
+		a.MissedPolicy = oasis.MissedPolicy(policy)
 		actions = append(actions, a)
 	}
 	return actions, rows.Err()
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+var _ oasis.StreamLogStore = (*Store)(nil)
+
+// --- Stream frames ---
+
+// AppendStreamFrame implements oasis.StreamLogStore.
+func (s *Store) AppendStreamFrame(ctx context.Context, streamID string, f oasis.StreamFrame) error {
+	_, err := s.pool.Exec(ctx,
+		`INSERT INTO stream_frames (stream_id, seq, type, data, created_at) VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (stream_id, seq) DO UPDATE SET type = EXCLUDED.type, data = EXCLUDED.data`,
+		streamID, f.Seq, f.Type, string(f.Data), time.Now().UnixMilli())
+	if err != nil {
+		s.logger.Error("postgres: append stream frame failed", "stream_id", streamID, "seq", f.Seq, "error", err)
+	}
+	return err
+}
+
+// StreamFrames implements oasis.StreamLogStore.
+func (s *Store) StreamFrames(ctx context.Context, streamID string) ([]oasis.StreamFrame, error) {
+	start := time.Now()
+	s.logger.Debug("postgres: stream frames", "stream_id", streamID)
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT seq, type, data FROM stream_frames WHERE stream_id = $1 ORDER BY seq`, streamID)
+	if err != nil {
+		s.logger.Error("postgres: stream frames failed", "stream_id", streamID, "error", err, "duration", time.Since(start))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var frames []oasis.StreamFrame
+	for rows.Next() {
+		var f oasis.StreamFrame
+		var data string
+		if err := rows.Scan(&f.Seq, &f.Type, &data); err != nil {
+			return nil, err
+		}
+		f.Data = []byte(data)
+		frames = append(frames, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	s.logger.Debug("postgres: stream frames ok", "stream_id", streamID, "count", len(frames), "duration", time.Since(start))
+	return frames, nil
+}
+
+// DeleteStreamFrames implements oasis.StreamLogStore.
+func (s *Store) DeleteStreamFrames(ctx context.Context, streamID string) error {
+	start := time.Now()
+	s.logger.Debug("postgres: delete stream frames", "stream_id", streamID)
+
+	if _, err := s.pool.Exec(ctx, `DELETE FROM stream_frames WHERE stream_id = $1`, streamID); err != nil {
+		s.logger.Error("postgres: delete stream frames failed", "stream_id", streamID, "error", err, "duration", time.Since(start))
+		return err
+	}
+	s.logger.Debug("postgres: delete stream frames ok", "stream_id", streamID, "duration", time.Since(start))
+	return nil
+}
+
+// PruneStreamFrames implements oasis.StreamLogStore.
+func (s *Store) PruneStreamFrames(ctx context.Context, before time.Time) (int, error) {
+	start := time.Now()
+	s.logger.Debug("postgres: prune stream frames", "before", before)
+
+	tag, err := s.pool.Exec(ctx,
+		`DELETE FROM stream_frames WHERE stream_id IN (
+			SELECT stream_id FROM stream_frames GROUP BY stream_id HAVING MAX(created_at) < $1)`,
+		before.UnixMilli())
+	if err != nil {
+		s.logger.Error("postgres: prune stream frames failed", "error", err, "duration", time.Since(start))
+		return 0, err
+	}
+	n := tag.RowsAffected()
+	s.logger.Debug("postgres: prune stream frames ok", "deleted", n, "duration", time.Since(start))
+	return int(n), nil
+}
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// WithContentEncryption encrypts Message.Content at rest with AES-GCM.
+// key must be 16, 24 or 32 bytes (AES-128/192/256). Embeddings, metadata
+// and IDs stay in the clear, so SearchMessages works unchanged.
+//
+// Each row records the ID of the key that sealed it (a hash of the key, never
+// the key itself). To rotate, pass the new key first and the retired keys
+// after it: new rows use key, old rows still decrypt with theirs. Rows
+// written before encryption was enabled are read as plaintext.
+//
+// An invalid key makes Init and every message write fail.
+func WithContentEncryption(key []byte, retired ...[]byte) StoreOption {
+	return func(s *Store) {
+		s.enc, s.encErr = newContentCipher(key, retired)
+	}
+}
+
+// contentCipher seals message content with the current key and opens it with
+// any known key, selected by the row's key ID.
+type contentCipher struct {
+	keyID string
+	aeads map[string]cipher.AEAD // key ID → AEAD
+}
+
+func newContentCipher(key []byte, retired [][]byte) (*contentCipher, error) {
+	c := &contentCipher{aeads: make(map[string]cipher.AEAD)}
+	for i, k := range append([][]byte{key}, retired...) {
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: content encryption key %d: %w", i, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: content encryption key %d: %w", i, err)
+		}
+		id := contentKeyID(k)
+		if i == 0 {
+			c.keyID = id
+		}
+		c.aeads[id] = aead
+	}
+	return c, nil
+}
+
+// contentKeyID identifies a key without revealing it.
+func contentKeyID(key []byte) string {
+	sum := sha256.Sum256(append([]byte("oasis-sqlite-content-key:"), key...))
+	return hex.EncodeToString(sum[:8])
+}
+
+// seal encrypts plaintext for the row with the given message ID. The ID is
+// bound as additional data, so ciphertext copied to another row will not
+// decrypt. Returns base64(nonce || ciphertext) and the key ID.
+func (c *contentCipher) seal(msgID, plaintext string) (string, string, error) {
+	aead := c.aeads[c.keyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", fmt.Errorf("generate nonce: %w", err)
+	}
+	out := aead.Seal(nonce, nonce, []byte(plaintext), []byte(msgID))
+	return base64.StdEncoding.EncodeToString(out), c.keyID, nil
+}
+
+// open reverses seal using the key named by keyID.
+func (c *contentCipher) open(msgID, keyID, sealed string) (string, error) {
+	aead, ok := c.aeads[keyID]
+	if !ok {
+		return "", fmt.Errorf("message %s: no key for key id %s", msgID, keyID)
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < aead.NonceSize() {
+		return "", fmt.Errorf("message %s: malformed encrypted content", msgID)
+	}
+	n := aead.NonceSize()
+	plain, err := aead.Open(nil, raw[:n], raw[n:], []byte(msgID))
+	if err != nil {
+		return "", fmt.Errorf("message %s: decrypt content: %w", msgID, err)
+	}
+	return string(plain), nil
+}
+
+// sealContent returns the stored form of a message's content and its key ID
+// (nil when encryption is off).
+func (s *Store) sealContent(msgID, content string) (string, *string, error) {
+	if s.encErr != nil {
+		return "", nil, s.encErr
+	}
+	if s.enc == nil {
+		return content, nil, nil
+	}
+	sealed, keyID, err := s.enc.seal(msgID, content)
+	if err != nil {
+		return "", nil, err
+	}
+	return sealed, &keyID, nil
+}
+
+// openContent returns a row's plaintext content. Rows without a key ID were
+// written unencrypted.
+func (s *Store) openContent(msgID, stored string, keyID *string) (string, error) {
+	if keyID == nil || *keyID == "" {
+		return stored, nil
+	}
+	if s.enc == nil {
+		return "", fmt.Errorf("message %s is encrypted but the store has no WithContentEncryption key", msgID)
+	}
+	return s.enc.open(msgID, *keyID, stored)
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+func TestContentEncryption(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "enc.db")
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	open := func(opts ...StoreOption) *Store {
+		s := New(path, opts...)
+		if err := s.Init(ctx); err != nil {
+			t.Fatalf("Init: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+	put := func(s *Store, id, content string) {
+		err := s.StoreMessage(ctx, oasis.Message{ID: id, ThreadID: "t1", Role: oasis.RoleUser,
+			Content: content, Embedding: []float32{1, 0}, CreatedAt: int64(len(id))})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	put(open(), "m", "written before encryption")
+	put(open(WithContentEncryption(oldKey)), "mm", "secret under the old key")
+	s := open(WithContentEncryption(newKey, oldKey))
+	put(s, "mmm", "secret under the new key")
+
+	var raw string
+	if err := s.DB().QueryRowContext(ctx, `SELECT content FROM messages WHERE id = 'mmm'`).Scan(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(raw, "secret") {
+		t.Errorf("content stored in the clear: %q", raw)
+	}
+
+	msgs, err := s.GetMessages(ctx, "t1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"written before encryption", "secret under the old key", "secret under the new key"}
+	if len(msgs) != len(want) {
+		t.Fatalf("got %d messages", len(msgs))
+	}
+	for i, m := range msgs {
+		if m.Content != want[i] {
+			t.Errorf("msgs[%d] = %q, want %q", i, m.Content, want[i])
+		}
+	}
+	scored, err := s.SearchMessages(ctx, []float32{1, 0}, 3, "")
+	if err != nil || len(scored) != 3 {
+		t.Fatalf("SearchMessages = %v, %v", scored, err)
+	}
+	for _, m := range scored {
+		if !strings.Contains(m.Content, " ") {
+			t.Errorf("SearchMessages returned undecrypted content %q", m.Content)
+		}
+	}
+
+	// Without the retired key, old rows no longer decrypt.
+	if _, err := open(WithContentEncryption(newKey)).GetMessages(ctx, "t1", 10); err == nil {
+		t.Error("expected an error reading rows sealed with an unknown key")
+	}
+}
+
+func TestContentEncryption_InvalidKey(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "bad.db"), WithContentEncryption([]byte("short")))
+	defer s.Close()
+	if err := s.Init(context.Background()); err == nil {
+		t.Fatal("expected Init to reject an invalid key")
+	}
+}
@@ -122,3 +122,51 @@ func TestSearchChunksKeyword_SpecialChars(t *testing.T) {
 		t.Fatalf("empty sanitized query should return 0 results, got %d", len(results))
 	}
 }
+
+func TestSearchMessagesText(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	defer s.Close()
+
+	for _, th := range []oasis.Thread{{ID: "t1", ChatID: "c1"}, {ID: "t2", ChatID: "c2"}} {
+		if err := s.CreateThread(ctx, th); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs := []oasis.Message{
+		{ID: "m1", ThreadID: "t1", Role: oasis.RoleUser, Content: "I moved to the Berlin office last week", CreatedAt: 1},
+		{ID: "m2", ThreadID: "t1", Role: oasis.RoleAssistant, Content: "How do you like Munich?", CreatedAt: 2},
+		{ID: "m3", ThreadID: "t2", Role: oasis.RoleUser, Content: "The Berlin office has a new lead", CreatedAt: 3},
+	}
+	for _, m := range msgs {
+		if err := s.StoreMessage(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.SearchMessagesText(ctx, `"Berlin office"`, 10, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "m1" {
+		t.Fatalf("chat c1 = %+v, want m1", got)
+	}
+	if got, _ := s.SearchMessagesText(ctx, "berlin", 10, ""); len(got) != 2 {
+		t.Errorf("all chats = %d results, want 2", len(got))
+	}
+
+	// Replacing a message re-indexes it; deleting its thread drops it.
+	msgs[0].Content = "I moved to Hamburg"
+	if err := s.StoreMessage(ctx, msgs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.SearchMessagesText(ctx, "berlin", 10, "c1"); len(got) != 0 {
+		t.Errorf("after edit = %+v, want none", got)
+	}
+	if err := s.DeleteThread(ctx, "t2"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.SearchMessagesText(ctx, "berlin", 10, ""); len(got) != 0 {
+		t.Errorf("after delete = %+v, want none", got)
+	}
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+var _ oasis.LeaseStore = (*Store)(nil)
+
+// --- Leases ---
+
+// AcquireLease implements oasis.LeaseStore. The upsert only overwrites a
+// row that has expired or that this Store already holds.
+func (s *Store) AcquireLease(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	start := time.Now()
+	s.logger.Debug("sqlite: acquire lease", "name", name, "ttl", ttl)
+
+	now := time.Now().UnixMilli()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO leases (name, holder, expires_at) VALUES (?, ?, ?)
+		 ON CONFLICT(name) DO UPDATE SET holder=excluded.holder, expires_at=excluded.expires_at
+		 WHERE leases.expires_at <= ? OR leases.holder = excluded.holder`,
+		name, s.leaseHolder, now+ttl.Milliseconds(), now)
+	if err != nil {
+		s.logger.Error("sqlite: acquire lease failed", "name", name, "error", err, "duration", time.Since(start))
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	s.logger.Debug("sqlite: acquire lease ok", "name", name, "acquired", n == 1, "duration", time.Since(start))
+	return n == 1, nil
+}
+
+// RenewLease implements oasis.LeaseStore.
+func (s *Store) RenewLease(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	start := time.Now()
+	s.logger.Debug("sqlite: renew lease", "name", name, "ttl", ttl)
+
+	now := time.Now().UnixMilli()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE leases SET expires_at=? WHERE name=? AND holder=? AND expires_at > ?`,
+		now+ttl.Milliseconds(), name, s.leaseHolder, now)
+	if err != nil {
+		s.logger.Error("sqlite: renew lease failed", "name", name, "error", err, "duration", time.Since(start))
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	s.logger.Debug("sqlite: renew lease ok", "name", name, "renewed", n == 1, "duration", time.Since(start))
+	return n == 1, nil
+}
+
+// ReleaseLease implements oasis.LeaseStore.
+func (s *Store) ReleaseLease(ctx context.Context, name string) error {
+	start := time.Now()
+	s.logger.Debug("sqlite: release lease", "name", name)
+
+	_, err := s.db.ExecContext(ctx, `DELETE FROM leases WHERE name=? AND holder=?`, name, s.leaseHolder)
+	if err != nil {
+		s.logger.Error("sqlite: release lease failed", "name", name, "error", err, "duration", time.Since(start))
+		return err
+	}
+	s.logger.Debug("sqlite: release lease ok", "name", name, "duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLease_SingleHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease.db")
+	a, b := New(path), New(path)
+	ctx := context.Background()
+	for _, s := range []*Store{a, b} {
+		if err := s.Init(ctx); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	if ok, err := a.AcquireLease(ctx, "scheduler", time.Minute); err != nil || !ok {
+		t.Fatalf("a acquire = %v, %v; want true", ok, err)
+	}
+	if ok, err := b.AcquireLease(ctx, "scheduler", time.Minute); err != nil || ok {
+		t.Fatalf("b acquire of a held lease = %v, %v; want false", ok, err)
+	}
+	if ok, err := a.AcquireLease(ctx, "scheduler", time.Minute); err != nil || !ok {
+		t.Fatalf("holder re-acquire = %v, %v; want true", ok, err)
+	}
+	if ok, err := b.RenewLease(ctx, "scheduler", time.Minute); err != nil || ok {
+		t.Fatalf("b renew = %v, %v; want false", ok, err)
+	}
+	if err := b.ReleaseLease(ctx, "scheduler"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := a.RenewLease(ctx, "scheduler", time.Minute); !ok {
+		t.Fatal("release by a non-holder must not free the lease")
+	}
+
+	if err := a.ReleaseLease(ctx, "scheduler"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := b.AcquireLease(ctx, "scheduler", 20*time.Millisecond); err != nil || !ok {
+		t.Fatalf("b acquire after release = %v, %v; want true", ok, err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if ok, _ := b.RenewLease(ctx, "scheduler", time.Minute); ok {
+		t.Error("renewing an expired lease should fail")
+	}
+	if ok, _ := a.AcquireLease(ctx, "scheduler", time.Minute); !ok {
+		t.Error("an expired lease should be free to take")
+	}
+}
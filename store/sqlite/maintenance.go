@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+var _ oasis.StoreMaintainer = (*Store)(nil)
+
+// Vacuum prunes rows left behind by deletes — chunks of missing documents,
+// their FTS entries and edges, and FTS entries of missing messages — then
+// runs VACUUM to return free pages to the OS, optimizes the FTS indexes, and
+// rebuilds the in-memory vector index so it drops pruned chunks and releases
+// the memory of deleted ones.
+//
+// Reads keep working throughout: VACUUM runs under WAL, and searches wait
+// only while the vector index is being reloaded. Writes wait for VACUUM via
+// the busy timeout, so schedule Vacuum off-peak on large databases.
+func (s *Store) Vacuum(ctx context.Context) (oasis.VacuumResult, error) {
+	start := time.Now()
+	s.logger.Debug("sqlite: vacuum started")
+
+	var res oasis.VacuumResult
+	var err error
+	if res.SizeBefore, _, err = s.dbSize(ctx); err != nil {
+		return res, err
+	}
+	if err := s.pruneOrphans(ctx, &res); err != nil {
+		s.logger.Error("sqlite: vacuum prune failed", "error", err, "duration", time.Since(start))
+		return res, err
+	}
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		s.logger.Error("sqlite: vacuum failed", "error", err, "duration", time.Since(start))
+		return res, fmt.Errorf("vacuum: %w", err)
+	}
+	// Best-effort: shrink the WAL and merge FTS segments.
+	_, _ = s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`)
+	_, _ = s.db.ExecContext(ctx, `INSERT INTO chunks_fts(chunks_fts) VALUES('optimize')`)
+	_, _ = s.db.ExecContext(ctx, `INSERT INTO messages_fts(messages_fts) VALUES('optimize')`)
+	_, _ = s.db.ExecContext(ctx, `PRAGMA optimize`)
+
+	if err := s.rebuildVecIndex(ctx); err != nil {
+		return res, err
+	}
+	if res.SizeAfter, _, err = s.dbSize(ctx); err != nil {
+		return res, err
+	}
+	s.logger.Info("sqlite: vacuum completed",
+		"orphan_chunks", res.OrphanChunks, "orphan_edges", res.OrphanEdges,
+		"size_before", res.SizeBefore, "size_after", res.SizeAfter, "duration", time.Since(start))
+	return res, nil
+}
+
+// pruneOrphans deletes orphaned chunks, edges and FTS rows in one transaction.
+func (s *Store) pruneOrphans(ctx context.Context, res *oasis.VacuumResult) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	const orphanChunks = `SELECT id FROM chunks WHERE document_id NOT IN (SELECT id FROM documents)`
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks_fts WHERE chunk_id IN (`+orphanChunks+`)`); err != nil {
+		return fmt.Errorf("prune chunk fts: %w", err)
+	}
+	r, err := tx.ExecContext(ctx, `DELETE FROM chunks WHERE document_id NOT IN (SELECT id FROM documents)`)
+	if err != nil {
+		return fmt.Errorf("prune orphan chunks: %w", err)
+	}
+	n, _ := r.RowsAffected()
+	res.OrphanChunks = int(n)
+
+	r, err = tx.ExecContext(ctx,
+		`DELETE FROM chunk_edges WHERE source_id NOT IN (SELECT id FROM chunks) OR target_id NOT IN (SELECT id FROM chunks)`)
+	if err != nil {
+		return fmt.Errorf("prune orphan edges: %w", err)
+	}
+	n, _ = r.RowsAffected()
+	res.OrphanEdges = int(n)
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks_fts WHERE chunk_id NOT IN (SELECT id FROM chunks)`); err != nil {
+		return fmt.Errorf("prune chunk fts: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM messages_fts WHERE message_id NOT IN (SELECT id FROM messages)`); err != nil {
+		return fmt.Errorf("prune message fts: %w", err)
+	}
+	return tx.Commit()
+}
+
+// rebuildVecIndex reloads the in-memory vector index if it has been loaded.
+// An unloaded index is left for the next search to load.
+func (s *Store) rebuildVecIndex(ctx context.Context) error {
+	s.vecMu.Lock()
+	loaded := s.vecReady
+	s.vecReady = false
+	s.vecIndex = nil
+	s.vecMu.Unlock()
+	if !loaded {
+		return nil
+	}
+	return s.loadVecIndex(ctx)
+}
+
+// Stats reports row counts and the database file size, including the free
+// pages a Vacuum would reclaim.
+func (s *Store) Stats(ctx context.Context) (oasis.StoreStats, error) {
+	var st oasis.StoreStats
+	err := s.db.QueryRowContext(ctx, `SELECT
+		(SELECT count(*) FROM threads),
+		(SELECT count(*) FROM messages),
+		(SELECT count(*) FROM documents),
+		(SELECT count(*) FROM chunks),
+		(SELECT count(*) FROM chunk_edges)`,
+	).Scan(&st.Threads, &st.Messages, &st.Documents, &st.Chunks, &st.Edges)
+	if err != nil {
+		return st, fmt.Errorf("stats: %w", err)
+	}
+	st.SizeBytes, st.FreeBytes, err = s.dbSize(ctx)
+	return st, err
+}
+
+// dbSize returns the database size and its free-page bytes.
+func (s *Store) dbSize(ctx context.Context) (size, free int64, err error) {
+	var pageSize, pages, freePages int64
+	err = s.db.QueryRowContext(ctx,
+		`SELECT page_size, page_count, freelist_count FROM pragma_page_size, pragma_page_count, pragma_freelist_count`,
+	).Scan(&pageSize, &pages, &freePages)
+	if err != nil {
+		return 0, 0, fmt.Errorf("database size: %w", err)
+	}
+	return pageSize * pages, pageSize * freePages, nil
+}
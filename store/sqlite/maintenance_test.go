@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+func TestVacuumAndStats(t *testing.T) {
+	s := testStore(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	doc := oasis.Document{ID: "d1", Title: "T", Source: "s", CreatedAt: 1}
+	chunks := []oasis.Chunk{
+		{ID: "c1", DocumentID: "d1", Content: "alpha", Embedding: []float32{1, 0}},
+		{ID: "c2", DocumentID: "d1", Content: "beta", Embedding: []float32{0, 1}},
+	}
+	if err := s.StoreDocument(ctx, doc, chunks); err != nil {
+		t.Fatal(err)
+	}
+	// An orphaned chunk and edge, as left by an interrupted delete.
+	if _, err := s.DB().ExecContext(ctx,
+		`INSERT INTO chunks (id, document_id, content, chunk_index, embedding) VALUES ('c9', 'gone', 'orphan', 0, '[1,1]')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreEdges(ctx, []oasis.ChunkEdge{{ID: "e1", SourceID: "c1", TargetID: "c9", Relation: "references", Weight: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SearchChunks(ctx, []float32{1, 0}, 5); err != nil { // loads the vector index
+		t.Fatal(err)
+	}
+
+	st, err := s.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Documents != 1 || st.Chunks != 3 || st.Edges != 1 || st.SizeBytes <= 0 {
+		t.Errorf("stats before = %+v", st)
+	}
+
+	res, err := s.Vacuum(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OrphanChunks != 1 || res.OrphanEdges != 1 || res.SizeAfter <= 0 {
+		t.Errorf("vacuum = %+v", res)
+	}
+	st, _ = s.Stats(ctx)
+	if st.Chunks != 2 || st.Edges != 0 {
+		t.Errorf("stats after = %+v", st)
+	}
+	s.vecMu.RLock()
+	n, ready := len(s.vecIndex), s.vecReady
+	s.vecMu.RUnlock()
+	if !ready || n != 2 {
+		t.Errorf("vector index: ready=%v entries=%d, want rebuilt with 2", ready, n)
+	}
+}
@@ -149,6 +149,24 @@ func (s *ItemStore) DeleteWhere(ctx context.Context, f core.MemoryFilter) (int,
 	return int(n), nil
 }
 
+// DeleteExpired implements core.MemoryExpirer.
+func (s *ItemStore) DeleteExpired(ctx context.Context, kinds ...core.MemoryKind) (int, error) {
+	q := `DELETE FROM memory_items WHERE expires_at > 0 AND expires_at <= ?`
+	args := []any{time.Now().Unix()}
+	if len(kinds) > 0 {
+		q += " AND kind IN (" + strings.TrimSuffix(strings.Repeat("?,", len(kinds)), ",") + ")"
+		for _, k := range kinds {
+			args = append(args, string(k))
+		}
+	}
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
 func (s *ItemStore) Get(ctx context.Context, id string) (core.MemoryItem, error) {
 	row := s.db.QueryRowContext(ctx, baseSelect()+" WHERE id = ?", id)
 	it, err := scanItem(row)
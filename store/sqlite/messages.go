@@ -28,11 +28,33 @@ func (s *Store) StoreMessage(ctx context.Context, msg oasis.Message) error {
 		metaJSON = &v
 	}
 
-	_, err := s.db.ExecContext(ctx,
-		`INSERT OR REPLACE INTO messages (id, thread_id, role, content, embedding, metadata, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		msg.ID, msg.ThreadID, msg.Role, msg.Content, embBlob, metaJSON, msg.CreatedAt,
+	content, keyID, err := s.sealContent(msg.ID, msg.Content)
+	if err != nil {
+		return fmt.Errorf("store message: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO messages (id, thread_id, role, content, content_key, embedding, metadata, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		msg.ID, msg.ThreadID, msg.Role, content, keyID, embBlob, metaJSON, msg.CreatedAt,
 	)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, `DELETE FROM messages_fts WHERE message_id = ?`, msg.ID)
+	}
+	// Why: encrypted content stays out of the full-text index, which would
+	// otherwise hold it in the clear.
+	if err == nil && keyID == nil {
+		_, err = tx.ExecContext(ctx, `INSERT INTO messages_fts(message_id, content) VALUES (?, ?)`, msg.ID, msg.Content)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
 	if err != nil {
 		s.logger.Error("sqlite: store message failed", "id", msg.ID, "error", err, "duration", time.Since(start))
 		return fmt.Errorf("store message: %w", err)
@@ -48,7 +70,7 @@ func (s *Store) GetMessages(ctx context.Context, threadID string, limit int) ([]
 	s.logger.Debug("sqlite: get messages", "thread_id", threadID, "limit", limit)
 
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, thread_id, role, content, metadata, created_at
+		`SELECT id, thread_id, role, content, content_key, metadata, created_at
 		 FROM messages
 		 WHERE thread_id = ?
 		 ORDER BY created_at DESC, id DESC
@@ -65,9 +87,13 @@ func (s *Store) GetMessages(ctx context.Context, threadID string, limit int) ([]
 	for rows.Next() {
 		var m oasis.Message
 		var metaJSON sql.NullString
-		if err := rows.Scan(&m.ID, &m.ThreadID, &m.Role, &m.Content, &metaJSON, &m.CreatedAt); err != nil {
+		var keyID *string
+		if err := rows.Scan(&m.ID, &m.ThreadID, &m.Role, &m.Content, &keyID, &metaJSON, &m.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan message: %w", err)
 		}
+		if m.Content, err = s.openContent(m.ID, m.Content, keyID); err != nil {
+			return nil, fmt.Errorf("get messages: %w", err)
+		}
 		if metaJSON.Valid {
 			m.Metadata = json.RawMessage(metaJSON.String)
 		}
@@ -97,7 +123,7 @@ func (s *Store) SearchMessages(ctx context.Context, embedding []float32, topK in
 	var err error
 	if chatID != "" {
 		rows, err = s.db.QueryContext(ctx,
-			`SELECT m.id, m.thread_id, m.role, m.content, m.embedding, m.metadata, m.created_at
+			`SELECT m.id, m.thread_id, m.role, m.content, m.content_key, m.embedding, m.metadata, m.created_at
 			 FROM messages m
 			 INNER JOIN threads t ON m.thread_id = t.id
 			 WHERE m.embedding IS NOT NULL AND t.chat_id = ?`,
@@ -105,7 +131,7 @@ func (s *Store) SearchMessages(ctx context.Context, embedding []float32, topK in
 		)
 	} else {
 		rows, err = s.db.QueryContext(ctx,
-			`SELECT id, thread_id, role, content, embedding, metadata, created_at
+			`SELECT id, thread_id, role, content, content_key, embedding, metadata, created_at
 			 FROM messages WHERE embedding IS NOT NULL`,
 		)
 	}
@@ -117,12 +143,15 @@ func (s *Store) SearchMessages(ctx context.Context, embedding []float32, topK in
 
 	var results []oasis.ScoredMessage
 	scanned := 0
+	// Encrypted rows are decrypted only once they make the top K.
+	keyIDs := make(map[string]*string)
 
 	for rows.Next() {
 		var m oasis.Message
 		var embBlob []byte
 		var metaJSON sql.NullString
-		if err := rows.Scan(&m.ID, &m.ThreadID, &m.Role, &m.Content, &embBlob, &metaJSON, &m.CreatedAt); err != nil {
+		var keyID *string
+		if err := rows.Scan(&m.ID, &m.ThreadID, &m.Role, &m.Content, &keyID, &embBlob, &metaJSON, &m.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan message: %w", err)
 		}
 		scanned++
@@ -133,6 +162,10 @@ func (s *Store) SearchMessages(ctx context.Context, embedding []float32, topK in
 		if err != nil {
 			continue
 		}
+		m.Embedding = stored
+		if keyID != nil {
+			keyIDs[m.ID] = keyID
+		}
 		results = append(results, oasis.ScoredMessage{Message: m, Score: oasis.CosineSimilarity(embedding, stored)})
 	}
 	if err := rows.Err(); err != nil {
@@ -146,6 +179,70 @@ func (s *Store) SearchMessages(ctx context.Context, embedding []float32, topK in
 	if len(results) > topK {
 		results = results[:topK]
 	}
+	for i := range results {
+		m := &results[i].Message
+		if m.Content, err = s.openContent(m.ID, m.Content, keyIDs[m.ID]); err != nil {
+			return nil, fmt.Errorf("search messages: %w", err)
+		}
+	}
 	s.logger.Debug("sqlite: search messages ok", "scanned", scanned, "returned", len(results), "duration", time.Since(start))
 	return results, nil
 }
+
+// SearchMessagesText performs full-text search over message content using
+// SQLite FTS5, best match first. When chatID is non-empty, only messages in
+// that chat's threads are searched. Messages stored under
+// WithContentEncryption are not indexed and never match.
+func (s *Store) SearchMessagesText(ctx context.Context, query string, limit int, chatID string) ([]oasis.Message, error) {
+	start := time.Now()
+	s.logger.Debug("sqlite: search messages text", "query", query, "limit", limit, "chat_id", chatID)
+
+	query = sanitizeFTS5Query(query)
+	if query == "" {
+		return nil, nil
+	}
+	var rows *sql.Rows
+	var err error
+	if chatID != "" {
+		rows, err = s.db.QueryContext(ctx,
+			`SELECT m.id, m.thread_id, m.role, m.content, m.content_key, m.metadata, m.created_at
+			 FROM messages_fts f
+			 JOIN messages m ON m.id = f.message_id
+			 JOIN threads t ON t.id = m.thread_id
+			 WHERE messages_fts MATCH ? AND t.chat_id = ?
+			 ORDER BY f.rank LIMIT ?`,
+			query, chatID, limit)
+	} else {
+		rows, err = s.db.QueryContext(ctx,
+			`SELECT m.id, m.thread_id, m.role, m.content, m.content_key, m.metadata, m.created_at
+			 FROM messages_fts f
+			 JOIN messages m ON m.id = f.message_id
+			 WHERE messages_fts MATCH ?
+			 ORDER BY f.rank LIMIT ?`,
+			query, limit)
+	}
+	if err != nil {
+		s.logger.Error("sqlite: search messages text failed", "error", err, "duration", time.Since(start))
+		return nil, fmt.Errorf("search messages text: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []oasis.Message
+	for rows.Next() {
+		var m oasis.Message
+		var metaJSON sql.NullString
+		var keyID *string
+		if err := rows.Scan(&m.ID, &m.ThreadID, &m.Role, &m.Content, &keyID, &metaJSON, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+		if m.Content, err = s.openContent(m.ID, m.Content, keyID); err != nil {
+			return nil, fmt.Errorf("search messages text: %w", err)
+		}
+		if metaJSON.Valid {
+			m.Metadata = json.RawMessage(metaJSON.String)
+		}
+		messages = append(messages, m)
+	}
+	s.logger.Debug("sqlite: search messages text ok", "returned", len(messages), "duration", time.Since(start))
+	return messages, rows.Err()
+}
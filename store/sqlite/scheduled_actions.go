@@ -15,10 +15,11 @@ func (s *Store) CreateScheduledAction(ctx context.Context, action oasis.Schedule
 	s.logger.Debug("sqlite: create scheduled action", "id", action.ID, "description", action.Description, "schedule", action.Schedule)
 
 	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO scheduled_actions (id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO scheduled_actions (id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at, missed_policy, last_run)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		action.ID, action.Description, action.Schedule, action.ToolCalls,
-		action.SynthesisPrompt, action.NextRun, boolToInt(action.Enabled), action.SkillID, action.CreatedAt)
+		action.SynthesisPrompt, action.NextRun, boolToInt(action.Enabled), action.SkillID, action.CreatedAt,
+		string(action.MissedPolicy), action.LastRun)
 	if err != nil {
 		s.logger.Error("sqlite: create scheduled action failed", "id", action.ID, "error", err, "duration", time.Since(start))
 		return err
@@ -31,7 +32,7 @@ func (s *Store) ListScheduledActions(ctx context.Context) ([]oasis.ScheduledActi
 	start := time.Now()
 	s.logger.Debug("sqlite: list scheduled actions")
 
-	rows, err := s.db.QueryContext(ctx, `SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at FROM scheduled_actions ORDER BY next_run`)
+	rows, err := s.db.QueryContext(ctx, `SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at, missed_policy, last_run FROM scheduled_actions ORDER BY next_run`)
 	if err != nil {
 		s.logger.Error("sqlite: list scheduled actions failed", "error", err, "duration", time.Since(start))
 		return nil, err
@@ -50,7 +51,7 @@ func (s *Store) GetDueScheduledActions(ctx context.Context, now int64) ([]oasis.
 	start := time.Now()
 	s.logger.Debug("sqlite: get due scheduled actions", "now", now)
 
-	rows, err := s.db.QueryContext(ctx, `SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at FROM scheduled_actions WHERE enabled = 1 AND next_run <= ?`, now)
+	rows, err := s.db.QueryContext(ctx, `SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at, missed_policy, last_run FROM scheduled_actions WHERE enabled = 1 AND next_run <= ?`, now)
 	if err != nil {
 		s.logger.Error("sqlite: get due scheduled actions failed", "error", err, "duration", time.Since(start))
 		return nil, err
@@ -70,8 +71,9 @@ func (s *Store) UpdateScheduledAction(ctx context.Context, action oasis.Schedule
 	s.logger.Debug("sqlite: update scheduled action", "id", action.ID, "next_run", action.NextRun, "enabled", action.Enabled)
 
 	_, err := s.db.ExecContext(ctx,
-		`UPDATE scheduled_actions SET description=?, schedule=?, tool_calls=?, synthesis_prompt=?, next_run=?, enabled=?, skill_id=? WHERE id=?`,
-		action.Description, action.Schedule, action.ToolCalls, action.SynthesisPrompt, action.NextRun, boolToInt(action.Enabled), action.SkillID, action.ID)
+		`UPDATE scheduled_actions SET description=?, schedule=?, tool_calls=?, synthesis_prompt=?, next_run=?, enabled=?, skill_id=?, missed_policy=?, last_run=? WHERE id=?`,
+		action.Description, action.Schedule, action.ToolCalls, action.SynthesisPrompt, action.NextRun, boolToInt(action.Enabled), action.SkillID,
+		string(action.MissedPolicy), action.LastRun, action.ID)
 	if err != nil {
 		s.logger.Error("sqlite: update scheduled action failed", "id", action.ID, "error", err, "duration", time.Since(start))
 		return err
@@ -80,6 +82,24 @@ func (s *Store) UpdateScheduledAction(ctx context.Context, action oasis.Schedule
 	return nil
 }
 
+// ClaimScheduledAction implements oasis.ScheduledActionClaimer with a
+// compare-and-set on next_run, so only one runner fires each due time.
+func (s *Store) ClaimScheduledAction(ctx context.Context, id string, due, next, now int64) (bool, error) {
+	start := time.Now()
+	s.logger.Debug("sqlite: claim scheduled action", "id", id, "due", due, "next_run", next)
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE scheduled_actions SET next_run=?, last_run=? WHERE id=? AND next_run=?`,
+		next, now, id, due)
+	if err != nil {
+		s.logger.Error("sqlite: claim scheduled action failed", "id", id, "error", err, "duration", time.Since(start))
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	s.logger.Debug("sqlite: claim scheduled action ok", "id", id, "claimed", n == 1, "duration", time.Since(start))
+	return n == 1, nil
+}
+
 func (s *Store) UpdateScheduledActionEnabled(ctx context.Context, id string, enabled bool) error {
 	start := time.Now()
 	s.logger.Debug("sqlite: update scheduled action enabled", "id", id, "enabled", enabled)
@@ -124,7 +144,7 @@ func (s *Store) ListScheduledActionsByDescription(ctx context.Context, pattern s
 	start := time.Now()
 	s.logger.Debug("sqlite: list scheduled actions by description", "pattern", pattern)
 
-	rows, err := s.db.QueryContext(ctx, `SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at FROM scheduled_actions WHERE description LIKE ?`, "%"+pattern+"%")
+	rows, err := s.db.QueryContext(ctx, `SELECT id, description, schedule, tool_calls, synthesis_prompt, next_run, enabled, skill_id, created_at, missed_policy, last_run FROM scheduled_actions WHERE description LIKE ?`, "%"+pattern+"%")
 	if err != nil {
 		s.logger.Error("sqlite: list scheduled actions by description failed", "pattern", pattern, "error", err, "duration", time.Since(start))
 		return nil, err
@@ -144,10 +164,14 @@ func scanScheduledActions(rows *sql.Rows) ([]oasis.ScheduledAction, error) {
 	for rows.Next() {
 		var a oasis.ScheduledAction
 		var enabled int
-		if err := rows.Scan(&a.ID, &a.Description, &a.Schedule, &a.ToolCalls, &a.SynthesisPrompt, &a.NextRun, &enabled, &a.SkillID, &a.CreatedAt); err != nil {
+		var policy sql.NullString
+		var lastRun sql.NullInt64
+		if err := rows.Scan(&a.ID, &a.Description, &a.Schedule, &a.ToolCalls, &a.SynthesisPrompt, &a.NextRun, &enabled, &a.SkillID, &a.CreatedAt, &policy, &lastRun); err != nil {
 			return nil, err
 		}
 		a.Enabled = enabled != 0
+		a.MissedPolicy = oasis.MissedPolicy(policy.String)
+		a.LastRun = lastRun.Int64
 		actions = append(actions, a)
 	}
 	return actions, rows.Err()
@@ -55,6 +55,13 @@ type Store struct {
 	docChunkCount map[string]int  // chunk count per docID in vecIndex
 	evictedDocs   map[string]bool // docIDs evicted from in-memory index
 
+	// Message content encryption (WithContentEncryption); nil = plaintext.
+	enc    *contentCipher
+	encErr error
+
+	// leaseHolder identifies this Store as a LeaseStore holder.
+	leaseHolder string
+
 	// ItemStore (memory items) — initialized lazily on first Memory() call.
 	memoryOnce sync.Once
 	itemStore  *ItemStore
@@ -69,11 +76,13 @@ type vecEntry struct {
 
 var _ oasis.Store = (*Store)(nil)
 var _ oasis.KeywordSearcher = (*Store)(nil)
+var _ oasis.MessageTextSearcher = (*Store)(nil)
 var _ oasis.GraphStore = (*Store)(nil)
 var _ oasis.BidirectionalGraphStore = (*Store)(nil)
 var _ oasis.CheckpointStore = (*Store)(nil)
 var _ oasis.DocumentMetaLister = (*Store)(nil)
 var _ oasis.ScheduledActionStore = (*Store)(nil)
+var _ oasis.ScheduledActionClaimer = (*Store)(nil)
 
 // nopLogger is a logger that discards all output.
 var nopLogger = slog.New(discardHandler{})
@@ -101,7 +110,7 @@ func New(dbPath string, opts ...StoreOption) *Store {
 	}
 
 	db.SetMaxOpenConns(4)
-	s := &Store{db: db, logger: nopLogger}
+	s := &Store{db: db, logger: nopLogger, leaseHolder: oasis.NewID()}
 	for _, o := range opts {
 		o(s)
 	}
@@ -113,6 +122,9 @@ func New(dbPath string, opts ...StoreOption) *Store {
 func (s *Store) Init(ctx context.Context) error {
 	start := time.Now()
 	s.logger.Debug("sqlite: init started")
+	if s.encErr != nil {
+		return s.encErr
+	}
 	tables := []string{
 		`CREATE TABLE IF NOT EXISTS documents (
 			id TEXT PRIMARY KEY,
@@ -166,7 +178,9 @@ func (s *Store) Init(ctx context.Context) error {
 		next_run INTEGER,
 		enabled INTEGER DEFAULT 1,
 		skill_id TEXT,
-		created_at INTEGER
+		created_at INTEGER,
+		missed_policy TEXT,
+		last_run INTEGER
 	)`)
 	if err != nil {
 		return fmt.Errorf("create table: %w", err)
@@ -195,9 +209,12 @@ func (s *Store) Init(ctx context.Context) error {
 
 	// Migrations (best-effort, silent fail if already applied)
 	_, _ = s.db.ExecContext(ctx, "ALTER TABLE scheduled_actions ADD COLUMN skill_id TEXT")
+	_, _ = s.db.ExecContext(ctx, "ALTER TABLE scheduled_actions ADD COLUMN missed_policy TEXT")
+	_, _ = s.db.ExecContext(ctx, "ALTER TABLE scheduled_actions ADD COLUMN last_run INTEGER")
 	_, _ = s.db.ExecContext(ctx, "ALTER TABLE chunks ADD COLUMN parent_id TEXT")
 	_, _ = s.db.ExecContext(ctx, "ALTER TABLE chunks ADD COLUMN metadata TEXT")
 	_, _ = s.db.ExecContext(ctx, "ALTER TABLE messages ADD COLUMN metadata TEXT")
+	_, _ = s.db.ExecContext(ctx, "ALTER TABLE messages ADD COLUMN content_key TEXT")
 
 	// Migrate conversations → threads
 	_, _ = s.db.ExecContext(ctx, "ALTER TABLE conversations RENAME TO threads")
@@ -215,6 +232,19 @@ func (s *Store) Init(ctx context.Context) error {
 	// FTS5 full-text index for keyword search over chunks.
 	_, _ = s.db.ExecContext(ctx, `CREATE VIRTUAL TABLE IF NOT EXISTS chunks_fts USING fts5(chunk_id UNINDEXED, content)`)
 
+	// FTS5 index for SearchMessagesText. Backfilled once when first created.
+	var hasMessagesFTS int
+	_ = s.db.QueryRowContext(ctx, `SELECT count(*) FROM sqlite_master WHERE name = 'messages_fts'`).Scan(&hasMessagesFTS)
+	if hasMessagesFTS == 0 {
+		if _, err := s.db.ExecContext(ctx, `CREATE VIRTUAL TABLE messages_fts USING fts5(message_id UNINDEXED, content)`); err != nil {
+			return fmt.Errorf("create messages fts: %w", err)
+		}
+		if _, err := s.db.ExecContext(ctx, `INSERT INTO messages_fts(message_id, content)
+			SELECT id, content FROM messages WHERE content_key IS NULL`); err != nil {
+			return fmt.Errorf("backfill messages fts: %w", err)
+		}
+	}
+
 	// Graph RAG edge table.
 	_, _ = s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS chunk_edges (
 		id TEXT PRIMARY KEY,
@@ -239,6 +269,21 @@ func (s *Store) Init(ctx context.Context) error {
 		updated_at INTEGER NOT NULL
 	)`)
 
+	_, _ = s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS leases (
+		name       TEXT PRIMARY KEY,
+		holder     TEXT NOT NULL,
+		expires_at INTEGER NOT NULL
+	)`)
+
+	_, _ = s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS stream_frames (
+		stream_id  TEXT NOT NULL,
+		seq        INTEGER NOT NULL,
+		type       TEXT NOT NULL,
+		data       TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (stream_id, seq)
+	)`)
+
 	s.logger.Info("sqlite: init completed", "duration", time.Since(start))
 	return nil
 }
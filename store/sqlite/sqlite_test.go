@@ -137,6 +137,19 @@ func TestConfig(t *testing.T) {
 	if val != "v2" {
 		t.Errorf("expected v2, got %q", val)
 	}
+
+	s.SetConfig(ctx, "idempotency:a", "1")
+	s.SetConfig(ctx, "idempotency_b", "2") // not under the prefix
+	entries, err := s.ListConfig(ctx, "idempotency:")
+	if err != nil || len(entries) != 1 || entries["idempotency:a"] != "1" {
+		t.Errorf("ListConfig = %v, %v", entries, err)
+	}
+	if err := s.DeleteConfig(ctx, "idempotency:a"); err != nil {
+		t.Fatalf("DeleteConfig: %v", err)
+	}
+	if val, _ = s.GetConfig(ctx, "idempotency:a"); val != "" {
+		t.Errorf("deleted key still returns %q", val)
+	}
 }
 
 func TestStoreDocument(t *testing.T) {
@@ -304,6 +317,37 @@ func TestScheduledActions(t *testing.T) {
 	}
 }
 
+func TestClaimScheduledAction(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+
+	due := oasis.NowUnix() - 7200
+	action := oasis.ScheduledAction{
+		ID: oasis.NewID(), Description: "hourly sync", Schedule: "every 1h",
+		NextRun: due, Enabled: true, MissedPolicy: oasis.MissedSkip, CreatedAt: oasis.NowUnix(),
+	}
+	if err := s.CreateScheduledAction(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+
+	now := oasis.NowUnix()
+	if ok, err := s.ClaimScheduledAction(ctx, action.ID, due, now+3600, now); err != nil || !ok {
+		t.Fatalf("first claim = %v, %v; want true", ok, err)
+	}
+	if ok, err := s.ClaimScheduledAction(ctx, action.ID, due, now+3600, now); err != nil || ok {
+		t.Fatalf("second claim of the same fire time = %v, %v; want false", ok, err)
+	}
+
+	actions, err := s.ListScheduledActions(ctx)
+	if err != nil || len(actions) != 1 {
+		t.Fatalf("list: %v, %d actions", err, len(actions))
+	}
+	got := actions[0]
+	if got.NextRun != now+3600 || got.LastRun != now || got.MissedPolicy != oasis.MissedSkip {
+		t.Errorf("after claim: next_run=%d last_run=%d policy=%q", got.NextRun, got.LastRun, got.MissedPolicy)
+	}
+}
+
 func TestConcurrentWrites_NoBusyError(t *testing.T) {
 	s := testStore(t)
 	ctx := context.Background()
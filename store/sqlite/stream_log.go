@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+var _ oasis.StreamLogStore = (*Store)(nil)
+
+// --- Stream frames ---
+
+// AppendStreamFrame implements oasis.StreamLogStore.
+func (s *Store) AppendStreamFrame(ctx context.Context, streamID string, f oasis.StreamFrame) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO stream_frames (stream_id, seq, type, data, created_at) VALUES (?, ?, ?, ?, ?)`,
+		streamID, f.Seq, f.Type, string(f.Data), time.Now().UnixMilli())
+	if err != nil {
+		s.logger.Error("sqlite: append stream frame failed", "stream_id", streamID, "seq", f.Seq, "error", err)
+	}
+	return err
+}
+
+// StreamFrames implements oasis.StreamLogStore.
+func (s *Store) StreamFrames(ctx context.Context, streamID string) ([]oasis.StreamFrame, error) {
+	start := time.Now()
+	s.logger.Debug("sqlite: stream frames", "stream_id", streamID)
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT seq, type, data FROM stream_frames WHERE stream_id = ? ORDER BY seq`, streamID)
+	if err != nil {
+		s.logger.Error("sqlite: stream frames failed", "stream_id", streamID, "error", err, "duration", time.Since(start))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var frames []oasis.StreamFrame
+	for rows.Next() {
+		var f oasis.StreamFrame
+		var data string
+		if err := rows.Scan(&f.Seq, &f.Type, &data); err != nil {
+			return nil, err
+		}
+		f.Data = []byte(data)
+		frames = append(frames, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	s.logger.Debug("sqlite: stream frames ok", "stream_id", streamID, "count", len(frames), "duration", time.Since(start))
+	return frames, nil
+}
+
+// DeleteStreamFrames implements oasis.StreamLogStore.
+func (s *Store) DeleteStreamFrames(ctx context.Context, streamID string) error {
+	start := time.Now()
+	s.logger.Debug("sqlite: delete stream frames", "stream_id", streamID)
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM stream_frames WHERE stream_id = ?`, streamID); err != nil {
+		s.logger.Error("sqlite: delete stream frames failed", "stream_id", streamID, "error", err, "duration", time.Since(start))
+		return err
+	}
+	s.logger.Debug("sqlite: delete stream frames ok", "stream_id", streamID, "duration", time.Since(start))
+	return nil
+}
+
+// PruneStreamFrames implements oasis.StreamLogStore.
+func (s *Store) PruneStreamFrames(ctx context.Context, before time.Time) (int, error) {
+	start := time.Now()
+	s.logger.Debug("sqlite: prune stream frames", "before", before)
+
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM stream_frames WHERE stream_id IN (
+			SELECT stream_id FROM stream_frames GROUP BY stream_id HAVING MAX(created_at) < ?)`,
+		before.UnixMilli())
+	if err != nil {
+		s.logger.Error("sqlite: prune stream frames failed", "error", err, "duration", time.Since(start))
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	s.logger.Debug("sqlite: prune stream frames ok", "deleted", n, "duration", time.Since(start))
+	return int(n), nil
+}
@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+func TestStreamFrames(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "stream.db"))
+	ctx := context.Background()
+	if err := s.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, f := range []oasis.StreamFrame{
+		{Seq: 2, Type: "done", Data: []byte(`{"output":"hi"}`)},
+		{Seq: 1, Type: "text-delta", Data: []byte(`{"content":"hi"}`)},
+	} {
+		if err := s.AppendStreamFrame(ctx, "s1", f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frames, err := s.StreamFrames(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[0].Seq != 1 || frames[1].Type != "done" || string(frames[1].Data) != `{"output":"hi"}` {
+		t.Fatalf("frames = %+v", frames)
+	}
+
+	if err := s.DeleteStreamFrames(ctx, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if frames, _ := s.StreamFrames(ctx, "s1"); len(frames) != 0 {
+		t.Errorf("frames after delete = %+v", frames)
+	}
+}
+
+func TestPruneStreamFrames(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "stream.db"))
+	ctx := context.Background()
+	if err := s.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, id := range []string{"s1", "s2"} {
+		if err := s.AppendStreamFrame(ctx, id, oasis.StreamFrame{Seq: 1, Type: "text-delta", Data: []byte(`{}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Backdate s1 as if its process crashed an hour ago.
+	if _, err := s.db.ExecContext(ctx, `UPDATE stream_frames SET created_at = ? WHERE stream_id = 's1'`, time.Now().Add(-time.Hour).UnixMilli()); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := s.PruneStreamFrames(ctx, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("pruned = %d, want 1", n)
+	}
+	if frames, _ := s.StreamFrames(ctx, "s1"); len(frames) != 0 {
+		t.Errorf("stale stream kept %d frames", len(frames))
+	}
+	if frames, _ := s.StreamFrames(ctx, "s2"); len(frames) != 1 {
+		t.Errorf("recent stream has %d frames, want 1", len(frames))
+	}
+}
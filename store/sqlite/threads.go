@@ -134,6 +134,11 @@ func (s *Store) DeleteThread(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback() //nolint:errcheck
 
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM messages_fts WHERE message_id IN (SELECT id FROM messages WHERE thread_id = ?)`, id)
+	if err != nil {
+		return fmt.Errorf("delete thread messages fts: %w", err)
+	}
 	_, err = tx.ExecContext(ctx, `DELETE FROM messages WHERE thread_id = ?`, id)
 	if err != nil {
 		s.logger.Error("sqlite: delete thread messages failed", "id", id, "error", err)
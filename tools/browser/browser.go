@@ -0,0 +1,577 @@
+// Package browser provides a headless-browser tool for pages that only render
+// their content with JavaScript.
+//
+// The browse tool loads a URL in headless Chromium, waits for the network to
+// go idle, and returns the rendered text and final URL — what http_fetch
+// cannot see on single-page apps and client-rendered sites:
+//
+//	b := browser.New(browser.WithScreenshot())
+//	defer b.Close()
+//	agent := oasis.NewAgent("research", "...", provider, oasis.WithTools(b))
+//
+// The tool speaks the Chrome DevTools Protocol directly, so it adds no Go
+// dependencies. Chromium itself is a runtime dependency: by default the first
+// call launches a local chromium / google-chrome binary found on PATH and
+// reuses it until Close. WithRemote connects to an already-running browser
+// (a container, browserless, chrome --remote-debugging-port) instead.
+package browser
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	oasis "github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/internal/netguard"
+)
+
+const (
+	defaultTimeout  = 30 * time.Second
+	defaultMaxBytes = 32 * 1024 // 32KB
+	// defaultIdle is how long the page must have no in-flight requests after
+	// the load event before it counts as settled.
+	defaultIdle = 500 * time.Millisecond
+	// launchTimeout bounds how long a local Chromium gets to print its
+	// DevTools endpoint.
+	launchTimeout = 20 * time.Second
+)
+
+// execCandidates are the binaries tried, in order, when no exec path is set.
+var execCandidates = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "headless_shell"}
+
+// BrowseInput is the input payload for the browse tool.
+type BrowseInput struct {
+	URL string `json:"url" describe:"http(s) URL to load and render"`
+}
+
+// BrowseOutput is the JSON content of a browse result.
+type BrowseOutput struct {
+	// URL is the page's final URL after redirects and client-side routing.
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	// Text is the rendered innerText of the page body.
+	Text string `json:"text"`
+	// Truncated reports that Text was cut at the byte limit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Page is a rendered page, as returned by Browse.
+type Page struct {
+	URL   string
+	Title string
+	Text  string
+	// Screenshot is a PNG of the viewport, set only with WithScreenshot.
+	Screenshot []byte
+}
+
+// Tool renders pages in headless Chromium. It implements oasis.AnyTool
+// directly rather than oasis.Tool[In, Out] because the screenshot travels as
+// a ToolResult attachment, which typed tools cannot set.
+//
+// A Tool is safe for concurrent use: each call opens its own browser tab.
+type Tool struct {
+	execPath     string
+	flags        []string
+	remote       string
+	timeout      time.Duration
+	maxBytes     int
+	screenshot   bool
+	idle         time.Duration
+	allowPrivate bool
+	logger       *slog.Logger
+	// lookup resolves hosts for the private-address check; tests stub it.
+	lookup func(ctx context.Context, network, host string) ([]net.IP, error)
+
+	mu      sync.Mutex
+	client  *cdpClient
+	cmd     *exec.Cmd
+	dataDir string
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithExecPath sets the Chromium binary to launch. Default: the first of
+// chromium, chromium-browser, google-chrome, google-chrome-stable and
+// headless_shell found on PATH.
+func WithExecPath(path string) Option {
+	return func(t *Tool) { t.execPath = path }
+}
+
+// WithFlags appends command-line flags for the launched browser, for example
+// "--no-sandbox" when running as root in a container. Ignored with WithRemote.
+func WithFlags(flags ...string) Option {
+	return func(t *Tool) { t.flags = append(t.flags, flags...) }
+}
+
+// WithRemote connects to a running browser at its DevTools websocket URL
+// (ws://host:9222/devtools/browser/<id>) instead of launching one.
+func WithRemote(wsURL string) Option {
+	return func(t *Tool) { t.remote = wsURL }
+}
+
+// WithTimeout sets the navigation deadline: loading the page and waiting for
+// the network to settle. Default: 30s.
+func WithTimeout(d time.Duration) Option {
+	return func(t *Tool) { t.timeout = d }
+}
+
+// WithMaxBytes caps the returned page text. Default: 32KB.
+func WithMaxBytes(n int) Option {
+	return func(t *Tool) { t.maxBytes = n }
+}
+
+// WithScreenshot attaches a PNG screenshot of the rendered viewport to each
+// result, for vision-capable models.
+func WithScreenshot() Option {
+	return func(t *Tool) { t.screenshot = true }
+}
+
+// WithAllowPrivateIPs lets the tool load pages and subresources on
+// loopback, private, link-local and other non-public addresses, which are
+// refused by default.
+func WithAllowPrivateIPs() Option {
+	return func(t *Tool) { t.allowPrivate = true }
+}
+
+// WithLogger sets a logger for DevTools failures the tool works around,
+// such as a tab that could not be closed. Default: no logging.
+func WithLogger(l *slog.Logger) Option {
+	return func(t *Tool) { t.logger = l }
+}
+
+// New creates a browse tool. No browser is started until the first call.
+func New(opts ...Option) *Tool {
+	t := &Tool{
+		timeout:  defaultTimeout,
+		maxBytes: defaultMaxBytes,
+		idle:     defaultIdle,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Name implements oasis.AnyTool.
+func (t *Tool) Name() string { return "browse" }
+
+// Definition implements oasis.AnyTool.
+func (t *Tool) Definition() oasis.ToolDefinition {
+	desc := "Load a web page in a headless browser, run its JavaScript, and return the rendered text and final URL. " +
+		"Use for single-page apps and pages whose content http_fetch cannot see; it is slower than http_fetch."
+	if t.screenshot {
+		desc += " A screenshot of the page is attached."
+	}
+	return oasis.ToolDefinition{
+		Name:        "browse",
+		Description: desc,
+		Parameters:  oasis.DeriveSchema[BrowseInput](),
+	}
+}
+
+// Cacheable implements oasis.CacheableTool. Browsing drives a live page and
+// its actions have side effects, so it is never cached.
+func (t *Tool) Cacheable() bool { return false }
+
+// ExecuteRaw implements oasis.AnyTool. Bad URLs, navigation failures and
+// timeouts are tool errors the model can react to; a browser that cannot be
+// started or reached is an infrastructure error.
+func (t *Tool) ExecuteRaw(ctx context.Context, args json.RawMessage) (oasis.ToolResult, error) {
+	var in BrowseInput
+	if err := json.Unmarshal(args, &in); err != nil {
+		return oasis.ToolResult{Error: "invalid args: " + err.Error()}, nil
+	}
+	u, err := url.Parse(in.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return oasis.ToolResult{Error: fmt.Sprintf("invalid url %q: must be an absolute http or https URL", in.URL)}, nil
+	}
+
+	page, err := t.Browse(ctx, in.URL)
+	if err != nil {
+		var le *launchError
+		if errors.As(err, &le) || ctx.Err() != nil {
+			return oasis.ToolResult{}, err
+		}
+		return oasis.ToolResult{Error: err.Error()}, nil
+	}
+
+	out := BrowseOutput{URL: page.URL, Title: page.Title, Text: page.Text}
+	if t.maxBytes > 0 && len(out.Text) > t.maxBytes {
+		cut := t.maxBytes
+		for cut > 0 && !utf8.RuneStart(out.Text[cut]) {
+			cut--
+		}
+		out.Text = out.Text[:cut] + "\n... (truncated)"
+		out.Truncated = true
+	}
+	content, err := json.Marshal(out)
+	if err != nil {
+		return oasis.ToolResult{}, err
+	}
+	res := oasis.ToolResult{Content: string(content)}
+	if len(page.Screenshot) > 0 {
+		res.Attachments = []oasis.Attachment{{MimeType: "image/png", Data: page.Screenshot}}
+	}
+	return res, nil
+}
+
+// Browse loads rawURL in a fresh tab and returns the rendered page.
+// Exported for use by other tools.
+//
+// Unless WithAllowPrivateIPs is set, a URL whose host resolves to a
+// non-public address is refused before the browser is asked to load it, and
+// every request the tab makes afterwards — redirects, subresources, script
+// fetches — is paused and failed if its host resolves to one. The browser
+// does its own DNS lookup after the check, so a host that re-resolves to a
+// private address in between (DNS rebinding) can still get through; run the
+// browser in a network that cannot reach internal services where that
+// matters.
+func (t *Tool) Browse(ctx context.Context, rawURL string) (*Page, error) {
+	if !t.allowPrivate {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := netguard.CheckHost(ctx, u.Hostname(), t.lookup); err != nil {
+			return nil, err
+		}
+	}
+	c, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := c.call(ctx, "", "Target.createTarget", map[string]any{"url": "about:blank"}, &target); err != nil {
+		return nil, err
+	}
+	// Why a detached context: the tab must be closed even when ctx was
+	// cancelled mid-navigation, or a long-lived browser leaks tabs.
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := c.call(closeCtx, "", "Target.closeTarget", map[string]any{"targetId": target.TargetID}, nil); err != nil && t.logger != nil {
+			t.logger.Warn("browser: close tab failed", "target", target.TargetID, "error", err)
+		}
+	}()
+
+	var attached struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := c.call(ctx, "", "Target.attachToTarget", map[string]any{"targetId": target.TargetID, "flatten": true}, &attached); err != nil {
+		return nil, err
+	}
+	session := attached.SessionID
+	events := c.subscribe(session)
+	defer c.unsubscribe(session)
+
+	for _, method := range []string{"Page.enable", "Network.enable"} {
+		if err := c.call(ctx, session, method, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	if !t.allowPrivate {
+		c.onRequestPaused(session, func(ev cdpMessage) { t.guardRequest(ctx, c, session, ev) })
+		if err := c.call(ctx, session, "Fetch.enable", map[string]any{"patterns": []map[string]any{{"urlPattern": "*"}}}, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	navCtx := ctx
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		navCtx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	events.drain() // anything from the initial about:blank load
+	var nav struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := c.call(navCtx, session, "Page.navigate", map[string]any{"url": rawURL}, &nav); err != nil {
+		if ctx.Err() == nil && navCtx.Err() != nil {
+			return nil, fmt.Errorf("navigation timed out after %s", t.timeout)
+		}
+		return nil, err
+	}
+	if nav.ErrorText != "" {
+		return nil, fmt.Errorf("navigation failed: %s", nav.ErrorText)
+	}
+	if err := t.waitIdle(navCtx, events); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	var eval struct {
+		Result struct {
+			Value string `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+	err = c.call(ctx, session, "Runtime.evaluate", map[string]any{
+		"expression":    `JSON.stringify({url: location.href, title: document.title, text: document.body ? document.body.innerText : ""})`,
+		"returnByValue": true,
+	}, &eval)
+	if err != nil {
+		return nil, err
+	}
+	if eval.ExceptionDetails != nil {
+		return nil, fmt.Errorf("extract page text: %s", eval.ExceptionDetails.Text)
+	}
+	var page Page
+	if err := json.Unmarshal([]byte(eval.Result.Value), &page); err != nil {
+		return nil, fmt.Errorf("extract page text: %w", err)
+	}
+	page.Text = strings.TrimSpace(page.Text)
+
+	if t.screenshot {
+		var shot struct {
+			Data string `json:"data"`
+		}
+		if err := c.call(ctx, session, "Page.captureScreenshot", map[string]any{"format": "png"}, &shot); err != nil {
+			return nil, err
+		}
+		if page.Screenshot, err = base64.StdEncoding.DecodeString(shot.Data); err != nil {
+			return nil, fmt.Errorf("decode screenshot: %w", err)
+		}
+	}
+	return &page, nil
+}
+
+// guardRequest answers one Fetch.requestPaused event: the request goes on
+// if its host resolves to public addresses only and is failed otherwise.
+func (t *Tool) guardRequest(ctx context.Context, c *cdpClient, session string, ev cdpMessage) {
+	var p struct {
+		RequestID string `json:"requestId"`
+		Request   struct {
+			URL string `json:"url"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(ev.Params, &p); err != nil {
+		if t.logger != nil {
+			t.logger.Warn("browser: malformed paused request", "error", err)
+		}
+		return
+	}
+	method, params := "Fetch.continueRequest", map[string]any{"requestId": p.RequestID}
+	if u, err := url.Parse(p.Request.URL); err != nil || (u.Host != "" && netguard.CheckHost(ctx, u.Hostname(), t.lookup) != nil) {
+		method, params["errorReason"] = "Fetch.failRequest", "AccessDenied"
+		if t.logger != nil {
+			t.logger.Warn("browser: blocked request to a private address", "url", p.Request.URL)
+		}
+	}
+	if err := c.call(ctx, session, method, params, nil); err != nil && t.logger != nil {
+		t.logger.Warn("browser: answer paused request failed", "url", p.Request.URL, "error", err)
+	}
+}
+
+// waitIdle returns once the load event has fired and no requests have been
+// in flight for t.idle. Pages that never go quiet (long polling, analytics
+// beacons) are accepted as-is when ctx expires after the load event.
+func (t *Tool) waitIdle(ctx context.Context, events *eventQueue) error {
+	inflight := map[string]bool{}
+	loaded := false
+	var idle <-chan time.Time
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-events.signal:
+			for _, ev := range events.drain() {
+				var p struct {
+					RequestID string `json:"requestId"`
+				}
+				switch ev.Method {
+				case "Page.loadEventFired":
+					loaded = true
+				case "Network.requestWillBeSent":
+					if err := json.Unmarshal(ev.Params, &p); err != nil {
+						return fmt.Errorf("decode %s: %w", ev.Method, err)
+					}
+					inflight[p.RequestID] = true
+				case "Network.loadingFinished", "Network.loadingFailed":
+					if err := json.Unmarshal(ev.Params, &p); err != nil {
+						return fmt.Errorf("decode %s: %w", ev.Method, err)
+					}
+					delete(inflight, p.RequestID)
+				}
+			}
+			// Re-arm on every batch so the quiet period restarts after
+			// each burst of activity.
+			if timer != nil {
+				timer.Stop()
+				idle = nil
+			}
+			if loaded && len(inflight) == 0 {
+				timer = time.NewTimer(t.idle)
+				idle = timer.C
+			}
+		case <-idle:
+			return nil
+		case <-ctx.Done():
+			if loaded {
+				return nil
+			}
+			return fmt.Errorf("navigation timed out after %s", t.timeout)
+		}
+	}
+}
+
+// launchError marks failures to start or reach the browser, which are
+// infrastructure errors rather than problems with the requested page.
+type launchError struct{ err error }
+
+func (e *launchError) Error() string { return e.err.Error() }
+func (e *launchError) Unwrap() error { return e.err }
+
+var devtoolsRE = regexp.MustCompile(`DevTools listening on (ws://\S+)`)
+
+// connect returns the shared browser connection, launching or dialing the
+// browser on first use and again if the previous connection dropped.
+func (t *Tool) connect(ctx context.Context) (*cdpClient, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil && t.client.alive() {
+		return t.client, nil
+	}
+	t.shutdownLocked()
+
+	wsURL := t.remote
+	if wsURL == "" {
+		var err error
+		if wsURL, err = t.launchLocked(ctx); err != nil {
+			return nil, &launchError{err}
+		}
+	}
+	ws, err := dialWS(ctx, wsURL)
+	if err != nil {
+		t.shutdownLocked()
+		return nil, &launchError{err}
+	}
+	t.client = newCDPClient(ws, t.logger)
+	return t.client, nil
+}
+
+// launchLocked starts a local headless browser and returns its DevTools URL.
+func (t *Tool) launchLocked(ctx context.Context) (string, error) {
+	path := t.execPath
+	if path == "" {
+		for _, name := range execCandidates {
+			if p, err := exec.LookPath(name); err == nil {
+				path = p
+				break
+			}
+		}
+		if path == "" {
+			return "", errors.New("no chromium or chrome binary found on PATH; set browser.WithExecPath or browser.WithRemote")
+		}
+	}
+	dir, err := os.MkdirTemp("", "oasis-browser-")
+	if err != nil {
+		return "", err
+	}
+	args := append([]string{
+		"--headless=new",
+		"--remote-debugging-port=0",
+		"--user-data-dir=" + dir,
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-gpu",
+		"--hide-scrollbars",
+		"--mute-audio",
+		"--window-size=1280,1024",
+	}, t.flags...)
+	args = append(args, "about:blank")
+
+	// Why not CommandContext: the browser outlives the call that started it
+	// and is shared by later calls until Close.
+	cmd := exec.Command(path, args...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("start %s: %w", path, err)
+	}
+	t.cmd, t.dataDir = cmd, dir
+
+	found := make(chan string, 1)
+	go func() {
+		sc := bufio.NewScanner(stderr)
+		for sc.Scan() {
+			if m := devtoolsRE.FindStringSubmatch(sc.Text()); m != nil {
+				found <- m[1]
+				break
+			}
+		}
+		// Keep draining so the browser never blocks on a full stderr pipe.
+		io.Copy(io.Discard, stderr)
+	}()
+
+	timer := time.NewTimer(launchTimeout)
+	defer timer.Stop()
+	select {
+	case u := <-found:
+		return u, nil
+	case <-timer.C:
+		t.shutdownLocked()
+		return "", fmt.Errorf("%s did not report a DevTools endpoint within %s", path, launchTimeout)
+	case <-ctx.Done():
+		t.shutdownLocked()
+		return "", ctx.Err()
+	}
+}
+
+// Close shuts down the browser connection and any browser the tool
+// launched. The next call starts a new one.
+func (t *Tool) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.shutdownLocked()
+	return nil
+}
+
+func (t *Tool) shutdownLocked() {
+	if t.client != nil {
+		t.client.close()
+		t.client = nil
+	}
+	if t.cmd != nil {
+		t.cmd.Process.Kill()
+		t.cmd.Wait()
+		t.cmd = nil
+	}
+	if t.dataDir != "" {
+		os.RemoveAll(t.dataDir)
+		t.dataDir = ""
+	}
+}
+
+// compile-time check
+var _ oasis.AnyTool = (*Tool)(nil)
@@ -0,0 +1,327 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCDP is a DevTools endpoint that answers the commands Browse sends and
+// emits the page events a real browser would after Page.navigate.
+type fakeCDP struct {
+	mu       sync.Mutex
+	methods  []string
+	navError string
+	noLoad   bool   // never fire Page.loadEventFired
+	text     string // body innerText returned by Runtime.evaluate
+	// subrequests are URLs the page requests after navigation. With
+	// Fetch enabled each is paused, and so is the document, and the
+	// navigation completes only once all of them are answered.
+	subrequests []string
+	answers     map[string]string // paused request ID -> Fetch method
+}
+
+func (f *fakeCDP) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		rw.Flush()
+		ws := &wsConn{conn: conn, br: rw.Reader}
+
+		send := func(m cdpMessage) {
+			b, _ := json.Marshal(m)
+			ws.writeMessage(b)
+		}
+		event := func(method, params string) {
+			send(cdpMessage{Method: method, Params: json.RawMessage(params), SessionID: "S1"})
+		}
+		finishNav := func(id int64) {
+			send(cdpMessage{ID: id, Result: json.RawMessage(`{"frameId":"F1"}`)})
+			event("Network.requestWillBeSent", `{"requestId":"doc"}`)
+			event("Network.loadingFinished", `{"requestId":"doc"}`)
+			if !f.noLoad {
+				event("Page.loadEventFired", `{}`)
+			}
+			// A script-initiated fetch after load; the tool must wait for it.
+			event("Network.requestWillBeSent", `{"requestId":"xhr"}`)
+			event("Network.loadingFinished", `{"requestId":"xhr"}`)
+		}
+		fetch := false
+		var navID int64
+		var pending int
+		for {
+			data, err := ws.readMessage()
+			if err != nil {
+				return
+			}
+			var req cdpMessage
+			json.Unmarshal(data, &req)
+			f.mu.Lock()
+			f.methods = append(f.methods, req.Method)
+			f.mu.Unlock()
+
+			result := `{}`
+			switch req.Method {
+			case "Target.createTarget":
+				result = `{"targetId":"T1"}`
+			case "Target.attachToTarget":
+				result = `{"sessionId":"S1"}`
+			case "Fetch.enable":
+				fetch = true
+			case "Fetch.continueRequest", "Fetch.failRequest":
+				var p struct {
+					RequestID string `json:"requestId"`
+				}
+				json.Unmarshal(req.Params, &p)
+				f.mu.Lock()
+				if f.answers == nil {
+					f.answers = map[string]string{}
+				}
+				f.answers[p.RequestID] = req.Method
+				f.mu.Unlock()
+				send(cdpMessage{ID: req.ID, Result: json.RawMessage(result), SessionID: req.SessionID})
+				if pending--; pending == 0 {
+					finishNav(navID)
+				}
+				continue
+			case "Page.navigate":
+				if f.navError != "" {
+					result = `{"frameId":"F1","errorText":"` + f.navError + `"}`
+					break
+				}
+				if !fetch {
+					finishNav(req.ID)
+					continue
+				}
+				var p struct {
+					URL string `json:"url"`
+				}
+				json.Unmarshal(req.Params, &p)
+				navID, pending = req.ID, 1+len(f.subrequests)
+				paused := func(id, u string) {
+					b, _ := json.Marshal(map[string]any{"requestId": id, "request": map[string]string{"url": u}})
+					event("Fetch.requestPaused", string(b))
+				}
+				paused("doc", p.URL)
+				for i, u := range f.subrequests {
+					paused(fmt.Sprint("sub", i), u)
+				}
+				continue
+			case "Runtime.evaluate":
+				v, _ := json.Marshal(map[string]string{
+					"url": "https://example.com/app#/home", "title": "App", "text": f.text,
+				})
+				b, _ := json.Marshal(map[string]any{"result": map[string]any{"type": "string", "value": string(v)}})
+				result = string(b)
+			case "Page.captureScreenshot":
+				result = `{"data":"` + base64.StdEncoding.EncodeToString([]byte("PNGDATA")) + `"}`
+			}
+			send(cdpMessage{ID: req.ID, Result: json.RawMessage(result), SessionID: req.SessionID})
+		}
+	}
+}
+
+func (f *fakeCDP) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.methods...)
+}
+
+func newFake(t *testing.T, f *fakeCDP, opts ...Option) *Tool {
+	t.Helper()
+	srv := httptest.NewServer(f.handler(t))
+	t.Cleanup(srv.Close)
+	opts = append([]Option{WithRemote("ws" + strings.TrimPrefix(srv.URL, "http") + "/devtools/browser/x")}, opts...)
+	tool := New(opts...)
+	tool.idle = 20 * time.Millisecond
+	tool.lookup = publicLookup
+	t.Cleanup(func() { tool.Close() })
+	return tool
+}
+
+// publicLookup resolves every host to a public address, so tests need no DNS.
+func publicLookup(context.Context, string, string) ([]net.IP, error) {
+	return []net.IP{net.ParseIP("93.184.216.34")}, nil
+}
+
+func TestBrowseRendersPage(t *testing.T) {
+	f := &fakeCDP{text: "  Hello from JavaScript  "}
+	tool := newFake(t, f, WithScreenshot())
+
+	res, err := tool.ExecuteRaw(context.Background(), json.RawMessage(`{"url":"https://example.com/app"}`))
+	if err != nil {
+		t.Fatalf("ExecuteRaw: %v", err)
+	}
+	if res.Error != "" {
+		t.Fatalf("tool error: %s", res.Error)
+	}
+	var out BrowseOutput
+	if err := json.Unmarshal([]byte(res.Content), &out); err != nil {
+		t.Fatalf("content is not BrowseOutput JSON: %v (%s)", err, res.Content)
+	}
+	if out.URL != "https://example.com/app#/home" || out.Title != "App" || out.Text != "Hello from JavaScript" {
+		t.Errorf("out = %+v", out)
+	}
+	if len(res.Attachments) != 1 || res.Attachments[0].MimeType != "image/png" || string(res.Attachments[0].Data) != "PNGDATA" {
+		t.Errorf("attachments = %+v", res.Attachments)
+	}
+
+	calls := f.calls()
+	if calls[len(calls)-1] != "Target.closeTarget" {
+		t.Errorf("tab not closed; calls = %v", calls)
+	}
+}
+
+func TestBrowseNoScreenshotByDefault(t *testing.T) {
+	f := &fakeCDP{text: "hi"}
+	tool := newFake(t, f)
+	res, err := tool.ExecuteRaw(context.Background(), json.RawMessage(`{"url":"https://example.com"}`))
+	if err != nil || res.Error != "" {
+		t.Fatalf("err=%v res.Error=%q", err, res.Error)
+	}
+	if len(res.Attachments) != 0 {
+		t.Errorf("unexpected attachments: %+v", res.Attachments)
+	}
+	for _, m := range f.calls() {
+		if m == "Page.captureScreenshot" {
+			t.Error("screenshot captured without WithScreenshot")
+		}
+	}
+}
+
+func TestBrowseTruncatesText(t *testing.T) {
+	f := &fakeCDP{text: strings.Repeat("é", 100)}
+	tool := newFake(t, f, WithMaxBytes(51))
+	res, _ := tool.ExecuteRaw(context.Background(), json.RawMessage(`{"url":"https://example.com"}`))
+	var out BrowseOutput
+	json.Unmarshal([]byte(res.Content), &out)
+	if !out.Truncated {
+		t.Fatalf("expected truncation: %+v", out)
+	}
+	if want := strings.Repeat("é", 25) + "\n... (truncated)"; out.Text != want {
+		t.Errorf("text = %q, want %q", out.Text, want)
+	}
+}
+
+func TestBrowseNavigationError(t *testing.T) {
+	f := &fakeCDP{navError: "net::ERR_NAME_NOT_RESOLVED"}
+	tool := newFake(t, f)
+	res, err := tool.ExecuteRaw(context.Background(), json.RawMessage(`{"url":"https://nope.invalid"}`))
+	if err != nil {
+		t.Fatalf("navigation failure should be a tool error, got Go error %v", err)
+	}
+	if !strings.Contains(res.Error, "ERR_NAME_NOT_RESOLVED") {
+		t.Errorf("res.Error = %q", res.Error)
+	}
+}
+
+func TestBrowseNavigationTimeout(t *testing.T) {
+	f := &fakeCDP{noLoad: true}
+	tool := newFake(t, f, WithTimeout(100*time.Millisecond))
+	res, err := tool.ExecuteRaw(context.Background(), json.RawMessage(`{"url":"https://example.com"}`))
+	if err != nil {
+		t.Fatalf("timeout should be a tool error, got Go error %v", err)
+	}
+	if !strings.Contains(res.Error, "timed out after 100ms") {
+		t.Errorf("res.Error = %q", res.Error)
+	}
+	calls := f.calls()
+	if calls[len(calls)-1] != "Target.closeTarget" {
+		t.Errorf("tab not closed after timeout; calls = %v", calls)
+	}
+}
+
+func TestBrowseRejectsBadURL(t *testing.T) {
+	tool := New(WithRemote("ws://127.0.0.1:1/unused"))
+	for _, u := range []string{"", "file:///etc/passwd", "javascript:alert(1)", "example.com"} {
+		args, _ := json.Marshal(BrowseInput{URL: u})
+		res, err := tool.ExecuteRaw(context.Background(), args)
+		if err != nil || res.Error == "" {
+			t.Errorf("url %q: err=%v res.Error=%q, want tool error", u, err, res.Error)
+		}
+	}
+}
+
+func TestBrowseUnreachableBrowserIsInfraError(t *testing.T) {
+	tool := New(WithRemote("ws://127.0.0.1:1/devtools/browser/x"))
+	tool.lookup = publicLookup
+	_, err := tool.ExecuteRaw(context.Background(), json.RawMessage(`{"url":"https://example.com"}`))
+	if err == nil {
+		t.Fatal("expected a Go error when the browser cannot be reached")
+	}
+}
+
+func TestBrowseBlocksPrivateAddresses(t *testing.T) {
+	f := &fakeCDP{text: "secret"}
+	tool := newFake(t, f)
+	tool.lookup = func(_ context.Context, _, host string) ([]net.IP, error) {
+		if host == "metadata.internal" {
+			return []net.IP{net.ParseIP("169.254.169.254")}, nil
+		}
+		return publicLookup(nil, "", host)
+	}
+	for _, u := range []string{"http://127.0.0.1:8080/admin", "http://metadata.internal/latest/"} {
+		args, _ := json.Marshal(BrowseInput{URL: u})
+		res, err := tool.ExecuteRaw(context.Background(), args)
+		if err != nil || !strings.Contains(res.Error, "blocked") {
+			t.Errorf("url %q: err=%v res.Error=%q, want a blocked tool error", u, err, res.Error)
+		}
+	}
+	if calls := f.calls(); len(calls) != 0 {
+		t.Errorf("browser was driven for a private address: %v", calls)
+	}
+
+	allowed := newFake(t, f, WithAllowPrivateIPs())
+	res, err := allowed.ExecuteRaw(context.Background(), json.RawMessage(`{"url":"http://127.0.0.1:8080/admin"}`))
+	if err != nil || res.Error != "" {
+		t.Errorf("WithAllowPrivateIPs: err=%v res.Error=%q", err, res.Error)
+	}
+}
+
+func TestBrowseBlocksPrivateSubrequests(t *testing.T) {
+	f := &fakeCDP{text: "page", subrequests: []string{
+		"http://169.254.169.254/latest/meta-data/", // e.g. a redirect or an <img>
+		"https://cdn.example.com/app.js",
+	}}
+	tool := newFake(t, f)
+	if _, err := tool.Browse(context.Background(), "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	want := map[string]string{"doc": "Fetch.continueRequest", "sub0": "Fetch.failRequest", "sub1": "Fetch.continueRequest"}
+	for id, method := range want {
+		if f.answers[id] != method {
+			t.Errorf("request %s answered with %q, want %q", id, f.answers[id], method)
+		}
+	}
+}
+
+func TestEventQueueBounded(t *testing.T) {
+	q := &eventQueue{signal: make(chan struct{}, 1)}
+	for i := range maxQueuedEvents + 10 {
+		kept := q.push(cdpMessage{Method: fmt.Sprint(i)})
+		if want := i < maxQueuedEvents; kept != want {
+			t.Fatalf("push %d kept = %v, want %v", i, kept, want)
+		}
+	}
+	items := q.drain()
+	if len(items) != maxQueuedEvents || items[0].Method != "10" {
+		t.Errorf("queue holds %d events starting at %q, want %d starting at the 11th", len(items), items[0].Method, maxQueuedEvents)
+	}
+}
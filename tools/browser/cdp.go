@@ -0,0 +1,226 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// maxQueuedEvents bounds each session's event buffer. A page that floods
+// events faster than the consumer drains them loses the oldest ones.
+const maxQueuedEvents = 1024
+
+// cdpMessage is one Chrome DevTools Protocol frame: a command response
+// (ID set) or an event (Method set), optionally scoped to a target session.
+type cdpMessage struct {
+	ID        int64           `json:"id,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *cdpError       `json:"error,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+}
+
+type cdpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *cdpError) Error() string { return fmt.Sprintf("cdp error %d: %s", e.Code, e.Message) }
+
+// cdpClient multiplexes commands and events over one browser-level
+// connection. Each page runs in its own flattened target session.
+type cdpClient struct {
+	ws     *wsConn
+	logger *slog.Logger // nil = no logging
+	nextID atomic.Int64
+
+	mu       sync.Mutex
+	pending  map[int64]chan cdpMessage
+	sessions map[string]*eventQueue
+	paused   map[string]func(cdpMessage) // Fetch.requestPaused handlers by session
+	err      error                       // set when the read loop exits
+
+	done chan struct{}
+}
+
+func newCDPClient(ws *wsConn, logger *slog.Logger) *cdpClient {
+	c := &cdpClient{
+		ws:       ws,
+		logger:   logger,
+		pending:  make(map[int64]chan cdpMessage),
+		sessions: make(map[string]*eventQueue),
+		paused:   make(map[string]func(cdpMessage)),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+func (c *cdpClient) readLoop() {
+	var err error
+	for {
+		var data []byte
+		data, err = c.ws.readMessage()
+		if err != nil {
+			break
+		}
+		var msg cdpMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if c.logger != nil {
+				c.logger.Warn("browser: malformed devtools message", "error", err)
+			}
+			continue
+		}
+		c.mu.Lock()
+		if msg.ID != 0 {
+			if ch, ok := c.pending[msg.ID]; ok {
+				delete(c.pending, msg.ID)
+				ch <- msg
+			}
+		} else if h, ok := c.paused[msg.SessionID]; ok && msg.Method == "Fetch.requestPaused" {
+			// The handler answers with a command of its own, whose response
+			// this loop has to read, so it cannot run inline.
+			go h(msg)
+		} else if q, ok := c.sessions[msg.SessionID]; ok {
+			if !q.push(msg) && c.logger != nil {
+				c.logger.Warn("browser: event queue full, dropped oldest event", "session", msg.SessionID)
+			}
+		}
+		c.mu.Unlock()
+	}
+	c.mu.Lock()
+	c.err = fmt.Errorf("devtools connection closed: %w", err)
+	c.pending = map[int64]chan cdpMessage{}
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// alive reports whether the connection is still usable.
+func (c *cdpClient) alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// call sends method with params and decodes the result into out (if non-nil).
+func (c *cdpClient) call(ctx context.Context, session, method string, params, out any) error {
+	id := c.nextID.Add(1)
+	msg := cdpMessage{ID: id, Method: method, SessionID: session}
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = b
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan cdpMessage, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	if err := c.ws.writeMessage(data); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s: %w", method, resp.Error)
+		}
+		if out != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, out)
+		}
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	case <-c.done:
+		return c.err
+	}
+}
+
+// subscribe starts buffering events for session. Call before the command
+// that triggers them so none are missed.
+func (c *cdpClient) subscribe(session string) *eventQueue {
+	q := &eventQueue{signal: make(chan struct{}, 1)}
+	c.mu.Lock()
+	c.sessions[session] = q
+	c.mu.Unlock()
+	return q
+}
+
+// onRequestPaused routes session's Fetch.requestPaused events to h instead
+// of its event queue. A paused request blocks the page until h continues or
+// fails it, so h must not wait on the queue's consumer.
+func (c *cdpClient) onRequestPaused(session string, h func(cdpMessage)) {
+	c.mu.Lock()
+	c.paused[session] = h
+	c.mu.Unlock()
+}
+
+func (c *cdpClient) unsubscribe(session string) {
+	c.mu.Lock()
+	delete(c.sessions, session)
+	delete(c.paused, session)
+	c.mu.Unlock()
+}
+
+func (c *cdpClient) close() error {
+	err := c.ws.close()
+	<-c.done
+	return err
+}
+
+// eventQueue is a per-session event buffer of at most maxQueuedEvents. The
+// read loop must never block on a slow consumer, or command responses
+// queued behind an event would never be delivered, so a full queue drops
+// its oldest event instead.
+type eventQueue struct {
+	mu     sync.Mutex
+	items  []cdpMessage
+	signal chan struct{}
+}
+
+// push appends m and reports false if an older event was dropped for it.
+func (q *eventQueue) push(m cdpMessage) bool {
+	q.mu.Lock()
+	kept := len(q.items) < maxQueuedEvents
+	if !kept {
+		q.items = q.items[1:]
+	}
+	q.items = append(q.items, m)
+	q.mu.Unlock()
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+	return kept
+}
+
+func (q *eventQueue) drain() []cdpMessage {
+	q.mu.Lock()
+	items := q.items
+	q.items = nil
+	q.mu.Unlock()
+	return items
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 	"unicode/utf8"
 
@@ -14,6 +17,7 @@ import (
 
 	oasis "github.com/nevindra/oasis/core"
 	"github.com/nevindra/oasis/ingest"
+	"github.com/nevindra/oasis/internal/netguard"
 )
 
 // defaultFetchMaxChars is the default character cap applied to extracted
@@ -26,21 +30,142 @@ type FetchInput struct {
 	MaxChars int    `json:"max_chars,omitempty" describe:"Max characters to return (default 8000)"`
 }
 
+// maxRedirects matches net/http's default redirect limit.
+const maxRedirects = 10
+
 // Tool fetches URLs and extracts readable content. It implements
 // oasis.Tool[FetchInput, string] — one tool = one operation. The output is
 // kept as a bare string for ergonomic LLM consumption: the model just sees
 // the extracted text, and Erase wraps it as JSON automatically.
 type Tool struct {
-	client *http.Client
+	client       *http.Client
+	allowedHosts []string // nil = any host
+	allowPrivate bool
+	headers      map[string]string
+	bearer       func() string
+	hostHeaders  map[string]map[string]string // normalized host → headers
+	credHosts    []string                     // hosts that get headers and bearer
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithAllowedHosts restricts fetches, including redirect targets, to the
+// given hosts. An entry matches the host itself and any subdomain:
+// "example.com" allows "docs.example.com". Comparison is case-insensitive
+// and ignores the port.
+func WithAllowedHosts(hosts ...string) Option {
+	return func(t *Tool) {
+		for _, h := range hosts {
+			t.allowedHosts = append(t.allowedHosts, normalizeHost(h))
+		}
+	}
+}
+
+// WithBlockPrivateIPs refuses connections to loopback, private, link-local
+// (including the 169.254.169.254 cloud metadata endpoint), CGNAT and other
+// non-public addresses. This is the default; the option exists to make the
+// policy explicit at the call site. While the block is on, the
+// HTTP_PROXY/HTTPS_PROXY environment variables are ignored.
+func WithBlockPrivateIPs() Option {
+	return func(t *Tool) { t.allowPrivate = false }
+}
+
+// WithAllowPrivateIPs disables the private-address block, for agents that
+// are meant to read intranet pages. Combine with WithAllowedHosts. It also
+// restores the proxy from HTTP_PROXY/HTTPS_PROXY, as http.DefaultTransport
+// uses it.
+func WithAllowPrivateIPs() Option {
+	return func(t *Tool) { t.allowPrivate = true }
+}
+
+// WithHeaders adds headers to requests for the hosts listed with
+// WithCredentialHosts; without that option they are never sent. They are
+// also dropped when a redirect moves to another host. Header values never
+// appear in tool results or errors.
+func WithHeaders(headers map[string]string) Option {
+	return func(t *Tool) {
+		if t.headers == nil {
+			t.headers = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			t.headers[k] = v
+		}
+	}
 }
 
-// New creates an HTTPTool with a 15-second timeout.
-func New() *Tool {
-	return &Tool{
-		client: &http.Client{Timeout: 15 * time.Second},
+// WithBearerToken sets "Authorization: Bearer <token>" on every request,
+// calling token per request so it can return a refreshed credential. An
+// empty token sends no header. Scoped to WithCredentialHosts and dropped on
+// cross-host redirects, like WithHeaders.
+func WithBearerToken(token func() string) Option {
+	return func(t *Tool) { t.bearer = token }
+}
+
+// WithCredentialHosts lists the hosts that receive WithHeaders and
+// WithBearerToken. An entry matches the request's URL host exactly
+// (case-insensitive); an entry without a port matches any port. Subdomains
+// do not match, so the model cannot send credentials to a host the
+// application did not name.
+func WithCredentialHosts(hosts ...string) Option {
+	return func(t *Tool) {
+		for _, h := range hosts {
+			t.credHosts = append(t.credHosts, normalizeHost(h))
+		}
 	}
 }
 
+// WithHostHeaders adds headers only to requests for host and its
+// subdomains, so each internal service can get its own credentials. They
+// override WithHeaders and WithBearerToken for the same header name.
+func WithHostHeaders(host string, headers map[string]string) Option {
+	return func(t *Tool) {
+		if t.hostHeaders == nil {
+			t.hostHeaders = make(map[string]map[string]string)
+		}
+		h := normalizeHost(host)
+		if t.hostHeaders[h] == nil {
+			t.hostHeaders[h] = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			t.hostHeaders[h][k] = v
+		}
+	}
+}
+
+// New creates an HTTPTool with a 15-second timeout. Requests to private and
+// link-local addresses are blocked unless WithAllowPrivateIPs is set.
+func New(opts ...Option) *Tool {
+	t := &Tool{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !t.allowPrivate {
+		// Why at dial time: checking the resolved address of the actual
+		// connection covers redirects and DNS rebinding, which a check on
+		// the URL's hostname cannot.
+		dialer.Control = blockPrivateControl
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	if !t.allowPrivate {
+		// Why: through a proxy the dialed address is the proxy's, so the
+		// private-address check could not see the real destination.
+		transport.Proxy = nil
+	}
+	var rt http.RoundTripper = transport
+	if t.headers != nil || t.bearer != nil || t.hostHeaders != nil {
+		rt = &headerTransport{base: transport, tool: t}
+	}
+	t.client = &http.Client{
+		Timeout:       15 * time.Second,
+		Transport:     rt,
+		CheckRedirect: t.checkRedirect,
+	}
+	return t
+}
+
 // Definition implements oasis.Tool.
 func (t *Tool) Definition() oasis.ToolMeta {
 	return oasis.ToolMeta{
@@ -49,6 +174,10 @@ func (t *Tool) Definition() oasis.ToolMeta {
 	}
 }
 
+// Cacheable implements oasis.CacheableTool. Pages change, so fetches are
+// never served from a tool or response cache.
+func (t *Tool) Cacheable() bool { return false }
+
 // Execute implements oasis.Tool. Returns the extracted, possibly-truncated
 // readable text for the given URL. Truncation happens at a UTF-8 rune
 // boundary so the returned string is always valid UTF-8.
@@ -81,6 +210,9 @@ func (t *Tool) Fetch(ctx context.Context, rawURL string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("invalid URL: %w", err)
 	}
+	if err := t.checkURL(req.URL); err != nil {
+		return "", err
+	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; OasisBot/1.0)")
 
 	resp, err := t.client.Do(req)
@@ -111,5 +243,122 @@ func (t *Tool) Fetch(ctx context.Context, rawURL string) (string, error) {
 	return ingest.StripHTML(html), nil
 }
 
+// checkURL enforces the scheme and host allowlist for a request or redirect
+// target.
+func (t *Tool) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("blocked: scheme %q is not allowed, only http and https", u.Scheme)
+	}
+	if !t.hostAllowed(u.Hostname()) {
+		return fmt.Errorf("blocked: host %q is not in the allowed hosts list", u.Hostname())
+	}
+	return nil
+}
+
+func (t *Tool) hostAllowed(host string) bool {
+	if t.allowedHosts == nil {
+		return true
+	}
+	for _, h := range t.allowedHosts {
+		if hostMatches(host, h) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// hostMatches reports whether host is pattern or a subdomain of it. pattern
+// must already be normalized.
+func hostMatches(host, pattern string) bool {
+	host = normalizeHost(host)
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// headerTransport injects configured headers per request, so redirect hops
+// get the headers that belong to their own host.
+type headerTransport struct {
+	base http.RoundTripper
+	tool *Tool
+}
+
+func (h *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := h.tool
+	req = req.Clone(req.Context())
+	host := req.URL.Hostname()
+
+	// Walk back through the redirect chain to the host the model asked for.
+	origin := req
+	for origin.Response != nil && origin.Response.Request != nil {
+		origin = origin.Response.Request
+	}
+	sameHost := normalizeHost(req.URL.Host) == normalizeHost(origin.URL.Host)
+	if sameHost && t.credentialHost(req.URL) {
+		for k, v := range t.headers {
+			req.Header.Set(k, v)
+		}
+		if t.bearer != nil {
+			if tok := t.bearer(); tok != "" {
+				req.Header.Set("Authorization", "Bearer "+tok)
+			}
+		}
+	}
+	// Apply shorter patterns first so the most specific host wins.
+	var matched []string
+	for pattern := range t.hostHeaders {
+		if hostMatches(host, pattern) {
+			matched = append(matched, pattern)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return len(matched[i]) < len(matched[j]) })
+	for _, pattern := range matched {
+		for k, v := range t.hostHeaders[pattern] {
+			req.Header.Set(k, v)
+		}
+	}
+	return h.base.RoundTrip(req)
+}
+
+// credentialHost reports whether u's host is listed in WithCredentialHosts.
+func (t *Tool) credentialHost(u *url.URL) bool {
+	hostPort, hostname := normalizeHost(u.Host), normalizeHost(u.Hostname())
+	for _, h := range t.credHosts {
+		if h == hostPort || h == hostname {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tool) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if err := t.checkURL(req.URL); err != nil {
+		return fmt.Errorf("redirect to %s %w", req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// errPrivateAddress is returned by the dialer for non-public addresses.
+var errPrivateAddress = netguard.ErrPrivateAddress
+
+// blockPrivateControl is a net.Dialer Control hook that rejects connections
+// to non-public IPs. address is the already-resolved "ip:port".
+func blockPrivateControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || netguard.IsPrivateIP(ip) {
+		return fmt.Errorf("%w (%s)", errPrivateAddress, host)
+	}
+	return nil
+}
+
 // compile-time check
 var _ oasis.Tool[FetchInput, string] = (*Tool)(nil)
@@ -3,8 +3,10 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -18,7 +20,7 @@ func TestHTTPFetchBasic(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	tool := New()
+	tool := New(WithAllowPrivateIPs())
 	out, err := tool.Execute(context.Background(), FetchInput{URL: srv.URL})
 	if err != nil {
 		t.Fatal(err)
@@ -34,7 +36,7 @@ func TestHTTPFetch404(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	tool := New()
+	tool := New(WithAllowPrivateIPs())
 	_, err := tool.Execute(context.Background(), FetchInput{URL: srv.URL})
 	if err == nil {
 		t.Error("expected error for 404")
@@ -51,7 +53,7 @@ func TestHTTPFetchTruncation(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	tool := New()
+	tool := New(WithAllowPrivateIPs())
 	out, _ := tool.Execute(context.Background(), FetchInput{URL: srv.URL})
 	if len(out) > 8100 {
 		t.Errorf("content not truncated: %d", len(out))
@@ -66,7 +68,7 @@ func TestHTTPFetchErased(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	any := oasis.Erase[FetchInput, string](New())
+	any := oasis.Erase[FetchInput, string](New(WithAllowPrivateIPs()))
 	if any.Name() != "http_fetch" {
 		t.Errorf("Name = %q, want http_fetch", any.Name())
 	}
@@ -105,3 +107,170 @@ func TestHTTPFetchErasedBadArgs(t *testing.T) {
 		t.Error("expected ToolResult.Error for bad args")
 	}
 }
+
+func TestHTTPFetchBlocksPrivateIPsByDefault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback server")
+	}))
+	defer srv.Close()
+
+	_, err := New().Execute(context.Background(), FetchInput{URL: srv.URL})
+	if err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Fatalf("err = %v, want private address block", err)
+	}
+}
+
+func TestHTTPFetchProxyOnlyWithoutPrivateBlock(t *testing.T) {
+	proxy := func(tool *Tool) func(*http.Request) (*url.URL, error) {
+		return tool.client.Transport.(*http.Transport).Proxy
+	}
+	if proxy(New()) != nil {
+		t.Error("default tool uses the environment proxy, which would bypass the private-address block")
+	}
+	if proxy(New(WithAllowPrivateIPs())) == nil {
+		t.Error("WithAllowPrivateIPs tool ignores the environment proxy")
+	}
+}
+
+func TestHTTPFetchAllowedHosts(t *testing.T) {
+	tool := New(WithAllowedHosts("example.com"))
+	for _, u := range []string{"http://evil.com/", "http://example.com.evil.com/", "ftp://example.com/"} {
+		_, err := tool.Execute(context.Background(), FetchInput{URL: u})
+		if err == nil || !strings.Contains(err.Error(), "blocked") {
+			t.Errorf("%s: err = %v, want blocked", u, err)
+		}
+	}
+	for _, h := range []string{"example.com", "Docs.Example.com", "example.com."} {
+		if !tool.hostAllowed(h) {
+			t.Errorf("hostAllowed(%q) = false", h)
+		}
+	}
+}
+
+func TestHTTPFetchRedirectToDisallowedHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://metadata.internal/latest/", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	tool := New(WithAllowPrivateIPs(), WithAllowedHosts("127.0.0.1"))
+	res, err := oasis.Erase[FetchInput, string](tool).ExecuteRaw(context.Background(),
+		json.RawMessage(`{"url":"`+srv.URL+`"}`))
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if !strings.Contains(res.Error, "redirect to http://metadata.internal/latest/ blocked") {
+		t.Errorf("res.Error = %q, want redirect block visible to the model", res.Error)
+	}
+}
+
+func TestHTTPFetchHeadersAndBearer(t *testing.T) {
+	var got []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	calls := 0
+	tool := New(WithAllowPrivateIPs(),
+		WithCredentialHosts("127.0.0.1"),
+		WithHeaders(map[string]string{"X-Team": "ops"}),
+		WithBearerToken(func() string { calls++; return fmt.Sprintf("tok-%d", calls) }),
+	)
+	for i := 0; i < 2; i++ {
+		if _, err := tool.Execute(context.Background(), FetchInput{URL: srv.URL}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("requests = %d", len(got))
+	}
+	if got[0].Get("X-Team") != "ops" {
+		t.Errorf("X-Team = %q", got[0].Get("X-Team"))
+	}
+	// The token function runs per request so credentials can refresh.
+	if got[0].Get("Authorization") != "Bearer tok-1" || got[1].Get("Authorization") != "Bearer tok-2" {
+		t.Errorf("Authorization = %q, %q", got[0].Get("Authorization"), got[1].Get("Authorization"))
+	}
+}
+
+func TestHTTPFetchHeadersDroppedOnCrossDomainRedirect(t *testing.T) {
+	var leaked http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Clone()
+		w.Write([]byte("landed"))
+	}))
+	defer other.Close()
+	// Same server, reached through a different host name.
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, otherURL, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// Both hosts are credential hosts; the redirect still drops the
+	// headers because it changes host.
+	tool := New(WithAllowPrivateIPs(),
+		WithCredentialHosts("127.0.0.1", "localhost"),
+		WithHeaders(map[string]string{"X-Api-Key": "secret"}),
+		WithBearerToken(func() string { return "secret" }),
+		WithHostHeaders("localhost", map[string]string{"X-Service": "other"}),
+	)
+	out, err := tool.Execute(context.Background(), FetchInput{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "landed") {
+		t.Fatalf("redirect not followed: %q", out)
+	}
+	if leaked.Get("Authorization") != "" || leaked.Get("X-Api-Key") != "" {
+		t.Errorf("credentials leaked across redirect: %v", leaked)
+	}
+	if leaked.Get("X-Service") != "other" {
+		t.Errorf("per-host header missing on redirect target: %v", leaked)
+	}
+}
+
+func TestHTTPFetchNoCredentialsForUnlistedHost(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	// The model picks a host the application did not list.
+	tool := New(WithAllowPrivateIPs(),
+		WithCredentialHosts("api.example.com"),
+		WithHeaders(map[string]string{"X-Api-Key": "secret"}),
+		WithBearerToken(func() string { return "secret" }),
+	)
+	if _, err := tool.Execute(context.Background(), FetchInput{URL: srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Authorization") != "" || got.Get("X-Api-Key") != "" {
+		t.Errorf("credentials sent to unlisted host: %v", got)
+	}
+}
+
+func TestHTTPFetchHostHeadersScoped(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	tool := New(WithAllowPrivateIPs(),
+		WithBearerToken(func() string { return "global" }),
+		WithHostHeaders("127.0.0.1", map[string]string{"Authorization": "Bearer billing"}),
+		WithHostHeaders("billing.internal", map[string]string{"X-Other": "no"}),
+	)
+	tool.Execute(context.Background(), FetchInput{URL: srv.URL})
+	if got.Get("Authorization") != "Bearer billing" {
+		t.Errorf("Authorization = %q, want per-host override", got.Get("Authorization"))
+	}
+	if got.Get("X-Other") != "" {
+		t.Errorf("header for another host was sent")
+	}
+}
@@ -0,0 +1,146 @@
+// Package imagegen provides an image-generation tool backed by an
+// image-capable chat model.
+//
+// The generate_image tool sends the model's prompt to a provider with image
+// output enabled and returns the generated images as ToolResult attachments.
+// The agent loop collects tool attachments into AgentResult.Attachments, so
+// images produced mid-run reach the caller without extra wiring:
+//
+//	img := gemini.New(key, "gemini-2.5-flash-image")
+//	agent := oasis.NewAgent("designer", "...", provider,
+//	    oasis.WithTools(imagegen.New(img)),
+//	)
+//	result, _ := agent.Execute(ctx, task)
+//	for _, a := range result.Attachments { /* a.MimeType, a.Data */ }
+//
+// Any provider that maps ChatRequest.Modalities to image output works:
+// Gemini image models, and OpenAI-compatible gateways such as OpenRouter.
+package imagegen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+// GenerateInput is the input payload for the generate_image tool.
+type GenerateInput struct {
+	Prompt string `json:"prompt" describe:"Detailed description of the image to generate: subject, style, composition, colors"`
+}
+
+// GenerateOutput is the JSON content of a generate_image result. The images
+// themselves travel as attachments.
+type GenerateOutput struct {
+	Images int `json:"images"`
+	// Text is any caption or commentary the image model returned.
+	Text string `json:"text,omitempty"`
+}
+
+// Tool generates images with an image-capable provider. It implements
+// oasis.AnyTool directly rather than oasis.Tool[In, Out] because the images
+// travel as ToolResult attachments, which typed tools cannot set.
+type Tool struct {
+	provider     oasis.Provider
+	instructions string
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithInstructions sends s as the system prompt of every generation call —
+// a house style, aspect ratio or content policy the tool's caller should not
+// have to repeat in each prompt.
+func WithInstructions(s string) Option {
+	return func(t *Tool) { t.instructions = s }
+}
+
+// New creates a generate_image tool that renders images with provider.
+// provider must be configured with an image-output model; the agent itself
+// can use any model.
+func New(provider oasis.Provider, opts ...Option) *Tool {
+	t := &Tool{provider: provider}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Name implements oasis.AnyTool.
+func (t *Tool) Name() string { return "generate_image" }
+
+// Definition implements oasis.AnyTool.
+func (t *Tool) Definition() oasis.ToolDefinition {
+	return oasis.ToolDefinition{
+		Name: "generate_image",
+		Description: "Generate an image from a text description. The image is attached to the result and returned to the user. " +
+			"Describe the subject, style and composition in detail.",
+		Parameters: oasis.DeriveSchema[GenerateInput](),
+	}
+}
+
+// Cacheable implements oasis.CacheableTool. Every call should produce a new
+// image, so results are never cached.
+func (t *Tool) Cacheable() bool { return false }
+
+// ExecuteRaw implements oasis.AnyTool. A model that answers without an
+// image is a tool error the model can react to; a failed provider call is
+// an infrastructure error.
+func (t *Tool) ExecuteRaw(ctx context.Context, args json.RawMessage) (oasis.ToolResult, error) {
+	var in GenerateInput
+	if err := json.Unmarshal(args, &in); err != nil {
+		return oasis.ToolResult{Error: "invalid args: " + err.Error()}, nil
+	}
+	if strings.TrimSpace(in.Prompt) == "" {
+		return oasis.ToolResult{Error: "prompt is required"}, nil
+	}
+
+	images, text, err := t.Generate(ctx, in.Prompt)
+	if err != nil {
+		if errors.Is(err, errNoImage) {
+			msg := err.Error()
+			if text != "" {
+				msg += "; it said: " + text
+			}
+			return oasis.ToolResult{Error: msg}, nil
+		}
+		return oasis.ToolResult{}, err
+	}
+	content, err := json.Marshal(GenerateOutput{Images: len(images), Text: text})
+	if err != nil {
+		return oasis.ToolResult{}, err
+	}
+	return oasis.ToolResult{Content: string(content), Attachments: images}, nil
+}
+
+var errNoImage = errors.New("model returned no image")
+
+// Generate renders prompt and returns the image attachments and any text
+// the model returned alongside them. Exported for use outside an agent.
+func (t *Tool) Generate(ctx context.Context, prompt string) ([]oasis.Attachment, string, error) {
+	var msgs []oasis.ChatMessage
+	if t.instructions != "" {
+		msgs = append(msgs, oasis.SystemMessage(t.instructions))
+	}
+	msgs = append(msgs, oasis.UserMessage(prompt))
+	resp, err := oasis.Chat(ctx, t.provider, oasis.ChatRequest{
+		Messages:   msgs,
+		Modalities: []string{"text", "image"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	var images []oasis.Attachment
+	for _, a := range resp.Attachments {
+		if strings.HasPrefix(a.MimeType, "image/") {
+			images = append(images, a)
+		}
+	}
+	text := strings.TrimSpace(resp.Content)
+	if len(images) == 0 {
+		return nil, text, errNoImage
+	}
+	return images, text, nil
+}
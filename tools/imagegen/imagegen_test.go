@@ -0,0 +1,100 @@
+package imagegen_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nevindra/oasis/agent"
+	oasis "github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/tools/imagegen"
+)
+
+// scriptedProvider returns its responses in order and records the requests.
+type scriptedProvider struct {
+	mu        sync.Mutex
+	responses []oasis.ChatResponse
+	reqs      []oasis.ChatRequest
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) ChatStream(_ context.Context, req oasis.ChatRequest, ch chan<- oasis.StreamEvent) (oasis.ChatResponse, error) {
+	if ch != nil {
+		defer close(ch)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reqs = append(p.reqs, req)
+	resp := p.responses[0]
+	if len(p.responses) > 1 {
+		p.responses = p.responses[1:]
+	}
+	return resp, nil
+}
+
+var png = []byte("\x89PNG fake")
+
+func TestExecuteRaw(t *testing.T) {
+	img := &scriptedProvider{responses: []oasis.ChatResponse{{
+		Content:     "A red fox.",
+		Attachments: []oasis.Attachment{{MimeType: "image/png", Data: png}},
+	}}}
+	tool := imagegen.New(img, imagegen.WithInstructions("flat illustration"))
+
+	res, err := tool.ExecuteRaw(context.Background(), json.RawMessage(`{"prompt":"a fox"}`))
+	if err != nil || res.Error != "" {
+		t.Fatalf("ExecuteRaw = %+v, %v", res, err)
+	}
+	if len(res.Attachments) != 1 || string(res.Attachments[0].Data) != string(png) {
+		t.Fatalf("attachments = %+v, want the generated image", res.Attachments)
+	}
+	if res.Content != `{"images":1,"text":"A red fox."}` {
+		t.Errorf("content = %s", res.Content)
+	}
+	req := img.reqs[0]
+	if strings.Join(req.Modalities, ",") != "text,image" || req.Messages[0].Content != "flat illustration" || req.Messages[1].Content != "a fox" {
+		t.Errorf("request = %+v, want image modality, instructions and prompt", req)
+	}
+
+	// A text-only answer is a tool error, not an infrastructure failure.
+	img.responses = []oasis.ChatResponse{{Content: "I can't draw that."}}
+	res, err = tool.ExecuteRaw(context.Background(), json.RawMessage(`{"prompt":"a fox"}`))
+	if err != nil || !strings.Contains(res.Error, "no image") || !strings.Contains(res.Error, "I can't draw that.") {
+		t.Errorf("ExecuteRaw = %+v, %v; want a no-image tool error", res, err)
+	}
+}
+
+func TestAgentResultCarriesGeneratedImage(t *testing.T) {
+	img := &scriptedProvider{responses: []oasis.ChatResponse{{
+		Attachments: []oasis.Attachment{{MimeType: "image/png", Data: png}},
+	}}}
+	newLLM := func() *scriptedProvider {
+		return &scriptedProvider{responses: []oasis.ChatResponse{
+			{ToolCalls: []oasis.ToolCall{{ID: "1", Name: "generate_image", Args: json.RawMessage(`{"prompt":"a fox"}`)}}},
+			{Content: "Here is your fox."},
+		}}
+	}
+	a := agent.New("designer", "draws", newLLM(), agent.WithTools(imagegen.New(img)))
+
+	result, err := a.Execute(context.Background(), oasis.AgentTask{Input: "draw a fox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Attachments) != 1 || result.Attachments[0].MimeType != "image/png" || string(result.Attachments[0].Data) != string(png) {
+		t.Fatalf("result attachments = %+v, want the generated image", result.Attachments)
+	}
+
+	// A later run must not overwrite the attachments of an earlier result.
+	b := agent.New("designer", "draws", newLLM(), agent.WithTools(imagegen.New(&scriptedProvider{responses: []oasis.ChatResponse{{
+		Attachments: []oasis.Attachment{{MimeType: "image/jpeg", Data: []byte("jpeg")}},
+	}}})))
+	if _, err := b.Execute(context.Background(), oasis.AgentTask{Input: "draw a fox"}); err != nil {
+		t.Fatal(err)
+	}
+	if result.Attachments[0].MimeType != "image/png" {
+		t.Errorf("first result's attachment changed to %s after a second run", result.Attachments[0].MimeType)
+	}
+}
@@ -0,0 +1,148 @@
+// Package messagesearch provides a full-text search tool over stored
+// conversation history.
+//
+// The message_search tool finds past messages containing the model's words
+// or phrase ("the message where I mentioned the Berlin office") — the exact
+// matches that semantic recall can miss. It needs a Store that implements
+// oasis.MessageTextSearcher, as store/sqlite and store/postgres do:
+//
+//	ms := messagesearch.New(store)
+//	agent := oasis.NewAgent("assistant", "...", provider,
+//	    oasis.WithTools(oasis.Erase[messagesearch.SearchInput, messagesearch.SearchOutput](ms)),
+//	)
+//
+// Searches are scoped to the chat of the running task (AgentTask.ChatID),
+// so one user's history never answers another's query.
+package messagesearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nevindra/oasis/agent"
+	oasis "github.com/nevindra/oasis/core"
+)
+
+const (
+	defaultLimit = 10
+	defaultMax   = 50
+)
+
+// SearchInput is the input payload for the message_search tool.
+type SearchInput struct {
+	Query string `json:"query" describe:"Words or phrase to look for in past messages"`
+	Limit int    `json:"limit,omitempty" describe:"Number of messages to return (default 10)"`
+}
+
+// Match is one past message.
+type Match struct {
+	ID        string `json:"id"`
+	ThreadID  string `json:"thread_id"`
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// SearchOutput is the result of message_search, best match first.
+type SearchOutput struct {
+	Results []Match `json:"results"`
+}
+
+// Tool runs full-text search over stored messages. It implements
+// oasis.Tool[SearchInput, SearchOutput].
+type Tool struct {
+	store    oasis.MessageTextSearcher
+	limit    int
+	maxLimit int
+	chatID   string
+	allChats bool
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithLimit sets the number of messages returned when the model does not
+// ask for a specific count. Default: 10.
+func WithLimit(n int) Option {
+	return func(t *Tool) { t.limit = n }
+}
+
+// WithMaxLimit caps the limit the model may request. Default: 50.
+func WithMaxLimit(n int) Option {
+	return func(t *Tool) { t.maxLimit = n }
+}
+
+// WithChatID searches chatID's messages instead of the running task's chat.
+func WithChatID(chatID string) Option {
+	return func(t *Tool) { t.chatID = chatID }
+}
+
+// WithAllChats searches every stored message regardless of chat. Only use
+// it when all history belongs to one user.
+func WithAllChats() Option {
+	return func(t *Tool) { t.allChats = true }
+}
+
+// New creates a message_search tool over store's messages.
+func New(store oasis.MessageTextSearcher, opts ...Option) *Tool {
+	t := &Tool{store: store, limit: defaultLimit, maxLimit: defaultMax}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Definition implements oasis.Tool.
+func (t *Tool) Definition() oasis.ToolMeta {
+	return oasis.ToolMeta{
+		Name: "message_search",
+		Description: "Search past conversation messages for exact words or phrases. " +
+			"Use it to find what was said earlier, e.g. a name, place, or number the user mentioned. " +
+			"Returns matching messages with their thread and timestamp, best match first.",
+	}
+}
+
+// Execute implements oasis.Tool. Without WithChatID or WithAllChats it
+// fails when the context carries no task with a ChatID.
+func (t *Tool) Execute(ctx context.Context, in SearchInput) (SearchOutput, error) {
+	if in.Query == "" {
+		return SearchOutput{}, errors.New("query is required")
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = t.limit
+	}
+	if t.maxLimit > 0 && limit > t.maxLimit {
+		limit = t.maxLimit
+	}
+	chatID := t.chatID
+	if chatID == "" && !t.allChats {
+		if task, ok := agent.TaskFromContext(ctx); ok {
+			chatID = task.ChatID
+		}
+		if chatID == "" {
+			return SearchOutput{}, errors.New("no chat to search: the task has no ChatID")
+		}
+	}
+
+	msgs, err := t.store.SearchMessagesText(ctx, in.Query, limit, chatID)
+	if err != nil {
+		return SearchOutput{}, oasis.InfraError(fmt.Errorf("search messages: %w", err))
+	}
+	out := SearchOutput{Results: make([]Match, len(msgs))}
+	for i, m := range msgs {
+		out.Results[i] = Match{ID: m.ID, ThreadID: m.ThreadID, Role: string(m.Role), Content: m.Content, CreatedAt: m.CreatedAt}
+	}
+	return out, nil
+}
+
+// Cacheable implements oasis.CacheableTool. Results depend on the task's
+// chat and grow with every message, so they are never served from a cache
+// shared across chats.
+func (t *Tool) Cacheable() bool { return false }
+
+var (
+	_ oasis.Tool[SearchInput, SearchOutput] = (*Tool)(nil)
+	_ oasis.CacheableTool                   = (*Tool)(nil)
+)
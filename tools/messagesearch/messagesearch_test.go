@@ -0,0 +1,72 @@
+package messagesearch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/agent"
+	oasis "github.com/nevindra/oasis/core"
+)
+
+// fakeStore records the last SearchMessagesText call.
+type fakeStore struct {
+	msgs   []oasis.Message
+	query  string
+	limit  int
+	chatID string
+}
+
+func (s *fakeStore) SearchMessagesText(_ context.Context, query string, limit int, chatID string) ([]oasis.Message, error) {
+	s.query, s.limit, s.chatID = query, limit, chatID
+	return s.msgs, nil
+}
+
+func TestExecute_ScopesToTaskChat(t *testing.T) {
+	store := &fakeStore{msgs: []oasis.Message{{ID: "m1", ThreadID: "t1", Role: oasis.RoleUser, Content: "the Berlin office", CreatedAt: 7}}}
+	tool := New(store, WithMaxLimit(20))
+	ctx := agent.WithTaskContext(context.Background(), agent.AgentTask{ChatID: "c1"})
+
+	out, err := tool.Execute(ctx, SearchInput{Query: "Berlin office", Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.query != "Berlin office" || store.limit != 20 || store.chatID != "c1" {
+		t.Errorf("search = %q limit %d chat %q", store.query, store.limit, store.chatID)
+	}
+	want := Match{ID: "m1", ThreadID: "t1", Role: "user", Content: "the Berlin office", CreatedAt: 7}
+	if len(out.Results) != 1 || out.Results[0] != want {
+		t.Errorf("results = %+v", out.Results)
+	}
+}
+
+func TestExecute_RequiresChat(t *testing.T) {
+	store := &fakeStore{}
+	if _, err := New(store).Execute(context.Background(), SearchInput{Query: "x"}); err == nil {
+		t.Error("expected an error without a chat to scope to")
+	}
+	if _, err := New(store, WithAllChats()).Execute(context.Background(), SearchInput{Query: "x"}); err != nil || store.chatID != "" || store.limit != defaultLimit {
+		t.Errorf("WithAllChats: err %v chat %q limit %d", err, store.chatID, store.limit)
+	}
+	if _, err := New(store).Execute(context.Background(), SearchInput{}); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+}
+
+func TestExecute_NotSharedThroughToolCache(t *testing.T) {
+	store := &fakeStore{}
+	cached := agent.CacheMiddleware(oasis.NewInMemoryToolCache(), time.Hour)(
+		oasis.Erase[SearchInput, SearchOutput](New(store)))
+	args := json.RawMessage(`{"query":"salary"}`)
+
+	for _, chat := range []string{"c1", "c2"} {
+		ctx := agent.WithTaskContext(context.Background(), agent.AgentTask{ChatID: chat})
+		if _, err := cached.ExecuteRaw(ctx, args); err != nil {
+			t.Fatal(err)
+		}
+		if store.chatID != chat {
+			t.Errorf("search for chat %s ran against %q: answered from another chat's cache", chat, store.chatID)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// writeKeywords are keywords that can turn a statement starting with SELECT
+// or WITH into a write: a data-modifying CTE (WITH x AS (DELETE ...)), a
+// WITH ... INSERT, SELECT ... INTO, or FOR UPDATE. They are matched against
+// whole tokens, never substrings, and only as bare words: string literals,
+// comments and quoted identifiers are skipped by tokenize, and a word
+// followed by "(" (a function such as replace()) or joined to a "." (a
+// qualified name such as o.update) is not a keyword. So columns such as
+// updated_at or created_by, and literals such as 'delete', are fine.
+//
+// Words that can only begin a statement (SET, COMMENT, LOCK, VACUUM, ...)
+// are not listed: the statement must begin with SELECT or WITH, so they are
+// rejected there, and elsewhere they are ordinary column names.
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true,
+	"DROP": true, "ALTER": true, "CREATE": true, "TRUNCATE": true,
+	"GRANT": true, "REVOKE": true, "ATTACH": true, "DETACH": true, "INTO": true,
+}
+
+// token is one lexical unit of a query. Quoted identifiers keep quoted=true
+// so they are never mistaken for keywords.
+type token struct {
+	text   string // bare words upper-cased; quoted identifiers unquoted
+	raw    string // the word as written, for identifiers in errors
+	quoted bool
+	punct  bool
+}
+
+func (t token) is(word string) bool { return !t.quoted && !t.punct && t.text == word }
+
+// check validates query and returns it with any trailing semicolon removed.
+func (t *Tool) check(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("query is required")
+	}
+	toks, err := tokenize(query)
+	if err != nil {
+		return "", err
+	}
+	// A trailing semicolon is harmless; anything after one is a second
+	// statement.
+	for len(toks) > 0 && toks[len(toks)-1].punct && toks[len(toks)-1].text == ";" {
+		toks = toks[:len(toks)-1]
+		query = strings.TrimRight(strings.TrimSpace(query), ";")
+	}
+	first := 0
+	for first < len(toks) && toks[first].punct && toks[first].text == "(" {
+		first++
+	}
+	if first >= len(toks) || !(toks[first].is("SELECT") || toks[first].is("WITH")) {
+		return "", fmt.Errorf("only SELECT queries are allowed")
+	}
+	for i, tok := range toks {
+		if tok.punct && tok.text == ";" {
+			return "", fmt.Errorf("only a single statement is allowed")
+		}
+		if !tok.quoted && !tok.punct && writeKeywords[tok.text] && !followedByParen(toks, i) && !qualified(toks, i) {
+			return "", fmt.Errorf("only read-only queries are allowed (found %s)", tok.text)
+		}
+	}
+	if t.allowed != nil {
+		if err := t.checkTables(toks); err != nil {
+			return "", err
+		}
+	}
+	return query, nil
+}
+
+func followedByParen(toks []token, i int) bool {
+	return i+1 < len(toks) && toks[i+1].punct && toks[i+1].text == "("
+}
+
+// qualified reports whether toks[i] is part of a dotted name (t.col).
+func qualified(toks []token, i int) bool {
+	dot := func(j int) bool { return j >= 0 && j < len(toks) && toks[j].punct && toks[j].text == "." }
+	return dot(i-1) || dot(i+1)
+}
+
+// parenKind is what an open parenthesis holds, as tracked by checkTables.
+type parenKind int
+
+const (
+	parenExpr   parenKind = iota // an expression or function arguments
+	parenQuery                   // a subquery: (SELECT ...) or (WITH ...)
+	parenTables                  // a join group: FROM (a JOIN b ON ...)
+)
+
+// checkTables rejects table references outside the allowlist. It walks the
+// FROM and JOIN clauses of every (sub)query, including parenthesized join
+// groups such as FROM (a JOIN b ON ...), and ignores FROM inside function
+// calls such as EXTRACT(YEAR FROM ts).
+func (t *Tool) checkTables(toks []token) error {
+	ctes := map[string]bool{}
+	for i := 0; i+1 < len(toks); i++ {
+		// "name AS (" introduces a CTE (WITH x AS (...), y AS (...)).
+		if isIdent(toks[i]) && toks[i+1].is("AS") && i+2 < len(toks) && toks[i+2].punct && toks[i+2].text == "(" {
+			ctes[strings.ToLower(toks[i].raw)] = true
+		}
+	}
+
+	// parens holds, per open paren, its kind and the enclosing inFrom.
+	type paren struct {
+		kind   parenKind
+		inFrom bool
+	}
+	var parens []paren
+	inFrom := false    // inside a FROM list, where "," introduces another table
+	expecting := false // the previous token introduced a table reference
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		startsTable := false
+		switch {
+		case tok.punct && tok.text == "(":
+			kind := parenExpr
+			switch {
+			case i+1 < len(toks) && (toks[i+1].is("SELECT") || toks[i+1].is("WITH")):
+				kind = parenQuery
+			case expecting:
+				kind = parenTables
+			}
+			parens = append(parens, paren{kind, inFrom})
+			if kind != parenTables {
+				expecting = false
+				continue
+			}
+			inFrom, startsTable = true, true
+		case tok.punct && tok.text == ")":
+			if len(parens) > 0 {
+				inFrom = parens[len(parens)-1].inFrom
+				parens = parens[:len(parens)-1]
+			}
+			expecting = false
+			continue
+		}
+		expecting = false
+		if len(parens) > 0 && parens[len(parens)-1].kind == parenExpr {
+			continue
+		}
+		startsTable = startsTable || tok.is("FROM") || tok.is("JOIN") || (inFrom && tok.punct && tok.text == ",")
+		if !startsTable {
+			if tok.is("WHERE") || tok.is("GROUP") || tok.is("ORDER") || tok.is("HAVING") ||
+				tok.is("LIMIT") || tok.is("UNION") || tok.is("ON") || tok.is("USING") || tok.is("WINDOW") {
+				inFrom = false
+			}
+			continue
+		}
+		if tok.is("FROM") {
+			inFrom = true
+		}
+		if i+1 < len(toks) && (toks[i+1].is("ONLY") || toks[i+1].is("LATERAL")) {
+			i++
+		}
+		if i+1 >= len(toks) || !isIdent(toks[i+1]) {
+			// A subquery or join group: its own FROMs and tables are
+			// checked in turn.
+			expecting = true
+			continue
+		}
+		name := toks[i+1].raw
+		j := i + 2
+		for j+1 < len(toks) && toks[j].punct && toks[j].text == "." && isIdent(toks[j+1]) {
+			name += "." + toks[j+1].raw
+			j += 2
+		}
+		if !t.tableAllowed(name, ctes) {
+			return fmt.Errorf("table %q is not in the allowed list", name)
+		}
+	}
+	return nil
+}
+
+// tableAllowed matches name exactly: a schema-qualified reference needs the
+// same qualified entry in the allowlist.
+func (t *Tool) tableAllowed(name string, ctes map[string]bool) bool {
+	lower := strings.ToLower(name)
+	return t.allowed[lower] || ctes[lower]
+}
+
+func isIdent(t token) bool {
+	if t.punct {
+		return false
+	}
+	if t.quoted {
+		return true
+	}
+	c := t.text[0]
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+// tokenize splits query into words and punctuation, dropping comments and
+// string literals (which can hold anything, including keywords).
+func tokenize(q string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == '-' && i+1 < len(q) && q[i+1] == '-':
+			for i < len(q) && q[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(q) && q[i+1] == '*':
+			end := strings.Index(q[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '\'':
+			j, err := skipQuoted(q, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			i = j
+		case c == '"' || c == '`':
+			j, err := skipQuoted(q, i, c)
+			if err != nil {
+				return nil, err
+			}
+			id := strings.ReplaceAll(q[i+1:j-1], string([]byte{c, c}), string(c))
+			toks = append(toks, token{text: id, raw: id, quoted: true})
+			i = j
+		case c == '$':
+			// Postgres dollar quoting: $tag$ ... $tag$.
+			tagEnd := strings.IndexByte(q[i+1:], '$')
+			if tagEnd < 0 || !isTag(q[i+1:i+1+tagEnd]) {
+				i++ // positional parameter such as $1
+				continue
+			}
+			tag := q[i : i+tagEnd+2]
+			end := strings.Index(q[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated dollar-quoted string")
+			}
+			i += len(tag) + end + len(tag)
+		case isWordByte(c):
+			j := i
+			for j < len(q) && isWordByte(q[j]) {
+				j++
+			}
+			toks = append(toks, token{text: strings.ToUpper(q[i:j]), raw: q[i:j]})
+			i = j
+		default:
+			toks = append(toks, token{text: string(c), punct: true})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+// skipQuoted returns the index just past the quoted run starting at q[i],
+// treating a doubled quote character as an escape.
+func skipQuoted(q string, i int, quote byte) (int, error) {
+	for j := i + 1; j < len(q); j++ {
+		if q[j] != quote {
+			continue
+		}
+		if j+1 < len(q) && q[j+1] == quote {
+			j++
+			continue
+		}
+		return j + 1, nil
+	}
+	return 0, fmt.Errorf("unterminated quoted string")
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+func isTag(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isWordByte(s[i]) || (i == 0 && s[i] >= '0' && s[i] <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,230 @@
+// Package sql provides a read-only SQL query tool over database/sql.
+//
+// The sql_query tool lets the LLM answer questions from a database — typically
+// a read replica — by running a single SELECT and getting rows back as JSON:
+//
+//	db, _ := sql.Open("pgx", replicaDSN)
+//	q := sqltool.New(db, sqltool.WithAllowedTables("orders", "customers"))
+//	agent := oasis.NewAgent("ops", "...", provider,
+//	    oasis.WithTools(oasis.Erase[sqltool.QueryInput, sqltool.QueryOutput](q)),
+//	)
+//
+// Read-only is enforced three ways: the query must be a single SELECT (or
+// WITH ... SELECT) statement with no write keywords, it runs inside a
+// read-only transaction, and that transaction is always rolled back. The
+// lexical check and the table allowlist are best-effort guards against a
+// model's mistakes, not a security boundary; connect with a database role
+// that only has SELECT on the tables you mean to expose.
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	oasis "github.com/nevindra/oasis/core"
+)
+
+const (
+	defaultTimeout  = 10 * time.Second
+	defaultMaxRows  = 100
+	defaultMaxBytes = 32 * 1024 // 32KB
+)
+
+// QueryInput is the input payload for the sql_query tool.
+type QueryInput struct {
+	Query string `json:"query" describe:"A single read-only SELECT statement"`
+}
+
+// QueryOutput is the result of sql_query.
+type QueryOutput struct {
+	Columns []string         `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+	// RowCount is the number of rows returned in Rows.
+	RowCount int `json:"row_count"`
+	// Truncated reports that the query produced more rows than were returned.
+	Truncated bool `json:"truncated,omitempty"`
+	// Note explains a truncation so the model can narrow the query.
+	Note string `json:"note,omitempty"`
+}
+
+// Tool runs read-only SQL queries. It implements
+// oasis.Tool[QueryInput, QueryOutput].
+type Tool struct {
+	db       *dbsql.DB
+	timeout  time.Duration
+	maxRows  int
+	maxBytes int
+	allowed  map[string]bool // nil = any table
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithTimeout sets the per-query deadline. The query's context is cancelled
+// when it elapses, which aborts the statement on drivers that support
+// cancellation (pgx, lib/pq, sqlite). Default: 10s.
+func WithTimeout(d time.Duration) Option {
+	return func(t *Tool) { t.timeout = d }
+}
+
+// WithMaxRows caps the rows returned per query. Default: 100.
+func WithMaxRows(n int) Option {
+	return func(t *Tool) { t.maxRows = n }
+}
+
+// WithMaxBytes caps the JSON size of the returned rows. Default: 32KB.
+func WithMaxBytes(n int) Option {
+	return func(t *Tool) { t.maxBytes = n }
+}
+
+// WithAllowedTables restricts queries to the named tables, compared
+// case-insensitively and exactly as written: a bare name ("orders") allows
+// only unqualified references, and a qualified name ("public.orders") only
+// references qualified with that schema. List both to allow both forms.
+// CTE names defined in the query are always allowed.
+func WithAllowedTables(tables ...string) Option {
+	return func(t *Tool) {
+		if t.allowed == nil {
+			t.allowed = make(map[string]bool, len(tables))
+		}
+		for _, name := range tables {
+			t.allowed[strings.ToLower(name)] = true
+		}
+	}
+}
+
+// New creates a sql_query tool over db.
+func New(db *dbsql.DB, opts ...Option) *Tool {
+	t := &Tool{
+		db:       db,
+		timeout:  defaultTimeout,
+		maxRows:  defaultMaxRows,
+		maxBytes: defaultMaxBytes,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Definition implements oasis.Tool.
+func (t *Tool) Definition() oasis.ToolMeta {
+	desc := "Run a read-only SQL SELECT query and return the rows as JSON. " +
+		"Only single SELECT statements are allowed. Add WHERE and LIMIT clauses to keep results small."
+	if len(t.allowed) > 0 {
+		names := make([]string, 0, len(t.allowed))
+		for n := range t.allowed {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		desc += " Available tables: " + strings.Join(names, ", ") + "."
+	}
+	return oasis.ToolMeta{Name: "sql_query", Description: desc}
+}
+
+// Cacheable implements oasis.CacheableTool. Query results change as the
+// database does, and statements may write, so they are never cached.
+func (t *Tool) Cacheable() bool { return false }
+
+// Execute implements oasis.Tool. Validation failures and query errors are
+// returned as errors, which the loop surfaces to the model as tool errors.
+func (t *Tool) Execute(ctx context.Context, in QueryInput) (QueryOutput, error) {
+	query, err := t.check(in.Query)
+	if err != nil {
+		return QueryOutput{}, err
+	}
+
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	tx, err := t.db.BeginTx(ctx, &dbsql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return QueryOutput{}, fmt.Errorf("begin read-only transaction: %w", err)
+	}
+	// Why always roll back: drivers that ignore TxOptions.ReadOnly (SQLite)
+	// would otherwise commit anything the lexical check missed.
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return QueryOutput{}, fmt.Errorf("query timed out after %s", t.timeout)
+		}
+		return QueryOutput{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	out, err := t.collect(rows)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return QueryOutput{}, fmt.Errorf("query timed out after %s", t.timeout)
+		}
+		return QueryOutput{}, err
+	}
+	return out, nil
+}
+
+// collect reads rows into QueryOutput, stopping at the row and byte caps.
+func (t *Tool) collect(rows *dbsql.Rows) (QueryOutput, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return QueryOutput{}, fmt.Errorf("read columns: %w", err)
+	}
+	out := QueryOutput{Columns: cols, Rows: []map[string]any{}}
+	size := 0
+	for rows.Next() {
+		if t.maxRows > 0 && len(out.Rows) >= t.maxRows {
+			out.Truncated = true
+			out.Note = fmt.Sprintf("result truncated to the first %d rows; add filters or a LIMIT to see the rest", len(out.Rows))
+			break
+		}
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return QueryOutput{}, fmt.Errorf("scan row: %w", err)
+		}
+		row := make(map[string]any, len(cols))
+		for i, c := range cols {
+			row[c] = jsonValue(vals[i])
+		}
+		b, err := json.Marshal(row)
+		if err != nil {
+			return QueryOutput{}, fmt.Errorf("encode row: %w", err)
+		}
+		if t.maxBytes > 0 && size+len(b) > t.maxBytes {
+			out.Truncated = true
+			out.Note = fmt.Sprintf("result truncated to %d rows (%d byte limit); select fewer columns or rows to see more", len(out.Rows), t.maxBytes)
+			break
+		}
+		size += len(b)
+		out.Rows = append(out.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return QueryOutput{}, fmt.Errorf("read rows: %w", err)
+	}
+	out.RowCount = len(out.Rows)
+	return out, nil
+}
+
+// jsonValue converts driver values to something json.Marshal renders
+// readably: []byte as text rather than base64.
+func jsonValue(v any) any {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+// compile-time check
+var _ oasis.Tool[QueryInput, QueryOutput] = (*Tool)(nil)
@@ -0,0 +1,190 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *dbsql.DB {
+	t.Helper()
+	db, err := dbsql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1) // one connection = one in-memory database
+	t.Cleanup(func() { db.Close() })
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY, customer TEXT, total REAL, note BLOB)`,
+		`CREATE TABLE secrets (k TEXT, v TEXT)`,
+		`INSERT INTO orders (customer, total, note) VALUES ('ada', 10.5, 'first'), ('bob', 20, NULL), ('ada', 3, NULL)`,
+		`INSERT INTO secrets VALUES ('api', 'xyz')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestQueryReturnsRows(t *testing.T) {
+	tool := New(testDB(t))
+	out, err := tool.Execute(context.Background(), QueryInput{Query: "SELECT customer, SUM(total) AS spent FROM orders GROUP BY customer ORDER BY customer;"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Columns) != 2 || out.Columns[1] != "spent" {
+		t.Errorf("Columns = %v", out.Columns)
+	}
+	if out.RowCount != 2 || out.Rows[0]["customer"] != "ada" || out.Rows[0]["spent"] != 13.5 {
+		t.Errorf("Rows = %v", out.Rows)
+	}
+	if out.Truncated {
+		t.Error("unexpected truncation")
+	}
+}
+
+func TestQueryBlobAsText(t *testing.T) {
+	out, err := New(testDB(t)).Execute(context.Background(), QueryInput{Query: "SELECT note FROM orders WHERE id = 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Rows[0]["note"] != "first" {
+		t.Errorf("note = %#v, want text", out.Rows[0]["note"])
+	}
+}
+
+func TestQueryTruncatesRows(t *testing.T) {
+	out, err := New(testDB(t), WithMaxRows(2)).Execute(context.Background(), QueryInput{Query: "SELECT * FROM orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.RowCount != 2 || !out.Truncated || !strings.Contains(out.Note, "first 2 rows") {
+		t.Errorf("out = %+v", out)
+	}
+}
+
+func TestQueryTruncatesBytes(t *testing.T) {
+	out, err := New(testDB(t), WithMaxBytes(80)).Execute(context.Background(), QueryInput{Query: "SELECT * FROM orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Truncated || out.RowCount >= 3 || !strings.Contains(out.Note, "byte limit") {
+		t.Errorf("out = %+v", out)
+	}
+}
+
+func TestQueryRejectsWrites(t *testing.T) {
+	db := testDB(t)
+	tool := New(db)
+	for _, q := range []string{
+		"DELETE FROM orders",
+		"UPDATE orders SET total = 0",
+		"SELECT 1; DROP TABLE orders",
+		"WITH x AS (DELETE FROM orders RETURNING *) SELECT * FROM x",
+		"SELECT * INTO backup FROM orders",
+		"SELECT * FROM orders FOR UPDATE",
+		"PRAGMA table_info(orders)",
+		"",
+	} {
+		if _, err := tool.Execute(context.Background(), QueryInput{Query: q}); err == nil {
+			t.Errorf("%q: expected rejection", q)
+		}
+	}
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM orders").Scan(&n); err != nil || n != 3 {
+		t.Errorf("orders count = %d, %v; writes must not land", n, err)
+	}
+}
+
+func TestQueryAllowsKeywordsInLiteralsAndFunctions(t *testing.T) {
+	tool := New(testDB(t))
+	for _, q := range []string{
+		"SELECT 'DELETE FROM orders' AS s",
+		`SELECT replace(customer, 'a', 'b') AS "update" FROM orders -- drop table`,
+		"/* insert */ SELECT COUNT(*) FROM orders",
+	} {
+		if _, err := tool.Execute(context.Background(), QueryInput{Query: q}); err != nil {
+			t.Errorf("%q: %v", q, err)
+		}
+	}
+
+	// Only whole keyword tokens count; identifiers that contain or equal a
+	// statement word are columns.
+	for _, q := range []string{
+		"SELECT updated_at, created_by, deleted FROM audit",
+		"SELECT id FROM posts WHERE status = 'delete me' /* update */",
+		"SELECT comment, lock, reset, copy FROM tickets",
+		"SELECT p.update, p.insert FROM posts p",
+		"SELECT value FROM settings -- drop",
+	} {
+		if _, err := tool.check(q); err != nil {
+			t.Errorf("%q: %v", q, err)
+		}
+	}
+	for _, q := range []string{
+		"WITH x AS (SELECT 1) DELETE FROM orders",
+		"WITH x AS (SELECT 1) INSERT INTO orders SELECT * FROM x",
+		"SELECT * FROM orders FOR NO KEY UPDATE",
+		"SELECT 1; SET role = admin",
+		"SET role = admin",
+	} {
+		if _, err := tool.check(q); err == nil {
+			t.Errorf("%q: expected rejection", q)
+		}
+	}
+}
+
+func TestCheckAllowedTables(t *testing.T) {
+	tool := New(nil, WithAllowedTables("orders", "public.customers"))
+	allowed := []string{
+		"SELECT * FROM orders",
+		"SELECT * FROM orders o JOIN public.customers c ON c.id = o.cid",
+		"SELECT * FROM (orders JOIN public.customers ON true)",
+		"SELECT * FROM ((orders) CROSS JOIN (public.customers))",
+		"SELECT * FROM (SELECT * FROM orders) o, public.customers",
+		"SELECT * FROM orders WHERE (id, 1) IN (SELECT oid, 1 FROM public.customers)",
+		"SELECT * FROM orders, public.customers",
+		"WITH recent AS (SELECT * FROM orders) SELECT * FROM recent",
+		"SELECT EXTRACT(YEAR FROM created_at) FROM orders",
+		"SELECT * FROM orders WHERE id IN (SELECT oid FROM public.customers)",
+		`SELECT * FROM "Orders"`,
+	}
+	for _, q := range allowed {
+		if _, err := tool.check(q); err != nil {
+			t.Errorf("%q: %v", q, err)
+		}
+	}
+	denied := []string{
+		"SELECT * FROM secrets",
+		"SELECT * FROM orders JOIN secrets ON 1=1",
+		"SELECT * FROM orders, secrets",
+		"SELECT * FROM orders WHERE id IN (SELECT id FROM secrets)",
+		"SELECT * FROM other.customers",
+		"SELECT * FROM main.orders",
+		"SELECT * FROM (secrets)",
+		"SELECT * FROM (orders CROSS JOIN secrets)",
+		"SELECT * FROM (orders JOIN secrets ON true)",
+		"SELECT * FROM orders, (public.customers JOIN secrets ON true)",
+		"SELECT * FROM (SELECT * FROM orders) o JOIN (secrets) s ON true",
+	}
+	for _, q := range denied {
+		if _, err := tool.check(q); err == nil || !strings.Contains(err.Error(), "not in the allowed list") {
+			t.Errorf("%q: err = %v, want allowlist rejection", q, err)
+		}
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	tool := New(testDB(t), WithTimeout(time.Millisecond))
+	// A recursive CTE that runs far longer than the deadline.
+	q := "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n) SELECT COUNT(*) FROM n"
+	_, err := tool.Execute(context.Background(), QueryInput{Query: q})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("err = %v, want timeout", err)
+	}
+}
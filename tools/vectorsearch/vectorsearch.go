@@ -0,0 +1,546 @@
+// Package vectorsearch provides a raw similarity-search tool over a Store's
+// document chunks.
+//
+// The vector_search tool embeds the model's query, runs Store.SearchChunks,
+// and returns ranked chunks with their scores and document sources — no
+// prompt shaping, no answer synthesis:
+//
+//	vs := vectorsearch.New(store, embedding, vectorsearch.WithTopK(8))
+//	agent := oasis.NewAgent("analyst", "...", provider,
+//	    oasis.WithTools(oasis.Erase[vectorsearch.SearchInput, vectorsearch.SearchOutput](vs)),
+//	)
+//
+// Reach for rag.Retriever when you want hybrid search; use this tool when the
+// model should see the raw hits and decide for itself. WithReranker over-fetches
+// and reorders the hits with a rag.Reranker before they are trimmed;
+// WithQueryExpansion has an LLM expand terse queries before they are
+// embedded. With
+// WithGraphExpansion the tool also follows the store's chunk edges (see
+// ingest's graph extraction) from each hit to pull in related chunks.
+package vectorsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	oasis "github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/rag"
+)
+
+const (
+	defaultTopK             = 5
+	defaultMax              = 20
+	defaultRerankCandidates = 50
+	// expansionTTL bounds how long a query's expansion is reused: long
+	// enough to cover the searches of one agent turn.
+	expansionTTL        = 5 * time.Minute
+	maxCachedExpansions = 256
+)
+
+// DefaultExpansionPrompt asks for a hypothetical answer passage (HyDE): a
+// passage embeds closer to the chunks that answer the question than the
+// question itself does.
+const DefaultExpansionPrompt = `Write a short passage that directly answers the search query below, as it might appear in a reference document. Invent plausible specifics if you do not know them; the passage is only used to find similar text. Write a single paragraph with no line breaks and no preamble.`
+
+// SearchInput is the input payload for the vector_search tool.
+type SearchInput struct {
+	Query       string   `json:"query" describe:"Natural-language text to search for"`
+	TopK        int      `json:"top_k,omitempty" describe:"Number of chunks to return (default 5)"`
+	DocumentIDs []string `json:"document_ids,omitempty" describe:"Only search chunks from these document IDs"`
+	Language    string   `json:"language,omitempty" describe:"Only search chunks in this language, as an ISO 639-1 code such as en or id"`
+}
+
+// Hit is one ranked chunk.
+type Hit struct {
+	Text       string  `json:"text"`
+	Score      float32 `json:"score"`
+	ChunkID    string  `json:"chunk_id"`
+	DocumentID string  `json:"document_id"`
+	// Source is the Document.Source of the chunk's document. Empty when the
+	// Store does not implement oasis.DocumentGetter.
+	Source string `json:"source,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// RelatedTo and Relation are set on chunks added by graph expansion:
+	// the ChunkID of the search hit they were reached from and the relation
+	// of the first edge on the way.
+	RelatedTo string `json:"related_to,omitempty"`
+	Relation  string `json:"relation,omitempty"`
+}
+
+// SearchOutput is the result of vector_search: the search hits ordered by
+// Score descending, then any chunks added by graph expansion.
+type SearchOutput struct {
+	Results []Hit `json:"results"`
+}
+
+// maxQuoteBytes caps Source.Quote so citations stay light next to the hits.
+const maxQuoteBytes = 500
+
+// Sources reports one citation per hit, so each search's documents land on
+// AgentResult.Sources: URL and Title from the document, the start of the
+// chunk as Quote, and chunk_id, document_id and score in Meta.
+func (o SearchOutput) Sources() []oasis.Source {
+	if len(o.Results) == 0 {
+		return nil
+	}
+	srcs := make([]oasis.Source, 0, len(o.Results))
+	for _, h := range o.Results {
+		quote := h.Text
+		if len(quote) > maxQuoteBytes {
+			cut := maxQuoteBytes
+			for cut > 0 && !utf8.RuneStart(quote[cut]) {
+				cut--
+			}
+			quote = quote[:cut]
+		}
+		meta, _ := json.Marshal(map[string]any{
+			"chunk_id":    h.ChunkID,
+			"document_id": h.DocumentID,
+			"score":       h.Score,
+		})
+		srcs = append(srcs, oasis.Source{
+			URL:    h.Source,
+			Title:  h.Title,
+			Quote:  quote,
+			Origin: "rag",
+			Meta:   meta,
+		})
+	}
+	return srcs
+}
+
+// Tool runs similarity search over document chunks. It implements
+// oasis.Tool[SearchInput, SearchOutput].
+type Tool struct {
+	store     oasis.Store
+	embedding oasis.EmbeddingProvider
+	topK      int
+	maxTopK   int
+	filters   []oasis.ChunkFilter
+	graphHops int
+	reranker  rag.Reranker
+	// candidates is how many chunks are fetched for the reranker.
+	candidates int
+	expander   oasis.Provider
+	prompt     string
+	expansions oasis.ToolCache
+	logger     *slog.Logger
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithTopK sets the number of chunks returned when the model does not ask
+// for a specific count. Default: 5.
+func WithTopK(n int) Option {
+	return func(t *Tool) { t.topK = n }
+}
+
+// WithMaxTopK caps the top_k the model may request. Default: 20.
+func WithMaxTopK(n int) Option {
+	return func(t *Tool) { t.maxTopK = n }
+}
+
+// WithFilters applies filters to every search, in addition to the model's
+// document_ids — for example oasis.ByMeta("tenant", id) to scope the tool to
+// one tenant's documents.
+func WithFilters(filters ...oasis.ChunkFilter) Option {
+	return func(t *Tool) { t.filters = append(t.filters, filters...) }
+}
+
+// WithGraphExpansion makes the tool follow chunk edges up to hops hops from
+// each search hit, in both directions, and append the chunks it reaches —
+// graph-augmented retrieval over the edges written by ingest's graph
+// extraction. An expanded chunk scores its hit's Score times the weight of
+// the edge that led to it, and at most top_k chunks are added per call.
+// Expanded chunks must pass the same filters as the search (WithFilters,
+// document_ids, language); those that don't are dropped.
+// Stores that do not implement oasis.GraphStore return the plain hits.
+func WithGraphExpansion(hops int) Option {
+	return func(t *Tool) { t.graphHops = hops }
+}
+
+// WithReranker reorders the search hits with r before they are returned:
+// the tool fetches WithRerankCandidates chunks (default 50), reranks them
+// against the query, and keeps the top_k best. If r returns an error the
+// tool logs it and falls back to the vector order. rag.NewLLMReranker scores relevance
+// with an LLM; any rag.Reranker, such as one calling a hosted rerank API,
+// works.
+func WithReranker(r rag.Reranker) Option {
+	return func(t *Tool) { t.reranker = r }
+}
+
+// WithRerankCandidates sets how many chunks are fetched for the reranker
+// to choose from. Ignored without WithReranker, and never less than top_k.
+// Default: 50.
+func WithRerankCandidates(n int) Option {
+	return func(t *Tool) { t.candidates = n }
+}
+
+// WithQueryExpansion has provider expand each query before it is embedded.
+// The expansion's non-empty lines are embedded with the query and the
+// search runs with the average vector, which improves recall for short,
+// terse questions. The default prompt writes a hypothetical answer (HyDE);
+// WithExpansionPrompt can ask for paraphrases instead, one per line. Each
+// query costs one extra LLM call, cached for a few minutes so repeated
+// searches within a turn reuse it. If the call fails the error is logged
+// and the query is embedded alone. Off by default.
+func WithQueryExpansion(provider oasis.Provider) Option {
+	return func(t *Tool) { t.expander = provider }
+}
+
+// WithExpansionPrompt replaces DefaultExpansionPrompt, the system prompt for
+// WithQueryExpansion. The query is sent as the user message; each non-empty
+// line of the reply is embedded.
+func WithExpansionPrompt(prompt string) Option {
+	return func(t *Tool) { t.prompt = prompt }
+}
+
+// WithLogger sets the logger for failures the tool works around, such as a
+// reranker or query expansion error. Default: slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(t *Tool) { t.logger = l }
+}
+
+// New creates a vector_search tool over store's chunks, embedding queries
+// with embedding. Use the same embedding provider the chunks were ingested
+// with.
+func New(store oasis.Store, embedding oasis.EmbeddingProvider, opts ...Option) *Tool {
+	t := &Tool{
+		store:      store,
+		embedding:  embedding,
+		topK:       defaultTopK,
+		maxTopK:    defaultMax,
+		candidates: defaultRerankCandidates,
+		prompt:     DefaultExpansionPrompt,
+		logger:     slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.expander != nil {
+		t.expansions = oasis.NewInMemoryToolCache(oasis.WithToolCacheMaxEntries(maxCachedExpansions))
+	}
+	return t
+}
+
+// Definition implements oasis.Tool.
+func (t *Tool) Definition() oasis.ToolMeta {
+	return oasis.ToolMeta{
+		Name: "vector_search",
+		Description: "Run a semantic similarity search over indexed document chunks. " +
+			"Returns the best-matching chunks with their similarity score and source document. " +
+			"Pass document_ids to restrict the search to specific documents, " +
+			"and language to search only chunks in that language when the index is tagged by language.",
+	}
+}
+
+// CacheScope implements oasis.ScopedCacheTool: tools built with different
+// WithFilters, such as one per tenant, never share cached results.
+func (t *Tool) CacheScope(context.Context) string {
+	if len(t.filters) == 0 {
+		return ""
+	}
+	return fmt.Sprint(t.filters)
+}
+
+// Execute implements oasis.Tool.
+func (t *Tool) Execute(ctx context.Context, in SearchInput) (SearchOutput, error) {
+	if in.Query == "" {
+		return SearchOutput{}, errors.New("query is required")
+	}
+	topK := in.TopK
+	if topK <= 0 {
+		topK = t.topK
+	}
+	if t.maxTopK > 0 && topK > t.maxTopK {
+		topK = t.maxTopK
+	}
+
+	texts := append([]string{in.Query}, t.expand(ctx, in.Query)...)
+	vecs, err := t.embedding.Embed(ctx, texts)
+	if err != nil {
+		return SearchOutput{}, oasis.InfraError(fmt.Errorf("embed query: %w", err))
+	}
+	if len(vecs) == 0 {
+		return SearchOutput{}, oasis.InfraError(errors.New("embed query: provider returned no vectors"))
+	}
+	query := meanVector(vecs)
+
+	filters := t.filters[:len(t.filters):len(t.filters)]
+	if len(in.DocumentIDs) > 0 {
+		filters = append(filters, oasis.ByDocument(in.DocumentIDs...))
+	}
+	if in.Language != "" {
+		filters = append(filters, oasis.ByLanguage(strings.ToLower(in.Language)))
+	}
+	fetch := topK
+	if t.reranker != nil {
+		fetch = max(topK, t.candidates)
+	}
+	chunks, err := t.store.SearchChunks(ctx, query, fetch, filters...)
+	if err != nil {
+		return SearchOutput{}, oasis.InfraError(fmt.Errorf("search chunks: %w", err))
+	}
+
+	out := SearchOutput{Results: make([]Hit, len(chunks))}
+	for i, c := range chunks {
+		out.Results[i] = Hit{Text: c.Content, Score: c.Score, ChunkID: c.ID, DocumentID: c.DocumentID}
+	}
+	if t.reranker != nil {
+		out.Results = t.rerank(ctx, in.Query, out.Results, topK)
+	}
+	if t.graphHops > 0 {
+		out.Results = t.expandGraph(ctx, out.Results, topK, filters)
+	}
+	t.populateSources(ctx, out.Results)
+	return out, nil
+}
+
+// expand returns the query expansion texts, or nil when expansion is off or
+// the LLM call fails (logged): a search without expansion still works.
+func (t *Tool) expand(ctx context.Context, query string) []string {
+	if t.expander == nil {
+		return nil
+	}
+	key := oasis.ToolCacheKey("vector_search.expansion", []byte(query))
+	if res, ok := t.expansions.Get(ctx, key); ok {
+		return strings.Split(res.Content, "\n")
+	}
+	resp, err := oasis.Chat(ctx, t.expander, oasis.ChatRequest{
+		Messages: []oasis.ChatMessage{oasis.SystemMessage(t.prompt), oasis.UserMessage(query)},
+	})
+	if err != nil {
+		t.logger.Warn("vector_search: query expansion failed, searching with the query alone", "error", err)
+		return nil
+	}
+	var texts []string
+	for _, line := range strings.Split(resp.Content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			texts = append(texts, line)
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	t.expansions.Set(ctx, key, oasis.ToolResult{Content: strings.Join(texts, "\n")}, expansionTTL)
+	return texts
+}
+
+// meanVector returns the element-wise mean of vecs.
+func meanVector(vecs [][]float32) []float32 {
+	if len(vecs) == 1 {
+		return vecs[0]
+	}
+	out := make([]float32, len(vecs[0]))
+	for _, v := range vecs {
+		for i := range min(len(v), len(out)) {
+			out[i] += v[i]
+		}
+	}
+	for i := range out {
+		out[i] /= float32(len(vecs))
+	}
+	return out
+}
+
+// rerank reorders hits with the reranker and trims them to topK. On a
+// reranker error, which is logged, the hits keep their vector order.
+func (t *Tool) rerank(ctx context.Context, query string, hits []Hit, topK int) []Hit {
+	results := make([]rag.RetrievalResult, len(hits))
+	for i, h := range hits {
+		results[i] = rag.RetrievalResult{Content: h.Text, Score: h.Score, ChunkID: h.ChunkID, DocumentID: h.DocumentID}
+	}
+	reranked, err := t.reranker.Rerank(ctx, query, results, topK)
+	if err != nil {
+		t.logger.Warn("vector_search: rerank failed, using vector order", "error", err)
+		return hits[:min(len(hits), topK)]
+	}
+	out := make([]Hit, 0, min(len(reranked), topK))
+	for _, r := range reranked[:min(len(reranked), topK)] {
+		out = append(out, Hit{Text: r.Content, Score: r.Score, ChunkID: r.ChunkID, DocumentID: r.DocumentID})
+	}
+	return out
+}
+
+// expandGraph appends up to limit chunks reached over graph edges from hits.
+// Edges cross documents, so each chunk is checked against filters, the ones
+// the search ran with, and dropped when it falls outside them. A failed
+// lookup skips that hit: expansion only ever adds context.
+func (t *Tool) expandGraph(ctx context.Context, hits []Hit, limit int, filters []oasis.ChunkFilter) []Hit {
+	if _, ok := t.store.(oasis.GraphStore); !ok {
+		return hits
+	}
+	seen := make(map[string]bool, len(hits))
+	for _, h := range hits {
+		seen[h.ChunkID] = true
+	}
+	added := 0
+	docs := make(map[string]*oasis.Document)
+	for _, h := range hits { // ranges over the search hits only
+		edges, chunks, err := rag.Neighbors(ctx, t.store, h.ChunkID, t.graphHops)
+		if err != nil {
+			continue
+		}
+		for _, c := range chunks {
+			if added >= limit {
+				return hits
+			}
+			if seen[c.ID] {
+				continue
+			}
+			seen[c.ID] = true
+			if !t.inScope(ctx, c, filters, docs) {
+				continue
+			}
+			hit := Hit{Text: c.Content, ChunkID: c.ID, DocumentID: c.DocumentID, RelatedTo: h.ChunkID}
+			if e, ok := firstEdgeTo(edges, c.ID); ok {
+				hit.Score = h.Score * e.Weight
+				hit.Relation = string(e.Relation)
+			}
+			hits = append(hits, hit)
+			added++
+		}
+	}
+	return hits
+}
+
+// inScope reports whether c passes every filter. Filters on document fields
+// look the document up through oasis.DocumentGetter, caching it in docs; a
+// store without one, or a failed lookup, drops the chunk.
+func (t *Tool) inScope(ctx context.Context, c oasis.Chunk, filters []oasis.ChunkFilter, docs map[string]*oasis.Document) bool {
+	for _, f := range filters {
+		var doc *oasis.Document
+		if f.Field == "source" || f.Field == "created_at" {
+			if doc = t.document(ctx, c.DocumentID, docs); doc == nil {
+				return false
+			}
+		}
+		if !matchFilter(c, doc, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// document returns the document with id, or nil when it cannot be looked up.
+func (t *Tool) document(ctx context.Context, id string, docs map[string]*oasis.Document) *oasis.Document {
+	if d, ok := docs[id]; ok {
+		return d
+	}
+	var doc *oasis.Document
+	if dg, ok := t.store.(oasis.DocumentGetter); ok {
+		if ds, err := dg.GetDocumentsByIDs(ctx, []string{id}); err == nil && len(ds) == 1 {
+			doc = &ds[0]
+		}
+	}
+	docs[id] = doc
+	return doc
+}
+
+// matchFilter reports whether chunk c of document doc passes f, as the
+// stores' SQL filters would. doc is only read for "source" and
+// "created_at". Fields and operators the stores do not support fail closed.
+func matchFilter(c oasis.Chunk, doc *oasis.Document, f oasis.ChunkFilter) bool {
+	if f.Value == nil {
+		return false
+	}
+	want := fmt.Sprint(f.Value.Raw())
+	switch {
+	case f.Field == "document_id":
+		switch f.Op {
+		case oasis.OpIn:
+			ids, _ := f.Value.(oasis.StringsValue)
+			return slices.Contains(ids, c.DocumentID)
+		case oasis.OpEq:
+			return c.DocumentID == want
+		case oasis.OpNeq:
+			return c.DocumentID != want
+		}
+	case f.Field == "source":
+		return f.Op == oasis.OpEq && doc.Source == want
+	case f.Field == "created_at":
+		n, ok := f.Value.(oasis.Int64Value)
+		switch {
+		case ok && f.Op == oasis.OpGt:
+			return doc.CreatedAt > int64(n)
+		case ok && f.Op == oasis.OpLt:
+			return doc.CreatedAt < int64(n)
+		}
+	case strings.HasPrefix(f.Field, "meta."):
+		if f.Op != oasis.OpEq || c.Metadata == nil {
+			return false
+		}
+		raw, err := json.Marshal(c.Metadata)
+		if err != nil {
+			return false
+		}
+		var meta map[string]any
+		if json.Unmarshal(raw, &meta) != nil {
+			return false
+		}
+		v, ok := meta[strings.TrimPrefix(f.Field, "meta.")]
+		return ok && fmt.Sprint(v) == want
+	}
+	return false
+}
+
+// firstEdgeTo returns the first edge in edges touching chunkID — for
+// Neighbors' breadth-first order, the edge the chunk was reached by.
+func firstEdgeTo(edges []oasis.ChunkEdge, chunkID string) (oasis.ChunkEdge, bool) {
+	for _, e := range edges {
+		if e.SourceID == chunkID || e.TargetID == chunkID {
+			return e, true
+		}
+	}
+	return oasis.ChunkEdge{}, false
+}
+
+// populateSources fills Source and Title from the chunks' documents. Stores
+// without oasis.DocumentGetter, or a failed lookup, leave them empty: the
+// hits are still useful without attribution.
+func (t *Tool) populateSources(ctx context.Context, hits []Hit) {
+	dg, ok := t.store.(oasis.DocumentGetter)
+	if !ok || len(hits) == 0 {
+		return
+	}
+	seen := make(map[string]bool)
+	var ids []string
+	for _, h := range hits {
+		if h.DocumentID != "" && !seen[h.DocumentID] {
+			seen[h.DocumentID] = true
+			ids = append(ids, h.DocumentID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	docs, err := dg.GetDocumentsByIDs(ctx, ids)
+	if err != nil {
+		return
+	}
+	byID := make(map[string]oasis.Document, len(docs))
+	for _, d := range docs {
+		byID[d.ID] = d
+	}
+	for i := range hits {
+		if d, ok := byID[hits[i].DocumentID]; ok {
+			hits[i].Source = d.Source
+			hits[i].Title = d.Title
+		}
+	}
+}
+
+// compile-time checks
+var (
+	_ oasis.Tool[SearchInput, SearchOutput] = (*Tool)(nil)
+	_ oasis.Sourced                         = SearchOutput{}
+	_ oasis.ScopedCacheTool                 = (*Tool)(nil)
+)
@@ -0,0 +1,430 @@
+package vectorsearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	oasis "github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/rag"
+)
+
+type fakeEmbedding struct{ err error }
+
+func (f fakeEmbedding) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		out[i] = []float32{1, 0}
+	}
+	return out, nil
+}
+func (fakeEmbedding) Dimensions() int { return 2 }
+func (fakeEmbedding) Name() string    { return "fake" }
+
+// fakeStore records the last SearchChunks call. Methods it does not override
+// panic through the nil embedded Store.
+type fakeStore struct {
+	oasis.Store
+	chunks  []oasis.ScoredChunk
+	docs    []oasis.Document
+	topK    int
+	filters []oasis.ChunkFilter
+	vec     []float32
+}
+
+func (s *fakeStore) SearchChunks(_ context.Context, vec []float32, topK int, filters ...oasis.ChunkFilter) ([]oasis.ScoredChunk, error) {
+	s.vec, s.topK, s.filters = vec, topK, filters
+	return s.chunks, nil
+}
+
+// docStore adds oasis.DocumentGetter.
+type docStore struct{ *fakeStore }
+
+func (s docStore) GetDocumentsByIDs(_ context.Context, ids []string) ([]oasis.Document, error) {
+	var out []oasis.Document
+	for _, d := range s.docs {
+		for _, id := range ids {
+			if d.ID == id {
+				out = append(out, d)
+			}
+		}
+	}
+	return out, nil
+}
+
+func chunk(id, doc, text string, score float32) oasis.ScoredChunk {
+	return oasis.ScoredChunk{Chunk: oasis.Chunk{ID: id, DocumentID: doc, Content: text}, Score: score}
+}
+
+func TestSearchReturnsHitsWithSources(t *testing.T) {
+	fs := &fakeStore{
+		chunks: []oasis.ScoredChunk{chunk("c1", "d1", "alpha", 0.9), chunk("c2", "d2", "beta", 0.7)},
+		docs: []oasis.Document{
+			{ID: "d1", Title: "Handbook", Source: "https://example.com/handbook"},
+			{ID: "d2", Title: "FAQ", Source: "faq.md"},
+		},
+	}
+	tool := New(docStore{fs}, fakeEmbedding{})
+
+	out, err := tool.Execute(context.Background(), SearchInput{Query: "alpha"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Hit{
+		{Text: "alpha", Score: 0.9, ChunkID: "c1", DocumentID: "d1", Source: "https://example.com/handbook", Title: "Handbook"},
+		{Text: "beta", Score: 0.7, ChunkID: "c2", DocumentID: "d2", Source: "faq.md", Title: "FAQ"},
+	}
+	if !reflect.DeepEqual(out.Results, want) {
+		t.Errorf("results = %+v, want %+v", out.Results, want)
+	}
+	if fs.topK != defaultTopK {
+		t.Errorf("topK = %d, want default %d", fs.topK, defaultTopK)
+	}
+	if len(fs.filters) != 0 {
+		t.Errorf("filters = %+v, want none", fs.filters)
+	}
+}
+
+func TestSearchWithoutDocumentGetter(t *testing.T) {
+	fs := &fakeStore{chunks: []oasis.ScoredChunk{chunk("c1", "d1", "alpha", 0.9)}}
+	out, err := New(fs, fakeEmbedding{}).Execute(context.Background(), SearchInput{Query: "alpha"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Results) != 1 || out.Results[0].Source != "" || out.Results[0].DocumentID != "d1" {
+		t.Errorf("results = %+v", out.Results)
+	}
+}
+
+func TestSearchDocumentFilterAndTopK(t *testing.T) {
+	fs := &fakeStore{}
+	base := oasis.ByMeta("tenant", "acme")
+	tool := New(fs, fakeEmbedding{}, WithFilters(base), WithMaxTopK(10))
+
+	if _, err := tool.Execute(context.Background(), SearchInput{Query: "q", TopK: 50, DocumentIDs: []string{"d1", "d2"}, Language: "ID"}); err != nil {
+		t.Fatal(err)
+	}
+	if fs.topK != 10 {
+		t.Errorf("topK = %d, want clamp to 10", fs.topK)
+	}
+	want := []oasis.ChunkFilter{base, oasis.ByDocument("d1", "d2"), oasis.ByLanguage("id")}
+	if !reflect.DeepEqual(fs.filters, want) {
+		t.Errorf("filters = %+v, want %+v", fs.filters, want)
+	}
+
+	// The configured filters must not grow across calls.
+	if _, err := tool.Execute(context.Background(), SearchInput{Query: "q", TopK: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if fs.topK != 3 || !reflect.DeepEqual(fs.filters, []oasis.ChunkFilter{base}) {
+		t.Errorf("second call: topK=%d filters=%+v", fs.topK, fs.filters)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tool := New(&fakeStore{}, fakeEmbedding{})
+	if _, err := tool.Execute(context.Background(), SearchInput{}); err == nil || oasis.IsInfraError(err) {
+		t.Errorf("empty query: err = %v, want plain tool error", err)
+	}
+
+	tool = New(&fakeStore{}, fakeEmbedding{err: errors.New("rate limited")})
+	if _, err := tool.Execute(context.Background(), SearchInput{Query: "q"}); !oasis.IsInfraError(err) {
+		t.Errorf("embed failure: err = %v, want InfraError", err)
+	}
+}
+
+// graphStore adds oasis.GraphStore and chunk lookup over a fixed edge list.
+type graphStore struct {
+	*fakeStore
+	edges []oasis.ChunkEdge
+	all   map[string]oasis.Chunk
+}
+
+func (s graphStore) edgesWhere(ids []string, end func(oasis.ChunkEdge) string) []oasis.ChunkEdge {
+	var out []oasis.ChunkEdge
+	for _, e := range s.edges {
+		for _, id := range ids {
+			if end(e) == id {
+				out = append(out, e)
+			}
+		}
+	}
+	return out
+}
+
+func (s graphStore) GetEdges(_ context.Context, ids []string) ([]oasis.ChunkEdge, error) {
+	return s.edgesWhere(ids, func(e oasis.ChunkEdge) string { return e.SourceID }), nil
+}
+
+func (s graphStore) GetIncomingEdges(_ context.Context, ids []string) ([]oasis.ChunkEdge, error) {
+	return s.edgesWhere(ids, func(e oasis.ChunkEdge) string { return e.TargetID }), nil
+}
+
+func (graphStore) StoreEdges(context.Context, []oasis.ChunkEdge) error { return nil }
+func (graphStore) PruneOrphanEdges(context.Context) (int, error)       { return 0, nil }
+
+func (s graphStore) GetChunksByIDs(_ context.Context, ids []string) ([]oasis.Chunk, error) {
+	var out []oasis.Chunk
+	for _, id := range ids {
+		if c, ok := s.all[id]; ok {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func TestSearchGraphExpansion(t *testing.T) {
+	gs := graphStore{
+		fakeStore: &fakeStore{chunks: []oasis.ScoredChunk{chunk("c1", "d1", "alpha", 0.8), chunk("c2", "d1", "beta", 0.6)}},
+		edges: []oasis.ChunkEdge{
+			{ID: "e1", SourceID: "c1", TargetID: "c3", Relation: oasis.RelReferences, Weight: 0.5},
+			{ID: "e2", SourceID: "c4", TargetID: "c1", Relation: oasis.RelElaborates, Weight: 1},
+			{ID: "e3", SourceID: "c2", TargetID: "c1", Relation: oasis.RelSequence, Weight: 1},
+		},
+		all: map[string]oasis.Chunk{
+			"c3": {ID: "c3", DocumentID: "d2", Content: "gamma"},
+			"c4": {ID: "c4", DocumentID: "d2", Content: "delta"},
+		},
+	}
+
+	out, err := New(gs, fakeEmbedding{}, WithGraphExpansion(1)).Execute(context.Background(), SearchInput{Query: "q"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Hit{
+		{Text: "alpha", Score: 0.8, ChunkID: "c1", DocumentID: "d1"},
+		{Text: "beta", Score: 0.6, ChunkID: "c2", DocumentID: "d1"},
+		{Text: "gamma", Score: 0.4, ChunkID: "c3", DocumentID: "d2", RelatedTo: "c1", Relation: "references"},
+		{Text: "delta", Score: 0.8, ChunkID: "c4", DocumentID: "d2", RelatedTo: "c1", Relation: "elaborates"},
+	}
+	if !reflect.DeepEqual(out.Results, want) {
+		t.Errorf("results = %+v, want %+v", out.Results, want)
+	}
+
+	// Expansion adds at most top_k chunks.
+	out, err = New(gs, fakeEmbedding{}, WithGraphExpansion(1)).Execute(context.Background(), SearchInput{Query: "q", TopK: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Results) != 3 {
+		t.Errorf("results = %d, want 2 hits + 1 expanded", len(out.Results))
+	}
+}
+
+func TestSearchGraphExpansionRespectsFilters(t *testing.T) {
+	hit := chunk("c1", "d1", "alpha", 0.8)
+	hit.Metadata = &oasis.ChunkMeta{Language: "en"}
+	gs := graphStore{
+		fakeStore: &fakeStore{chunks: []oasis.ScoredChunk{hit}},
+		edges: []oasis.ChunkEdge{
+			{ID: "e1", SourceID: "c1", TargetID: "c2", Relation: oasis.RelReferences, Weight: 1},
+			{ID: "e2", SourceID: "c1", TargetID: "c3", Relation: oasis.RelReferences, Weight: 1},
+		},
+		all: map[string]oasis.Chunk{
+			"c2": {ID: "c2", DocumentID: "d1", Content: "beta", Metadata: &oasis.ChunkMeta{Language: "en"}},
+			"c3": {ID: "c3", DocumentID: "d2", Content: "gamma", Metadata: &oasis.ChunkMeta{Language: "de"}},
+		},
+	}
+	ids := func(out SearchOutput) []string {
+		var ids []string
+		for _, h := range out.Results {
+			ids = append(ids, h.ChunkID)
+		}
+		return ids
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		in   SearchInput
+		want []string
+	}{
+		{"tenant boundary", []Option{WithFilters(oasis.ByMeta("tenant", "acme"))}, SearchInput{Query: "q"}, []string{"c1"}},
+		{"document_ids", nil, SearchInput{Query: "q", DocumentIDs: []string{"d1"}}, []string{"c1", "c2"}},
+		{"language", nil, SearchInput{Query: "q", Language: "en"}, []string{"c1", "c2"}},
+		{"unfiltered", nil, SearchInput{Query: "q"}, []string{"c1", "c2", "c3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := New(gs, fakeEmbedding{}, append(tt.opts, WithGraphExpansion(1))...).Execute(context.Background(), tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("results = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// reverseReranker reverses the candidates and rescores them by position,
+// or fails with err.
+type reverseReranker struct {
+	err  error
+	seen int
+}
+
+func (r *reverseReranker) Rerank(_ context.Context, _ string, results []rag.RetrievalResult, topK int) ([]rag.RetrievalResult, error) {
+	r.seen = len(results)
+	if r.err != nil {
+		return nil, r.err
+	}
+	out := make([]rag.RetrievalResult, 0, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		res := results[i]
+		res.Score = float32(i+1) / 10
+		out = append(out, res)
+	}
+	return out[:min(len(out), topK)], nil
+}
+
+func TestSearchReranker(t *testing.T) {
+	fs := &fakeStore{chunks: []oasis.ScoredChunk{
+		chunk("c1", "d1", "alpha", 0.9), chunk("c2", "d1", "beta", 0.8), chunk("c3", "d1", "gamma", 0.7),
+	}}
+	rr := &reverseReranker{}
+	out, err := New(fs, fakeEmbedding{}, WithReranker(rr), WithRerankCandidates(30)).
+		Execute(context.Background(), SearchInput{Query: "q", TopK: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs.topK != 30 || rr.seen != 3 {
+		t.Errorf("fetched %d, reranked %d; want 30 fetched and all 3 hits reranked", fs.topK, rr.seen)
+	}
+	want := []Hit{
+		{Text: "gamma", Score: 0.3, ChunkID: "c3", DocumentID: "d1"},
+		{Text: "beta", Score: 0.2, ChunkID: "c2", DocumentID: "d1"},
+	}
+	if !reflect.DeepEqual(out.Results, want) {
+		t.Errorf("results = %+v, want %+v", out.Results, want)
+	}
+
+	// A failing reranker is logged and falls back to the vector order.
+	var logs bytes.Buffer
+	out, err = New(fs, fakeEmbedding{}, WithReranker(&reverseReranker{err: errors.New("rerank API down")}),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))).
+		Execute(context.Background(), SearchInput{Query: "q", TopK: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs.topK != defaultRerankCandidates || len(out.Results) != 2 || out.Results[0].ChunkID != "c1" || out.Results[1].ChunkID != "c2" {
+		t.Errorf("fetched %d, results = %+v; want %d fetched and c1, c2 in vector order", fs.topK, out.Results, defaultRerankCandidates)
+	}
+	if !strings.Contains(logs.String(), "rerank API down") {
+		t.Errorf("log = %q, want the reranker error", logs.String())
+	}
+}
+
+// lenEmbedding embeds each text as {len(text), 1}.
+type lenEmbedding struct{ fakeEmbedding }
+
+func (lenEmbedding) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i] = []float32{float32(len(t)), 1}
+	}
+	return out, nil
+}
+
+// expansionProvider answers every chat with reply and counts the calls.
+type expansionProvider struct {
+	reply string
+	err   error
+	calls int
+	req   oasis.ChatRequest
+}
+
+func (p *expansionProvider) Chat(_ context.Context, req oasis.ChatRequest) (oasis.ChatResponse, error) {
+	return p.ChatStream(context.Background(), req, nil)
+}
+
+func (p *expansionProvider) ChatStream(_ context.Context, req oasis.ChatRequest, ch chan<- oasis.StreamEvent) (oasis.ChatResponse, error) {
+	if ch != nil {
+		close(ch)
+	}
+	p.calls++
+	p.req = req
+	if p.err != nil {
+		return oasis.ChatResponse{}, p.err
+	}
+	return oasis.ChatResponse{Content: p.reply}, nil
+}
+
+func (*expansionProvider) Name() string { return "fake" }
+
+func TestSearchQueryExpansion(t *testing.T) {
+	fs := &fakeStore{}
+	p := &expansionProvider{reply: "abcdefgh\n\n  abcd  \n"}
+	tool := New(fs, lenEmbedding{}, WithQueryExpansion(p), WithExpansionPrompt("paraphrase"))
+
+	for range 2 {
+		if _, err := tool.Execute(context.Background(), SearchInput{Query: "ab"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The query and both expansion lines are averaged: (2+8+4)/3.
+	if want := []float32{14.0 / 3, 1}; !reflect.DeepEqual(fs.vec, want) {
+		t.Errorf("search vector = %v, want %v", fs.vec, want)
+	}
+	if p.calls != 1 {
+		t.Errorf("LLM calls = %d, want 1 for a repeated query", p.calls)
+	}
+	if p.req.Messages[0].Content != "paraphrase" || p.req.Messages[1].Content != "ab" {
+		t.Errorf("expansion request = %+v, want the custom prompt and the query", p.req.Messages)
+	}
+
+	// A failed expansion is logged and searches with the query alone.
+	var logs bytes.Buffer
+	tool = New(fs, lenEmbedding{}, WithQueryExpansion(&expansionProvider{err: errors.New("overloaded")}),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	if _, err := tool.Execute(context.Background(), SearchInput{Query: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{3, 1}; !reflect.DeepEqual(fs.vec, want) {
+		t.Errorf("search vector = %v, want the plain query vector %v", fs.vec, want)
+	}
+	if !strings.Contains(logs.String(), "overloaded") {
+		t.Errorf("log = %q, want the expansion error", logs.String())
+	}
+}
+
+func TestSearchOutputSources(t *testing.T) {
+	fs := &fakeStore{
+		chunks: []oasis.ScoredChunk{chunk("c1", "d1", "alpha", 0.9)},
+		docs:   []oasis.Document{{ID: "d1", Title: "Handbook", Source: "https://example.com/handbook"}},
+	}
+	tool := oasis.Erase[SearchInput, SearchOutput](New(docStore{fs}, fakeEmbedding{}))
+
+	res, err := tool.ExecuteRaw(context.Background(), []byte(`{"query":"alpha"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Sources) != 1 {
+		t.Fatalf("Sources = %+v, want one per hit", res.Sources)
+	}
+	src := res.Sources[0]
+	if src.URL != "https://example.com/handbook" || src.Title != "Handbook" || src.Quote != "alpha" || src.Origin != "rag" {
+		t.Errorf("source = %+v", src)
+	}
+	if string(src.Meta) != `{"chunk_id":"c1","document_id":"d1","score":0.9}` {
+		t.Errorf("meta = %s", src.Meta)
+	}
+}
+
+func TestCacheScopeFollowsFilters(t *testing.T) {
+	ctx := context.Background()
+	a := New(&fakeStore{}, fakeEmbedding{}, WithFilters(oasis.ByMeta("tenant", "a")))
+	b := New(&fakeStore{}, fakeEmbedding{}, WithFilters(oasis.ByMeta("tenant", "b")))
+	if a.CacheScope(ctx) == b.CacheScope(ctx) {
+		t.Errorf("tenants share cache scope %q", a.CacheScope(ctx))
+	}
+	if s := New(&fakeStore{}, fakeEmbedding{}).CacheScope(ctx); s != "" {
+		t.Errorf("unfiltered scope = %q, want empty", s)
+	}
+}
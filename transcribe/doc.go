@@ -0,0 +1,20 @@
+// Package transcribe provides a processor that turns voice messages into
+// text before the LLM sees them.
+//
+// A Transcriber implements core.PreProcessor. Before each LLM call it finds
+// user messages carrying audio attachments (voice notes from Telegram,
+// Discord and similar chat apps), transcribes each clip, drops the audio,
+// and prepends the transcript to the message text under a "[Voice message
+// transcript]" marker so the model knows the words were spoken. Images,
+// PDFs and other attachments are left untouched.
+//
+//	tr := transcribe.New(gemini.New(key, "gemini-2.5-flash"))
+//	agent := agent.New("assistant", "...", provider,
+//	    agent.WithProcessors(agent.Processors{Pre: []core.PreProcessor{tr}}),
+//	)
+//
+// New transcribes with an audio-capable chat model. NewFunc plugs in any
+// other speech-to-text service. The rewritten message stays in the run's
+// history, so tool-call iterations do not transcribe again. A failed
+// transcription is logged and the audio passes through unchanged.
+package transcribe
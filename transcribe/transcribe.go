@@ -0,0 +1,125 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// transcriptHeader marks text that came from speech rather than typing.
+const transcriptHeader = "[Voice message transcript]\n"
+
+const transcribePrompt = "Transcribe the speech in the attached audio verbatim, in the language it is spoken. " +
+	"Reply with the transcript only. If there is no intelligible speech, reply with an empty message."
+
+// Func transcribes one audio attachment. Use it to plug an external
+// speech-to-text service into NewFunc.
+type Func func(ctx context.Context, audio core.Attachment) (string, error)
+
+// Transcriber is a PreProcessor that replaces audio attachments on user
+// messages with their transcript. Safe for concurrent use.
+type Transcriber struct {
+	fn     Func
+	logger *slog.Logger
+}
+
+// Option configures a Transcriber.
+type Option func(*Transcriber)
+
+// WithLogger sets the logger used for transcription failures.
+func WithLogger(l *slog.Logger) Option {
+	return func(t *Transcriber) { t.logger = l }
+}
+
+// New creates a Transcriber that sends each clip to p, which must accept
+// audio input (for example a Gemini model).
+func New(p core.Provider, opts ...Option) *Transcriber {
+	return NewFunc(func(ctx context.Context, audio core.Attachment) (string, error) {
+		resp, err := core.Chat(ctx, p, core.ChatRequest{Messages: []core.ChatMessage{
+			core.SystemMessage(transcribePrompt),
+			{Role: core.RoleUser, Attachments: []core.Attachment{audio}},
+		}})
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	}, opts...)
+}
+
+// NewFunc creates a Transcriber that transcribes with fn.
+func NewFunc(fn Func, opts ...Option) *Transcriber {
+	t := &Transcriber{fn: fn, logger: slog.New(slog.DiscardHandler)}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// PreLLM transcribes the audio attachments of every user message in req.
+// A message whose audio all transcribes loses the audio and gains the
+// transcripts ahead of its text; on any failure the message is left as-is.
+func (t *Transcriber) PreLLM(ctx context.Context, req *core.ChatRequest) error {
+	for i := range req.Messages {
+		msg := &req.Messages[i]
+		if msg.Role != core.RoleUser || !hasAudio(msg.Attachments) {
+			continue
+		}
+		var (
+			transcripts []string
+			kept        []core.Attachment
+			failed      bool
+		)
+		for _, a := range msg.Attachments {
+			if !isAudio(a) {
+				kept = append(kept, a)
+				continue
+			}
+			text, err := t.fn(ctx, a)
+			if err == nil && strings.TrimSpace(text) == "" {
+				err = errors.New("empty transcript")
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				t.logger.Warn("transcribe: failed, passing audio through", "mime_type", a.MimeType, "error", err)
+				failed = true
+				break
+			}
+			transcripts = append(transcripts, strings.TrimSpace(text))
+		}
+		if failed {
+			continue
+		}
+
+		var b strings.Builder
+		for _, tr := range transcripts {
+			b.WriteString(transcriptHeader)
+			b.WriteString(tr)
+			b.WriteString("\n\n")
+		}
+		b.WriteString(msg.Content)
+		msg.Content = strings.TrimRight(b.String(), "\n")
+		msg.Attachments = kept
+	}
+	return nil
+}
+
+func hasAudio(atts []core.Attachment) bool {
+	for _, a := range atts {
+		if isAudio(a) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAudio(a core.Attachment) bool {
+	return strings.HasPrefix(strings.ToLower(a.MimeType), "audio/")
+}
+
+// compile-time check
+var _ core.PreProcessor = (*Transcriber)(nil)
@@ -0,0 +1,116 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nevindra/oasis/agent"
+	"github.com/nevindra/oasis/core"
+)
+
+var (
+	voice = core.Attachment{MimeType: "audio/ogg", Data: []byte("opus")}
+	photo = core.Attachment{MimeType: "image/jpeg", Data: []byte("jpeg")}
+)
+
+// recordingLLM is the agent's own provider; it records the last user
+// message of every call.
+type recordingLLM struct {
+	mu   sync.Mutex
+	seen []core.ChatMessage
+	tool bool // answer the first call with a tool call
+}
+
+func (r *recordingLLM) Name() string { return "recording" }
+func (r *recordingLLM) ChatStream(_ context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+	if ch != nil {
+		defer close(ch)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == core.RoleUser {
+			r.seen = append(r.seen, req.Messages[i])
+			break
+		}
+	}
+	if r.tool && len(r.seen) == 1 {
+		return core.ChatResponse{ToolCalls: []core.ToolCall{{ID: "1", Name: "clock", Args: []byte(`{}`)}}}, nil
+	}
+	return core.ChatResponse{Content: "ok"}, nil
+}
+
+func run(t *testing.T, tr *Transcriber, llm *recordingLLM, opts ...agent.AgentOption) {
+	t.Helper()
+	opts = append(opts, agent.WithProcessors(agent.Processors{Pre: []core.PreProcessor{tr}}))
+	a := agent.New("a", "test", llm, opts...)
+	task := agent.AgentTask{Input: "what do you think?", Attachments: []core.Attachment{voice, photo}}
+	if _, err := a.Execute(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTranscriberReplacesAudio(t *testing.T) {
+	var calls int
+	tr := NewFunc(func(_ context.Context, a core.Attachment) (string, error) {
+		calls++
+		if a.MimeType != "audio/ogg" {
+			t.Errorf("transcribed %s, want only audio", a.MimeType)
+		}
+		return " I found this bird in the garden. ", nil
+	})
+	llm := &recordingLLM{tool: true}
+	clock := core.Func("clock", "time", func(context.Context, struct{}) (string, error) { return "12:00", nil })
+	run(t, tr, llm, agent.WithTools(clock))
+
+	want := "[Voice message transcript]\nI found this bird in the garden.\n\nwhat do you think?"
+	for i, msg := range llm.seen {
+		if msg.Content != want {
+			t.Errorf("call %d: content = %q, want %q", i, msg.Content, want)
+		}
+		if len(msg.Attachments) != 1 || msg.Attachments[0].MimeType != "image/jpeg" {
+			t.Errorf("call %d: attachments = %+v, want only the photo", i, msg.Attachments)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("transcriptions = %d, want 1 across tool iterations", calls)
+	}
+}
+
+func TestTranscriberFailsOpen(t *testing.T) {
+	tr := NewFunc(func(context.Context, core.Attachment) (string, error) { return "", errors.New("stt down") })
+	llm := &recordingLLM{}
+	run(t, tr, llm)
+
+	if msg := llm.seen[0]; msg.Content != "what do you think?" || len(msg.Attachments) != 2 {
+		t.Errorf("message = %+v, want input and attachments unchanged", msg)
+	}
+}
+
+// sttModel is an audio-capable chat model that "hears" a fixed transcript.
+type sttModel struct{ got []core.ChatMessage }
+
+func (s *sttModel) Name() string { return "stt" }
+func (s *sttModel) ChatStream(_ context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+	if ch != nil {
+		defer close(ch)
+	}
+	s.got = req.Messages
+	return core.ChatResponse{Content: "hello there"}, nil
+}
+
+func TestNewUsesChatModel(t *testing.T) {
+	stt := &sttModel{}
+	req := core.ChatRequest{Messages: []core.ChatMessage{{Role: core.RoleUser, Attachments: []core.Attachment{voice}}}}
+	if err := New(stt).PreLLM(context.Background(), &req); err != nil {
+		t.Fatal(err)
+	}
+	if len(stt.got) != 2 || len(stt.got[1].Attachments) != 1 || stt.got[1].Attachments[0].MimeType != "audio/ogg" {
+		t.Fatalf("model request = %+v, want prompt plus the audio", stt.got)
+	}
+	if msg := req.Messages[0]; msg.Content != "[Voice message transcript]\nhello there" || len(msg.Attachments) != 0 {
+		t.Errorf("message = %+v, want the transcript and no audio", msg)
+	}
+}
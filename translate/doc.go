@@ -0,0 +1,26 @@
+// Package translate provides a processor pair that lets an agent work in
+// one language while talking to users in theirs.
+//
+// A Translator implements core.PreProcessor and core.PostProcessor. Before
+// each LLM call it detects the language of the latest user message and, if
+// it differs from the working language, rewrites the message in place. After
+// the final response (one with no tool calls) it translates the content back
+// to the detected language. Detection and translation share one LLM call;
+// messages already in the working language cost that call and nothing more.
+//
+//	tr := translate.New(translatorProvider, "en")
+//	agent := agent.New("support", "...", provider,
+//	    agent.WithProcessors(agent.Processors{
+//	        Pre:  []core.PreProcessor{tr},
+//	        Post: []core.PostProcessor{tr},
+//	    }),
+//	)
+//
+// The detected language is remembered for the rest of the run, so tool-call
+// iterations do not detect again. Translation failures are logged and the
+// text passes through unchanged: a missed translation is preferable to a
+// failed turn.
+//
+// Streamed text deltas are emitted before PostLLM runs and therefore arrive
+// in the working language; AgentResult.Output carries the translation.
+package translate
@@ -0,0 +1,194 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// maxTracked bounds the per-run and memo maps. Entries are tiny, but a
+// run that fails between PreLLM and PostLLM never clears its slot, so the
+// maps are reset wholesale when they reach this size.
+const maxTracked = 4096
+
+// Translator is a PreProcessor + PostProcessor that translates user input
+// into a working language and the final answer back. Safe for concurrent use.
+type Translator struct {
+	provider core.Provider
+	target   string
+	logger   *slog.Logger
+
+	mu sync.Mutex
+	// pending hands the detected language from PreLLM to PostLLM. Both hooks
+	// receive the same iteration context, and every run derives its own.
+	pending map[context.Context]string
+	// known maps text already in the working language (translated by us, or
+	// detected as such) to the user's language, so later iterations of a run
+	// see the rewritten message and skip detection.
+	known map[string]string
+}
+
+// Option configures a Translator.
+type Option func(*Translator)
+
+// WithLogger sets the logger used for translation failures.
+func WithLogger(l *slog.Logger) Option {
+	return func(t *Translator) { t.logger = l }
+}
+
+// New creates a Translator that uses p for detection and translation and
+// works in target, a language code or name the model understands (e.g. "en").
+func New(p core.Provider, target string, opts ...Option) *Translator {
+	t := &Translator{
+		provider: p,
+		target:   target,
+		logger:   slog.New(slog.DiscardHandler),
+		pending:  make(map[context.Context]string),
+		known:    make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// detection is the JSON the detect-and-translate call returns.
+type detection struct {
+	Language    string `json:"language"`
+	Translation string `json:"translation"`
+}
+
+var detectionSchema = core.NewResponseSchema("translation", &core.SchemaObject{
+	Type: "object",
+	Properties: map[string]*core.SchemaObject{
+		"language":    {Type: "string", Description: "ISO 639-1 code of the input language"},
+		"translation": {Type: "string", Description: "The input translated to the target language; empty if already in it"},
+	},
+	Required: []string{"language", "translation"},
+})
+
+// PreLLM detects the language of the last user message and rewrites it in
+// the working language when they differ.
+func (t *Translator) PreLLM(ctx context.Context, req *core.ChatRequest) error {
+	idx := -1
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == core.RoleUser {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 || strings.TrimSpace(req.Messages[idx].Content) == "" {
+		return nil
+	}
+	content := req.Messages[idx].Content
+
+	t.mu.Lock()
+	lang, ok := t.known[content]
+	t.mu.Unlock()
+	if !ok {
+		d, err := t.detect(ctx, content)
+		if err != nil {
+			t.logger.Warn("translate: detection failed, passing input through", "error", err)
+			return nil
+		}
+		lang = d.Language
+		if !t.sameLanguage(lang) && d.Translation != "" {
+			content = d.Translation
+			req.Messages[idx].Content = content
+		}
+		t.mu.Lock()
+		setBounded(t.known, content, lang)
+		t.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	setBounded(t.pending, ctx, lang)
+	t.mu.Unlock()
+	return nil
+}
+
+// PostLLM translates the final response back to the user's language.
+// Responses that carry tool calls are intermediate and left as-is.
+func (t *Translator) PostLLM(ctx context.Context, resp *core.ChatResponse) error {
+	t.mu.Lock()
+	lang, ok := t.pending[ctx]
+	delete(t.pending, ctx)
+	t.mu.Unlock()
+	if !ok || t.sameLanguage(lang) || len(resp.ToolCalls) > 0 || strings.TrimSpace(resp.Content) == "" {
+		return nil
+	}
+
+	out, err := core.Chat(ctx, t.provider, core.ChatRequest{Messages: []core.ChatMessage{
+		core.SystemMessage(fmt.Sprintf(
+			"Translate the user's text to the language with code %q. Preserve formatting, "+
+				"markdown, code blocks, URLs, and names. Reply with the translation only.", lang)),
+		core.UserMessage(resp.Content),
+	}})
+	if err != nil {
+		t.logger.Warn("translate: response translation failed, passing output through", "error", err)
+		return nil
+	}
+	resp.Content = strings.TrimSpace(out.Content)
+	return nil
+}
+
+// detect asks the model for the language of text and, when it is not the
+// working language, a translation into it.
+func (t *Translator) detect(ctx context.Context, text string) (detection, error) {
+	resp, err := core.Chat(ctx, t.provider, core.ChatRequest{
+		Messages: []core.ChatMessage{
+			core.SystemMessage(fmt.Sprintf(
+				"Detect the language of the user's text. If it is not %q, translate it to %q, "+
+					"preserving formatting, code, URLs, and names. Reply with JSON only: "+
+					`{"language":"<ISO 639-1 code>","translation":"<translated text, or empty if already %s>"}`,
+				t.target, t.target, t.target)),
+			core.UserMessage(text),
+		},
+		ResponseSchema: detectionSchema,
+	})
+	if err != nil {
+		return detection{}, err
+	}
+	var d detection
+	if err := json.Unmarshal([]byte(extractJSON(resp.Content)), &d); err != nil {
+		return detection{}, fmt.Errorf("parse detection: %w", err)
+	}
+	if d.Language == "" {
+		return detection{}, fmt.Errorf("parse detection: empty language")
+	}
+	return d, nil
+}
+
+func (t *Translator) sameLanguage(lang string) bool {
+	return strings.EqualFold(strings.TrimSpace(lang), t.target)
+}
+
+// setBounded stores k=v, resetting m first when it is full. Caller holds t.mu.
+func setBounded[K comparable](m map[K]string, k K, v string) {
+	if len(m) >= maxTracked {
+		clear(m)
+	}
+	m[k] = v
+}
+
+// extractJSON strips markdown code fences some models wrap JSON in.
+func extractJSON(s string) string {
+	s = strings.TrimSpace(s)
+	if start := strings.IndexByte(s, '{'); start >= 0 {
+		if end := strings.LastIndexByte(s, '}'); end > start {
+			return s[start : end+1]
+		}
+	}
+	return s
+}
+
+// compile-time checks
+var (
+	_ core.PreProcessor  = (*Translator)(nil)
+	_ core.PostProcessor = (*Translator)(nil)
+)
@@ -0,0 +1,145 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nevindra/oasis/agent"
+	"github.com/nevindra/oasis/core"
+)
+
+// fakeTranslator answers detection calls from a fixed table and translation
+// calls by tagging the text with the requested language.
+type fakeTranslator struct {
+	mu      sync.Mutex
+	detects int
+	err     error
+}
+
+func (f *fakeTranslator) Name() string { return "fake-translator" }
+func (f *fakeTranslator) ChatStream(_ context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+	if ch != nil {
+		defer close(ch)
+	}
+	if f.err != nil {
+		return core.ChatResponse{}, f.err
+	}
+	text := req.Messages[1].Content
+	if req.ResponseSchema != nil {
+		f.mu.Lock()
+		f.detects++
+		f.mu.Unlock()
+		switch text {
+		case "hola, ¿qué hora es?":
+			return core.ChatResponse{Content: "```json\n{\"language\":\"es\",\"translation\":\"hello, what time is it?\"}\n```"}, nil
+		default:
+			return core.ChatResponse{Content: `{"language":"en","translation":""}`}, nil
+		}
+	}
+	lang := req.Messages[0].Content[strings.Index(req.Messages[0].Content, `"`)+1:]
+	lang = lang[:strings.Index(lang, `"`)]
+	return core.ChatResponse{Content: "[" + lang + "] " + text}, nil
+}
+
+// echoLLM is the agent's own provider; it records the user message it saw.
+type echoLLM struct {
+	mu   sync.Mutex
+	seen []string
+	tool bool // answer the first call with a tool call
+}
+
+func (e *echoLLM) Name() string { return "echo" }
+func (e *echoLLM) ChatStream(_ context.Context, req core.ChatRequest, ch chan<- core.StreamEvent) (core.ChatResponse, error) {
+	if ch != nil {
+		defer close(ch)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == core.RoleUser {
+			e.seen = append(e.seen, req.Messages[i].Content)
+			break
+		}
+	}
+	if e.tool && len(e.seen) == 1 {
+		return core.ChatResponse{ToolCalls: []core.ToolCall{{ID: "1", Name: "clock", Args: []byte(`{}`)}}}, nil
+	}
+	return core.ChatResponse{Content: "it is noon"}, nil
+}
+
+func newAgent(llm core.Provider, tr *Translator, opts ...agent.AgentOption) *agent.LLMAgent {
+	opts = append(opts, agent.WithProcessors(agent.Processors{
+		Pre:  []core.PreProcessor{tr},
+		Post: []core.PostProcessor{tr},
+	}))
+	return agent.New("a", "test", llm, opts...)
+}
+
+func TestTranslatorRoundTrip(t *testing.T) {
+	ft := &fakeTranslator{}
+	llm := &echoLLM{}
+	a := newAgent(llm, New(ft, "en"))
+
+	res, err := a.Execute(context.Background(), agent.AgentTask{Input: "hola, ¿qué hora es?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if llm.seen[0] != "hello, what time is it?" {
+		t.Errorf("agent saw %q, want the English translation", llm.seen[0])
+	}
+	if res.Output != "[es] it is noon" {
+		t.Errorf("Output = %q, want translation back to es", res.Output)
+	}
+}
+
+func TestTranslatorSkipsWorkingLanguage(t *testing.T) {
+	ft := &fakeTranslator{}
+	llm := &echoLLM{}
+	a := newAgent(llm, New(ft, "en"))
+
+	res, err := a.Execute(context.Background(), agent.AgentTask{Input: "what time is it?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Output != "it is noon" {
+		t.Errorf("Output = %q, want untranslated", res.Output)
+	}
+	if ft.detects != 1 {
+		t.Errorf("detect calls = %d, want 1", ft.detects)
+	}
+}
+
+func TestTranslatorDetectsOncePerRun(t *testing.T) {
+	ft := &fakeTranslator{}
+	llm := &echoLLM{tool: true}
+	clock := core.Func("clock", "time", func(context.Context, struct{}) (string, error) { return "12:00", nil })
+	a := newAgent(llm, New(ft, "en"), agent.WithTools(clock))
+
+	res, err := a.Execute(context.Background(), agent.AgentTask{Input: "hola, ¿qué hora es?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft.detects != 1 {
+		t.Errorf("detect calls = %d, want 1 across tool iterations", ft.detects)
+	}
+	if res.Output != "[es] it is noon" {
+		t.Errorf("Output = %q", res.Output)
+	}
+}
+
+func TestTranslatorFailsOpen(t *testing.T) {
+	ft := &fakeTranslator{err: errors.New("down")}
+	llm := &echoLLM{}
+	a := newAgent(llm, New(ft, "en"))
+
+	res, err := a.Execute(context.Background(), agent.AgentTask{Input: "hola, ¿qué hora es?"})
+	if err != nil {
+		t.Fatalf("translation failure should not fail the run: %v", err)
+	}
+	if llm.seen[0] != "hola, ¿qué hora es?" || res.Output != "it is noon" {
+		t.Errorf("seen %q, output %q; want passthrough", llm.seen[0], res.Output)
+	}
+}
@@ -0,0 +1,301 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+)
+
+// ConfigStore is the subset of core.Store that workflow checkpoints persist
+// to. Any core.Store satisfies it.
+type ConfigStore interface {
+	GetConfig(ctx context.Context, key string) (string, error)
+	SetConfig(ctx context.Context, key, value string) error
+}
+
+// ErrRunNotFound is returned by Workflow.Resume when no checkpoint exists
+// for the run ID — it was never saved, already resumed to completion, or
+// belongs to another store.
+var ErrRunNotFound = errors.New("workflow: no suspended run with that ID")
+
+// checkpointKeyPrefix namespaces workflow checkpoints in the config table.
+const checkpointKeyPrefix = "workflow_run:"
+
+// wakeActionPrefix starts the ID of the ScheduledAction that resumes a run
+// suspended until a time; the rest is the run ID.
+const wakeActionPrefix = "workflow_wake:"
+
+// wakeCall is the single entry of a wake-up's ToolCalls. Nothing executes it
+// as a tool: it names the run for ResumeDue, and for schedulers that list
+// actions.
+type wakeCall struct {
+	Tool   string `json:"tool"`
+	Params struct {
+		Workflow string `json:"workflow"`
+		RunID    string `json:"run_id"`
+	} `json:"params"`
+}
+
+// WithWorkflowStore makes suspensions durable. When a step suspends, the
+// run's task, completed step results, suspend payload and WorkflowContext
+// values are saved to store under a run ID (ErrSuspended.RunID), and
+// Workflow.Resume can continue the run after a process restart. The
+// checkpoint is cleared once a resumed run finishes or fails.
+//
+// Only JSON-serializable context values survive a checkpoint, and they come
+// back as their JSON decoding: strings stay strings, numbers become float64,
+// structs become map[string]any. Values that cannot be marshalled —
+// functions, channels, open connections — are dropped, so steps after the
+// suspension must not depend on them.
+func WithWorkflowStore(store ConfigStore) WorkflowOption {
+	return func(c *workflowConfig) { c.store = store }
+}
+
+// runCheckpoint is the persisted form of a suspended run.
+type runCheckpoint struct {
+	Workflow string                     `json:"workflow"`
+	Step     string                     `json:"step"`
+	Payload  json.RawMessage            `json:"payload,omitempty"`
+	Task     core.AgentTask             `json:"task"`
+	Results  []checkpointStep           `json:"results,omitempty"`
+	Values   map[string]json.RawMessage `json:"values,omitempty"`
+	Usage    core.Usage                 `json:"usage"`
+	SavedAt  int64                      `json:"saved_at"`
+}
+
+// checkpointStep is a completed StepResult. Only successful and
+// condition-skipped steps are checkpointed, so there is no Error to keep.
+type checkpointStep struct {
+	Name     string        `json:"name"`
+	Status   StepStatus    `json:"status"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// saveCheckpoint persists a suspended run and returns its run ID: runID
+// when resuming an already-stored run, a new ID otherwise. Returns "" when
+// no store is configured or the save fails; the in-memory Resume still
+// works either way.
+func (w *Workflow) saveCheckpoint(ctx context.Context, runID string, task core.AgentTask, step string, payload json.RawMessage, results map[string]StepResult, values map[string]any) string {
+	if w.store == nil {
+		return ""
+	}
+	if runID == "" {
+		runID = core.NewID()
+	}
+	cp := runCheckpoint{
+		Workflow: w.name,
+		Step:     step,
+		Payload:  payload,
+		Task:     task,
+		Values:   make(map[string]json.RawMessage, len(values)),
+		SavedAt:  time.Now().Unix(),
+	}
+	for _, r := range results {
+		cp.Results = append(cp.Results, checkpointStep{Name: r.Name, Status: r.Status, Output: r.Output, Duration: r.Duration})
+	}
+	sort.Slice(cp.Results, func(i, j int) bool { return cp.Results[i].Name < cp.Results[j].Name })
+	var dropped []string
+	for k, v := range values {
+		switch k {
+		case resumeDataKey:
+			continue
+		case usageKey:
+			cp.Usage, _ = v.(core.Usage)
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			dropped = append(dropped, k)
+			continue
+		}
+		cp.Values[k] = raw
+	}
+	if len(dropped) > 0 {
+		sort.Strings(dropped)
+		w.logger.Warn("workflow checkpoint dropped non-serializable context values", "workflow", w.name, "run_id", runID, "keys", dropped)
+	}
+
+	data, err := json.Marshal(cp)
+	if err == nil {
+		// Why WithoutCancel: the run is suspending, not failing — a caller
+		// deadline that fires now must not lose the checkpoint.
+		err = w.store.SetConfig(context.WithoutCancel(ctx), checkpointKeyPrefix+runID, string(data))
+	}
+	if err != nil {
+		w.logger.Error("workflow checkpoint save failed", "workflow", w.name, "run_id", runID, "error", err)
+		return ""
+	}
+	return runID
+}
+
+// clearCheckpoint removes a run's checkpoint, deleting the key when the
+// store implements core.ConfigLister. Otherwise an empty value marks the
+// run as gone.
+func (w *Workflow) clearCheckpoint(ctx context.Context, runID string) {
+	if w.store == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	key := checkpointKeyPrefix + runID
+	var err error
+	if cl, ok := w.store.(core.ConfigLister); ok {
+		err = cl.DeleteConfig(ctx, key)
+	} else {
+		err = w.store.SetConfig(ctx, key, "")
+	}
+	if err != nil {
+		w.logger.Error("workflow checkpoint clear failed", "workflow", w.name, "run_id", runID, "error", err)
+	}
+	if sas, ok := w.store.(core.ScheduledActionStore); ok {
+		if err := sas.DeleteScheduledAction(ctx, wakeActionPrefix+runID); err != nil {
+			w.logger.Error("workflow wake-up delete failed", "workflow", w.name, "run_id", runID, "error", err)
+		}
+	}
+}
+
+// scheduleWake registers a one-shot ScheduledAction due at wake for a run
+// suspended until then, when the store supports scheduled actions. It
+// replaces the wake-up of an earlier suspension of the same run.
+func (w *Workflow) scheduleWake(ctx context.Context, runID string, wake time.Time) {
+	sas, ok := w.store.(core.ScheduledActionStore)
+	if !ok {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	id := wakeActionPrefix + runID
+	if err := sas.DeleteScheduledAction(ctx, id); err != nil {
+		w.logger.Error("workflow wake-up delete failed", "workflow", w.name, "run_id", runID, "error", err)
+		return
+	}
+	call := wakeCall{Tool: "workflow_resume"}
+	call.Params.Workflow, call.Params.RunID = w.name, runID
+	calls, _ := json.Marshal([]wakeCall{call})
+	err := sas.CreateScheduledAction(ctx, core.ScheduledAction{
+		ID:          id,
+		Description: fmt.Sprintf("resume workflow %q run %s", w.name, runID),
+		Schedule:    "once",
+		ToolCalls:   string(calls),
+		NextRun:     wake.Unix(),
+		Enabled:     true,
+		CreatedAt:   time.Now().Unix(),
+	})
+	if err != nil {
+		w.logger.Error("workflow wake-up schedule failed", "workflow", w.name, "run_id", runID, "error", err)
+	}
+}
+
+// Resume continues a run suspended under WithWorkflowStore, identified by
+// ErrSuspended.RunID, with the human's response data. It works in any
+// process that builds the same workflow over the same store: completed
+// steps are not re-run, and the suspended step runs again with data
+// available under the resume-data key.
+//
+// The run may suspend again (returning a new *ErrSuspended with the same
+// RunID). Resuming the same run ID twice concurrently runs it twice; callers
+// that may race should serialize on the run ID.
+func (w *Workflow) Resume(ctx context.Context, runID string, data json.RawMessage) (core.AgentResult, error) {
+	if w.store == nil {
+		return core.AgentResult{}, errors.New("workflow: Resume requires WithWorkflowStore")
+	}
+	raw, err := w.store.GetConfig(ctx, checkpointKeyPrefix+runID)
+	if err != nil {
+		return core.AgentResult{}, fmt.Errorf("workflow: load checkpoint %s: %w", runID, err)
+	}
+	if raw == "" {
+		return core.AgentResult{}, ErrRunNotFound
+	}
+	var cp runCheckpoint
+	if err := json.Unmarshal([]byte(raw), &cp); err != nil {
+		return core.AgentResult{}, fmt.Errorf("workflow: decode checkpoint %s: %w", runID, err)
+	}
+	if cp.Workflow != w.name {
+		return core.AgentResult{}, fmt.Errorf("workflow: run %s belongs to workflow %q, not %q", runID, cp.Workflow, w.name)
+	}
+
+	results := make(map[string]StepResult, len(cp.Results))
+	for _, r := range cp.Results {
+		if _, ok := w.steps[r.Name]; !ok {
+			return core.AgentResult{}, fmt.Errorf("workflow: run %s completed step %q, which this workflow does not define", runID, r.Name)
+		}
+		results[r.Name] = StepResult{Name: r.Name, Status: r.Status, Output: r.Output, Duration: r.Duration}
+	}
+	values := make(map[string]any, len(cp.Values)+1)
+	for k, v := range cp.Values {
+		var decoded any
+		if err := json.Unmarshal(v, &decoded); err != nil {
+			return core.AgentResult{}, fmt.Errorf("workflow: decode checkpoint value %q: %w", k, err)
+		}
+		values[k] = decoded
+	}
+	values[usageKey] = cp.Usage
+
+	w.logger.Info("resuming workflow from checkpoint", "workflow", w.name, "run_id", runID, "step", cp.Step)
+	return w.executeResume(ctx, cp.Task, runID, results, values, data, nil)
+}
+
+// ResumeDue resumes this workflow's runs whose Sleep or SuspendUntil wake
+// time has passed, using the wake-ups saved to a WithWorkflowStore store
+// that implements core.ScheduledActionStore. Call it periodically, e.g.
+// from the loop that runs your other scheduled actions; wake-ups have IDs
+// starting with "workflow_wake:" and should be left to ResumeDue. With a
+// core.ScheduledActionClaimer only one replica resumes each run.
+//
+// It returns how many runs it resumed. A run that fails or suspends again
+// still counts; its error is logged rather than returned.
+func (w *Workflow) ResumeDue(ctx context.Context) (int, error) {
+	sas, ok := w.store.(core.ScheduledActionStore)
+	if !ok {
+		return 0, errors.New("workflow: ResumeDue requires a WithWorkflowStore store implementing core.ScheduledActionStore")
+	}
+	now := time.Now().Unix()
+	due, err := sas.GetDueScheduledActions(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("workflow: list due wake-ups: %w", err)
+	}
+	claimer, _ := sas.(core.ScheduledActionClaimer)
+	resumed := 0
+	for _, a := range due {
+		runID, ok := strings.CutPrefix(a.ID, wakeActionPrefix)
+		if !ok {
+			continue
+		}
+		var calls []wakeCall
+		if json.Unmarshal([]byte(a.ToolCalls), &calls) != nil || len(calls) != 1 || calls[0].Params.Workflow != w.name {
+			continue
+		}
+		if claimer != nil {
+			// Why an hour ahead: if the delete below fails, the wake-up
+			// fires again later instead of on every poll.
+			won, err := claimer.ClaimScheduledAction(ctx, a.ID, a.NextRun, now+3600, now)
+			if err != nil {
+				return resumed, fmt.Errorf("workflow: claim wake-up %s: %w", a.ID, err)
+			}
+			if !won {
+				continue
+			}
+		}
+		// Delete before resuming: a run that suspends again registers a
+		// new wake-up under the same ID.
+		if err := sas.DeleteScheduledAction(ctx, a.ID); err != nil {
+			w.logger.Error("workflow wake-up delete failed", "workflow", w.name, "run_id", runID, "error", err)
+		}
+		_, err := w.Resume(ctx, runID, nil)
+		var suspended *ErrSuspended
+		switch {
+		case errors.Is(err, ErrRunNotFound):
+			// Resumed by other means in the meantime.
+			continue
+		case err != nil && !errors.As(err, &suspended):
+			w.logger.Error("workflow resume at wake time failed", "workflow", w.name, "run_id", runID, "error", err)
+		}
+		resumed++
+	}
+	return resumed, nil
+}
@@ -0,0 +1,338 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nevindra/oasis/core"
+	"github.com/nevindra/oasis/store/sqlite"
+)
+
+// memConfigStore is an in-memory ConfigStore.
+type memConfigStore struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func (s *memConfigStore) GetConfig(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *memConfigStore) SetConfig(_ context.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		s.data = make(map[string]string)
+	}
+	s.data[key] = value
+	return nil
+}
+
+// listerConfigStore is a memConfigStore that can also delete keys, like
+// the SQLite and Postgres stores.
+type listerConfigStore struct{ memConfigStore }
+
+func (s *listerConfigStore) ListConfig(_ context.Context, prefix string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[string]string{}
+	for k, v := range s.data {
+		if strings.HasPrefix(k, prefix) {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+func (s *listerConfigStore) DeleteConfig(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+// approvalWorkflow fetches, waits for approval, then publishes. fetchRuns
+// counts executions of the first step.
+func approvalWorkflow(t *testing.T, store ConfigStore, fetchRuns *int) *Workflow {
+	t.Helper()
+	wf, err := New("approval", "approval flow",
+		WithWorkflowStore(store),
+		Step("fetch", func(_ context.Context, wCtx *WorkflowContext) error {
+			*fetchRuns++
+			wCtx.Set("fetch.output", "draft for "+wCtx.Input())
+			wCtx.Set("fetch.count", 3)
+			wCtx.Set("fetch.callback", func() {}) // not serializable
+			return nil
+		}),
+		Step("approve", func(_ context.Context, wCtx *WorkflowContext) error {
+			data, ok := ResumeData(wCtx)
+			if !ok {
+				return Suspend(json.RawMessage(`{"ask":"publish?"}`))
+			}
+			wCtx.Set("approve.output", string(data))
+			return nil
+		}, After("fetch")),
+		Step("publish", func(_ context.Context, wCtx *WorkflowContext) error {
+			draft, _ := wCtx.Get("fetch.output")
+			count, _ := wCtx.Get("fetch.count")
+			approval, _ := wCtx.Get("approve.output")
+			_, hasCallback := wCtx.Get("fetch.callback")
+			wCtx.Set("publish.output", strings.Join([]string{
+				draft.(string), approval.(string), jsonString(count), jsonString(hasCallback),
+			}, "|"))
+			return nil
+		}, After("approve")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wf
+}
+
+func jsonString(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestWorkflowResumeFromStore(t *testing.T) {
+	store := &memConfigStore{}
+	var fetchRuns int
+
+	_, err := approvalWorkflow(t, store, &fetchRuns).Execute(context.Background(), core.AgentTask{Input: "q3 report", ThreadID: "t1"})
+	var suspended *ErrSuspended
+	if !errors.As(err, &suspended) {
+		t.Fatalf("expected *ErrSuspended, got %v", err)
+	}
+	if suspended.RunID == "" {
+		t.Fatal("RunID is empty with a store configured")
+	}
+
+	// A fresh Workflow over the same store stands in for a restarted process.
+	restarted := approvalWorkflow(t, store, &fetchRuns)
+	result, err := restarted.Resume(context.Background(), suspended.RunID, json.RawMessage(`"yes"`))
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if want := `draft for q3 report|"yes"|3|false`; result.Output != want {
+		t.Errorf("Output = %q, want %q", result.Output, want)
+	}
+	if fetchRuns != 1 {
+		t.Errorf("fetch ran %d times, want 1", fetchRuns)
+	}
+
+	if _, err := restarted.Resume(context.Background(), suspended.RunID, nil); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("second Resume: err = %v, want ErrRunNotFound", err)
+	}
+}
+
+func TestWorkflowResumeDeletesCheckpoint(t *testing.T) {
+	store := &listerConfigStore{}
+	var fetchRuns int
+	wf := approvalWorkflow(t, store, &fetchRuns)
+	_, err := wf.Execute(context.Background(), core.AgentTask{Input: "q3 report"})
+	var suspended *ErrSuspended
+	if !errors.As(err, &suspended) {
+		t.Fatalf("expected *ErrSuspended, got %v", err)
+	}
+	if _, err := wf.Resume(context.Background(), suspended.RunID, json.RawMessage(`"yes"`)); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if left, _ := store.ListConfig(context.Background(), checkpointKeyPrefix); len(left) != 0 {
+		t.Errorf("checkpoint keys left after the run finished: %v", left)
+	}
+}
+
+func TestWorkflowResumeRejectsOtherWorkflow(t *testing.T) {
+	store := &memConfigStore{}
+	var fetchRuns int
+	_, err := approvalWorkflow(t, store, &fetchRuns).Execute(context.Background(), core.AgentTask{Input: "x"})
+	var suspended *ErrSuspended
+	if !errors.As(err, &suspended) {
+		t.Fatalf("expected *ErrSuspended, got %v", err)
+	}
+
+	other, err := New("other", "other", WithWorkflowStore(store),
+		Step("noop", func(context.Context, *WorkflowContext) error { return nil }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Resume(context.Background(), suspended.RunID, nil); err == nil || !strings.Contains(err.Error(), `belongs to workflow "approval"`) {
+		t.Errorf("err = %v, want workflow mismatch", err)
+	}
+}
+
+func TestWorkflowSuspendWithoutStoreHasNoRunID(t *testing.T) {
+	wf, err := New("mem", "mem",
+		Step("gate", func(context.Context, *WorkflowContext) error { return Suspend(nil) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wf.Execute(context.Background(), core.AgentTask{})
+	var suspended *ErrSuspended
+	if !errors.As(err, &suspended) || suspended.RunID != "" {
+		t.Fatalf("err = %v, want in-memory suspension without RunID", err)
+	}
+	if _, err := wf.Resume(context.Background(), "x", nil); err == nil {
+		t.Error("Resume without a store: want error")
+	}
+}
+
+func TestSleep_SuspendsUntilWakeAndResumes(t *testing.T) {
+	store := &memConfigStore{}
+	var followed int
+	wf, err := New("reminder", "remind, wait a day, follow up",
+		WithWorkflowStore(store),
+		Sleep("wait", 24*time.Hour),
+		Step("follow", func(_ context.Context, wCtx *WorkflowContext) error {
+			followed++
+			return nil
+		}, After("wait")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	_, err = wf.Execute(ctx, core.AgentTask{Input: "hi"})
+	var susp *ErrSuspended
+	if !errors.As(err, &susp) {
+		t.Fatalf("err = %v, want ErrSuspended", err)
+	}
+	if d := time.Until(susp.WakeAt); d < 23*time.Hour || d > 25*time.Hour {
+		t.Fatalf("WakeAt in %v, want about 24h", d)
+	}
+	wake := susp.WakeAt
+
+	// Resuming early suspends again with the same wake time.
+	_, err = wf.Resume(ctx, susp.RunID, nil)
+	var again *ErrSuspended
+	if !errors.As(err, &again) || !again.WakeAt.Equal(wake) || followed != 0 {
+		t.Fatalf("early resume: err = %v, followed = %d; want re-suspension at %v", err, followed, wake)
+	}
+
+	// Move the stored wake time into the past, as if the day had gone by.
+	key := checkpointKeyPrefix + susp.RunID
+	var cp runCheckpoint
+	raw, _ := store.GetConfig(ctx, key)
+	if err := json.Unmarshal([]byte(raw), &cp); err != nil {
+		t.Fatal(err)
+	}
+	cp.Values["wait.wake_at"], _ = json.Marshal(time.Now().Add(-time.Minute).Format(time.RFC3339Nano))
+	b, _ := json.Marshal(cp)
+	store.SetConfig(ctx, key, string(b))
+
+	if _, err := wf.Resume(ctx, susp.RunID, nil); err != nil {
+		t.Fatalf("resume after wake: %v", err)
+	}
+	if followed != 1 {
+		t.Errorf("follow-up ran %d times, want 1", followed)
+	}
+}
+
+func TestSleep_ShortWaitBlocksInPlace(t *testing.T) {
+	wf, err := New("short", "",
+		Sleep("pause", 20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := wf.Execute(context.Background(), core.AgentTask{}); err != nil {
+		t.Fatal(err)
+	}
+	if el := time.Since(start); el < 20*time.Millisecond {
+		t.Errorf("returned after %v, want at least 20ms", el)
+	}
+
+	long, err := New("long", "", Sleep("pause", 30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if _, err := long.Execute(ctx, core.AgentTask{}); err == nil {
+		t.Error("cancelling ctx should fail the sleeping step")
+	}
+	if el := time.Since(start); el > 5*time.Second {
+		t.Errorf("sleep ignored ctx for %v", el)
+	}
+}
+
+func TestSleep_LongWaitRequiresStore(t *testing.T) {
+	if _, err := New("reminder", "", Sleep("wait", time.Hour)); err == nil {
+		t.Error("New: want error for a long sleep without WithWorkflowStore")
+	}
+}
+
+func TestSleep_ResumeDue(t *testing.T) {
+	store := sqlite.New(filepath.Join(t.TempDir(), "wf.db"))
+	ctx := context.Background()
+	if err := store.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var followed int
+	wf, err := New("reminder", "",
+		WithWorkflowStore(store),
+		Sleep("wait", 24*time.Hour),
+		Step("follow", func(context.Context, *WorkflowContext) error {
+			followed++
+			return nil
+		}, After("wait")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wf.Execute(ctx, core.AgentTask{Input: "hi"})
+	var susp *ErrSuspended
+	if !errors.As(err, &susp) {
+		t.Fatalf("err = %v, want ErrSuspended", err)
+	}
+
+	actions, err := store.ListScheduledActions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].NextRun != susp.WakeAt.Unix() || !strings.Contains(actions[0].ToolCalls, susp.RunID) {
+		t.Fatalf("wake-ups = %+v, want one due at %v for run %s", actions, susp.WakeAt, susp.RunID)
+	}
+	if n, err := wf.ResumeDue(ctx); err != nil || n != 0 {
+		t.Fatalf("ResumeDue before wake = %d, %v; want 0", n, err)
+	}
+
+	// Let the day pass: backdate the wake-up and the stored wake time.
+	key := checkpointKeyPrefix + susp.RunID
+	var cp runCheckpoint
+	raw, _ := store.GetConfig(ctx, key)
+	if err := json.Unmarshal([]byte(raw), &cp); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Minute)
+	cp.Values["wait.wake_at"], _ = json.Marshal(past.Format(time.RFC3339Nano))
+	b, _ := json.Marshal(cp)
+	store.SetConfig(ctx, key, string(b))
+	actions[0].NextRun = past.Unix()
+	if err := store.UpdateScheduledAction(ctx, actions[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := wf.ResumeDue(ctx); err != nil || n != 1 {
+		t.Fatalf("ResumeDue after wake = %d, %v; want 1", n, err)
+	}
+	if followed != 1 {
+		t.Errorf("follow-up ran %d times, want 1", followed)
+	}
+	if actions, _ := store.ListScheduledActions(ctx); len(actions) != 0 {
+		t.Errorf("wake-ups left after resume: %+v", actions)
+	}
+}
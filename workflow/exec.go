@@ -19,6 +19,8 @@ type executionState struct {
 	failureSkipped map[string]bool // steps skipped due to upstream failure (not When() condition)
 	suspendedStep  string          // name of step that suspended
 	suspendPayload json.RawMessage // payload from the suspended step
+	suspendWakeAt  time.Time       // wake time when the step suspended with SuspendUntil
+	runID          string          // durable run ID when resuming a stored checkpoint; empty otherwise
 	mu             sync.RWMutex    // protects results, failedStep, failureSkipped
 	cancel         context.CancelFunc
 }
@@ -90,7 +92,7 @@ func (w *Workflow) executeStreamInternal(ctx context.Context, task core.AgentTas
 
 	w.runDAG(ctx, state, ch)
 
-	result, err := w.buildResult(state, task, ch)
+	result, err := w.buildResult(ctx, state, task, ch)
 	if span != nil {
 		if err != nil {
 			var suspended *ErrSuspended
@@ -112,7 +114,11 @@ func (w *Workflow) executeStreamInternal(ctx context.Context, task core.AgentTas
 // are pre-populated — steps that were skipped due to the suspension (failure-skipped)
 // will re-execute on resume. This is intentional: those steps never ran, so they
 // must run once the suspended step succeeds.
-func (w *Workflow) executeResume(ctx context.Context, task core.AgentTask, completedResults map[string]StepResult, contextValues map[string]any, data json.RawMessage, ch chan<- core.StreamEvent) (core.AgentResult, error) {
+//
+// runID is the run's checkpoint key under WithWorkflowStore (empty without a
+// store). A re-suspension overwrites that checkpoint; any other outcome
+// clears it.
+func (w *Workflow) executeResume(ctx context.Context, task core.AgentTask, runID string, completedResults map[string]StepResult, contextValues map[string]any, data json.RawMessage, ch chan<- core.StreamEvent) (core.AgentResult, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -129,6 +135,7 @@ func (w *Workflow) executeResume(ctx context.Context, task core.AgentTask, compl
 		results:        make(map[string]StepResult),
 		failureSkipped: make(map[string]bool),
 		cancel:         cancel,
+		runID:          runID,
 	}
 
 	// Pre-populate completed steps so they don't re-execute.
@@ -142,13 +149,16 @@ func (w *Workflow) executeResume(ctx context.Context, task core.AgentTask, compl
 	delete(wCtx.values, resumeDataKey)
 	wCtx.mu.Unlock()
 
-	return w.buildResult(state, task, ch)
+	if runID != "" && state.suspendedStep == "" {
+		w.clearCheckpoint(ctx, runID)
+	}
+	return w.buildResult(ctx, state, task, ch)
 }
 
 // buildResult converts execution state into an AgentResult after the DAG completes.
 // Handles suspension (returns ErrSuspended), failure (returns WorkflowError),
 // and success. Shared by Execute and executeResume.
-func (w *Workflow) buildResult(state *executionState, task core.AgentTask, ch chan<- core.StreamEvent) (core.AgentResult, error) {
+func (w *Workflow) buildResult(ctx context.Context, state *executionState, task core.AgentTask, ch chan<- core.StreamEvent) (core.AgentResult, error) {
 	// Check for suspension.
 	if state.suspendedStep != "" {
 		snapshotResults := make(map[string]StepResult)
@@ -164,16 +174,22 @@ func (w *Workflow) buildResult(state *executionState, task core.AgentTask, ch ch
 
 		suspendedStep := state.suspendedStep
 		suspendPayload := state.suspendPayload
+		runID := w.saveCheckpoint(ctx, state.runID, task, suspendedStep, suspendPayload, snapshotResults, snapshotValues)
+		if runID != "" && !state.suspendWakeAt.IsZero() {
+			w.scheduleWake(ctx, runID, state.suspendWakeAt)
+		}
 
 		return core.AgentResult{}, &ErrSuspended{
 			Step:    suspendedStep,
 			Payload: suspendPayload,
+			RunID:   runID,
+			WakeAt:  state.suspendWakeAt,
 			resume: func(ctx context.Context, data json.RawMessage) (core.AgentResult, error) {
-				return w.executeResume(ctx, task, snapshotResults, snapshotValues, data, nil)
+				return w.executeResume(ctx, task, runID, snapshotResults, snapshotValues, data, nil)
 			},
 			resumeStream: func(ctx context.Context, data json.RawMessage, ch chan<- core.StreamEvent) (core.AgentResult, error) {
 				defer close(ch)
-				return w.executeResume(ctx, task, snapshotResults, snapshotValues, data, ch)
+				return w.executeResume(ctx, task, runID, snapshotResults, snapshotValues, data, ch)
 			},
 		}
 	}
@@ -426,6 +442,11 @@ func (w *Workflow) executeStep(ctx context.Context, s *stepConfig, state *execut
 		Status: StepRunning,
 	})
 
+	// AgentStep functions pick the channel up to forward their agent's events.
+	if ch != nil {
+		ctx = context.WithValue(ctx, stepStreamCtxKey{}, ch)
+	}
+
 	var run func() error
 	switch s.stepType {
 	case stepTypeForEach:
@@ -435,7 +456,7 @@ func (w *Workflow) executeStep(ctx context.Context, s *stepConfig, state *execut
 	case stepTypeDoWhile:
 		run = func() error { return w.executeDoWhile(ctx, s, state) }
 	default:
-		run = func() error { return s.fn(ctx, state.wCtx) }
+		run = func() error { return s.callFn(ctx, state.wCtx) }
 	}
 	err := w.executeWithRetry(ctx, s, run)
 
@@ -458,6 +479,7 @@ func (w *Workflow) recordStepOutcome(s *stepConfig, state *executionState, err e
 		if state.suspendedStep == "" {
 			state.suspendedStep = s.name
 			state.suspendPayload = suspend.payload
+			state.suspendWakeAt = suspend.wakeAt
 		}
 		state.mu.Unlock()
 		w.logger.Info("step suspended", "workflow", w.name, "step", s.name)
@@ -506,7 +528,7 @@ func (w *Workflow) recordStepOutcome(s *stepConfig, state *executionState, err e
 		state.cancel()
 		if ch != nil {
 			select {
-			case ch <- core.StreamEvent{Type: core.EventStepFinish, Name: s.name, Content: err.Error(), Duration: duration}:
+			case ch <- core.StreamEvent{Type: core.EventStepFinish, Name: s.name, Content: err.Error(), Duration: duration, IsError: true}:
 			default:
 			}
 		}
@@ -162,6 +162,81 @@ func TestWorkflowSkippedByConditionDoesNotCascadeFailure(t *testing.T) {
 	}
 }
 
+func TestWorkflowBranch(t *testing.T) {
+	for _, verdict := range []string{"approve", "reject"} {
+		t.Run(verdict, func(t *testing.T) {
+			var ran []string
+			record := func(name string) StepFunc {
+				return func(_ context.Context, wCtx *WorkflowContext) error {
+					ran = append(ran, name)
+					wCtx.Set(name+".output", name)
+					return nil
+				}
+			}
+			wf, err := New("branch", "branch test",
+				Step("review", func(_ context.Context, wCtx *WorkflowContext) error {
+					wCtx.Set("review.output", verdict)
+					return nil
+				}),
+				Branch("decide", func(wCtx *WorkflowContext) bool {
+					v, _ := wCtx.Get("review.output")
+					return v == "approve"
+				}, "publish", "revise", After("review")),
+				Step("publish", record("publish")),
+				Step("revise", record("revise")),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := wf.Execute(context.Background(), core.AgentTask{Input: "draft"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]string{"approve": "publish", "reject": "revise"}[verdict]
+			if len(ran) != 1 || ran[0] != want {
+				t.Errorf("ran = %v, want [%s]", ran, want)
+			}
+			if result.Output != want {
+				t.Errorf("Output = %q, want %q", result.Output, want)
+			}
+		})
+	}
+}
+
+func TestWorkflowBranchKeepsTargetWhen(t *testing.T) {
+	ran := false
+	wf, err := New("branch-when", "branch with target When",
+		Branch("decide", func(*WorkflowContext) bool { return true }, "a", ""),
+		Step("a", func(context.Context, *WorkflowContext) error {
+			ran = true
+			return nil
+		}, When(func(*WorkflowContext) bool { return false })),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wf.Execute(context.Background(), core.AgentTask{}); err != nil {
+		t.Fatal(err)
+	}
+	if ran {
+		t.Error("target ran although its own When returned false")
+	}
+}
+
+func TestNewWorkflowBranchUnknownTarget(t *testing.T) {
+	_, err := New("test", "test",
+		Branch("decide", func(*WorkflowContext) bool { return true }, "yes", "no"),
+		Step("yes", func(context.Context, *WorkflowContext) error { return nil }),
+	)
+	if err == nil {
+		t.Fatal("expected error for unknown branch target")
+	}
+	if want := `workflow test: branch "decide" targets unknown step "no"`; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
 // --- Failure cascade tests ---
 
 func TestWorkflowFailFast(t *testing.T) {
@@ -462,6 +537,54 @@ func TestWorkflowContextCancellation(t *testing.T) {
 	}
 }
 
+func TestWorkflowStreamForwardsAgentEvents(t *testing.T) {
+	writer := &streamingStubAgent{
+		name:   "writer",
+		output: "hello",
+		events: []core.StreamEvent{
+			{Type: core.EventRunStart},
+			{Type: core.EventTextDelta, Content: "hel"},
+			{Type: core.EventTextDelta, Content: "lo"},
+			{Type: core.EventRunFinish},
+		},
+	}
+	wf, err := New("stream-agent", "forward agent events",
+		AgentStep("write", writer),
+		Step("check", func(context.Context, *WorkflowContext) error {
+			return errors.New("bad draft")
+		}, After("write")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan core.StreamEvent, 32)
+	if _, err := wf.Execute(context.Background(), core.AgentTask{Input: "go"}, core.WithStream(ch)); err == nil {
+		t.Fatal("expected failure from step check")
+	}
+
+	var got []string
+	for ev := range ch {
+		switch ev.Type {
+		case core.EventRunStart, core.EventRunFinish:
+			t.Errorf("envelope event %s forwarded", ev.Type)
+		case core.EventTextDelta:
+			if ev.Agent != "writer" || ev.Name != "writer" {
+				t.Errorf("text delta Agent/Name = %q/%q, want writer", ev.Agent, ev.Name)
+			}
+			got = append(got, "delta:"+ev.Content)
+		case core.EventStepStart:
+			got = append(got, "start:"+ev.Name)
+		case core.EventStepFinish:
+			got = append(got, fmt.Sprintf("finish:%s:%v", ev.Name, ev.IsError))
+		}
+	}
+	want := []string{"start:write", "delta:hel", "delta:lo", "finish:write:false", "start:check", "finish:check:true"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
 // --------------------------------------------------------------------------
 // B. EventStepSuspended fires when a step returns Suspend(...)
 // --------------------------------------------------------------------------
@@ -535,3 +658,98 @@ func TestWorkflowStepSuspendedEventFires(t *testing.T) {
 		t.Errorf("EventStepSuspended (%d) must come before EventStepFinish (%d)", suspIdx, finishIdx)
 	}
 }
+
+// --- Timeout tests ---
+
+// blockUntilDone waits for ctx to end, standing in for a hung agent call.
+func blockUntilDone(ctx context.Context, _ *WorkflowContext) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWorkflowStepTimeout(t *testing.T) {
+	var siblingErr atomic.Value
+	wf, err := New("timeout", "timeout test",
+		Step("slow", blockUntilDone, Timeout(20*time.Millisecond)),
+		Step("sibling", func(ctx context.Context, _ *WorkflowContext) error {
+			<-ctx.Done()
+			siblingErr.Store(ctx.Err())
+			return ctx.Err()
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = wf.Execute(context.Background(), core.AgentTask{})
+	if time.Since(start) > time.Second {
+		t.Fatal("workflow did not stop at the step timeout")
+	}
+	var wfErr *WorkflowError
+	if !errors.As(err, &wfErr) || wfErr.StepName != "slow" {
+		t.Fatalf("err = %v, want WorkflowError for step slow", err)
+	}
+	if !errors.Is(err, ErrStepTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want ErrStepTimeout wrapping DeadlineExceeded", err)
+	}
+	if wfErr.Result.Steps["slow"].Status != StepFailed {
+		t.Errorf("slow status = %s, want failed", wfErr.Result.Steps["slow"].Status)
+	}
+	if siblingErr.Load() != context.Canceled {
+		t.Errorf("sibling ctx err = %v, want Canceled by fail-fast", siblingErr.Load())
+	}
+}
+
+func TestWorkflowStepTimeoutRetries(t *testing.T) {
+	var attempts atomic.Int32
+	wf, err := New("timeout-retry", "timeout retry test",
+		Step("flaky", func(ctx context.Context, wCtx *WorkflowContext) error {
+			if attempts.Add(1) == 1 {
+				return blockUntilDone(ctx, wCtx)
+			}
+			wCtx.Set("flaky.output", "ok")
+			return nil
+		}, Timeout(20*time.Millisecond), Retry(1, 0)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := wf.Execute(context.Background(), core.AgentTask{})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Output != "ok" || attempts.Load() != 2 {
+		t.Errorf("output = %q after %d attempts, want ok after 2", result.Output, attempts.Load())
+	}
+}
+
+func TestWorkflowTimeoutIsPerIteration(t *testing.T) {
+	var iterations atomic.Int32
+	wf, err := New("loop-timeout", "per-iteration timeout",
+		// Three 15ms iterations exceed the 25ms timeout in total but not
+		// individually.
+		DoUntil("poll", func(ctx context.Context, wCtx *WorkflowContext) error {
+			select {
+			case <-time.After(15 * time.Millisecond):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			iterations.Add(1)
+			wCtx.Set("items", []any{1, 2})
+			return nil
+		}, Until(func(*WorkflowContext) bool { return iterations.Load() == 3 }), Timeout(25*time.Millisecond)),
+		ForEach("each", blockUntilDone, After("poll"), IterOver("items"), Timeout(10*time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wf.Execute(context.Background(), core.AgentTask{})
+	var wfErr *WorkflowError
+	if !errors.As(err, &wfErr) || wfErr.StepName != "each" || !errors.Is(err, ErrStepTimeout) {
+		t.Fatalf("err = %v, want timeout in each", err)
+	}
+	if iterations.Load() != 3 {
+		t.Errorf("poll ran %d iterations, want 3", iterations.Load())
+	}
+}
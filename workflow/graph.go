@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT returns the step graph in Graphviz DOT format, for review and
+// debugging: one node per step labelled with its name and kind (step, agent,
+// tool, branch, foreach, dountil, dowhile) and one edge per After
+// dependency. Conditional steps (When, Branch targets) are drawn dashed, as
+// are the edges into them; edges out of a Branch are labelled true/false.
+//
+//	os.WriteFile("wf.dot", []byte(wf.ToDOT()), 0o644)
+//	// dot -Tsvg wf.dot -o wf.svg
+//
+// Steps and edges appear in declaration order, so the output is stable.
+func (w *Workflow) ToDOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(w.name))
+	b.WriteString("  node [shape=box];\n")
+	for _, name := range w.stepOrder {
+		s := w.steps[name]
+		fmt.Fprintf(&b, "  %s [label=%s", dotQuote(name), dotQuote(name+"\n("+s.kindLabel()+")"))
+		if s.when != nil {
+			b.WriteString(", style=dashed")
+		}
+		b.WriteString("];\n")
+	}
+	for _, name := range w.stepOrder {
+		for _, dep := range w.edges[name] {
+			fmt.Fprintf(&b, "  %s -> %s", dotQuote(dep), dotQuote(name))
+			if label := w.branchLabel(dep, name); label != "" {
+				fmt.Fprintf(&b, " [style=dashed, label=%s]", dotQuote(label))
+			} else if w.steps[name].when != nil {
+				b.WriteString(" [style=dashed]")
+			}
+			b.WriteString(";\n")
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ToMermaid returns the step graph as a Mermaid flowchart, for embedding in
+// Markdown docs. It draws the same graph as ToDOT: conditional steps get a
+// dashed outline and dotted incoming edges.
+func (w *Workflow) ToMermaid() string {
+	// Mermaid IDs are restricted; step names only appear in labels.
+	ids := make(map[string]string, len(w.stepOrder))
+	for i, name := range w.stepOrder {
+		ids[name] = fmt.Sprintf("s%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	var conditional []string
+	for _, name := range w.stepOrder {
+		s := w.steps[name]
+		fmt.Fprintf(&b, "  %s[\"%s<br/>(%s)\"]\n", ids[name], mermaidEscape(name), s.kindLabel())
+		if s.when != nil {
+			conditional = append(conditional, ids[name])
+		}
+	}
+	for _, name := range w.stepOrder {
+		arrow := "-->"
+		if w.steps[name].when != nil {
+			arrow = "-.->"
+		}
+		for _, dep := range w.edges[name] {
+			if label := w.branchLabel(dep, name); label != "" {
+				fmt.Fprintf(&b, "  %s -. %s .-> %s\n", ids[dep], label, ids[name])
+				continue
+			}
+			fmt.Fprintf(&b, "  %s %s %s\n", ids[dep], arrow, ids[name])
+		}
+	}
+	if len(conditional) > 0 {
+		b.WriteString("  classDef conditional stroke-dasharray: 5 5\n")
+		fmt.Fprintf(&b, "  class %s conditional\n", strings.Join(conditional, ","))
+	}
+	return b.String()
+}
+
+// branchLabel returns "true" or "false" when the edge dep -> name leaves a
+// Branch step toward one of its targets, "" otherwise.
+func (w *Workflow) branchLabel(dep, name string) string {
+	switch d := w.steps[dep]; {
+	case d.kind != "branch":
+		return ""
+	case d.branchTrue == name:
+		return "true"
+	case d.branchFalse == name:
+		return "false"
+	}
+	return ""
+}
+
+// kindLabel names the step's kind for graph exports.
+func (s *stepConfig) kindLabel() string {
+	if s.kind != "" {
+		return s.kind
+	}
+	switch s.stepType {
+	case stepTypeForEach:
+		return "foreach"
+	case stepTypeDoUntil:
+		return "dountil"
+	case stepTypeDoWhile:
+		return "dowhile"
+	default:
+		return "step"
+	}
+}
+
+// dotQuote renders s as a DOT quoted string. Newlines become DOT's \n line
+// break.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// mermaidEscape makes s safe inside a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	return r.Replace(s)
+}
@@ -0,0 +1,89 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nevindra/oasis/core"
+)
+
+func graphWorkflow(t *testing.T) *Workflow {
+	t.Helper()
+	noop := func(context.Context, *WorkflowContext) error { return nil }
+	researcher := &stubAgent{name: "researcher", fn: func(core.AgentTask) (core.AgentResult, error) { return core.AgentResult{}, nil }}
+	wf, err := New("report", "build a report",
+		Step("fetch", noop),
+		AgentStep("research", researcher, After("fetch")),
+		ForEach("summarize", noop, After("fetch"), IterOver("docs")),
+		Step(`publish "final"`, noop, After("research", "summarize"),
+			When(func(*WorkflowContext) bool { return true })),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wf
+}
+
+func TestWorkflowToDOT(t *testing.T) {
+	want := `digraph "report" {
+  node [shape=box];
+  "fetch" [label="fetch\n(step)"];
+  "research" [label="research\n(agent)"];
+  "summarize" [label="summarize\n(foreach)"];
+  "publish \"final\"" [label="publish \"final\"\n(step)", style=dashed];
+  "fetch" -> "research";
+  "fetch" -> "summarize";
+  "research" -> "publish \"final\"" [style=dashed];
+  "summarize" -> "publish \"final\"" [style=dashed];
+}
+`
+	if got := graphWorkflow(t).ToDOT(); got != want {
+		t.Errorf("ToDOT() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWorkflowToMermaid(t *testing.T) {
+	want := `flowchart TD
+  s0["fetch<br/>(step)"]
+  s1["research<br/>(agent)"]
+  s2["summarize<br/>(foreach)"]
+  s3["publish #quot;final#quot;<br/>(step)"]
+  s0 --> s1
+  s0 --> s2
+  s1 -.-> s3
+  s2 -.-> s3
+  classDef conditional stroke-dasharray: 5 5
+  class s3 conditional
+`
+	if got := graphWorkflow(t).ToMermaid(); got != want {
+		t.Errorf("ToMermaid() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWorkflowToDOTBranch(t *testing.T) {
+	noop := func(context.Context, *WorkflowContext) error { return nil }
+	wf, err := New("review", "review",
+		Branch("decide", func(*WorkflowContext) bool { return true }, "publish", "revise"),
+		Step("publish", noop),
+		Step("revise", noop),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "review" {
+  node [shape=box];
+  "decide" [label="decide\n(branch)"];
+  "publish" [label="publish\n(step)", style=dashed];
+  "revise" [label="revise\n(step)", style=dashed];
+  "decide" -> "publish" [style=dashed, label="true"];
+  "decide" -> "revise" [style=dashed, label="false"];
+}
+`
+	if got := wf.ToDOT(); got != want {
+		t.Errorf("ToDOT() =\n%s\nwant:\n%s", got, want)
+	}
+	if got := wf.ToMermaid(); !strings.Contains(got, "s0 -. true .-> s1\n") || !strings.Contains(got, "s0 -. false .-> s2\n") {
+		t.Errorf("ToMermaid() missing labelled branch edges:\n%s", got)
+	}
+}
@@ -3,9 +3,11 @@ package workflow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/nevindra/oasis/core"
 )
@@ -19,6 +21,7 @@ type forEachIterCtxKey struct{}
 type forEachIter struct {
 	item  any
 	index int
+	out   *any // this iteration's CollectTo slot; nil without CollectTo
 }
 
 // ForEachItem retrieves the current iteration element inside a ForEach step function.
@@ -41,11 +44,72 @@ func ForEachIndex(ctx context.Context) (int, bool) {
 	return -1, false
 }
 
+// SetForEachOutput records v as the current iteration's output for a ForEach
+// step configured with CollectTo. Each iteration owns its own slot, so
+// concurrent iterations need no locking; calling it again overwrites the
+// value. It is a no-op outside a ForEach step or without CollectTo.
+func SetForEachOutput(ctx context.Context, v any) {
+	if it, ok := ctx.Value(forEachIterCtxKey{}).(forEachIter); ok && it.out != nil {
+		*it.out = v
+	}
+}
+
 // --- Agent and Tool step wrappers ---
 
+// stepStreamCtxKey is the context key for the workflow's stream channel,
+// set by executeStep when the run is streaming.
+type stepStreamCtxKey struct{}
+
+// stepStream returns the workflow's stream channel, or nil when the run is
+// not streaming.
+func stepStream(ctx context.Context) chan<- core.StreamEvent {
+	ch, _ := ctx.Value(stepStreamCtxKey{}).(chan<- core.StreamEvent)
+	return ch
+}
+
+// executeAgentStreaming runs agent with its own stream and forwards the
+// events into ch, so a streaming workflow shows the agent's text and tool
+// calls live instead of a silent step. Envelope events (run and iteration
+// start/finish) are dropped — the workflow's step events frame the agent —
+// and every forwarded event is stamped with the agent's name, as Network
+// does for its subagents.
+func executeAgentStreaming(ctx context.Context, agent core.Agent, task core.AgentTask, ch chan<- core.StreamEvent) (core.AgentResult, error) {
+	name := agent.Name()
+	subCh := make(chan core.StreamEvent, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range subCh {
+			switch ev.Type {
+			case core.EventRunStart, core.EventRunFinish, core.EventIterationStart, core.EventIterationFinish:
+				continue
+			case core.EventTextDelta, core.EventReasoningStart, core.EventReasoningDelta, core.EventReasoningEnd, core.EventThinking:
+				if ev.Name == "" {
+					ev.Name = name
+				}
+			}
+			if ev.Agent == "" {
+				ev.Agent = name
+			}
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				// Keep draining so the agent never blocks on a full subCh.
+				for range subCh {
+				}
+				return
+			}
+		}
+	}()
+	result, err := agent.Execute(ctx, task, core.WithStream(subCh))
+	<-done
+	return result, err
+}
+
 // agentStepFunc wraps an Agent into a StepFunc. Input is read from context
 // (via InputFrom key) or from the original task input. Output and usage are
-// written back to context.
+// written back to context. When the workflow is streaming, the agent's
+// events are forwarded into the workflow's stream.
 func agentStepFunc(agent core.Agent, cfg *stepConfig) StepFunc {
 	return func(ctx context.Context, wCtx *WorkflowContext) error {
 		input := wCtx.Input()
@@ -55,14 +119,23 @@ func agentStepFunc(agent core.Agent, cfg *stepConfig) StepFunc {
 			}
 		}
 
-		result, err := agent.Execute(ctx, core.AgentTask{
+		task := core.AgentTask{
 			Input:       input,
 			Attachments: wCtx.task.Attachments,
 			ThreadID:    wCtx.task.ThreadID,
 			UserID:      wCtx.task.UserID,
 			ChatID:      wCtx.task.ChatID,
 			Extra:       wCtx.task.Extra,
-		})
+		}
+		var (
+			result core.AgentResult
+			err    error
+		)
+		if ch := stepStream(ctx); ch != nil {
+			result, err = executeAgentStreaming(ctx, agent, task, ch)
+		} else {
+			result, err = agent.Execute(ctx, task)
+		}
 		if err != nil {
 			return err
 		}
@@ -80,6 +153,42 @@ func agentStepFunc(agent core.Agent, cfg *stepConfig) StepFunc {
 	}
 }
 
+// inlineSleepMax is the longest wait a Sleep step blocks for; longer waits
+// suspend the run.
+const inlineSleepMax = time.Minute
+
+// sleepStepFunc implements Sleep. The wake time is stored as an RFC 3339
+// string so it survives the checkpoint's JSON round trip.
+func sleepStepFunc(name string, d time.Duration) StepFunc {
+	key := name + ".wake_at"
+	return func(ctx context.Context, wCtx *WorkflowContext) error {
+		var wake time.Time
+		if v, ok := wCtx.Get(key); ok {
+			s, _ := v.(string)
+			wake, _ = time.Parse(time.RFC3339Nano, s)
+		}
+		if wake.IsZero() {
+			wake = time.Now().Add(d)
+			wCtx.Set(key, wake.Format(time.RFC3339Nano))
+		}
+		wait := time.Until(wake)
+		if wait <= 0 {
+			return nil
+		}
+		if wait > inlineSleepMax {
+			return SuspendUntil(wake)
+		}
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 // toolStepFunc wraps an core.AnyTool call into a StepFunc. Args are read from context
 // (via ArgsFrom key) and the tool result is written back to context. toolName
 // is preserved for error-message labelling; the core.AnyTool itself owns dispatch.
@@ -142,7 +251,17 @@ func (w *Workflow) executeForEach(ctx context.Context, s *stepConfig, state *exe
 		return fmt.Errorf("step %s: IterOver key %q is not []any", s.name, s.iterOver)
 	}
 
+	// Why index slots: iterations finish in any order under Concurrency, so
+	// each writes to results[idx] and the slice is stored whole at the end.
+	var results []any
+	if s.collectTo != "" {
+		results = make([]any, len(items))
+	}
+
 	if len(items) == 0 {
+		if s.collectTo != "" {
+			state.wCtx.Set(s.collectTo, results)
+		}
 		return nil
 	}
 
@@ -179,12 +298,13 @@ func (w *Workflow) executeForEach(ctx context.Context, s *stepConfig, state *exe
 					return
 				}
 
-				elemCtx := context.WithValue(iterCtx, forEachIterCtxKey{}, forEachIter{
-					item:  elem,
-					index: idx,
-				})
+				iter := forEachIter{item: elem, index: idx}
+				if results != nil {
+					iter.out = &results[idx]
+				}
+				elemCtx := context.WithValue(iterCtx, forEachIterCtxKey{}, iter)
 
-				if err := s.fn(elemCtx, state.wCtx); err != nil {
+				if err := s.callFn(elemCtx, state.wCtx); err != nil {
 					errOnce.Do(func() { firstErr = err })
 					iterCancel()
 					return
@@ -209,6 +329,9 @@ func (w *Workflow) executeForEach(ctx context.Context, s *stepConfig, state *exe
 	}
 
 	wg.Wait()
+	if firstErr == nil && ctx.Err() == nil && s.collectTo != "" {
+		state.wCtx.Set(s.collectTo, results)
+	}
 	return firstErr
 }
 
@@ -229,7 +352,7 @@ func (w *Workflow) executeDoUntil(ctx context.Context, s *stepConfig, state *exe
 			return ctx.Err()
 		}
 
-		if err := s.fn(ctx, state.wCtx); err != nil {
+		if err := s.callFn(ctx, state.wCtx); err != nil {
 			return err
 		}
 
@@ -264,7 +387,7 @@ func (w *Workflow) executeDoWhile(ctx context.Context, s *stepConfig, state *exe
 			return nil
 		}
 
-		if err := s.fn(ctx, state.wCtx); err != nil {
+		if err := s.callFn(ctx, state.wCtx); err != nil {
 			return err
 		}
 	}
@@ -272,3 +395,19 @@ func (w *Workflow) executeDoWhile(ctx context.Context, s *stepConfig, state *exe
 	w.logger.Warn("step reached max iterations", "workflow", w.name, "step", s.name, "max_iter", maxIter)
 	return fmt.Errorf("step %s: %w", s.name, ErrMaxIterExceeded)
 }
+
+// callFn runs the step function once, under the step's Timeout when set.
+// Only the child context expires, so the caller's ctx stays live and Retry
+// can run another attempt.
+func (s *stepConfig) callFn(ctx context.Context, wCtx *WorkflowContext) error {
+	if s.timeout <= 0 {
+		return s.fn(ctx, wCtx)
+	}
+	tctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	err := s.fn(tctx, wCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(tctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("step %s: %w after %s: %w", s.name, ErrStepTimeout, s.timeout, err)
+	}
+	return err
+}
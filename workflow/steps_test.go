@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/nevindra/oasis/core"
 )
@@ -230,6 +231,73 @@ func TestWorkflowForEachNoItemRace(t *testing.T) {
 	}
 }
 
+func TestWorkflowForEachCollectTo(t *testing.T) {
+	wf, err := New("map-reduce", "collect then reduce",
+		Step("seed", func(_ context.Context, wCtx *WorkflowContext) error {
+			items := make([]any, 20)
+			for i := range items {
+				items[i] = i
+			}
+			wCtx.Set("items", items)
+			return nil
+		}),
+		ForEach("square", func(ctx context.Context, _ *WorkflowContext) error {
+			item, _ := ForEachItem(ctx)
+			n := item.(int)
+			// Later elements finish first so completion order is reversed.
+			time.Sleep(time.Duration(20-n) * time.Millisecond)
+			SetForEachOutput(ctx, n*n)
+			return nil
+		}, After("seed"), IterOver("items"), Concurrency(20), CollectTo("squares")),
+		Step("sum", func(_ context.Context, wCtx *WorkflowContext) error {
+			v, _ := wCtx.Get("squares")
+			squares := v.([]any)
+			for i, sq := range squares {
+				if sq != i*i {
+					return fmt.Errorf("squares[%d] = %v, want %d", i, sq, i*i)
+				}
+			}
+			wCtx.Set("sum.output", fmt.Sprint(len(squares)))
+			return nil
+		}, After("square")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := wf.Execute(context.Background(), core.AgentTask{Input: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "20" {
+		t.Errorf("Output = %q, want %q", result.Output, "20")
+	}
+}
+
+func TestWorkflowForEachCollectToEmpty(t *testing.T) {
+	wf, err := New("map-empty", "collect over no items",
+		Step("seed", func(_ context.Context, wCtx *WorkflowContext) error {
+			wCtx.Set("items", []any{})
+			return nil
+		}),
+		ForEach("noop", func(context.Context, *WorkflowContext) error { return nil },
+			After("seed"), IterOver("items"), CollectTo("out")),
+		Step("check", func(_ context.Context, wCtx *WorkflowContext) error {
+			v, ok := wCtx.Get("out")
+			if s, isSlice := v.([]any); !ok || !isSlice || len(s) != 0 {
+				return fmt.Errorf("out = %#v, %v; want empty []any", v, ok)
+			}
+			return nil
+		}, After("noop")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wf.Execute(context.Background(), core.AgentTask{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestWorkflowForEachMissingIterOver(t *testing.T) {
 	wf, err := New("foreach-missing", "foreach missing iterover",
 		ForEach("bad", func(_ context.Context, _ *WorkflowContext) error {
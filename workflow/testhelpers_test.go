@@ -30,3 +30,23 @@ func (m mockTool) Definition() core.ToolDefinition {
 func (m mockTool) ExecuteRaw(_ context.Context, _ json.RawMessage) (core.ToolResult, error) {
 	return core.TextResult("hello from greet"), nil
 }
+
+// streamingStubAgent emits events on the WithStream channel, closing it
+// before returning as the Agent contract requires.
+type streamingStubAgent struct {
+	name   string
+	events []core.StreamEvent
+	output string
+}
+
+func (s *streamingStubAgent) Name() string        { return s.name }
+func (s *streamingStubAgent) Description() string { return "" }
+func (s *streamingStubAgent) Execute(_ context.Context, _ core.AgentTask, opts ...core.RunOption) (core.AgentResult, error) {
+	if ch := core.ApplyRunOptions(opts...).Stream; ch != nil {
+		for _, ev := range s.events {
+			ch <- ev
+		}
+		close(ch)
+	}
+	return core.AgentResult{Output: s.output}, nil
+}
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +37,14 @@ type ErrSuspended struct {
 	Step string
 	// Payload carries context for the human (passed to Suspend).
 	Payload json.RawMessage
+	// RunID identifies the checkpoint saved under WithWorkflowStore; pass it
+	// to Workflow.Resume, from any process, to continue the run. Empty when
+	// no store is configured or the checkpoint could not be saved.
+	RunID string
+	// WakeAt is set when the step is waiting for a time (Sleep or
+	// SuspendUntil) rather than for input: resume the run at or after
+	// WakeAt. Zero otherwise.
+	WakeAt time.Time
 	// resume continues execution with human input.
 	resume func(ctx context.Context, data json.RawMessage) (core.AgentResult, error)
 	// resumeStream is like resume but emits StreamEvent values into ch.
@@ -117,7 +128,8 @@ func (e *ErrSuspended) ResumeStream(ctx context.Context, data json.RawMessage, c
 // errSuspend is the internal sentinel for suspension.
 type errSuspend struct {
 	payload json.RawMessage
-	tag     string // empty for untyped Suspend; protocol name for typed suspends
+	tag     string    // empty for untyped Suspend; protocol name for typed suspends
+	wakeAt  time.Time // set by SuspendUntil
 }
 
 func (e *errSuspend) Error() string { return "suspend" }
@@ -127,6 +139,18 @@ func Suspend(payload json.RawMessage) error {
 	return &errSuspend{payload: payload}
 }
 
+// SuspendUntil signals the workflow to pause until t. The returned
+// ErrSuspended has WakeAt set to t and a {"wake_at": t} payload; whoever
+// holds it (or the RunID) resumes the run then. The step runs again on
+// resume, so it must check the clock itself and suspend again if resumed
+// early — Sleep does this for you.
+func SuspendUntil(t time.Time) error {
+	payload, _ := json.Marshal(struct {
+		WakeAt time.Time `json:"wake_at"`
+	}{t})
+	return &errSuspend{payload: payload, wakeAt: t}
+}
+
 // --- Workflow Definition Types ---
 
 // NodeType describes the type of a workflow node.
@@ -252,6 +276,139 @@ func (c *WorkflowContext) Set(key string, value any) {
 	c.values[key] = value
 }
 
+// GetString returns the string stored under key. ok is false when the key is
+// missing or holds any other type.
+func (c *WorkflowContext) GetString(key string) (string, bool) {
+	v, ok := c.Get(key)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// GetInt returns the integer stored under key, converting from any Go
+// integer type, json.Number, or a float64 with no fractional part (the form
+// numbers take after a JSON round trip, e.g. a restored checkpoint). ok is
+// false when the key is missing, holds a non-numeric type, or the number
+// does not fit in an int.
+func (c *WorkflowContext) GetInt(key string) (int, bool) {
+	v, ok := c.Get(key)
+	if !ok {
+		return 0, false
+	}
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), int64(int(n)) == n
+	case uint:
+		return int(n), int(n) >= 0
+	case uint8:
+		return int(n), true
+	case uint16:
+		return int(n), true
+	case uint32:
+		return int(n), int64(n) <= math.MaxInt
+	case uint64:
+		return int(n), n <= math.MaxInt
+	case float32:
+		return floatToInt(float64(n))
+	case float64:
+		return floatToInt(n)
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return int(i), int64(int(i)) == i
+	}
+	return 0, false
+}
+
+// floatToInt converts f when it is a whole number within int range.
+func floatToInt(f float64) (int, bool) {
+	if f != math.Trunc(f) || f < math.MinInt || f >= math.MaxInt {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// GetFloat returns the number stored under key as a float64, converting
+// from any Go integer or float type or json.Number. ok is false when the key
+// is missing or holds a non-numeric type.
+func (c *WorkflowContext) GetFloat(key string) (float64, bool) {
+	v, ok := c.Get(key)
+	if !ok {
+		return 0, false
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// GetJSON decodes the value stored under key into dst, which must be a
+// pointer. A string, []byte or json.RawMessage value is parsed as JSON text
+// — the usual shape of an AgentStep's output — and any other value is
+// round-tripped through json.Marshal, so a map written by one step can be
+// read back as a struct. ok is false when the key is missing or the value
+// does not decode into dst.
+func (c *WorkflowContext) GetJSON(key string, dst any) bool {
+	v, ok := c.Get(key)
+	if !ok {
+		return false
+	}
+	var data []byte
+	switch raw := v.(type) {
+	case string:
+		data = []byte(raw)
+	case []byte:
+		data = raw
+	case json.RawMessage:
+		data = raw
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		data = b
+	}
+	return json.Unmarshal(data, dst) == nil
+}
+
 // Input returns the original AgentTask.Input that started the workflow.
 func (c *WorkflowContext) Input() string {
 	return c.input
@@ -404,6 +561,10 @@ type WorkflowResult struct {
 // is reached without the exit condition being met.
 var ErrMaxIterExceeded = errors.New("step reached max iterations without meeting exit condition")
 
+// ErrStepTimeout is returned (wrapped) when a step attempt exceeds its
+// Timeout.
+var ErrStepTimeout = errors.New("step timed out")
+
 // ErrOverridesUnsupported is returned by Workflow.Execute when per-call
 // overrides (RunConfig.Overrides, e.g. via agent.WithOverrides) are supplied.
 // Workflows run a fixed, declaration-time step graph and intentionally do not
@@ -466,17 +627,25 @@ type stepConfig struct {
 	outputTo   string                      // override default output key
 	retry      int                         // max retry count (0 = no retries)
 	retryDelay time.Duration               // delay between retries
+	timeout    time.Duration               // per-attempt (per-iteration for loops) bound; 0 = none
 
 	// ForEach fields
 	iterOver    string // context key containing []any
 	concurrency int    // max parallel iterations (default 1)
+	collectTo   string // context key receiving per-iteration outputs as []any
 
 	// Loop fields
 	until   func(*WorkflowContext) bool // DoUntil: exit when true
 	whileFn func(*WorkflowContext) bool // DoWhile: continue while true
 	maxIter int                         // loop safety cap (default 10)
 
+	// Branch fields: the steps gated on this branch's decision.
+	branchTrue  string
+	branchFalse string
+
 	stepType stepType
+	kind     string        // display kind for ToDOT/ToMermaid: "agent", "tool", "branch" or "sleep"; empty derives from stepType
+	sleep    time.Duration // Sleep's wait; checked against the store in New
 }
 
 // workflowConfig accumulates options passed to New.
@@ -488,6 +657,7 @@ type workflowConfig struct {
 	defaultDelay time.Duration
 	tracer       core.Tracer
 	logger       *slog.Logger
+	store        ConfigStore
 }
 
 // --- Step options ---
@@ -539,6 +709,16 @@ func Retry(n int, delay time.Duration) StepOption {
 	}
 }
 
+// Timeout bounds each run of the step function: every attempt of a basic
+// step and every iteration of a ForEach, DoUntil or DoWhile step gets its
+// own context.WithTimeout(ctx, d). On expiry the attempt fails with
+// ErrStepTimeout, so Retry applies and, once retries are exhausted, the step
+// fails and cancels in-flight siblings like any other failure. The step
+// function must honor ctx for the timeout to take effect; AgentStep does.
+func Timeout(d time.Duration) StepOption {
+	return func(c *stepConfig) { c.timeout = d }
+}
+
 // IterOver sets the context key that contains a []any collection for a
 // ForEach step. Each element is made available to the step function via
 // the context key "{name}.item".
@@ -546,6 +726,15 @@ func IterOver(key string) StepOption {
 	return func(c *stepConfig) { c.iterOver = key }
 }
 
+// CollectTo gathers a ForEach step's per-iteration outputs into a []any
+// stored under key once all iterations succeed. Iterations report their
+// output with SetForEachOutput; the slice is in element order regardless of
+// Concurrency, and holds nil for iterations that set nothing. A downstream
+// reduce step reads the slice with After on the ForEach step.
+func CollectTo(key string) StepOption {
+	return func(c *stepConfig) { c.collectTo = key }
+}
+
 // Concurrency sets the maximum number of parallel iterations for a ForEach step.
 // Defaults to 1 (sequential) if not specified.
 func Concurrency(n int) StepOption {
@@ -646,6 +835,23 @@ func AgentStep(name string, agent core.Agent, opts ...StepOption) WorkflowOption
 	return func(c *workflowConfig) {
 		cfg := buildStepConfig(name, nil, stepTypeBasic, opts)
 		cfg.fn = agentStepFunc(agent, cfg)
+		cfg.kind = "agent"
+		c.steps = append(c.steps, cfg)
+	}
+}
+
+// Sleep defines a step that waits d before its dependents run. A wait of up
+// to a minute blocks in place, honoring ctx. A longer wait suspends the run
+// with SuspendUntil and needs WithWorkflowStore (New fails without one):
+// Execute returns *ErrSuspended with WakeAt and RunID set, and a store that
+// implements core.ScheduledActionStore also gets a wake-up that ResumeDue
+// acts on. The wake time is kept in the context under "{name}.wake_at", so
+// an early resume suspends again and a late one continues at once.
+func Sleep(name string, d time.Duration, opts ...StepOption) WorkflowOption {
+	return func(c *workflowConfig) {
+		cfg := buildStepConfig(name, sleepStepFunc(name, d), stepTypeBasic, opts)
+		cfg.kind = "sleep"
+		cfg.sleep = d
 		c.steps = append(c.steps, cfg)
 	}
 }
@@ -656,6 +862,7 @@ func toolStepInternal(name string, tool core.AnyTool, toolName string, opts ...S
 	return func(c *workflowConfig) {
 		cfg := buildStepConfig(name, nil, stepTypeBasic, opts)
 		cfg.fn = toolStepFunc(tool, toolName, cfg)
+		cfg.kind = "tool"
 		c.steps = append(c.steps, cfg)
 	}
 }
@@ -692,6 +899,36 @@ func DoWhile(name string, fn StepFunc, opts ...StepOption) WorkflowOption {
 	}
 }
 
+// Branch defines a step that evaluates predicate and activates exactly one
+// of two downstream steps: ifTrue when it returns true, ifFalse otherwise.
+// Either target may be "" to run nothing on that side. The targets get an
+// implicit After(name) and are skipped (StepSkipped) when not chosen; a
+// target's own When still applies on top of the branch decision. The
+// decision is written to context as "{name}.result" ("true" or "false").
+//
+//	Step("review", reviewFn),
+//	Branch("decide", func(wCtx *WorkflowContext) bool {
+//		v, _ := wCtx.Get("review.output")
+//		return v == "approve"
+//	}, "publish", "revise", After("review")),
+//	Step("publish", publishFn),
+//	Step("revise", reviseFn),
+//
+// As with When, a skipped target counts as satisfied, so a step that
+// depends on both targets runs after whichever one was taken.
+func Branch(name string, predicate func(*WorkflowContext) bool, ifTrue, ifFalse string, opts ...StepOption) WorkflowOption {
+	return func(c *workflowConfig) {
+		cfg := buildStepConfig(name, func(_ context.Context, wCtx *WorkflowContext) error {
+			wCtx.Set(name+resultSuffix, strconv.FormatBool(predicate(wCtx)))
+			return nil
+		}, stepTypeBasic, opts)
+		cfg.branchTrue = ifTrue
+		cfg.branchFalse = ifFalse
+		cfg.kind = "branch"
+		c.steps = append(c.steps, cfg)
+	}
+}
+
 // --- Workflow struct ---
 
 const defaultLoopMaxIter = 10
@@ -720,6 +957,7 @@ type Workflow struct {
 	defaultDelay time.Duration
 	tracer       core.Tracer
 	logger       *slog.Logger
+	store        ConfigStore // checkpoints for suspended runs; nil = in-memory only
 }
 
 // compile-time checks
@@ -739,6 +977,7 @@ func (w *Workflow) Description() string { return w.description }
 // Returns an error if the step graph is invalid:
 //   - duplicate step names
 //   - After() references an unknown step
+//   - Branch() targets an unknown step or itself
 //   - cycle detected in the dependency graph
 //
 // Logs a warning for unreachable steps (steps that are not roots and have no
@@ -765,6 +1004,7 @@ func New(name, description string, opts ...WorkflowOption) (*Workflow, error) {
 		defaultDelay: cfg.defaultDelay,
 		tracer:       cfg.tracer,
 		logger:       logger,
+		store:        cfg.store,
 	}
 
 	// Register steps, check for duplicates.
@@ -772,6 +1012,11 @@ func New(name, description string, opts ...WorkflowOption) (*Workflow, error) {
 		if _, exists := w.steps[s.name]; exists {
 			return nil, fmt.Errorf("workflow %s: duplicate step name %q", name, s.name)
 		}
+		// Why: a long sleep suspends the run, and without a store there is
+		// no RunID for anything to resume it by.
+		if s.sleep > inlineSleepMax && cfg.store == nil {
+			return nil, fmt.Errorf("workflow %s: step %q sleeps longer than %v, which requires WithWorkflowStore", name, s.name, inlineSleepMax)
+		}
 
 		// Apply default retry if step doesn't have its own.
 		if s.retry == 0 && w.defaultRetry > 0 {
@@ -794,6 +1039,10 @@ func New(name, description string, opts ...WorkflowOption) (*Workflow, error) {
 		w.edges[s.name] = s.after
 	}
 
+	if err := w.wireBranches(cfg.steps); err != nil {
+		return nil, err
+	}
+
 	// Validate dependencies: all After() targets must exist.
 	for _, s := range cfg.steps {
 		for _, dep := range s.after {
@@ -827,6 +1076,64 @@ func New(name, description string, opts ...WorkflowOption) (*Workflow, error) {
 	return w, nil
 }
 
+// wireBranches turns each Branch step's targets into ordinary dependents:
+// the target gets an edge from the branch and a When gate on its decision,
+// composed with the target's own When. Doing this before the graph checks
+// lets cycle detection and the unreachable-step warning see branch edges.
+func (w *Workflow) wireBranches(steps []*stepConfig) error {
+	// gates collects per-target branch decisions; several branches may
+	// route to the same target, in which case any one choosing it suffices.
+	gates := make(map[string][]func(*WorkflowContext) bool)
+	for _, b := range steps {
+		if b.kind != "branch" {
+			continue
+		}
+		if b.branchTrue == "" && b.branchFalse == "" {
+			return fmt.Errorf("workflow %s: branch %q has no targets", w.name, b.name)
+		}
+		resultKey := b.name + resultSuffix
+		for _, t := range []struct{ target, want string }{{b.branchTrue, "true"}, {b.branchFalse, "false"}} {
+			if t.target == "" {
+				continue
+			}
+			target, ok := w.steps[t.target]
+			if !ok {
+				return fmt.Errorf("workflow %s: branch %q targets unknown step %q", w.name, b.name, t.target)
+			}
+			if target == b {
+				return fmt.Errorf("workflow %s: branch %q targets itself", w.name, b.name)
+			}
+			if !slices.Contains(target.after, b.name) {
+				// Clip so the append never writes into a slice shared with
+				// another step's options.
+				target.after = append(target.after[:len(target.after):len(target.after)], b.name)
+				w.edges[target.name] = target.after
+			}
+			want := t.want
+			gates[target.name] = append(gates[target.name], func(wCtx *WorkflowContext) bool {
+				v, ok := wCtx.Get(resultKey)
+				return ok && v == want
+			})
+		}
+	}
+	for name, gs := range gates {
+		target := w.steps[name]
+		own := target.when
+		target.when = func(wCtx *WorkflowContext) bool {
+			if own != nil && !own(wCtx) {
+				return false
+			}
+			for _, g := range gs {
+				if g(wCtx) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return nil
+}
+
 // buildDependents constructs the forward adjacency map (dep -> steps that
 // depend on it) from the edges map. Computed once at construction time and
 // reused by detectCycle, findReachable, and runDAG.
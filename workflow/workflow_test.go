@@ -3,6 +3,7 @@ package workflow
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/nevindra/oasis/core"
@@ -45,6 +46,60 @@ func TestWorkflowContextGetSet(t *testing.T) {
 	}
 }
 
+func TestWorkflowContextTypedGetters(t *testing.T) {
+	ctx := newWorkflowContext(core.AgentTask{})
+	ctx.Set("name", "oasis")
+	ctx.Set("count", 3)
+	ctx.Set("restored", float64(7)) // a number after a JSON round trip
+	ctx.Set("ratio", 0.5)
+	ctx.Set("big", uint64(math.MaxUint64))
+	ctx.Set("doc", `{"title":"Q3","pages":12}`)
+	ctx.Set("doc.map", map[string]any{"title": "Q4", "pages": 3})
+
+	if s, ok := ctx.GetString("name"); !ok || s != "oasis" {
+		t.Errorf("GetString(name) = (%q, %v)", s, ok)
+	}
+	if _, ok := ctx.GetString("count"); ok {
+		t.Error("GetString(count) ok = true for an int")
+	}
+	if n, ok := ctx.GetInt("count"); !ok || n != 3 {
+		t.Errorf("GetInt(count) = (%d, %v)", n, ok)
+	}
+	if n, ok := ctx.GetInt("restored"); !ok || n != 7 {
+		t.Errorf("GetInt(restored) = (%d, %v)", n, ok)
+	}
+	if _, ok := ctx.GetInt("ratio"); ok {
+		t.Error("GetInt(ratio) ok = true for 0.5")
+	}
+	if _, ok := ctx.GetInt("big"); ok {
+		t.Error("GetInt(big) ok = true for an overflowing uint64")
+	}
+	if _, ok := ctx.GetInt("name"); ok {
+		t.Error("GetInt(name) ok = true for a string")
+	}
+	if f, ok := ctx.GetFloat("count"); !ok || f != 3 {
+		t.Errorf("GetFloat(count) = (%v, %v)", f, ok)
+	}
+	if _, ok := ctx.GetFloat("missing"); ok {
+		t.Error("GetFloat(missing) ok = true")
+	}
+
+	type doc struct {
+		Title string `json:"title"`
+		Pages int    `json:"pages"`
+	}
+	var d doc
+	if !ctx.GetJSON("doc", &d) || d != (doc{"Q3", 12}) {
+		t.Errorf("GetJSON(doc) = %+v", d)
+	}
+	if !ctx.GetJSON("doc.map", &d) || d != (doc{"Q4", 3}) {
+		t.Errorf("GetJSON(doc.map) = %+v", d)
+	}
+	if ctx.GetJSON("name", &d) {
+		t.Error("GetJSON(name) ok = true for non-JSON text")
+	}
+}
+
 func TestWorkflowContextAddUsage(t *testing.T) {
 	ctx := newWorkflowContext(core.AgentTask{})
 	ctx.addUsage(core.Usage{InputTokens: 10, OutputTokens: 5})